@@ -25,6 +25,7 @@ import (
 	"context"
 	"crypto/tls"
 	"errors"
+	"expvar"
 	"flag"
 	"fmt"
 	"log/slog"
@@ -54,6 +55,7 @@ import (
 	"google.golang.org/grpc/channelz/service"
 	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/reflection"
+	"google.golang.org/protobuf/encoding/prototext"
 	"google.golang.org/protobuf/proto"
 )
 
@@ -79,9 +81,11 @@ var cloudProber struct {
 	prober          *prober.Prober
 	defaultServerLn net.Listener
 	defaultGRPCLn   net.Listener
+	debugServerLn   net.Listener
 	configSource    config.ConfigSource
 	config          *configpb.ProberConfig
 	cancelInitCtx   context.CancelFunc
+	reloadErr       error // Last config reload's error, if it failed.
 	sync.RWMutex
 }
 
@@ -140,14 +144,25 @@ func initDefaultServer(c *configpb.ProberConfig, l *logger.Logger) (net.Listener
 }
 
 func setDebugHandlers(srvMux *http.ServeMux) {
-	if os.Getenv(DisableHTTPDebugVar) != "" {
-		return
-	}
 	srvMux.HandleFunc("/debug/pprof/", pprof.Index)
 	srvMux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
 	srvMux.HandleFunc("/debug/pprof/profile", pprof.Profile)
 	srvMux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
 	srvMux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	srvMux.Handle("/debug/vars", expvar.Handler())
+}
+
+// initDebugServer creates the listener for the dedicated debug server, if
+// debug_port is configured.
+func initDebugServer(c *configpb.ProberConfig) (net.Listener, error) {
+	if c.GetDebugPort() == 0 {
+		return nil, nil
+	}
+	ln, err := net.Listen("tcp", net.JoinHostPort(getServerHost(c), strconv.Itoa(int(c.GetDebugPort()))))
+	if err != nil {
+		return nil, fmt.Errorf("error while creating listener for debug HTTP server: %v", err)
+	}
+	return ln, nil
 }
 
 // InitFromConfig initializes Cloudprober using the provided config.
@@ -157,6 +172,16 @@ func InitFromConfig(configFile string) error {
 	return InitWithConfigSource(config.ConfigSourceWithFile(configFile))
 }
 
+// InitFromConfigContents initializes Cloudprober using the provided config
+// content, in the textproto format. It's useful when embedding Cloudprober in
+// other programs that manage the config themselves. Like other Init
+// functions, it returns initialization errors instead of exiting, leaving
+// error handling to the caller. For other config formats, use
+// InitWithConfigSource() with config.ConfigSourceWithContent().
+func InitFromConfigContents(configText string) error {
+	return InitWithConfigSource(config.ConfigSourceWithContent(configText, "textpb"))
+}
+
 // Init initializes Cloudprober using the default config source.
 //
 // Optionally, one can use the 'state' package to customize cloudprober; for example,
@@ -170,31 +195,26 @@ func InitWithConfigSource(configSrc config.ConfigSource) error {
 		return err
 	}
 	return web.InitWithDataFuncs(web.DataFuncs{
-		GetRawConfig:    GetRawConfig,
-		GetParsedConfig: GetParsedConfig,
-		GetInfo:         GetInfo,
+		GetRawConfig:      GetRawConfig,
+		GetParsedConfig:   GetParsedConfig,
+		GetExpandedConfig: GetExpandedConfig,
+		GetInfo:           GetInfo,
+		ReloadConfig:      ReloadConfig,
+		AdminToken: func() string {
+			return GetProber().AdminToken()
+		},
+		SetProbePaused: func(name string, paused bool) error {
+			return GetProber().SetProbePaused(name, paused)
+		},
+		SetLameDuck: func(lameDuck bool) {
+			GetProber().SetLameDuckMode(lameDuck)
+		},
 	})
 }
 
-func initWithConfigSource(configSrc config.ConfigSource) error {
-	// Return immediately if prober is already initialized.
-	cloudProber.Lock()
-	defer cloudProber.Unlock()
-
-	if cloudProber.prober != nil {
-		return nil
-	}
-
-	// Initialize sysvars module
-	if err := sysvars.Init(logger.NewWithAttrs(slog.String("component", sysvarsModuleName)), nil); err != nil {
-		return err
-	}
-
-	cfg, err := configSrc.GetConfig()
-	if err != nil {
-		return err
-	}
-
+// addDefaultSysProbe adds the default system probe to the config, unless
+// it's disabled or a system probe is already configured.
+func addDefaultSysProbe(cfg *configpb.ProberConfig) {
 	// Be careful about imports, we want to check if system probe is configured.
 	// We iterate over probes to see if any of them is a system probe.
 	sysProbeConfigured := false
@@ -217,6 +237,28 @@ func initWithConfigSource(configSrc config.ConfigSource) error {
 			},
 		})
 	}
+}
+
+func initWithConfigSource(configSrc config.ConfigSource) error {
+	// Return immediately if prober is already initialized.
+	cloudProber.Lock()
+	defer cloudProber.Unlock()
+
+	if cloudProber.prober != nil {
+		return nil
+	}
+
+	// Initialize sysvars module
+	if err := sysvars.Init(logger.NewWithAttrs(slog.String("component", sysvarsModuleName)), nil); err != nil {
+		return err
+	}
+
+	cfg, err := configSrc.GetConfig()
+	if err != nil {
+		return err
+	}
+
+	addDefaultSysProbe(cfg)
 
 	globalLogger := logger.NewWithAttrs(slog.String("component", "global"))
 
@@ -227,9 +269,19 @@ func initWithConfigSource(configSrc config.ConfigSource) error {
 		return err
 	}
 	srvMux := http.NewServeMux()
-	setDebugHandlers(srvMux)
 	state.SetDefaultHTTPServeMux(srvMux)
 
+	// Debug handlers go on the default server, unless there is a dedicated
+	// debug server.
+	debugLn, err := initDebugServer(cfg)
+	if err != nil {
+		ln.Close()
+		return err
+	}
+	if debugLn == nil && os.Getenv(DisableHTTPDebugVar) == "" {
+		setDebugHandlers(srvMux)
+	}
+
 	var grpcLn net.Listener
 	if cfg.GetGrpcPort() != 0 {
 		serverHost := getServerHost(cfg)
@@ -273,6 +325,9 @@ func initWithConfigSource(configSrc config.ConfigSource) error {
 	if err != nil {
 		cancelFunc()
 		ln.Close()
+		if debugLn != nil {
+			debugLn.Close()
+		}
 		return err
 	}
 
@@ -281,13 +336,50 @@ func initWithConfigSource(configSrc config.ConfigSource) error {
 	cloudProber.configSource = configSrc
 	cloudProber.defaultServerLn = ln
 	cloudProber.defaultGRPCLn = grpcLn
+	cloudProber.debugServerLn = debugLn
 	cloudProber.cancelInitCtx = cancelFunc
 
 	return nil
 }
 
+// CheckConfig does a deep dry run of the config from the given config source:
+// besides parsing the config, it creates the probes, expands their targets
+// and initializes the surfacers, without starting anything. See
+// prober.CheckConfig for details. It's meant to be run in a standalone
+// process, before deploying a config.
+func CheckConfig(ctx context.Context, configSrc config.ConfigSource) error {
+	if err := sysvars.Init(logger.NewWithAttrs(slog.String("component", sysvarsModuleName)), nil); err != nil {
+		return err
+	}
+
+	cfg, err := configSrc.GetConfig()
+	if err != nil {
+		return err
+	}
+
+	// Surfacers and probes may register web handlers.
+	if state.DefaultHTTPServeMux() == nil {
+		state.SetDefaultHTTPServeMux(http.NewServeMux())
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	return prober.CheckConfig(ctx, cfg, logger.NewWithAttrs(slog.String("component", "configtest")))
+}
+
 // RunOnce runs requested probes once and print probe results to stdout.
 func RunOnce(ctx context.Context, names, format, indent string) error {
+	return runOnce(ctx, names, format, indent, false)
+}
+
+// RunOnceAndExport is like RunOnce, but it also exports the probe results to
+// the configured surfacers, and waits for the surfacers to pick them up. This
+// is useful to run cloudprober from cron jobs and CI pipelines.
+func RunOnceAndExport(ctx context.Context, names, format, indent string) error {
+	return runOnce(ctx, names, format, indent, true)
+}
+
+func runOnce(ctx context.Context, names, format, indent string, export bool) error {
 	cloudProber.RLock()
 	defer cloudProber.RUnlock()
 
@@ -299,9 +391,16 @@ func RunOnce(ctx context.Context, names, format, indent string) error {
 	prrs, err := cloudProber.prober.Run(ctx, probeNames)
 	fmt.Println(singlerun.FormatProbeRunResults(prrs, singlerun.Format(format), indent))
 
+	if export {
+		cloudProber.prober.ExportRunResults(ctx, prrs)
+	}
+
 	// In CLI case, aggregate the probe run errors, so we can more easily show to users.
-	for _, prr := range prrs {
+	for name, prr := range prrs {
 		for _, r := range prr {
+			if r.Error == nil && !r.Success {
+				r.Error = fmt.Errorf("probe %s failed for target %s", name, r.Target.Dst())
+			}
 			err = errors.Join(err, r.Error)
 		}
 	}
@@ -319,10 +418,21 @@ func Start(ctx context.Context) {
 	httpSrv := &http.Server{Handler: srvMux}
 	grpcSrv := state.DefaultGRPCServer()
 
+	var debugSrv *http.Server
+	if cloudProber.debugServerLn != nil {
+		debugMux := http.NewServeMux()
+		setDebugHandlers(debugMux)
+		debugSrv = &http.Server{Handler: debugMux}
+		go debugSrv.Serve(cloudProber.debugServerLn)
+	}
+
 	// Set up a goroutine to cleanup if context ends.
 	go func() {
 		<-ctx.Done()
 		httpSrv.Close() // This will close the listener as well.
+		if debugSrv != nil {
+			debugSrv.Close()
+		}
 		if grpcSrv != nil {
 			grpcSrv.Stop()
 		}
@@ -331,9 +441,11 @@ func Start(ctx context.Context) {
 		defer cloudProber.Unlock()
 		cloudProber.defaultServerLn = nil
 		cloudProber.defaultGRPCLn = nil
+		cloudProber.debugServerLn = nil
 		cloudProber.config = nil
 		cloudProber.configSource = nil
 		cloudProber.prober = nil
+		cloudProber.reloadErr = nil
 		// prevent reuse in, for example, tests
 		state.SetDefaultGRPCServer(nil)
 		state.SetDefaultHTTPServeMux(nil)
@@ -349,9 +461,99 @@ func Start(ctx context.Context) {
 	}
 
 	cloudProber.prober.Start(ctx)
+
+	if wcs, ok := cloudProber.configSource.(config.WatchableConfigSource); ok {
+		if changes := wcs.Watch(ctx); changes != nil {
+			go watchConfig(ctx, changes)
+		}
+	}
+
 	srvMux.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
 		fmt.Fprintf(w, "OK")
 	})
+	srvMux.HandleFunc("/healthz", healthzHandler)
+}
+
+// healthzHandler reports cloudprober's internal health: unlike /health, it
+// returns an error (503) if probes have stopped producing data, metrics or
+// surfacer queues are saturated, or the last config reload failed. It's
+// meant for the Kubernetes liveness and readiness probes.
+func healthzHandler(w http.ResponseWriter, r *http.Request) {
+	cloudProber.RLock()
+	pr, reloadErr := cloudProber.prober, cloudProber.reloadErr
+	cloudProber.RUnlock()
+
+	var err error
+	if pr == nil {
+		err = errors.New("prober is not running")
+	} else {
+		err = pr.Health()
+	}
+	if reloadErr != nil {
+		err = errors.Join(err, fmt.Errorf("last config reload failed: %v", reloadErr))
+	}
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+	fmt.Fprintf(w, "OK")
+}
+
+// watchConfig reloads the config every time config source reports a change,
+// until the context is canceled.
+func watchConfig(ctx context.Context, changes <-chan struct{}) {
+	l := logger.NewWithAttrs(slog.String("component", "config-reload"))
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-changes:
+		}
+
+		l.Info("Config changed, reloading")
+		if err := ReloadConfig(); err != nil {
+			l.Errorf("Error reloading config: %v", err)
+		}
+	}
+}
+
+// ReloadConfig loads the config again from the config source and applies the
+// probe and surfacer changes: new probes and surfacers are started, removed
+// ones are stopped, and changed ones are restarted. Unchanged probes and
+// surfacers keep running with their state intact. Changes to the other parts
+// of the config still require a restart.
+func ReloadConfig() error {
+	l := logger.NewWithAttrs(slog.String("component", "config-reload"))
+
+	cloudProber.Lock()
+	defer cloudProber.Unlock()
+
+	if cloudProber.prober == nil {
+		return errors.New("prober is not running")
+	}
+
+	cfg, err := cloudProber.configSource.GetConfig()
+	if err != nil {
+		cloudProber.reloadErr = err
+		return err
+	}
+	addDefaultSysProbe(cfg)
+
+	oldCfg, newCfg := proto.Clone(cloudProber.config).(*configpb.ProberConfig), proto.Clone(cfg).(*configpb.ProberConfig)
+	oldCfg.Probe, newCfg.Probe = nil, nil
+	oldCfg.Surfacer, newCfg.Surfacer = nil, nil
+	if !proto.Equal(oldCfg, newCfg) {
+		l.Warning("Config changes outside of probes and surfacers require a restart to take effect, only probe and surfacer changes will be applied")
+	}
+
+	// Surfacers are reloaded first, so that new probes' data goes to the
+	// new surfacers. Even if some probes or surfacers fail to reload, others
+	// have been updated, so we update the config regardless.
+	err = errors.Join(cloudProber.prober.ReloadSurfacers(cfg.GetSurfacer()), cloudProber.prober.ReloadProbes(cfg))
+	cloudProber.config = cfg
+	cloudProber.reloadErr = err
+	return err
 }
 
 // GetConfig returns the prober config.
@@ -375,6 +577,18 @@ func GetParsedConfig() string {
 	return cloudProber.configSource.ParsedConfig()
 }
 
+// GetExpandedConfig returns the running config, after processing templates
+// and environment variables, in the textproto format. Secrets in the config
+// are redacted.
+func GetExpandedConfig() string {
+	cloudProber.RLock()
+	defer cloudProber.RUnlock()
+	if cloudProber.config == nil {
+		return ""
+	}
+	return prototext.Format(config.RedactSecrets(cloudProber.config))
+}
+
 // GetInfo returns information on all the probes, servers and surfacers.
 func GetInfo() (map[string]*probes.ProbeInfo, []*surfacers.SurfacerInfo, []*servers.ServerInfo) {
 	cloudProber.RLock()
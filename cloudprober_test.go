@@ -16,7 +16,9 @@ package cloudprober
 
 import (
 	"context"
+	"fmt"
 	"net"
+	"net/http"
 	"os"
 	"testing"
 	"time"
@@ -220,6 +222,42 @@ func TestRestart(t *testing.T) {
 	}
 }
 
+func TestDebugServer(t *testing.T) {
+	ports := freePortsT(t, 2)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer func() {
+		cancel()
+		// Wait required for the cloudprober instance to fully shut down.
+		time.Sleep(time.Second)
+	}()
+
+	cfg := &configpb.ProberConfig{
+		Port:      proto.Int32(ports[0]),
+		DebugPort: proto.Int32(ports[1]),
+	}
+	if err := InitWithConfigSource(config.ConfigSourceWithContent(prototext.Format(cfg), "textpb")); err != nil {
+		t.Fatalf("Error initializing cloudprober: %v", err)
+	}
+	Start(ctx)
+
+	getStatus := func(port int32, path string) int {
+		t.Helper()
+		resp, err := http.Get(fmt.Sprintf("http://localhost:%d%s", port, path))
+		if err != nil {
+			t.Fatalf("Error getting %s from port %d: %v", path, port, err)
+		}
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	assert.Equal(t, http.StatusOK, getStatus(ports[1], "/debug/pprof/"))
+	assert.Equal(t, http.StatusOK, getStatus(ports[1], "/debug/vars"))
+	// Debug handlers are not served on the default server.
+	assert.Equal(t, http.StatusNotFound, getStatus(ports[0], "/debug/pprof/"))
+	assert.Equal(t, http.StatusOK, getStatus(ports[0], "/health"))
+}
+
 func TestCloudproberConfig(t *testing.T) {
 	rawCfg := `probe { type: PING, name: "test_probe", targets { host_names: "localhost" }}`
 	f, err := os.CreateTemp("", "cloudprober_test")
@@ -26,7 +26,6 @@ import (
 	"log/slog"
 	"os"
 	"os/signal"
-	"runtime/pprof"
 	"strconv"
 	"syscall"
 	"time"
@@ -35,6 +34,7 @@ import (
 
 	"github.com/cloudprober/cloudprober"
 	"github.com/cloudprober/cloudprober/config"
+	"github.com/cloudprober/cloudprober/internal/plugins"
 	"github.com/cloudprober/cloudprober/logger"
 	"github.com/cloudprober/cloudprober/state"
 )
@@ -43,11 +43,12 @@ var (
 	versionFlag      = flag.Bool("version", false, "Print version and exit")
 	buildInfoFlag    = flag.Bool("buildinfo", false, "Print build info and exit")
 	stopTime         = flag.Duration("stop_time", 0, "How long to wait for cleanup before process exits on SIGINT and SIGTERM")
-	cpuprofile       = flag.String("cpuprof", "", "Write cpu profile to file")
-	memprofile       = flag.String("memprof", "", "Write heap profile to file")
+	cpuprofile       = flag.String("cpuprof", "", "Deprecated: no-op, profiles are served by the debug HTTP server, see debug_port config option")
+	memprofile       = flag.String("memprof", "", "Deprecated: no-op, profiles are served by the debug HTTP server, see debug_port config option")
 	configTest       = flag.Bool("configtest", false, "Dry run to test config file")
 	dumpConfig       = flag.Bool("dumpconfig", false, "Dump processed config to stdout")
 	dumpConfigFormat = flag.String("dumpconfig_fmt", "textpb", "Dump config format (textpb, json, yaml)")
+	pluginsDir       = flag.String("plugins_dir", "", "Directory to load Go plugins (.so files) from. Plugins can register new probes, surfacers and targets types.")
 
 	// Run once flags
 	runOnce           = flag.Bool("run_once", false, "Run a single probe and exit")
@@ -56,6 +57,15 @@ var (
 	runOnceOutIndent  = flag.String("run_once_output_indent", "  ", "Run once output indent")
 )
 
+var configTestDeep = flag.Bool("configtest_deep", false, "Deeper dry run to test config: besides parsing the config, create probes, expand their targets and initialize surfacers, reporting errors for each config section")
+
+var (
+	serviceCmd  = flag.String("service", "", "Windows service control command: install, uninstall, start or stop. Flags set along with --service=install are used to run the service.")
+	serviceName = flag.String("service_name", "cloudprober", "Windows service name")
+)
+
+var runOnceExportMetrics = flag.Bool("run_once_export_metrics", false, "Export run once probe results to the configured surfacers as well. Use --stop_time to give surfacers time to flush their data before exit.")
+
 // These variables get overwritten by using -ldflags="-X main.<var>=<value?" at
 // the build time.
 var version string
@@ -63,42 +73,13 @@ var buildTimestamp string
 var dirty string
 var l *logger.Logger
 
-func setupProfiling() {
-	sigChan := make(chan os.Signal, 1)
-	signal.Notify(sigChan, os.Interrupt)
-	var f *os.File
-	if *cpuprofile != "" {
-		var err error
-		f, err = os.Create(*cpuprofile)
-		if err != nil {
-			l.Critical(err.Error())
-		}
-		if err = pprof.StartCPUProfile(f); err != nil {
-			l.Criticalf("Could not start CPU profiling: %v", err)
-		}
+// getStopTime returns the time to wait for cleanup before exiting. If it's not
+// set through the flag, it's taken from the config.
+func getStopTime() time.Duration {
+	if *stopTime != 0 {
+		return *stopTime
 	}
-	go func(file *os.File) {
-		<-sigChan
-		pprof.StopCPUProfile()
-		if *cpuprofile != "" {
-			if err := file.Close(); err != nil {
-				l.Critical(err.Error())
-			}
-		}
-		if *memprofile != "" {
-			f, err := os.Create(*memprofile)
-			if err != nil {
-				l.Critical(err.Error())
-			}
-			if err = pprof.WriteHeapProfile(f); err != nil {
-				l.Critical(err.Error())
-			}
-			if err := f.Close(); err != nil {
-				l.Critical(err.Error())
-			}
-		}
-		os.Exit(1)
-	}(f)
+	return time.Duration(cloudprober.GetConfig().GetStopTimeSec()) * time.Second
 }
 
 func main() {
@@ -135,6 +116,14 @@ func main() {
 		return
 	}
 
+	// Load plugins before processing the config, as plugins may register
+	// config extensions.
+	if *pluginsDir != "" {
+		if err := plugins.Load(*pluginsDir, l); err != nil {
+			l.Criticalf("Error loading plugins. Err: %v", err)
+		}
+	}
+
 	if *dumpConfig {
 		out, err := config.DumpConfig(*dumpConfigFormat, nil)
 		if err != nil {
@@ -151,7 +140,31 @@ func main() {
 		return
 	}
 
-	setupProfiling()
+	if *configTestDeep {
+		if err := cloudprober.CheckConfig(context.Background(), config.DefaultConfigSource()); err != nil {
+			l.Criticalf("Config test failed. Errors:\n%v", err)
+		}
+		fmt.Println("Config test passed.")
+		return
+	}
+
+	if *serviceCmd != "" {
+		if err := controlService(*serviceName, *serviceCmd); err != nil {
+			l.Criticalf("Error running service command %s. Err: %v", *serviceCmd, err)
+		}
+		return
+	}
+
+	if isWindowsService() {
+		if err := runService(*serviceName); err != nil {
+			l.Criticalf("Error running as Windows service. Err: %v", err)
+		}
+		return
+	}
+
+	if *cpuprofile != "" || *memprofile != "" {
+		l.Warning("--cpuprof and --memprof flags are deprecated and have no effect. Get profiles from the /debug/pprof/ handlers instead, see debug_port config option.")
+	}
 
 	if err := cloudprober.Init(); err != nil {
 		l.Criticalf("Error initializing cloudprober. Err: %v", err)
@@ -159,9 +172,7 @@ func main() {
 
 	startCtx := context.Background()
 
-	if *stopTime == 0 {
-		*stopTime = time.Duration(cloudprober.GetConfig().GetStopTimeSec()) * time.Second
-	}
+	*stopTime = getStopTime()
 
 	if *stopTime != 0 {
 		// Set up signal handling for the cancelation of the start context.
@@ -173,6 +184,9 @@ func main() {
 		go func() {
 			sig := <-sigs
 			l.Warningf("Received signal \"%v\", canceling the start context and waiting for %v before closing", sig, *stopTime)
+			if err := sdNotify(sdNotifyStopping); err != nil {
+				l.Warningf("Error notifying systemd: %v", err)
+			}
 			cancelF()
 			time.Sleep(*stopTime)
 			os.Exit(0)
@@ -180,7 +194,15 @@ func main() {
 	}
 
 	if *runOnce {
-		err := cloudprober.RunOnce(startCtx, *runOnceProbeNames, *runOnceOutFormat, *runOnceOutIndent)
+		runOnceFunc := cloudprober.RunOnce
+		if *runOnceExportMetrics {
+			runOnceFunc = cloudprober.RunOnceAndExport
+		}
+		err := runOnceFunc(startCtx, *runOnceProbeNames, *runOnceOutFormat, *runOnceOutIndent)
+		if *runOnceExportMetrics {
+			// Surfacers may be batching data, give them time to flush.
+			time.Sleep(*stopTime)
+		}
 		if err != nil {
 			l.Criticalf("Error running run-once probe. Err: %v", err)
 		}
@@ -188,6 +210,28 @@ func main() {
 	}
 	cloudprober.Start(startCtx)
 
+	// Let systemd know that we are up, if running as a notify type service.
+	if err := sdNotify(sdNotifyReady); err != nil {
+		l.Warningf("Error notifying systemd: %v", err)
+	}
+	if err := startSDWatchdog(startCtx); err != nil {
+		l.Warningf("Error starting systemd watchdog notifications: %v", err)
+	}
+
+	// Reload config on SIGHUP.
+	hupSigs := make(chan os.Signal, 1)
+	signal.Notify(hupSigs, syscall.SIGHUP)
+	go func() {
+		for range hupSigs {
+			l.Info("Received SIGHUP, reloading config")
+			sdNotify(sdNotifyReloading)
+			if err := cloudprober.ReloadConfig(); err != nil {
+				l.Errorf("Error reloading config: %v", err)
+			}
+			sdNotify(sdNotifyReady)
+		}
+	}()
+
 	// Wait forever
 	select {}
 }
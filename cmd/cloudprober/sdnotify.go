@@ -0,0 +1,110 @@
+// Copyright 2025 The Cloudprober Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"time"
+)
+
+// Service state notifications for systemd, see sd_notify(3).
+const (
+	sdNotifyReady     = "READY=1"
+	sdNotifyReloading = "RELOADING=1"
+	sdNotifyStopping  = "STOPPING=1"
+	sdNotifyWatchdog  = "WATCHDOG=1"
+)
+
+// sdNotify sends a state notification to systemd. It's a no-op if we are not
+// running under systemd with Type=notify, i.e. if NOTIFY_SOCKET is not set.
+func sdNotify(state string) error {
+	socket := os.Getenv("NOTIFY_SOCKET")
+	if socket == "" {
+		return nil
+	}
+
+	// Socket names starting with '@' are in the abstract namespace.
+	if socket[0] == '@' {
+		socket = "\x00" + socket[1:]
+	}
+
+	conn, err := net.DialUnix("unixgram", nil, &net.UnixAddr{Name: socket, Net: "unixgram"})
+	if err != nil {
+		return fmt.Errorf("error connecting to systemd notify socket (%s): %v", socket, err)
+	}
+	defer conn.Close()
+
+	if _, err := conn.Write([]byte(state)); err != nil {
+		return fmt.Errorf("error writing to systemd notify socket (%s): %v", socket, err)
+	}
+	return nil
+}
+
+// sdWatchdogInterval returns the interval at which systemd expects watchdog
+// keep-alive notifications, or 0 if watchdog is not enabled for us.
+func sdWatchdogInterval() (time.Duration, error) {
+	usecStr := os.Getenv("WATCHDOG_USEC")
+	if usecStr == "" {
+		return 0, nil
+	}
+
+	// If WATCHDOG_PID is set, watchdog is meant for that process only.
+	if pidStr := os.Getenv("WATCHDOG_PID"); pidStr != "" {
+		pid, err := strconv.Atoi(pidStr)
+		if err != nil {
+			return 0, fmt.Errorf("invalid WATCHDOG_PID (%s): %v", pidStr, err)
+		}
+		if pid != os.Getpid() {
+			return 0, nil
+		}
+	}
+
+	usec, err := strconv.ParseInt(usecStr, 10, 64)
+	if err != nil || usec <= 0 {
+		return 0, fmt.Errorf("invalid WATCHDOG_USEC (%s)", usecStr)
+	}
+	return time.Duration(usec) * time.Microsecond, nil
+}
+
+// startSDWatchdog sends watchdog keep-alive notifications to systemd at half
+// the watchdog interval, until the context is canceled. It's a no-op if
+// watchdog is not enabled.
+func startSDWatchdog(ctx context.Context) error {
+	interval, err := sdWatchdogInterval()
+	if err != nil || interval == 0 {
+		return err
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval / 2)
+		defer ticker.Stop()
+
+		for {
+			if err := sdNotify(sdNotifyWatchdog); err != nil {
+				l.Warningf("Error sending watchdog notification to systemd: %v", err)
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+	return nil
+}
@@ -0,0 +1,114 @@
+// Copyright 2025 The Cloudprober Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !windows
+
+package main
+
+import (
+	"context"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testNotifySocket(t *testing.T) *net.UnixConn {
+	t.Helper()
+
+	dir, err := os.MkdirTemp("", "sdnotify")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	socket := filepath.Join(dir, "notify.sock")
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: socket, Net: "unixgram"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	t.Setenv("NOTIFY_SOCKET", socket)
+	return conn
+}
+
+func readNotification(t *testing.T, conn *net.UnixConn) string {
+	t.Helper()
+
+	buf := make([]byte, 64)
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	n, err := conn.Read(buf)
+	if err != nil {
+		t.Fatalf("error reading notification: %v", err)
+	}
+	return string(buf[:n])
+}
+
+func TestSDNotify(t *testing.T) {
+	t.Setenv("NOTIFY_SOCKET", "")
+	assert.NoError(t, sdNotify(sdNotifyReady), "no-op without NOTIFY_SOCKET")
+
+	conn := testNotifySocket(t)
+	assert.NoError(t, sdNotify(sdNotifyReady))
+	assert.Equal(t, "READY=1", readNotification(t, conn))
+}
+
+func TestSDWatchdogInterval(t *testing.T) {
+	for _, tt := range []struct {
+		desc    string
+		usec    string
+		pid     string
+		want    time.Duration
+		wantErr bool
+	}{
+		{desc: "not set"},
+		{desc: "enabled", usec: "2000000", want: 2 * time.Second},
+		{desc: "our pid", usec: "2000000", pid: strconv.Itoa(os.Getpid()), want: 2 * time.Second},
+		{desc: "other pid", usec: "2000000", pid: "1"},
+		{desc: "bad usec", usec: "2s", wantErr: true},
+		{desc: "bad pid", usec: "2000000", pid: "x", wantErr: true},
+	} {
+		t.Run(tt.desc, func(t *testing.T) {
+			t.Setenv("WATCHDOG_USEC", tt.usec)
+			t.Setenv("WATCHDOG_PID", tt.pid)
+
+			got, err := sdWatchdogInterval()
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestStartSDWatchdog(t *testing.T) {
+	conn := testNotifySocket(t)
+	t.Setenv("WATCHDOG_USEC", "100000")
+	t.Setenv("WATCHDOG_PID", "")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	assert.NoError(t, startSDWatchdog(ctx))
+
+	for i := 0; i < 2; i++ {
+		assert.Equal(t, "WATCHDOG=1", readNotification(t, conn))
+	}
+}
@@ -0,0 +1,154 @@
+// Copyright 2025 The Cloudprober Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/cloudprober/cloudprober"
+	"golang.org/x/sys/windows/svc"
+	"golang.org/x/sys/windows/svc/mgr"
+)
+
+// cloudproberService implements svc.Handler to run cloudprober as a Windows
+// service.
+type cloudproberService struct{}
+
+func (cs *cloudproberService) Execute(_ []string, r <-chan svc.ChangeRequest, status chan<- svc.Status) (bool, uint32) {
+	status <- svc.Status{State: svc.StartPending}
+
+	if err := cloudprober.Init(); err != nil {
+		l.Errorf("Error initializing cloudprober. Err: %v", err)
+		return true, 1
+	}
+
+	ctx, cancelF := context.WithCancel(context.Background())
+	defer cancelF()
+	cloudprober.Start(ctx)
+
+	status <- svc.Status{State: svc.Running, Accepts: svc.AcceptStop | svc.AcceptShutdown}
+
+	for c := range r {
+		switch c.Cmd {
+		case svc.Interrogate:
+			status <- c.CurrentStatus
+		case svc.Stop, svc.Shutdown:
+			stopTime := getStopTime()
+			l.Warningf("Received service control request %d, canceling the start context and waiting for %v before stopping", c.Cmd, stopTime)
+			status <- svc.Status{State: svc.StopPending, WaitHint: uint32((stopTime + 5*time.Second).Milliseconds())}
+			cancelF()
+			time.Sleep(stopTime)
+			return false, 0
+		default:
+			l.Warningf("Unexpected service control request: %d", c.Cmd)
+		}
+	}
+	return false, 0
+}
+
+// isWindowsService reports whether we are running as a Windows service.
+func isWindowsService() bool {
+	isSvc, err := svc.IsWindowsService()
+	if err != nil {
+		l.Warningf("Error determining if running as a Windows service: %v", err)
+	}
+	return isSvc
+}
+
+// runService runs cloudprober as a Windows service. It returns when the
+// service is stopped.
+func runService(name string) error {
+	return svc.Run(name, &cloudproberService{})
+}
+
+// serviceArgs returns the command line flags to run the service with: all
+// flags set for the current invocation, except the service control flag.
+func serviceArgs() []string {
+	var args []string
+	flag.Visit(func(f *flag.Flag) {
+		if f.Name != "service" {
+			args = append(args, fmt.Sprintf("--%s=%s", f.Name, f.Value.String()))
+		}
+	})
+	return args
+}
+
+// controlService installs, uninstalls, starts or stops the cloudprober
+// Windows service.
+func controlService(name, cmd string) error {
+	m, err := mgr.Connect()
+	if err != nil {
+		return fmt.Errorf("error connecting to Windows service manager: %v", err)
+	}
+	defer m.Disconnect()
+
+	if cmd == "install" {
+		if s, err := m.OpenService(name); err == nil {
+			s.Close()
+			return fmt.Errorf("service %s already exists", name)
+		}
+		exePath, err := os.Executable()
+		if err != nil {
+			return fmt.Errorf("error getting executable path: %v", err)
+		}
+		s, err := m.CreateService(name, exePath, mgr.Config{
+			DisplayName: "Cloudprober",
+			Description: "Cloudprober active monitoring",
+			StartType:   mgr.StartAutomatic,
+		}, serviceArgs()...)
+		if err != nil {
+			return fmt.Errorf("error creating service %s: %v", name, err)
+		}
+		s.Close()
+		return nil
+	}
+
+	s, err := m.OpenService(name)
+	if err != nil {
+		return fmt.Errorf("error opening service %s: %v", name, err)
+	}
+	defer s.Close()
+
+	switch cmd {
+	case "uninstall":
+		return s.Delete()
+	case "start":
+		return s.Start()
+	case "stop":
+		st, err := s.Control(svc.Stop)
+		if err != nil {
+			return fmt.Errorf("error stopping service %s: %v", name, err)
+		}
+		timeout := time.Now().Add(getStopTime() + 10*time.Second)
+		for st.State != svc.Stopped {
+			if time.Now().After(timeout) {
+				return fmt.Errorf("timed out waiting for service %s to stop", name)
+			}
+			time.Sleep(500 * time.Millisecond)
+			if st, err = s.Query(); err != nil {
+				return fmt.Errorf("error querying service %s status: %v", name, err)
+			}
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown service command: %s, expected one of: install, uninstall, start, stop", cmd)
+	}
+}
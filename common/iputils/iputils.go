@@ -20,6 +20,7 @@ package iputils
 import (
 	"fmt"
 	"net"
+	"syscall"
 )
 
 // IPVersion tells if an IP address is IPv4 or IPv6.
@@ -80,3 +81,15 @@ func ResolveIntfAddr(intfName string, ipVer int) (net.IP, error) {
 	}
 	return nil, fmt.Errorf("resolveIntfAddr(%v, %d) found no apprpriate IP addresses in %v", intfName, ipVer, addrs)
 }
+
+// BindToDeviceControl returns a function that binds the sockets to the given
+// network interface, for use as net.Dialer.Control or net.ListenConfig.Control.
+func BindToDeviceControl(intf string) func(network, address string, c syscall.RawConn) error {
+	return func(_, _ string, c syscall.RawConn) error {
+		var err error
+		if cerr := c.Control(func(fd uintptr) { err = BindToDevice(int(fd), intf) }); cerr != nil {
+			return cerr
+		}
+		return err
+	}
+}
@@ -21,6 +21,7 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"sync"
 	"time"
 
 	configpb "github.com/cloudprober/cloudprober/common/oauth/proto"
@@ -28,6 +29,8 @@ import (
 	"github.com/cloudprober/cloudprober/logger"
 	"golang.org/x/oauth2"
 	"golang.org/x/oauth2/google"
+	"google.golang.org/api/idtoken"
+	"google.golang.org/api/option"
 )
 
 // jsonToken represents OAuth2 token. We use this struct to parse responses
@@ -60,6 +63,10 @@ func TokenSourceFromConfig(c *configpb.Config, l *logger.Logger) (oauth2.TokenSo
 	case *configpb.Config_GoogleCredentials:
 		f := c.GetGoogleCredentials().GetJsonFile()
 
+		if c.GetGoogleCredentials().GetIdTokenAudience() != "" {
+			return idTokenSource(c.GetGoogleCredentials())
+		}
+
 		// If JSON file is not provided, try default credentials.
 		if f == "" {
 			creds, err := google.FindDefaultCredentials(context.Background(), c.GetGoogleCredentials().GetScope()...)
@@ -91,3 +98,48 @@ func TokenSourceFromConfig(c *configpb.Config, l *logger.Logger) (oauth2.TokenSo
 
 	return newTokenSource(c, refreshExpiryBuffer, l)
 }
+
+// idTokenSource returns a token source that provides OpenID Connect ID tokens
+// as access tokens.
+func idTokenSource(c *configpb.GoogleCredentials) (oauth2.TokenSource, error) {
+	if c.GetJwtAsAccessToken() {
+		return nil, fmt.Errorf("oauth: only one of id_token_audience and jwt_as_access_token can be set")
+	}
+
+	var opts []option.ClientOption
+	if f := c.GetJsonFile(); f != "" {
+		jsonKey, err := file.ReadFile(context.Background(), f)
+		if err != nil {
+			return nil, fmt.Errorf("error reading Google Credentials file (%s): %v", f, err)
+		}
+		opts = append(opts, option.WithCredentialsJSON(jsonKey))
+	}
+
+	return &lazyIDTokenSource{audience: c.GetIdTokenAudience(), opts: opts}, nil
+}
+
+// lazyIDTokenSource creates the underlying ID token source on the first
+// Token() call. idtoken.NewTokenSource fetches a token right away, and we
+// don't want to fail the probe initialization if the token endpoint is
+// temporarily unavailable.
+type lazyIDTokenSource struct {
+	audience string
+	opts     []option.ClientOption
+
+	mu sync.Mutex
+	ts oauth2.TokenSource
+}
+
+func (lts *lazyIDTokenSource) Token() (*oauth2.Token, error) {
+	lts.mu.Lock()
+	defer lts.mu.Unlock()
+
+	if lts.ts == nil {
+		ts, err := idtoken.NewTokenSource(context.Background(), lts.audience, lts.opts...)
+		if err != nil {
+			return nil, fmt.Errorf("oauth: error creating ID token source for audience (%s): %v", lts.audience, err)
+		}
+		lts.ts = ts
+	}
+	return lts.ts.Token()
+}
@@ -15,10 +15,14 @@
 package oauth
 
 import (
+	"encoding/base64"
 	"fmt"
 	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
 	configpb "github.com/cloudprober/cloudprober/common/oauth/proto"
 	"google.golang.org/protobuf/proto"
@@ -108,3 +112,66 @@ func TestGoogleCredentials(t *testing.T) {
 		t.Errorf("Config: %v, Unexpected error: %v", c, err)
 	}
 }
+
+func TestGoogleCredentialsIDToken(t *testing.T) {
+	audience := "test-client-id.apps.googleusercontent.com"
+	exp := time.Now().Add(time.Hour).Unix()
+	idToken := strings.Join([]string{
+		base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"RS256","typ":"JWT"}`)),
+		base64.RawURLEncoding.EncodeToString([]byte(fmt.Sprintf(`{"aud":"%s","exp":%d}`, audience, exp))),
+		base64.RawURLEncoding.EncodeToString([]byte("signature")),
+	}, ".")
+
+	// Fake token endpoint, we count the requests to verify that token is
+	// fetched only when needed.
+	var reqCount int
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		reqCount++
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"id_token": "%s"}`, idToken)
+	}))
+	defer ts.Close()
+
+	jsonKey := strings.Replace(testJSONKey(), "https://oauth2.googleapis.com/token", ts.URL, 1)
+	jsonF := createTempFile(t, []byte(jsonKey))
+
+	googleC := &configpb.GoogleCredentials{
+		JsonFile:        proto.String(jsonF),
+		IdTokenAudience: proto.String(audience),
+	}
+
+	c := &configpb.Config{
+		Source: &configpb.Config_GoogleCredentials{
+			GoogleCredentials: googleC,
+		},
+	}
+
+	tokSrc, err := TokenSourceFromConfig(c, nil)
+	if err != nil {
+		t.Fatalf("Config: %v, Unexpected error: %v", c, err)
+	}
+	if reqCount != 0 {
+		t.Errorf("Token fetched before it was needed, requests: %d", reqCount)
+	}
+
+	for i := 0; i < 2; i++ {
+		tok, err := tokSrc.Token()
+		if err != nil {
+			t.Fatalf("Unexpected error getting token: %v", err)
+		}
+		if tok.AccessToken != idToken {
+			t.Errorf("Got token: %s, want: %s", tok.AccessToken, idToken)
+		}
+	}
+	if reqCount != 1 {
+		t.Errorf("Token requests: %d, want: 1", reqCount)
+	}
+
+	// Both id_token_audience and jwt_as_access_token can't be set.
+	googleC.JwtAsAccessToken = proto.Bool(true)
+
+	_, err = TokenSourceFromConfig(c, nil)
+	if err == nil {
+		t.Errorf("Config: %v, Expected error, but got none.", c)
+	}
+}
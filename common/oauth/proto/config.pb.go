@@ -465,9 +465,16 @@ type GoogleCredentials struct {
 	// OAuth2.0 flow.
 	JwtAsAccessToken *bool `protobuf:"varint,4,opt,name=jwt_as_access_token,json=jwtAsAccessToken" json:"jwt_as_access_token,omitempty"`
 	// Audience works only if jwt_as_access_token is true.
-	Audience      *string `protobuf:"bytes,3,opt,name=audience" json:"audience,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	Audience *string `protobuf:"bytes,3,opt,name=audience" json:"audience,omitempty"`
+	// If set, fetch an OpenID Connect ID token for this audience, instead of an
+	// access token. This is required for the endpoints that verify ID tokens,
+	// e.g. Cloud Run services and IAP-protected endpoints. For IAP, audience is
+	// the OAuth client ID used by IAP. ID tokens are fetched using the JSON
+	// file's service account, or default credentials (e.g. GCE metadata) if
+	// json_file is not set. Scopes are not used for ID tokens.
+	IdTokenAudience *string `protobuf:"bytes,5,opt,name=id_token_audience,json=idTokenAudience" json:"id_token_audience,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
 }
 
 func (x *GoogleCredentials) Reset() {
@@ -528,6 +535,13 @@ func (x *GoogleCredentials) GetAudience() string {
 	return ""
 }
 
+func (x *GoogleCredentials) GetIdTokenAudience() string {
+	if x != nil && x.IdTokenAudience != nil {
+		return *x.IdTokenAudience
+	}
+	return ""
+}
+
 var File_github_com_cloudprober_cloudprober_common_oauth_proto_config_proto protoreflect.FileDescriptor
 
 const file_github_com_cloudprober_cloudprober_common_oauth_proto_config_proto_rawDesc = "" +
@@ -559,12 +573,13 @@ const file_github_com_cloudprober_cloudprober_common_oauth_proto_config_proto_ra
 	"\x13gce_service_account\x18\x03 \x01(\tH\x00R\x11gceServiceAccount\x12(\n" +
 	"\x0fk8s_local_token\x18\x04 \x01(\bH\x00R\rk8sLocalToken\x120\n" +
 	"\x14refresh_interval_sec\x18Z \x01(\x02R\x12refreshIntervalSecB\b\n" +
-	"\x06source\"\x91\x01\n" +
+	"\x06source\"\xbd\x01\n" +
 	"\x11GoogleCredentials\x12\x1b\n" +
 	"\tjson_file\x18\x01 \x01(\tR\bjsonFile\x12\x14\n" +
 	"\x05scope\x18\x02 \x03(\tR\x05scope\x12-\n" +
 	"\x13jwt_as_access_token\x18\x04 \x01(\bR\x10jwtAsAccessToken\x12\x1a\n" +
-	"\baudience\x18\x03 \x01(\tR\baudienceB7Z5github.com/cloudprober/cloudprober/common/oauth/proto"
+	"\baudience\x18\x03 \x01(\tR\baudience\x12*\n" +
+	"\x11id_token_audience\x18\x05 \x01(\tR\x0fidTokenAudienceB7Z5github.com/cloudprober/cloudprober/common/oauth/proto"
 
 var (
 	file_github_com_cloudprober_cloudprober_common_oauth_proto_config_proto_rawDescOnce sync.Once
@@ -96,7 +96,10 @@ type TLSConfig struct {
 	TlsKeyFile *string `protobuf:"bytes,3,opt,name=tls_key_file,json=tlsKeyFile" json:"tls_key_file,omitempty"`
 	// Whether to ignore the cert validation.
 	DisableCertValidation *bool `protobuf:"varint,4,opt,name=disable_cert_validation,json=disableCertValidation" json:"disable_cert_validation,omitempty"`
-	// ServerName override
+	// ServerName override. This is the name used for SNI and for verifying the
+	// server certificate.
+	// HTTP probe supports target substitutions in server_name, e.g.
+	// "@target.label.sni@", to use a different server name for each target.
 	ServerName    *string     `protobuf:"bytes,5,opt,name=server_name,json=serverName" json:"server_name,omitempty"`
 	MinTlsVersion *TLSVersion `protobuf:"varint,7,opt,name=min_tls_version,json=minTlsVersion,enum=cloudprober.tlsconfig.TLSVersion" json:"min_tls_version,omitempty"`
 	MaxTlsVersion *TLSVersion `protobuf:"varint,8,opt,name=max_tls_version,json=maxTlsVersion,enum=cloudprober.tlsconfig.TLSVersion" json:"max_tls_version,omitempty"`
@@ -21,12 +21,17 @@ import (
 	"errors"
 	"flag"
 	"fmt"
+	"net/url"
 	"os"
+	"path"
 	"path/filepath"
 	"regexp"
 	"runtime"
+	"slices"
 	"strings"
+	"time"
 
+	configpb "github.com/cloudprober/cloudprober/config/proto"
 	"github.com/cloudprober/cloudprober/internal/file"
 	"github.com/cloudprober/cloudprober/logger"
 	"github.com/google/go-jsonnet"
@@ -37,16 +42,28 @@ import (
 )
 
 var (
-	configFile          = flag.String("config_file", "", "Config file")
+	configFile          = flag.String("config_file", "", "Config file. It can be a local file or a gs://, s3:// or http(s):// URL.")
 	surfacersConfigFile = flag.String("surfacers_config_file", "", "Surfacers config file")
 	testInstanceName    = flag.String("test_instance_name", "ig-us-central1-a-01-0000", "Instance name example to be used in tests")
 )
 
+// Config reload flags.
+var (
+	configReloadInterval   = flag.Duration("config_reload_interval", 0, "If set, config file is checked for changes at this interval, and probe and surfacer changes are applied without a restart. This works for Kubernetes ConfigMaps mounted as files and remote (gs://, s3:// and http(s)://) config files as well.")
+	configK8sConfigMap     = flag.String("config_k8s_configmap", "", "Kubernetes ConfigMap to read the config from, specified as <namespace>/<name>, or just <name> for the pod's namespace. ConfigMap is watched for changes and probe and surfacer changes are applied without a restart.")
+	configK8sConfigMapKey  = flag.String("config_k8s_configmap_key", "cloudprober.cfg", "ConfigMap key that contains the config. Config format is determined from the key's extension.")
+	configGCPRuntimeConfig = flag.String("config_gcp_runtimeconfig", "", "GCP Runtime Config variable to read the config from, specified as projects/<project>/configs/<config>/variables/<variable>, or <config>/<variable> for the current project. Variable is watched for changes and probe and surfacer changes are applied without a restart. Config format is determined from the variable name's extension.")
+)
+
 // EnvRegex is the regex used to find environment variable placeholders
 // in the config file. The placeholders are of the form **$<env_var_name>**,
 // and are added during Go template processing for envSecret functions.
 var EnvRegex = regexp.MustCompile(`\*\*\$([^*\s]+)\*\*`)
 
+// Timeout for reading a config file. It matters mostly for the remote config
+// files.
+var configReadTimeout = time.Minute
+
 const (
 	configMetadataKeyName = "cloudprober_config"
 )
@@ -63,7 +80,13 @@ var configTestVars = map[string]any{
 }
 
 func DefaultConfigSource(opts ...Option) ConfigSource {
-	opts = append(opts, WithSurfacerConfig(*surfacersConfigFile))
+	opts = append(opts, WithSurfacerConfig(*surfacersConfigFile), WithReloadInterval(*configReloadInterval))
+	if *configK8sConfigMap != "" {
+		opts = append(opts, WithK8sConfigMap(*configK8sConfigMap, *configK8sConfigMapKey))
+	}
+	if *configGCPRuntimeConfig != "" {
+		opts = append(opts, WithGCPRuntimeConfig(*configGCPRuntimeConfig))
+	}
 	return ConfigSourceWithFile(*configFile, opts...)
 }
 
@@ -78,7 +101,31 @@ func ConfigSourceWithFile(fileName string, opts ...Option) ConfigSource {
 	return cs
 }
 
+// ConfigSourceWithContent returns a config source that uses the given config
+// content, instead of reading it from a file. Format is one of "textpb",
+// "yaml", "json" and "jsonnet"; an empty format means textpb. Content is
+// still processed as a Go template. Such config source is never watched for
+// changes.
+func ConfigSourceWithContent(content, format string, opts ...Option) ConfigSource {
+	dcs := &defaultConfigSource{
+		content:       &content,
+		contentFormat: format,
+	}
+	cs := ConfigSource(dcs)
+	for _, opt := range opts {
+		cs = opt(cs)
+	}
+	return cs
+}
+
 func formatFromFileName(fileName string) string {
+	// Ignore query and fragment in the config URLs.
+	if file.IsRemote(fileName) {
+		if i := strings.IndexAny(fileName, "?#"); i != -1 {
+			fileName = fileName[:i]
+		}
+	}
+
 	switch filepath.Ext(fileName) {
 	case ".json":
 		return "json"
@@ -92,8 +139,9 @@ func formatFromFileName(fileName string) string {
 }
 
 // handleIncludes handles "include" statements in the config file. It handles
-// nested includes in a depth-first manner.
-func handleIncludes(baseDir string, content []byte) (string, error) {
+// nested includes in a depth-first manner. parents are the files including
+// this content (including the file itself), used to detect include cycles.
+func handleIncludes(baseDir string, content []byte, parents []string) (string, error) {
 	var final []string
 
 	re := regexp.MustCompile(`(?m)^include\s+"([^"]+)"\s*$`)
@@ -107,17 +155,13 @@ func handleIncludes(baseDir string, content []byte) (string, error) {
 			continue
 		}
 
-		includePath := filepath.Join(baseDir, m[1])
-		files, err := filepath.Glob(includePath)
+		files, err := includeFiles(baseDir, m[1])
 		if err != nil {
 			return "", err
 		}
-		if len(files) == 0 {
-			return "", fmt.Errorf("config include error: %s didn't match any files", includePath)
-		}
 
 		for _, file := range files {
-			includedCfg, err := readConfigFile(file)
+			includedCfg, err := readIncludedFile(file, parents)
 			if err != nil {
 				return "", err
 			}
@@ -132,14 +176,133 @@ func handleIncludes(baseDir string, content []byte) (string, error) {
 	return strings.Join(final, newline), nil
 }
 
+// includeFiles returns the files to include for the given include path.
+// Local include paths can be glob patterns. Remote config files (e.g. on GCS
+// or HTTP servers) can include other files relative to their location (or
+// through the full URL), but glob patterns are not supported for them.
+func includeFiles(baseDir, includePath string) ([]string, error) {
+	if file.IsRemote(baseDir) {
+		base, err := url.Parse(baseDir + "/")
+		if err != nil {
+			return nil, fmt.Errorf("config include error: invalid config URL (%s): %v", baseDir, err)
+		}
+		ref, err := url.Parse(includePath)
+		if err != nil {
+			return nil, fmt.Errorf("config include error: invalid include path (%s): %v", includePath, err)
+		}
+		return []string{base.ResolveReference(ref).String()}, nil
+	}
+
+	includePath = filepath.Join(baseDir, includePath)
+	files, err := filepath.Glob(includePath)
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("config include error: %s didn't match any files", includePath)
+	}
+	return files, nil
+}
+
+// configFileDir returns the directory of the config file. For remote config
+// files, it's the URL up to the last slash.
+func configFileDir(fileName string) string {
+	if file.IsRemote(fileName) {
+		return fileName[:strings.LastIndex(fileName, "/")]
+	}
+	return filepath.Dir(fileName)
+}
+
+// cleanRemoteFileName cleans up the path part of a remote config file's URL.
+func cleanRemoteFileName(fileName string) string {
+	u, err := url.Parse(fileName)
+	// Cleaning up the escaped paths may change their meaning, leave them.
+	if err != nil || u.Path == "" || u.RawPath != "" {
+		return fileName
+	}
+	u.Path = path.Clean(u.Path)
+	return u.String()
+}
+
 func readConfigFile(fileName string) (string, error) {
-	b, err := file.ReadFile(context.Background(), fileName)
+	return readIncludedFile(fileName, nil)
+}
+
+// readIncludedFile reads the config file and processes its includes. parents
+// are the files that include this file, directly or indirectly.
+func readIncludedFile(fileName string, parents []string) (string, error) {
+	// Normalize file names, so that the same file included through different
+	// paths (e.g. "a/../b.cfg" and "b.cfg") is caught by the cycle check.
+	if file.IsRemote(fileName) {
+		fileName = cleanRemoteFileName(fileName)
+	} else {
+		fileName = filepath.Clean(fileName)
+	}
+	if slices.Contains(parents, fileName) {
+		return "", fmt.Errorf("config include error: include cycle: %s -> %s", strings.Join(parents, " -> "), fileName)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), configReadTimeout)
+	defer cancel()
+	b, err := file.ReadFile(ctx, fileName)
 	if err != nil {
 		return "", err
 	}
 
-	final, err := handleIncludes(filepath.Dir(fileName), b)
-	return final, err
+	// Clip parents to make sure that sibling includes don't share the
+	// underlying array.
+	return handleIncludes(configFileDir(fileName), b, append(slices.Clip(parents), fileName))
+}
+
+// checkMergedConfig verifies the config assembled from multiple sources:
+// included files and the surfacers config file. Repeated fields, e.g. probes,
+// surfacers and servers, from all sources are merged by appending them in the
+// order of inclusion, but the names of the probes (except the ones limited
+// to certain hosts through run_on), named surfacers, shared targets and
+// namespaces must be unique across all sources.
+func checkMergedConfig(cfg *configpb.ProberConfig) error {
+	checkUnique := func(kind string, names []string) error {
+		seen := make(map[string]bool)
+		for _, name := range names {
+			if name == "" {
+				continue
+			}
+			if seen[name] {
+				return fmt.Errorf("%s %q is defined more than once", kind, name)
+			}
+			seen[name] = true
+		}
+		return nil
+	}
+
+	var probeNames, surfacerNames, targetsNames, nsNames []string
+	for _, p := range cfg.GetProbe() {
+		// Probes with the same name may run on different hosts.
+		if p.GetRunOn() == "" {
+			probeNames = append(probeNames, p.GetName())
+		}
+	}
+	for _, s := range cfg.GetSurfacer() {
+		surfacerNames = append(surfacerNames, s.GetName())
+	}
+	for _, st := range cfg.GetSharedTargets() {
+		targetsNames = append(targetsNames, st.GetName())
+	}
+	for _, ns := range cfg.GetNamespace() {
+		nsNames = append(nsNames, ns.GetName())
+	}
+
+	for _, err := range []error{
+		checkUnique("probe", probeNames),
+		checkUnique("surfacer", surfacerNames),
+		checkUnique("shared_targets", targetsNames),
+		checkUnique("namespace", nsNames),
+	} {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 func processConfigText(configStr, configFormat string, tmplData map[string]any, m protoreflect.ProtoMessage, l *logger.Logger) (string, error) {
@@ -205,6 +368,7 @@ func substEnvVars(configStr string, l *logger.Logger) string {
 			continue
 		}
 		configStr = strings.ReplaceAll(configStr, "**$"+v+"**", envVal)
+		addSecretEnvVar(v)
 	}
 
 	return configStr
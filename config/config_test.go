@@ -333,6 +333,10 @@ func TestReadConfigFile(t *testing.T) {
 			fileName: "testdata/include_test/cloudprober_include.error.txtar",
 			wantErr:  true,
 		},
+		{
+			fileName: "testdata/include_test/cloudprober_include.cycle.txtar",
+			wantErr:  true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(filepath.Base(tt.fileName), func(t *testing.T) {
@@ -406,3 +410,52 @@ func TestReadConfigFile(t *testing.T) {
 		})
 	}
 }
+
+func TestCheckMergedConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  string
+		wantErr string
+	}{
+		{
+			name: "ok",
+			config: `
+				probe { name: "p1" type: HTTP }
+				probe { name: "p2" type: HTTP }
+				surfacer { type: PROMETHEUS }
+				surfacer { type: PROMETHEUS }`,
+		},
+		{
+			name: "duplicate_probe",
+			config: `
+				probe { name: "p1" type: HTTP }
+				probe { name: "p1" type: HTTP }`,
+			wantErr: `probe "p1" is defined more than once`,
+		},
+		{
+			name: "duplicate_probe_run_on",
+			config: `
+				probe { name: "p1" type: HTTP run_on: "host1" }
+				probe { name: "p1" type: HTTP run_on: "host2" }`,
+		},
+		{
+			name: "duplicate_surfacer",
+			config: `
+				surfacer { name: "s1" type: PROMETHEUS }
+				surfacer { name: "s1" type: FILE }`,
+			wantErr: `surfacer "s1" is defined more than once`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &configpb.ProberConfig{}
+			assert.NoError(t, prototext.Unmarshal([]byte(tt.config), cfg))
+			err := checkMergedConfig(cfg)
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
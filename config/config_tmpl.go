@@ -97,6 +97,61 @@ construction easier:
 			{{end}}
 			{{end}}
 
+		urlHost and joinHostPort
+			urlHost returns the given IP in a form that can be used as a URL host,
+			i.e. it encloses IPv6 addresses in square brackets. joinHostPort joins
+			host and port, again taking care of IPv6 addresses. These are useful
+			for the configs that need to work on the IPv6-only hosts as well.
+
+			probe {
+			  name: "local_http"
+			  type: HTTP
+			  targets {
+			    endpoint {
+			      name: "self"
+			      url: "http://{{urlHost .internal_ipv6}}:8080/healthz"
+			    }
+			  }
+			}
+			probe {
+			  name: "local_port_check"
+			  type: EXTERNAL
+			  external_probe {
+			    command: "/probes/check_port.sh {{joinHostPort .internal_ipv6 "9313"}}"
+			  }
+			}
+
+		env, expandenv and mustEnv
+			env (sprig) returns the value of the given environment variable, or an
+			empty string if it's not set. expandenv (sprig) expands $FOO and ${FOO}
+			references in the given string. mustEnv is like env, but it fails config
+			parsing if the variable is not set. Note that the expanded values show
+			up on the config pages (/config-parsed and /config-running); use
+			envSecret for the secrets.
+
+			probe {
+			  name: "api_{{env "ENVIRONMENT"}}"
+			  type: HTTP
+			  targets {
+			    host_names: "{{mustEnv "API_HOST"}}"
+			  }
+			  http_probe {
+			    relative_url: "{{expandenv "/${API_PATH}/healthz"}}"
+			  }
+			}
+
+		envSecret
+			envSecret expands to the value of the given environment variable only
+			after the config pages are rendered, so that the secret values don't
+			show up there. This is useful to inject credentials.
+
+			http_probe {
+			  header {
+			    name: "Authorization"
+			    value: "Bearer {{envSecret "API_TOKEN"}}"
+			  }
+			}
+
 	    configDir
 			configDir expands to the config file's directory. This is useful to
 			specify files relative to the config file.
@@ -109,14 +164,21 @@ construction easier:
 			    command: "{{configDir}}/test_x.sh"
 	    	  }
 	    	}
+
+# Custom Functions
+
+Programs that embed cloudprober can add their own template functions, e.g. to
+look up targets in an inventory system, using RegisterTemplateFunc.
 */
 package config
 
 import (
 	"bytes"
 	"fmt"
-	"path/filepath"
+	"net"
+	"os"
 	"regexp"
+	"sync"
 	"text/template"
 
 	"cloud.google.com/go/compute/metadata"
@@ -145,6 +207,43 @@ var readFromGCEMetadata = func(metadataKeyName string) (string, error) {
 	return metadata.ProjectAttributeValue(metadataKeyName)
 }
 
+var (
+	userTemplateFuncsMu sync.Mutex
+	userTemplateFuncs   = make(template.FuncMap)
+)
+
+// RegisterTemplateFunc registers an additional function that can be used in
+// the config templates. It should be called before cloudprober is
+// initialized, typically from an init() function. Functions registered this
+// way take precedence over the built-in functions with the same name.
+//
+// Like text/template's Funcs, it panics if f is not a function with one or two
+// return values (second of type error), or if name is not a valid identifier.
+//
+// Example usage:
+//
+//	config.RegisterTemplateFunc("inventoryHosts", func(service string) ([]string, error) {
+//		return inventory.Hosts(service)
+//	})
+//
+// Corresponding config:
+//
+//	probe {
+//	  name: "service-a"
+//	  type: HTTP
+//	  targets {
+//	    host_names: "{{ inventoryHosts "service-a" | join "," }}"
+//	  }
+//	}
+func RegisterTemplateFunc(name string, f any) {
+	// Validate the function right away, instead of at config parsing time.
+	template.New("").Funcs(template.FuncMap{name: f})
+
+	userTemplateFuncsMu.Lock()
+	defer userTemplateFuncsMu.Unlock()
+	userTemplateFuncs[name] = f
+}
+
 // DefaultConfig returns the default config string.
 func DefaultConfig() string {
 	b, _ := prototext.Marshal(&configpb.ProberConfig{})
@@ -186,7 +285,21 @@ func parseTemplate(config string, tmplVars map[string]any, getGCECustomMetadata
 			return matches[n], nil
 		},
 		"envSecret": func(s string) string { return "**$" + s + "**" },
-		"configDir": func() string { return filepath.Dir(state.ConfigFilePath()) },
+		"mustEnv": func(s string) (string, error) {
+			v, ok := os.LookupEnv(s)
+			if !ok {
+				return "", fmt.Errorf("environment variable %s is not set", s)
+			}
+			return v, nil
+		},
+		"configDir": func() string { return configFileDir(state.ConfigFilePath()) },
+		"urlHost": func(host string) string {
+			if ip := net.ParseIP(host); ip != nil && ip.To4() == nil {
+				return "[" + host + "]"
+			}
+			return host
+		},
+		"joinHostPort": net.JoinHostPort,
 	}
 
 	for name, f := range sprig.TxtFuncMap() {
@@ -195,6 +308,12 @@ func parseTemplate(config string, tmplVars map[string]any, getGCECustomMetadata
 	funcMap["mkSlice"] = funcMap["list"]
 	funcMap["mkMap"] = funcMap["dict"]
 
+	userTemplateFuncsMu.Lock()
+	for name, f := range userTemplateFuncs {
+		funcMap[name] = f
+	}
+	userTemplateFuncsMu.Unlock()
+
 	configTmpl, err := template.New("cloudprober_cfg").Funcs(funcMap).Parse(config)
 	if err != nil {
 		return "", err
@@ -93,6 +93,30 @@ func TestParseTemplate(t *testing.T) {
 			wantProbes:  []string{"**$SECRET_PROBE_NAME**"},
 			wantTargets: []string{"host_names:\"www.google.com\""},
 		},
+		{
+			desc: "config-with-env",
+			config: `
+				probe {
+				name: "{{ env "TEST_PROBE_NAME" }}-{{ env "TEST_UNDEFINED_VAR" }}"
+				type: PING
+				targets {
+					host_names: "{{ mustEnv "TEST_PROBE_HOST" }},{{ expandenv "${TEST_PROBE_HOST}:80" }}"
+				}
+				}
+			`,
+			wantProbes:  []string{"ping_test-"},
+			wantTargets: []string{"host_names:\"www.google.com,www.google.com:80\""},
+		},
+		{
+			desc: "config-with-must-env-error",
+			config: `
+				probe {
+				name: "{{ mustEnv "TEST_UNDEFINED_VAR" }}"
+				type: PING
+				}
+			`,
+			wantErrStr: "TEST_UNDEFINED_VAR is not set",
+		},
 		{
 			desc: "config-with-map-and-template",
 			config: `
@@ -112,6 +136,21 @@ func TestParseTemplate(t *testing.T) {
 			wantProbes:  []string{"ping_google", "ping_facebook"},
 			wantTargets: []string{"host_names:\"www.google.com\"", "host_names:\"www.facebook.com\""},
 		},
+		{
+			desc: "config-with-ipv6-funcs",
+			config: `
+				probe {
+				type: PING
+				name: "{{joinHostPort .internal_ipv6 "9313"}}"
+				targets {
+					host_names: "{{urlHost .internal_ipv6}},{{urlHost .internal_ip}},{{urlHost .hostname}}"
+				}
+				}
+			`,
+			tmplVars:    map[string]any{"internal_ipv6": "2001:db8::1", "internal_ip": "10.1.1.1", "hostname": "host1"},
+			wantProbes:  []string{"[2001:db8::1]:9313"},
+			wantTargets: []string{"host_names:\"[2001:db8::1],10.1.1.1,host1\""},
+		},
 		{
 			desc: "config-with-template-error",
 			config: `
@@ -139,6 +178,9 @@ func TestParseTemplate(t *testing.T) {
 		},
 	}
 
+	t.Setenv("TEST_PROBE_NAME", "ping_test")
+	t.Setenv("TEST_PROBE_HOST", "www.google.com")
+
 	for _, tt := range tests {
 		t.Run(tt.desc, func(t *testing.T) {
 			if tt.tmplVars == nil {
@@ -233,3 +275,31 @@ probe {
 	assert.Len(t, cfg.GetProbe(), 1, "number of probes")
 	assert.Equal(t, "google_dot_com_from-undefined", cfg.GetProbe()[0].GetName(), "probe name")
 }
+
+func TestRegisterTemplateFunc(t *testing.T) {
+	defer func() {
+		userTemplateFuncsMu.Lock()
+		delete(userTemplateFuncs, "inventoryHosts")
+		delete(userTemplateFuncs, "upper")
+		userTemplateFuncsMu.Unlock()
+	}()
+
+	RegisterTemplateFunc("inventoryHosts", func(service string) ([]string, error) {
+		if service != "svc-a" {
+			return nil, fmt.Errorf("unknown service: %s", service)
+		}
+		return []string{"host1", "host2"}, nil
+	})
+	// Registered functions override the built-in ones.
+	RegisterTemplateFunc("upper", func(s string) string { return s + "!" })
+
+	textConfig, err := parseTemplate(`host_names: "{{ inventoryHosts "svc-a" | join "," }}" name: "{{ upper "x" }}"`, nil, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, `host_names: "host1,host2" name: "x!"`, textConfig)
+
+	_, err = parseTemplate(`{{ inventoryHosts "svc-b" }}`, nil, nil)
+	assert.ErrorContains(t, err, "unknown service")
+
+	assert.Panics(t, func() { RegisterTemplateFunc("bad", "not-a-func") })
+	assert.Panics(t, func() { RegisterTemplateFunc("bad-name", func() string { return "" }) })
+}
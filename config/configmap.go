@@ -0,0 +1,258 @@
+// Copyright 2025 The Cloudprober Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cloudprober/cloudprober/common/metadata"
+	"github.com/cloudprober/cloudprober/common/oauth"
+	"github.com/cloudprober/cloudprober/logger"
+	"golang.org/x/oauth2"
+)
+
+// Variables defined by Kubernetes spec to find out local CA cert.
+var k8sLocalCACert = "/var/run/secrets/kubernetes.io/serviceaccount/ca.crt"
+
+// Wait time before re-establishing a broken ConfigMap watch.
+var configMapRewatchDelay = 10 * time.Second
+
+type configMapObject struct {
+	Metadata struct {
+		ResourceVersion string `json:"resourceVersion"`
+	} `json:"metadata"`
+	Data map[string]string `json:"data"`
+}
+
+type configMapEvent struct {
+	Type   string          `json:"type"`
+	Object configMapObject `json:"object"`
+}
+
+// configMapSource reads the config from a Kubernetes ConfigMap through the
+// Kubernetes API, and watches it for changes.
+type configMapSource struct {
+	namespace, name, key string
+
+	baseURL string
+	httpC   *http.Client
+	l       *logger.Logger
+
+	mu              sync.Mutex
+	data            string
+	resourceVersion string
+}
+
+func newConfigMapSource(spec, key string) *configMapSource {
+	cms := &configMapSource{
+		name: spec,
+		key:  key,
+	}
+	if ns, name, ok := strings.Cut(spec, "/"); ok {
+		cms.namespace, cms.name = ns, name
+	}
+	return cms
+}
+
+// initClient initializes an in-cluster Kubernetes API client.
+func (cms *configMapSource) initClient() error {
+	if cms.namespace == "" {
+		if cms.namespace = strings.TrimSpace(metadata.KubernetesNamespace()); cms.namespace == "" {
+			return errors.New("ConfigMap namespace not specified and couldn't determine pod's namespace")
+		}
+	}
+
+	host, port := os.Getenv("KUBERNETES_SERVICE_HOST"), os.Getenv("KUBERNETES_SERVICE_PORT")
+	if host == "" || port == "" {
+		return errors.New("not running in cluster: KUBERNETES_SERVICE_HOST and KUBERNETES_SERVICE_PORT environment variables not set")
+	}
+	cms.baseURL = "https://" + net.JoinHostPort(host, port)
+
+	certs, err := os.ReadFile(k8sLocalCACert)
+	if err != nil {
+		return fmt.Errorf("error while reading local ca.crt file (%s): %v", k8sLocalCACert, err)
+	}
+	caCertPool := x509.NewCertPool()
+	caCertPool.AppendCertsFromPEM(certs)
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = &tls.Config{RootCAs: caCertPool}
+
+	ts, err := oauth.K8STokenSource(cms.l)
+	if err != nil {
+		return fmt.Errorf("error while creating token source from k8s token file: %v", err)
+	}
+
+	cms.httpC = &http.Client{
+		Transport: &oauth2.Transport{Source: ts, Base: transport},
+	}
+	return nil
+}
+
+func (cms *configMapSource) get(ctx context.Context, path string, query url.Values) (*http.Response, error) {
+	u := fmt.Sprintf("%s/api/v1/namespaces/%s/configmaps%s", cms.baseURL, cms.namespace, path)
+	if len(query) != 0 {
+		u += "?" + query.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := cms.httpC.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("HTTP response status code: %d, status: %s", resp.StatusCode, resp.Status)
+	}
+	return resp, nil
+}
+
+// read fetches the ConfigMap and returns the config stored under the
+// configured key.
+func (cms *configMapSource) read(ctx context.Context) (string, error) {
+	if cms.httpC == nil {
+		if err := cms.initClient(); err != nil {
+			return "", err
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	resp, err := cms.get(ctx, "/"+cms.name, nil)
+	if err != nil {
+		return "", fmt.Errorf("error getting ConfigMap %s/%s: %v", cms.namespace, cms.name, err)
+	}
+	defer resp.Body.Close()
+
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+
+	var obj configMapObject
+	if err := json.Unmarshal(b, &obj); err != nil {
+		return "", fmt.Errorf("error parsing ConfigMap %s/%s: %v", cms.namespace, cms.name, err)
+	}
+
+	data, ok := obj.Data[cms.key]
+	if !ok {
+		return "", fmt.Errorf("key %s not found in ConfigMap %s/%s", cms.key, cms.namespace, cms.name)
+	}
+
+	cms.mu.Lock()
+	defer cms.mu.Unlock()
+	cms.data, cms.resourceVersion = data, obj.Metadata.ResourceVersion
+
+	return data, nil
+}
+
+// watchOnce runs a single watch request, and sends a signal on the changes
+// channel whenever the config data changes. It returns when the watch ends.
+func (cms *configMapSource) watchOnce(ctx context.Context, changes chan<- struct{}) error {
+	cms.mu.Lock()
+	query := url.Values{
+		"watch":           {"true"},
+		"fieldSelector":   {"metadata.name=" + cms.name},
+		"resourceVersion": {cms.resourceVersion},
+	}
+	cms.mu.Unlock()
+
+	resp, err := cms.get(ctx, "", query)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	dec := json.NewDecoder(resp.Body)
+	for {
+		var ev configMapEvent
+		if err := dec.Decode(&ev); err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+
+		switch ev.Type {
+		case "ADDED", "MODIFIED":
+		case "ERROR":
+			// Most likely our resource version is too old. Start afresh, we
+			// compare the data anyway before signaling a change.
+			cms.mu.Lock()
+			cms.resourceVersion = ""
+			cms.mu.Unlock()
+			return fmt.Errorf("watch error: %v", ev.Object)
+		default:
+			continue
+		}
+
+		cms.mu.Lock()
+		cms.resourceVersion = ev.Object.Metadata.ResourceVersion
+		changed := ev.Object.Data[cms.key] != cms.data
+		cms.mu.Unlock()
+
+		if changed {
+			cms.l.Infof("ConfigMap %s/%s changed (resourceVersion: %s)", cms.namespace, cms.name, ev.Object.Metadata.ResourceVersion)
+			select {
+			case changes <- struct{}{}:
+			default:
+			}
+		}
+	}
+}
+
+// watch watches the ConfigMap for changes until the context is canceled.
+func (cms *configMapSource) watch(ctx context.Context) <-chan struct{} {
+	changes := make(chan struct{}, 1)
+
+	go func() {
+		for {
+			err := cms.watchOnce(ctx, changes)
+			if ctx.Err() != nil {
+				return
+			}
+			// API server closes watches periodically, re-watch right away.
+			if err == nil {
+				continue
+			}
+			cms.l.Warningf("Error watching ConfigMap %s/%s: %v", cms.namespace, cms.name, err)
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(configMapRewatchDelay):
+			}
+		}
+	}()
+
+	return changes
+}
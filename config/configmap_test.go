@@ -0,0 +1,124 @@
+// Copyright 2025 The Cloudprober Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeConfigMapServer implements just enough of the Kubernetes API to get and
+// watch a ConfigMap.
+type fakeConfigMapServer struct {
+	mu      sync.Mutex
+	version int
+	data    map[string]string
+	events  chan string
+}
+
+func (fs *fakeConfigMapServer) object() configMapObject {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	var obj configMapObject
+	obj.Metadata.ResourceVersion = fmt.Sprint(fs.version)
+	obj.Data = fs.data
+	return obj
+}
+
+func (fs *fakeConfigMapServer) update(data map[string]string) {
+	fs.mu.Lock()
+	fs.version++
+	fs.data = data
+	fs.mu.Unlock()
+
+	b, _ := json.Marshal(configMapEvent{Type: "MODIFIED", Object: fs.object()})
+	fs.events <- string(b)
+}
+
+func (fs *fakeConfigMapServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.URL.Path {
+	case "/api/v1/namespaces/ns/configmaps/cm":
+		json.NewEncoder(w).Encode(fs.object())
+	case "/api/v1/namespaces/ns/configmaps":
+		if r.URL.Query().Get("watch") != "true" || r.URL.Query().Get("fieldSelector") != "metadata.name=cm" {
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+		w.(http.Flusher).Flush()
+		for {
+			select {
+			case <-r.Context().Done():
+				return
+			case ev := <-fs.events:
+				fmt.Fprintln(w, ev)
+				w.(http.Flusher).Flush()
+			}
+		}
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func TestConfigMapSource(t *testing.T) {
+	fs := &fakeConfigMapServer{
+		data:   map[string]string{"cloudprober.cfg": "probe {}"},
+		events: make(chan string, 10),
+	}
+	ts := httptest.NewServer(fs)
+	defer ts.Close()
+
+	cms := newConfigMapSource("ns/cm", "cloudprober.cfg")
+	assert.Equal(t, "ns", cms.namespace)
+	assert.Equal(t, "cm", cms.name)
+	cms.baseURL, cms.httpC = ts.URL, ts.Client()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	data, err := cms.read(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, "probe {}", data)
+
+	changes := cms.watch(ctx)
+
+	// Changes to other keys should not trigger a reload.
+	fs.update(map[string]string{"cloudprober.cfg": "probe {}", "other": "x"})
+	// Config change should.
+	fs.update(map[string]string{"cloudprober.cfg": "probe {}\nprobe {}"})
+
+	select {
+	case <-changes:
+	case <-time.After(10 * time.Second):
+		t.Fatal("timed out waiting for the ConfigMap change")
+	}
+
+	data, err = cms.read(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, "probe {}\nprobe {}", data)
+	assert.Len(t, changes, 0, "unexpected extra change notification")
+
+	// Missing key.
+	cms.key = "missing.cfg"
+	_, err = cms.read(ctx)
+	assert.Error(t, err)
+}
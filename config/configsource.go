@@ -15,8 +15,11 @@
 package config
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os"
+	"time"
 
 	"cloud.google.com/go/compute/metadata"
 	configpb "github.com/cloudprober/cloudprober/config/proto"
@@ -33,6 +36,16 @@ type ConfigSource interface {
 	ParsedConfig() string
 }
 
+// WatchableConfigSource is implemented by the config sources that can detect
+// config changes. Watch returns a channel that receives a value every time
+// the underlying config changes, until the context is canceled. It returns
+// nil if watching is not enabled. It's up to the caller to call GetConfig()
+// to load the new config.
+type WatchableConfigSource interface {
+	ConfigSource
+	Watch(ctx context.Context) <-chan struct{}
+}
+
 type Option func(ConfigSource) ConfigSource
 
 func WithBaseVars(vars map[string]any) Option {
@@ -57,9 +70,58 @@ func WithSurfacerConfig(sconfig string) Option {
 	}
 }
 
+// WithReloadInterval sets the interval at which config file is checked for
+// changes. A zero interval disables config file watching.
+func WithReloadInterval(interval time.Duration) Option {
+	return func(cs ConfigSource) ConfigSource {
+		dcs, ok := cs.(*defaultConfigSource)
+		if !ok {
+			return cs
+		}
+		dcs.reloadInterval = interval
+		return dcs
+	}
+}
+
+// WithK8sConfigMap makes config source read the config from the given
+// Kubernetes ConfigMap's key, instead of a file. ConfigMap is specified as
+// <namespace>/<name>, or just <name> for the pod's namespace. ConfigMap is
+// watched for changes through the Kubernetes API.
+func WithK8sConfigMap(configMap, key string) Option {
+	return func(cs ConfigSource) ConfigSource {
+		dcs, ok := cs.(*defaultConfigSource)
+		if !ok {
+			return cs
+		}
+		dcs.cm = newConfigMapSource(configMap, key)
+		return dcs
+	}
+}
+
+// WithGCPRuntimeConfig makes config source read the config from the given
+// GCP Runtime Config variable, instead of a file. Variable is specified by its
+// full name, projects/<project>/configs/<config>/variables/<variable>, or as
+// <config>/<variable> for the current project. Variable is watched for
+// changes through the Runtime Config API.
+func WithGCPRuntimeConfig(variable string) Option {
+	return func(cs ConfigSource) ConfigSource {
+		dcs, ok := cs.(*defaultConfigSource)
+		if !ok {
+			return cs
+		}
+		dcs.rc = newRuntimeConfigSource(variable)
+		return dcs
+	}
+}
+
 type defaultConfigSource struct {
 	fileName                string
+	content                 *string // Config content, if provided directly.
+	contentFormat           string
 	surfacersConfigFileName string
+	reloadInterval          time.Duration
+	cm                      *configMapSource
+	rc                      *runtimeConfigSource
 	baseVars                map[string]any
 	getGCECustomMetadata    func(string) (string, error)
 	l                       *logger.Logger
@@ -70,6 +132,20 @@ type defaultConfigSource struct {
 }
 
 func (dcs *defaultConfigSource) configContent() (content string, format string, err error) {
+	if dcs.content != nil {
+		return *dcs.content, dcs.contentFormat, nil
+	}
+
+	if dcs.cm != nil {
+		content, err := dcs.cm.read(context.Background())
+		return content, formatFromFileName(dcs.cm.key), err
+	}
+
+	if dcs.rc != nil {
+		content, err := dcs.rc.read(context.Background())
+		return content, formatFromFileName(dcs.rc.key()), err
+	}
+
 	if dcs.fileName != "" {
 		content, err := readConfigFile(dcs.fileName)
 		return content, formatFromFileName(dcs.fileName), err
@@ -89,20 +165,33 @@ func (dcs *defaultConfigSource) configContent() (content string, format string,
 }
 
 func (dcs *defaultConfigSource) GetConfig() (*configpb.ProberConfig, error) {
-	// Figure out which file to read
-	if dcs.fileName == "" {
-		dcs.fileName = *configFile
-	}
+	if dcs.content != nil {
+		if dcs.cm != nil || dcs.rc != nil {
+			return nil, errors.New("config content cannot be used together with Kubernetes ConfigMap or GCP Runtime Config")
+		}
+	} else if dcs.cm != nil || dcs.rc != nil {
+		if dcs.cm != nil && dcs.rc != nil {
+			return nil, errors.New("Kubernetes ConfigMap and GCP Runtime Config cannot be used together")
+		}
+		if dcs.fileName != "" {
+			return nil, errors.New("config file cannot be used together with Kubernetes ConfigMap or GCP Runtime Config")
+		}
+	} else {
+		// Figure out which file to read
+		if dcs.fileName == "" {
+			dcs.fileName = *configFile
+		}
 
-	if dcs.fileName == "" {
-		if _, err := os.Stat(defaultConfigFile); !os.IsNotExist(err) {
-			dcs.fileName = defaultConfigFile
+		if dcs.fileName == "" {
+			if _, err := os.Stat(defaultConfigFile); !os.IsNotExist(err) {
+				dcs.fileName = defaultConfigFile
+			}
 		}
-	}
 
-	// Set the config file path in state. This can be used to find files
-	// relative to the config file.
-	state.SetConfigFilePath(dcs.fileName)
+		// Set the config file path in state. This can be used to find files
+		// relative to the config file.
+		state.SetConfigFilePath(dcs.fileName)
+	}
 
 	tmplVars := make(map[string]any)
 	for k, v := range dcs.baseVars {
@@ -146,6 +235,10 @@ func (dcs *defaultConfigSource) GetConfig() (*configpb.ProberConfig, error) {
 		dcs.cfg.Surfacer = append(dcs.cfg.Surfacer, sConfig.GetSurfacer()...)
 	}
 
+	if err := checkMergedConfig(dcs.cfg); err != nil {
+		return nil, fmt.Errorf("error in config: %v", err)
+	}
+
 	return dcs.cfg, nil
 }
 
@@ -156,3 +249,70 @@ func (dcs *defaultConfigSource) RawConfig() string {
 func (dcs *defaultConfigSource) ParsedConfig() string {
 	return dcs.parsedConfig
 }
+
+// fileContent returns the current content of the config files. It's used to
+// detect config changes.
+func (dcs *defaultConfigSource) fileContent() (string, error) {
+	content, err := readConfigFile(dcs.fileName)
+	if err != nil {
+		return "", err
+	}
+	if dcs.surfacersConfigFileName != "" {
+		sContent, err := readConfigFile(dcs.surfacersConfigFileName)
+		if err != nil {
+			return "", err
+		}
+		content += "\n\n" + sContent
+	}
+	return content, nil
+}
+
+// Watch watches the config for changes. ConfigMap and Runtime Config configs
+// are watched through their APIs, while config files (including ConfigMaps
+// mounted as files) are checked for changes at the reload interval.
+func (dcs *defaultConfigSource) Watch(ctx context.Context) <-chan struct{} {
+	if dcs.cm != nil {
+		return dcs.cm.watch(ctx)
+	}
+	if dcs.rc != nil {
+		return dcs.rc.watch(ctx)
+	}
+
+	if dcs.reloadInterval == 0 || dcs.fileName == "" {
+		return nil
+	}
+
+	changes := make(chan struct{}, 1)
+	lastContent := dcs.rawConfig
+
+	go func() {
+		ticker := time.NewTicker(dcs.reloadInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			content, err := dcs.fileContent()
+			if err != nil {
+				dcs.l.Warningf("Error reading config file %s: %v", dcs.fileName, err)
+				continue
+			}
+			if content == lastContent {
+				continue
+			}
+			lastContent = content
+
+			dcs.l.Infof("Config file %s changed", dcs.fileName)
+			select {
+			case changes <- struct{}{}:
+			default:
+			}
+		}
+	}()
+
+	return changes
+}
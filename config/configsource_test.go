@@ -15,9 +15,16 @@
 package config
 
 import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"runtime"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	configpb "github.com/cloudprober/cloudprober/config/proto"
 	surfacerspb "github.com/cloudprober/cloudprober/internal/surfacers/proto"
@@ -150,3 +157,124 @@ surfacer {
 		})
 	}
 }
+
+func TestDefaultConfigSourceWatch(t *testing.T) {
+	cfgFile := filepath.Join(t.TempDir(), "cloudprober.cfg")
+	assert.NoError(t, os.WriteFile(cfgFile, []byte(`probe { name: "p1" type: DNS }`), 0644))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Watch is disabled without a reload interval.
+	cs := ConfigSourceWithFile(cfgFile).(WatchableConfigSource)
+	assert.Nil(t, cs.Watch(ctx))
+
+	cs = ConfigSourceWithFile(cfgFile, WithReloadInterval(10*time.Millisecond)).(WatchableConfigSource)
+	cfg, err := cs.GetConfig()
+	assert.NoError(t, err)
+	assert.Len(t, cfg.GetProbe(), 1)
+
+	changes := cs.Watch(ctx)
+	assert.NotNil(t, changes)
+
+	// No change, no notification.
+	time.Sleep(50 * time.Millisecond)
+	assert.Len(t, changes, 0)
+
+	assert.NoError(t, os.WriteFile(cfgFile, []byte(`probe { name: "p1" type: DNS } probe { name: "p2" type: DNS }`), 0644))
+	select {
+	case <-changes:
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the config change")
+	}
+
+	cfg, err = cs.GetConfig()
+	assert.NoError(t, err)
+	assert.Len(t, cfg.GetProbe(), 2)
+}
+
+func TestRemoteConfigSource(t *testing.T) {
+	var mu sync.Mutex
+	files := map[string]string{
+		"/configs/cloudprober.yaml": "probe:\n  - name: p1\n    type: DNS\n",
+		"/configs/cloudprober.cfg":  "include \"teams/team1.cfg\"\n",
+		"/configs/teams/team1.cfg":  `probe { name: "team1_p1" type: DNS }`,
+		"/configs/cycle.cfg":        "include \"teams/../cycle.cfg\"\n",
+		"/configs/teams/team2.cfg":  "include \"../common.cfg\"\n",
+		"/configs/common.cfg":       `probe { name: "common_p1" type: DNS }`,
+	}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		content, ok := files[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(content))
+	}))
+	defer ts.Close()
+
+	// Format is determined from the URL path.
+	cs := ConfigSourceWithFile(ts.URL + "/configs/cloudprober.yaml?version=1")
+	cfg, err := cs.GetConfig()
+	assert.NoError(t, err)
+	assert.Equal(t, "p1", cfg.GetProbe()[0].GetName())
+
+	// Includes are relative to the config URL.
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	wcs := ConfigSourceWithFile(ts.URL+"/configs/cloudprober.cfg", WithReloadInterval(10*time.Millisecond)).(WatchableConfigSource)
+	cfg, err = wcs.GetConfig()
+	assert.NoError(t, err)
+	assert.Equal(t, "team1_p1", cfg.GetProbe()[0].GetName())
+
+	// Remote config is re-fetched at the reload interval.
+	changes := wcs.Watch(ctx)
+	assert.NotNil(t, changes)
+	mu.Lock()
+	files["/configs/teams/team1.cfg"] = `probe { name: "team1_p2" type: DNS }`
+	mu.Unlock()
+	select {
+	case <-changes:
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the config change")
+	}
+	cfg, err = wcs.GetConfig()
+	assert.NoError(t, err)
+	assert.Equal(t, "team1_p2", cfg.GetProbe()[0].GetName())
+
+	_, err = ConfigSourceWithFile(ts.URL + "/configs/missing.cfg").GetConfig()
+	assert.Error(t, err)
+
+	// Include paths are resolved against the including file's URL.
+	cfg, err = ConfigSourceWithFile(ts.URL + "/configs/teams/team2.cfg").GetConfig()
+	assert.NoError(t, err)
+	assert.Equal(t, "common_p1", cfg.GetProbe()[0].GetName())
+
+	// Include cycles are caught even if the paths don't match literally.
+	_, err = ConfigSourceWithFile(ts.URL + "/configs/cycle.cfg").GetConfig()
+	assert.ErrorContains(t, err, "include cycle")
+}
+
+func TestConfigFileAndConfigMap(t *testing.T) {
+	cs := ConfigSourceWithFile("testdata/cloudprober.cfg", WithK8sConfigMap("ns/cm", "cloudprober.cfg"))
+	_, err := cs.GetConfig()
+	assert.Error(t, err)
+}
+
+func TestConfigSourceWithContent(t *testing.T) {
+	cs := ConfigSourceWithContent("probe {\n  name: \"{{ .probe_name }}\"\n  type: DNS\n}", "", WithBaseVars(map[string]any{"probe_name": "dns_test"}))
+	cfg, err := cs.GetConfig()
+	assert.NoError(t, err)
+	assert.Equal(t, "dns_test", cfg.GetProbe()[0].GetName())
+	assert.Nil(t, cs.(WatchableConfigSource).Watch(context.Background()))
+
+	cs = ConfigSourceWithContent("probe:\n  - name: dns_test\n    type: DNS\n", "yaml")
+	cfg, err = cs.GetConfig()
+	assert.NoError(t, err)
+	assert.Equal(t, "dns_test", cfg.GetProbe()[0].GetName())
+
+	_, err = ConfigSourceWithContent("probe {", "").GetConfig()
+	assert.Error(t, err)
+}
@@ -115,6 +115,8 @@ type ProberConfig struct {
 	DisableJitter *bool `protobuf:"varint,102,opt,name=disable_jitter,json=disableJitter,def=0" json:"disable_jitter,omitempty"`
 	// How often to export system variables. To learn more about system variables:
 	// http://godoc.org/github.com/cloudprober/cloudprober/internal/sysvars.
+	// Cloudprober's own metrics (probe="cloudprober") are exported at the same
+	// interval.
 	SysvarsIntervalMsec *int32 `protobuf:"varint,97,opt,name=sysvars_interval_msec,json=sysvarsIntervalMsec,def=10000" json:"sysvars_interval_msec,omitempty"`
 	// Variables specified in this environment variable are exported as it is.
 	// This is specifically useful to export information about system environment,
@@ -148,8 +150,108 @@ type ProberConfig struct {
 	//	  }
 	//	}
 	GlobalArtifactsOptions *proto6.ArtifactsOptions `protobuf:"bytes,103,opt,name=global_artifacts_options,json=globalArtifactsOptions" json:"global_artifacts_options,omitempty"`
-	unknownFields          protoimpl.UnknownFields
-	sizeCache              protoimpl.SizeCache
+	// Probe namespaces. Namespaces make it possible for a shared cloudprober
+	// instance to serve multiple teams. Probes are assigned to a namespace using
+	// the probe's "namespace" field. Example:
+	//
+	//	namespace {
+	//	  name: "team-a"
+	//	  label_prefix: "team_a_"
+	//	  surfacer: "team-a-prometheus"
+	//	  max_probes: 50
+	//	}
+	//
+	//	probe {
+	//	  name: "team-a-frontend"
+	//	  namespace: "team-a"
+	//	  ...
+	//	}
+	Namespace []*Namespace `protobuf:"bytes,106,rep,name=namespace" json:"namespace,omitempty"`
+	// Maximum number of outbound probe operations (probe runs for a target),
+	// per second, across all probes. This is a safeguard against misconfigured
+	// probes, e.g. a probe with a very small interval and a large number of
+	// targets, overwhelming the targets or tripping intrusion detection
+	// systems. Probe runs over the limit wait until they are allowed, i.e.
+	// they are delayed, not dropped. 0 means no limit.
+	// Note: This is enforced for all probe types except EXTERNAL and the
+	// extension probes. PING and UDP probes probe all their targets in one go;
+	// for them, each run waits for as many operations as the number of targets.
+	MaxOutboundOpsPerSec *float32 `protobuf:"fixed32,107,opt,name=max_outbound_ops_per_sec,json=maxOutboundOpsPerSec" json:"max_outbound_ops_per_sec,omitempty"`
+	// Sysvars to add as labels to all probe results, e.g. GCE instance labels
+	// (label_<key>) or custom metadata (metadata_<key>) sysvars. Label key is
+	// the same as the sysvar name. Sysvars that are not set are skipped, and
+	// probe's own additional labels take precedence.
+	// Example:
+	//
+	//	sysvars_as_labels: "label_tier"
+	//	sysvars_as_labels: "zone"
+	SysvarsAsLabels []string `protobuf:"bytes,108,rep,name=sysvars_as_labels,json=sysvarsAsLabels" json:"sysvars_as_labels,omitempty"`
+	// Size of the buffer for the metrics flowing from the probes to the
+	// surfacers. If the buffer fills up, e.g. because surfacers can't keep up,
+	// probes block while writing their results, unless
+	// drop_metrics_on_full_buffer is set. Buffer's state is exported through
+	// the following metrics, as part of the cloudprober's own metrics (exported
+	// with probe="cloudprober" label):
+	//
+	//	metrics_buffer_depth: number of EventMetrics in the buffer.
+	//	metrics_buffer_capacity: buffer size.
+	//	metrics_buffer_blocked_writes: writes that had to wait for room.
+	//	metrics_buffer_dropped: EventMetrics dropped because buffer was full.
+	MetricsBufferSize *int32 `protobuf:"varint,109,opt,name=metrics_buffer_size,json=metricsBufferSize,def=100000" json:"metrics_buffer_size,omitempty"`
+	// Drop probe results, instead of blocking the probes, if the metrics buffer
+	// is full. This keeps the probes running on schedule if surfacers stall.
+	// Note: this applies only to the probe results, other metrics, e.g.
+	// sysvars, always wait for room in the buffer.
+	DropMetricsOnFullBuffer *bool `protobuf:"varint,110,opt,name=drop_metrics_on_full_buffer,json=dropMetricsOnFullBuffer" json:"drop_metrics_on_full_buffer,omitempty"`
+	// Window over which the probe starts are spread out, e.g. "1m". By default,
+	// probes with the same interval are spread out over that interval (capped
+	// at 1 minute). If this option is set, all probes are spread out evenly
+	// over this window instead. It's ignored if disable_jitter is set. Probes
+	// can override it using their start_jitter option.
+	ProbeStartWindow *string `protobuf:"bytes,111,opt,name=probe_start_window,json=probeStartWindow" json:"probe_start_window,omitempty"`
+	// Token for the admin endpoints, used to pause and resume probes, and to
+	// put the instance in the lameduck mode. Requests to the admin endpoints
+	// should include it as a bearer token: "Authorization: Bearer <token>". For
+	// the gRPC methods, it goes in the "authorization" metadata.
+	//
+	// Admin HTTP endpoints (/admin/pause, /admin/resume and /admin/lameduck)
+	// are enabled only if this token is set. Admin gRPC methods check it if
+	// it's set, otherwise they are available only to the clients connecting
+	// from localhost.
+	//
+	// To avoid putting the token in the config, you can get it from an
+	// environment variable: admin_token: "**$ADMIN_TOKEN**"
+	AdminToken *string `protobuf:"bytes,112,opt,name=admin_token,json=adminToken" json:"admin_token,omitempty"`
+	// Maximum number of probe runs (probe runs for a target) in progress at the
+	// same time, across all probes. Along with max_outbound_ops_per_sec, it
+	// keeps a config with thousands of targets from saturating the host's
+	// network or connection tracking table. Probe runs over the limit wait for
+	// a slot, and the time spent waiting for the slots and the rate limits is
+	// exported through the following metrics, as part of the cloudprober's own
+	// metrics (exported with probe="cloudprober" label):
+	//
+	//	queued_runs: probe runs that had to wait.
+	//	queue_delay_usec: cumulative time spent waiting.
+	//	runs_in_flight, max_concurrent_runs: probe runs in progress, and the
+	//	  limit (only if limit is set).
+	//
+	// 0 means no limit.
+	// Note: This is currently enforced only for the probe types that use the
+	// common scheduler: HTTP, TCP, DNS, GRPC, BROWSER, SCRIPT and TRANSACTION.
+	MaxConcurrentProbeRuns *int32 `protobuf:"varint,113,opt,name=max_concurrent_probe_runs,json=maxConcurrentProbeRuns" json:"max_concurrent_probe_runs,omitempty"`
+	// Port for a dedicated debug HTTP server, serving the pprof
+	// (/debug/pprof/) and expvar (/debug/vars) handlers, e.g. to grab CPU and
+	// heap profiles from a long-running instance:
+	//
+	//	go tool pprof http://<host>:<debug_port>/debug/pprof/profile
+	//
+	// It listens on the same host as the default server. If this port is set,
+	// debug handlers are served only on this port. Otherwise, they are served
+	// on the default server, unless the environment variable
+	// CLOUDPROBER_DISABLE_HTTP_PPROF is set.
+	DebugPort     *int32 `protobuf:"varint,114,opt,name=debug_port,json=debugPort" json:"debug_port,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
 // Default values for ProberConfig fields.
@@ -158,6 +260,7 @@ const (
 	Default_ProberConfig_SysvarsIntervalMsec = int32(10000)
 	Default_ProberConfig_SysvarsEnvVar       = string("SYSVARS")
 	Default_ProberConfig_StopTimeSec         = int32(5)
+	Default_ProberConfig_MetricsBufferSize   = int32(100000)
 )
 
 func (x *ProberConfig) Reset() {
@@ -295,6 +398,167 @@ func (x *ProberConfig) GetGlobalArtifactsOptions() *proto6.ArtifactsOptions {
 	return nil
 }
 
+func (x *ProberConfig) GetNamespace() []*Namespace {
+	if x != nil {
+		return x.Namespace
+	}
+	return nil
+}
+
+func (x *ProberConfig) GetMaxOutboundOpsPerSec() float32 {
+	if x != nil && x.MaxOutboundOpsPerSec != nil {
+		return *x.MaxOutboundOpsPerSec
+	}
+	return 0
+}
+
+func (x *ProberConfig) GetSysvarsAsLabels() []string {
+	if x != nil {
+		return x.SysvarsAsLabels
+	}
+	return nil
+}
+
+func (x *ProberConfig) GetMetricsBufferSize() int32 {
+	if x != nil && x.MetricsBufferSize != nil {
+		return *x.MetricsBufferSize
+	}
+	return Default_ProberConfig_MetricsBufferSize
+}
+
+func (x *ProberConfig) GetDropMetricsOnFullBuffer() bool {
+	if x != nil && x.DropMetricsOnFullBuffer != nil {
+		return *x.DropMetricsOnFullBuffer
+	}
+	return false
+}
+
+func (x *ProberConfig) GetProbeStartWindow() string {
+	if x != nil && x.ProbeStartWindow != nil {
+		return *x.ProbeStartWindow
+	}
+	return ""
+}
+
+func (x *ProberConfig) GetAdminToken() string {
+	if x != nil && x.AdminToken != nil {
+		return *x.AdminToken
+	}
+	return ""
+}
+
+func (x *ProberConfig) GetMaxConcurrentProbeRuns() int32 {
+	if x != nil && x.MaxConcurrentProbeRuns != nil {
+		return *x.MaxConcurrentProbeRuns
+	}
+	return 0
+}
+
+func (x *ProberConfig) GetDebugPort() int32 {
+	if x != nil && x.DebugPort != nil {
+		return *x.DebugPort
+	}
+	return 0
+}
+
+type Namespace struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Name  *string                `protobuf:"bytes,1,req,name=name" json:"name,omitempty"`
+	// Prefix added to the keys of the additional labels configured in the
+	// namespace's probes. This keeps labels from different namespaces from
+	// colliding with each other.
+	LabelPrefix *string `protobuf:"bytes,2,opt,name=label_prefix,json=labelPrefix" json:"label_prefix,omitempty"`
+	// Surfacers (by name) that metrics from the namespace's probes should be
+	// sent to. If not specified, metrics are sent to all surfacers.
+	Surfacer []string `protobuf:"bytes,3,rep,name=surfacer" json:"surfacer,omitempty"`
+	// Maximum number of probes in the namespace. Adding a probe beyond this
+	// limit fails. 0 means no limit.
+	MaxProbes *int32 `protobuf:"varint,4,opt,name=max_probes,json=maxProbes" json:"max_probes,omitempty"`
+	// Maximum number of EventMetrics (a set of metrics with the same labels,
+	// e.g. one probe result for one target) that the namespace's probes can
+	// export per second. EventMetrics beyond this rate are dropped. 0 means no
+	// limit.
+	MaxEventMetricsPerSec *int32 `protobuf:"varint,5,opt,name=max_event_metrics_per_sec,json=maxEventMetricsPerSec" json:"max_event_metrics_per_sec,omitempty"`
+	// Maximum number of unique time series (combination of metric names and
+	// labels) that the namespace's probes can export. Once this limit is
+	// reached, EventMetrics for new time series are dropped. 0 means no limit.
+	MaxSeries     *int32 `protobuf:"varint,6,opt,name=max_series,json=maxSeries" json:"max_series,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Namespace) Reset() {
+	*x = Namespace{}
+	mi := &file_github_com_cloudprober_cloudprober_config_proto_config_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Namespace) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Namespace) ProtoMessage() {}
+
+func (x *Namespace) ProtoReflect() protoreflect.Message {
+	mi := &file_github_com_cloudprober_cloudprober_config_proto_config_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Namespace.ProtoReflect.Descriptor instead.
+func (*Namespace) Descriptor() ([]byte, []int) {
+	return file_github_com_cloudprober_cloudprober_config_proto_config_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Namespace) GetName() string {
+	if x != nil && x.Name != nil {
+		return *x.Name
+	}
+	return ""
+}
+
+func (x *Namespace) GetLabelPrefix() string {
+	if x != nil && x.LabelPrefix != nil {
+		return *x.LabelPrefix
+	}
+	return ""
+}
+
+func (x *Namespace) GetSurfacer() []string {
+	if x != nil {
+		return x.Surfacer
+	}
+	return nil
+}
+
+func (x *Namespace) GetMaxProbes() int32 {
+	if x != nil && x.MaxProbes != nil {
+		return *x.MaxProbes
+	}
+	return 0
+}
+
+func (x *Namespace) GetMaxEventMetricsPerSec() int32 {
+	if x != nil && x.MaxEventMetricsPerSec != nil {
+		return *x.MaxEventMetricsPerSec
+	}
+	return 0
+}
+
+func (x *Namespace) GetMaxSeries() int32 {
+	if x != nil && x.MaxSeries != nil {
+		return *x.MaxSeries
+	}
+	return 0
+}
+
 type SharedTargets struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Name          *string                `protobuf:"bytes,1,req,name=name" json:"name,omitempty"`
@@ -305,7 +569,7 @@ type SharedTargets struct {
 
 func (x *SharedTargets) Reset() {
 	*x = SharedTargets{}
-	mi := &file_github_com_cloudprober_cloudprober_config_proto_config_proto_msgTypes[1]
+	mi := &file_github_com_cloudprober_cloudprober_config_proto_config_proto_msgTypes[2]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -317,7 +581,7 @@ func (x *SharedTargets) String() string {
 func (*SharedTargets) ProtoMessage() {}
 
 func (x *SharedTargets) ProtoReflect() protoreflect.Message {
-	mi := &file_github_com_cloudprober_cloudprober_config_proto_config_proto_msgTypes[1]
+	mi := &file_github_com_cloudprober_cloudprober_config_proto_config_proto_msgTypes[2]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -330,7 +594,7 @@ func (x *SharedTargets) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use SharedTargets.ProtoReflect.Descriptor instead.
 func (*SharedTargets) Descriptor() ([]byte, []int) {
-	return file_github_com_cloudprober_cloudprober_config_proto_config_proto_rawDescGZIP(), []int{1}
+	return file_github_com_cloudprober_cloudprober_config_proto_config_proto_rawDescGZIP(), []int{2}
 }
 
 func (x *SharedTargets) GetName() string {
@@ -357,7 +621,7 @@ type SurfacersConfig struct {
 
 func (x *SurfacersConfig) Reset() {
 	*x = SurfacersConfig{}
-	mi := &file_github_com_cloudprober_cloudprober_config_proto_config_proto_msgTypes[2]
+	mi := &file_github_com_cloudprober_cloudprober_config_proto_config_proto_msgTypes[3]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -369,7 +633,7 @@ func (x *SurfacersConfig) String() string {
 func (*SurfacersConfig) ProtoMessage() {}
 
 func (x *SurfacersConfig) ProtoReflect() protoreflect.Message {
-	mi := &file_github_com_cloudprober_cloudprober_config_proto_config_proto_msgTypes[2]
+	mi := &file_github_com_cloudprober_cloudprober_config_proto_config_proto_msgTypes[3]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -382,7 +646,7 @@ func (x *SurfacersConfig) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use SurfacersConfig.ProtoReflect.Descriptor instead.
 func (*SurfacersConfig) Descriptor() ([]byte, []int) {
-	return file_github_com_cloudprober_cloudprober_config_proto_config_proto_rawDescGZIP(), []int{2}
+	return file_github_com_cloudprober_cloudprober_config_proto_config_proto_rawDescGZIP(), []int{3}
 }
 
 func (x *SurfacersConfig) GetSurfacer() []*proto1.SurfacerDef {
@@ -396,7 +660,8 @@ var File_github_com_cloudprober_cloudprober_config_proto_config_proto protorefle
 
 const file_github_com_cloudprober_cloudprober_config_proto_config_proto_rawDesc = "" +
 	"\n" +
-	"<github.com/cloudprober/cloudprober/config/proto/config.proto\x12\vcloudprober\x1aFgithub.com/cloudprober/cloudprober/common/tlsconfig/proto/config.proto\x1aNgithub.com/cloudprober/cloudprober/probes/browser/artifacts/proto/config.proto\x1a<github.com/cloudprober/cloudprober/probes/proto/config.proto\x1aIgithub.com/cloudprober/cloudprober/internal/rds/server/proto/config.proto\x1aFgithub.com/cloudprober/cloudprober/internal/servers/proto/config.proto\x1aHgithub.com/cloudprober/cloudprober/internal/surfacers/proto/config.proto\x1a>github.com/cloudprober/cloudprober/targets/proto/targets.proto\"\xdb\x06\n" +
+	"<github.com/cloudprober/cloudprober/config/proto/config.proto\x12\vcloudprober\x1aFgithub.com/cloudprober/cloudprober/common/tlsconfig/proto/config.proto\x1aNgithub.com/cloudprober/cloudprober/probes/browser/artifacts/proto/config.proto\x1a<github.com/cloudprober/cloudprober/probes/proto/config.proto\x1aIgithub.com/cloudprober/cloudprober/internal/rds/server/proto/config.proto\x1aFgithub.com/cloudprober/cloudprober/internal/servers/proto/config.proto\x1aHgithub.com/cloudprober/cloudprober/internal/surfacers/proto/config.proto\x1a>github.com/cloudprober/cloudprober/targets/proto/targets.proto\"\x94\n" +
+	"\n" +
 	"\fProberConfig\x122\n" +
 	"\x05probe\x18\x01 \x03(\v2\x1c.cloudprober.probes.ProbeDefR\x05probe\x12=\n" +
 	"\bsurfacer\x18\x02 \x03(\v2!.cloudprober.surfacer.SurfacerDefR\bsurfacer\x126\n" +
@@ -413,7 +678,27 @@ const file_github_com_cloudprober_cloudprober_config_proto_config_proto_rawDesc
 	"\x0fsysvars_env_var\x18b \x01(\t:\aSYSVARSR\rsysvarsEnvVar\x12%\n" +
 	"\rstop_time_sec\x18c \x01(\x05:\x015R\vstopTimeSec\x12_\n" +
 	"\x16global_targets_options\x18d \x01(\v2).cloudprober.targets.GlobalTargetsOptionsR\x14globalTargetsOptions\x12p\n" +
-	"\x18global_artifacts_options\x18g \x01(\v26.cloudprober.probes.browser.artifacts.ArtifactsOptionsR\x16globalArtifactsOptions\"^\n" +
+	"\x18global_artifacts_options\x18g \x01(\v26.cloudprober.probes.browser.artifacts.ArtifactsOptionsR\x16globalArtifactsOptions\x124\n" +
+	"\tnamespace\x18j \x03(\v2\x16.cloudprober.NamespaceR\tnamespace\x126\n" +
+	"\x18max_outbound_ops_per_sec\x18k \x01(\x02R\x14maxOutboundOpsPerSec\x12*\n" +
+	"\x11sysvars_as_labels\x18l \x03(\tR\x0fsysvarsAsLabels\x126\n" +
+	"\x13metrics_buffer_size\x18m \x01(\x05:\x06100000R\x11metricsBufferSize\x12<\n" +
+	"\x1bdrop_metrics_on_full_buffer\x18n \x01(\bR\x17dropMetricsOnFullBuffer\x12,\n" +
+	"\x12probe_start_window\x18o \x01(\tR\x10probeStartWindow\x12\x1f\n" +
+	"\vadmin_token\x18p \x01(\tR\n" +
+	"adminToken\x129\n" +
+	"\x19max_concurrent_probe_runs\x18q \x01(\x05R\x16maxConcurrentProbeRuns\x12\x1d\n" +
+	"\n" +
+	"debug_port\x18r \x01(\x05R\tdebugPort\"\xd6\x01\n" +
+	"\tNamespace\x12\x12\n" +
+	"\x04name\x18\x01 \x02(\tR\x04name\x12!\n" +
+	"\flabel_prefix\x18\x02 \x01(\tR\vlabelPrefix\x12\x1a\n" +
+	"\bsurfacer\x18\x03 \x03(\tR\bsurfacer\x12\x1d\n" +
+	"\n" +
+	"max_probes\x18\x04 \x01(\x05R\tmaxProbes\x128\n" +
+	"\x19max_event_metrics_per_sec\x18\x05 \x01(\x05R\x15maxEventMetricsPerSec\x12\x1d\n" +
+	"\n" +
+	"max_series\x18\x06 \x01(\x05R\tmaxSeries\"^\n" +
 	"\rSharedTargets\x12\x12\n" +
 	"\x04name\x18\x01 \x02(\tR\x04name\x129\n" +
 	"\atargets\x18\x02 \x02(\v2\x1f.cloudprober.targets.TargetsDefR\atargets\"P\n" +
@@ -432,36 +717,38 @@ func file_github_com_cloudprober_cloudprober_config_proto_config_proto_rawDescGZ
 	return file_github_com_cloudprober_cloudprober_config_proto_config_proto_rawDescData
 }
 
-var file_github_com_cloudprober_cloudprober_config_proto_config_proto_msgTypes = make([]protoimpl.MessageInfo, 3)
+var file_github_com_cloudprober_cloudprober_config_proto_config_proto_msgTypes = make([]protoimpl.MessageInfo, 4)
 var file_github_com_cloudprober_cloudprober_config_proto_config_proto_goTypes = []any{
 	(*ProberConfig)(nil),                // 0: cloudprober.ProberConfig
-	(*SharedTargets)(nil),               // 1: cloudprober.SharedTargets
-	(*SurfacersConfig)(nil),             // 2: cloudprober.SurfacersConfig
-	(*proto.ProbeDef)(nil),              // 3: cloudprober.probes.ProbeDef
-	(*proto1.SurfacerDef)(nil),          // 4: cloudprober.surfacer.SurfacerDef
-	(*proto2.ServerDef)(nil),            // 5: cloudprober.servers.ServerDef
-	(*proto3.ServerConf)(nil),           // 6: cloudprober.rds.ServerConf
-	(*proto4.TLSConfig)(nil),            // 7: cloudprober.tlsconfig.TLSConfig
-	(*proto5.GlobalTargetsOptions)(nil), // 8: cloudprober.targets.GlobalTargetsOptions
-	(*proto6.ArtifactsOptions)(nil),     // 9: cloudprober.probes.browser.artifacts.ArtifactsOptions
-	(*proto5.TargetsDef)(nil),           // 10: cloudprober.targets.TargetsDef
+	(*Namespace)(nil),                   // 1: cloudprober.Namespace
+	(*SharedTargets)(nil),               // 2: cloudprober.SharedTargets
+	(*SurfacersConfig)(nil),             // 3: cloudprober.SurfacersConfig
+	(*proto.ProbeDef)(nil),              // 4: cloudprober.probes.ProbeDef
+	(*proto1.SurfacerDef)(nil),          // 5: cloudprober.surfacer.SurfacerDef
+	(*proto2.ServerDef)(nil),            // 6: cloudprober.servers.ServerDef
+	(*proto3.ServerConf)(nil),           // 7: cloudprober.rds.ServerConf
+	(*proto4.TLSConfig)(nil),            // 8: cloudprober.tlsconfig.TLSConfig
+	(*proto5.GlobalTargetsOptions)(nil), // 9: cloudprober.targets.GlobalTargetsOptions
+	(*proto6.ArtifactsOptions)(nil),     // 10: cloudprober.probes.browser.artifacts.ArtifactsOptions
+	(*proto5.TargetsDef)(nil),           // 11: cloudprober.targets.TargetsDef
 }
 var file_github_com_cloudprober_cloudprober_config_proto_config_proto_depIdxs = []int32{
-	3,  // 0: cloudprober.ProberConfig.probe:type_name -> cloudprober.probes.ProbeDef
-	4,  // 1: cloudprober.ProberConfig.surfacer:type_name -> cloudprober.surfacer.SurfacerDef
-	5,  // 2: cloudprober.ProberConfig.server:type_name -> cloudprober.servers.ServerDef
-	1,  // 3: cloudprober.ProberConfig.shared_targets:type_name -> cloudprober.SharedTargets
-	6,  // 4: cloudprober.ProberConfig.rds_server:type_name -> cloudprober.rds.ServerConf
-	7,  // 5: cloudprober.ProberConfig.grpc_tls_config:type_name -> cloudprober.tlsconfig.TLSConfig
-	8,  // 6: cloudprober.ProberConfig.global_targets_options:type_name -> cloudprober.targets.GlobalTargetsOptions
-	9,  // 7: cloudprober.ProberConfig.global_artifacts_options:type_name -> cloudprober.probes.browser.artifacts.ArtifactsOptions
-	10, // 8: cloudprober.SharedTargets.targets:type_name -> cloudprober.targets.TargetsDef
-	4,  // 9: cloudprober.SurfacersConfig.surfacer:type_name -> cloudprober.surfacer.SurfacerDef
-	10, // [10:10] is the sub-list for method output_type
-	10, // [10:10] is the sub-list for method input_type
-	10, // [10:10] is the sub-list for extension type_name
-	10, // [10:10] is the sub-list for extension extendee
-	0,  // [0:10] is the sub-list for field type_name
+	4,  // 0: cloudprober.ProberConfig.probe:type_name -> cloudprober.probes.ProbeDef
+	5,  // 1: cloudprober.ProberConfig.surfacer:type_name -> cloudprober.surfacer.SurfacerDef
+	6,  // 2: cloudprober.ProberConfig.server:type_name -> cloudprober.servers.ServerDef
+	2,  // 3: cloudprober.ProberConfig.shared_targets:type_name -> cloudprober.SharedTargets
+	7,  // 4: cloudprober.ProberConfig.rds_server:type_name -> cloudprober.rds.ServerConf
+	8,  // 5: cloudprober.ProberConfig.grpc_tls_config:type_name -> cloudprober.tlsconfig.TLSConfig
+	9,  // 6: cloudprober.ProberConfig.global_targets_options:type_name -> cloudprober.targets.GlobalTargetsOptions
+	10, // 7: cloudprober.ProberConfig.global_artifacts_options:type_name -> cloudprober.probes.browser.artifacts.ArtifactsOptions
+	1,  // 8: cloudprober.ProberConfig.namespace:type_name -> cloudprober.Namespace
+	11, // 9: cloudprober.SharedTargets.targets:type_name -> cloudprober.targets.TargetsDef
+	5,  // 10: cloudprober.SurfacersConfig.surfacer:type_name -> cloudprober.surfacer.SurfacerDef
+	11, // [11:11] is the sub-list for method output_type
+	11, // [11:11] is the sub-list for method input_type
+	11, // [11:11] is the sub-list for extension type_name
+	11, // [11:11] is the sub-list for extension extendee
+	0,  // [0:11] is the sub-list for field type_name
 }
 
 func init() { file_github_com_cloudprober_cloudprober_config_proto_config_proto_init() }
@@ -475,7 +762,7 @@ func file_github_com_cloudprober_cloudprober_config_proto_config_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_github_com_cloudprober_cloudprober_config_proto_config_proto_rawDesc), len(file_github_com_cloudprober_cloudprober_config_proto_config_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   3,
+			NumMessages:   4,
 			NumExtensions: 0,
 			NumServices:   0,
 		},
@@ -0,0 +1,149 @@
+// Copyright 2025 The Cloudprober Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// RedactedValue replaces the secret values in the redacted configs.
+const RedactedValue = "REDACTED"
+
+var (
+	// Names of the string fields that hold secrets, e.g. api_key, and the
+	// exceptions that only refer to secrets, e.g. api_key_env_var.
+	secretFieldRe    = regexp.MustCompile(`(?i)(password|passwd|secret|token|api_?key|app_key|routing_key|private_key|credential|connection_string|webhook_url)`)
+	notSecretFieldRe = regexp.MustCompile(`(?i)(_env_var|_env|_file|_path|_format|token_url)$`)
+
+	// Names of the headers (and other name-value pairs) that hold secrets.
+	secretHeaderRe = regexp.MustCompile(`(?i)(^authorization$|^proxy-authorization$|^cookie$|token|secret|password|api[-_]?key)`)
+)
+
+// Values of the secret environment variables shorter than this are redacted
+// only if they make up the whole field. Looking for them inside other fields
+// would redact too much, e.g. all fields containing "on".
+const minEnvSecretLen = 6
+
+// Environment variables substituted in the config through envSecret. Their
+// values are redacted by RedactSecrets wherever they show up.
+var secretEnvVars struct {
+	mu    sync.Mutex
+	names map[string]bool
+}
+
+func addSecretEnvVar(name string) {
+	secretEnvVars.mu.Lock()
+	defer secretEnvVars.mu.Unlock()
+	if secretEnvVars.names == nil {
+		secretEnvVars.names = make(map[string]bool)
+	}
+	secretEnvVars.names[name] = true
+}
+
+func secretEnvValues() []string {
+	secretEnvVars.mu.Lock()
+	defer secretEnvVars.mu.Unlock()
+
+	var values []string
+	for name := range secretEnvVars.names {
+		if v := os.Getenv(name); v != "" {
+			values = append(values, v)
+		}
+	}
+	return values
+}
+
+func isSecretField(fd protoreflect.FieldDescriptor) bool {
+	name := string(fd.Name())
+	return secretFieldRe.MatchString(name) && !notSecretFieldRe.MatchString(name)
+}
+
+func containsSecret(s string, envSecrets []string) bool {
+	for _, v := range envSecrets {
+		if s == v || (len(v) >= minEnvSecretLen && strings.Contains(s, v)) {
+			return true
+		}
+	}
+	return false
+}
+
+// RedactSecrets returns a copy of the given config (or any other proto
+// message), with the secrets replaced by RedactedValue. Secrets are
+// identified by the field names (e.g. password or api_key), header names
+// (e.g. Authorization) and values of the environment variables used through
+// envSecret. Short environment variable values (less than minEnvSecretLen
+// characters) are matched only against whole fields.
+func RedactSecrets[M proto.Message](m M) M {
+	c := proto.Clone(m).(M)
+	redactMessage(c.ProtoReflect(), secretEnvValues())
+	return c
+}
+
+func redactMessage(m protoreflect.Message, envSecrets []string) {
+	// Name-value pairs, e.g. HTTP headers.
+	secretPair := false
+	for _, n := range []protoreflect.Name{"name", "key"} {
+		if fd := m.Descriptor().Fields().ByName(n); fd != nil && fd.Kind() == protoreflect.StringKind && m.Has(fd) {
+			secretPair = secretPair || secretHeaderRe.MatchString(m.Get(fd).String())
+		}
+	}
+
+	redactString := func(fd protoreflect.FieldDescriptor, s string) bool {
+		return isSecretField(fd) || (secretPair && fd.Name() == "value") || containsSecret(s, envSecrets)
+	}
+
+	m.Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		switch {
+		case fd.IsMap():
+			valueFD := fd.MapValue()
+			v.Map().Range(func(k protoreflect.MapKey, mv protoreflect.Value) bool {
+				switch {
+				case valueFD.Message() != nil:
+					redactMessage(mv.Message(), envSecrets)
+				case valueFD.Kind() == protoreflect.StringKind:
+					if isSecretField(fd) || secretHeaderRe.MatchString(k.String()) || containsSecret(mv.String(), envSecrets) {
+						v.Map().Set(k, protoreflect.ValueOfString(RedactedValue))
+					}
+				}
+				return true
+			})
+		case fd.IsList():
+			list := v.List()
+			for i := 0; i < list.Len(); i++ {
+				switch {
+				case fd.Message() != nil:
+					redactMessage(list.Get(i).Message(), envSecrets)
+				case fd.Kind() == protoreflect.StringKind:
+					if redactString(fd, list.Get(i).String()) {
+						list.Set(i, protoreflect.ValueOfString(RedactedValue))
+					}
+				}
+			}
+		case fd.Message() != nil:
+			redactMessage(v.Message(), envSecrets)
+		case fd.Kind() == protoreflect.StringKind:
+			if redactString(fd, v.String()) {
+				m.Set(fd, protoreflect.ValueOfString(RedactedValue))
+			}
+		}
+		return true
+	})
+}
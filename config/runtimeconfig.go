@@ -0,0 +1,188 @@
+// Copyright 2025 The Cloudprober Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"cloud.google.com/go/compute/metadata"
+	"github.com/cloudprober/cloudprober/logger"
+	"google.golang.org/api/option"
+	runtimeconfig "google.golang.org/api/runtimeconfig/v1beta1"
+)
+
+// Wait time before re-establishing a failed Runtime Config watch.
+var runtimeConfigRewatchDelay = 10 * time.Second
+
+// runtimeConfigSource reads the config from a GCP Runtime Config variable,
+// and watches it for changes.
+type runtimeConfigSource struct {
+	spec string
+	name string // Full variable name: projects/<p>/configs/<c>/variables/<v>
+
+	svc        *runtimeconfig.ProjectsConfigsVariablesService
+	clientOpts []option.ClientOption // Used by tests.
+	l          *logger.Logger
+
+	mu         sync.Mutex
+	data       string
+	updateTime string
+}
+
+// newRuntimeConfigSource creates a runtimeConfigSource for the given variable,
+// specified either by its full name, projects/<project>/configs/<config>/variables/<variable>,
+// or as <config>/<variable> for the current project.
+func newRuntimeConfigSource(spec string) *runtimeConfigSource {
+	return &runtimeConfigSource{spec: spec}
+}
+
+func (rcs *runtimeConfigSource) init(ctx context.Context) error {
+	rcs.name = rcs.spec
+	if !strings.HasPrefix(rcs.spec, "projects/") {
+		cfg, variable, ok := strings.Cut(rcs.spec, "/")
+		if !ok || cfg == "" || variable == "" {
+			return fmt.Errorf("invalid Runtime Config variable (%s), expected <config>/<variable> or full variable name", rcs.spec)
+		}
+		project, err := metadata.ProjectIDWithContext(ctx)
+		if err != nil {
+			return fmt.Errorf("error getting current project for Runtime Config variable (%s): %v", rcs.spec, err)
+		}
+		rcs.name = fmt.Sprintf("projects/%s/configs/%s/variables/%s", project, cfg, variable)
+	}
+
+	clientOpts := append([]option.ClientOption{option.WithScopes(runtimeconfig.CloudruntimeconfigScope)}, rcs.clientOpts...)
+	svc, err := runtimeconfig.NewService(ctx, clientOpts...)
+	if err != nil {
+		return fmt.Errorf("error creating Runtime Config client: %v", err)
+	}
+	rcs.svc = runtimeconfig.NewProjectsConfigsVariablesService(svc)
+	return nil
+}
+
+// key returns the last component of the variable name. It's used to
+// determine the config format.
+func (rcs *runtimeConfigSource) key() string {
+	return path.Base(rcs.spec)
+}
+
+func variableContent(v *runtimeconfig.Variable) (string, error) {
+	if v.Value == "" {
+		return v.Text, nil
+	}
+	b, err := base64.StdEncoding.DecodeString(v.Value)
+	if err != nil {
+		return "", fmt.Errorf("error decoding value of the Runtime Config variable %s: %v", v.Name, err)
+	}
+	return string(b), nil
+}
+
+// read fetches the config from the Runtime Config variable.
+func (rcs *runtimeConfigSource) read(ctx context.Context) (string, error) {
+	if rcs.svc == nil {
+		if err := rcs.init(ctx); err != nil {
+			return "", err
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	v, err := rcs.svc.Get(rcs.name).Context(ctx).Do()
+	if err != nil {
+		return "", fmt.Errorf("error getting Runtime Config variable %s: %v", rcs.name, err)
+	}
+
+	data, err := variableContent(v)
+	if err != nil {
+		return "", err
+	}
+
+	rcs.mu.Lock()
+	defer rcs.mu.Unlock()
+	rcs.data, rcs.updateTime = data, v.UpdateTime
+
+	return data, nil
+}
+
+// watchOnce runs a single watch request, and sends a signal on the changes
+// channel if the config data changed. Runtime Config watch requests return
+// after a change, or after about a minute without changes.
+func (rcs *runtimeConfigSource) watchOnce(ctx context.Context, changes chan<- struct{}) error {
+	rcs.mu.Lock()
+	req := &runtimeconfig.WatchVariableRequest{NewerThan: rcs.updateTime}
+	rcs.mu.Unlock()
+
+	v, err := rcs.svc.Watch(rcs.name, req).Context(ctx).Do()
+	if err != nil {
+		return err
+	}
+	if v.State == "DELETED" {
+		return fmt.Errorf("Runtime Config variable %s was deleted", rcs.name)
+	}
+
+	data, err := variableContent(v)
+	if err != nil {
+		return err
+	}
+
+	rcs.mu.Lock()
+	if v.UpdateTime != "" {
+		rcs.updateTime = v.UpdateTime
+	}
+	changed := data != rcs.data
+	rcs.mu.Unlock()
+
+	if changed {
+		rcs.l.Infof("Runtime Config variable %s changed (updateTime: %s)", rcs.name, v.UpdateTime)
+		select {
+		case changes <- struct{}{}:
+		default:
+		}
+	}
+	return nil
+}
+
+// watch watches the Runtime Config variable for changes until the context is
+// canceled.
+func (rcs *runtimeConfigSource) watch(ctx context.Context) <-chan struct{} {
+	changes := make(chan struct{}, 1)
+
+	go func() {
+		for {
+			err := rcs.watchOnce(ctx, changes)
+			if ctx.Err() != nil {
+				return
+			}
+			if err == nil {
+				continue
+			}
+			rcs.l.Warningf("Error watching Runtime Config variable %s: %v", rcs.name, err)
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(runtimeConfigRewatchDelay):
+			}
+		}
+	}()
+
+	return changes
+}
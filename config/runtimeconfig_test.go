@@ -0,0 +1,129 @@
+// Copyright 2025 The Cloudprober Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/api/option"
+	runtimeconfig "google.golang.org/api/runtimeconfig/v1beta1"
+)
+
+const testRuntimeConfigVar = "projects/p1/configs/c1/variables/cloudprober.cfg"
+
+// fakeRuntimeConfigServer implements just enough of the Runtime Config API to
+// get and watch a variable.
+type fakeRuntimeConfigServer struct {
+	mu      sync.Mutex
+	version int
+	text    string
+	updates chan struct{}
+}
+
+func (fs *fakeRuntimeConfigServer) variable() *runtimeconfig.Variable {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	return &runtimeconfig.Variable{
+		Name:       testRuntimeConfigVar,
+		Text:       fs.text,
+		UpdateTime: fmt.Sprintf("2025-01-01T00:00:%02dZ", fs.version),
+	}
+}
+
+func (fs *fakeRuntimeConfigServer) update(text string) {
+	fs.mu.Lock()
+	fs.version++
+	fs.text = text
+	fs.mu.Unlock()
+	fs.updates <- struct{}{}
+}
+
+func (fs *fakeRuntimeConfigServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.URL.Path {
+	case "/v1beta1/" + testRuntimeConfigVar:
+		json.NewEncoder(w).Encode(fs.variable())
+	case "/v1beta1/" + testRuntimeConfigVar + ":watch":
+		select {
+		case <-r.Context().Done():
+			return
+		case <-fs.updates:
+		case <-time.After(time.Second):
+		}
+		json.NewEncoder(w).Encode(fs.variable())
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func TestRuntimeConfigSource(t *testing.T) {
+	fs := &fakeRuntimeConfigServer{
+		text:    "probe {}",
+		updates: make(chan struct{}, 10),
+	}
+	ts := httptest.NewServer(fs)
+	defer ts.Close()
+
+	rcs := newRuntimeConfigSource(testRuntimeConfigVar)
+	rcs.clientOpts = []option.ClientOption{option.WithEndpoint(ts.URL + "/"), option.WithHTTPClient(ts.Client())}
+	assert.Equal(t, "cloudprober.cfg", rcs.key())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	data, err := rcs.read(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, "probe {}", data)
+
+	changes := rcs.watch(ctx)
+	fs.update("probe {}\nprobe {}")
+
+	select {
+	case <-changes:
+	case <-time.After(10 * time.Second):
+		t.Fatal("timed out waiting for the Runtime Config change")
+	}
+
+	data, err = rcs.read(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, "probe {}\nprobe {}", data)
+}
+
+func TestRuntimeConfigSourceInit(t *testing.T) {
+	for _, spec := range []string{"c1", "c1/", "/v1"} {
+		rcs := newRuntimeConfigSource(spec)
+		assert.Error(t, rcs.init(context.Background()), spec)
+	}
+}
+
+func TestVariableContent(t *testing.T) {
+	data, err := variableContent(&runtimeconfig.Variable{Value: "cHJvYmUge30="})
+	assert.NoError(t, err)
+	assert.Equal(t, "probe {}", data)
+
+	data, err = variableContent(&runtimeconfig.Variable{Text: "probe {}"})
+	assert.NoError(t, err)
+	assert.Equal(t, "probe {}", data)
+
+	_, err = variableContent(&runtimeconfig.Variable{Value: "not base64!"})
+	assert.Error(t, err)
+}
@@ -37,6 +37,9 @@ type targetState struct {
 	lastTotal   int64
 	failures    []bool
 
+	// Used only for burn rate condition.
+	samples []counterSample
+
 	alerted      bool
 	alertTS      time.Time
 	failingSince time.Time
@@ -49,6 +52,7 @@ type AlertHandler struct {
 	name         string
 	probeName    string
 	condition    *configpb.Condition
+	burnRate     *burnRateRule
 	notifyConfig *configpb.NotifyConfig
 	notifyCh     chan *alertinfo.AlertInfo // Used only for testing for now.
 	notifier     *notifier.Notifier
@@ -96,6 +100,14 @@ func NewAlertHandler(conf *configpb.AlertConf, probeName string, l *logger.Logge
 		ah.name = probeName
 	}
 
+	if conf.GetBurnRateCondition() != nil {
+		br, err := newBurnRateRule(conf.GetBurnRateCondition())
+		if err != nil {
+			return nil, fmt.Errorf("error in alert %s: %v", ah.name, err)
+		}
+		ah.burnRate = br
+	}
+
 	// Initialize notifier.
 	notifier, err := notifier.New(ah.c, l)
 	if err != nil {
@@ -125,8 +137,12 @@ func conditionID(alertKey string) string {
 	return uuid.NewMD5(uuid.NameSpaceOID, []byte(alertKey)).String()
 }
 
-func (ah *AlertHandler) notify(ep endpoint.Endpoint, ts *targetState, totalFailures int) {
-	ah.l.Warningf("ALERT (%s): target (%s), failures (%d) higher than (%d) since (%v)", ah.name, ep.Name, totalFailures, ah.condition.Failures, ts.failingSince)
+func (ah *AlertHandler) notify(ep endpoint.Endpoint, ts *targetState, totalFailures, total int) {
+	if ah.burnRate != nil {
+		ah.l.Warningf("ALERT (%s): target (%s), error budget burn rate too high, failures (%d) out of (%d) since (%v)", ah.name, ep.Name, totalFailures, total, ts.failingSince)
+	} else {
+		ah.l.Warningf("ALERT (%s): target (%s), failures (%d) higher than (%d) since (%v)", ah.name, ep.Name, totalFailures, ah.condition.Failures, ts.failingSince)
+	}
 
 	ts.alerted = true
 	alertKey := ah.globalKey(ep)
@@ -137,7 +153,7 @@ func (ah *AlertHandler) notify(ep endpoint.Endpoint, ts *targetState, totalFailu
 		DeduplicationID: conditionID(alertKey),
 		Target:          ep,
 		Failures:        totalFailures,
-		Total:           int(ah.condition.Total),
+		Total:           total,
 		FailingSince:    ts.failingSince,
 	}
 
@@ -167,12 +183,12 @@ func (ah *AlertHandler) resolveAlertCondition(ts *targetState, ep endpoint.Endpo
 }
 
 // handleAlertCondition handles the alert condition.
-func (ah *AlertHandler) handleAlertCondition(ts *targetState, ep endpoint.Endpoint, timestamp time.Time, totalFailures int) {
+func (ah *AlertHandler) handleAlertCondition(ts *targetState, ep endpoint.Endpoint, timestamp time.Time, totalFailures, total int) {
 	// Ongoing alert. Notify if the repeat interval has passed.
 	if ts.alerted {
 		if time.Since(ts.alertTS) > time.Duration(ah.c.GetRepeatIntervalSec())*time.Second {
 			ts.alertTS = time.Now()
-			ah.notify(ep, ts, totalFailures)
+			ah.notify(ep, ts, totalFailures, total)
 		}
 		return
 	}
@@ -181,7 +197,7 @@ func (ah *AlertHandler) handleAlertCondition(ts *targetState, ep endpoint.Endpoi
 	ts.alerted = true
 	ts.failingSince = timestamp
 	ts.alertTS = time.Now()
-	ah.notify(ep, ts, totalFailures)
+	ah.notify(ep, ts, totalFailures, total)
 }
 
 func (ah *AlertHandler) globalKey(ep endpoint.Endpoint) string {
@@ -203,6 +219,11 @@ func (ah *AlertHandler) Record(ep endpoint.Endpoint, em *metrics.EventMetrics) {
 		return
 	}
 
+	if ah.burnRate != nil {
+		ah.recordBurnRate(ep, em.Timestamp, total, success)
+		return
+	}
+
 	key := ep.Key()
 	ts := ah.targets[key]
 	if ts == nil {
@@ -266,10 +287,29 @@ func (ah *AlertHandler) Record(ep endpoint.Endpoint, em *metrics.EventMetrics) {
 	}
 
 	if totalFailures >= int(ah.condition.Failures) {
-		ah.handleAlertCondition(ts, ep, em.Timestamp, totalFailures)
+		ah.handleAlertCondition(ts, ep, em.Timestamp, totalFailures, int(ah.condition.Total))
 	} else if ts.alerted {
 		ah.resolveAlertCondition(ts, ep)
 	}
 
 	ts.lastTotal, ts.lastSuccess = total, success
 }
+
+// recordBurnRate records the target's counters and evaluates the burn rate
+// condition.
+func (ah *AlertHandler) recordBurnRate(ep endpoint.Endpoint, timestamp time.Time, total, success int64) {
+	key := ep.Key()
+	ts := ah.targets[key]
+	if ts == nil {
+		ts = &targetState{}
+		ah.targets[key] = ts
+	}
+
+	ts.samples = ah.burnRate.addSample(ts.samples, counterSample{ts: timestamp, total: total, success: success})
+
+	if firing, failures, total := ah.burnRate.evaluate(ts.samples); firing {
+		ah.handleAlertCondition(ts, ep, timestamp, failures, total)
+	} else if ts.alerted {
+		ah.resolveAlertCondition(ts, ep)
+	}
+}
@@ -0,0 +1,145 @@
+// Copyright 2025 The Cloudprober Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package alerting
+
+import (
+	"fmt"
+	"time"
+
+	configpb "github.com/cloudprober/cloudprober/internal/alerting/proto"
+)
+
+var defaultBurnRateWindows = []*configpb.BurnRateWindow{
+	{LongWindow: "1h", ShortWindow: "5m", BurnRate: 14.4},
+	{LongWindow: "6h", ShortWindow: "30m", BurnRate: 6},
+}
+
+type burnRateWindow struct {
+	long, short time.Duration
+	burnRate    float64
+}
+
+// burnRateRule implements multi-window, multi-burn-rate alerting rule.
+type burnRateRule struct {
+	budget    float64
+	windows   []burnRateWindow
+	maxWindow time.Duration
+}
+
+// counterSample is a sample of the target's total and success counters.
+type counterSample struct {
+	ts             time.Time
+	total, success int64
+}
+
+func newBurnRateRule(c *configpb.BurnRateCondition) (*burnRateRule, error) {
+	if c.GetSlo() <= 0 || c.GetSlo() >= 1 {
+		return nil, fmt.Errorf("burn_rate_condition: slo (%f) should be in the range (0, 1)", c.GetSlo())
+	}
+
+	br := &burnRateRule{budget: 1 - c.GetSlo()}
+
+	windows := c.GetWindow()
+	if len(windows) == 0 {
+		windows = defaultBurnRateWindows
+	}
+
+	for _, w := range windows {
+		long, err := time.ParseDuration(w.GetLongWindow())
+		if err != nil {
+			return nil, fmt.Errorf("burn_rate_condition: error parsing long_window (%s): %v", w.GetLongWindow(), err)
+		}
+		short, err := time.ParseDuration(w.GetShortWindow())
+		if err != nil {
+			return nil, fmt.Errorf("burn_rate_condition: error parsing short_window (%s): %v", w.GetShortWindow(), err)
+		}
+		if short <= 0 || short > long {
+			return nil, fmt.Errorf("burn_rate_condition: short_window (%v) should be positive and not larger than long_window (%v)", short, long)
+		}
+		if w.GetBurnRate() <= 0 {
+			return nil, fmt.Errorf("burn_rate_condition: burn_rate (%f) should be positive", w.GetBurnRate())
+		}
+
+		br.windows = append(br.windows, burnRateWindow{long: long, short: short, burnRate: float64(w.GetBurnRate())})
+		if long > br.maxWindow {
+			br.maxWindow = long
+		}
+	}
+
+	return br, nil
+}
+
+// addSample adds a sample to the samples slice and removes the samples that
+// are not needed anymore. We keep the latest sample older than the max
+// window, to serve as the base for the max window.
+func (br *burnRateRule) addSample(samples []counterSample, s counterSample) []counterSample {
+	// If counters went down, target's results were reset. Start over.
+	if len(samples) > 0 && s.total < samples[len(samples)-1].total {
+		samples = samples[:0]
+	}
+	samples = append(samples, s)
+
+	cutoff := s.ts.Add(-br.maxWindow)
+	i := 0
+	for i+1 < len(samples) && !samples[i+1].ts.After(cutoff) {
+		i++
+	}
+	return samples[i:]
+}
+
+// failuresInWindow returns failures and total in the given window, ending at
+// the latest sample. If we don't have enough history, we use the oldest
+// sample as the base.
+func failuresInWindow(samples []counterSample, window time.Duration) (int64, int64) {
+	latest := samples[len(samples)-1]
+	cutoff := latest.ts.Add(-window)
+
+	base := samples[0]
+	for _, s := range samples {
+		if s.ts.After(cutoff) {
+			break
+		}
+		base = s
+	}
+
+	total := latest.total - base.total
+	success := latest.success - base.success
+	return total - success, total
+}
+
+func (br *burnRateRule) exceeds(failures, total int64, burnRate float64) bool {
+	if total <= 0 {
+		return false
+	}
+	return float64(failures)/float64(total) > burnRate*br.budget
+}
+
+// evaluate evaluates the burn rate rule for the given samples. If rule fires,
+// it returns true, along with failures and total in the long window of the
+// firing window pair.
+func (br *burnRateRule) evaluate(samples []counterSample) (bool, int, int) {
+	if len(samples) < 2 {
+		return false, 0, 0
+	}
+
+	for _, w := range br.windows {
+		longFailures, longTotal := failuresInWindow(samples, w.long)
+		shortFailures, shortTotal := failuresInWindow(samples, w.short)
+		if br.exceeds(longFailures, longTotal, w.burnRate) && br.exceeds(shortFailures, shortTotal, w.burnRate) {
+			return true, int(longFailures), int(longTotal)
+		}
+	}
+	return false, 0, 0
+}
@@ -0,0 +1,130 @@
+// Copyright 2025 The Cloudprober Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package alerting
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cloudprober/cloudprober/internal/alerting/alertinfo"
+	configpb "github.com/cloudprober/cloudprober/internal/alerting/proto"
+	"github.com/cloudprober/cloudprober/metrics"
+	"github.com/cloudprober/cloudprober/targets/endpoint"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewBurnRateRule(t *testing.T) {
+	br, err := newBurnRateRule(&configpb.BurnRateCondition{Slo: 0.999})
+	assert.NoError(t, err)
+	assert.InDelta(t, 0.001, br.budget, 1e-9)
+	// Burn rates come from float32 config fields, compare them with a delta.
+	wantWindows := []burnRateWindow{
+		{long: time.Hour, short: 5 * time.Minute, burnRate: 14.4},
+		{long: 6 * time.Hour, short: 30 * time.Minute, burnRate: 6},
+	}
+	if assert.Len(t, br.windows, len(wantWindows)) {
+		for i, w := range wantWindows {
+			assert.Equal(t, w.long, br.windows[i].long)
+			assert.Equal(t, w.short, br.windows[i].short)
+			assert.InDelta(t, w.burnRate, br.windows[i].burnRate, 1e-6)
+		}
+	}
+	assert.Equal(t, 6*time.Hour, br.maxWindow)
+
+	for _, c := range []*configpb.BurnRateCondition{
+		{Slo: 1},
+		{Slo: 0.99, Window: []*configpb.BurnRateWindow{{LongWindow: "1x", ShortWindow: "5m", BurnRate: 1}}},
+		{Slo: 0.99, Window: []*configpb.BurnRateWindow{{LongWindow: "5m", ShortWindow: "1h", BurnRate: 1}}},
+		{Slo: 0.99, Window: []*configpb.BurnRateWindow{{LongWindow: "1h", ShortWindow: "5m"}}},
+	} {
+		_, err := newBurnRateRule(c)
+		assert.Error(t, err, "config: %v", c)
+	}
+}
+
+func TestBurnRateAddSample(t *testing.T) {
+	br := &burnRateRule{maxWindow: 10 * time.Minute}
+
+	var samples []counterSample
+	start := time.Time{}
+	for i := 0; i <= 20; i++ {
+		samples = br.addSample(samples, counterSample{ts: start.Add(time.Duration(i) * time.Minute), total: int64(i), success: int64(i)})
+	}
+	// We keep the samples in the last 10 minutes, plus the base sample.
+	assert.Len(t, samples, 11)
+	assert.Equal(t, start.Add(10*time.Minute), samples[0].ts)
+
+	// Counters reset.
+	samples = br.addSample(samples, counterSample{ts: start.Add(21 * time.Minute), total: 1, success: 1})
+	assert.Len(t, samples, 1)
+}
+
+func TestAlertHandlerBurnRate(t *testing.T) {
+	ah, err := NewAlertHandler(&configpb.AlertConf{
+		BurnRateCondition: &configpb.BurnRateCondition{
+			Slo: 0.99,
+			Window: []*configpb.BurnRateWindow{
+				{LongWindow: "1h", ShortWindow: "5m", BurnRate: 10},
+			},
+		},
+	}, "test-probe", nil)
+	assert.NoError(t, err)
+	ah.notifyCh = make(chan *alertinfo.AlertInfo, 10)
+
+	ep := endpoint.Endpoint{Name: "target1"}
+	ts := time.Time{}
+	var total, success int64
+
+	// record records a minute worth of probes (60) with the given number of
+	// failures.
+	record := func(failures int64) {
+		ts = ts.Add(time.Minute)
+		total += 60
+		success += 60 - failures
+		em := metrics.NewEventMetrics(ts).
+			AddMetric("total", metrics.NewInt(total)).
+			AddMetric("success", metrics.NewInt(success))
+		ah.Record(ep, em)
+	}
+
+	// One hour of success.
+	for i := 0; i < 60; i++ {
+		record(0)
+	}
+	assert.False(t, ah.targets[ep.Key()].alerted)
+
+	// Error budget threshold is 10 * 0.01 = 10% error rate. Short window
+	// exceeds it right away, but long window needs 7 minutes of failures:
+	// 420/3600 > 10%.
+	for i := 0; i < 6; i++ {
+		record(60)
+	}
+	assert.False(t, ah.targets[ep.Key()].alerted)
+	assert.Len(t, ah.notifyCh, 0)
+
+	record(60)
+	assert.True(t, ah.targets[ep.Key()].alerted)
+	if assert.Len(t, ah.notifyCh, 1) {
+		ai := <-ah.notifyCh
+		assert.Equal(t, 420, ai.Failures)
+		assert.Equal(t, 3600, ai.Total)
+	}
+
+	// Recovery: alert resolves once short window is clean.
+	for i := 0; i < 5; i++ {
+		record(0)
+	}
+	assert.False(t, ah.targets[ep.Key()].alerted)
+}
@@ -132,7 +132,7 @@ func (x AlertConf_Severity) Number() protoreflect.EnumNumber {
 
 // Deprecated: Use AlertConf_Severity.Descriptor instead.
 func (AlertConf_Severity) EnumDescriptor() ([]byte, []int) {
-	return file_github_com_cloudprober_cloudprober_internal_alerting_proto_config_proto_rawDescGZIP(), []int{6, 0}
+	return file_github_com_cloudprober_cloudprober_internal_alerting_proto_config_proto_rawDescGZIP(), []int{8, 0}
 }
 
 type Email struct {
@@ -622,6 +622,135 @@ func (x *Condition) GetTotal() int32 {
 	return 0
 }
 
+type BurnRateWindow struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Long window, e.g. "1h". Error rate in this window determines whether
+	// the error budget is being consumed at the given burn rate.
+	LongWindow string `protobuf:"bytes,1,opt,name=long_window,json=longWindow,proto3" json:"long_window,omitempty"`
+	// Short window, e.g. "5m". Error rate in this window must also be above
+	// the threshold. It helps resolve the alert quickly once the issue is
+	// fixed.
+	ShortWindow string `protobuf:"bytes,2,opt,name=short_window,json=shortWindow,proto3" json:"short_window,omitempty"`
+	// Burn rate threshold, as a multiple of the error budget, e.g. a burn rate
+	// of 14.4 consumes 2% of a 30-day error budget in 1 hour.
+	BurnRate      float32 `protobuf:"fixed32,3,opt,name=burn_rate,json=burnRate,proto3" json:"burn_rate,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BurnRateWindow) Reset() {
+	*x = BurnRateWindow{}
+	mi := &file_github_com_cloudprober_cloudprober_internal_alerting_proto_config_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BurnRateWindow) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BurnRateWindow) ProtoMessage() {}
+
+func (x *BurnRateWindow) ProtoReflect() protoreflect.Message {
+	mi := &file_github_com_cloudprober_cloudprober_internal_alerting_proto_config_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BurnRateWindow.ProtoReflect.Descriptor instead.
+func (*BurnRateWindow) Descriptor() ([]byte, []int) {
+	return file_github_com_cloudprober_cloudprober_internal_alerting_proto_config_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *BurnRateWindow) GetLongWindow() string {
+	if x != nil {
+		return x.LongWindow
+	}
+	return ""
+}
+
+func (x *BurnRateWindow) GetShortWindow() string {
+	if x != nil {
+		return x.ShortWindow
+	}
+	return ""
+}
+
+func (x *BurnRateWindow) GetBurnRate() float32 {
+	if x != nil {
+		return x.BurnRate
+	}
+	return 0
+}
+
+// Multi-window, multi-burn-rate condition, as described in the Google SRE
+// workbook. Error rate is computed from the probe's total and success
+// counters for each target.
+type BurnRateCondition struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// SLO target as a fraction, e.g. 0.999 for 99.9%. Error budget is 1 - slo.
+	Slo float64 `protobuf:"fixed64,1,opt,name=slo,proto3" json:"slo,omitempty"`
+	// Alert fires if error rate is above burn_rate * error budget in both,
+	// long and short windows, for any of the configured windows. Default
+	// windows, as recommended in the SRE workbook, are:
+	//
+	//	window { long_window: "1h" short_window: "5m" burn_rate: 14.4 }
+	//	window { long_window: "6h" short_window: "30m" burn_rate: 6 }
+	Window        []*BurnRateWindow `protobuf:"bytes,2,rep,name=window,proto3" json:"window,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BurnRateCondition) Reset() {
+	*x = BurnRateCondition{}
+	mi := &file_github_com_cloudprober_cloudprober_internal_alerting_proto_config_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BurnRateCondition) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BurnRateCondition) ProtoMessage() {}
+
+func (x *BurnRateCondition) ProtoReflect() protoreflect.Message {
+	mi := &file_github_com_cloudprober_cloudprober_internal_alerting_proto_config_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BurnRateCondition.ProtoReflect.Descriptor instead.
+func (*BurnRateCondition) Descriptor() ([]byte, []int) {
+	return file_github_com_cloudprober_cloudprober_internal_alerting_proto_config_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *BurnRateCondition) GetSlo() float64 {
+	if x != nil {
+		return x.Slo
+	}
+	return 0
+}
+
+func (x *BurnRateCondition) GetWindow() []*BurnRateWindow {
+	if x != nil {
+		return x.Window
+	}
+	return nil
+}
+
 type AlertConf struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
 	// Name of the alert. Default is to use the probe name. If you have multiple
@@ -636,6 +765,15 @@ type AlertConf struct {
 	//	  total: 10
 	//	}
 	Condition *Condition `protobuf:"bytes,2,opt,name=condition,proto3,oneof" json:"condition,omitempty"`
+	// Burn rate condition. If specified, it's used instead of the condition
+	// above.
+	// Example:
+	// # Alert on fast and slow burn of the 99.9% SLO's error budget.
+	//
+	//	burn_rate_condition {
+	//	  slo: 0.999
+	//	}
+	BurnRateCondition *BurnRateCondition `protobuf:"bytes,11,opt,name=burn_rate_condition,json=burnRateCondition,proto3" json:"burn_rate_condition,omitempty"`
 	// How to notify in case of alert.
 	Notify *NotifyConfig `protobuf:"bytes,3,opt,name=notify,proto3" json:"notify,omitempty"`
 	// Dashboard URL template.
@@ -666,7 +804,7 @@ type AlertConf struct {
 
 func (x *AlertConf) Reset() {
 	*x = AlertConf{}
-	mi := &file_github_com_cloudprober_cloudprober_internal_alerting_proto_config_proto_msgTypes[6]
+	mi := &file_github_com_cloudprober_cloudprober_internal_alerting_proto_config_proto_msgTypes[8]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -678,7 +816,7 @@ func (x *AlertConf) String() string {
 func (*AlertConf) ProtoMessage() {}
 
 func (x *AlertConf) ProtoReflect() protoreflect.Message {
-	mi := &file_github_com_cloudprober_cloudprober_internal_alerting_proto_config_proto_msgTypes[6]
+	mi := &file_github_com_cloudprober_cloudprober_internal_alerting_proto_config_proto_msgTypes[8]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -691,7 +829,7 @@ func (x *AlertConf) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use AlertConf.ProtoReflect.Descriptor instead.
 func (*AlertConf) Descriptor() ([]byte, []int) {
-	return file_github_com_cloudprober_cloudprober_internal_alerting_proto_config_proto_rawDescGZIP(), []int{6}
+	return file_github_com_cloudprober_cloudprober_internal_alerting_proto_config_proto_rawDescGZIP(), []int{8}
 }
 
 func (x *AlertConf) GetName() string {
@@ -708,6 +846,13 @@ func (x *AlertConf) GetCondition() *Condition {
 	return nil
 }
 
+func (x *AlertConf) GetBurnRateCondition() *BurnRateCondition {
+	if x != nil {
+		return x.BurnRateCondition
+	}
+	return nil
+}
+
 func (x *AlertConf) GetNotify() *NotifyConfig {
 	if x != nil {
 		return x.Notify
@@ -778,7 +923,7 @@ type Opsgenie_Responder struct {
 
 func (x *Opsgenie_Responder) Reset() {
 	*x = Opsgenie_Responder{}
-	mi := &file_github_com_cloudprober_cloudprober_internal_alerting_proto_config_proto_msgTypes[7]
+	mi := &file_github_com_cloudprober_cloudprober_internal_alerting_proto_config_proto_msgTypes[9]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -790,7 +935,7 @@ func (x *Opsgenie_Responder) String() string {
 func (*Opsgenie_Responder) ProtoMessage() {}
 
 func (x *Opsgenie_Responder) ProtoReflect() protoreflect.Message {
-	mi := &file_github_com_cloudprober_cloudprober_internal_alerting_proto_config_proto_msgTypes[7]
+	mi := &file_github_com_cloudprober_cloudprober_internal_alerting_proto_config_proto_msgTypes[9]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -908,10 +1053,19 @@ const file_github_com_cloudprober_cloudprober_internal_alerting_proto_config_pro
 	"httpNotify\"=\n" +
 	"\tCondition\x12\x1a\n" +
 	"\bfailures\x18\x01 \x01(\x05R\bfailures\x12\x14\n" +
-	"\x05total\x18\x02 \x01(\x05R\x05total\"\xdf\x05\n" +
+	"\x05total\x18\x02 \x01(\x05R\x05total\"q\n" +
+	"\x0eBurnRateWindow\x12\x1f\n" +
+	"\vlong_window\x18\x01 \x01(\tR\n" +
+	"longWindow\x12!\n" +
+	"\fshort_window\x18\x02 \x01(\tR\vshortWindow\x12\x1b\n" +
+	"\tburn_rate\x18\x03 \x01(\x02R\bburnRate\"c\n" +
+	"\x11BurnRateCondition\x12\x10\n" +
+	"\x03slo\x18\x01 \x01(\x01R\x03slo\x12<\n" +
+	"\x06window\x18\x02 \x03(\v2$.cloudprober.alerting.BurnRateWindowR\x06window\"\xb8\x06\n" +
 	"\tAlertConf\x12\x12\n" +
 	"\x04name\x18\x01 \x01(\tR\x04name\x12B\n" +
-	"\tcondition\x18\x02 \x01(\v2\x1f.cloudprober.alerting.ConditionH\x00R\tcondition\x88\x01\x01\x12:\n" +
+	"\tcondition\x18\x02 \x01(\v2\x1f.cloudprober.alerting.ConditionH\x00R\tcondition\x88\x01\x01\x12W\n" +
+	"\x13burn_rate_condition\x18\v \x01(\v2'.cloudprober.alerting.BurnRateConditionR\x11burnRateCondition\x12:\n" +
 	"\x06notify\x18\x03 \x01(\v2\".cloudprober.alerting.NotifyConfigR\x06notify\x124\n" +
 	"\x16dashboard_url_template\x18\x04 \x01(\tR\x14dashboardUrlTemplate\x122\n" +
 	"\x15playbook_url_template\x18\x05 \x01(\tR\x13playbookUrlTemplate\x12)\n" +
@@ -948,7 +1102,7 @@ func file_github_com_cloudprober_cloudprober_internal_alerting_proto_config_prot
 }
 
 var file_github_com_cloudprober_cloudprober_internal_alerting_proto_config_proto_enumTypes = make([]protoimpl.EnumInfo, 2)
-var file_github_com_cloudprober_cloudprober_internal_alerting_proto_config_proto_msgTypes = make([]protoimpl.MessageInfo, 9)
+var file_github_com_cloudprober_cloudprober_internal_alerting_proto_config_proto_msgTypes = make([]protoimpl.MessageInfo, 11)
 var file_github_com_cloudprober_cloudprober_internal_alerting_proto_config_proto_goTypes = []any{
 	(Opsgenie_Responder_Type)(0), // 0: cloudprober.alerting.Opsgenie.Responder.Type
 	(AlertConf_Severity)(0),      // 1: cloudprober.alerting.AlertConf.Severity
@@ -958,28 +1112,32 @@ var file_github_com_cloudprober_cloudprober_internal_alerting_proto_config_proto
 	(*Slack)(nil),                // 5: cloudprober.alerting.Slack
 	(*NotifyConfig)(nil),         // 6: cloudprober.alerting.NotifyConfig
 	(*Condition)(nil),            // 7: cloudprober.alerting.Condition
-	(*AlertConf)(nil),            // 8: cloudprober.alerting.AlertConf
-	(*Opsgenie_Responder)(nil),   // 9: cloudprober.alerting.Opsgenie.Responder
-	nil,                          // 10: cloudprober.alerting.AlertConf.OtherInfoEntry
-	(*proto.HTTPRequest)(nil),    // 11: cloudprober.utils.httpreq.HTTPRequest
+	(*BurnRateWindow)(nil),       // 8: cloudprober.alerting.BurnRateWindow
+	(*BurnRateCondition)(nil),    // 9: cloudprober.alerting.BurnRateCondition
+	(*AlertConf)(nil),            // 10: cloudprober.alerting.AlertConf
+	(*Opsgenie_Responder)(nil),   // 11: cloudprober.alerting.Opsgenie.Responder
+	nil,                          // 12: cloudprober.alerting.AlertConf.OtherInfoEntry
+	(*proto.HTTPRequest)(nil),    // 13: cloudprober.utils.httpreq.HTTPRequest
 }
 var file_github_com_cloudprober_cloudprober_internal_alerting_proto_config_proto_depIdxs = []int32{
-	9,  // 0: cloudprober.alerting.Opsgenie.responders:type_name -> cloudprober.alerting.Opsgenie.Responder
+	11, // 0: cloudprober.alerting.Opsgenie.responders:type_name -> cloudprober.alerting.Opsgenie.Responder
 	2,  // 1: cloudprober.alerting.NotifyConfig.email:type_name -> cloudprober.alerting.Email
 	4,  // 2: cloudprober.alerting.NotifyConfig.pager_duty:type_name -> cloudprober.alerting.PagerDuty
 	5,  // 3: cloudprober.alerting.NotifyConfig.slack:type_name -> cloudprober.alerting.Slack
 	3,  // 4: cloudprober.alerting.NotifyConfig.opsgenie:type_name -> cloudprober.alerting.Opsgenie
-	11, // 5: cloudprober.alerting.NotifyConfig.http_notify:type_name -> cloudprober.utils.httpreq.HTTPRequest
-	7,  // 6: cloudprober.alerting.AlertConf.condition:type_name -> cloudprober.alerting.Condition
-	6,  // 7: cloudprober.alerting.AlertConf.notify:type_name -> cloudprober.alerting.NotifyConfig
-	10, // 8: cloudprober.alerting.AlertConf.other_info:type_name -> cloudprober.alerting.AlertConf.OtherInfoEntry
-	1,  // 9: cloudprober.alerting.AlertConf.severity:type_name -> cloudprober.alerting.AlertConf.Severity
-	0,  // 10: cloudprober.alerting.Opsgenie.Responder.type:type_name -> cloudprober.alerting.Opsgenie.Responder.Type
-	11, // [11:11] is the sub-list for method output_type
-	11, // [11:11] is the sub-list for method input_type
-	11, // [11:11] is the sub-list for extension type_name
-	11, // [11:11] is the sub-list for extension extendee
-	0,  // [0:11] is the sub-list for field type_name
+	13, // 5: cloudprober.alerting.NotifyConfig.http_notify:type_name -> cloudprober.utils.httpreq.HTTPRequest
+	8,  // 6: cloudprober.alerting.BurnRateCondition.window:type_name -> cloudprober.alerting.BurnRateWindow
+	7,  // 7: cloudprober.alerting.AlertConf.condition:type_name -> cloudprober.alerting.Condition
+	9,  // 8: cloudprober.alerting.AlertConf.burn_rate_condition:type_name -> cloudprober.alerting.BurnRateCondition
+	6,  // 9: cloudprober.alerting.AlertConf.notify:type_name -> cloudprober.alerting.NotifyConfig
+	12, // 10: cloudprober.alerting.AlertConf.other_info:type_name -> cloudprober.alerting.AlertConf.OtherInfoEntry
+	1,  // 11: cloudprober.alerting.AlertConf.severity:type_name -> cloudprober.alerting.AlertConf.Severity
+	0,  // 12: cloudprober.alerting.Opsgenie.Responder.type:type_name -> cloudprober.alerting.Opsgenie.Responder.Type
+	13, // [13:13] is the sub-list for method output_type
+	13, // [13:13] is the sub-list for method input_type
+	13, // [13:13] is the sub-list for extension type_name
+	13, // [13:13] is the sub-list for extension extendee
+	0,  // [0:13] is the sub-list for field type_name
 }
 
 func init() { file_github_com_cloudprober_cloudprober_internal_alerting_proto_config_proto_init() }
@@ -987,8 +1145,8 @@ func file_github_com_cloudprober_cloudprober_internal_alerting_proto_config_prot
 	if File_github_com_cloudprober_cloudprober_internal_alerting_proto_config_proto != nil {
 		return
 	}
-	file_github_com_cloudprober_cloudprober_internal_alerting_proto_config_proto_msgTypes[6].OneofWrappers = []any{}
-	file_github_com_cloudprober_cloudprober_internal_alerting_proto_config_proto_msgTypes[7].OneofWrappers = []any{
+	file_github_com_cloudprober_cloudprober_internal_alerting_proto_config_proto_msgTypes[8].OneofWrappers = []any{}
+	file_github_com_cloudprober_cloudprober_internal_alerting_proto_config_proto_msgTypes[9].OneofWrappers = []any{
 		(*Opsgenie_Responder_Id)(nil),
 		(*Opsgenie_Responder_Name)(nil),
 	}
@@ -998,7 +1156,7 @@ func file_github_com_cloudprober_cloudprober_internal_alerting_proto_config_prot
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_github_com_cloudprober_cloudprober_internal_alerting_proto_config_proto_rawDesc), len(file_github_com_cloudprober_cloudprober_internal_alerting_proto_config_proto_rawDesc)),
 			NumEnums:      2,
-			NumMessages:   9,
+			NumMessages:   11,
 			NumExtensions: 0,
 			NumServices:   0,
 		},
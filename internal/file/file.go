@@ -46,17 +46,36 @@ type modTimeFunc func(ctx context.Context, path string) (time.Time, error)
 var zeroTime = time.Time{}
 
 var prefixToReadfunc = map[string]readFunc{
-	"gs://":    readFileFromGCS,
-	"s3://":    readFileFromS3,
-	"http://":  readFileFromHTTP,
-	"https://": readFileFromHTTP,
+	"gs://": readFileFromGCS,
+	"s3://": readFileFromS3,
+	"http://": func(ctx context.Context, path string) ([]byte, error) {
+		return readFileFromHTTP(ctx, "http://"+path)
+	},
+	"https://": func(ctx context.Context, path string) ([]byte, error) {
+		return readFileFromHTTP(ctx, "https://"+path)
+	},
 }
 
 var prefixToModTimeFunc = map[string]modTimeFunc{
-	"gs://":    gcsModTime,
-	"s3://":    s3ModTime,
-	"http://":  httpModTime,
-	"https://": httpModTime,
+	"gs://": gcsModTime,
+	"s3://": s3ModTime,
+	"http://": func(ctx context.Context, path string) (time.Time, error) {
+		return httpModTime(ctx, "http://"+path)
+	},
+	"https://": func(ctx context.Context, path string) (time.Time, error) {
+		return httpModTime(ctx, "https://"+path)
+	},
+}
+
+// IsRemote returns true if the file is not on the local disk, i.e. it's a
+// gs://, s3://, http:// or https:// URL.
+func IsRemote(fname string) bool {
+	for prefix := range prefixToReadfunc {
+		if strings.HasPrefix(fname, prefix) {
+			return true
+		}
+	}
+	return false
 }
 
 func parseObjectURL(objectPath string) (bucket, object string, err error) {
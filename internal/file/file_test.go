@@ -16,6 +16,8 @@ package file
 
 import (
 	"context"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"strings"
 	"testing"
@@ -113,3 +115,33 @@ func TestReadWithCache(t *testing.T) {
 	time.Sleep(time.Second)
 	readAndVerify(testContent+"-updated-2", 1*time.Second)
 }
+
+func TestReadFileHTTP(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Last-Modified", "Mon, 02 Jan 2006 15:04:05 GMT")
+		w.Write([]byte("content-for-" + r.URL.Path))
+	}))
+	defer ts.Close()
+
+	b, err := ReadFile(context.Background(), ts.URL+"/cloudprober.cfg")
+	assert.NoError(t, err)
+	assert.Equal(t, "content-for-/cloudprober.cfg", string(b))
+
+	modTime, err := ModTime(context.Background(), ts.URL+"/cloudprober.cfg")
+	assert.NoError(t, err)
+	assert.Equal(t, 2006, modTime.Year())
+}
+
+func TestIsRemote(t *testing.T) {
+	for fname, want := range map[string]bool{
+		"gs://bucket/cloudprober.cfg":      true,
+		"s3://bucket/cloudprober.cfg":      true,
+		"https://host/cloudprober.cfg":     true,
+		"http://host/cloudprober.cfg":      true,
+		"/etc/cloudprober.cfg":             false,
+		"cloudprober.d/http_probes.cfg":    false,
+		"C:\\cloudprober\\cloudprober.cfg": false,
+	} {
+		assert.Equal(t, want, IsRemote(fname), fname)
+	}
+}
@@ -0,0 +1,162 @@
+// Copyright 2025 The Cloudprober Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+/*
+Package namespace implements probe namespaces. Namespaces make it possible for
+a shared cloudprober instance to serve multiple teams: metrics from probes
+in a namespace carry the namespace label, additional labels get the
+namespace's prefix, metrics are routed only to the namespace's surfacers, and
+are subject to the namespace's rate and cardinality quotas.
+*/
+package namespace
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	configpb "github.com/cloudprober/cloudprober/config/proto"
+	"github.com/cloudprober/cloudprober/logger"
+	"github.com/cloudprober/cloudprober/metrics"
+)
+
+// Label is the label added to all metrics from the namespace's probes.
+const Label = "namespace"
+
+// How often to log about the dropped metrics.
+const dropLogInterval = time.Minute
+
+// timeNow is overridden in tests.
+var timeNow = time.Now
+
+// Namespace represents a probe namespace.
+type Namespace struct {
+	Name string
+
+	c         *configpb.Namespace
+	l         *logger.Logger
+	surfacers map[string]bool
+
+	mu         sync.Mutex
+	tokens     float64
+	lastRefill time.Time
+	series     map[string]bool
+	dropped    int64
+	lastLog    time.Time
+}
+
+// New creates a new namespace from the given config.
+func New(c *configpb.Namespace, l *logger.Logger) (*Namespace, error) {
+	if c.GetName() == "" {
+		return nil, errors.New("namespace name cannot be empty")
+	}
+
+	ns := &Namespace{
+		Name:       c.GetName(),
+		c:          c,
+		l:          l,
+		surfacers:  make(map[string]bool),
+		tokens:     float64(c.GetMaxEventMetricsPerSec()),
+		lastRefill: timeNow(),
+		series:     make(map[string]bool),
+	}
+	for _, s := range c.GetSurfacer() {
+		ns.surfacers[s] = true
+	}
+	return ns, nil
+}
+
+// Init creates namespaces from the given configs and returns them as a map,
+// keyed by the namespace name.
+func Init(configs []*configpb.Namespace, l *logger.Logger) (map[string]*Namespace, error) {
+	namespaces := make(map[string]*Namespace)
+	for _, c := range configs {
+		if namespaces[c.GetName()] != nil {
+			return nil, fmt.Errorf("namespace %s is defined more than once", c.GetName())
+		}
+		ns, err := New(c, l)
+		if err != nil {
+			return nil, err
+		}
+		namespaces[ns.Name] = ns
+	}
+	return namespaces, nil
+}
+
+// LabelPrefix returns the prefix for the keys of the additional labels of
+// the namespace's probes.
+func (ns *Namespace) LabelPrefix() string {
+	return ns.c.GetLabelPrefix()
+}
+
+// MaxProbes returns the maximum number of probes allowed in the namespace.
+// 0 means no limit.
+func (ns *Namespace) MaxProbes() int {
+	return int(ns.c.GetMaxProbes())
+}
+
+// Surfacers returns the names of the surfacers configured for the namespace.
+func (ns *Namespace) Surfacers() []string {
+	return ns.c.GetSurfacer()
+}
+
+// RoutesTo returns whether the namespace's metrics should be sent to the
+// surfacer with the given name.
+func (ns *Namespace) RoutesTo(surfacerName string) bool {
+	return len(ns.surfacers) == 0 || ns.surfacers[surfacerName]
+}
+
+// Admit applies namespace's quotas to the given EventMetrics. It returns
+// false if EventMetrics should be dropped. If EventMetrics is admitted, it
+// adds the namespace label to it.
+func (ns *Namespace) Admit(em *metrics.EventMetrics) bool {
+	ns.mu.Lock()
+	defer ns.mu.Unlock()
+
+	if maxSeries := int(ns.c.GetMaxSeries()); maxSeries > 0 {
+		key := em.Key()
+		if !ns.series[key] {
+			if len(ns.series) >= maxSeries {
+				ns.drop("max_series quota (%d) reached", maxSeries)
+				return false
+			}
+			ns.series[key] = true
+		}
+	}
+
+	if rate := float64(ns.c.GetMaxEventMetricsPerSec()); rate > 0 {
+		now := timeNow()
+		ns.tokens = min(rate, ns.tokens+now.Sub(ns.lastRefill).Seconds()*rate)
+		ns.lastRefill = now
+		if ns.tokens < 1 {
+			ns.drop("max_event_metrics_per_sec quota (%d) exceeded", int(rate))
+			return false
+		}
+		ns.tokens--
+	}
+
+	em.AddLabel(Label, ns.Name)
+	return true
+}
+
+// drop records a dropped EventMetrics and logs about it, at most once every
+// dropLogInterval. It should be called with ns.mu held.
+func (ns *Namespace) drop(format string, args ...interface{}) {
+	ns.dropped++
+	if now := timeNow(); now.Sub(ns.lastLog) >= dropLogInterval {
+		ns.lastLog = now
+		ns.l.Warningf("namespace %s: dropping metrics, %s. Total dropped so far: %d", ns.Name, fmt.Sprintf(format, args...), ns.dropped)
+	}
+}
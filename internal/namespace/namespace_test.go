@@ -0,0 +1,138 @@
+// Copyright 2025 The Cloudprober Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package namespace
+
+import (
+	"testing"
+	"time"
+
+	configpb "github.com/cloudprober/cloudprober/config/proto"
+	"github.com/cloudprober/cloudprober/metrics"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/proto"
+)
+
+func testEM(dst string) *metrics.EventMetrics {
+	return metrics.NewEventMetrics(time.Now()).
+		AddMetric("total", metrics.NewInt(1)).
+		AddLabel("probe", "p1").
+		AddLabel("dst", dst)
+}
+
+func TestInit(t *testing.T) {
+	tests := []struct {
+		name    string
+		configs []*configpb.Namespace
+		want    []string
+		wantErr bool
+	}{
+		{
+			name: "valid",
+			configs: []*configpb.Namespace{
+				{Name: proto.String("team-a")},
+				{Name: proto.String("team-b")},
+			},
+			want: []string{"team-a", "team-b"},
+		},
+		{
+			name: "duplicate",
+			configs: []*configpb.Namespace{
+				{Name: proto.String("team-a")},
+				{Name: proto.String("team-a")},
+			},
+			wantErr: true,
+		},
+		{
+			name:    "no_name",
+			configs: []*configpb.Namespace{{}},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Init(tt.configs, nil)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Init() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			var names []string
+			for name := range got {
+				names = append(names, name)
+			}
+			assert.ElementsMatch(t, tt.want, names)
+		})
+	}
+}
+
+func TestRoutesTo(t *testing.T) {
+	ns, _ := New(&configpb.Namespace{Name: proto.String("team-a")}, nil)
+	assert.True(t, ns.RoutesTo("any"), "no surfacers configured")
+
+	ns, _ = New(&configpb.Namespace{
+		Name:     proto.String("team-a"),
+		Surfacer: []string{"s1", "s2"},
+	}, nil)
+	assert.True(t, ns.RoutesTo("s1"))
+	assert.True(t, ns.RoutesTo("s2"))
+	assert.False(t, ns.RoutesTo("s3"))
+}
+
+func TestAdmitRate(t *testing.T) {
+	now := time.Now()
+	timeNow = func() time.Time { return now }
+	defer func() { timeNow = time.Now }()
+
+	ns, _ := New(&configpb.Namespace{
+		Name:                  proto.String("team-a"),
+		MaxEventMetricsPerSec: proto.Int32(2),
+	}, nil)
+
+	// Bucket starts full.
+	assert.True(t, ns.Admit(testEM("t1")))
+	assert.True(t, ns.Admit(testEM("t1")))
+	assert.False(t, ns.Admit(testEM("t1")))
+
+	// Half a second later, we should have one more token.
+	now = now.Add(500 * time.Millisecond)
+	assert.True(t, ns.Admit(testEM("t1")))
+	assert.False(t, ns.Admit(testEM("t1")))
+
+	// Tokens don't accumulate beyond the rate.
+	now = now.Add(10 * time.Second)
+	assert.True(t, ns.Admit(testEM("t1")))
+	assert.True(t, ns.Admit(testEM("t1")))
+	assert.False(t, ns.Admit(testEM("t1")))
+
+	assert.Equal(t, int64(3), ns.dropped)
+}
+
+func TestAdmitSeries(t *testing.T) {
+	ns, _ := New(&configpb.Namespace{
+		Name:      proto.String("team-a"),
+		MaxSeries: proto.Int32(2),
+	}, nil)
+
+	assert.True(t, ns.Admit(testEM("t1")))
+	assert.True(t, ns.Admit(testEM("t2")))
+	assert.False(t, ns.Admit(testEM("t3")), "new series beyond quota")
+
+	// Existing series are still admitted.
+	em := testEM("t1")
+	assert.True(t, ns.Admit(em))
+	assert.Equal(t, "team-a", em.Label(Label))
+}
@@ -0,0 +1,102 @@
+// Copyright 2025 The Cloudprober Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+/*
+Package plugins implements loading of cloudprober extensions from Go plugin
+shared objects (.so files).
+
+A plugin is a Go package built with "go build -buildmode=plugin" against the
+same cloudprober version as the main binary. Plugins register their probes,
+surfacers and targets providers through the regular registration APIs
+(probes.RegisterUserDefined, probes.RegisterUserDefinedFactory,
+probes.RegisterProbeType, surfacers.Register, surfacers.RegisterFactory,
+targets.RegisterProvider, etc), typically in their init() functions. If a
+plugin exports an "Init" function with the signature "func() error", it's
+called right after the plugin is opened.
+*/
+package plugins
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"plugin"
+	"sort"
+	"strings"
+
+	"github.com/cloudprober/cloudprober/logger"
+)
+
+// InitSymbol is the optional plugin symbol that is called after a plugin has
+// been opened.
+const InitSymbol = "Init"
+
+type symbolLooker interface {
+	Lookup(symName string) (plugin.Symbol, error)
+}
+
+// openPlugin is a variable so that it can be overridden in tests.
+var openPlugin = func(path string) (symbolLooker, error) {
+	p, err := plugin.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+func loadPlugin(path string) error {
+	p, err := openPlugin(path)
+	if err != nil {
+		return err
+	}
+
+	sym, err := p.Lookup(InitSymbol)
+	if err != nil {
+		// Init symbol is optional.
+		return nil
+	}
+
+	initFunc, ok := sym.(func() error)
+	if !ok {
+		return fmt.Errorf("plugin symbol %s has unexpected type %T, expected func() error", InitSymbol, sym)
+	}
+	return initFunc()
+}
+
+// Load loads all plugins (files with .so extension) from the given directory,
+// in lexical order. It returns an error if any of the plugins fails to load.
+func Load(dir string, l *logger.Logger) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return fmt.Errorf("error reading plugins directory (%s): %v", dir, err)
+	}
+
+	var paths []string
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), ".so") {
+			continue
+		}
+		paths = append(paths, filepath.Join(dir, e.Name()))
+	}
+	sort.Strings(paths)
+
+	for _, path := range paths {
+		l.Infof("Loading plugin: %s", path)
+		if err := loadPlugin(path); err != nil {
+			return fmt.Errorf("error loading plugin (%s): %v", path, err)
+		}
+	}
+
+	return nil
+}
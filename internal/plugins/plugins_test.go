@@ -0,0 +1,123 @@
+// Copyright 2025 The Cloudprober Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plugins
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"plugin"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testPlugin struct {
+	syms map[string]plugin.Symbol
+}
+
+func (tp *testPlugin) Lookup(symName string) (plugin.Symbol, error) {
+	if sym, ok := tp.syms[symName]; ok {
+		return sym, nil
+	}
+	return nil, errors.New("symbol not found")
+}
+
+func TestLoad(t *testing.T) {
+	var initCalls []string
+
+	testPlugins := map[string]*testPlugin{
+		"a.so": {syms: map[string]plugin.Symbol{
+			"Init": func() error { initCalls = append(initCalls, "a"); return nil },
+		}},
+		"b.so": {}, // No Init symbol.
+		"c.so": {syms: map[string]plugin.Symbol{
+			"Init": func() error { initCalls = append(initCalls, "c"); return nil },
+		}},
+		"bad-init.so": {syms: map[string]plugin.Symbol{
+			"Init": func() error { return errors.New("init error") },
+		}},
+		"bad-sym.so": {syms: map[string]plugin.Symbol{
+			"Init": func() {},
+		}},
+	}
+
+	oldOpenPlugin := openPlugin
+	defer func() { openPlugin = oldOpenPlugin }()
+	openPlugin = func(path string) (symbolLooker, error) {
+		p := testPlugins[filepath.Base(path)]
+		if p == nil {
+			return nil, errors.New("not a plugin")
+		}
+		return p, nil
+	}
+
+	tests := []struct {
+		name          string
+		files         []string
+		wantInitCalls []string
+		wantErr       bool
+	}{
+		{
+			name:          "valid",
+			files:         []string{"c.so", "b.so", "a.so", "README.md"},
+			wantInitCalls: []string{"a", "c"},
+		},
+		{
+			name:  "empty",
+			files: []string{},
+		},
+		{
+			name:    "bad_plugin",
+			files:   []string{"a.so", "not-a-plugin.so"},
+			wantErr: true,
+		},
+		{
+			name:    "init_error",
+			files:   []string{"bad-init.so"},
+			wantErr: true,
+		},
+		{
+			name:    "bad_init_symbol",
+			files:   []string{"bad-sym.so"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			initCalls = nil
+
+			dir := t.TempDir()
+			for _, f := range tt.files {
+				if err := os.WriteFile(filepath.Join(dir, f), nil, 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			err := Load(dir, nil)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Load() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr {
+				assert.Equal(t, tt.wantInitCalls, initCalls)
+			}
+		})
+	}
+
+	if err := Load(filepath.Join(t.TempDir(), "missing"), nil); err == nil {
+		t.Errorf("Expected error for missing plugins dir")
+	}
+}
@@ -42,6 +42,7 @@ type CWSurfacer struct {
 	c         *configpb.SurfacerConf
 	opts      *options.Options
 	writeChan chan *metrics.EventMetrics
+	flushChan chan chan struct{}
 	session   *cloudwatch.Client
 	l         *logger.Logger
 
@@ -65,6 +66,7 @@ func New(ctx context.Context, conf *configpb.SurfacerConf, opts *options.Options
 		c:                conf,
 		opts:             opts,
 		writeChan:        make(chan *metrics.EventMetrics, opts.Config.GetMetricsBufferSize()), // incoming internal metrics buffer
+		flushChan:        make(chan chan struct{}),
 		session:          cloudwatch.NewFromConfig(cfg),
 		l:                l,
 		metricDatumCache: make([]types.MetricDatum, 0, int(conf.GetMetricsBatchSize())), // batching buffer between cloudprober and cloudwatch
@@ -97,6 +99,14 @@ func (cw *CWSurfacer) processIncomingMetrics(ctx context.Context) {
 			return
 		case em := <-cw.writeChan:
 			cw.recordEventMetrics(ctx, publishTimer, em)
+		case done := <-cw.flushChan:
+			for n := len(cw.writeChan); n > 0; n-- {
+				cw.recordEventMetrics(ctx, publishTimer, <-cw.writeChan)
+			}
+			if len(cw.metricDatumCache) != 0 {
+				cw.publishMetrics(ctx)
+			}
+			close(done)
 		case <-publishTimer.C: // the ticker will reset when metrics are published in cw.addMetricAndPublish
 			if len(cw.metricDatumCache) != 0 {
 				cw.publishMetrics(ctx)
@@ -105,6 +115,20 @@ func (cw *CWSurfacer) processIncomingMetrics(ctx context.Context) {
 	}
 }
 
+// Flush publishes the metrics that are waiting in the surfacer's buffers.
+func (cw *CWSurfacer) Flush(ctx context.Context) {
+	done := make(chan struct{})
+	select {
+	case cw.flushChan <- done:
+	case <-ctx.Done():
+		return
+	}
+	select {
+	case <-done:
+	case <-ctx.Done():
+	}
+}
+
 func recordMapValue[T int64 | float64](ctx context.Context, cw *CWSurfacer, key string, m *metrics.Map[T], d []types.Dimension, em *metrics.EventMetrics, publishTimer *time.Ticker) {
 	for _, mapKey := range m.Keys() {
 		newDimensions := append(d, types.Dimension{
@@ -48,6 +48,7 @@ type DDSurfacer struct {
 	c         *configpb.SurfacerConf
 	opts      *options.Options
 	writeChan chan *metrics.EventMetrics
+	flushChan chan chan struct{}
 	client    *ddClient
 	l         *logger.Logger
 	prefix    string
@@ -74,6 +75,7 @@ func New(ctx context.Context, config *configpb.SurfacerConf, opts *options.Optio
 	dd := &DDSurfacer{
 		c:             config,
 		writeChan:     make(chan *metrics.EventMetrics, config.GetMetricsBatchSize()),
+		flushChan:     make(chan chan struct{}),
 		client:        newClient(config.GetServer(), config.GetApiKey(), config.GetAppKey(), config.GetDisableCompression()),
 		l:             l,
 		prefix:        p,
@@ -107,6 +109,14 @@ func (dd *DDSurfacer) receiveMetricsFromEvent(ctx context.Context) {
 			return
 		case em := <-dd.writeChan:
 			dd.recordEventMetrics(ctx, publishTimer, em)
+		case done := <-dd.flushChan:
+			for n := len(dd.writeChan); n > 0; n-- {
+				dd.recordEventMetrics(ctx, publishTimer, <-dd.writeChan)
+			}
+			if len(dd.ddSeriesCache) != 0 {
+				dd.publishMetrics(ctx)
+			}
+			close(done)
 		case <-publishTimer.C:
 			if len(dd.ddSeriesCache) != 0 {
 				dd.publishMetrics(ctx)
@@ -115,6 +125,20 @@ func (dd *DDSurfacer) receiveMetricsFromEvent(ctx context.Context) {
 	}
 }
 
+// Flush publishes the metrics that are waiting in the surfacer's buffers.
+func (dd *DDSurfacer) Flush(ctx context.Context) {
+	done := make(chan struct{})
+	select {
+	case dd.flushChan <- done:
+	case <-ctx.Done():
+		return
+	}
+	select {
+	case <-done:
+	case <-ctx.Done():
+	}
+}
+
 func recordMapValue[T int64 | float64](dd *DDSurfacer, m *metrics.Map[T], baseTags []string, key string, em *metrics.EventMetrics) []ddSeries {
 	var series []ddSeries
 	for _, k := range m.Keys() {
@@ -15,11 +15,17 @@
 package datadog
 
 import (
+	"context"
+	"net/http"
+	"net/http/httptest"
 	"reflect"
+	"sync/atomic"
 	"testing"
 	"time"
 
+	configpb "github.com/cloudprober/cloudprober/internal/surfacers/datadog/proto"
 	"github.com/cloudprober/cloudprober/metrics"
+	"google.golang.org/protobuf/proto"
 )
 
 func TestEmLabelsToTags(t *testing.T) {
@@ -55,3 +61,37 @@ func TestEmLabelsToTags(t *testing.T) {
 		})
 	}
 }
+
+func TestFlush(t *testing.T) {
+	var requests atomic.Int32
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+	}))
+	defer ts.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Large batch size and timer, so that nothing is published without a flush.
+	dd, err := New(ctx, &configpb.SurfacerConf{
+		ApiKey:        proto.String("test-api-key"),
+		Server:        proto.String(ts.Listener.Addr().String()),
+		BatchTimerSec: proto.Int32(3600),
+	}, nil, nil)
+	if err != nil {
+		t.Fatalf("Error creating surfacer: %v", err)
+	}
+	dd.client.c = *ts.Client()
+
+	dd.Write(ctx, metrics.NewEventMetrics(time.Now()).AddMetric("total", metrics.NewInt(1)))
+	dd.Flush(ctx)
+	if got := requests.Load(); got != 1 {
+		t.Errorf("Requests after flush: %d, want: 1", got)
+	}
+
+	// Nothing to publish.
+	dd.Flush(ctx)
+	if got := requests.Load(); got != 1 {
+		t.Errorf("Requests after second flush: %d, want: 1", got)
+	}
+}
@@ -66,6 +66,24 @@ type Surfacer struct {
 	closeOnce sync.Once
 }
 
+// formatLine formats the EventMetrics as an output line, as per the
+// configured format.
+func (s *Surfacer) formatLine(em *metrics.EventMetrics) (string, error) {
+	if s.c.GetFormat() == configpb.SurfacerConf_JSON {
+		b, err := marshalJSON(em, s.opts.IgnoreMetric)
+		return string(b), err
+	}
+
+	var emStr strings.Builder
+	emStr.WriteString(s.c.GetPrefix())
+	emStr.WriteByte(' ')
+	emStr.WriteString(strconv.FormatInt(s.id, 10))
+	emStr.WriteByte(' ')
+	emStr.WriteString(em.String(metrics.StringerIgnoreMetric(s.opts.IgnoreMetric)))
+	s.id++
+	return emStr.String(), nil
+}
+
 func (s *Surfacer) processInput(ctx context.Context) {
 	defer s.processInputWg.Done()
 
@@ -76,21 +94,19 @@ func (s *Surfacer) processInput(ctx context.Context) {
 			if !ok {
 				return
 			}
-			var emStr strings.Builder
-			emStr.WriteString(s.c.GetPrefix())
-			emStr.WriteByte(' ')
-			emStr.WriteString(strconv.FormatInt(s.id, 10))
-			emStr.WriteByte(' ')
-			emStr.WriteString(em.String(metrics.StringerIgnoreMetric(s.opts.IgnoreMetric)))
-			s.id++
+			line, err := s.formatLine(em)
+			if err != nil {
+				s.l.Errorf("Error formatting EventMetrics (%s): %v", em.String(), err)
+				continue
+			}
 
 			// If compression is not enabled, write line to file and continue.
 			if !s.c.GetCompressionEnabled() {
-				if _, err := s.outf.WriteString(emStr.String() + "\n"); err != nil {
+				if _, err := s.outf.WriteString(line + "\n"); err != nil {
 					s.l.Errorf("Unable to write data to %s. Err: %v", s.c.GetFilePath(), err)
 				}
 			} else {
-				s.compressionBuffer.WriteLineToBuffer(emStr.String())
+				s.compressionBuffer.WriteLineToBuffer(line)
 			}
 
 		case <-ctx.Done():
@@ -0,0 +1,128 @@
+// Copyright 2025 The Cloudprober Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package file
+
+import (
+	"encoding/json"
+	"math"
+	"time"
+
+	"github.com/cloudprober/cloudprober/metrics"
+)
+
+// jsonEventMetrics is the JSON representation of an EventMetrics.
+type jsonEventMetrics struct {
+	Timestamp string            `json:"timestamp"`
+	Kind      string            `json:"kind"`
+	Probe     string            `json:"probe,omitempty"`
+	Target    string            `json:"target,omitempty"`
+	Labels    map[string]string `json:"labels,omitempty"`
+	Values    map[string]any    `json:"values"`
+}
+
+type jsonDistribution struct {
+	Count        int64   `json:"count"`
+	Sum          any     `json:"sum"`
+	LowerBounds  []any   `json:"lower_bounds"`
+	BucketCounts []int64 `json:"bucket_counts"`
+}
+
+// jsonFloat returns a JSON friendly representation of a float. JSON doesn't
+// support NaN and infinity, we use strings for them.
+func jsonFloat(f float64) any {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return metrics.FloatToString(f)
+	}
+	return f
+}
+
+func jsonMap[T metrics.Number](m *metrics.Map[T]) map[string]any {
+	out := make(map[string]any, len(m.Keys()))
+	for _, k := range m.Keys() {
+		switch v := any(m.GetKey(k)).(type) {
+		case float64:
+			out[k] = jsonFloat(v)
+		default:
+			out[k] = v
+		}
+	}
+	return out
+}
+
+func jsonValue(v metrics.Value) any {
+	switch v := v.(type) {
+	case *metrics.Float:
+		return jsonFloat(v.Float64())
+	case metrics.NumValue:
+		return v.Int64()
+	case metrics.String:
+		// String() returns the quoted value, e.g. "v1", let the JSON encoder
+		// marshal the underlying string instead.
+		return v
+	case *metrics.Map[int64]:
+		return jsonMap(v)
+	case *metrics.Map[float64]:
+		return jsonMap(v)
+	case *metrics.Distribution:
+		d := v.Data()
+		jd := &jsonDistribution{
+			Count:        d.Count,
+			Sum:          jsonFloat(d.Sum),
+			BucketCounts: d.BucketCounts,
+		}
+		for _, lb := range d.LowerBounds {
+			jd.LowerBounds = append(jd.LowerBounds, jsonFloat(lb))
+		}
+		return jd
+	default:
+		return v.String()
+	}
+}
+
+// marshalJSON returns the JSON representation of the EventMetrics, as a
+// single line. Metrics for which ignoreMetric returns true are skipped.
+func marshalJSON(em *metrics.EventMetrics, ignoreMetric func(string) bool) ([]byte, error) {
+	jem := &jsonEventMetrics{
+		Timestamp: em.Timestamp.UTC().Format(time.RFC3339Nano),
+		Kind:      "cumulative",
+		Values:    make(map[string]any),
+	}
+	if em.Kind == metrics.GAUGE {
+		jem.Kind = "gauge"
+	}
+
+	for _, k := range em.LabelsKeys() {
+		switch k {
+		case "probe":
+			jem.Probe = em.Label(k)
+		case "dst":
+			jem.Target = em.Label(k)
+		default:
+			if jem.Labels == nil {
+				jem.Labels = make(map[string]string)
+			}
+			jem.Labels[k] = em.Label(k)
+		}
+	}
+
+	for _, k := range em.MetricsKeys() {
+		if ignoreMetric != nil && ignoreMetric(k) {
+			continue
+		}
+		jem.Values[k] = jsonValue(em.Metric(k))
+	}
+
+	return json.Marshal(jem)
+}
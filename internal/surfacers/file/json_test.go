@@ -0,0 +1,97 @@
+// Copyright 2025 The Cloudprober Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package file
+
+import (
+	"context"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	configpb "github.com/cloudprober/cloudprober/internal/surfacers/file/proto"
+	"github.com/cloudprober/cloudprober/metrics"
+	"github.com/cloudprober/cloudprober/surfacers/options"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/proto"
+)
+
+func testJSONEventMetrics() *metrics.EventMetrics {
+	ts := time.Date(2025, time.January, 2, 15, 4, 5, 0, time.UTC)
+	dist := metrics.NewDistribution([]float64{1, 10})
+	dist.AddSample(5)
+
+	return metrics.NewEventMetrics(ts).
+		AddMetric("total", metrics.NewInt(10)).
+		AddMetric("latency", metrics.NewFloat(1.5)).
+		AddMetric("resp-code", metrics.NewMap("code").IncKeyBy("200", 9)).
+		AddMetric("version", metrics.NewString("v1")).
+		AddMetric("quoted", metrics.NewString(`"v1" build`)).
+		AddMetric("lat-dist", dist).
+		AddMetric("nan", metrics.NewFloat(math.NaN())).
+		AddLabel("ptype", "http").
+		AddLabel("probe", "web").
+		AddLabel("dst", "www.example.com")
+}
+
+func TestMarshalJSON(t *testing.T) {
+	b, err := marshalJSON(testJSONEventMetrics(), func(name string) bool { return name == "nan" })
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{
+		"timestamp": "2025-01-02T15:04:05Z",
+		"kind": "cumulative",
+		"probe": "web",
+		"target": "www.example.com",
+		"labels": {"ptype": "http"},
+		"values": {
+			"total": 10,
+			"latency": 1.5,
+			"resp-code": {"200": 9},
+			"version": "v1",
+			"quoted": "\"v1\" build",
+			"lat-dist": {"count": 1, "sum": 5, "lower_bounds": ["-Inf", 1, 10], "bucket_counts": [0, 1, 0]}
+		}
+	}`, string(b))
+
+	// NaN values should not break JSON encoding.
+	b, err = marshalJSON(testJSONEventMetrics(), nil)
+	assert.NoError(t, err)
+	assert.Contains(t, string(b), `"nan":"NaN"`)
+}
+
+func TestWriteJSON(t *testing.T) {
+	fileName := filepath.Join(t.TempDir(), "out.json")
+	s := &Surfacer{
+		c: &configpb.SurfacerConf{
+			FilePath: proto.String(fileName),
+			Format:   configpb.SurfacerConf_JSON.Enum(),
+		},
+		opts: &options.Options{MetricsBufferSize: 10},
+	}
+	assert.NoError(t, s.init(context.Background(), 1))
+
+	em := testJSONEventMetrics()
+	s.Write(context.Background(), em)
+	s.Write(context.Background(), em)
+	s.close()
+
+	want, err := marshalJSON(em, nil)
+	assert.NoError(t, err)
+
+	dat, err := os.ReadFile(fileName)
+	assert.NoError(t, err)
+	assert.Equal(t, string(want)+"\n"+string(want)+"\n", string(dat))
+}
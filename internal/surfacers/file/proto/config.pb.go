@@ -21,22 +21,91 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
+type SurfacerConf_Format int32
+
+const (
+	// One line per EventMetrics: "<prefix> <id> <timestamp> labels=... <metrics>"
+	SurfacerConf_TEXT SurfacerConf_Format = 0
+	// One JSON object per EventMetrics, e.g.:
+	// {"timestamp":"2025-01-02T15:04:05.123Z","kind":"cumulative",
+	//
+	//	"probe":"web","target":"www.example.com","labels":{"ptype":"http"},
+	//	"values":{"total":10,"success":9,"resp-code":{"200":9}}}
+	//
+	// Map values are written as JSON objects, and distributions as objects with
+	// "count", "sum", "lower_bounds" and "bucket_counts" fields.
+	SurfacerConf_JSON SurfacerConf_Format = 1
+)
+
+// Enum value maps for SurfacerConf_Format.
+var (
+	SurfacerConf_Format_name = map[int32]string{
+		0: "TEXT",
+		1: "JSON",
+	}
+	SurfacerConf_Format_value = map[string]int32{
+		"TEXT": 0,
+		"JSON": 1,
+	}
+)
+
+func (x SurfacerConf_Format) Enum() *SurfacerConf_Format {
+	p := new(SurfacerConf_Format)
+	*p = x
+	return p
+}
+
+func (x SurfacerConf_Format) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (SurfacerConf_Format) Descriptor() protoreflect.EnumDescriptor {
+	return file_github_com_cloudprober_cloudprober_internal_surfacers_file_proto_config_proto_enumTypes[0].Descriptor()
+}
+
+func (SurfacerConf_Format) Type() protoreflect.EnumType {
+	return &file_github_com_cloudprober_cloudprober_internal_surfacers_file_proto_config_proto_enumTypes[0]
+}
+
+func (x SurfacerConf_Format) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Do not use.
+func (x *SurfacerConf_Format) UnmarshalJSON(b []byte) error {
+	num, err := protoimpl.X.UnmarshalJSONEnum(x.Descriptor(), b)
+	if err != nil {
+		return err
+	}
+	*x = SurfacerConf_Format(num)
+	return nil
+}
+
+// Deprecated: Use SurfacerConf_Format.Descriptor instead.
+func (SurfacerConf_Format) EnumDescriptor() ([]byte, []int) {
+	return file_github_com_cloudprober_cloudprober_internal_surfacers_file_proto_config_proto_rawDescGZIP(), []int{0, 0}
+}
+
 type SurfacerConf struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
 	// Where to write the results. If left unset, file surfacer writes to the
 	// standard output.
 	FilePath *string `protobuf:"bytes,1,opt,name=file_path,json=filePath" json:"file_path,omitempty"`
-	Prefix   *string `protobuf:"bytes,2,opt,name=prefix,def=cloudprober" json:"prefix,omitempty"`
+	// Prefix for the output lines. It's used only for the TEXT format.
+	Prefix *string `protobuf:"bytes,2,opt,name=prefix,def=cloudprober" json:"prefix,omitempty"`
 	// Compress data before writing to the file.
 	CompressionEnabled *bool `protobuf:"varint,3,opt,name=compression_enabled,json=compressionEnabled,def=0" json:"compression_enabled,omitempty"`
-	unknownFields      protoimpl.UnknownFields
-	sizeCache          protoimpl.SizeCache
+	// Output format.
+	Format        *SurfacerConf_Format `protobuf:"varint,4,opt,name=format,enum=cloudprober.surfacer.file.SurfacerConf_Format,def=0" json:"format,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
 // Default values for SurfacerConf fields.
 const (
 	Default_SurfacerConf_Prefix             = string("cloudprober")
 	Default_SurfacerConf_CompressionEnabled = bool(false)
+	Default_SurfacerConf_Format             = SurfacerConf_TEXT
 )
 
 func (x *SurfacerConf) Reset() {
@@ -90,15 +159,26 @@ func (x *SurfacerConf) GetCompressionEnabled() bool {
 	return Default_SurfacerConf_CompressionEnabled
 }
 
+func (x *SurfacerConf) GetFormat() SurfacerConf_Format {
+	if x != nil && x.Format != nil {
+		return *x.Format
+	}
+	return Default_SurfacerConf_Format
+}
+
 var File_github_com_cloudprober_cloudprober_internal_surfacers_file_proto_config_proto protoreflect.FileDescriptor
 
 const file_github_com_cloudprober_cloudprober_internal_surfacers_file_proto_config_proto_rawDesc = "" +
 	"\n" +
-	"Mgithub.com/cloudprober/cloudprober/internal/surfacers/file/proto/config.proto\x12\x19cloudprober.surfacer.file\"\x88\x01\n" +
+	"Mgithub.com/cloudprober/cloudprober/internal/surfacers/file/proto/config.proto\x12\x19cloudprober.surfacer.file\"\xf4\x01\n" +
 	"\fSurfacerConf\x12\x1b\n" +
 	"\tfile_path\x18\x01 \x01(\tR\bfilePath\x12#\n" +
 	"\x06prefix\x18\x02 \x01(\t:\vcloudproberR\x06prefix\x126\n" +
-	"\x13compression_enabled\x18\x03 \x01(\b:\x05falseR\x12compressionEnabledBBZ@github.com/cloudprober/cloudprober/internal/surfacers/file/proto"
+	"\x13compression_enabled\x18\x03 \x01(\b:\x05falseR\x12compressionEnabled\x12L\n" +
+	"\x06format\x18\x04 \x01(\x0e2..cloudprober.surfacer.file.SurfacerConf.Format:\x04TEXTR\x06format\"\x1c\n" +
+	"\x06Format\x12\b\n" +
+	"\x04TEXT\x10\x00\x12\b\n" +
+	"\x04JSON\x10\x01BBZ@github.com/cloudprober/cloudprober/internal/surfacers/file/proto"
 
 var (
 	file_github_com_cloudprober_cloudprober_internal_surfacers_file_proto_config_proto_rawDescOnce sync.Once
@@ -112,16 +192,19 @@ func file_github_com_cloudprober_cloudprober_internal_surfacers_file_proto_confi
 	return file_github_com_cloudprober_cloudprober_internal_surfacers_file_proto_config_proto_rawDescData
 }
 
+var file_github_com_cloudprober_cloudprober_internal_surfacers_file_proto_config_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
 var file_github_com_cloudprober_cloudprober_internal_surfacers_file_proto_config_proto_msgTypes = make([]protoimpl.MessageInfo, 1)
 var file_github_com_cloudprober_cloudprober_internal_surfacers_file_proto_config_proto_goTypes = []any{
-	(*SurfacerConf)(nil), // 0: cloudprober.surfacer.file.SurfacerConf
+	(SurfacerConf_Format)(0), // 0: cloudprober.surfacer.file.SurfacerConf.Format
+	(*SurfacerConf)(nil),     // 1: cloudprober.surfacer.file.SurfacerConf
 }
 var file_github_com_cloudprober_cloudprober_internal_surfacers_file_proto_config_proto_depIdxs = []int32{
-	0, // [0:0] is the sub-list for method output_type
-	0, // [0:0] is the sub-list for method input_type
-	0, // [0:0] is the sub-list for extension type_name
-	0, // [0:0] is the sub-list for extension extendee
-	0, // [0:0] is the sub-list for field type_name
+	0, // 0: cloudprober.surfacer.file.SurfacerConf.format:type_name -> cloudprober.surfacer.file.SurfacerConf.Format
+	1, // [1:1] is the sub-list for method output_type
+	1, // [1:1] is the sub-list for method input_type
+	1, // [1:1] is the sub-list for extension type_name
+	1, // [1:1] is the sub-list for extension extendee
+	0, // [0:1] is the sub-list for field type_name
 }
 
 func init() {
@@ -136,13 +219,14 @@ func file_github_com_cloudprober_cloudprober_internal_surfacers_file_proto_confi
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_github_com_cloudprober_cloudprober_internal_surfacers_file_proto_config_proto_rawDesc), len(file_github_com_cloudprober_cloudprober_internal_surfacers_file_proto_config_proto_rawDesc)),
-			NumEnums:      0,
+			NumEnums:      1,
 			NumMessages:   1,
 			NumExtensions: 0,
 			NumServices:   0,
 		},
 		GoTypes:           file_github_com_cloudprober_cloudprober_internal_surfacers_file_proto_config_proto_goTypes,
 		DependencyIndexes: file_github_com_cloudprober_cloudprober_internal_surfacers_file_proto_config_proto_depIdxs,
+		EnumInfos:         file_github_com_cloudprober_cloudprober_internal_surfacers_file_proto_config_proto_enumTypes,
 		MessageInfos:      file_github_com_cloudprober_cloudprober_internal_surfacers_file_proto_config_proto_msgTypes,
 	}.Build()
 	File_github_com_cloudprober_cloudprober_internal_surfacers_file_proto_config_proto = out.File
@@ -0,0 +1,200 @@
+// Copyright 2025 The Cloudprober Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package probestatus
+
+// This file implements a Grafana JSON datasource (SimpleJSON) compatible API
+// on top of the in-memory timeseries:
+//   GET  <url>/grafana/            - Connection test.
+//   POST <url>/grafana/search      - List of the available series.
+//   POST <url>/grafana/query       - Datapoints for the requested series.
+//   POST <url>/grafana/annotations - Always empty.
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/cloudprober/cloudprober/state"
+)
+
+var grafanaMetrics = []string{"availability", "total", "success"}
+
+type grafanaQueryRequest struct {
+	Range struct {
+		From time.Time `json:"from"`
+		To   time.Time `json:"to"`
+	} `json:"range"`
+	IntervalMs    int64 `json:"intervalMs"`
+	MaxDataPoints int   `json:"maxDataPoints"`
+	Targets       []struct {
+		Target string `json:"target"`
+	} `json:"targets"`
+}
+
+type grafanaSeries struct {
+	Target string `json:"target"`
+	// Each datapoint is a [value, unix time in milliseconds] pair.
+	Datapoints [][2]float64 `json:"datapoints"`
+}
+
+// parseGrafanaTarget parses series name of the form <probe>/<target>/<metric>.
+// Target name itself may contain slashes, e.g. for URL targets.
+func parseGrafanaTarget(name string) (probe, target, metric string, err error) {
+	first, last := strings.Index(name, "/"), strings.LastIndex(name, "/")
+	if first == -1 || first == last {
+		return "", "", "", fmt.Errorf("invalid series name: %s, expected <probe>/<target>/<metric>", name)
+	}
+	probe, target, metric = name[:first], name[first+1:last], name[last+1:]
+	for _, m := range grafanaMetrics {
+		if m == metric {
+			return probe, target, metric, nil
+		}
+	}
+	return "", "", "", fmt.Errorf("invalid metric: %s, should be one of: %s", metric, strings.Join(grafanaMetrics, ","))
+}
+
+func (ps *Surfacer) grafanaSearch() []string {
+	var names []string
+	for _, probeName := range ps.probeNames {
+		for _, targetName := range ps.probeTargets[probeName] {
+			for _, m := range grafanaMetrics {
+				names = append(names, probeName+"/"+targetName+"/"+m)
+			}
+		}
+	}
+	return names
+}
+
+// grafanaDatapoints returns datapoints for the given timeseries and metric,
+// between from and to, in the ascending order of time. Like the status page
+// graphs, we assume that the timeseries buckets are contiguous.
+func grafanaDatapoints(ts *timeseries, metric string, from, to time.Time, step int) [][2]float64 {
+	if step < 1 {
+		step = 1
+	}
+
+	ts = ts.shallowCopy()
+
+	var points [][2]float64
+	bucketTime := ts.currentTS
+	for ts.latest != ts.oldest && !bucketTime.Before(from) {
+		currentD := ts.a[ts.latest]
+		ts.latest = ts.agoIndex(step)
+		lastD := ts.a[ts.latest]
+
+		if !bucketTime.After(to) {
+			var val float64
+			switch metric {
+			case "availability":
+				val = float64(currentD.success-lastD.success) / float64(currentD.total-lastD.total)
+			case "total":
+				val = float64(currentD.total - lastD.total)
+			case "success":
+				val = float64(currentD.success - lastD.success)
+			}
+			if !math.IsNaN(val) {
+				points = append(points, [2]float64{val, float64(bucketTime.UnixMilli())})
+			}
+		}
+		bucketTime = bucketTime.Add(-time.Duration(step) * ts.res)
+	}
+
+	// Reverse to get the ascending order.
+	for i, j := 0, len(points)-1; i < j; i, j = i+1, j-1 {
+		points[i], points[j] = points[j], points[i]
+	}
+	return points
+}
+
+func (ps *Surfacer) grafanaQuery(req *grafanaQueryRequest) ([]*grafanaSeries, error) {
+	step := 1
+	if req.IntervalMs > 0 {
+		step = int(time.Duration(req.IntervalMs) * time.Millisecond / ps.resolution)
+	}
+	// Honor maxDataPoints, if required by increasing the step.
+	if req.MaxDataPoints > 0 {
+		if n := int(req.Range.To.Sub(req.Range.From) / ps.resolution); n/max(step, 1) > req.MaxDataPoints {
+			step = int(math.Ceil(float64(n) / float64(req.MaxDataPoints)))
+		}
+	}
+
+	result := []*grafanaSeries{}
+	for _, t := range req.Targets {
+		probeName, targetName, metric, err := parseGrafanaTarget(t.Target)
+		if err != nil {
+			return nil, err
+		}
+		series := &grafanaSeries{Target: t.Target, Datapoints: [][2]float64{}}
+		if ts := ps.metrics[probeName][targetName]; ts != nil {
+			if dp := grafanaDatapoints(ts, metric, req.Range.From, req.Range.To, step); dp != nil {
+				series.Datapoints = dp
+			}
+		}
+		result = append(result, series)
+	}
+	return result, nil
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func (ps *Surfacer) grafanaHandler(w http.ResponseWriter, r *http.Request) {
+	switch strings.TrimPrefix(r.URL.Path, ps.c.GetUrl()+"/grafana") {
+	case "", "/":
+		w.Write([]byte("OK"))
+	case "/search":
+		writeJSON(w, ps.grafanaSearch())
+	case "/query":
+		req := &grafanaQueryRequest{}
+		if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+			http.Error(w, "error parsing query request: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		result, err := ps.grafanaQuery(req)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		writeJSON(w, result)
+	case "/annotations":
+		writeJSON(w, []any{})
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (ps *Surfacer) addGrafanaHandlers(ctx context.Context) error {
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		// Like the status page, we process the requests in the same goroutine
+		// that updates the timeseries, to avoid data races.
+		doneChan := make(chan struct{}, 1)
+		ps.queryChan <- &httpWriter{w: w, r: r, doneChan: doneChan, handler: ps.grafanaHandler}
+		<-doneChan
+	}
+	for _, path := range []string{"/grafana", "/grafana/"} {
+		if err := state.AddWebHandlerWithContext(ctx, ps.c.GetUrl()+path, handler); err != nil {
+			return fmt.Errorf("error adding grafana datasource handler: %v", err)
+		}
+	}
+	return nil
+}
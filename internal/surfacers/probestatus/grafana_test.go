@@ -0,0 +1,127 @@
+// Copyright 2025 The Cloudprober Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package probestatus
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	configpb "github.com/cloudprober/cloudprober/internal/surfacers/probestatus/proto"
+	"github.com/cloudprober/cloudprober/state"
+	"github.com/cloudprober/cloudprober/surfacers/options"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestParseGrafanaTarget(t *testing.T) {
+	tests := []struct {
+		name                  string
+		wantProbe, wantTarget string
+		wantMetric            string
+		wantErr               bool
+	}{
+		{name: "p1/t1/availability", wantProbe: "p1", wantTarget: "t1", wantMetric: "availability"},
+		{name: "p1/https://t1/path/total", wantProbe: "p1", wantTarget: "https://t1/path", wantMetric: "total"},
+		{name: "p1//success", wantProbe: "p1", wantTarget: "", wantMetric: "success"},
+		{name: "p1/t1/latency", wantErr: true},
+		{name: "p1/availability", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			probe, target, metric, err := parseGrafanaTarget(tt.name)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseGrafanaTarget() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			assert.Equal(t, tt.wantProbe, probe)
+			assert.Equal(t, tt.wantTarget, target)
+			assert.Equal(t, tt.wantMetric, metric)
+		})
+	}
+}
+
+func TestGrafanaDatasource(t *testing.T) {
+	mux := http.NewServeMux()
+	state.SetDefaultHTTPServeMux(mux)
+	defer state.SetDefaultHTTPServeMux(nil)
+
+	ctx, cancelFunc := context.WithCancel(context.Background())
+	defer cancelFunc()
+
+	ps, err := New(ctx, &configpb.SurfacerConf{
+		TimeseriesSize:    proto.Int32(10),
+		GrafanaDatasource: proto.Bool(true),
+	}, &options.Options{}, nil)
+	if err != nil {
+		t.Fatalf("Error creating surfacer: %v", err)
+	}
+
+	// 5 minutes of data, every minute we get 10 more probes, with 1, 2, 3..
+	// failures.
+	endTime := time.Now().Truncate(time.Minute)
+	total, success := 0, 0
+	for i := 0; i < 5; i++ {
+		total, success = total+10, success+10-i
+		ps.record(testEM(t, endTime.Add(time.Duration(i-4)*time.Minute), "p1", "t1", total, success, 0))
+	}
+
+	do := func(path, body string) *httptest.ResponseRecorder {
+		t.Helper()
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, httptest.NewRequest(http.MethodPost, path, strings.NewReader(body)))
+		return w
+	}
+
+	assert.Equal(t, http.StatusOK, do("/status/grafana", "").Code)
+
+	var search []string
+	assert.NoError(t, json.Unmarshal(do("/status/grafana/search", "{}").Body.Bytes(), &search))
+	assert.Equal(t, []string{"p1/t1/availability", "p1/t1/total", "p1/t1/success"}, search)
+
+	query := fmt.Sprintf(`{
+		"range": {"from": "%s", "to": "%s"},
+		"targets": [{"target": "p1/t1/availability"}, {"target": "p1/t1/total"}, {"target": "p1/t2/total"}]
+	}`, endTime.Add(-2*time.Minute).Format(time.RFC3339), endTime.Format(time.RFC3339))
+
+	var series []*grafanaSeries
+	w := do("/status/grafana/query", query)
+	assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &series))
+
+	ms := func(d time.Duration) float64 { return float64(endTime.Add(d).UnixMilli()) }
+	assert.Equal(t, []*grafanaSeries{
+		{
+			Target:     "p1/t1/availability",
+			Datapoints: [][2]float64{{0.8, ms(-2 * time.Minute)}, {0.7, ms(-time.Minute)}, {0.6, ms(0)}},
+		},
+		{
+			Target:     "p1/t1/total",
+			Datapoints: [][2]float64{{10, ms(-2 * time.Minute)}, {10, ms(-time.Minute)}, {10, ms(0)}},
+		},
+		{
+			Target:     "p1/t2/total",
+			Datapoints: [][2]float64{},
+		},
+	}, series)
+
+	// Bad series name.
+	assert.Equal(t, http.StatusBadRequest, do("/status/grafana/query", `{"targets": [{"target": "p1/t1/latency"}]}`).Code)
+}
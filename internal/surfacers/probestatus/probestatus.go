@@ -60,6 +60,10 @@ type httpWriter struct {
 	w        http.ResponseWriter
 	r        *http.Request
 	doneChan chan struct{}
+
+	// If set, handler is used to write the response, instead of the status
+	// page.
+	handler http.HandlerFunc
 }
 
 type pageCache struct {
@@ -165,17 +169,21 @@ func New(ctx context.Context, config *configpb.SurfacerConf, opts *options.Optio
 			case em := <-ps.emChan:
 				ps.record(em)
 			case hw := <-ps.queryChan:
-				ps.writeData(hw)
+				if hw.handler != nil {
+					hw.handler(hw.w, hw.r)
+				} else {
+					ps.writeData(hw)
+				}
 				close(hw.doneChan)
 			}
 		}
 	}()
 
-	state.AddWebHandler(config.GetUrl(), func(w http.ResponseWriter, r *http.Request) {
+	state.AddWebHandlerWithContext(ctx, config.GetUrl(), func(w http.ResponseWriter, r *http.Request) {
 		// doneChan is used to track the completion of the response writing. This is
 		// required as response is written in a different goroutine.
 		doneChan := make(chan struct{}, 1)
-		ps.queryChan <- &httpWriter{w, r, doneChan}
+		ps.queryChan <- &httpWriter{w: w, r: r, doneChan: doneChan}
 		<-doneChan
 	})
 
@@ -183,7 +191,7 @@ func New(ctx context.Context, config *configpb.SurfacerConf, opts *options.Optio
 
 	// Make sure older path /probestatus is redirected to the new path.
 	if !state.IsHandled("/probestatus") {
-		if err := state.AddWebHandler("/probestatus", func(w http.ResponseWriter, r *http.Request) {
+		if err := state.AddWebHandlerWithContext(ctx, "/probestatus", func(w http.ResponseWriter, r *http.Request) {
 			fmt.Fprint(w, redirectHTML)
 		}); err != nil {
 			return nil, fmt.Errorf("error setting up /probestatus redirect: %v", err)
@@ -191,7 +199,7 @@ func New(ctx context.Context, config *configpb.SurfacerConf, opts *options.Optio
 	}
 
 	if !state.IsHandled("/") {
-		err := state.AddWebHandler("/", func(w http.ResponseWriter, r *http.Request) {
+		err := state.AddWebHandlerWithContext(ctx, "/", func(w http.ResponseWriter, r *http.Request) {
 			if r.URL.Path != "/" {
 				http.NotFound(w, r)
 				return
@@ -203,10 +211,17 @@ func New(ctx context.Context, config *configpb.SurfacerConf, opts *options.Optio
 		}
 	}
 
-	if err := state.AddWebHandler(config.GetUrl()+"/static/", http.StripPrefix(config.GetUrl(), http.FileServer(http.FS(content))).ServeHTTP); err != nil {
+	if err := state.AddWebHandlerWithContext(ctx, config.GetUrl()+"/static/", http.StripPrefix(config.GetUrl(), http.FileServer(http.FS(content))).ServeHTTP); err != nil {
 		return nil, fmt.Errorf("error adding static file handler: %v", err)
 	}
 
+	if config.GetGrafanaDatasource() {
+		if err := ps.addGrafanaHandlers(ctx); err != nil {
+			return nil, err
+		}
+		l.Infof("Grafana JSON datasource available at the URL: %s/grafana", config.GetUrl())
+	}
+
 	l.Infof("Initialized status surfacer at the URL: %s", config.GetUrl())
 	return ps, nil
 }
@@ -41,9 +41,18 @@ type SurfacerConf struct {
 	CacheTimeSec *int32 `protobuf:"varint,5,opt,name=cache_time_sec,json=cacheTimeSec,def=2" json:"cache_time_sec,omitempty"`
 	// Probestatus surfacer is enabled by default. To disable it, set this
 	// option.
-	Disable       *bool `protobuf:"varint,6,opt,name=disable" json:"disable,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	Disable *bool `protobuf:"varint,6,opt,name=disable" json:"disable,omitempty"`
+	// Expose the in-memory timeseries through a Grafana JSON datasource
+	// (SimpleJSON) compatible API at <url>/grafana, e.g. /status/grafana.
+	// This allows small deployments to build Grafana dashboards directly on
+	// top of cloudprober, without an external timeseries database.
+	//
+	// Series are named as <probe>/<target>/<metric>, where metric is one of
+	// "availability", "total" and "success". "total" and "success" are
+	// per-bucket deltas.
+	GrafanaDatasource *bool `protobuf:"varint,7,opt,name=grafana_datasource,json=grafanaDatasource" json:"grafana_datasource,omitempty"`
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
 }
 
 // Default values for SurfacerConf fields.
@@ -127,18 +136,26 @@ func (x *SurfacerConf) GetDisable() bool {
 	return false
 }
 
+func (x *SurfacerConf) GetGrafanaDatasource() bool {
+	if x != nil && x.GrafanaDatasource != nil {
+		return *x.GrafanaDatasource
+	}
+	return false
+}
+
 var File_github_com_cloudprober_cloudprober_internal_surfacers_probestatus_proto_config_proto protoreflect.FileDescriptor
 
 const file_github_com_cloudprober_cloudprober_internal_surfacers_probestatus_proto_config_proto_rawDesc = "" +
 	"\n" +
-	"Tgithub.com/cloudprober/cloudprober/internal/surfacers/probestatus/proto/config.proto\x12 cloudprober.surfacer.probestatus\"\xfd\x01\n" +
+	"Tgithub.com/cloudprober/cloudprober/internal/surfacers/probestatus/proto/config.proto\x12 cloudprober.surfacer.probestatus\"\xac\x02\n" +
 	"\fSurfacerConf\x12)\n" +
 	"\x0eresolution_sec\x18\x01 \x01(\x05:\x0260R\rresolutionSec\x12-\n" +
 	"\x0ftimeseries_size\x18\x02 \x01(\x05:\x044320R\x0etimeseriesSize\x125\n" +
 	"\x15max_targets_per_probe\x18\x03 \x01(\x05:\x0220R\x12maxTargetsPerProbe\x12\x19\n" +
 	"\x03url\x18\x04 \x01(\t:\a/statusR\x03url\x12'\n" +
 	"\x0ecache_time_sec\x18\x05 \x01(\x05:\x012R\fcacheTimeSec\x12\x18\n" +
-	"\adisable\x18\x06 \x01(\bR\adisableBIZGgithub.com/cloudprober/cloudprober/internal/surfacers/probestatus/proto"
+	"\adisable\x18\x06 \x01(\bR\adisable\x12-\n" +
+	"\x12grafana_datasource\x18\a \x01(\bR\x11grafanaDatasourceBIZGgithub.com/cloudprober/cloudprober/internal/surfacers/probestatus/proto"
 
 var (
 	file_github_com_cloudprober_cloudprober_internal_surfacers_probestatus_proto_config_proto_rawDescOnce sync.Once
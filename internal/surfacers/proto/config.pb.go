@@ -16,6 +16,7 @@ import (
 	proto7 "github.com/cloudprober/cloudprober/internal/surfacers/probestatus/proto"
 	proto "github.com/cloudprober/cloudprober/internal/surfacers/prometheus/proto"
 	proto4 "github.com/cloudprober/cloudprober/internal/surfacers/pubsub/proto"
+	proto10 "github.com/cloudprober/cloudprober/internal/surfacers/snmp/proto"
 	proto1 "github.com/cloudprober/cloudprober/internal/surfacers/stackdriver/proto"
 	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
 	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
@@ -46,6 +47,7 @@ const (
 	Type_PROBESTATUS Type = 8
 	Type_BIGQUERY    Type = 9 // Experimental mode.
 	Type_OTEL        Type = 10
+	Type_SNMP        Type = 11
 	// One of the extension surfacer types. See "extensions" below for more
 	// details.
 	Type_EXTENSION Type = 98
@@ -67,6 +69,7 @@ var (
 		8:  "PROBESTATUS",
 		9:  "BIGQUERY",
 		10: "OTEL",
+		11: "SNMP",
 		98: "EXTENSION",
 		99: "USER_DEFINED",
 	}
@@ -82,6 +85,7 @@ var (
 		"PROBESTATUS":  8,
 		"BIGQUERY":     9,
 		"OTEL":         10,
+		"SNMP":         11,
 		"EXTENSION":    98,
 		"USER_DEFINED": 99,
 	}
@@ -251,6 +255,8 @@ type SurfacerDef struct {
 	//	*SurfacerDef_ProbestatusSurfacer
 	//	*SurfacerDef_BigquerySurfacer
 	//	*SurfacerDef_OtelSurfacer
+	//	*SurfacerDef_SnmpSurfacer
+	//	*SurfacerDef_UserDefinedConfig
 	Surfacer        isSurfacerDef_Surfacer `protobuf_oneof:"surfacer"`
 	extensionFields protoimpl.ExtensionFields
 	unknownFields   protoimpl.UnknownFields
@@ -469,6 +475,24 @@ func (x *SurfacerDef) GetOtelSurfacer() *proto9.SurfacerConf {
 	return nil
 }
 
+func (x *SurfacerDef) GetSnmpSurfacer() *proto10.SurfacerConf {
+	if x != nil {
+		if x, ok := x.Surfacer.(*SurfacerDef_SnmpSurfacer); ok {
+			return x.SnmpSurfacer
+		}
+	}
+	return nil
+}
+
+func (x *SurfacerDef) GetUserDefinedConfig() string {
+	if x != nil {
+		if x, ok := x.Surfacer.(*SurfacerDef_UserDefinedConfig); ok {
+			return x.UserDefinedConfig
+		}
+	}
+	return ""
+}
+
 type isSurfacerDef_Surfacer interface {
 	isSurfacerDef_Surfacer()
 }
@@ -513,6 +537,18 @@ type SurfacerDef_OtelSurfacer struct {
 	OtelSurfacer *proto9.SurfacerConf `protobuf:"bytes,19,opt,name=otel_surfacer,json=otelSurfacer,oneof"`
 }
 
+type SurfacerDef_SnmpSurfacer struct {
+	SnmpSurfacer *proto10.SurfacerConf `protobuf:"bytes,21,opt,name=snmp_surfacer,json=snmpSurfacer,oneof"`
+}
+
+type SurfacerDef_UserDefinedConfig struct {
+	// Config for the USER_DEFINED surfacers. This config is passed as it is
+	// (as an opaque blob) to the factory registered for this surfacer's name
+	// through surfacers.RegisterFactory(). It's up to the factory to interpret
+	// it, e.g. as JSON or YAML.
+	UserDefinedConfig string `protobuf:"bytes,20,opt,name=user_defined_config,json=userDefinedConfig,oneof"`
+}
+
 func (*SurfacerDef_PrometheusSurfacer) isSurfacerDef_Surfacer() {}
 
 func (*SurfacerDef_StackdriverSurfacer) isSurfacerDef_Surfacer() {}
@@ -533,14 +569,18 @@ func (*SurfacerDef_BigquerySurfacer) isSurfacerDef_Surfacer() {}
 
 func (*SurfacerDef_OtelSurfacer) isSurfacerDef_Surfacer() {}
 
+func (*SurfacerDef_SnmpSurfacer) isSurfacerDef_Surfacer() {}
+
+func (*SurfacerDef_UserDefinedConfig) isSurfacerDef_Surfacer() {}
+
 var File_github_com_cloudprober_cloudprober_internal_surfacers_proto_config_proto protoreflect.FileDescriptor
 
 const file_github_com_cloudprober_cloudprober_internal_surfacers_proto_config_proto_rawDesc = "" +
 	"\n" +
-	"Hgithub.com/cloudprober/cloudprober/internal/surfacers/proto/config.proto\x12\x14cloudprober.surfacer\x1aSgithub.com/cloudprober/cloudprober/internal/surfacers/cloudwatch/proto/config.proto\x1aPgithub.com/cloudprober/cloudprober/internal/surfacers/datadog/proto/config.proto\x1aMgithub.com/cloudprober/cloudprober/internal/surfacers/file/proto/config.proto\x1aMgithub.com/cloudprober/cloudprober/internal/surfacers/otel/proto/config.proto\x1aQgithub.com/cloudprober/cloudprober/internal/surfacers/postgres/proto/config.proto\x1aTgithub.com/cloudprober/cloudprober/internal/surfacers/probestatus/proto/config.proto\x1aSgithub.com/cloudprober/cloudprober/internal/surfacers/prometheus/proto/config.proto\x1aOgithub.com/cloudprober/cloudprober/internal/surfacers/pubsub/proto/config.proto\x1aTgithub.com/cloudprober/cloudprober/internal/surfacers/stackdriver/proto/config.proto\x1aQgithub.com/cloudprober/cloudprober/internal/surfacers/bigquery/proto/config.proto\"5\n" +
+	"Hgithub.com/cloudprober/cloudprober/internal/surfacers/proto/config.proto\x12\x14cloudprober.surfacer\x1aSgithub.com/cloudprober/cloudprober/internal/surfacers/cloudwatch/proto/config.proto\x1aPgithub.com/cloudprober/cloudprober/internal/surfacers/datadog/proto/config.proto\x1aMgithub.com/cloudprober/cloudprober/internal/surfacers/file/proto/config.proto\x1aMgithub.com/cloudprober/cloudprober/internal/surfacers/otel/proto/config.proto\x1aQgithub.com/cloudprober/cloudprober/internal/surfacers/postgres/proto/config.proto\x1aTgithub.com/cloudprober/cloudprober/internal/surfacers/probestatus/proto/config.proto\x1aSgithub.com/cloudprober/cloudprober/internal/surfacers/prometheus/proto/config.proto\x1aOgithub.com/cloudprober/cloudprober/internal/surfacers/pubsub/proto/config.proto\x1aMgithub.com/cloudprober/cloudprober/internal/surfacers/snmp/proto/config.proto\x1aTgithub.com/cloudprober/cloudprober/internal/surfacers/stackdriver/proto/config.proto\x1aQgithub.com/cloudprober/cloudprober/internal/surfacers/bigquery/proto/config.proto\"5\n" +
 	"\vLabelFilter\x12\x10\n" +
 	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
-	"\x05value\x18\x02 \x01(\tR\x05value\"\xdb\f\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value\"\xdd\r\n" +
 	"\vSurfacerDef\x12\x12\n" +
 	"\x04name\x18\x01 \x01(\tR\x04name\x12.\n" +
 	"\x04type\x18\x02 \x01(\x0e2\x1a.cloudprober.surfacer.TypeR\x04type\x125\n" +
@@ -563,9 +603,11 @@ const file_github_com_cloudprober_cloudprober_internal_surfacers_proto_config_pr
 	"\x10datadog_surfacer\x18\x10 \x01(\v2*.cloudprober.surfacer.datadog.SurfacerConfH\x00R\x0fdatadogSurfacer\x12c\n" +
 	"\x14probestatus_surfacer\x18\x11 \x01(\v2..cloudprober.surfacer.probestatus.SurfacerConfH\x00R\x13probestatusSurfacer\x12Z\n" +
 	"\x11bigquery_surfacer\x18\x12 \x01(\v2+.cloudprober.surfacer.bigquery.SurfacerConfH\x00R\x10bigquerySurfacer\x12N\n" +
-	"\rotel_surfacer\x18\x13 \x01(\v2'.cloudprober.surfacer.otel.SurfacerConfH\x00R\fotelSurfacer*\t\b\xc8\x01\x10\x80\x80\x80\x80\x02B\n" +
+	"\rotel_surfacer\x18\x13 \x01(\v2'.cloudprober.surfacer.otel.SurfacerConfH\x00R\fotelSurfacer\x12N\n" +
+	"\rsnmp_surfacer\x18\x15 \x01(\v2'.cloudprober.surfacer.snmp.SurfacerConfH\x00R\fsnmpSurfacer\x120\n" +
+	"\x13user_defined_config\x18\x14 \x01(\tH\x00R\x11userDefinedConfig*\t\b\xc8\x01\x10\x80\x80\x80\x80\x02B\n" +
 	"\n" +
-	"\bsurfacer*\xbc\x01\n" +
+	"\bsurfacer*\xc6\x01\n" +
 	"\x04Type\x12\b\n" +
 	"\x04NONE\x10\x00\x12\x0e\n" +
 	"\n" +
@@ -581,7 +623,8 @@ const file_github_com_cloudprober_cloudprober_internal_surfacers_proto_config_pr
 	"\vPROBESTATUS\x10\b\x12\f\n" +
 	"\bBIGQUERY\x10\t\x12\b\n" +
 	"\x04OTEL\x10\n" +
-	"\x12\r\n" +
+	"\x12\b\n" +
+	"\x04SNMP\x10\v\x12\r\n" +
 	"\tEXTENSION\x10b\x12\x10\n" +
 	"\fUSER_DEFINED\x10cB=Z;github.com/cloudprober/cloudprober/internal/surfacers/proto"
 
@@ -600,19 +643,20 @@ func file_github_com_cloudprober_cloudprober_internal_surfacers_proto_config_pro
 var file_github_com_cloudprober_cloudprober_internal_surfacers_proto_config_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
 var file_github_com_cloudprober_cloudprober_internal_surfacers_proto_config_proto_msgTypes = make([]protoimpl.MessageInfo, 2)
 var file_github_com_cloudprober_cloudprober_internal_surfacers_proto_config_proto_goTypes = []any{
-	(Type)(0),                   // 0: cloudprober.surfacer.Type
-	(*LabelFilter)(nil),         // 1: cloudprober.surfacer.LabelFilter
-	(*SurfacerDef)(nil),         // 2: cloudprober.surfacer.SurfacerDef
-	(*proto.SurfacerConf)(nil),  // 3: cloudprober.surfacer.prometheus.SurfacerConf
-	(*proto1.SurfacerConf)(nil), // 4: cloudprober.surfacer.stackdriver.SurfacerConf
-	(*proto2.SurfacerConf)(nil), // 5: cloudprober.surfacer.file.SurfacerConf
-	(*proto3.SurfacerConf)(nil), // 6: cloudprober.surfacer.postgres.SurfacerConf
-	(*proto4.SurfacerConf)(nil), // 7: cloudprober.surfacer.pubsub.SurfacerConf
-	(*proto5.SurfacerConf)(nil), // 8: cloudprober.surfacer.cloudwatch.SurfacerConf
-	(*proto6.SurfacerConf)(nil), // 9: cloudprober.surfacer.datadog.SurfacerConf
-	(*proto7.SurfacerConf)(nil), // 10: cloudprober.surfacer.probestatus.SurfacerConf
-	(*proto8.SurfacerConf)(nil), // 11: cloudprober.surfacer.bigquery.SurfacerConf
-	(*proto9.SurfacerConf)(nil), // 12: cloudprober.surfacer.otel.SurfacerConf
+	(Type)(0),                    // 0: cloudprober.surfacer.Type
+	(*LabelFilter)(nil),          // 1: cloudprober.surfacer.LabelFilter
+	(*SurfacerDef)(nil),          // 2: cloudprober.surfacer.SurfacerDef
+	(*proto.SurfacerConf)(nil),   // 3: cloudprober.surfacer.prometheus.SurfacerConf
+	(*proto1.SurfacerConf)(nil),  // 4: cloudprober.surfacer.stackdriver.SurfacerConf
+	(*proto2.SurfacerConf)(nil),  // 5: cloudprober.surfacer.file.SurfacerConf
+	(*proto3.SurfacerConf)(nil),  // 6: cloudprober.surfacer.postgres.SurfacerConf
+	(*proto4.SurfacerConf)(nil),  // 7: cloudprober.surfacer.pubsub.SurfacerConf
+	(*proto5.SurfacerConf)(nil),  // 8: cloudprober.surfacer.cloudwatch.SurfacerConf
+	(*proto6.SurfacerConf)(nil),  // 9: cloudprober.surfacer.datadog.SurfacerConf
+	(*proto7.SurfacerConf)(nil),  // 10: cloudprober.surfacer.probestatus.SurfacerConf
+	(*proto8.SurfacerConf)(nil),  // 11: cloudprober.surfacer.bigquery.SurfacerConf
+	(*proto9.SurfacerConf)(nil),  // 12: cloudprober.surfacer.otel.SurfacerConf
+	(*proto10.SurfacerConf)(nil), // 13: cloudprober.surfacer.snmp.SurfacerConf
 }
 var file_github_com_cloudprober_cloudprober_internal_surfacers_proto_config_proto_depIdxs = []int32{
 	0,  // 0: cloudprober.surfacer.SurfacerDef.type:type_name -> cloudprober.surfacer.Type
@@ -628,11 +672,12 @@ var file_github_com_cloudprober_cloudprober_internal_surfacers_proto_config_prot
 	10, // 10: cloudprober.surfacer.SurfacerDef.probestatus_surfacer:type_name -> cloudprober.surfacer.probestatus.SurfacerConf
 	11, // 11: cloudprober.surfacer.SurfacerDef.bigquery_surfacer:type_name -> cloudprober.surfacer.bigquery.SurfacerConf
 	12, // 12: cloudprober.surfacer.SurfacerDef.otel_surfacer:type_name -> cloudprober.surfacer.otel.SurfacerConf
-	13, // [13:13] is the sub-list for method output_type
-	13, // [13:13] is the sub-list for method input_type
-	13, // [13:13] is the sub-list for extension type_name
-	13, // [13:13] is the sub-list for extension extendee
-	0,  // [0:13] is the sub-list for field type_name
+	13, // 13: cloudprober.surfacer.SurfacerDef.snmp_surfacer:type_name -> cloudprober.surfacer.snmp.SurfacerConf
+	14, // [14:14] is the sub-list for method output_type
+	14, // [14:14] is the sub-list for method input_type
+	14, // [14:14] is the sub-list for extension type_name
+	14, // [14:14] is the sub-list for extension extendee
+	0,  // [0:14] is the sub-list for field type_name
 }
 
 func init() { file_github_com_cloudprober_cloudprober_internal_surfacers_proto_config_proto_init() }
@@ -651,6 +696,8 @@ func file_github_com_cloudprober_cloudprober_internal_surfacers_proto_config_pro
 		(*SurfacerDef_ProbestatusSurfacer)(nil),
 		(*SurfacerDef_BigquerySurfacer)(nil),
 		(*SurfacerDef_OtelSurfacer)(nil),
+		(*SurfacerDef_SnmpSurfacer)(nil),
+		(*SurfacerDef_UserDefinedConfig)(nil),
 	}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
@@ -0,0 +1,235 @@
+// Copyright 2025 The Cloudprober Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package snmp
+
+// This file implements the minimal subset of BER (Basic Encoding Rules) that
+// is required to decode SNMP requests and encode SNMP responses.
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// BER and SNMP tags.
+const (
+	tagInteger     = 0x02
+	tagOctetString = 0x04
+	tagNull        = 0x05
+	tagOID         = 0x06
+	tagSequence    = 0x30
+
+	tagCounter32 = 0x41
+	tagGauge32   = 0x42
+	tagTimeTicks = 0x43
+	tagCounter64 = 0x46
+
+	tagNoSuchObject   = 0x80
+	tagNoSuchInstance = 0x81
+	tagEndOfMibView   = 0x82
+
+	tagGetRequest     = 0xa0
+	tagGetNextRequest = 0xa1
+	tagResponse       = 0xa2
+	tagGetBulkRequest = 0xa5
+)
+
+var errTruncated = errors.New("truncated BER data")
+
+// tlv is a decoded BER type-length-value element.
+type tlv struct {
+	tag   byte
+	value []byte
+}
+
+// readTLV reads a TLV from b and returns it along with the rest of the data.
+func readTLV(b []byte) (tlv, []byte, error) {
+	if len(b) < 2 {
+		return tlv{}, nil, errTruncated
+	}
+	tag, l, b := b[0], int(b[1]), b[2:]
+	if l&0x80 != 0 {
+		n := l & 0x7f
+		if n == 0 || n > 4 || len(b) < n {
+			return tlv{}, nil, fmt.Errorf("invalid BER length encoding")
+		}
+		l = 0
+		for _, c := range b[:n] {
+			l = l<<8 | int(c)
+		}
+		b = b[n:]
+	}
+	if l < 0 || len(b) < l {
+		return tlv{}, nil, errTruncated
+	}
+	return tlv{tag: tag, value: b[:l]}, b[l:], nil
+}
+
+// readExpected reads a TLV and verifies its tag.
+func readExpected(b []byte, tag byte) ([]byte, []byte, error) {
+	t, rest, err := readTLV(b)
+	if err != nil {
+		return nil, nil, err
+	}
+	if t.tag != tag {
+		return nil, nil, fmt.Errorf("unexpected BER tag: 0x%x, expected: 0x%x", t.tag, tag)
+	}
+	return t.value, rest, nil
+}
+
+func readInt(b []byte) (int64, []byte, error) {
+	v, rest, err := readExpected(b, tagInteger)
+	if err != nil {
+		return 0, nil, err
+	}
+	if len(v) == 0 || len(v) > 8 {
+		return 0, nil, fmt.Errorf("invalid integer length: %d", len(v))
+	}
+	i := int64(int8(v[0])) // Sign extension.
+	for _, c := range v[1:] {
+		i = i<<8 | int64(c)
+	}
+	return i, rest, nil
+}
+
+// oid is an SNMP object identifier.
+type oid []uint32
+
+func parseOID(s string) (oid, error) {
+	var o oid
+	for _, part := range strings.Split(strings.Trim(s, "."), ".") {
+		n, err := strconv.ParseUint(part, 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid OID (%s): %v", s, err)
+		}
+		o = append(o, uint32(n))
+	}
+	if len(o) < 2 {
+		return nil, fmt.Errorf("invalid OID (%s): too short", s)
+	}
+	return o, nil
+}
+
+func (o oid) String() string {
+	parts := make([]string, len(o))
+	for i, n := range o {
+		parts[i] = strconv.FormatUint(uint64(n), 10)
+	}
+	return strings.Join(parts, ".")
+}
+
+// compare compares OIDs lexicographically.
+func (o oid) compare(other oid) int {
+	for i := 0; i < len(o) && i < len(other); i++ {
+		if o[i] != other[i] {
+			if o[i] < other[i] {
+				return -1
+			}
+			return 1
+		}
+	}
+	return len(o) - len(other)
+}
+
+func (o oid) append(n ...uint32) oid {
+	return append(append(oid{}, o...), n...)
+}
+
+func decodeOID(v []byte) (oid, error) {
+	if len(v) == 0 {
+		return nil, errors.New("empty OID")
+	}
+	o := oid{uint32(v[0]) / 40, uint32(v[0]) % 40}
+	var n uint32
+	for _, c := range v[1:] {
+		n = n<<7 | uint32(c&0x7f)
+		if c&0x80 == 0 {
+			o = append(o, n)
+			n = 0
+		}
+	}
+	return o, nil
+}
+
+func encodeLength(l int) []byte {
+	if l < 0x80 {
+		return []byte{byte(l)}
+	}
+	var b []byte
+	for ; l > 0; l >>= 8 {
+		b = append([]byte{byte(l)}, b...)
+	}
+	return append([]byte{0x80 | byte(len(b))}, b...)
+}
+
+func encodeTLV(tag byte, value []byte) []byte {
+	return append(append([]byte{tag}, encodeLength(len(value))...), value...)
+}
+
+func encodeInt(tag byte, i int64) []byte {
+	var b []byte
+	for {
+		b = append([]byte{byte(i)}, b...)
+		i >>= 8
+		// Stop when the remaining bits are just sign extension.
+		if (i == 0 && b[0]&0x80 == 0) || (i == -1 && b[0]&0x80 != 0) {
+			break
+		}
+	}
+	return encodeTLV(tag, b)
+}
+
+// encodeUint encodes unsigned application types, e.g. Counter64 and Gauge32.
+func encodeUint(tag byte, u uint64) []byte {
+	var b []byte
+	for {
+		b = append([]byte{byte(u)}, b...)
+		u >>= 8
+		if u == 0 {
+			break
+		}
+	}
+	// Add a leading zero byte, if required, to keep the value positive.
+	if b[0]&0x80 != 0 {
+		b = append([]byte{0}, b...)
+	}
+	return encodeTLV(tag, b)
+}
+
+func encodeBase128(b []byte, n uint32) []byte {
+	var tmp []byte
+	tmp = append(tmp, byte(n&0x7f))
+	for n >>= 7; n > 0; n >>= 7 {
+		tmp = append([]byte{byte(n&0x7f) | 0x80}, tmp...)
+	}
+	return append(b, tmp...)
+}
+
+func encodeOID(o oid) []byte {
+	b := []byte{byte(o[0]*40 + o[1])}
+	for _, n := range o[2:] {
+		b = encodeBase128(b, n)
+	}
+	return encodeTLV(tagOID, b)
+}
+
+func encodeSequence(tag byte, elems ...[]byte) []byte {
+	var b []byte
+	for _, e := range elems {
+		b = append(b, e...)
+	}
+	return encodeTLV(tag, b)
+}
@@ -0,0 +1,84 @@
+// Copyright 2025 The Cloudprober Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package snmp
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIntEncoding(t *testing.T) {
+	tests := []struct {
+		i    int64
+		want []byte
+	}{
+		{i: 0, want: []byte{0x02, 0x01, 0x00}},
+		{i: 127, want: []byte{0x02, 0x01, 0x7f}},
+		{i: 128, want: []byte{0x02, 0x02, 0x00, 0x80}},
+		{i: 256, want: []byte{0x02, 0x02, 0x01, 0x00}},
+		{i: -1, want: []byte{0x02, 0x01, 0xff}},
+		{i: -129, want: []byte{0x02, 0x02, 0xff, 0x7f}},
+	}
+
+	for _, tt := range tests {
+		b := encodeInt(tagInteger, tt.i)
+		assert.Equal(t, tt.want, b, "encodeInt(%d)", tt.i)
+
+		got, rest, err := readInt(b)
+		assert.NoError(t, err)
+		assert.Empty(t, rest)
+		assert.Equal(t, tt.i, got, "readInt(%v)", b)
+	}
+
+	assert.Equal(t, []byte{0x46, 0x02, 0x00, 0xff}, encodeUint(tagCounter64, 255))
+}
+
+func TestOIDEncoding(t *testing.T) {
+	o, err := parseOID("1.3.6.1.4.1.32473.1")
+	assert.NoError(t, err)
+	assert.Equal(t, "1.3.6.1.4.1.32473.1", o.String())
+
+	b := encodeOID(o)
+	assert.Equal(t, []byte{0x06, 0x09, 0x2b, 0x06, 0x01, 0x04, 0x01, 0x81, 0xfd, 0x59, 0x01}, b)
+
+	v, _, err := readExpected(b, tagOID)
+	assert.NoError(t, err)
+	got, err := decodeOID(v)
+	assert.NoError(t, err)
+	assert.Equal(t, o, got)
+
+	_, err = parseOID("1.3.x")
+	assert.Error(t, err)
+
+	assert.Negative(t, oid{1, 3, 6}.compare(oid{1, 3, 6, 1}))
+	assert.Negative(t, oid{1, 3, 6, 1}.compare(oid{1, 3, 7}))
+	assert.Positive(t, oid{1, 3, 10}.compare(oid{1, 3, 9, 1}))
+	assert.Zero(t, oid{1, 3}.compare(oid{1, 3}))
+}
+
+func TestLongLength(t *testing.T) {
+	value := make([]byte, 300)
+	b := encodeTLV(tagOctetString, value)
+	assert.Equal(t, []byte{0x04, 0x82, 0x01, 0x2c}, b[:4])
+
+	got, rest, err := readExpected(b, tagOctetString)
+	assert.NoError(t, err)
+	assert.Empty(t, rest)
+	assert.Len(t, got, 300)
+
+	_, _, err = readTLV(b[:100])
+	assert.Error(t, err)
+}
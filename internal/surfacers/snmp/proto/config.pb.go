@@ -0,0 +1,199 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.11
+// 	protoc        v5.27.5
+// source: github.com/cloudprober/cloudprober/internal/surfacers/snmp/proto/config.proto
+
+package proto
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// SNMP surfacer runs an SNMP (v2c) agent that exposes probes' status, so that
+// legacy network management systems can poll cloudprober directly. Only the
+// read operations (Get, GetNext and GetBulk) are supported.
+//
+// Status is exposed as a table under the configured enterprise OID:
+//
+//	<oid>.1.1.<column>.<index>
+//
+// where index is assigned to a probe/target pair the first time cloudprober
+// sees it, and stays the same until cloudprober restarts or the row expires
+// (see stale_row_timeout_sec). Indices of the expired rows are not reused.
+// Columns:
+//
+//	1: probe name (OCTET STRING)
+//	2: target name (OCTET STRING)
+//	3: total (Counter64)
+//	4: success (Counter64)
+//	5: status (INTEGER): 1 - up, 2 - down, i.e. the last probe run failed.
+//	6: latency of the last successful run, in microseconds (Gauge32)
+//
+// In addition to the table, <oid>.2.0 (Gauge32) contains the number of rows.
+type SurfacerConf struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Address to listen on for the SNMP requests.
+	Address *string `protobuf:"bytes,1,opt,name=address,def=:1161" json:"address,omitempty"`
+	// SNMP community. Requests for any other community are ignored.
+	Community *string `protobuf:"bytes,2,opt,name=community,def=public" json:"community,omitempty"`
+	// Enterprise OID subtree to expose the status under. Default OID is under
+	// the private enterprise number reserved for documentation (RFC 5612). You
+	// should set it to your organization's subtree.
+	EnterpriseOid *string `protobuf:"bytes,3,opt,name=enterprise_oid,json=enterpriseOid,def=1.3.6.1.4.1.32473.1" json:"enterprise_oid,omitempty"`
+	// Name of the metric to read the latency from. Probes can export latency
+	// under a different name, through the probe's latency_metric_name.
+	LatencyMetricName *string `protobuf:"bytes,4,opt,name=latency_metric_name,json=latencyMetricName,def=latency" json:"latency_metric_name,omitempty"`
+	// Rows that haven't been updated for this long are removed, e.g. for the
+	// targets that went away. Set to 0 to never remove rows.
+	StaleRowTimeoutSec *int32 `protobuf:"varint,5,opt,name=stale_row_timeout_sec,json=staleRowTimeoutSec,def=3600" json:"stale_row_timeout_sec,omitempty"`
+	unknownFields      protoimpl.UnknownFields
+	sizeCache          protoimpl.SizeCache
+}
+
+// Default values for SurfacerConf fields.
+const (
+	Default_SurfacerConf_Address            = string(":1161")
+	Default_SurfacerConf_Community          = string("public")
+	Default_SurfacerConf_EnterpriseOid      = string("1.3.6.1.4.1.32473.1")
+	Default_SurfacerConf_LatencyMetricName  = string("latency")
+	Default_SurfacerConf_StaleRowTimeoutSec = int32(3600)
+)
+
+func (x *SurfacerConf) Reset() {
+	*x = SurfacerConf{}
+	mi := &file_github_com_cloudprober_cloudprober_internal_surfacers_snmp_proto_config_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SurfacerConf) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SurfacerConf) ProtoMessage() {}
+
+func (x *SurfacerConf) ProtoReflect() protoreflect.Message {
+	mi := &file_github_com_cloudprober_cloudprober_internal_surfacers_snmp_proto_config_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SurfacerConf.ProtoReflect.Descriptor instead.
+func (*SurfacerConf) Descriptor() ([]byte, []int) {
+	return file_github_com_cloudprober_cloudprober_internal_surfacers_snmp_proto_config_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *SurfacerConf) GetAddress() string {
+	if x != nil && x.Address != nil {
+		return *x.Address
+	}
+	return Default_SurfacerConf_Address
+}
+
+func (x *SurfacerConf) GetCommunity() string {
+	if x != nil && x.Community != nil {
+		return *x.Community
+	}
+	return Default_SurfacerConf_Community
+}
+
+func (x *SurfacerConf) GetEnterpriseOid() string {
+	if x != nil && x.EnterpriseOid != nil {
+		return *x.EnterpriseOid
+	}
+	return Default_SurfacerConf_EnterpriseOid
+}
+
+func (x *SurfacerConf) GetLatencyMetricName() string {
+	if x != nil && x.LatencyMetricName != nil {
+		return *x.LatencyMetricName
+	}
+	return Default_SurfacerConf_LatencyMetricName
+}
+
+func (x *SurfacerConf) GetStaleRowTimeoutSec() int32 {
+	if x != nil && x.StaleRowTimeoutSec != nil {
+		return *x.StaleRowTimeoutSec
+	}
+	return Default_SurfacerConf_StaleRowTimeoutSec
+}
+
+var File_github_com_cloudprober_cloudprober_internal_surfacers_snmp_proto_config_proto protoreflect.FileDescriptor
+
+const file_github_com_cloudprober_cloudprober_internal_surfacers_snmp_proto_config_proto_rawDesc = "" +
+	"\n" +
+	"Mgithub.com/cloudprober/cloudprober/internal/surfacers/snmp/proto/config.proto\x12\x19cloudprober.surfacer.snmp\"\x83\x02\n" +
+	"\fSurfacerConf\x12\x1f\n" +
+	"\aaddress\x18\x01 \x01(\t:\x05:1161R\aaddress\x12$\n" +
+	"\tcommunity\x18\x02 \x01(\t:\x06publicR\tcommunity\x12:\n" +
+	"\x0eenterprise_oid\x18\x03 \x01(\t:\x131.3.6.1.4.1.32473.1R\renterpriseOid\x127\n" +
+	"\x13latency_metric_name\x18\x04 \x01(\t:\alatencyR\x11latencyMetricName\x127\n" +
+	"\x15stale_row_timeout_sec\x18\x05 \x01(\x05:\x043600R\x12staleRowTimeoutSecBBZ@github.com/cloudprober/cloudprober/internal/surfacers/snmp/proto"
+
+var (
+	file_github_com_cloudprober_cloudprober_internal_surfacers_snmp_proto_config_proto_rawDescOnce sync.Once
+	file_github_com_cloudprober_cloudprober_internal_surfacers_snmp_proto_config_proto_rawDescData []byte
+)
+
+func file_github_com_cloudprober_cloudprober_internal_surfacers_snmp_proto_config_proto_rawDescGZIP() []byte {
+	file_github_com_cloudprober_cloudprober_internal_surfacers_snmp_proto_config_proto_rawDescOnce.Do(func() {
+		file_github_com_cloudprober_cloudprober_internal_surfacers_snmp_proto_config_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_github_com_cloudprober_cloudprober_internal_surfacers_snmp_proto_config_proto_rawDesc), len(file_github_com_cloudprober_cloudprober_internal_surfacers_snmp_proto_config_proto_rawDesc)))
+	})
+	return file_github_com_cloudprober_cloudprober_internal_surfacers_snmp_proto_config_proto_rawDescData
+}
+
+var file_github_com_cloudprober_cloudprober_internal_surfacers_snmp_proto_config_proto_msgTypes = make([]protoimpl.MessageInfo, 1)
+var file_github_com_cloudprober_cloudprober_internal_surfacers_snmp_proto_config_proto_goTypes = []any{
+	(*SurfacerConf)(nil), // 0: cloudprober.surfacer.snmp.SurfacerConf
+}
+var file_github_com_cloudprober_cloudprober_internal_surfacers_snmp_proto_config_proto_depIdxs = []int32{
+	0, // [0:0] is the sub-list for method output_type
+	0, // [0:0] is the sub-list for method input_type
+	0, // [0:0] is the sub-list for extension type_name
+	0, // [0:0] is the sub-list for extension extendee
+	0, // [0:0] is the sub-list for field type_name
+}
+
+func init() {
+	file_github_com_cloudprober_cloudprober_internal_surfacers_snmp_proto_config_proto_init()
+}
+func file_github_com_cloudprober_cloudprober_internal_surfacers_snmp_proto_config_proto_init() {
+	if File_github_com_cloudprober_cloudprober_internal_surfacers_snmp_proto_config_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_github_com_cloudprober_cloudprober_internal_surfacers_snmp_proto_config_proto_rawDesc), len(file_github_com_cloudprober_cloudprober_internal_surfacers_snmp_proto_config_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   1,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_github_com_cloudprober_cloudprober_internal_surfacers_snmp_proto_config_proto_goTypes,
+		DependencyIndexes: file_github_com_cloudprober_cloudprober_internal_surfacers_snmp_proto_config_proto_depIdxs,
+		MessageInfos:      file_github_com_cloudprober_cloudprober_internal_surfacers_snmp_proto_config_proto_msgTypes,
+	}.Build()
+	File_github_com_cloudprober_cloudprober_internal_surfacers_snmp_proto_config_proto = out.File
+	file_github_com_cloudprober_cloudprober_internal_surfacers_snmp_proto_config_proto_goTypes = nil
+	file_github_com_cloudprober_cloudprober_internal_surfacers_snmp_proto_config_proto_depIdxs = nil
+}
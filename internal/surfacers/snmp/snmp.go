@@ -0,0 +1,393 @@
+// Copyright 2025 The Cloudprober Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+/*
+Package snmp implements a surfacer that runs an SNMP v2c agent, exposing
+probes' status to the network management systems. See the config proto for
+the details of the exposed objects.
+*/
+package snmp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"sync"
+	"time"
+
+	configpb "github.com/cloudprober/cloudprober/internal/surfacers/snmp/proto"
+	"github.com/cloudprober/cloudprober/logger"
+	"github.com/cloudprober/cloudprober/metrics"
+	"github.com/cloudprober/cloudprober/surfacers/options"
+)
+
+const snmpVersion2c = 1
+
+// Max repetitions that we honor for a GetBulk request. This keeps the
+// response size in check.
+const maxBulkRepetitions = 100
+
+// Table columns, see the config proto for the description.
+const (
+	colProbe = iota + 1
+	colTarget
+	colTotal
+	colSuccess
+	colStatus
+	colLatency
+	numCols = colLatency
+)
+
+const (
+	statusUp   = 1
+	statusDown = 2
+)
+
+type row struct {
+	index          uint32
+	probe, target  string
+	total, success int64
+	status         int64
+	latencyUsec    uint32
+	latencySum     float64
+	lastUpdated    time.Time
+}
+
+// varbind is an encoded variable binding.
+type varbind struct {
+	oid   oid
+	value []byte
+}
+
+// Surfacer implements an SNMP agent surfacer.
+type Surfacer struct {
+	c       *configpb.SurfacerConf
+	opts    *options.Options
+	l       *logger.Logger
+	baseOID oid
+	conn    net.PacketConn
+
+	mu        sync.RWMutex
+	rows      []*row
+	keys      map[[2]string]*row
+	lastIndex uint32
+}
+
+// New creates a new SNMP surfacer and starts the SNMP agent.
+func New(ctx context.Context, config *configpb.SurfacerConf, opts *options.Options, l *logger.Logger) (*Surfacer, error) {
+	baseOID, err := parseOID(config.GetEnterpriseOid())
+	if err != nil {
+		return nil, fmt.Errorf("invalid enterprise_oid: %v", err)
+	}
+
+	conn, err := net.ListenPacket("udp", config.GetAddress())
+	if err != nil {
+		return nil, fmt.Errorf("error listening on %s: %v", config.GetAddress(), err)
+	}
+
+	s := &Surfacer{
+		c:       config,
+		opts:    opts,
+		l:       l,
+		baseOID: baseOID,
+		conn:    conn,
+		keys:    make(map[[2]string]*row),
+	}
+
+	go func() {
+		<-ctx.Done()
+		s.l.Infof("Context canceled, stopping the SNMP agent.")
+		conn.Close()
+	}()
+	go s.serve()
+
+	if timeout := time.Duration(config.GetStaleRowTimeoutSec()) * time.Second; timeout > 0 {
+		go func() {
+			ticker := time.NewTicker(min(timeout, time.Minute))
+			defer ticker.Stop()
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case now := <-ticker.C:
+					s.expireRows(now.Add(-timeout))
+				}
+			}
+		}()
+	}
+
+	s.l.Infof("Initialized SNMP surfacer at %s, enterprise OID: %s", conn.LocalAddr().String(), baseOID.String())
+	return s, nil
+}
+
+// Write updates the status table from the incoming EventMetrics.
+func (s *Surfacer) Write(_ context.Context, em *metrics.EventMetrics) {
+	probe, target := em.Label("probe"), em.Label("dst")
+	if probe == "" || probe == "sysvars" || em.Kind != metrics.CUMULATIVE {
+		return
+	}
+
+	totalV, ok1 := em.Metric("total").(metrics.NumValue)
+	successV, ok2 := em.Metric("success").(metrics.NumValue)
+	if !ok1 || !ok2 {
+		return
+	}
+	total, success := totalV.Int64(), successV.Int64()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := [2]string{probe, target}
+	r := s.keys[key]
+	if r == nil {
+		s.lastIndex++
+		r = &row{index: s.lastIndex, probe: probe, target: target}
+		s.rows = append(s.rows, r)
+		s.keys[key] = r
+	}
+	r.lastUpdated = time.Now()
+
+	latencySum, hasLatency := latencySum(em.Metric(s.c.GetLatencyMetricName()))
+
+	if total > r.total {
+		if success > r.success {
+			r.status = statusUp
+			if hasLatency {
+				r.latencyUsec = latencyUsec((latencySum-r.latencySum)/float64(success-r.success), em.LatencyUnit)
+			}
+		} else {
+			r.status = statusDown
+		}
+	}
+	r.total, r.success, r.latencySum = total, success, latencySum
+}
+
+// expireRows removes the rows that were last updated before the given time.
+func (s *Surfacer) expireRows(before time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rows := s.rows[:0]
+	for _, r := range s.rows {
+		if r.lastUpdated.Before(before) {
+			s.l.Infof("Removing stale row for probe %s, target %s (index %d)", r.probe, r.target, r.index)
+			delete(s.keys, [2]string{r.probe, r.target})
+			continue
+		}
+		rows = append(rows, r)
+	}
+	clear(s.rows[len(rows):])
+	s.rows = rows
+}
+
+func latencySum(v metrics.Value) (float64, bool) {
+	switch lv := v.(type) {
+	case *metrics.Float:
+		return lv.Float64(), true
+	case *metrics.Distribution:
+		return lv.Data().Sum, true
+	}
+	return 0, false
+}
+
+// latencyUsec converts latency in the given unit to microseconds.
+func latencyUsec(v float64, unit time.Duration) uint32 {
+	if unit == 0 {
+		unit = time.Microsecond
+	}
+	us := v * float64(unit) / float64(time.Microsecond)
+	if us < 0 || math.IsNaN(us) {
+		return 0
+	}
+	return uint32(math.Min(us, math.MaxUint32))
+}
+
+// varbinds returns all the variables that we expose, in the OID order.
+func (s *Surfacer) varbinds() []varbind {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	entryOID := s.baseOID.append(1, 1)
+	vbs := make([]varbind, 0, numCols*len(s.rows)+1)
+	for col := uint32(1); col <= numCols; col++ {
+		for _, r := range s.rows {
+			var v []byte
+			switch col {
+			case colProbe:
+				v = encodeTLV(tagOctetString, []byte(r.probe))
+			case colTarget:
+				v = encodeTLV(tagOctetString, []byte(r.target))
+			case colTotal:
+				v = encodeUint(tagCounter64, uint64(r.total))
+			case colSuccess:
+				v = encodeUint(tagCounter64, uint64(r.success))
+			case colStatus:
+				v = encodeInt(tagInteger, r.status)
+			case colLatency:
+				v = encodeUint(tagGauge32, uint64(r.latencyUsec))
+			}
+			vbs = append(vbs, varbind{oid: entryOID.append(col, r.index), value: v})
+		}
+	}
+	vbs = append(vbs, varbind{oid: s.baseOID.append(2, 0), value: encodeUint(tagGauge32, uint64(len(s.rows)))})
+	return vbs
+}
+
+func (s *Surfacer) get(vbs []varbind, o oid) varbind {
+	for _, vb := range vbs {
+		if vb.oid.compare(o) == 0 {
+			return vb
+		}
+	}
+	if len(o) > len(s.baseOID) && o[:len(s.baseOID)].compare(s.baseOID) == 0 {
+		return varbind{oid: o, value: encodeTLV(tagNoSuchInstance, nil)}
+	}
+	return varbind{oid: o, value: encodeTLV(tagNoSuchObject, nil)}
+}
+
+func getNext(vbs []varbind, o oid) varbind {
+	for _, vb := range vbs {
+		if vb.oid.compare(o) > 0 {
+			return vb
+		}
+	}
+	return varbind{oid: o, value: encodeTLV(tagEndOfMibView, nil)}
+}
+
+// handleRequest processes an SNMP request and returns the response.
+func (s *Surfacer) handleRequest(req []byte) ([]byte, error) {
+	msg, _, err := readExpected(req, tagSequence)
+	if err != nil {
+		return nil, err
+	}
+	version, msg, err := readInt(msg)
+	if err != nil {
+		return nil, err
+	}
+	if version != snmpVersion2c {
+		return nil, fmt.Errorf("unsupported SNMP version: %d", version)
+	}
+	community, msg, err := readExpected(msg, tagOctetString)
+	if err != nil {
+		return nil, err
+	}
+	if string(community) != s.c.GetCommunity() {
+		return nil, fmt.Errorf("unknown community: %s", string(community))
+	}
+
+	pdu, _, err := readTLV(msg)
+	if err != nil {
+		return nil, err
+	}
+	if pdu.tag != tagGetRequest && pdu.tag != tagGetNextRequest && pdu.tag != tagGetBulkRequest {
+		return nil, fmt.Errorf("unsupported PDU type: 0x%x", pdu.tag)
+	}
+
+	b := pdu.value
+	var reqID, field1, field2 int64
+	for _, f := range []*int64{&reqID, &field1, &field2} {
+		if *f, b, err = readInt(b); err != nil {
+			return nil, err
+		}
+	}
+
+	vbList, _, err := readExpected(b, tagSequence)
+	if err != nil {
+		return nil, err
+	}
+	var oids []oid
+	for len(vbList) > 0 {
+		var vb, oidBytes []byte
+		if vb, vbList, err = readExpected(vbList, tagSequence); err != nil {
+			return nil, err
+		}
+		if oidBytes, _, err = readExpected(vb, tagOID); err != nil {
+			return nil, err
+		}
+		o, err := decodeOID(oidBytes)
+		if err != nil {
+			return nil, err
+		}
+		oids = append(oids, o)
+	}
+
+	vbs := s.varbinds()
+	var result []varbind
+
+	switch pdu.tag {
+	case tagGetRequest:
+		for _, o := range oids {
+			result = append(result, s.get(vbs, o))
+		}
+	case tagGetNextRequest:
+		for _, o := range oids {
+			result = append(result, getNext(vbs, o))
+		}
+	case tagGetBulkRequest:
+		nonRepeaters, maxRepetitions := int(max(field1, 0)), int(min(max(field2, 0), maxBulkRepetitions))
+		nonRepeaters = min(nonRepeaters, len(oids))
+		for _, o := range oids[:nonRepeaters] {
+			result = append(result, getNext(vbs, o))
+		}
+		repeaters := append([]oid{}, oids[nonRepeaters:]...)
+		for i := 0; i < maxRepetitions && len(repeaters) > 0; i++ {
+			for j, o := range repeaters {
+				vb := getNext(vbs, o)
+				result = append(result, vb)
+				repeaters[j] = vb.oid
+			}
+		}
+	}
+
+	encodedVBs := make([][]byte, len(result))
+	for i, vb := range result {
+		encodedVBs[i] = encodeSequence(tagSequence, encodeOID(vb.oid), vb.value)
+	}
+
+	return encodeSequence(tagSequence,
+		encodeInt(tagInteger, version),
+		encodeTLV(tagOctetString, community),
+		encodeSequence(tagResponse,
+			encodeInt(tagInteger, reqID),
+			encodeInt(tagInteger, 0), // error-status
+			encodeInt(tagInteger, 0), // error-index
+			encodeSequence(tagSequence, encodedVBs...),
+		),
+	), nil
+}
+
+func (s *Surfacer) serve() {
+	buf := make([]byte, 65535)
+	for {
+		n, addr, err := s.conn.ReadFrom(buf)
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return
+			}
+			s.l.Warningf("Error reading SNMP request: %v", err)
+			continue
+		}
+
+		resp, err := s.handleRequest(buf[:n])
+		if err != nil {
+			s.l.Debugf("Ignoring SNMP request from %s: %v", addr.String(), err)
+			continue
+		}
+		if _, err := s.conn.WriteTo(resp, addr); err != nil {
+			s.l.Warningf("Error sending SNMP response to %s: %v", addr.String(), err)
+		}
+	}
+}
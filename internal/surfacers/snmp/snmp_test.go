@@ -0,0 +1,232 @@
+// Copyright 2025 The Cloudprober Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package snmp
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	configpb "github.com/cloudprober/cloudprober/internal/surfacers/snmp/proto"
+	"github.com/cloudprober/cloudprober/metrics"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/proto"
+)
+
+func testEM(probe, target string, total, success int64, latency float64) *metrics.EventMetrics {
+	em := metrics.NewEventMetrics(time.Now()).
+		AddLabel("probe", probe).
+		AddLabel("dst", target).
+		AddMetric("total", metrics.NewInt(total)).
+		AddMetric("success", metrics.NewInt(success)).
+		AddMetric("latency", metrics.NewFloat(latency))
+	em.LatencyUnit = time.Millisecond
+	return em
+}
+
+func testRequest(pduTag byte, community string, field1, field2 int64, oids ...oid) []byte {
+	var vbs [][]byte
+	for _, o := range oids {
+		vbs = append(vbs, encodeSequence(tagSequence, encodeOID(o), encodeTLV(tagNull, nil)))
+	}
+	return encodeSequence(tagSequence,
+		encodeInt(tagInteger, snmpVersion2c),
+		encodeTLV(tagOctetString, []byte(community)),
+		encodeSequence(pduTag,
+			encodeInt(tagInteger, 1234),
+			encodeInt(tagInteger, field1),
+			encodeInt(tagInteger, field2),
+			encodeSequence(tagSequence, vbs...),
+		),
+	)
+}
+
+// parseResponse parses the response and returns the variable bindings as a
+// list of OID and TLV pairs.
+func parseResponse(t *testing.T, b []byte) ([]oid, []tlv) {
+	t.Helper()
+
+	msg, _, err := readExpected(b, tagSequence)
+	assert.NoError(t, err)
+	_, msg, _ = readInt(msg)
+	_, msg, _ = readExpected(msg, tagOctetString)
+	pdu, _, err := readExpected(msg, tagResponse)
+	assert.NoError(t, err)
+
+	reqID, pdu, _ := readInt(pdu)
+	assert.Equal(t, int64(1234), reqID)
+	_, pdu, _ = readInt(pdu)
+	_, pdu, _ = readInt(pdu)
+
+	vbList, _, err := readExpected(pdu, tagSequence)
+	assert.NoError(t, err)
+
+	var oids []oid
+	var values []tlv
+	for len(vbList) > 0 {
+		var vb, oidBytes []byte
+		vb, vbList, _ = readExpected(vbList, tagSequence)
+		oidBytes, vb, _ = readExpected(vb, tagOID)
+		o, _ := decodeOID(oidBytes)
+		v, _, err := readTLV(vb)
+		assert.NoError(t, err)
+		oids = append(oids, o)
+		values = append(values, v)
+	}
+	return oids, values
+}
+
+func testSurfacer(t *testing.T, ctx context.Context) *Surfacer {
+	t.Helper()
+
+	s, err := New(ctx, &configpb.SurfacerConf{
+		Address: proto.String("127.0.0.1:0"),
+	}, nil, nil)
+	if err != nil {
+		t.Fatalf("Error creating surfacer: %v", err)
+	}
+
+	s.Write(ctx, testEM("p1", "t1", 10, 10, 100))
+	s.Write(ctx, testEM("p1", "t2", 10, 10, 100))
+	s.Write(ctx, testEM("p1", "t1", 20, 20, 150)) // 10 more successes, 5ms each.
+	s.Write(ctx, testEM("p1", "t2", 20, 10, 100)) // All failed.
+	s.Write(ctx, testEM("sysvars", "", 1, 1, 0))  // Ignored.
+	return s
+}
+
+func TestHandleRequest(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	s := testSurfacer(t, ctx)
+
+	entry := s.baseOID.append(1, 1)
+
+	// Get.
+	resp, err := s.handleRequest(testRequest(tagGetRequest, "public", 0, 0,
+		entry.append(colProbe, 1), entry.append(colTarget, 2), entry.append(colTotal, 1),
+		entry.append(colStatus, 1), entry.append(colStatus, 2), entry.append(colLatency, 1),
+		entry.append(colLatency, 3), s.baseOID.append(2, 0), oid{1, 3, 6, 1, 2}))
+	assert.NoError(t, err)
+
+	oids, values := parseResponse(t, resp)
+	assert.Len(t, oids, 9)
+	assert.Equal(t, tlv{tagOctetString, []byte("p1")}, values[0])
+	assert.Equal(t, tlv{tagOctetString, []byte("t2")}, values[1])
+	assert.Equal(t, tlv{tagCounter64, []byte{20}}, values[2])
+	assert.Equal(t, tlv{tagInteger, []byte{statusUp}}, values[3])
+	assert.Equal(t, tlv{tagInteger, []byte{statusDown}}, values[4])
+	assert.Equal(t, tlv{tagGauge32, []byte{0x13, 0x88}}, values[5]) // 5000us
+	assert.Equal(t, byte(tagNoSuchInstance), values[6].tag)
+	assert.Equal(t, tlv{tagGauge32, []byte{2}}, values[7])
+	assert.Equal(t, byte(tagNoSuchObject), values[8].tag)
+
+	// GetNext, walking from the base OID.
+	resp, err = s.handleRequest(testRequest(tagGetNextRequest, "public", 0, 0, s.baseOID, s.baseOID.append(2, 0)))
+	assert.NoError(t, err)
+	oids, values = parseResponse(t, resp)
+	assert.Equal(t, []oid{entry.append(colProbe, 1), s.baseOID.append(2, 0)}, oids)
+	assert.Equal(t, byte(tagEndOfMibView), values[1].tag)
+
+	// GetBulk, with 1 non-repeater.
+	resp, err = s.handleRequest(testRequest(tagGetBulkRequest, "public", 1, 3, s.baseOID.append(1, 1, colStatus), entry.append(colTarget)))
+	assert.NoError(t, err)
+	oids, _ = parseResponse(t, resp)
+	assert.Equal(t, []oid{
+		entry.append(colStatus, 1),
+		entry.append(colTarget, 1),
+		entry.append(colTarget, 2),
+		entry.append(colTotal, 1),
+	}, oids)
+
+	// Wrong community.
+	_, err = s.handleRequest(testRequest(tagGetRequest, "private", 0, 0, s.baseOID))
+	assert.Error(t, err)
+
+	// Garbage.
+	_, err = s.handleRequest([]byte{0x30, 0x10, 0x02})
+	assert.Error(t, err)
+}
+
+func TestLatencyMetricName(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	s, err := New(ctx, &configpb.SurfacerConf{
+		Address:           proto.String("127.0.0.1:0"),
+		LatencyMetricName: proto.String("latency_ms"),
+	}, nil, nil)
+	if err != nil {
+		t.Fatalf("Error creating surfacer: %v", err)
+	}
+
+	em := func(total int64, latency float64) *metrics.EventMetrics {
+		em := metrics.NewEventMetrics(time.Now()).
+			AddLabel("probe", "p1").
+			AddLabel("dst", "t1").
+			AddMetric("total", metrics.NewInt(total)).
+			AddMetric("success", metrics.NewInt(total)).
+			AddMetric("latency_ms", metrics.NewFloat(latency))
+		em.LatencyUnit = time.Millisecond
+		return em
+	}
+	s.Write(ctx, em(10, 100))
+	s.Write(ctx, em(20, 200)) // 10 more successes, 10ms each.
+
+	assert.Equal(t, uint32(10000), s.rows[0].latencyUsec)
+}
+
+func TestExpireRows(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	s := testSurfacer(t, ctx)
+
+	s.rows[0].lastUpdated = time.Now().Add(-2 * time.Hour)
+	s.expireRows(time.Now().Add(-time.Hour))
+	if assert.Len(t, s.rows, 1) {
+		assert.Equal(t, "t2", s.rows[0].target)
+	}
+
+	// Target comes back with a new index.
+	s.Write(ctx, testEM("p1", "t1", 10, 10, 100))
+	if assert.Len(t, s.rows, 2) {
+		assert.Equal(t, uint32(3), s.rows[1].index)
+	}
+	assert.Equal(t, s.rows[1], s.keys[[2]string{"p1", "t1"}])
+}
+
+func TestAgent(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	s := testSurfacer(t, ctx)
+
+	conn, err := net.Dial("udp", s.conn.LocalAddr().String())
+	if err != nil {
+		t.Fatalf("Error connecting to the SNMP agent: %v", err)
+	}
+	defer conn.Close()
+
+	_, err = conn.Write(testRequest(tagGetRequest, "public", 0, 0, s.baseOID.append(2, 0)))
+	assert.NoError(t, err)
+
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	buf := make([]byte, 1500)
+	n, err := conn.Read(buf)
+	if err != nil {
+		t.Fatalf("Error reading SNMP response: %v", err)
+	}
+	_, values := parseResponse(t, buf[:n])
+	assert.Equal(t, []tlv{{tagGauge32, []byte{2}}}, values)
+}
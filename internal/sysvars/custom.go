@@ -0,0 +1,154 @@
+// Copyright 2025 The Cloudprober Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sysvars
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// Custom sysvars are sourced from files or commands, and are refreshed on
+// every sysvars export. They are meant for site specific information, e.g.
+// rack, pop or pod, that can then be used in config templates, e.g. in
+// additional_label.
+//
+// Custom sysvars are configured through flags, and not through the config
+// file, as they need to be available before the config file is processed.
+var customVarsFlag customVars
+
+func init() {
+	flag.Var(customVarsFlag.source("file"), "sysvar_file", "Custom sysvar sourced from a file, in the format name=path. "+
+		"Can be repeated. File contents (with leading and trailing whitespace removed) are used as the sysvar value.")
+	flag.Var(customVarsFlag.source("cmd"), "sysvar_cmd", "Custom sysvar sourced from a command's output, in the format "+
+		"name=command. Can be repeated. Command is split into arguments on spaces and is run without a shell.")
+}
+
+// Max time a custom sysvar command is allowed to run.
+var customVarCmdTimeout = 10 * time.Second
+
+type customVar struct {
+	name string
+	file string
+	cmd  []string
+}
+
+func (cv *customVar) value(ctx context.Context) (string, error) {
+	if cv.file != "" {
+		b, err := os.ReadFile(cv.file)
+		if err != nil {
+			return "", err
+		}
+		return strings.TrimSpace(string(b)), nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, customVarCmdTimeout)
+	defer cancel()
+	out, err := exec.CommandContext(ctx, cv.cmd[0], cv.cmd[1:]...).Output()
+	if err != nil {
+		return "", fmt.Errorf("error running command (%s): %v", strings.Join(cv.cmd, " "), err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+func parseCustomVar(source, s string) (*customVar, error) {
+	name, v, ok := strings.Cut(s, "=")
+	if !ok || name == "" || v == "" {
+		return nil, fmt.Errorf("invalid custom sysvar: %s, expected format: name=value", s)
+	}
+	cv := &customVar{name: name}
+	switch source {
+	case "file":
+		cv.file = v
+	case "cmd":
+		cv.cmd = strings.Fields(v)
+		if len(cv.cmd) == 0 {
+			return nil, fmt.Errorf("invalid custom sysvar: %s, command is empty", s)
+		}
+	}
+	return cv, nil
+}
+
+type customVars []*customVar
+
+// customVarsSource implements flag.Value for one of the custom sysvar
+// sources.
+type customVarsSource struct {
+	vars   *customVars
+	source string
+}
+
+func (cvs *customVars) source(source string) *customVarsSource {
+	return &customVarsSource{vars: cvs, source: source}
+}
+
+func (s *customVarsSource) String() string {
+	if s == nil || s.vars == nil {
+		return ""
+	}
+	var parts []string
+	for _, cv := range *s.vars {
+		if s.source == "file" && cv.file != "" {
+			parts = append(parts, cv.name+"="+cv.file)
+		}
+		if s.source == "cmd" && cv.cmd != nil {
+			parts = append(parts, cv.name+"="+strings.Join(cv.cmd, " "))
+		}
+	}
+	return strings.Join(parts, ",")
+}
+
+func (s *customVarsSource) Set(v string) error {
+	cv, err := parseCustomVar(s.source, v)
+	if err != nil {
+		return err
+	}
+	*s.vars = append(*s.vars, cv)
+	return nil
+}
+
+// updateCustomVars updates vars with the current values of the custom sysvars.
+// It returns an error only if strict is true, otherwise it just logs the
+// errors and keeps the older values.
+func updateCustomVars(ctx context.Context, cvs customVars, vars map[string]string, strict bool) error {
+	for _, cv := range cvs {
+		v, err := cv.value(ctx)
+		if err != nil {
+			if strict {
+				return fmt.Errorf("error getting value for custom sysvar %s: %v", cv.name, err)
+			}
+			l.Warningf("Error getting value for custom sysvar %s: %v, keeping the old value", cv.name, err)
+			continue
+		}
+		vars[cv.name] = v
+	}
+	return nil
+}
+
+// refreshCustomVars refreshes the custom sysvars in the global sysvars map.
+func refreshCustomVars(ctx context.Context) {
+	newVars := make(map[string]string)
+	updateCustomVars(ctx, customVarsFlag, newVars, false)
+
+	sysVarsMu.Lock()
+	defer sysVarsMu.Unlock()
+	for k, v := range newVars {
+		sysVars[k] = v
+	}
+}
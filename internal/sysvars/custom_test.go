@@ -0,0 +1,85 @@
+// Copyright 2025 The Cloudprober Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sysvars
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCustomVarsFlag(t *testing.T) {
+	var cvs customVars
+	assert.NoError(t, cvs.source("file").Set("rack=/etc/rack"))
+	assert.NoError(t, cvs.source("cmd").Set("pop=/usr/bin/get-pop --short"))
+	assert.Error(t, cvs.source("file").Set("rack"))
+	assert.Error(t, cvs.source("cmd").Set("pop="))
+	assert.Error(t, cvs.source("cmd").Set("pop=  \t"))
+
+	assert.Len(t, cvs, 2)
+	assert.Equal(t, "rack=/etc/rack", cvs.source("file").String())
+	assert.Equal(t, "pop=/usr/bin/get-pop --short", cvs.source("cmd").String())
+	assert.Equal(t, []string{"/usr/bin/get-pop", "--short"}, cvs[1].cmd)
+}
+
+func TestCustomVars(t *testing.T) {
+	rackFile := filepath.Join(t.TempDir(), "rack")
+	if err := os.WriteFile(rackFile, []byte("rack-1\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cvs := customVars{
+		{name: "rack", file: rackFile},
+		{name: "pop", cmd: []string{"echo", "pop-1"}},
+	}
+
+	vars := map[string]string{}
+	assert.NoError(t, updateCustomVars(context.Background(), cvs, vars, true))
+	assert.Equal(t, map[string]string{"rack": "rack-1", "pop": "pop-1"}, vars)
+
+	// Refresh after the file changes.
+	if err := os.WriteFile(rackFile, []byte("rack-2"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	assert.NoError(t, updateCustomVars(context.Background(), cvs, vars, true))
+	assert.Equal(t, "rack-2", vars["rack"])
+
+	// Errors: strict mode returns an error, otherwise we keep the old value.
+	os.Remove(rackFile)
+	assert.Error(t, updateCustomVars(context.Background(), cvs, vars, true))
+	assert.NoError(t, updateCustomVars(context.Background(), cvs, vars, false))
+	assert.Equal(t, "rack-2", vars["rack"])
+
+	badCmd := customVars{{name: "pop", cmd: []string{"/non/existent/command"}}}
+	assert.Error(t, updateCustomVars(context.Background(), badCmd, vars, true))
+}
+
+func TestRefreshCustomVars(t *testing.T) {
+	defer func(old customVars) { customVarsFlag = old }(customVarsFlag)
+	sysVars = map[string]string{"hostname": "host1"}
+	defer func() { sysVars = nil }()
+
+	customVarsFlag = customVars{{name: "pod", cmd: []string{"echo", "pod-1"}}}
+	refreshCustomVars(context.Background())
+
+	assert.Equal(t, map[string]string{"hostname": "host1", "pod": "pod-1"}, Vars())
+
+	em := varsEventMetrics(map[string]string{"env": "prod"})
+	assert.Equal(t, "\"pod-1\"", em.Metric("pod").String())
+	assert.Equal(t, "\"prod\"", em.Metric("env").String())
+}
@@ -39,14 +39,15 @@ var (
 	startTime time.Time
 )
 
-var cloudMetadataFlag = flag.String("cloud_metadata", "auto", "Collect cloud metadata for [auto|gce|ec2|none]")
+var cloudMetadataFlag = flag.String("cloud_metadata", "auto", "Collect cloud metadata for [auto|gce|ec2|azure|none]")
 
 var cloudProviders = struct {
-	auto, gce, ec2 string
+	auto, gce, ec2, azure string
 }{
-	auto: "auto",
-	gce:  "gce",
-	ec2:  "ec2",
+	auto:  "auto",
+	gce:   "gce",
+	ec2:   "ec2",
+	azure: "azure",
 }
 
 func GetVar(k string) string {
@@ -110,11 +111,23 @@ func providersToCheck(fv string) []string {
 	}
 	// Update this list when we add new providers
 	if fv == cloudProviders.auto {
-		return []string{cloudProviders.gce, cloudProviders.ec2}
+		return []string{cloudProviders.gce, cloudProviders.ec2, cloudProviders.azure}
 	}
 	return []string{fv}
 }
 
+// setCommonVars sets the cloud-agnostic variables, e.g. instance, zone and
+// region, unless they are already set. These variables have the same names
+// as the GCE variables, so that config templates written for GCE work
+// unchanged on other clouds.
+func setCommonVars(vars, common map[string]string) {
+	for k, v := range common {
+		if vars[k] == "" && v != "" {
+			vars[k] = v
+		}
+	}
+}
+
 func initCloudMetadata(fv string) error {
 	for _, provider := range providersToCheck(fv) {
 		switch provider {
@@ -131,6 +144,12 @@ func initCloudMetadata(fv string) error {
 			if onEC2 {
 				return err
 			}
+		case cloudProviders.azure:
+			tryHard := fv == cloudProviders.azure
+			onAzure, err := azureVars(sysVars, tryHard, l)
+			if onAzure {
+				return err
+			}
 		default:
 			return fmt.Errorf("unknown cloud provider: %v", provider)
 		}
@@ -169,17 +188,23 @@ func Init(ll *logger.Logger, userVars map[string]string) error {
 		return err
 	}
 
+	hostIPv6Vars(sysVars, l)
+
+	if err := updateCustomVars(context.Background(), customVarsFlag, sysVars, true); err != nil {
+		return err
+	}
+
 	for k, v := range userVars {
 		sysVars[k] = v
 	}
 	return nil
 }
 
-// Start exports system variables at the given interval. It overlays variables with
-// variables passed through the envVarsName env variable.
-func Start(ctx context.Context, dataChan chan *metrics.EventMetrics, interval time.Duration, envVarsName string) {
+// varsEventMetrics returns system variables, overlaid with envVars, as
+// EventMetrics.
+func varsEventMetrics(envVars map[string]string) *metrics.EventMetrics {
 	vars := Vars()
-	for k, v := range parseEnvVars(envVarsName) {
+	for k, v := range envVars {
 		vars[k] = v
 	}
 	// Add reset timestamp (Unix epoch corresponding to when Cloudprober was started)
@@ -198,6 +223,15 @@ func Start(ctx context.Context, dataChan chan *metrics.EventMetrics, interval ti
 	for _, k := range varsKeys {
 		em.AddMetric(k, metrics.NewString(vars[k]))
 	}
+	return em
+}
+
+// Start exports system variables at the given interval. It overlays variables with
+// variables passed through the envVarsName env variable. Custom sysvars (see
+// --sysvar_file and --sysvar_cmd flags) are refreshed before every export.
+func Start(ctx context.Context, dataChan chan *metrics.EventMetrics, interval time.Duration, envVarsName string) {
+	envVars := parseEnvVars(envVarsName)
+	em := varsEventMetrics(envVars)
 	l.Info(em.String())
 
 	for ts := range time.Tick(interval) {
@@ -208,6 +242,11 @@ func Start(ctx context.Context, dataChan chan *metrics.EventMetrics, interval ti
 		default:
 		}
 
+		if len(customVarsFlag) != 0 {
+			refreshCustomVars(ctx)
+			em = varsEventMetrics(envVars)
+		}
+
 		// Update timestamp and publish static variables.
 		em.Timestamp = ts
 		dataChan <- em.Clone()
@@ -0,0 +1,115 @@
+// Copyright 2025 The Cloudprober Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sysvars
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/cloudprober/cloudprober/logger"
+)
+
+// Azure Instance Metadata Service (IMDS) endpoint.
+var azureMetadataURL = "http://169.254.169.254/metadata/instance?api-version=2021-02-01"
+
+type azureMetadata struct {
+	Compute struct {
+		Name              string `json:"name"`
+		VMID              string `json:"vmId"`
+		Location          string `json:"location"`
+		Zone              string `json:"zone"`
+		SubscriptionID    string `json:"subscriptionId"`
+		ResourceGroupName string `json:"resourceGroupName"`
+		VMSize            string `json:"vmSize"`
+	} `json:"compute"`
+	Network struct {
+		Interface []struct {
+			IPv4 struct {
+				IPAddress []struct {
+					PrivateIPAddress string `json:"privateIpAddress"`
+					PublicIPAddress  string `json:"publicIpAddress"`
+				} `json:"ipAddress"`
+			} `json:"ipv4"`
+		} `json:"interface"`
+	} `json:"network"`
+}
+
+var azureVars = func(sysVars map[string]string, tryHard bool, l *logger.Logger) (bool, error) {
+	ctx := context.Background()
+
+	// If not trying hard (cloud_metadata != azure), use shorter timeout.
+	if !tryHard {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 100*time.Millisecond)
+		defer cancel()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, azureMetadataURL, nil)
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Metadata", "true")
+
+	resp, err := http.DefaultClient.Do(req)
+	// Similar to EC2, we use the error here to decide if we are running on
+	// Azure or not.
+	if err != nil {
+		return false, fmt.Errorf("sysvars_azure: error getting instance metadata: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return false, fmt.Errorf("sysvars_azure: error getting instance metadata, status code: %d", resp.StatusCode)
+	}
+
+	var md azureMetadata
+	if err := json.NewDecoder(resp.Body).Decode(&md); err != nil {
+		return true, fmt.Errorf("sysvars_azure: error parsing instance metadata: %v", err)
+	}
+
+	sysVars["AZURE_METADATA_Available"] = "true"
+	sysVars["AZURE_VMName"] = md.Compute.Name
+	sysVars["AZURE_VMID"] = md.Compute.VMID
+	sysVars["AZURE_Location"] = md.Compute.Location
+	sysVars["AZURE_Zone"] = md.Compute.Zone
+	sysVars["AZURE_SubscriptionID"] = md.Compute.SubscriptionID
+	sysVars["AZURE_ResourceGroup"] = md.Compute.ResourceGroupName
+	sysVars["AZURE_VMSize"] = md.Compute.VMSize
+
+	common := map[string]string{
+		"instance":     md.Compute.Name,
+		"instance_id":  md.Compute.VMID,
+		"region":       md.Compute.Location,
+		"zone":         md.Compute.Location,
+		"project":      md.Compute.SubscriptionID,
+		"account":      md.Compute.SubscriptionID,
+		"machine_type": md.Compute.VMSize,
+	}
+	// Availability zones in Azure are just numbers, e.g. "1". We prefix them
+	// with the location, e.g. "eastus-1", to make them unique across regions.
+	if md.Compute.Zone != "" {
+		common["zone"] = md.Compute.Location + "-" + md.Compute.Zone
+	}
+	if ifaces := md.Network.Interface; len(ifaces) > 0 && len(ifaces[0].IPv4.IPAddress) > 0 {
+		common["internal_ip"] = ifaces[0].IPv4.IPAddress[0].PrivateIPAddress
+		common["external_ip"] = ifaces[0].IPv4.IPAddress[0].PublicIPAddress
+	}
+	setCommonVars(sysVars, common)
+
+	return true, nil
+}
@@ -0,0 +1,88 @@
+// Copyright 2025 The Cloudprober Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sysvars
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Other tests override azureVars, keep a reference to the original.
+var origAzureVars = azureVars
+
+func TestAzureVars(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Metadata") != "true" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		fmt.Fprint(w, `{
+			"compute": {
+				"name": "vm-1",
+				"vmId": "1234-5678",
+				"location": "eastus",
+				"zone": "2",
+				"subscriptionId": "sub-1",
+				"resourceGroupName": "rg-1",
+				"vmSize": "Standard_B2s"
+			},
+			"network": {
+				"interface": [{
+					"ipv4": {
+						"ipAddress": [{"privateIpAddress": "10.0.0.4", "publicIpAddress": ""}]
+					}
+				}]
+			}
+		}`)
+	}))
+	defer ts.Close()
+
+	defer func(u string) { azureMetadataURL = u }(azureMetadataURL)
+	azureMetadataURL = ts.URL
+
+	vars := map[string]string{"instance": "already-set"}
+	onAzure, err := origAzureVars(vars, true, nil)
+	assert.True(t, onAzure)
+	assert.NoError(t, err)
+
+	assert.Equal(t, map[string]string{
+		"AZURE_METADATA_Available": "true",
+		"AZURE_VMName":             "vm-1",
+		"AZURE_VMID":               "1234-5678",
+		"AZURE_Location":           "eastus",
+		"AZURE_Zone":               "2",
+		"AZURE_SubscriptionID":     "sub-1",
+		"AZURE_ResourceGroup":      "rg-1",
+		"AZURE_VMSize":             "Standard_B2s",
+		"instance":                 "already-set",
+		"instance_id":              "1234-5678",
+		"region":                   "eastus",
+		"zone":                     "eastus-2",
+		"project":                  "sub-1",
+		"account":                  "sub-1",
+		"machine_type":             "Standard_B2s",
+		"internal_ip":              "10.0.0.4",
+	}, vars)
+
+	// Not on Azure.
+	ts.Close()
+	onAzure, err = origAzureVars(map[string]string{}, false, nil)
+	assert.False(t, onAzure)
+	assert.Error(t, err)
+}
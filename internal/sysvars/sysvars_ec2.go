@@ -41,6 +41,7 @@ var ec2Vars = func(sysVars map[string]string, tryHard bool, l *logger.Logger) (b
 		return false, err
 	}
 
+	// IMDS client uses IMDSv2 (session token based) by default.
 	client := imds.NewFromConfig(cfg)
 
 	id, err := client.GetInstanceIdentityDocument(ctx, &imds.GetInstanceIdentityDocumentInput{})
@@ -60,6 +61,17 @@ var ec2Vars = func(sysVars map[string]string, tryHard bool, l *logger.Logger) (b
 	sysVars["EC2_KernelID"] = id.KernelID
 	sysVars["EC2_RamdiskID"] = id.RamdiskID
 	sysVars["EC2_Architecture"] = id.Architecture
+
+	setCommonVars(sysVars, map[string]string{
+		"instance":     id.InstanceID,
+		"instance_id":  id.InstanceID,
+		"region":       id.Region,
+		"zone":         id.AvailabilityZone,
+		"project":      id.AccountID,
+		"account":      id.AccountID,
+		"internal_ip":  id.PrivateIP,
+		"machine_type": id.InstanceType,
+	})
 	return true, nil
 }
 
@@ -16,6 +16,7 @@ package sysvars
 
 import (
 	"context"
+	"flag"
 	"fmt"
 	"os"
 	"strings"
@@ -29,6 +30,40 @@ import (
 // maxNICs is the number of NICs allowed on a VM. Used by addGceNicInfo.
 var maxNICs = 8
 
+var gceMetadataSysvarsFlag = flag.String("gce_metadata_sysvars", "", "Comma separated list of GCE custom metadata keys to export as sysvars, "+
+	"named metadata_<key>. Instance's custom metadata takes precedence over project's custom metadata.")
+
+// gceCustomMetadata returns the value of a GCE custom metadata key. It looks
+// for the key in the instance's custom metadata first, and then in the
+// project's custom metadata.
+var gceCustomMetadata = func(key string) (string, error) {
+	val, err := metadata.InstanceAttributeValue(key)
+	if _, notFound := err.(metadata.NotDefinedError); !notFound {
+		return val, err
+	}
+	return metadata.ProjectAttributeValue(key)
+}
+
+// addGCECustomMetadata adds the given custom metadata keys to vars, as
+// metadata_<key>. Keys that are not defined are skipped.
+func addGCECustomMetadata(vars map[string]string, keys string, l *logger.Logger) error {
+	for _, key := range strings.Split(keys, ",") {
+		if key = strings.TrimSpace(key); key == "" {
+			continue
+		}
+		v, err := gceCustomMetadata(key)
+		if err != nil {
+			if _, notFound := err.(metadata.NotDefinedError); notFound {
+				l.Warningf("sysvars_gce: custom metadata key %s not found", key)
+				continue
+			}
+			return fmt.Errorf("sysvars_gce: error while getting custom metadata %s: %v", key, err)
+		}
+		vars["metadata_"+key] = v
+	}
+	return nil
+}
+
 // commonGCEGKEVars sets the variables that are available on both - GCE and GKE
 // metadata. This list is based on
 // https://cloud.google.com/kubernetes-engine/docs/how-to/workload-identity#gke_mds
@@ -157,6 +192,10 @@ var gceVars = func(vars map[string]string, l *logger.Logger) (bool, error) {
 		vars["label_"+k] = v
 
 	}
+
+	if err := addGCECustomMetadata(vars, *gceMetadataSysvarsFlag, l); err != nil {
+		return onGCE, err
+	}
 	return onGCE, nil
 }
 
@@ -183,7 +222,9 @@ func labelsFromGCE(project, zone, instance string) (map[string]string, error) {
 // - Primary IP, if one is assigned to nic.
 //	 If no primary IP is found, assume that NIC doesn't exist.
 // - IPv6 IP, if one is assigned to nic.
-//   If nic0 has IPv6 IP, then assign ip to key: "internal_ipv6_ip"
+//   If nic0 has IPv6 IP, then assign ip to keys: "internal_ipv6_ip" and
+//   "internal_ipv6".
+// - External IPv6 IP of nic0, if any, is assigned to key: "external_ipv6".
 // - External IP, if one is assigned to nic.
 // - An IP alias, if any IP alias ranges are assigned to nic.
 //
@@ -208,6 +249,19 @@ func addGceNicInfo(vars map[string]string, l *logger.Logger) {
 			vars[k] = v
 			if i == 0 {
 				vars["internal_ipv6_ip"] = v
+				// There can be multiple IPv6 addresses, one per line.
+				if ips := strings.Fields(v); len(ips) > 0 {
+					vars["internal_ipv6"] = ips[0]
+				}
+			}
+		}
+
+		if i == 0 {
+			v, err = metadata.Get("instance/network-interfaces/0/ipv6-access-configs/0/external-ipv6")
+			if err != nil {
+				l.Debugf("VM does not have an external ipv6 ip")
+			} else if v = strings.TrimSpace(v); v != "" {
+				vars["external_ipv6"] = v
 			}
 		}
 	}
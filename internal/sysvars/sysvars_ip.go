@@ -0,0 +1,71 @@
+// Copyright 2025 The Cloudprober Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sysvars
+
+import (
+	"net"
+
+	"github.com/cloudprober/cloudprober/logger"
+)
+
+// interfaceAddrs is a variable so that it can be overridden in tests.
+var interfaceAddrs = net.InterfaceAddrs
+
+// hostIPv6Vars sets the internal_ipv6 and external_ipv6 variables from the
+// host's network interfaces, unless they are already set (e.g. through the
+// cloud metadata). Unique local addresses (fc00::/7) are considered internal,
+// and other global unicast addresses are considered external.
+func hostIPv6Vars(vars map[string]string, l *logger.Logger) {
+	if vars["internal_ipv6"] != "" && vars["external_ipv6"] != "" {
+		return
+	}
+
+	addrs, err := interfaceAddrs()
+	if err != nil {
+		l.Warningf("sysvars: error getting interface addresses: %v", err)
+		return
+	}
+
+	var internal, external string
+	for _, addr := range addrs {
+		ipNet, ok := addr.(*net.IPNet)
+		if !ok || ipNet.IP.To4() != nil || !ipNet.IP.IsGlobalUnicast() {
+			continue
+		}
+		if ipNet.IP.IsPrivate() {
+			if internal == "" {
+				internal = ipNet.IP.String()
+			}
+			continue
+		}
+		if external == "" {
+			external = ipNet.IP.String()
+		}
+	}
+
+	// On a lot of v6-only deployments, hosts have only global addresses. We
+	// use the external address as the internal one in that case, as that's
+	// the address that other hosts in the network can reach us at.
+	if internal == "" {
+		internal = external
+	}
+
+	if vars["internal_ipv6"] == "" && internal != "" {
+		vars["internal_ipv6"] = internal
+	}
+	if vars["external_ipv6"] == "" && external != "" {
+		vars["external_ipv6"] = external
+	}
+}
@@ -17,19 +17,22 @@ package sysvars
 import (
 	"context"
 	"fmt"
+	"net"
 	"reflect"
 	"testing"
 
+	"cloud.google.com/go/compute/metadata"
 	"github.com/cloudprober/cloudprober/logger"
 	"github.com/stretchr/testify/assert"
 )
 
 func TestProvidersToCheck(t *testing.T) {
 	flagToProviders := map[string][]string{
-		"auto": {"gce", "ec2"},
-		"gce":  {"gce"},
-		"ec2":  {"ec2"},
-		"none": nil,
+		"auto":  {"gce", "ec2", "azure"},
+		"gce":   {"gce"},
+		"ec2":   {"ec2"},
+		"azure": {"azure"},
+		"none":  nil,
 	}
 
 	for flagValue, expected := range flagToProviders {
@@ -52,6 +55,11 @@ var testEC2Vars = map[string]string{
 	"zone":     "ec2-zone-1",
 }
 
+var testAzureVars = map[string]string{
+	"platform": "azure",
+	"zone":     "azure-zone-1",
+}
+
 func testSetVars(vars, inVars map[string]string, onPlatform bool) (bool, error) {
 	if !onPlatform {
 		return onPlatform, nil
@@ -67,9 +75,9 @@ func TestInitCloudMetadata(t *testing.T) {
 	defer func() { sysVars = nil }()
 
 	tests := []struct {
-		mode         string
-		onGCE, onEC2 bool
-		expected     map[string]string
+		mode                  string
+		onGCE, onEC2, onAzure bool
+		expected              map[string]string
 	}{
 		{
 			mode:     "auto",
@@ -101,6 +109,21 @@ func TestInitCloudMetadata(t *testing.T) {
 			onEC2:    true,
 			expected: testEC2Vars,
 		},
+		{
+			mode:     "auto",
+			onAzure:  true,
+			expected: testAzureVars,
+		},
+		{
+			mode:     "auto",
+			expected: map[string]string{},
+		},
+		{
+			mode:     "azure",
+			onGCE:    true,
+			onAzure:  true,
+			expected: testAzureVars,
+		},
 	}
 
 	for _, test := range tests {
@@ -113,6 +136,9 @@ func TestInitCloudMetadata(t *testing.T) {
 			ec2Vars = func(vars map[string]string, tryHard bool, l *logger.Logger) (bool, error) {
 				return testSetVars(vars, testEC2Vars, test.onEC2)
 			}
+			azureVars = func(vars map[string]string, tryHard bool, l *logger.Logger) (bool, error) {
+				return testSetVars(vars, testAzureVars, test.onAzure)
+			}
 
 			if err := initCloudMetadata(test.mode); err != nil {
 				t.Errorf("Got unexpected error: %v", err)
@@ -226,3 +252,91 @@ func TestParseEnvVars(t *testing.T) {
 		})
 	}
 }
+
+func TestHostIPv6Vars(t *testing.T) {
+	defer func(f func() ([]net.Addr, error)) { interfaceAddrs = f }(interfaceAddrs)
+
+	ipNet := func(s string) net.Addr {
+		ip, n, _ := net.ParseCIDR(s)
+		n.IP = ip
+		return n
+	}
+
+	tests := []struct {
+		name     string
+		addrs    []net.Addr
+		vars     map[string]string
+		expected map[string]string
+	}{
+		{
+			name: "internal_and_external",
+			addrs: []net.Addr{
+				ipNet("127.0.0.1/8"),
+				ipNet("::1/128"),
+				ipNet("fe80::1/64"),
+				ipNet("10.1.1.1/24"),
+				ipNet("2001:db8::1/64"),
+				ipNet("fd00::1/64"),
+			},
+			expected: map[string]string{
+				"internal_ipv6": "fd00::1",
+				"external_ipv6": "2001:db8::1",
+			},
+		},
+		{
+			name:  "only_global",
+			addrs: []net.Addr{ipNet("2001:db8::1/64")},
+			expected: map[string]string{
+				"internal_ipv6": "2001:db8::1",
+				"external_ipv6": "2001:db8::1",
+			},
+		},
+		{
+			name:     "no_ipv6",
+			addrs:    []net.Addr{ipNet("10.1.1.1/24"), ipNet("fe80::1/64")},
+			expected: map[string]string{},
+		},
+		{
+			name:  "already_set_from_metadata",
+			addrs: []net.Addr{ipNet("2001:db8::1/64"), ipNet("fd00::1/64")},
+			vars:  map[string]string{"internal_ipv6": "fd00::2"},
+			expected: map[string]string{
+				"internal_ipv6": "fd00::2",
+				"external_ipv6": "2001:db8::1",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			interfaceAddrs = func() ([]net.Addr, error) { return tt.addrs, nil }
+			vars := tt.vars
+			if vars == nil {
+				vars = map[string]string{}
+			}
+			hostIPv6Vars(vars, &logger.Logger{})
+			assert.Equal(t, tt.expected, vars)
+		})
+	}
+}
+
+func TestAddGCECustomMetadata(t *testing.T) {
+	defer func(f func(string) (string, error)) { gceCustomMetadata = f }(gceCustomMetadata)
+
+	md := map[string]string{"tier": "edge", "pop": "sjc"}
+	gceCustomMetadata = func(key string) (string, error) {
+		if key == "bad" {
+			return "", fmt.Errorf("metadata server error")
+		}
+		if v, ok := md[key]; ok {
+			return v, nil
+		}
+		return "", metadata.NotDefinedError(key)
+	}
+
+	vars := map[string]string{}
+	assert.NoError(t, addGCECustomMetadata(vars, "tier, pop,missing,", &logger.Logger{}))
+	assert.Equal(t, map[string]string{"metadata_tier": "edge", "metadata_pop": "sjc"}, vars)
+
+	assert.Error(t, addGCECustomMetadata(vars, "tier,bad", &logger.Logger{}))
+}
@@ -0,0 +1,119 @@
+// Copyright 2025 The Cloudprober Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package checksum provides a response checksum validator for the
+// Cloudprober's validator framework.
+package checksum
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+
+	configpb "github.com/cloudprober/cloudprober/internal/validators/checksum/proto"
+	"github.com/cloudprober/cloudprober/logger"
+)
+
+// Validator implements a checksum validator.
+type Validator struct {
+	sha256       []byte
+	sha256Header string
+}
+
+// Init initializes the checksum validator.
+func (v *Validator) Init(config interface{}) error {
+	c, ok := config.(*configpb.Validator)
+	if !ok {
+		return fmt.Errorf("%v is not a valid checksum validator config", config)
+	}
+
+	switch c.GetExpected().(type) {
+	case *configpb.Validator_Sha256:
+		b, err := hex.DecodeString(c.GetSha256())
+		if err != nil || len(b) != sha256.Size {
+			return fmt.Errorf("invalid SHA-256 checksum (%s), should be %d hex encoded bytes", c.GetSha256(), sha256.Size)
+		}
+		v.sha256 = b
+	case *configpb.Validator_Sha256Header:
+		if c.GetSha256Header() == "" {
+			return fmt.Errorf("sha256_header can't be empty")
+		}
+		v.sha256Header = c.GetSha256Header()
+	default:
+		return fmt.Errorf("checksum validator: one of sha256 and sha256_header should be set")
+	}
+
+	return nil
+}
+
+// parseChecksum parses a SHA-256 checksum from a header value. Value can be
+// hex or base64 encoded, optionally in the Digest header format, e.g.
+// sha-256=<base64> or sha-256=:<base64>:.
+func parseChecksum(s string) ([]byte, error) {
+	s = strings.TrimSpace(s)
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if len(part) > len("sha-256=") && strings.EqualFold(part[:len("sha-256=")], "sha-256=") {
+			s = strings.Trim(part[len("sha-256="):], ":")
+			break
+		}
+	}
+
+	if b, err := hex.DecodeString(s); err == nil && len(b) == sha256.Size {
+		return b, nil
+	}
+	if b, err := base64.StdEncoding.DecodeString(s); err == nil && len(b) == sha256.Size {
+		return b, nil
+	}
+	return nil, fmt.Errorf("invalid SHA-256 checksum: %s", s)
+}
+
+func (v *Validator) expectedChecksum(response interface{}) ([]byte, error) {
+	if v.sha256 != nil {
+		return v.sha256, nil
+	}
+
+	resp, ok := response.(*http.Response)
+	if !ok {
+		return nil, fmt.Errorf("checksum validator: sha256_header works only for HTTP responses, got: %T", response)
+	}
+	val := resp.Header.Get(v.sha256Header)
+	if val == "" {
+		return nil, nil
+	}
+	return parseChecksum(val)
+}
+
+// Validate validates the checksum of the response body.
+func (v *Validator) Validate(response interface{}, responseBody []byte, l *logger.Logger) (bool, error) {
+	want, err := v.expectedChecksum(response)
+	if err != nil {
+		return false, err
+	}
+	if want == nil {
+		l.Errorf("Checksum validation failure: checksum header %s not found in the response", v.sha256Header)
+		return false, nil
+	}
+
+	got := sha256.Sum256(responseBody)
+	if !bytes.Equal(got[:], want) {
+		l.Errorf("Checksum validation failure: response SHA-256 %x doesn't match the expected checksum %x", got, want)
+		return false, nil
+	}
+	return true, nil
+}
@@ -0,0 +1,110 @@
+// Copyright 2025 The Cloudprober Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package checksum
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"net/http"
+	"testing"
+
+	configpb "github.com/cloudprober/cloudprober/internal/validators/checksum/proto"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	testBody      = []byte("cloudprober")
+	testSum       = sha256.Sum256(testBody)
+	testSumHex    = hex.EncodeToString(testSum[:])
+	testSumBase64 = base64.StdEncoding.EncodeToString(testSum[:])
+)
+
+func TestInit(t *testing.T) {
+	for _, test := range []struct {
+		desc    string
+		c       *configpb.Validator
+		wantErr bool
+	}{
+		{desc: "empty", c: &configpb.Validator{}, wantErr: true},
+		{desc: "sha256", c: &configpb.Validator{Expected: &configpb.Validator_Sha256{Sha256: testSumHex}}},
+		{desc: "sha256_short", c: &configpb.Validator{Expected: &configpb.Validator_Sha256{Sha256: "abcd"}}, wantErr: true},
+		{desc: "sha256_not_hex", c: &configpb.Validator{Expected: &configpb.Validator_Sha256{Sha256: testSumBase64}}, wantErr: true},
+		{desc: "sha256_header", c: &configpb.Validator{Expected: &configpb.Validator_Sha256Header{Sha256Header: "X-Checksum-Sha256"}}},
+		{desc: "sha256_header_empty", c: &configpb.Validator{Expected: &configpb.Validator_Sha256Header{}}, wantErr: true},
+	} {
+		t.Run(test.desc, func(t *testing.T) {
+			v := &Validator{}
+			err := v.Init(test.c)
+			if test.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestValidateSHA256(t *testing.T) {
+	v := &Validator{}
+	assert.NoError(t, v.Init(&configpb.Validator{Expected: &configpb.Validator_Sha256{Sha256: testSumHex}}))
+
+	ok, err := v.Validate(nil, testBody, nil)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	ok, err = v.Validate(nil, []byte("cloudprobe"), nil)
+	assert.NoError(t, err)
+	assert.False(t, ok)
+}
+
+func TestValidateSHA256Header(t *testing.T) {
+	v := &Validator{}
+	assert.NoError(t, v.Init(&configpb.Validator{Expected: &configpb.Validator_Sha256Header{Sha256Header: "Repr-Digest"}}))
+
+	for _, test := range []struct {
+		desc    string
+		header  string
+		body    []byte
+		want    bool
+		wantErr bool
+	}{
+		{desc: "hex", header: testSumHex, body: testBody, want: true},
+		{desc: "base64", header: testSumBase64, body: testBody, want: true},
+		{desc: "digest", header: "sha-256=:" + testSumBase64 + ":", body: testBody, want: true},
+		{desc: "digest_multiple", header: "md5=abc, SHA-256=" + testSumBase64, body: testBody, want: true},
+		{desc: "mismatch", header: testSumHex, body: []byte("cloudprobe"), want: false},
+		{desc: "missing_header", header: "", body: testBody, want: false},
+		{desc: "invalid_header", header: "not-a-checksum", body: testBody, wantErr: true},
+	} {
+		t.Run(test.desc, func(t *testing.T) {
+			resp := &http.Response{Header: http.Header{}}
+			if test.header != "" {
+				resp.Header.Set("Repr-Digest", test.header)
+			}
+			ok, err := v.Validate(resp, test.body, nil)
+			if test.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, test.want, ok)
+		})
+	}
+
+	// Header based validation works only for HTTP responses.
+	_, err := v.Validate(nil, testBody, nil)
+	assert.Error(t, err)
+}
@@ -0,0 +1,177 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.11
+// 	protoc        v5.27.5
+// source: github.com/cloudprober/cloudprober/internal/validators/checksum/proto/config.proto
+
+package proto
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// Checksum validator verifies the SHA-256 checksum of the response body, e.g.
+// to detect corruption of static artifacts served through a CDN. Validation
+// fails if the checksum doesn't match.
+type Validator struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Types that are valid to be assigned to Expected:
+	//
+	//	*Validator_Sha256
+	//	*Validator_Sha256Header
+	Expected      isValidator_Expected `protobuf_oneof:"expected"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Validator) Reset() {
+	*x = Validator{}
+	mi := &file_github_com_cloudprober_cloudprober_internal_validators_checksum_proto_config_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Validator) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Validator) ProtoMessage() {}
+
+func (x *Validator) ProtoReflect() protoreflect.Message {
+	mi := &file_github_com_cloudprober_cloudprober_internal_validators_checksum_proto_config_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Validator.ProtoReflect.Descriptor instead.
+func (*Validator) Descriptor() ([]byte, []int) {
+	return file_github_com_cloudprober_cloudprober_internal_validators_checksum_proto_config_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Validator) GetExpected() isValidator_Expected {
+	if x != nil {
+		return x.Expected
+	}
+	return nil
+}
+
+func (x *Validator) GetSha256() string {
+	if x != nil {
+		if x, ok := x.Expected.(*Validator_Sha256); ok {
+			return x.Sha256
+		}
+	}
+	return ""
+}
+
+func (x *Validator) GetSha256Header() string {
+	if x != nil {
+		if x, ok := x.Expected.(*Validator_Sha256Header); ok {
+			return x.Sha256Header
+		}
+	}
+	return ""
+}
+
+type isValidator_Expected interface {
+	isValidator_Expected()
+}
+
+type Validator_Sha256 struct {
+	// Expected SHA-256 checksum of the response body, hex encoded.
+	Sha256 string `protobuf:"bytes,1,opt,name=sha256,proto3,oneof"`
+}
+
+type Validator_Sha256Header struct {
+	// Response header that provides the expected SHA-256 checksum, e.g.
+	// "X-Checksum-Sha256". Header value can be hex or base64 encoded, and can
+	// be in the Digest/Repr-Digest format, e.g. "sha-256=:<base64>:". This
+	// works only for HTTP responses. Validation fails if header is missing.
+	Sha256Header string `protobuf:"bytes,2,opt,name=sha256_header,json=sha256Header,proto3,oneof"`
+}
+
+func (*Validator_Sha256) isValidator_Expected() {}
+
+func (*Validator_Sha256Header) isValidator_Expected() {}
+
+var File_github_com_cloudprober_cloudprober_internal_validators_checksum_proto_config_proto protoreflect.FileDescriptor
+
+const file_github_com_cloudprober_cloudprober_internal_validators_checksum_proto_config_proto_rawDesc = "" +
+	"\n" +
+	"Rgithub.com/cloudprober/cloudprober/internal/validators/checksum/proto/config.proto\x12\x1fcloudprober.validators.checksum\"X\n" +
+	"\tValidator\x12\x18\n" +
+	"\x06sha256\x18\x01 \x01(\tH\x00R\x06sha256\x12%\n" +
+	"\rsha256_header\x18\x02 \x01(\tH\x00R\fsha256HeaderB\n" +
+	"\n" +
+	"\bexpectedBGZEgithub.com/cloudprober/cloudprober/internal/validators/checksum/protob\x06proto3"
+
+var (
+	file_github_com_cloudprober_cloudprober_internal_validators_checksum_proto_config_proto_rawDescOnce sync.Once
+	file_github_com_cloudprober_cloudprober_internal_validators_checksum_proto_config_proto_rawDescData []byte
+)
+
+func file_github_com_cloudprober_cloudprober_internal_validators_checksum_proto_config_proto_rawDescGZIP() []byte {
+	file_github_com_cloudprober_cloudprober_internal_validators_checksum_proto_config_proto_rawDescOnce.Do(func() {
+		file_github_com_cloudprober_cloudprober_internal_validators_checksum_proto_config_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_github_com_cloudprober_cloudprober_internal_validators_checksum_proto_config_proto_rawDesc), len(file_github_com_cloudprober_cloudprober_internal_validators_checksum_proto_config_proto_rawDesc)))
+	})
+	return file_github_com_cloudprober_cloudprober_internal_validators_checksum_proto_config_proto_rawDescData
+}
+
+var file_github_com_cloudprober_cloudprober_internal_validators_checksum_proto_config_proto_msgTypes = make([]protoimpl.MessageInfo, 1)
+var file_github_com_cloudprober_cloudprober_internal_validators_checksum_proto_config_proto_goTypes = []any{
+	(*Validator)(nil), // 0: cloudprober.validators.checksum.Validator
+}
+var file_github_com_cloudprober_cloudprober_internal_validators_checksum_proto_config_proto_depIdxs = []int32{
+	0, // [0:0] is the sub-list for method output_type
+	0, // [0:0] is the sub-list for method input_type
+	0, // [0:0] is the sub-list for extension type_name
+	0, // [0:0] is the sub-list for extension extendee
+	0, // [0:0] is the sub-list for field type_name
+}
+
+func init() {
+	file_github_com_cloudprober_cloudprober_internal_validators_checksum_proto_config_proto_init()
+}
+func file_github_com_cloudprober_cloudprober_internal_validators_checksum_proto_config_proto_init() {
+	if File_github_com_cloudprober_cloudprober_internal_validators_checksum_proto_config_proto != nil {
+		return
+	}
+	file_github_com_cloudprober_cloudprober_internal_validators_checksum_proto_config_proto_msgTypes[0].OneofWrappers = []any{
+		(*Validator_Sha256)(nil),
+		(*Validator_Sha256Header)(nil),
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_github_com_cloudprober_cloudprober_internal_validators_checksum_proto_config_proto_rawDesc), len(file_github_com_cloudprober_cloudprober_internal_validators_checksum_proto_config_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   1,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_github_com_cloudprober_cloudprober_internal_validators_checksum_proto_config_proto_goTypes,
+		DependencyIndexes: file_github_com_cloudprober_cloudprober_internal_validators_checksum_proto_config_proto_depIdxs,
+		MessageInfos:      file_github_com_cloudprober_cloudprober_internal_validators_checksum_proto_config_proto_msgTypes,
+	}.Build()
+	File_github_com_cloudprober_cloudprober_internal_validators_checksum_proto_config_proto = out.File
+	file_github_com_cloudprober_cloudprober_internal_validators_checksum_proto_config_proto_goTypes = nil
+	file_github_com_cloudprober_cloudprober_internal_validators_checksum_proto_config_proto_depIdxs = nil
+}
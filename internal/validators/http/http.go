@@ -56,6 +56,9 @@ func (nr *numRange) find(i int) bool {
 //	403:     &numRange{403, 403}
 func parseNumRange(s string) (*numRange, error) {
 	fields := strings.Split(s, "-")
+	for i := range fields {
+		fields[i] = strings.TrimSpace(fields[i])
+	}
 	if len(fields) < 1 || len(fields) > 2 {
 		return nil, fmt.Errorf("number range %s is not in correct format (200 or 100-199)", s)
 	}
@@ -84,7 +87,7 @@ func parseNumRange(s string) (*numRange, error) {
 
 // parseStatusCodeConfig parses the status code config. Status codes are
 // defined as a comma-separated list of integer or integer ranges, for
-// example: 302,200-299.
+// example: 302,200-299. Spaces around the numbers are ignored.
 func parseStatusCodeConfig(s string) ([]*numRange, error) {
 	var statusCodeRanges []*numRange
 
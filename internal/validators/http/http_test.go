@@ -58,6 +58,15 @@ func TestParseStatusCodeConfig(t *testing.T) {
 		}
 	}
 
+	// Spaces around the numbers are ignored.
+	numRanges, err = parseStatusCodeConfig(" 302, 200 - 299,403 ")
+	if err != nil {
+		t.Errorf("parseStatusCodeConfig(): got error: %v", err)
+	}
+	if !reflect.DeepEqual(numRanges, expectedNR) {
+		t.Errorf("parseStatusCodeConfig(): got: %v, expected: %v", numRanges, expectedNR)
+	}
+
 	// Verify that parsing invalid status code strings result in an error.
 	invalidTestStr := []string{
 		"30a,404",
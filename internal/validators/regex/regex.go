@@ -27,6 +27,9 @@ import (
 // Validator implements a regex validator.
 type Validator struct {
 	r *regexp.Regexp
+
+	// If Invert is set, validation fails if the response matches the regex.
+	Invert bool
 }
 
 // Init initializes the regex validator.
@@ -51,9 +54,15 @@ func (v *Validator) Init(config interface{}) error {
 }
 
 // Validate the provided responseBody and return true if responseBody matches
-// the configured regex.
+// the configured regex (or doesn't match it, if Invert is set).
 func (v *Validator) Validate(responseBody []byte, l *logger.Logger) (bool, error) {
 	matched := v.r.Match(responseBody)
+	if v.Invert {
+		if matched {
+			l.Errorf("Regex validation failure: response %s matched the failure regex %s", string(responseBody), v.r.String())
+		}
+		return !matched, nil
+	}
 	if !matched {
 		l.Errorf("Regex validation failure: response %s didn't match the regex %s", string(responseBody), v.r.String())
 	}
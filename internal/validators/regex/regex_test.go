@@ -80,3 +80,23 @@ func TestPatternString(t *testing.T) {
 	}
 
 }
+
+func TestInvert(t *testing.T) {
+	v := Validator{Invert: true}
+	if err := v.Init("Internal Server Error"); err != nil {
+		t.Fatalf("v.Init(): got error: %v", err)
+	}
+
+	for body, want := range map[string]bool{
+		"<html>OK</html>":                    true,
+		"<html>Internal Server Error</html>": false,
+	} {
+		got, err := v.Validate([]byte(body), nil)
+		if err != nil {
+			t.Errorf("v.Validate(%s): got error: %v", body, err)
+		}
+		if got != want {
+			t.Errorf("v.Validate(%s): got %v, want %v", body, got, want)
+		}
+	}
+}
@@ -19,6 +19,7 @@ package validators
 import (
 	"fmt"
 
+	"github.com/cloudprober/cloudprober/internal/validators/checksum"
 	"github.com/cloudprober/cloudprober/internal/validators/http"
 	"github.com/cloudprober/cloudprober/internal/validators/integrity"
 	"github.com/cloudprober/cloudprober/internal/validators/json"
@@ -78,6 +79,16 @@ func initValidator(validatorConf *configpb.Validator) (validator *Validator, err
 		}
 		return
 
+	case *configpb.Validator_ChecksumValidator:
+		v := &checksum.Validator{}
+		if err := v.Init(validatorConf.GetChecksumValidator()); err != nil {
+			return nil, err
+		}
+		validator.Validate = func(input *Input, l *logger.Logger) (bool, error) {
+			return v.Validate(input.Response, input.ResponseBody, l)
+		}
+		return
+
 	case *configpb.Validator_IntegrityValidator:
 		v := &integrity.Validator{}
 		if err := v.Init(validatorConf.GetIntegrityValidator()); err != nil {
@@ -107,6 +118,16 @@ func initValidator(validatorConf *configpb.Validator) (validator *Validator, err
 			return v.Validate(input.ResponseBody, l)
 		}
 		return
+
+	case *configpb.Validator_FailureRegex:
+		v := &regex.Validator{Invert: true}
+		if err := v.Init(validatorConf.GetFailureRegex()); err != nil {
+			return nil, err
+		}
+		validator.Validate = func(input *Input, l *logger.Logger) (bool, error) {
+			return v.Validate(input.ResponseBody, l)
+		}
+		return
 	default:
 		err = fmt.Errorf("unknown validator type: %v", validatorConf.Type)
 		return
@@ -445,3 +445,22 @@ func TestAcceptRejectRegex(t *testing.T) {
 		})
 	}
 }
+
+func TestCommentLines(t *testing.T) {
+	input := strings.Join([]string{
+		"# HELP http_requests_total Total number of HTTP requests.",
+		"# TYPE http_requests_total counter",
+		"http_requests_total{code=\"200\"} 1027",
+		"  # Indented comment",
+		"queue_length 5",
+	}, "\n")
+
+	p, err := NewParser(&configpb.OutputMetricsOptions{}, nil)
+	assert.NoError(t, err)
+
+	ems := p.PayloadMetrics(&Input{Text: []byte(input)}, testTarget)
+	assert.Len(t, ems, 2)
+	assert.Equal(t, int64(1027), ems[0].Metric("http_requests_total").(metrics.NumValue).Int64())
+	assert.Equal(t, "200", ems[0].Label("code"))
+	assert.Equal(t, int64(5), ems[1].Metric("queue_length").(metrics.NumValue).Int64())
+}
@@ -255,7 +255,9 @@ func (p *Parser) lineBasedMetrics(text []byte, targetKey string) []*metrics.Even
 			continue
 		}
 		line = strings.TrimSpace(line)
-		if len(line) == 0 {
+		// Skip empty lines and comments, e.g. "# HELP" and "# TYPE" lines in
+		// the Prometheus text format.
+		if len(line) == 0 || line[0] == '#' {
 			continue
 		}
 
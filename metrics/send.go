@@ -0,0 +1,48 @@
+// Copyright 2025 The Cloudprober Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metrics
+
+import "sync/atomic"
+
+// Counters for the writes to full EventMetrics channels, across the process.
+var (
+	blockedSends atomic.Int64
+	droppedSends atomic.Int64
+)
+
+// Send sends em on the data channel. If the channel is full, em is dropped
+// if dropOnFull is true, otherwise Send blocks until there is room. Both
+// these events are counted, see SendStats.
+func Send(dataChan chan<- *EventMetrics, em *EventMetrics, dropOnFull bool) {
+	select {
+	case dataChan <- em:
+		return
+	default:
+	}
+
+	if dropOnFull {
+		droppedSends.Add(1)
+		return
+	}
+	blockedSends.Add(1)
+	dataChan <- em
+}
+
+// SendStats returns the number of sends that had to wait for room in the
+// data channel, and the number of EventMetrics dropped because the data
+// channel was full.
+func SendStats() (blocked, dropped int64) {
+	return blockedSends.Load(), droppedSends.Load()
+}
@@ -0,0 +1,51 @@
+// Copyright 2025 The Cloudprober Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metrics
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSend(t *testing.T) {
+	dataChan := make(chan *EventMetrics, 1)
+	blocked0, dropped0 := SendStats()
+
+	Send(dataChan, NewEventMetrics(time.Now()), true)
+	Send(dataChan, NewEventMetrics(time.Now()), true) // Dropped.
+
+	blocked, dropped := SendStats()
+	assert.Equal(t, int64(0), blocked-blocked0)
+	assert.Equal(t, int64(1), dropped-dropped0)
+	assert.Len(t, dataChan, 1)
+
+	// Without drops, Send should wait for room.
+	done := make(chan struct{})
+	go func() {
+		Send(dataChan, NewEventMetrics(time.Now()), false)
+		close(done)
+	}()
+
+	assert.Eventually(t, func() bool {
+		blocked, _ := SendStats()
+		return blocked-blocked0 == 1
+	}, 5*time.Second, 10*time.Millisecond)
+
+	<-dataChan
+	<-done
+	assert.Len(t, dataChan, 1)
+}
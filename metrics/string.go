@@ -14,7 +14,10 @@
 
 package metrics
 
-import "errors"
+import (
+	"encoding/json"
+	"errors"
+)
 
 // String implements a value type with string storage.
 // It satisfies the Value interface.
@@ -44,6 +47,11 @@ func (s String) String() string {
 	return "\"" + s.s + "\""
 }
 
+// MarshalJSON marshals the stored string as a JSON string.
+func (s String) MarshalJSON() ([]byte, error) {
+	return json.Marshal(s.s)
+}
+
 // Clone returns the copy of receiver String.
 func (s String) Clone() Value {
 	return String{s: s.s}
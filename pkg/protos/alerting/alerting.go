@@ -18,6 +18,8 @@ const Opsgenie_Responder_UNKNOWN_RESPONDER = alertingpb.Opsgenie_Responder_UNKNO
 const Opsgenie_Responder_USER = alertingpb.Opsgenie_Responder_USER
 type AlertConf = alertingpb.AlertConf
 type AlertConf_Severity = alertingpb.AlertConf_Severity
+type BurnRateCondition = alertingpb.BurnRateCondition
+type BurnRateWindow = alertingpb.BurnRateWindow
 type Condition = alertingpb.Condition
 type Email = alertingpb.Email
 type NotifyConfig = alertingpb.NotifyConfig
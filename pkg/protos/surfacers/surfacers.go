@@ -12,6 +12,7 @@ import postgrespb "github.com/cloudprober/cloudprober/internal/surfacers/postgre
 import probestatuspb "github.com/cloudprober/cloudprober/internal/surfacers/probestatus/proto"
 import prometheuspb "github.com/cloudprober/cloudprober/internal/surfacers/prometheus/proto"
 import pubsubpb "github.com/cloudprober/cloudprober/internal/surfacers/pubsub/proto"
+import snmppb "github.com/cloudprober/cloudprober/internal/surfacers/snmp/proto"
 import stackdriverpb "github.com/cloudprober/cloudprober/internal/surfacers/stackdriver/proto"
 import surfacerspb "github.com/cloudprober/cloudprober/internal/surfacers/proto"
 
@@ -31,6 +32,7 @@ const Type_POSTGRES = surfacerspb.Type_POSTGRES
 const Type_PROBESTATUS = surfacerspb.Type_PROBESTATUS
 const Type_PROMETHEUS = surfacerspb.Type_PROMETHEUS
 const Type_PUBSUB = surfacerspb.Type_PUBSUB
+const Type_SNMP = surfacerspb.Type_SNMP
 const Type_STACKDRIVER = surfacerspb.Type_STACKDRIVER
 const Type_USER_DEFINED = surfacerspb.Type_USER_DEFINED
 type LabelFilter = surfacerspb.LabelFilter
@@ -44,7 +46,9 @@ type SurfacerDef_PostgresSurfacer = surfacerspb.SurfacerDef_PostgresSurfacer
 type SurfacerDef_ProbestatusSurfacer = surfacerspb.SurfacerDef_ProbestatusSurfacer
 type SurfacerDef_PrometheusSurfacer = surfacerspb.SurfacerDef_PrometheusSurfacer
 type SurfacerDef_PubsubSurfacer = surfacerspb.SurfacerDef_PubsubSurfacer
+type SurfacerDef_SnmpSurfacer = surfacerspb.SurfacerDef_SnmpSurfacer
 type SurfacerDef_StackdriverSurfacer = surfacerspb.SurfacerDef_StackdriverSurfacer
+type SurfacerDef_UserDefinedConfig = surfacerspb.SurfacerDef_UserDefinedConfig
 type Type = surfacerspb.Type
 
 // Symbols from github.com/cloudprober/cloudprober/internal/surfacers/bigquery/proto
@@ -73,8 +77,12 @@ type DatadogSurfacerConf = datadogpb.SurfacerConf
 
 // Symbols from github.com/cloudprober/cloudprober/internal/surfacers/file/proto
 const FileDefault_SurfacerConf_CompressionEnabled = filepb.Default_SurfacerConf_CompressionEnabled
+const FileDefault_SurfacerConf_Format = filepb.Default_SurfacerConf_Format
 const FileDefault_SurfacerConf_Prefix = filepb.Default_SurfacerConf_Prefix
+const FileSurfacerConf_JSON = filepb.SurfacerConf_JSON
+const FileSurfacerConf_TEXT = filepb.SurfacerConf_TEXT
 type FileSurfacerConf = filepb.SurfacerConf
+type FileSurfacerConf_Format = filepb.SurfacerConf_Format
 
 // Symbols from github.com/cloudprober/cloudprober/internal/surfacers/otel/proto
 const OtelCompression_GZIP = otelpb.Compression_GZIP
@@ -113,6 +121,12 @@ type PrometheusSurfacerConf = prometheuspb.SurfacerConf
 const PubsubDefault_SurfacerConf_CompressionEnabled = pubsubpb.Default_SurfacerConf_CompressionEnabled
 type PubsubSurfacerConf = pubsubpb.SurfacerConf
 
+// Symbols from github.com/cloudprober/cloudprober/internal/surfacers/snmp/proto
+const SnmpDefault_SurfacerConf_Address = snmppb.Default_SurfacerConf_Address
+const SnmpDefault_SurfacerConf_Community = snmppb.Default_SurfacerConf_Community
+const SnmpDefault_SurfacerConf_EnterpriseOid = snmppb.Default_SurfacerConf_EnterpriseOid
+type SnmpSurfacerConf = snmppb.SurfacerConf
+
 // Symbols from github.com/cloudprober/cloudprober/internal/surfacers/stackdriver/proto
 const StackdriverDefault_SurfacerConf_BatchTimerSec = stackdriverpb.Default_SurfacerConf_BatchTimerSec
 const StackdriverDefault_SurfacerConf_MetricsBufferSize = stackdriverpb.Default_SurfacerConf_MetricsBufferSize
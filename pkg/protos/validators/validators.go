@@ -3,6 +3,7 @@
 
 package validators
 
+import checksumpb "github.com/cloudprober/cloudprober/internal/validators/checksum/proto"
 import httppb "github.com/cloudprober/cloudprober/internal/validators/http/proto"
 import integritypb "github.com/cloudprober/cloudprober/internal/validators/integrity/proto"
 import jsonpb "github.com/cloudprober/cloudprober/internal/validators/json/proto"
@@ -10,11 +11,18 @@ import validatorspb "github.com/cloudprober/cloudprober/internal/validators/prot
 
 // Symbols from github.com/cloudprober/cloudprober/internal/validators/proto
 type Validator = validatorspb.Validator
+type Validator_ChecksumValidator = validatorspb.Validator_ChecksumValidator
+type Validator_FailureRegex = validatorspb.Validator_FailureRegex
 type Validator_HttpValidator = validatorspb.Validator_HttpValidator
 type Validator_IntegrityValidator = validatorspb.Validator_IntegrityValidator
 type Validator_JsonValidator = validatorspb.Validator_JsonValidator
 type Validator_Regex = validatorspb.Validator_Regex
 
+// Symbols from github.com/cloudprober/cloudprober/internal/validators/checksum/proto
+type ChecksumValidator = checksumpb.Validator
+type ChecksumValidator_Sha256 = checksumpb.Validator_Sha256
+type ChecksumValidator_Sha256Header = checksumpb.Validator_Sha256Header
+
 // Symbols from github.com/cloudprober/cloudprober/internal/validators/http/proto
 type HttpValidator = httppb.Validator
 type HttpValidator_Header = httppb.Validator_Header
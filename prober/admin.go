@@ -0,0 +1,118 @@
+// Copyright 2025 The Cloudprober Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prober
+
+import (
+	"context"
+	"crypto/subtle"
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/cloudprober/cloudprober/probes/options"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// AdminToken returns the token configured for the admin endpoints.
+func (pr *Prober) AdminToken() string {
+	return pr.config().GetAdminToken()
+}
+
+// validBearerToken verifies the value of the authorization metadata against
+// the configured admin token.
+func validBearerToken(authorization, token string) bool {
+	bearer, ok := strings.CutPrefix(authorization, "Bearer ")
+	if !ok || token == "" {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(bearer), []byte(token)) == 1
+}
+
+// isLocalPeer returns true if the gRPC request came from the local host.
+func isLocalPeer(ctx context.Context) bool {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return false
+	}
+	switch addr := p.Addr.(type) {
+	case *net.TCPAddr:
+		return addr.IP.IsLoopback()
+	case *net.UnixAddr:
+		return true
+	}
+	return false
+}
+
+// checkAdminAuth verifies the admin token for the gRPC admin methods. If the
+// token is not configured, admin methods are available only to the local
+// clients.
+func (pr *Prober) checkAdminAuth(ctx context.Context) error {
+	token := pr.AdminToken()
+	if token == "" {
+		if isLocalPeer(ctx) {
+			return nil
+		}
+		return status.Error(codes.PermissionDenied, "admin methods are available only from localhost if admin_token is not configured")
+	}
+
+	md, _ := metadata.FromIncomingContext(ctx)
+	for _, authorization := range md.Get("authorization") {
+		if validBearerToken(authorization, token) {
+			return nil
+		}
+	}
+	return status.Error(codes.Unauthenticated, "missing or invalid admin token")
+}
+
+// SetProbePaused pauses or resumes the given probe. Paused state is recorded
+// by the probe name, and it's applied again if the probe is rebuilt, e.g. on
+// config reload.
+func (pr *Prober) SetProbePaused(name string, paused bool) error {
+	pr.mu.Lock()
+	defer pr.mu.Unlock()
+
+	pi := pr.Probes[name]
+	if pi == nil {
+		return fmt.Errorf("probe %s not found", name)
+	}
+
+	if paused {
+		pr.l.Warningf("Pausing probe: %s", name)
+		if pr.pausedProbes == nil {
+			pr.pausedProbes = make(map[string]bool)
+		}
+		pr.pausedProbes[name] = true
+		pi.Options.Pause()
+	} else {
+		pr.l.Infof("Resuming probe: %s", name)
+		delete(pr.pausedProbes, name)
+		pi.Options.Resume()
+	}
+	return nil
+}
+
+// SetLameDuckMode puts the instance in the lameduck mode, or takes it out of
+// it. No probe runs in the lameduck mode.
+func (pr *Prober) SetLameDuckMode(lameDuck bool) {
+	if lameDuck {
+		pr.l.Warning("Entering lameduck mode, probes will not run")
+	} else {
+		pr.l.Info("Exiting lameduck mode")
+	}
+	options.SetLameDuck(lameDuck)
+}
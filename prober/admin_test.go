@@ -0,0 +1,120 @@
+// Copyright 2025 The Cloudprober Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prober
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	configpb "github.com/cloudprober/cloudprober/config/proto"
+	pb "github.com/cloudprober/cloudprober/prober/proto"
+	"github.com/cloudprober/cloudprober/probes"
+	"github.com/cloudprober/cloudprober/probes/options"
+	probes_configpb "github.com/cloudprober/cloudprober/probes/proto"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestPauseResumeProbe(t *testing.T) {
+	pr := &Prober{
+		c: &configpb.ProberConfig{AdminToken: proto.String("t1")},
+		Probes: map[string]*probes.ProbeInfo{
+			"probe1": {ProbeDef: testProbeDef("probe1"), Options: options.DefaultOptions()},
+		},
+	}
+	opts := pr.Probes["probe1"].Options
+
+	authCtx := func(token string) context.Context {
+		return metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "Bearer "+token))
+	}
+
+	_, err := pr.PauseProbe(context.Background(), &pb.PauseProbeRequest{ProbeName: proto.String("probe1")})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	_, err = pr.PauseProbe(authCtx("t2"), &pb.PauseProbeRequest{ProbeName: proto.String("probe1")})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	assert.True(t, opts.IsScheduled())
+
+	_, err = pr.PauseProbe(authCtx("t1"), &pb.PauseProbeRequest{ProbeName: proto.String("probe1")})
+	assert.NoError(t, err)
+	assert.False(t, opts.IsScheduled())
+
+	resp, err := pr.ListProbes(context.Background(), &pb.ListProbesRequest{})
+	assert.NoError(t, err)
+	assert.True(t, resp.GetProbe()[0].GetPaused())
+
+	_, err = pr.PauseProbe(authCtx("t1"), &pb.PauseProbeRequest{ProbeName: proto.String("probe2")})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	_, err = pr.ResumeProbe(authCtx("t1"), &pb.ResumeProbeRequest{ProbeName: proto.String("probe1")})
+	assert.NoError(t, err)
+	assert.True(t, opts.IsScheduled())
+
+	// Lameduck mode doesn't change the paused state of the probes.
+	defer options.SetLameDuck(false)
+	_, err = pr.SetLameDuck(authCtx("t1"), &pb.SetLameDuckRequest{})
+	assert.NoError(t, err)
+	assert.False(t, opts.IsScheduled())
+	assert.False(t, opts.IsPaused())
+
+	_, err = pr.SetLameDuck(authCtx("t1"), &pb.SetLameDuckRequest{LameDuck: proto.Bool(false)})
+	assert.NoError(t, err)
+	assert.True(t, opts.IsScheduled())
+
+	// Without admin token, gRPC methods are available only from localhost.
+	pr.c = &configpb.ProberConfig{}
+	peerCtx := func(addr net.Addr) context.Context {
+		return peer.NewContext(context.Background(), &peer.Peer{Addr: addr})
+	}
+	_, err = pr.PauseProbe(context.Background(), &pb.PauseProbeRequest{ProbeName: proto.String("probe1")})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	_, err = pr.PauseProbe(peerCtx(&net.TCPAddr{IP: net.ParseIP("10.1.1.1"), Port: 1234}), &pb.PauseProbeRequest{ProbeName: proto.String("probe1")})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	assert.False(t, opts.IsPaused())
+
+	_, err = pr.PauseProbe(peerCtx(&net.TCPAddr{IP: net.ParseIP("127.0.0.1"), Port: 1234}), &pb.PauseProbeRequest{ProbeName: proto.String("probe1")})
+	assert.NoError(t, err)
+	assert.True(t, opts.IsPaused())
+}
+
+func TestPausedStateSurvivesReload(t *testing.T) {
+	pr, cancel := testProber(t, &configpb.ProberConfig{
+		Probe: []*probes_configpb.ProbeDef{testProbeDef("probe1"), testProbeDef("probe2")},
+	})
+	defer cancel()
+
+	assert.NoError(t, pr.SetProbePaused("probe1", true))
+	assert.NoError(t, pr.SetProbePaused("probe2", true))
+
+	// Changed probe is rebuilt, but it should stay paused.
+	changedDef := testProbeDef("probe1")
+	changedDef.Interval = proto.String("5s")
+	oldOpts := pr.Probes["probe1"].Options
+	assert.NoError(t, pr.ReloadProbes(&configpb.ProberConfig{
+		Probe: []*probes_configpb.ProbeDef{changedDef},
+	}))
+	assert.NotSame(t, oldOpts, pr.Probes["probe1"].Options)
+	assert.True(t, pr.Probes["probe1"].Options.IsPaused())
+
+	// Removed probe's paused state is forgotten.
+	assert.NoError(t, pr.ReloadProbes(&configpb.ProberConfig{
+		Probe: []*probes_configpb.ProbeDef{changedDef, testProbeDef("probe2")},
+	}))
+	assert.False(t, pr.Probes["probe2"].Options.IsPaused())
+}
@@ -16,6 +16,7 @@
 // demonstrate how cloudprober can be programmed dynamically.
 //
 // go run ./cmd/client.go --server localhost:9314 --add_probe newprobe.cfg
+// go run ./cmd/client.go --server localhost:9314 --add_probe newprobe.cfg --ttl 10m
 // go run ./cmd/client.go --server localhost:9314 --rm_probe newprobe
 package main
 
@@ -37,6 +38,7 @@ var (
 	server   = flag.String("server", "", "gRPC server address")
 	addProbe = flag.String("add_probe", "", "Path to probe config to add")
 	rmProbe  = flag.String("rm_probe", "", "Probe name to remove")
+	ttl      = flag.Duration("ttl", 0, "If set, added probe is removed automatically after this duration")
 )
 
 func main() {
@@ -73,7 +75,12 @@ func main() {
 			log.Fatal(err)
 		}
 
-		_, err = client.AddProbe(context.Background(), &pb.AddProbeRequest{ProbeConfig: cfg})
+		req := &pb.AddProbeRequest{ProbeConfig: cfg}
+		if *ttl != 0 {
+			ttlSec := int32(ttl.Seconds())
+			req.TtlSec = &ttlSec
+		}
+		_, err = client.AddProbe(context.Background(), req)
 		if err != nil {
 			log.Fatal(err)
 		}
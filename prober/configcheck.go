@@ -0,0 +1,147 @@
+// Copyright 2025 The Cloudprober Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prober
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"time"
+
+	configpb "github.com/cloudprober/cloudprober/config/proto"
+	"github.com/cloudprober/cloudprober/internal/namespace"
+	surfacerpb "github.com/cloudprober/cloudprober/internal/surfacers/proto"
+	"github.com/cloudprober/cloudprober/logger"
+	"github.com/cloudprober/cloudprober/probes"
+	"github.com/cloudprober/cloudprober/probes/options"
+	"github.com/cloudprober/cloudprober/surfacers"
+	"github.com/cloudprober/cloudprober/targets"
+)
+
+// How long CheckConfig waits for the probes' targets to resolve, and how
+// often it checks them. Targets discovery providers (e.g. Kubernetes or
+// RDS) fetch the resources asynchronously.
+var (
+	checkTargetsTimeout  = 30 * time.Second
+	checkTargetsInterval = 500 * time.Millisecond
+)
+
+// CheckConfig does a dry run of the given config, going deeper than the
+// config parsing:
+//   - Shared targets, namespaces and probes are created (but not started).
+//     This verifies the probe options and probe type specific configs.
+//   - Probes' targets are expanded, waiting for the discovery providers to
+//     return at least one target.
+//   - Surfacers are initialized and closed right away, verifying their
+//     configs and, for most surfacers, credentials and connectivity.
+//
+// Servers are not checked, as they need to bind to their ports. Returned
+// error includes all the errors found, prefixed with the config section.
+func CheckConfig(ctx context.Context, cfg *configpb.ProberConfig, l *logger.Logger) error {
+	var errs []error
+	addErr := func(section string, err error) {
+		errs = append(errs, fmt.Errorf("%s: %v", section, err))
+	}
+
+	if cfg.GetMetricsBufferSize() < 1 {
+		addErr("metrics_buffer_size", fmt.Errorf("should be at least 1, got %d", cfg.GetMetricsBufferSize()))
+	}
+
+	globalTargetsOpts := cfg.GetGlobalTargetsOptions()
+	for _, st := range cfg.GetSharedTargets() {
+		tgts, err := targets.New(st.GetTargets(), nil, globalTargetsOpts, l, l)
+		if err != nil {
+			addErr("shared_targets "+st.GetName(), err)
+			continue
+		}
+		targets.SetSharedTargets(st.GetName(), tgts)
+	}
+
+	namespaces, err := namespace.Init(cfg.GetNamespace(), l)
+	if err != nil {
+		addErr("namespace", err)
+	}
+	for _, ns := range namespaces {
+		for _, s := range ns.Surfacers() {
+			if !slices.ContainsFunc(cfg.GetSurfacer(), func(sd *surfacerpb.SurfacerDef) bool { return sd.GetName() == s }) {
+				addErr("namespace "+ns.Name, fmt.Errorf("unknown surfacer: %s", s))
+			}
+		}
+	}
+
+	// Probes with targets, to check that their targets resolve.
+	pendingTargets := make(map[string]*options.Options)
+
+	for _, p := range cfg.GetProbe() {
+		section := "probe " + p.GetName()
+		if nsName := p.GetNamespace(); nsName != "" && namespaces[nsName] == nil {
+			addErr(section, fmt.Errorf("namespace %s is not defined", nsName))
+			continue
+		}
+
+		opts, err := options.BuildProbeOptions(p, nil, cfg, l)
+		if err != nil {
+			addErr(section, err)
+			continue
+		}
+		if _, err := probes.CreateProbe(p, opts); err != nil {
+			addErr(section, err)
+			continue
+		}
+		if p.GetTargets() != nil {
+			pendingTargets[p.GetName()] = opts
+		}
+	}
+
+	for _, name := range waitForTargets(ctx, pendingTargets) {
+		addErr("probe "+name, errors.New("targets didn't resolve to any endpoints"))
+	}
+
+	if err := surfacers.Validate(ctx, cfg.GetSurfacer()); err != nil {
+		addErr("surfacers", err)
+	}
+
+	return errors.Join(errs...)
+}
+
+// waitForTargets waits for the given probes' targets to resolve to at least
+// one endpoint, and returns the names of the probes whose targets didn't
+// resolve in time.
+func waitForTargets(ctx context.Context, pending map[string]*options.Options) []string {
+	ticker := time.NewTicker(checkTargetsInterval)
+	defer ticker.Stop()
+	timeout := time.After(checkTargetsTimeout)
+
+	for {
+		for name, opts := range pending {
+			if len(opts.Targets.ListEndpoints()) > 0 {
+				delete(pending, name)
+			}
+		}
+		if len(pending) == 0 {
+			return nil
+		}
+
+		select {
+		case <-ticker.C:
+		case <-timeout:
+			return slices.Sorted(maps.Keys(pending))
+		case <-ctx.Done():
+			return slices.Sorted(maps.Keys(pending))
+		}
+	}
+}
@@ -0,0 +1,120 @@
+// Copyright 2025 The Cloudprober Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prober
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	configpb "github.com/cloudprober/cloudprober/config/proto"
+	"github.com/cloudprober/cloudprober/state"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/encoding/prototext"
+)
+
+func TestCheckConfig(t *testing.T) {
+	state.SetDefaultHTTPServeMux(http.NewServeMux())
+
+	oldTimeout, oldInterval := checkTargetsTimeout, checkTargetsInterval
+	defer func() { checkTargetsTimeout, checkTargetsInterval = oldTimeout, oldInterval }()
+	checkTargetsTimeout, checkTargetsInterval = 100*time.Millisecond, 10*time.Millisecond
+
+	tmpDir := t.TempDir()
+	emptyTargetsFile := filepath.Join(tmpDir, "targets.textpb")
+	assert.NoError(t, os.WriteFile(emptyTargetsFile, []byte(""), 0644))
+
+	goodProbe := `
+		probe {
+			name: "good"
+			type: HTTP
+			targets { host_names: "www.example.com" }
+		}`
+	goodSurfacer := fmt.Sprintf(`
+		surfacer {
+			type: FILE
+			file_surfacer { file_path: "%s" }
+		}`, filepath.Join(tmpDir, "metrics.txt"))
+
+	tests := []struct {
+		name     string
+		config   string
+		wantErrs []string
+	}{
+		{
+			name:   "ok",
+			config: goodProbe + goodSurfacer,
+		},
+		{
+			name: "bad_probe_options",
+			config: goodProbe + goodSurfacer + `
+				probe {
+					name: "bad_timeout"
+					type: HTTP
+					interval: "5s"
+					timeout: "10s"
+					targets { host_names: "www.example.com" }
+				}
+				probe {
+					name: "bad_namespace"
+					type: HTTP
+					namespace: "ns1"
+					targets { host_names: "www.example.com" }
+				}`,
+			wantErrs: []string{"probe bad_timeout: timeout", "probe bad_namespace: namespace ns1 is not defined"},
+		},
+		{
+			name: "unresolved_targets",
+			config: goodProbe + goodSurfacer + fmt.Sprintf(`
+				probe {
+					name: "no_targets"
+					type: HTTP
+					targets { file_targets { file_path: "%s" } }
+				}`, emptyTargetsFile),
+			wantErrs: []string{"probe no_targets:"},
+		},
+		{
+			name: "bad_surfacer",
+			config: goodProbe + `
+				surfacer {
+					name: "bad_file"
+					type: FILE
+					file_surfacer { file_path: "/non-existent-dir/metrics.txt" }
+				}`,
+			wantErrs: []string{"surfacer bad_file:"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &configpb.ProberConfig{}
+			assert.NoError(t, prototext.Unmarshal([]byte(tt.config), cfg))
+
+			err := CheckConfig(context.Background(), cfg, nil)
+			if len(tt.wantErrs) == 0 {
+				assert.NoError(t, err)
+				return
+			}
+			for _, wantErr := range tt.wantErrs {
+				assert.ErrorContains(t, err, wantErr)
+			}
+			assert.NotContains(t, err.Error(), "probe good:")
+		})
+	}
+}
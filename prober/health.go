@@ -0,0 +1,90 @@
+// Copyright 2025 The Cloudprober Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prober
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+const (
+	// Probes are considered stalled if we don't receive any probe data for
+	// this many stats export intervals (or probe intervals, if larger).
+	probeDataStalenessFactor = 3
+
+	// Minimum time without probe data before probes are considered stalled.
+	minProbeDataStaleness = time.Minute
+
+	// Queues are considered saturated when they are this full.
+	queueSaturationFraction = 0.9
+)
+
+func queueSaturated(depth, capacity int) bool {
+	return capacity > 0 && float64(depth) >= queueSaturationFraction*float64(capacity)
+}
+
+// probeDataStaleness returns the time without any probe data after which
+// probes are considered stalled.
+func (pr *Prober) probeDataStaleness() time.Duration {
+	pr.mu.RLock()
+	defer pr.mu.RUnlock()
+
+	var maxInterval time.Duration
+	for _, pi := range pr.Probes {
+		if pi.Options != nil {
+			maxInterval = max(maxInterval, pi.Options.StatsExportInterval, pi.Options.Interval)
+		}
+	}
+	return max(minProbeDataStaleness, probeDataStalenessFactor*maxInterval)
+}
+
+// Health checks the internal health of the prober, and returns an error
+// describing the problems found, if any:
+//   - Probes have stopped producing data.
+//   - Metrics buffer, between the probes and the surfacers, is saturated.
+//   - A surfacer's write queue is saturated.
+func (pr *Prober) Health() error {
+	if pr.startCtx == nil {
+		return errors.New("prober is not started")
+	}
+
+	var errs error
+
+	pr.mu.RLock()
+	numProbes := len(pr.Probes)
+	pr.mu.RUnlock()
+
+	if numProbes > 0 {
+		since := time.Since(time.Unix(0, pr.lastProbeData.Load()))
+		if staleness := pr.probeDataStaleness(); since > staleness {
+			errs = errors.Join(errs, fmt.Errorf("no probe data received for %v (threshold: %v)", since.Round(time.Second), staleness))
+		}
+	}
+
+	if depth, capacity := len(pr.dataChan), cap(pr.dataChan); queueSaturated(depth, capacity) {
+		errs = errors.Join(errs, fmt.Errorf("metrics buffer is saturated (%d/%d)", depth, capacity))
+	}
+
+	pr.surfacersMu.RLock()
+	defer pr.surfacersMu.RUnlock()
+	for _, si := range pr.Surfacers {
+		if depth, capacity := si.QueueDepth(), si.QueueCapacity(); queueSaturated(depth, capacity) {
+			errs = errors.Join(errs, fmt.Errorf("surfacer %s (%s) write queue is saturated (%d/%d)", si.Name, si.Type, depth, capacity))
+		}
+	}
+
+	return errs
+}
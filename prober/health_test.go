@@ -0,0 +1,61 @@
+// Copyright 2025 The Cloudprober Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prober
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/cloudprober/cloudprober/metrics"
+	"github.com/cloudprober/cloudprober/probes"
+	"github.com/cloudprober/cloudprober/probes/options"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHealth(t *testing.T) {
+	pr := &Prober{
+		Probes: map[string]*probes.ProbeInfo{
+			"p1": {Name: "p1", Options: &options.Options{Interval: 30 * time.Second, StatsExportInterval: time.Minute}},
+		},
+	}
+	assert.Error(t, pr.Health(), "prober not started")
+
+	pr.startCtx = context.Background()
+	pr.dataChan = make(chan *metrics.EventMetrics, 10)
+	pr.lastProbeData.Store(time.Now().UnixNano())
+	assert.NoError(t, pr.Health())
+
+	// Probe data is stale after 3 stats export intervals.
+	assert.Equal(t, 3*time.Minute, pr.probeDataStaleness())
+	pr.lastProbeData.Store(time.Now().Add(-4 * time.Minute).UnixNano())
+	assert.ErrorContains(t, pr.Health(), "no probe data")
+
+	// Probe data refreshes the health.
+	pr.writeToSurfacers(metrics.NewEventMetrics(time.Now()).AddLabel("probe", "p1"))
+	assert.NoError(t, pr.Health())
+
+	// Sysvars don't count as probe data.
+	pr.lastProbeData.Store(time.Now().Add(-4 * time.Minute).UnixNano())
+	pr.writeToSurfacers(metrics.NewEventMetrics(time.Now()).AddLabel("probe", "sysvars"))
+	assert.Error(t, pr.Health())
+	pr.lastProbeData.Store(time.Now().UnixNano())
+
+	// Saturated metrics buffer.
+	for i := 0; i < 9; i++ {
+		pr.dataChan <- metrics.NewEventMetrics(time.Now())
+	}
+	assert.ErrorContains(t, pr.Health(), "metrics buffer is saturated (9/10)")
+}
@@ -30,9 +30,11 @@ import (
 	"regexp"
 	"slices"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	configpb "github.com/cloudprober/cloudprober/config/proto"
+	"github.com/cloudprober/cloudprober/internal/namespace"
 	rdsserver "github.com/cloudprober/cloudprober/internal/rds/server"
 	"github.com/cloudprober/cloudprober/internal/servers"
 	"github.com/cloudprober/cloudprober/internal/sysvars"
@@ -50,6 +52,7 @@ import (
 	"github.com/cloudprober/cloudprober/targets/lameduck"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
 )
 
 var randGenerator = rand.New(rand.NewSource(time.Now().UnixNano()))
@@ -68,19 +71,52 @@ type Prober struct {
 	ldLister  endpoint.Lister
 	Surfacers []*surfacers.SurfacerInfo
 
+	// Surfacers can be swapped on config reload, surfacersMu protects the
+	// Surfacers list, and surfacersCtx is used to initialize new surfacers.
+	surfacersMu  sync.RWMutex
+	surfacersCtx context.Context
+
 	// We need this to start probes in response to API trigger. We still want
 	// these probes to exit if prober's start context gets canceled.
 	startCtx context.Context
 
+	// Window over which the probe starts are spread out, if configured.
+	probeStartWindow time.Duration
+
 	// Probe channel to handle starting of the new probes.
 	grpcStartProbeCh chan string
 
 	// Per-probe cancelFunc map.
 	probeCancelFunc map[string]context.CancelFunc
 
+	// Probe definitions as they were provided, before being modified during
+	// probe initialization. Used to detect probe changes on config reload.
+	probeSrcDefs map[string]*probes_configpb.ProbeDef
+
+	// Probes loaded from the config file. Only these probes are managed by
+	// the config reload, probes added through the gRPC API are left alone.
+	configProbes map[string]bool
+
+	// Expiration time for the probes added through the gRPC API with a TTL.
+	probeExpiration map[string]time.Time
+
+	// Probes paused through the admin endpoints. Paused state is kept by probe
+	// name, so that it survives the probe being rebuilt on config reload.
+	pausedProbes map[string]bool
+
 	// dataChan for passing metrics between probes and main goroutine.
 	dataChan chan *metrics.EventMetrics
 
+	// Last time we received data from a probe, as Unix time in nanoseconds.
+	// Used for health checking.
+	lastProbeData atomic.Int64
+
+	// Probe namespaces, and probe name to namespace mapping. The latter is
+	// protected by its own mutex as it's accessed for every EventMetrics.
+	namespaces     map[string]*namespace.Namespace
+	probeNSMu      sync.RWMutex
+	probeNamespace map[string]*namespace.Namespace
+
 	// Required for all gRPC server implementations.
 	spb.UnimplementedCloudproberServer
 }
@@ -96,6 +132,14 @@ func runOnThisHost(runOn string, hostname string) (bool, error) {
 	return r.MatchString(hostname), nil
 }
 
+// config returns the prober config. Config can be replaced on reload, hence
+// it should always be accessed through this method, unless pr.mu is held.
+func (pr *Prober) config() *configpb.ProberConfig {
+	pr.mu.RLock()
+	defer pr.mu.RUnlock()
+	return pr.c
+}
+
 func (pr *Prober) addProbe(p *probes_configpb.ProbeDef) error {
 	pr.mu.Lock()
 	defer pr.mu.Unlock()
@@ -109,25 +153,99 @@ func (pr *Prober) addProbe(p *probes_configpb.ProbeDef) error {
 		return nil
 	}
 
+	if p.GetName() == selfMetricsProbeName {
+		return status.Errorf(codes.InvalidArgument, "probe name %s is reserved for cloudprober's own metrics", p.GetName())
+	}
+
 	if pr.Probes[p.GetName()] != nil {
 		return status.Errorf(codes.AlreadyExists, "probe %s is already defined", p.GetName())
 	}
 
+	var ns *namespace.Namespace
+	if p.GetNamespace() != "" {
+		if ns = pr.namespaces[p.GetNamespace()]; ns == nil {
+			return status.Errorf(codes.InvalidArgument, "namespace %s is not defined", p.GetNamespace())
+		}
+		if ns.MaxProbes() > 0 && pr.numProbesInNamespace(ns.Name) >= ns.MaxProbes() {
+			return status.Errorf(codes.ResourceExhausted, "namespace %s already has max allowed probes (%d)", ns.Name, ns.MaxProbes())
+		}
+	}
+
+	srcDef := proto.Clone(p).(*probes_configpb.ProbeDef)
+
 	opts, err := options.BuildProbeOptions(p, pr.ldLister, pr.c, pr.l)
 	if err != nil {
 		return status.Error(codes.Unknown, err.Error())
 	}
 
+	if ns != nil && ns.LabelPrefix() != "" {
+		for _, al := range opts.AdditionalLabels {
+			al.Key = ns.LabelPrefix() + al.Key
+		}
+	}
+
 	pr.l.Infof("Creating a %s probe: %s", p.GetType(), p.GetName())
 	probeInfo, err := probes.CreateProbe(p, opts)
 	if err != nil {
 		return status.Error(codes.Unknown, err.Error())
 	}
+	if pr.pausedProbes[p.GetName()] {
+		probeInfo.Options.Pause()
+	}
 	pr.Probes[p.GetName()] = probeInfo
+	pr.probeSrcDefs[p.GetName()] = srcDef
+
+	if ns != nil {
+		pr.probeNSMu.Lock()
+		pr.probeNamespace[p.GetName()] = ns
+		pr.probeNSMu.Unlock()
+	}
 
 	return nil
 }
 
+// numProbesInNamespace returns the number of probes in the given namespace.
+// It should be called with pr.mu held.
+func (pr *Prober) numProbesInNamespace(nsName string) int {
+	n := 0
+	for _, p := range pr.Probes {
+		if p.ProbeDef.GetNamespace() == nsName {
+			n++
+		}
+	}
+	return n
+}
+
+// writeToSurfacers sends the EventMetrics to the surfacers. If EventMetrics
+// belongs to a namespaced probe, namespace's quotas and surfacer routing
+// are applied.
+func (pr *Prober) writeToSurfacers(em *metrics.EventMetrics) {
+	pr.probeNSMu.RLock()
+	ns := pr.probeNamespace[em.Label("probe")]
+	pr.probeNSMu.RUnlock()
+
+	if probe := em.Label("probe"); probe != "" && probe != "sysvars" && probe != selfMetricsProbeName {
+		pr.lastProbeData.Store(time.Now().UnixNano())
+	}
+
+	if ns != nil && !ns.Admit(em) {
+		return
+	}
+
+	// Replicate the surfacer message to every surfacer we have
+	// registered. Each surfacer has its own write queue, so that a slow
+	// surfacer doesn't block EventMetrics processing for the others.
+	pr.surfacersMu.RLock()
+	defer pr.surfacersMu.RUnlock()
+
+	for _, surfacer := range pr.Surfacers {
+		if ns != nil && !ns.RoutesTo(surfacer.Name) {
+			continue
+		}
+		surfacer.Write(pr.startCtx, em)
+	}
+}
+
 // startProbe starts the probe with the given name.
 // startProbe is protected and can be called concurrently. It's called
 // from Start() at the very beginning, and then every time a new probe is
@@ -136,9 +254,48 @@ func (pr *Prober) startProbe(name string) {
 	pr.mu.Lock()
 	defer pr.mu.Unlock()
 
+	pr.startProbeLocked(name, pr.Probes[name])
+}
+
+// startDeferredProbe starts the given probe, if it's still the current probe
+// for its name. Probe starts are staggered at start-up, and the probe may get
+// removed or replaced (e.g. by a config reload) while it's waiting for its
+// turn.
+func (pr *Prober) startDeferredProbe(p *probes.ProbeInfo) {
+	pr.mu.Lock()
+	defer pr.mu.Unlock()
+
+	if pr.Probes[p.Name] != p {
+		pr.l.Infof("Not starting probe %s, it was removed or replaced while waiting to start", p.Name)
+		return
+	}
+	pr.l.Info("Starting probe: ", p.Name)
+	pr.startProbeLocked(p.Name, p)
+}
+
+// startProbeLocked starts the given probe. It should be called with pr.mu
+// held.
+func (pr *Prober) startProbeLocked(name string, pi *probes.ProbeInfo) {
+	if pi == nil {
+		pr.l.Warningf("Not starting probe %s, it doesn't exist anymore", name)
+		return
+	}
+	if pr.probeCancelFunc[name] != nil {
+		pr.l.Warningf("Not starting probe %s, it's already running", name)
+		return
+	}
+
 	probeCtx, cancelFunc := context.WithCancel(pr.startCtx)
 	pr.probeCancelFunc[name] = cancelFunc
-	go pr.Probes[name].Start(probeCtx, pr.dataChan)
+
+	// Panics in the probe are recovered, and the probe is restarted as per
+	// its restart policy.
+	go pi.Options.RunWithRestarts(probeCtx, "", pr.dataChan, func() {
+		// Stop goroutines left behind by a panicked run before restarting.
+		runCtx, cancel := context.WithCancel(probeCtx)
+		defer cancel()
+		pi.Start(runCtx, pr.dataChan)
+	})
 }
 
 func randomDuration(duration time.Duration) time.Duration {
@@ -163,7 +320,7 @@ func randomDuration(duration time.Duration) time.Duration {
 //	12 probes in 5m interval, gap=25s, last probe starts at 4m35s
 //	8 probes in 10m interval, gap=1m, last probe starts at 7m
 //
-// TODO(manugarg): Allow this to be overridden with config.
+// It can be overridden with probe_start_window in the config.
 func interProbeGap(interval time.Duration, numProbes int) time.Duration {
 	d := interval / time.Duration(numProbes)
 	if d > 1*time.Minute {
@@ -172,24 +329,76 @@ func interProbeGap(interval time.Duration, numProbes int) time.Duration {
 	return d
 }
 
-// startProbesWithJitter try to space out probes over time, as much as
-// possible, without making it too complicated.
+// randomDurationUpTo returns a random duration in [0, d).
+func randomDurationUpTo(d time.Duration) time.Duration {
+	if d <= 0 {
+		return 0
+	}
+	return time.Duration(randGenerator.Int63n(int64(d)))
+}
+
+// startProbes starts all the probes. Unless disabled through config, it tries
+// to space out probes over time, as much as possible, without making it too
+// complicated.
 //
-// We arrange probes into interval buckets - all probes with the same interval
-// will be part of the same bucket. We spread out probes within an interval,
-// and also the overall interval-buckets themselves.
+// By default, we arrange probes into interval buckets - all probes with the
+// same interval will be part of the same bucket. We spread out probes within
+// an interval, and also the overall interval-buckets themselves.
 //
 //	[probe1 <gap> probe2 <gap> probe3 <gap> ...]    interval1 (30s)
 //	<interval-bucket-gap> [probe4 <gap> probe5 ...] interval2 (10s)
 //	<interval-bucket-gap> [probe6 <gap> probe7 ...] interval3 (1m)
 //
+// If probe_start_window is configured, probes are spread out evenly over that
+// window instead. Probes with their own start_jitter are not part of the
+// above, they are started after a random delay up to their start_jitter.
+//
 // Note this function is not concurrency safe, which is fine, because it's only
 // called from Start(), which is never called concurrently itself.
-func (pr *Prober) startProbesWithJitter() {
+func (pr *Prober) startProbes() {
+	var staggered []*probes.ProbeInfo
+	for _, p := range pr.Probes {
+		if p.Options.StartJitter == nil {
+			staggered = append(staggered, p)
+			continue
+		}
+		go func(p *probes.ProbeInfo, delay time.Duration) {
+			timer := time.NewTimer(delay)
+			defer timer.Stop()
+			select {
+			case <-pr.startCtx.Done():
+				return
+			case <-timer.C:
+			}
+			pr.startDeferredProbe(p)
+		}(p, randomDurationUpTo(*p.Options.StartJitter))
+	}
+
+	if pr.config().GetDisableJitter() {
+		for _, p := range staggered {
+			go pr.startDeferredProbe(p)
+		}
+		return
+	}
+
+	if pr.probeStartWindow > 0 {
+		if len(staggered) == 0 {
+			return
+		}
+		gap := pr.probeStartWindow / time.Duration(len(staggered))
+		go func() {
+			for _, p := range staggered {
+				go pr.startDeferredProbe(p)
+				time.Sleep(gap)
+			}
+		}()
+		return
+	}
+
 	// Make interval -> [probe1, probe2, probe3..] map
 	intervalBuckets := make(map[time.Duration][]*probes.ProbeInfo)
 
-	for _, p := range pr.Probes {
+	for _, p := range staggered {
 		intervalBuckets[p.Options.Interval] = append(intervalBuckets[p.Options.Interval], p)
 	}
 
@@ -208,8 +417,7 @@ func (pr *Prober) startProbesWithJitter() {
 			}
 
 			for _, p := range probeInfos {
-				pr.l.Info("Starting probe: ", p.Name)
-				go pr.startProbe(p.Name)
+				go pr.startDeferredProbe(p)
 				time.Sleep(interProbeGap(interval, len(probeInfos)))
 			}
 		}(interval, probeInfos, iter)
@@ -270,39 +478,28 @@ func (pr *Prober) Run(ctx context.Context, probeNames []string) (map[string][]*s
 // once from the main function, never in response to a gRPC request.
 func (pr *Prober) Start(ctx context.Context) {
 	pr.startCtx = ctx
+	c := pr.config()
 
-	pr.dataChan = make(chan *metrics.EventMetrics, 100000)
+	pr.dataChan = make(chan *metrics.EventMetrics, c.GetMetricsBufferSize())
+	pr.lastProbeData.Store(time.Now().UnixNano())
 
 	go func() {
-		var em *metrics.EventMetrics
 		for {
-			em = <-pr.dataChan
-
-			// Replicate the surfacer message to every surfacer we have
-			// registered. Note that s.Write() is expected to be
-			// non-blocking to avoid blocking of EventMetrics message
-			// processing.
-			for _, surfacer := range pr.Surfacers {
-				surfacer.Write(pr.startCtx, em)
-			}
+			pr.writeToSurfacers(<-pr.dataChan)
 		}
 	}()
 
 	// Start a goroutine to export system variables
-	go sysvars.Start(ctx, pr.dataChan, time.Millisecond*time.Duration(pr.c.GetSysvarsIntervalMsec()), pr.c.GetSysvarsEnvVar())
+	go sysvars.Start(ctx, pr.dataChan, time.Millisecond*time.Duration(c.GetSysvarsIntervalMsec()), c.GetSysvarsEnvVar())
+
+	go pr.exportSelfMetrics(ctx, time.Millisecond*time.Duration(c.GetSysvarsIntervalMsec()))
 
 	// Start servers, each in its own goroutine
 	for _, s := range pr.Servers {
 		go s.Start(ctx, pr.dataChan)
 	}
 
-	if pr.c.GetDisableJitter() {
-		for name := range pr.Probes {
-			go pr.startProbe(name)
-		}
-	} else {
-		pr.startProbesWithJitter()
-	}
+	pr.startProbes()
 }
 
 // Init initialize prober with the given config file.
@@ -312,6 +509,23 @@ func Init(ctx context.Context, cfg *configpb.ProberConfig, l *logger.Logger) (*P
 		l: l,
 	}
 
+	if pr.c.GetMetricsBufferSize() < 1 {
+		return nil, fmt.Errorf("metrics_buffer_size (%d) should be at least 1", pr.c.GetMetricsBufferSize())
+	}
+
+	if w := pr.c.GetProbeStartWindow(); w != "" {
+		var err error
+		if pr.probeStartWindow, err = time.ParseDuration(w); err != nil {
+			return nil, fmt.Errorf("failed to parse probe_start_window (%s): %v", w, err)
+		}
+		if pr.probeStartWindow < 0 {
+			return nil, fmt.Errorf("probe_start_window (%s) cannot be negative", w)
+		}
+	}
+
+	// Global limit on concurrent probe runs, shared by all probes.
+	options.SetMaxConcurrentProbeRuns(int(pr.c.GetMaxConcurrentProbeRuns()))
+
 	// Initialize cloudprober gRPC service if configured.
 	srv := state.DefaultGRPCServer()
 	if srv != nil {
@@ -362,13 +576,26 @@ func Init(ctx context.Context, cfg *configpb.ProberConfig, l *logger.Logger) (*P
 		targets.SetSharedTargets(st.GetName(), tgts)
 	}
 
+	// Initialize namespaces
+	pr.namespaces, err = namespace.Init(pr.c.GetNamespace(), pr.l)
+	if err != nil {
+		return nil, err
+	}
+	pr.probeNamespace = make(map[string]*namespace.Namespace)
+
 	// Initiliaze probes
 	pr.Probes = make(map[string]*probes.ProbeInfo)
 	pr.probeCancelFunc = make(map[string]context.CancelFunc)
+	pr.probeSrcDefs = make(map[string]*probes_configpb.ProbeDef)
+	pr.probeExpiration = make(map[string]time.Time)
+	pr.configProbes = make(map[string]bool)
 	for _, p := range pr.c.GetProbe() {
 		if err := pr.addProbe(p); err != nil {
 			return nil, fmt.Errorf("error while adding probe '%s': %v", p.GetName(), err)
 		}
+		if pr.Probes[p.GetName()] != nil {
+			pr.configProbes[p.GetName()] = true
+		}
 	}
 
 	// Initialize servers
@@ -377,10 +604,20 @@ func Init(ctx context.Context, cfg *configpb.ProberConfig, l *logger.Logger) (*P
 		return nil, fmt.Errorf("error while initializing servers: %v", err)
 	}
 
+	pr.surfacersCtx = ctx
 	pr.Surfacers, err = surfacers.Init(ctx, pr.c.GetSurfacer())
 	if err != nil {
 		return nil, fmt.Errorf("error while initializing surfacers: %v", err)
 	}
 
+	// Make sure that namespaces refer to the valid surfacers.
+	for _, ns := range pr.namespaces {
+		for _, s := range ns.Surfacers() {
+			if !slices.ContainsFunc(pr.Surfacers, func(si *surfacers.SurfacerInfo) bool { return si.Name == s }) {
+				return nil, fmt.Errorf("namespace %s: unknown surfacer: %s", ns.Name, s)
+			}
+		}
+	}
+
 	return pr, nil
 }
@@ -17,10 +17,12 @@ package prober
 import (
 	"context"
 	"fmt"
+	"net/http"
 	"testing"
 	"time"
 
 	configpb "github.com/cloudprober/cloudprober/config/proto"
+	"github.com/cloudprober/cloudprober/internal/namespace"
 	"github.com/cloudprober/cloudprober/logger"
 	"github.com/cloudprober/cloudprober/metrics"
 	"github.com/cloudprober/cloudprober/metrics/singlerun"
@@ -29,6 +31,8 @@ import (
 	"github.com/cloudprober/cloudprober/probes/ping"
 	probes_configpb "github.com/cloudprober/cloudprober/probes/proto"
 	testdatapb "github.com/cloudprober/cloudprober/probes/testdata"
+	"github.com/cloudprober/cloudprober/state"
+	"github.com/cloudprober/cloudprober/surfacers"
 	"github.com/cloudprober/cloudprober/targets/endpoint"
 	targetspb "github.com/cloudprober/cloudprober/targets/proto"
 	"github.com/stretchr/testify/assert"
@@ -195,6 +199,65 @@ func TestStartProbesWithJitter(t *testing.T) {
 	assert.GreaterOrEqual(t, delay, time.Second)
 }
 
+func TestStartProbesWithStartWindow(t *testing.T) {
+	jitteredProbe := testProbeDef("test-probe-3")
+	jitteredProbe.StartJitter = proto.String("10ms")
+
+	pr, cancel := testProber(t, &configpb.ProberConfig{
+		Probe: []*probes_configpb.ProbeDef{
+			testProbeDef("test-probe-1"),
+			testProbeDef("test-probe-2"),
+			jitteredProbe,
+		},
+		ProbeStartWindow: proto.String("200ms"),
+	})
+	defer cancel()
+
+	startTimes := make(map[string]time.Time)
+	for name, p := range pr.Probes {
+		tp := p.Probe.(*testProbe)
+		<-tp.runningStatusCh
+		startTimes[name] = tp.startTime
+	}
+
+	delay := startTimes["test-probe-2"].Sub(startTimes["test-probe-1"])
+	if delay < 0 {
+		delay = -delay
+	}
+	assert.GreaterOrEqual(t, delay, 100*time.Millisecond)
+	assert.Less(t, delay, time.Second)
+}
+
+func TestStartRemovedProbe(t *testing.T) {
+	pr, cancel := testProber(t, &configpb.ProberConfig{})
+	defer cancel()
+
+	// Probe removed before it could be started, e.g. while waiting for its
+	// start jitter.
+	pr.startProbe("test-probe-removed")
+
+	// Probe replaced (e.g. by a config reload) while waiting for its turn in
+	// the start-up stagger.
+	assert.NoError(t, pr.addProbe(testProbeDef("test-probe-replaced")))
+	pr.startDeferredProbe(&probes.ProbeInfo{Name: "test-probe-replaced"})
+
+	pr.mu.RLock()
+	assert.Nil(t, pr.probeCancelFunc["test-probe-removed"])
+	assert.Nil(t, pr.probeCancelFunc["test-probe-replaced"])
+	pr.mu.RUnlock()
+
+	// Current probe should be started.
+	pr.startDeferredProbe(pr.Probes["test-probe-replaced"])
+	verifyProbeRunningStatus(t, pr.Probes["test-probe-replaced"].Probe.(*testProbe), true)
+}
+
+func TestInitWithBadStartWindow(t *testing.T) {
+	_, err := Init(context.Background(), &configpb.ProberConfig{
+		ProbeStartWindow: proto.String("10"),
+	}, nil)
+	assert.Error(t, err)
+}
+
 // Fake ProbeWithRunOnce implementation
 type fakeProbe struct {
 	runOnceCalled bool
@@ -248,3 +311,107 @@ func TestProberRun(t *testing.T) {
 	assert.True(t, pr.Probes["probe1"].Probe.(*fakeProbe).runOnceCalled)
 	assert.Len(t, out["probe2"], 0)
 }
+
+type fakeSurfacer struct {
+	ems []*metrics.EventMetrics
+}
+
+func (fs *fakeSurfacer) Write(_ context.Context, em *metrics.EventMetrics) {
+	fs.ems = append(fs.ems, em)
+}
+
+func TestNamespaces(t *testing.T) {
+	nsProbeDef := func(name, ns string) *probes_configpb.ProbeDef {
+		p := testProbeDef(name)
+		p.Namespace = proto.String(ns)
+		p.AdditionalLabel = []*probes_configpb.AdditionalLabel{
+			{Key: proto.String("env"), Value: proto.String("prod")},
+		}
+		return p
+	}
+
+	state.SetDefaultHTTPServeMux(http.NewServeMux())
+	defer state.SetDefaultHTTPServeMux(nil)
+
+	tests := []struct {
+		name    string
+		probes  []*probes_configpb.ProbeDef
+		wantErr bool
+	}{
+		{
+			name:   "valid",
+			probes: []*probes_configpb.ProbeDef{nsProbeDef("p1", "team-a"), testProbeDef("p2")},
+		},
+		{
+			name:    "unknown_namespace",
+			probes:  []*probes_configpb.ProbeDef{nsProbeDef("p1", "team-b")},
+			wantErr: true,
+		},
+		{
+			name:    "max_probes",
+			probes:  []*probes_configpb.ProbeDef{nsProbeDef("p1", "team-a"), nsProbeDef("p2", "team-a")},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pr, err := Init(context.Background(), &configpb.ProberConfig{
+				Probe: tt.probes,
+				Namespace: []*configpb.Namespace{
+					{
+						Name:        proto.String("team-a"),
+						LabelPrefix: proto.String("team_a_"),
+						MaxProbes:   proto.Int32(1),
+					},
+				},
+			}, nil)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Init() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			assert.Equal(t, "team_a_env", pr.Probes["p1"].Options.AdditionalLabels[0].Key)
+			assert.Equal(t, "team-a", pr.probeNamespace["p1"].Name)
+			assert.Nil(t, pr.probeNamespace["p2"])
+		})
+	}
+}
+
+func TestWriteToSurfacers(t *testing.T) {
+	state.SetDefaultHTTPServeMux(http.NewServeMux())
+	defer state.SetDefaultHTTPServeMux(nil)
+
+	pr, err := Init(context.Background(), &configpb.ProberConfig{
+		Probe: []*probes_configpb.ProbeDef{testProbeDef("p1"), testProbeDef("p2")},
+	}, nil)
+	if err != nil {
+		t.Fatalf("Error initializing prober: %v", err)
+	}
+
+	s1, s2 := &fakeSurfacer{}, &fakeSurfacer{}
+	pr.Surfacers = []*surfacers.SurfacerInfo{
+		{Surfacer: s1, Name: "s1"},
+		{Surfacer: s2, Name: "s2"},
+	}
+
+	// Namespace is set up after Init, as Init verifies that the namespace's
+	// surfacers exist, and we swap in fake surfacers above.
+	ns, err := namespace.New(&configpb.Namespace{
+		Name:     proto.String("team-a"),
+		Surfacer: []string{"s1"},
+	}, nil)
+	if err != nil {
+		t.Fatalf("Error creating namespace: %v", err)
+	}
+	pr.probeNamespace["p1"] = ns
+
+	pr.writeToSurfacers(metrics.NewEventMetrics(time.Now()).AddLabel("probe", "p1"))
+	pr.writeToSurfacers(metrics.NewEventMetrics(time.Now()).AddLabel("probe", "p2"))
+
+	assert.Len(t, s1.ems, 2)
+	assert.Len(t, s2.ems, 1, "namespaced probe's metrics should go only to s1")
+	assert.Equal(t, "team-a", s1.ems[0].Label("namespace"))
+	assert.Equal(t, "p2", s2.ems[0].Label("probe"))
+}
@@ -24,8 +24,12 @@ const (
 )
 
 type AddProbeRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	ProbeConfig   *proto.ProbeDef        `protobuf:"bytes,1,opt,name=probe_config,json=probeConfig" json:"probe_config,omitempty"`
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	ProbeConfig *proto.ProbeDef        `protobuf:"bytes,1,opt,name=probe_config,json=probeConfig" json:"probe_config,omitempty"`
+	// If set, probe is removed automatically after this many seconds. This is
+	// useful for scheduling short-lived probes, without having to remove them
+	// explicitly.
+	TtlSec        *int32 `protobuf:"varint,2,opt,name=ttl_sec,json=ttlSec" json:"ttl_sec,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -67,6 +71,13 @@ func (x *AddProbeRequest) GetProbeConfig() *proto.ProbeDef {
 	return nil
 }
 
+func (x *AddProbeRequest) GetTtlSec() int32 {
+	if x != nil && x.TtlSec != nil {
+		return *x.TtlSec
+	}
+	return 0
+}
+
 type AddProbeResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	unknownFields protoimpl.UnknownFields
@@ -472,9 +483,13 @@ func (*ListProbesRequest) Descriptor() ([]byte, []int) {
 }
 
 type Probe struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Name          *string                `protobuf:"bytes,1,opt,name=name" json:"name,omitempty"`
-	Config        *proto.ProbeDef        `protobuf:"bytes,2,opt,name=config" json:"config,omitempty"`
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	Name   *string                `protobuf:"bytes,1,opt,name=name" json:"name,omitempty"`
+	Config *proto.ProbeDef        `protobuf:"bytes,2,opt,name=config" json:"config,omitempty"`
+	// Expiration time (unix epoch in seconds) for the probes added with a TTL.
+	ExpirationTime *int64 `protobuf:"varint,3,opt,name=expiration_time,json=expirationTime" json:"expiration_time,omitempty"`
+	// Whether the probe has been paused.
+	Paused        *bool `protobuf:"varint,4,opt,name=paused" json:"paused,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -523,6 +538,20 @@ func (x *Probe) GetConfig() *proto.ProbeDef {
 	return nil
 }
 
+func (x *Probe) GetExpirationTime() int64 {
+	if x != nil && x.ExpirationTime != nil {
+		return *x.ExpirationTime
+	}
+	return 0
+}
+
+func (x *Probe) GetPaused() bool {
+	if x != nil && x.Paused != nil {
+		return *x.Paused
+	}
+	return false
+}
+
 type ListProbesResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Probe         []*Probe               `protobuf:"bytes,1,rep,name=probe" json:"probe,omitempty"`
@@ -657,13 +686,340 @@ func (x *SaveProbesConfigResponse) GetFilePath() string {
 	return ""
 }
 
+type GetConfigRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetConfigRequest) Reset() {
+	*x = GetConfigRequest{}
+	mi := &file_github_com_cloudprober_cloudprober_prober_proto_service_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetConfigRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetConfigRequest) ProtoMessage() {}
+
+func (x *GetConfigRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_github_com_cloudprober_cloudprober_prober_proto_service_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetConfigRequest.ProtoReflect.Descriptor instead.
+func (*GetConfigRequest) Descriptor() ([]byte, []int) {
+	return file_github_com_cloudprober_cloudprober_prober_proto_service_proto_rawDescGZIP(), []int{14}
+}
+
+type GetConfigResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Running config in the textproto format.
+	Config        *string `protobuf:"bytes,1,opt,name=config" json:"config,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetConfigResponse) Reset() {
+	*x = GetConfigResponse{}
+	mi := &file_github_com_cloudprober_cloudprober_prober_proto_service_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetConfigResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetConfigResponse) ProtoMessage() {}
+
+func (x *GetConfigResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_github_com_cloudprober_cloudprober_prober_proto_service_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetConfigResponse.ProtoReflect.Descriptor instead.
+func (*GetConfigResponse) Descriptor() ([]byte, []int) {
+	return file_github_com_cloudprober_cloudprober_prober_proto_service_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *GetConfigResponse) GetConfig() string {
+	if x != nil && x.Config != nil {
+		return *x.Config
+	}
+	return ""
+}
+
+type PauseProbeRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ProbeName     *string                `protobuf:"bytes,1,opt,name=probe_name,json=probeName" json:"probe_name,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PauseProbeRequest) Reset() {
+	*x = PauseProbeRequest{}
+	mi := &file_github_com_cloudprober_cloudprober_prober_proto_service_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PauseProbeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PauseProbeRequest) ProtoMessage() {}
+
+func (x *PauseProbeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_github_com_cloudprober_cloudprober_prober_proto_service_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PauseProbeRequest.ProtoReflect.Descriptor instead.
+func (*PauseProbeRequest) Descriptor() ([]byte, []int) {
+	return file_github_com_cloudprober_cloudprober_prober_proto_service_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *PauseProbeRequest) GetProbeName() string {
+	if x != nil && x.ProbeName != nil {
+		return *x.ProbeName
+	}
+	return ""
+}
+
+type PauseProbeResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PauseProbeResponse) Reset() {
+	*x = PauseProbeResponse{}
+	mi := &file_github_com_cloudprober_cloudprober_prober_proto_service_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PauseProbeResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PauseProbeResponse) ProtoMessage() {}
+
+func (x *PauseProbeResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_github_com_cloudprober_cloudprober_prober_proto_service_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PauseProbeResponse.ProtoReflect.Descriptor instead.
+func (*PauseProbeResponse) Descriptor() ([]byte, []int) {
+	return file_github_com_cloudprober_cloudprober_prober_proto_service_proto_rawDescGZIP(), []int{17}
+}
+
+type ResumeProbeRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ProbeName     *string                `protobuf:"bytes,1,opt,name=probe_name,json=probeName" json:"probe_name,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ResumeProbeRequest) Reset() {
+	*x = ResumeProbeRequest{}
+	mi := &file_github_com_cloudprober_cloudprober_prober_proto_service_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ResumeProbeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ResumeProbeRequest) ProtoMessage() {}
+
+func (x *ResumeProbeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_github_com_cloudprober_cloudprober_prober_proto_service_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ResumeProbeRequest.ProtoReflect.Descriptor instead.
+func (*ResumeProbeRequest) Descriptor() ([]byte, []int) {
+	return file_github_com_cloudprober_cloudprober_prober_proto_service_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *ResumeProbeRequest) GetProbeName() string {
+	if x != nil && x.ProbeName != nil {
+		return *x.ProbeName
+	}
+	return ""
+}
+
+type ResumeProbeResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ResumeProbeResponse) Reset() {
+	*x = ResumeProbeResponse{}
+	mi := &file_github_com_cloudprober_cloudprober_prober_proto_service_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ResumeProbeResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ResumeProbeResponse) ProtoMessage() {}
+
+func (x *ResumeProbeResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_github_com_cloudprober_cloudprober_prober_proto_service_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ResumeProbeResponse.ProtoReflect.Descriptor instead.
+func (*ResumeProbeResponse) Descriptor() ([]byte, []int) {
+	return file_github_com_cloudprober_cloudprober_prober_proto_service_proto_rawDescGZIP(), []int{19}
+}
+
+type SetLameDuckRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	LameDuck      *bool                  `protobuf:"varint,1,opt,name=lame_duck,json=lameDuck,def=1" json:"lame_duck,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+// Default values for SetLameDuckRequest fields.
+const (
+	Default_SetLameDuckRequest_LameDuck = bool(true)
+)
+
+func (x *SetLameDuckRequest) Reset() {
+	*x = SetLameDuckRequest{}
+	mi := &file_github_com_cloudprober_cloudprober_prober_proto_service_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SetLameDuckRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetLameDuckRequest) ProtoMessage() {}
+
+func (x *SetLameDuckRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_github_com_cloudprober_cloudprober_prober_proto_service_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetLameDuckRequest.ProtoReflect.Descriptor instead.
+func (*SetLameDuckRequest) Descriptor() ([]byte, []int) {
+	return file_github_com_cloudprober_cloudprober_prober_proto_service_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *SetLameDuckRequest) GetLameDuck() bool {
+	if x != nil && x.LameDuck != nil {
+		return *x.LameDuck
+	}
+	return Default_SetLameDuckRequest_LameDuck
+}
+
+type SetLameDuckResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SetLameDuckResponse) Reset() {
+	*x = SetLameDuckResponse{}
+	mi := &file_github_com_cloudprober_cloudprober_prober_proto_service_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SetLameDuckResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetLameDuckResponse) ProtoMessage() {}
+
+func (x *SetLameDuckResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_github_com_cloudprober_cloudprober_prober_proto_service_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetLameDuckResponse.ProtoReflect.Descriptor instead.
+func (*SetLameDuckResponse) Descriptor() ([]byte, []int) {
+	return file_github_com_cloudprober_cloudprober_prober_proto_service_proto_rawDescGZIP(), []int{21}
+}
+
 var File_github_com_cloudprober_cloudprober_prober_proto_service_proto protoreflect.FileDescriptor
 
 const file_github_com_cloudprober_cloudprober_prober_proto_service_proto_rawDesc = "" +
 	"\n" +
-	"=github.com/cloudprober/cloudprober/prober/proto/service.proto\x12\vcloudprober\x1a<github.com/cloudprober/cloudprober/probes/proto/config.proto\x1aHgithub.com/cloudprober/cloudprober/targets/endpoint/proto/endpoint.proto\"R\n" +
+	"=github.com/cloudprober/cloudprober/prober/proto/service.proto\x12\vcloudprober\x1a<github.com/cloudprober/cloudprober/probes/proto/config.proto\x1aHgithub.com/cloudprober/cloudprober/targets/endpoint/proto/endpoint.proto\"k\n" +
 	"\x0fAddProbeRequest\x12?\n" +
-	"\fprobe_config\x18\x01 \x01(\v2\x1c.cloudprober.probes.ProbeDefR\vprobeConfig\"\x12\n" +
+	"\fprobe_config\x18\x01 \x01(\v2\x1c.cloudprober.probes.ProbeDefR\vprobeConfig\x12\x17\n" +
+	"\attl_sec\x18\x02 \x01(\x05R\x06ttlSec\"\x12\n" +
 	"\x10AddProbeResponse\"3\n" +
 	"\x12RemoveProbeRequest\x12\x1d\n" +
 	"\n" +
@@ -687,23 +1043,44 @@ const file_github_com_cloudprober_cloudprober_prober_proto_service_proto_rawDesc
 	"\fResultsEntry\x12\x10\n" +
 	"\x03key\x18\x01 \x01(\tR\x03key\x12/\n" +
 	"\x05value\x18\x02 \x01(\v2\x19.cloudprober.ProbeResultsR\x05value:\x028\x01\"\x13\n" +
-	"\x11ListProbesRequest\"Q\n" +
+	"\x11ListProbesRequest\"\x92\x01\n" +
 	"\x05Probe\x12\x12\n" +
 	"\x04name\x18\x01 \x01(\tR\x04name\x124\n" +
-	"\x06config\x18\x02 \x01(\v2\x1c.cloudprober.probes.ProbeDefR\x06config\">\n" +
+	"\x06config\x18\x02 \x01(\v2\x1c.cloudprober.probes.ProbeDefR\x06config\x12'\n" +
+	"\x0fexpiration_time\x18\x03 \x01(\x03R\x0eexpirationTime\x12\x16\n" +
+	"\x06paused\x18\x04 \x01(\bR\x06paused\">\n" +
 	"\x12ListProbesResponse\x12(\n" +
 	"\x05probe\x18\x01 \x03(\v2\x12.cloudprober.ProbeR\x05probe\"6\n" +
 	"\x17SaveProbesConfigRequest\x12\x1b\n" +
 	"\tfile_path\x18\x01 \x01(\tR\bfilePath\"7\n" +
 	"\x18SaveProbesConfigResponse\x12\x1b\n" +
-	"\tfile_path\x18\x01 \x01(\tR\bfilePath2\xab\x03\n" +
+	"\tfile_path\x18\x01 \x01(\tR\bfilePath\"\x12\n" +
+	"\x10GetConfigRequest\"+\n" +
+	"\x11GetConfigResponse\x12\x16\n" +
+	"\x06config\x18\x01 \x01(\tR\x06config\"2\n" +
+	"\x11PauseProbeRequest\x12\x1d\n" +
+	"\n" +
+	"probe_name\x18\x01 \x01(\tR\tprobeName\"\x14\n" +
+	"\x12PauseProbeResponse\"3\n" +
+	"\x12ResumeProbeRequest\x12\x1d\n" +
+	"\n" +
+	"probe_name\x18\x01 \x01(\tR\tprobeName\"\x15\n" +
+	"\x13ResumeProbeResponse\"7\n" +
+	"\x12SetLameDuckRequest\x12!\n" +
+	"\tlame_duck\x18\x01 \x01(\b:\x04trueR\blameDuck\"\x15\n" +
+	"\x13SetLameDuckResponse2\xf2\x05\n" +
 	"\vCloudprober\x12I\n" +
 	"\bAddProbe\x12\x1c.cloudprober.AddProbeRequest\x1a\x1d.cloudprober.AddProbeResponse\"\x00\x12R\n" +
 	"\vRemoveProbe\x12\x1f.cloudprober.RemoveProbeRequest\x1a .cloudprober.RemoveProbeResponse\"\x00\x12I\n" +
 	"\bRunProbe\x12\x1c.cloudprober.RunProbeRequest\x1a\x1d.cloudprober.RunProbeResponse\"\x00\x12O\n" +
 	"\n" +
 	"ListProbes\x12\x1e.cloudprober.ListProbesRequest\x1a\x1f.cloudprober.ListProbesResponse\"\x00\x12a\n" +
-	"\x10SaveProbesConfig\x12$.cloudprober.SaveProbesConfigRequest\x1a%.cloudprober.SaveProbesConfigResponse\"\x00B1Z/github.com/cloudprober/cloudprober/prober/proto"
+	"\x10SaveProbesConfig\x12$.cloudprober.SaveProbesConfigRequest\x1a%.cloudprober.SaveProbesConfigResponse\"\x00\x12L\n" +
+	"\tGetConfig\x12\x1d.cloudprober.GetConfigRequest\x1a\x1e.cloudprober.GetConfigResponse\"\x00\x12O\n" +
+	"\n" +
+	"PauseProbe\x12\x1e.cloudprober.PauseProbeRequest\x1a\x1f.cloudprober.PauseProbeResponse\"\x00\x12R\n" +
+	"\vResumeProbe\x12\x1f.cloudprober.ResumeProbeRequest\x1a .cloudprober.ResumeProbeResponse\"\x00\x12R\n" +
+	"\vSetLameDuck\x12\x1f.cloudprober.SetLameDuckRequest\x1a .cloudprober.SetLameDuckResponse\"\x00B1Z/github.com/cloudprober/cloudprober/prober/proto"
 
 var (
 	file_github_com_cloudprober_cloudprober_prober_proto_service_proto_rawDescOnce sync.Once
@@ -717,7 +1094,7 @@ func file_github_com_cloudprober_cloudprober_prober_proto_service_proto_rawDescG
 	return file_github_com_cloudprober_cloudprober_prober_proto_service_proto_rawDescData
 }
 
-var file_github_com_cloudprober_cloudprober_prober_proto_service_proto_msgTypes = make([]protoimpl.MessageInfo, 15)
+var file_github_com_cloudprober_cloudprober_prober_proto_service_proto_msgTypes = make([]protoimpl.MessageInfo, 23)
 var file_github_com_cloudprober_cloudprober_prober_proto_service_proto_goTypes = []any{
 	(*AddProbeRequest)(nil),          // 0: cloudprober.AddProbeRequest
 	(*AddProbeResponse)(nil),         // 1: cloudprober.AddProbeResponse
@@ -733,17 +1110,25 @@ var file_github_com_cloudprober_cloudprober_prober_proto_service_proto_goTypes =
 	(*ListProbesResponse)(nil),       // 11: cloudprober.ListProbesResponse
 	(*SaveProbesConfigRequest)(nil),  // 12: cloudprober.SaveProbesConfigRequest
 	(*SaveProbesConfigResponse)(nil), // 13: cloudprober.SaveProbesConfigResponse
-	nil,                              // 14: cloudprober.RunProbeResponse.ResultsEntry
-	(*proto.ProbeDef)(nil),           // 15: cloudprober.probes.ProbeDef
-	(*proto1.Endpoint)(nil),          // 16: cloudprober.targets.Endpoint
+	(*GetConfigRequest)(nil),         // 14: cloudprober.GetConfigRequest
+	(*GetConfigResponse)(nil),        // 15: cloudprober.GetConfigResponse
+	(*PauseProbeRequest)(nil),        // 16: cloudprober.PauseProbeRequest
+	(*PauseProbeResponse)(nil),       // 17: cloudprober.PauseProbeResponse
+	(*ResumeProbeRequest)(nil),       // 18: cloudprober.ResumeProbeRequest
+	(*ResumeProbeResponse)(nil),      // 19: cloudprober.ResumeProbeResponse
+	(*SetLameDuckRequest)(nil),       // 20: cloudprober.SetLameDuckRequest
+	(*SetLameDuckResponse)(nil),      // 21: cloudprober.SetLameDuckResponse
+	nil,                              // 22: cloudprober.RunProbeResponse.ResultsEntry
+	(*proto.ProbeDef)(nil),           // 23: cloudprober.probes.ProbeDef
+	(*proto1.Endpoint)(nil),          // 24: cloudprober.targets.Endpoint
 }
 var file_github_com_cloudprober_cloudprober_prober_proto_service_proto_depIdxs = []int32{
-	15, // 0: cloudprober.AddProbeRequest.probe_config:type_name -> cloudprober.probes.ProbeDef
-	16, // 1: cloudprober.ProbeRunResult.target:type_name -> cloudprober.targets.Endpoint
+	23, // 0: cloudprober.AddProbeRequest.probe_config:type_name -> cloudprober.probes.ProbeDef
+	24, // 1: cloudprober.ProbeRunResult.target:type_name -> cloudprober.targets.Endpoint
 	5,  // 2: cloudprober.ProbeRunResult.result_metrics:type_name -> cloudprober.ResultMetric
 	6,  // 3: cloudprober.ProbeResults.run_result:type_name -> cloudprober.ProbeRunResult
-	14, // 4: cloudprober.RunProbeResponse.results:type_name -> cloudprober.RunProbeResponse.ResultsEntry
-	15, // 5: cloudprober.Probe.config:type_name -> cloudprober.probes.ProbeDef
+	22, // 4: cloudprober.RunProbeResponse.results:type_name -> cloudprober.RunProbeResponse.ResultsEntry
+	23, // 5: cloudprober.Probe.config:type_name -> cloudprober.probes.ProbeDef
 	10, // 6: cloudprober.ListProbesResponse.probe:type_name -> cloudprober.Probe
 	7,  // 7: cloudprober.RunProbeResponse.ResultsEntry.value:type_name -> cloudprober.ProbeResults
 	0,  // 8: cloudprober.Cloudprober.AddProbe:input_type -> cloudprober.AddProbeRequest
@@ -751,13 +1136,21 @@ var file_github_com_cloudprober_cloudprober_prober_proto_service_proto_depIdxs =
 	4,  // 10: cloudprober.Cloudprober.RunProbe:input_type -> cloudprober.RunProbeRequest
 	9,  // 11: cloudprober.Cloudprober.ListProbes:input_type -> cloudprober.ListProbesRequest
 	12, // 12: cloudprober.Cloudprober.SaveProbesConfig:input_type -> cloudprober.SaveProbesConfigRequest
-	1,  // 13: cloudprober.Cloudprober.AddProbe:output_type -> cloudprober.AddProbeResponse
-	3,  // 14: cloudprober.Cloudprober.RemoveProbe:output_type -> cloudprober.RemoveProbeResponse
-	8,  // 15: cloudprober.Cloudprober.RunProbe:output_type -> cloudprober.RunProbeResponse
-	11, // 16: cloudprober.Cloudprober.ListProbes:output_type -> cloudprober.ListProbesResponse
-	13, // 17: cloudprober.Cloudprober.SaveProbesConfig:output_type -> cloudprober.SaveProbesConfigResponse
-	13, // [13:18] is the sub-list for method output_type
-	8,  // [8:13] is the sub-list for method input_type
+	14, // 13: cloudprober.Cloudprober.GetConfig:input_type -> cloudprober.GetConfigRequest
+	16, // 14: cloudprober.Cloudprober.PauseProbe:input_type -> cloudprober.PauseProbeRequest
+	18, // 15: cloudprober.Cloudprober.ResumeProbe:input_type -> cloudprober.ResumeProbeRequest
+	20, // 16: cloudprober.Cloudprober.SetLameDuck:input_type -> cloudprober.SetLameDuckRequest
+	1,  // 17: cloudprober.Cloudprober.AddProbe:output_type -> cloudprober.AddProbeResponse
+	3,  // 18: cloudprober.Cloudprober.RemoveProbe:output_type -> cloudprober.RemoveProbeResponse
+	8,  // 19: cloudprober.Cloudprober.RunProbe:output_type -> cloudprober.RunProbeResponse
+	11, // 20: cloudprober.Cloudprober.ListProbes:output_type -> cloudprober.ListProbesResponse
+	13, // 21: cloudprober.Cloudprober.SaveProbesConfig:output_type -> cloudprober.SaveProbesConfigResponse
+	15, // 22: cloudprober.Cloudprober.GetConfig:output_type -> cloudprober.GetConfigResponse
+	17, // 23: cloudprober.Cloudprober.PauseProbe:output_type -> cloudprober.PauseProbeResponse
+	19, // 24: cloudprober.Cloudprober.ResumeProbe:output_type -> cloudprober.ResumeProbeResponse
+	21, // 25: cloudprober.Cloudprober.SetLameDuck:output_type -> cloudprober.SetLameDuckResponse
+	17, // [17:26] is the sub-list for method output_type
+	8,  // [8:17] is the sub-list for method input_type
 	8,  // [8:8] is the sub-list for extension type_name
 	8,  // [8:8] is the sub-list for extension extendee
 	0,  // [0:8] is the sub-list for field type_name
@@ -774,7 +1167,7 @@ func file_github_com_cloudprober_cloudprober_prober_proto_service_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_github_com_cloudprober_cloudprober_prober_proto_service_proto_rawDesc), len(file_github_com_cloudprober_cloudprober_prober_proto_service_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   15,
+			NumMessages:   23,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
@@ -24,6 +24,10 @@ const (
 	Cloudprober_RunProbe_FullMethodName         = "/cloudprober.Cloudprober/RunProbe"
 	Cloudprober_ListProbes_FullMethodName       = "/cloudprober.Cloudprober/ListProbes"
 	Cloudprober_SaveProbesConfig_FullMethodName = "/cloudprober.Cloudprober/SaveProbesConfig"
+	Cloudprober_GetConfig_FullMethodName        = "/cloudprober.Cloudprober/GetConfig"
+	Cloudprober_PauseProbe_FullMethodName       = "/cloudprober.Cloudprober/PauseProbe"
+	Cloudprober_ResumeProbe_FullMethodName      = "/cloudprober.Cloudprober/ResumeProbe"
+	Cloudprober_SetLameDuck_FullMethodName      = "/cloudprober.Cloudprober/SetLameDuck"
 )
 
 // CloudproberClient is the client API for Cloudprober service.
@@ -41,6 +45,17 @@ type CloudproberClient interface {
 	// ListProbes lists active probes.
 	ListProbes(ctx context.Context, in *ListProbesRequest, opts ...grpc.CallOption) (*ListProbesResponse, error)
 	SaveProbesConfig(ctx context.Context, in *SaveProbesConfigRequest, opts ...grpc.CallOption) (*SaveProbesConfigResponse, error)
+	// GetConfig returns the running config, after processing templates and
+	// environment variables, with secrets redacted.
+	GetConfig(ctx context.Context, in *GetConfigRequest, opts ...grpc.CallOption) (*GetConfigResponse, error)
+	// PauseProbe pauses a probe. Paused probe stays configured but skips its
+	// runs until it's resumed, e.g. during maintenance windows.
+	PauseProbe(ctx context.Context, in *PauseProbeRequest, opts ...grpc.CallOption) (*PauseProbeResponse, error)
+	// ResumeProbe resumes a paused probe.
+	ResumeProbe(ctx context.Context, in *ResumeProbeRequest, opts ...grpc.CallOption) (*ResumeProbeResponse, error)
+	// SetLameDuck puts the instance in the lameduck mode, or takes it out of it.
+	// No probe runs while the instance is in the lameduck mode.
+	SetLameDuck(ctx context.Context, in *SetLameDuckRequest, opts ...grpc.CallOption) (*SetLameDuckResponse, error)
 }
 
 type cloudproberClient struct {
@@ -101,6 +116,46 @@ func (c *cloudproberClient) SaveProbesConfig(ctx context.Context, in *SaveProbes
 	return out, nil
 }
 
+func (c *cloudproberClient) GetConfig(ctx context.Context, in *GetConfigRequest, opts ...grpc.CallOption) (*GetConfigResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetConfigResponse)
+	err := c.cc.Invoke(ctx, Cloudprober_GetConfig_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cloudproberClient) PauseProbe(ctx context.Context, in *PauseProbeRequest, opts ...grpc.CallOption) (*PauseProbeResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(PauseProbeResponse)
+	err := c.cc.Invoke(ctx, Cloudprober_PauseProbe_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cloudproberClient) ResumeProbe(ctx context.Context, in *ResumeProbeRequest, opts ...grpc.CallOption) (*ResumeProbeResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ResumeProbeResponse)
+	err := c.cc.Invoke(ctx, Cloudprober_ResumeProbe_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cloudproberClient) SetLameDuck(ctx context.Context, in *SetLameDuckRequest, opts ...grpc.CallOption) (*SetLameDuckResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SetLameDuckResponse)
+	err := c.cc.Invoke(ctx, Cloudprober_SetLameDuck_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // CloudproberServer is the server API for Cloudprober service.
 // All implementations must embed UnimplementedCloudproberServer
 // for forward compatibility.
@@ -116,6 +171,17 @@ type CloudproberServer interface {
 	// ListProbes lists active probes.
 	ListProbes(context.Context, *ListProbesRequest) (*ListProbesResponse, error)
 	SaveProbesConfig(context.Context, *SaveProbesConfigRequest) (*SaveProbesConfigResponse, error)
+	// GetConfig returns the running config, after processing templates and
+	// environment variables, with secrets redacted.
+	GetConfig(context.Context, *GetConfigRequest) (*GetConfigResponse, error)
+	// PauseProbe pauses a probe. Paused probe stays configured but skips its
+	// runs until it's resumed, e.g. during maintenance windows.
+	PauseProbe(context.Context, *PauseProbeRequest) (*PauseProbeResponse, error)
+	// ResumeProbe resumes a paused probe.
+	ResumeProbe(context.Context, *ResumeProbeRequest) (*ResumeProbeResponse, error)
+	// SetLameDuck puts the instance in the lameduck mode, or takes it out of it.
+	// No probe runs while the instance is in the lameduck mode.
+	SetLameDuck(context.Context, *SetLameDuckRequest) (*SetLameDuckResponse, error)
 	mustEmbedUnimplementedCloudproberServer()
 }
 
@@ -141,6 +207,18 @@ func (UnimplementedCloudproberServer) ListProbes(context.Context, *ListProbesReq
 func (UnimplementedCloudproberServer) SaveProbesConfig(context.Context, *SaveProbesConfigRequest) (*SaveProbesConfigResponse, error) {
 	return nil, status.Error(codes.Unimplemented, "method SaveProbesConfig not implemented")
 }
+func (UnimplementedCloudproberServer) GetConfig(context.Context, *GetConfigRequest) (*GetConfigResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetConfig not implemented")
+}
+func (UnimplementedCloudproberServer) PauseProbe(context.Context, *PauseProbeRequest) (*PauseProbeResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method PauseProbe not implemented")
+}
+func (UnimplementedCloudproberServer) ResumeProbe(context.Context, *ResumeProbeRequest) (*ResumeProbeResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ResumeProbe not implemented")
+}
+func (UnimplementedCloudproberServer) SetLameDuck(context.Context, *SetLameDuckRequest) (*SetLameDuckResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method SetLameDuck not implemented")
+}
 func (UnimplementedCloudproberServer) mustEmbedUnimplementedCloudproberServer() {}
 func (UnimplementedCloudproberServer) testEmbeddedByValue()                     {}
 
@@ -252,6 +330,78 @@ func _Cloudprober_SaveProbesConfig_Handler(srv interface{}, ctx context.Context,
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Cloudprober_GetConfig_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetConfigRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CloudproberServer).GetConfig(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Cloudprober_GetConfig_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CloudproberServer).GetConfig(ctx, req.(*GetConfigRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Cloudprober_PauseProbe_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PauseProbeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CloudproberServer).PauseProbe(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Cloudprober_PauseProbe_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CloudproberServer).PauseProbe(ctx, req.(*PauseProbeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Cloudprober_ResumeProbe_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ResumeProbeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CloudproberServer).ResumeProbe(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Cloudprober_ResumeProbe_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CloudproberServer).ResumeProbe(ctx, req.(*ResumeProbeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Cloudprober_SetLameDuck_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetLameDuckRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CloudproberServer).SetLameDuck(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Cloudprober_SetLameDuck_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CloudproberServer).SetLameDuck(ctx, req.(*SetLameDuckRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // Cloudprober_ServiceDesc is the grpc.ServiceDesc for Cloudprober service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -279,6 +429,22 @@ var Cloudprober_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "SaveProbesConfig",
 			Handler:    _Cloudprober_SaveProbesConfig_Handler,
 		},
+		{
+			MethodName: "GetConfig",
+			Handler:    _Cloudprober_GetConfig_Handler,
+		},
+		{
+			MethodName: "PauseProbe",
+			Handler:    _Cloudprober_PauseProbe_Handler,
+		},
+		{
+			MethodName: "ResumeProbe",
+			Handler:    _Cloudprober_ResumeProbe_Handler,
+		},
+		{
+			MethodName: "SetLameDuck",
+			Handler:    _Cloudprober_SetLameDuck_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "github.com/cloudprober/cloudprober/prober/proto/service.proto",
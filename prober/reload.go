@@ -0,0 +1,156 @@
+// Copyright 2025 The Cloudprober Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prober
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	configpb "github.com/cloudprober/cloudprober/config/proto"
+	surfacerpb "github.com/cloudprober/cloudprober/internal/surfacers/proto"
+	"github.com/cloudprober/cloudprober/internal/sysvars"
+	"github.com/cloudprober/cloudprober/probes/options"
+	probes_configpb "github.com/cloudprober/cloudprober/probes/proto"
+	"github.com/cloudprober/cloudprober/surfacers"
+	"google.golang.org/protobuf/proto"
+)
+
+// ReloadProbes updates the running probes to match the given config. Probes
+// that are not in the new config anymore are stopped, new probes are
+// started, and probes whose definition changed are restarted. Probes that
+// didn't change keep running undisturbed, with their state intact.
+//
+// Only the probes that were loaded from the config are affected. Probes added
+// through the gRPC API (including the ones with a TTL) are not part of the
+// config, and they keep running.
+func (pr *Prober) ReloadProbes(cfg *configpb.ProberConfig) error {
+	if pr.startCtx == nil {
+		return errors.New("prober not started")
+	}
+
+	// Probes that are not supposed to run on this host are skipped before
+	// checking for duplicates, as a probe name may be defined once per host.
+	var defs []*probes_configpb.ProbeDef
+	newDefs := make(map[string]*probes_configpb.ProbeDef)
+	for _, p := range cfg.GetProbe() {
+		runHere, err := runOnThisHost(p.GetRunOn(), sysvars.GetVar("hostname"))
+		if err != nil {
+			return fmt.Errorf("error while checking run_on for probe '%s': %v", p.GetName(), err)
+		}
+		if !runHere {
+			continue
+		}
+		if newDefs[p.GetName()] != nil {
+			return fmt.Errorf("probe %s is defined more than once", p.GetName())
+		}
+		newDefs[p.GetName()] = p
+		defs = append(defs, p)
+	}
+
+	pr.mu.Lock()
+	// New probes should pick up the new global settings, e.g. rate limits.
+	pr.c = cfg
+	// All probes share the global run slots, update their limit in place.
+	options.SetMaxConcurrentProbeRuns(int(cfg.GetMaxConcurrentProbeRuns()))
+
+	// Forget the paused state of the config probes that are not in the config
+	// anymore.
+	for name := range pr.pausedProbes {
+		if newDefs[name] == nil && (pr.Probes[name] == nil || pr.configProbes[name]) {
+			delete(pr.pausedProbes, name)
+		}
+	}
+
+	var toRemove []string
+	for name := range pr.configProbes {
+		if newDef := newDefs[name]; newDef == nil || !proto.Equal(newDef, pr.probeSrcDefs[name]) {
+			toRemove = append(toRemove, name)
+		}
+	}
+
+	var errs error
+	var toAdd []*probes_configpb.ProbeDef
+	for _, p := range defs {
+		name := p.GetName()
+		if pr.Probes[name] != nil && !pr.configProbes[name] {
+			errs = errors.Join(errs, fmt.Errorf("probe %s is already defined through the gRPC API", name))
+			continue
+		}
+		if pr.Probes[name] == nil || !proto.Equal(p, pr.probeSrcDefs[name]) {
+			toAdd = append(toAdd, p)
+		}
+	}
+	pr.mu.Unlock()
+
+	for _, name := range toRemove {
+		pr.l.Infof("Config reload: stopping probe: %s", name)
+		if err := pr.removeProbe(name); err != nil {
+			pr.l.Warningf("Config reload: error removing probe %s: %v", name, err)
+		}
+	}
+
+	for _, p := range toAdd {
+		if err := pr.addProbe(p); err != nil {
+			errs = errors.Join(errs, fmt.Errorf("error while adding probe '%s': %v", p.GetName(), err))
+			continue
+		}
+
+		pr.mu.Lock()
+		pr.configProbes[p.GetName()] = true
+		pr.mu.Unlock()
+
+		pr.l.Infof("Config reload: starting probe: %s", p.GetName())
+		pr.startProbe(p.GetName())
+	}
+
+	return errs
+}
+
+// ReloadSurfacers updates the running surfacers to match the given surfacer
+// definitions. Unchanged surfacers keep running, removed and changed
+// surfacers are drained and closed, and new surfacers are initialized and
+// added to the fan-out. Probes are not affected.
+func (pr *Prober) ReloadSurfacers(sDefs []*surfacerpb.SurfacerDef) error {
+	if pr.surfacersCtx == nil {
+		return errors.New("prober not initialized")
+	}
+
+	pr.surfacersMu.RLock()
+	current := pr.Surfacers
+	pr.surfacersMu.RUnlock()
+
+	setSurfacers := func(sis []*surfacers.SurfacerInfo) {
+		pr.surfacersMu.Lock()
+		defer pr.surfacersMu.Unlock()
+		pr.Surfacers = sis
+	}
+
+	_, err := surfacers.Reload(pr.surfacersCtx, current, sDefs, setSurfacers, pr.l)
+
+	// Namespaces are not reloaded, warn if they refer to the surfacers that
+	// don't exist anymore.
+	pr.surfacersMu.RLock()
+	defer pr.surfacersMu.RUnlock()
+	for _, ns := range pr.namespaces {
+		for _, s := range ns.Surfacers() {
+			if !slices.ContainsFunc(pr.Surfacers, func(si *surfacers.SurfacerInfo) bool { return si.Name == s }) {
+				pr.l.Warningf("Config reload: namespace %s refers to an unknown surfacer: %s", ns.Name, s)
+			}
+		}
+	}
+
+	return err
+}
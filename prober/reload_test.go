@@ -0,0 +1,122 @@
+// Copyright 2025 The Cloudprober Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prober
+
+import (
+	"context"
+	"testing"
+
+	configpb "github.com/cloudprober/cloudprober/config/proto"
+	pb "github.com/cloudprober/cloudprober/prober/proto"
+	probes_configpb "github.com/cloudprober/cloudprober/probes/proto"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestReloadProbes(t *testing.T) {
+	pr, cancel := testProber(t, &configpb.ProberConfig{
+		Probe: []*probes_configpb.ProbeDef{
+			testProbeDef("unchanged"),
+			testProbeDef("changed"),
+			testProbeDef("removed"),
+		},
+	})
+	defer cancel()
+
+	oldProbes := make(map[string]*testProbe)
+	for name, p := range pr.Probes {
+		oldProbes[name] = p.Probe.(*testProbe)
+		verifyProbeRunningStatus(t, oldProbes[name], true)
+	}
+
+	changedDef := testProbeDef("changed")
+	changedDef.Interval = proto.String("5s")
+
+	err := pr.ReloadProbes(&configpb.ProberConfig{
+		Probe: []*probes_configpb.ProbeDef{
+			testProbeDef("unchanged"),
+			changedDef,
+			testProbeDef("added"),
+		},
+	})
+	assert.NoError(t, err)
+
+	assert.ElementsMatch(t, []string{"unchanged", "changed", "added"}, func() []string {
+		var names []string
+		for name := range pr.Probes {
+			names = append(names, name)
+		}
+		return names
+	}())
+
+	// Unchanged probe should keep running undisturbed.
+	assert.Same(t, oldProbes["unchanged"], pr.Probes["unchanged"].Probe)
+
+	// Removed and changed probes should be stopped.
+	verifyProbeRunningStatus(t, oldProbes["removed"], false)
+	verifyProbeRunningStatus(t, oldProbes["changed"], false)
+
+	// Changed and added probes should be started.
+	assert.NotSame(t, oldProbes["changed"], pr.Probes["changed"].Probe)
+	verifyProbeRunningStatus(t, pr.Probes["changed"].Probe.(*testProbe), true)
+	verifyProbeRunningStatus(t, pr.Probes["added"].Probe.(*testProbe), true)
+
+	// Duplicate probe names should be rejected without touching the probes.
+	err = pr.ReloadProbes(&configpb.ProberConfig{
+		Probe: []*probes_configpb.ProbeDef{testProbeDef("p"), testProbeDef("p")},
+	})
+	assert.Error(t, err)
+	assert.Len(t, pr.Probes, 3)
+}
+
+func TestReloadProbesKeepsGRPCProbes(t *testing.T) {
+	pr, cancel := testProber(t, &configpb.ProberConfig{
+		Probe: []*probes_configpb.ProbeDef{testProbeDef("config-probe")},
+	})
+	defer cancel()
+
+	_, err := pr.AddProbe(context.Background(), &pb.AddProbeRequest{ProbeConfig: testProbeDef("grpc-probe")})
+	assert.NoError(t, err)
+	grpcProbe := pr.Probes["grpc-probe"].Probe.(*testProbe)
+
+	// Same probe name defined once per host: only the copy that runs on this
+	// host counts.
+	otherHostDef := testProbeDef("per-host")
+	otherHostDef.RunOn = proto.String("^no-such-host$")
+
+	err = pr.ReloadProbes(&configpb.ProberConfig{
+		Probe: []*probes_configpb.ProbeDef{otherHostDef, testProbeDef("per-host")},
+	})
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []string{"grpc-probe", "per-host"}, func() []string {
+		var names []string
+		for name := range pr.Probes {
+			names = append(names, name)
+		}
+		return names
+	}())
+
+	// Probe added through the gRPC API should keep running.
+	assert.Same(t, grpcProbe, pr.Probes["grpc-probe"].Probe)
+	verifyProbeRunningStatus(t, grpcProbe, true)
+
+	// Config probe can't take over the gRPC probe's name.
+	err = pr.ReloadProbes(&configpb.ProberConfig{
+		Probe: []*probes_configpb.ProbeDef{testProbeDef("grpc-probe")},
+	})
+	assert.Error(t, err)
+	assert.Same(t, grpcProbe, pr.Probes["grpc-probe"].Probe)
+	assert.Nil(t, pr.Probes["per-host"])
+}
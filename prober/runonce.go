@@ -0,0 +1,107 @@
+// Copyright 2025 The Cloudprober Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prober
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/cloudprober/cloudprober/metrics"
+	"github.com/cloudprober/cloudprober/metrics/singlerun"
+	"github.com/cloudprober/cloudprober/probes"
+)
+
+// How often to check if surfacers have processed the exported results.
+var surfacersDrainCheckInterval = 100 * time.Millisecond
+
+// runResultEventMetrics converts the results of a single probe run to
+// EventMetrics, labeled the same way as the metrics from regular probe runs.
+// Metrics reported by the probe itself are used if available, otherwise
+// "total", "success" and latency metrics are derived from the result.
+func runResultEventMetrics(pi *probes.ProbeInfo, prr *singlerun.ProbeRunResult, ts time.Time) []*metrics.EventMetrics {
+	ems := prr.Metrics
+
+	if len(ems) == 0 {
+		var success int64
+		if prr.Success {
+			success = 1
+		}
+		em := metrics.NewEventMetrics(ts).
+			AddMetric("total", metrics.NewInt(1)).
+			AddMetric("success", metrics.NewInt(success))
+		if latencyUnit := pi.Options.LatencyUnit; latencyUnit > 0 && prr.Success {
+			em.AddMetric(pi.Options.LatencyMetricName, metrics.NewFloat(float64(prr.Latency)/float64(latencyUnit)))
+		}
+		ems = []*metrics.EventMetrics{em}
+	}
+
+	for _, em := range ems {
+		em.LatencyUnit = pi.Options.LatencyUnit
+		if em.Label("ptype") == "" {
+			em.AddLabel("ptype", strings.ToLower(pi.Type))
+		}
+		em.AddLabel("probe", pi.Name).AddLabel("dst", prr.Target.Dst())
+		for _, al := range pi.Options.AdditionalLabels {
+			em.AddLabel(al.KeyValueForTarget(prr.Target))
+		}
+	}
+	return ems
+}
+
+// ExportRunResults writes the results of a single run (see Run) to the
+// surfacers, and waits for the surfacers to pick them up, or for the context
+// to be canceled. It's used to export results in the run-once mode, where
+// probes are not started.
+func (pr *Prober) ExportRunResults(ctx context.Context, results map[string][]*singlerun.ProbeRunResult) {
+	ts := time.Now()
+	for name, prrs := range results {
+		pr.mu.RLock()
+		pi := pr.Probes[name]
+		pr.mu.RUnlock()
+		if pi == nil {
+			continue
+		}
+
+		for _, prr := range prrs {
+			for _, em := range runResultEventMetrics(pi, prr, ts) {
+				pr.writeToSurfacers(em)
+			}
+		}
+	}
+
+	ticker := time.NewTicker(surfacersDrainCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		pending := 0
+		pr.surfacersMu.RLock()
+		for _, si := range pr.Surfacers {
+			pending += si.QueueDepth()
+		}
+		pr.surfacersMu.RUnlock()
+
+		if pending == 0 {
+			return
+		}
+
+		select {
+		case <-ctx.Done():
+			pr.l.Warningf("Context canceled with %d EventMetrics yet to be processed by the surfacers", pending)
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,72 @@
+// Copyright 2025 The Cloudprober Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prober
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/cloudprober/cloudprober/logger"
+	"github.com/cloudprober/cloudprober/metrics"
+	"github.com/cloudprober/cloudprober/metrics/singlerun"
+	"github.com/cloudprober/cloudprober/probes"
+	"github.com/cloudprober/cloudprober/probes/options"
+	"github.com/cloudprober/cloudprober/surfacers"
+	"github.com/cloudprober/cloudprober/targets/endpoint"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExportRunResults(t *testing.T) {
+	pi := &probes.ProbeInfo{
+		Name: "p1",
+		Type: "HTTP",
+		Options: &options.Options{
+			LatencyUnit:       time.Millisecond,
+			LatencyMetricName: "latency",
+		},
+	}
+	fs := &fakeSurfacer{}
+	pr := &Prober{
+		Probes:    map[string]*probes.ProbeInfo{"p1": pi},
+		Surfacers: []*surfacers.SurfacerInfo{{Surfacer: fs, Name: "s1"}},
+		l:         logger.New(),
+	}
+
+	probeEM := metrics.NewEventMetrics(time.Now()).AddMetric("total", metrics.NewInt(1))
+	pr.ExportRunResults(context.Background(), map[string][]*singlerun.ProbeRunResult{
+		"p1": {
+			{Target: endpoint.Endpoint{Name: "t1"}, Success: true, Latency: 5 * time.Millisecond},
+			{Target: endpoint.Endpoint{Name: "t2"}, Error: errors.New("failed")},
+			{Target: endpoint.Endpoint{Name: "t3"}, Success: true, Metrics: []*metrics.EventMetrics{probeEM}},
+		},
+		"unknown": {{Target: endpoint.Endpoint{Name: "t1"}}},
+	})
+
+	assert.Len(t, fs.ems, 3)
+	for i, target := range []string{"t1", "t2", "t3"} {
+		em := fs.ems[i]
+		assert.Equal(t, "p1", em.Label("probe"))
+		assert.Equal(t, "http", em.Label("ptype"))
+		assert.Equal(t, target, em.Label("dst"))
+	}
+
+	assert.Equal(t, "1", fs.ems[0].Metric("success").String())
+	assert.Equal(t, "5.000", fs.ems[0].Metric("latency").String())
+	assert.Equal(t, "0", fs.ems[1].Metric("success").String())
+	assert.Nil(t, fs.ems[1].Metric("latency"))
+	assert.Same(t, probeEM, fs.ems[2], "probe's own metrics should be used")
+}
@@ -0,0 +1,152 @@
+// Copyright 2025 The Cloudprober Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prober
+
+import (
+	"context"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/cloudprober/cloudprober/metrics"
+	"github.com/cloudprober/cloudprober/probes/options"
+	"github.com/cloudprober/cloudprober/surfacers"
+)
+
+// selfMetricsProbeName is the probe name (and ptype) used for cloudprober's
+// own metrics. User probes can't use this name.
+const selfMetricsProbeName = "cloudprober"
+
+func newSelfEM(ts time.Time, kind metrics.Kind) *metrics.EventMetrics {
+	em := metrics.NewEventMetrics(ts).
+		AddLabel("ptype", selfMetricsProbeName).
+		AddLabel("probe", selfMetricsProbeName)
+	em.Kind = kind
+	return em
+}
+
+// selfMetrics returns cloudprober's own metrics at the given time:
+//   - process: goroutines, heap in use.
+//   - metrics buffer (the channel between probes and surfacers): depth,
+//     capacity, writes that had to wait and writes that were dropped.
+//   - surfacers: write queue depth, dropped EventMetrics, number of writes
+//     and cumulative write latency, labeled by surfacer.
+//   - probe runs queue: runs that had to wait for the rate limits or the
+//     concurrency limit, total time spent waiting, and runs in progress if
+//     the concurrency limit is set.
+//   - probes: number of runs and cumulative scheduling drift, i.e. how late
+//     the runs started compared to their schedule, labeled by probe_name.
+func (pr *Prober) selfMetrics(ts time.Time) []*metrics.EventMetrics {
+	var ms runtime.MemStats
+	runtime.ReadMemStats(&ms)
+
+	gaugeEM := newSelfEM(ts, metrics.GAUGE).
+		AddMetric("goroutines", metrics.NewInt(int64(runtime.NumGoroutine()))).
+		AddMetric("heap_inuse_bytes", metrics.NewInt(int64(ms.HeapInuse))).
+		AddMetric("metrics_buffer_depth", metrics.NewInt(int64(len(pr.dataChan)))).
+		AddMetric("metrics_buffer_capacity", metrics.NewInt(int64(cap(pr.dataChan))))
+
+	if inUse, total := options.RunSlotsInUse(); total > 0 {
+		gaugeEM.AddMetric("runs_in_flight", metrics.NewInt(int64(inUse))).
+			AddMetric("max_concurrent_runs", metrics.NewInt(int64(total)))
+	}
+
+	blocked, dropped := metrics.SendStats()
+	queued, queueDelay := options.QueueStats()
+	counterEM := newSelfEM(ts, metrics.CUMULATIVE).
+		AddMetric("metrics_buffer_blocked_writes", metrics.NewInt(blocked)).
+		AddMetric("metrics_buffer_dropped", metrics.NewInt(dropped)).
+		AddMetric("queued_runs", metrics.NewInt(queued)).
+		AddMetric("queue_delay_usec", metrics.NewInt(queueDelay.Microseconds()))
+
+	ems := []*metrics.EventMetrics{gaugeEM, counterEM}
+
+	pr.surfacersMu.RLock()
+	sis := pr.Surfacers
+	pr.surfacersMu.RUnlock()
+
+	for _, si := range sis {
+		writes, latency := si.WriteLatency()
+		ems = append(ems,
+			newSelfEM(ts, metrics.GAUGE).
+				AddMetric("surfacer_queue_depth", metrics.NewInt(int64(si.QueueDepth()))).
+				AddLabel("surfacer", surfacerName(si)),
+			newSelfEM(ts, metrics.CUMULATIVE).
+				AddMetric("surfacer_dropped", metrics.NewInt(si.Dropped())).
+				AddMetric("surfacer_writes", metrics.NewInt(writes)).
+				AddMetric("surfacer_write_latency_usec", metrics.NewInt(latency.Microseconds())).
+				AddLabel("surfacer", surfacerName(si)))
+	}
+
+	pr.mu.RLock()
+	defer pr.mu.RUnlock()
+	for name, p := range pr.Probes {
+		runs, drift := p.Options.SchedulingDrift()
+		if runs == 0 {
+			continue
+		}
+		ems = append(ems, newSelfEM(ts, metrics.CUMULATIVE).
+			AddMetric("scheduled_runs", metrics.NewInt(runs)).
+			AddMetric("scheduling_drift_usec", metrics.NewInt(drift.Microseconds())).
+			AddLabel("probe_name", name))
+	}
+
+	return ems
+}
+
+func surfacerName(si *surfacers.SurfacerInfo) string {
+	if si.Name != "" {
+		return si.Name
+	}
+	return strings.ToLower(si.Type)
+}
+
+// exportSelfMetrics exports cloudprober's own metrics (see selfMetrics) at
+// the given interval, under the probe name "cloudprober". It also logs a
+// warning if surfacers dropped EventMetrics since the last export.
+func (pr *Prober) exportSelfMetrics(ctx context.Context, interval time.Duration) {
+	lastDropped := make(map[*surfacers.SurfacerInfo]int64)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		var ts time.Time
+		select {
+		case <-ctx.Done():
+			return
+		case ts = <-ticker.C:
+		}
+
+		pr.surfacersMu.RLock()
+		sis := pr.Surfacers
+		pr.surfacersMu.RUnlock()
+
+		// Rebuilt every time to forget the surfacers removed on config reload.
+		dropCounts := make(map[*surfacers.SurfacerInfo]int64)
+		for _, si := range sis {
+			dropped := si.Dropped()
+			if dropped > lastDropped[si] {
+				pr.l.Warningf("Surfacer %s dropped %d EventMetrics as its write queue was full", surfacerName(si), dropped-lastDropped[si])
+			}
+			dropCounts[si] = dropped
+		}
+		lastDropped = dropCounts
+
+		for _, em := range pr.selfMetrics(ts) {
+			pr.dataChan <- em
+		}
+	}
+}
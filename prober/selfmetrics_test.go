@@ -0,0 +1,90 @@
+// Copyright 2025 The Cloudprober Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prober
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cloudprober/cloudprober/metrics"
+	"github.com/cloudprober/cloudprober/probes"
+	"github.com/cloudprober/cloudprober/probes/options"
+	configpb "github.com/cloudprober/cloudprober/probes/proto"
+	"github.com/cloudprober/cloudprober/surfacers"
+	targetspb "github.com/cloudprober/cloudprober/targets/proto"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestSelfMetrics(t *testing.T) {
+	opts, err := options.BuildProbeOptions(&configpb.ProbeDef{
+		Name: proto.String("p1"),
+		Type: configpb.ProbeDef_HTTP.Enum(),
+		Targets: &targetspb.TargetsDef{
+			Type: &targetspb.TargetsDef_HostNames{HostNames: "testHost"},
+		},
+	}, nil, nil, nil)
+	assert.NoError(t, err)
+	opts.RecordSchedulingDrift(3 * time.Millisecond)
+	opts.RecordSchedulingDrift(time.Millisecond)
+
+	pr := &Prober{
+		Probes: map[string]*probes.ProbeInfo{
+			"p1": {Name: "p1", Options: opts},
+			"p2": {Name: "p2", Options: &options.Options{}},
+		},
+		Surfacers: []*surfacers.SurfacerInfo{{Type: "FILE"}},
+		dataChan:  make(chan *metrics.EventMetrics, 10),
+	}
+	pr.dataChan <- metrics.NewEventMetrics(time.Now())
+
+	ems := pr.selfMetrics(time.Now())
+	for _, em := range ems {
+		assert.Equal(t, "cloudprober", em.Label("probe"))
+		assert.Equal(t, "cloudprober", em.Label("ptype"))
+	}
+
+	// process, metrics buffer (2), surfacer (2), probe p1 (no runs for p2).
+	assert.Len(t, ems, 5)
+
+	gaugeEM := ems[0]
+	assert.Equal(t, metrics.Kind(metrics.GAUGE), gaugeEM.Kind)
+	assert.Greater(t, gaugeEM.Metric("goroutines").(*metrics.Int).Int64(), int64(0))
+	assert.Greater(t, gaugeEM.Metric("heap_inuse_bytes").(*metrics.Int).Int64(), int64(0))
+	assert.Equal(t, int64(1), gaugeEM.Metric("metrics_buffer_depth").(*metrics.Int).Int64())
+	assert.Equal(t, int64(10), gaugeEM.Metric("metrics_buffer_capacity").(*metrics.Int).Int64())
+
+	assert.NotNil(t, ems[1].Metric("queued_runs"))
+	assert.NotNil(t, ems[1].Metric("queue_delay_usec"))
+
+	assert.Equal(t, "file", ems[2].Label("surfacer"))
+	assert.NotNil(t, ems[2].Metric("surfacer_queue_depth"))
+	assert.Equal(t, metrics.Kind(metrics.CUMULATIVE), ems[3].Kind)
+	assert.NotNil(t, ems[3].Metric("surfacer_write_latency_usec"))
+
+	probeEM := ems[4]
+	assert.Equal(t, "p1", probeEM.Label("probe_name"))
+	assert.Equal(t, int64(2), probeEM.Metric("scheduled_runs").(*metrics.Int).Int64())
+	assert.Equal(t, int64(4000), probeEM.Metric("scheduling_drift_usec").(*metrics.Int).Int64())
+}
+
+func TestAddProbeReservedName(t *testing.T) {
+	pr := &Prober{Probes: make(map[string]*probes.ProbeInfo)}
+	err := pr.addProbe(&configpb.ProbeDef{
+		Name: proto.String(selfMetricsProbeName),
+		Type: configpb.ProbeDef_HTTP.Enum(),
+	})
+	assert.ErrorContains(t, err, "reserved")
+}
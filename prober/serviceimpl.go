@@ -20,10 +20,13 @@ import (
 	"fmt"
 	"os"
 	"sort"
+	"time"
 
+	"github.com/cloudprober/cloudprober/config"
 	configpb "github.com/cloudprober/cloudprober/config/proto"
 	"github.com/cloudprober/cloudprober/metrics/singlerun"
 	pb "github.com/cloudprober/cloudprober/prober/proto"
+	"github.com/cloudprober/cloudprober/probes"
 	probes_configpb "github.com/cloudprober/cloudprober/probes/proto"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
@@ -75,6 +78,9 @@ func (pr *Prober) AddProbe(ctx context.Context, req *pb.AddProbeRequest) (*pb.Ad
 	if p == nil {
 		return &pb.AddProbeResponse{}, status.Errorf(codes.InvalidArgument, "probe config cannot be nil")
 	}
+	if req.GetTtlSec() < 0 {
+		return &pb.AddProbeResponse{}, status.Errorf(codes.InvalidArgument, "ttl_sec (%d) cannot be negative", req.GetTtlSec())
+	}
 
 	if err := pr.addProbe(p); err != nil {
 		return &pb.AddProbeResponse{}, err
@@ -84,8 +90,22 @@ func (pr *Prober) AddProbe(ctx context.Context, req *pb.AddProbeRequest) (*pb.Ad
 	if pr.startCtx == nil {
 		return &pb.AddProbeResponse{}, status.Errorf(codes.FailedPrecondition, "prober not started")
 	}
+
+	pr.mu.RLock()
+	probeInfo := pr.Probes[p.GetName()]
+	pr.mu.RUnlock()
+
+	// addProbe skips the probes that are not supposed to run on this host.
+	if probeInfo == nil {
+		pr.l.Infof("Probe %s is not configured to run on this host, not starting it", p.GetName())
+		return &pb.AddProbeResponse{}, nil
+	}
 	pr.startProbe(p.GetName())
 
+	if ttl := time.Duration(req.GetTtlSec()) * time.Second; ttl > 0 {
+		pr.expireProbe(p.GetName(), probeInfo, ttl)
+	}
+
 	if *probesConfigSavePath != "" {
 		pr.saveProbesConfigToFile(*probesConfigSavePath)
 	}
@@ -104,6 +124,35 @@ func (pr *Prober) RunProbe(ctx context.Context, req *pb.RunProbeRequest) (*pb.Ru
 	}, nil
 }
 
+// expireProbe removes the probe after the given TTL, unless the probe has
+// been removed (or replaced) in the meantime.
+func (pr *Prober) expireProbe(name string, probeInfo *probes.ProbeInfo, ttl time.Duration) {
+	pr.mu.Lock()
+	pr.probeExpiration[name] = time.Now().Add(ttl)
+	pr.mu.Unlock()
+
+	go func() {
+		select {
+		case <-pr.startCtx.Done():
+			return
+		case <-time.After(ttl):
+		}
+
+		pr.mu.Lock()
+		if pr.Probes[name] != probeInfo {
+			pr.mu.Unlock()
+			return
+		}
+		pr.l.Infof("Probe %s expired after %v, removing it", name, ttl)
+		pr.deleteProbe(name)
+		pr.mu.Unlock()
+
+		if *probesConfigSavePath != "" {
+			pr.saveProbesConfigToFile(*probesConfigSavePath)
+		}
+	}()
+}
+
 // removeProbe removes the probe with the given name from the prober's internal
 // database and cancels the probe's context.
 func (pr *Prober) removeProbe(name string) error {
@@ -114,11 +163,29 @@ func (pr *Prober) removeProbe(name string) error {
 		return fmt.Errorf("probe %s not found", name)
 	}
 
-	pr.probeCancelFunc[name]()
-	delete(pr.Probes, name)
+	pr.deleteProbe(name)
 	return nil
 }
 
+// deleteProbe cancels the probe's context and deletes it from the prober's
+// internal database. It should be called with pr.mu held.
+func (pr *Prober) deleteProbe(name string) {
+	// Probe may not have been started yet, e.g. if it's still waiting for its
+	// turn in the start-up stagger.
+	if cancel, ok := pr.probeCancelFunc[name]; ok && cancel != nil {
+		cancel()
+	}
+	delete(pr.probeCancelFunc, name)
+	delete(pr.Probes, name)
+	delete(pr.configProbes, name)
+	delete(pr.probeSrcDefs, name)
+	delete(pr.probeExpiration, name)
+
+	pr.probeNSMu.Lock()
+	delete(pr.probeNamespace, name)
+	pr.probeNSMu.Unlock()
+}
+
 // RemoveProbe gRPC method cancels the given probe and removes its from the
 // prober's internal database.
 func (pr *Prober) RemoveProbe(ctx context.Context, req *pb.RemoveProbeRequest) (*pb.RemoveProbeResponse, error) {
@@ -133,6 +200,11 @@ func (pr *Prober) RemoveProbe(ctx context.Context, req *pb.RemoveProbeRequest) (
 		return &pb.RemoveProbeResponse{}, status.Errorf(codes.NotFound, "%v", err)
 	}
 
+	// Probe is gone for good, forget its paused state.
+	pr.mu.Lock()
+	delete(pr.pausedProbes, name)
+	pr.mu.Unlock()
+
 	if *probesConfigSavePath != "" {
 		pr.saveProbesConfigToFile(*probesConfigSavePath)
 	}
@@ -149,10 +221,17 @@ func (pr *Prober) ListProbes(ctx context.Context, req *pb.ListProbesRequest) (*p
 	resp := &pb.ListProbesResponse{}
 
 	for name, p := range pr.Probes {
-		resp.Probe = append(resp.Probe, &pb.Probe{
+		probe := &pb.Probe{
 			Name:   proto.String(name),
 			Config: proto.Clone(p.ProbeDef).(*probes_configpb.ProbeDef),
-		})
+		}
+		if exp, ok := pr.probeExpiration[name]; ok {
+			probe.ExpirationTime = proto.Int64(exp.Unix())
+		}
+		if p.Options.IsPaused() {
+			probe.Paused = proto.Bool(true)
+		}
+		resp.Probe = append(resp.Probe, probe)
 	}
 
 	return resp, nil
@@ -175,3 +254,48 @@ func (pr *Prober) SaveProbesConfig(ctx context.Context, req *pb.SaveProbesConfig
 		FilePath: &filePath,
 	}, nil
 }
+
+// GetConfig gRPC method returns the running config, with secrets redacted.
+func (pr *Prober) GetConfig(ctx context.Context, req *pb.GetConfigRequest) (*pb.GetConfigResponse, error) {
+	return &pb.GetConfigResponse{
+		Config: proto.String(prototext.Format(config.RedactSecrets(pr.config()))),
+	}, nil
+}
+
+// PauseProbe gRPC method pauses the given probe.
+func (pr *Prober) PauseProbe(ctx context.Context, req *pb.PauseProbeRequest) (*pb.PauseProbeResponse, error) {
+	if err := pr.checkAdminAuth(ctx); err != nil {
+		return &pb.PauseProbeResponse{}, err
+	}
+	if req.GetProbeName() == "" {
+		return &pb.PauseProbeResponse{}, status.Errorf(codes.InvalidArgument, "probe name cannot be empty")
+	}
+	if err := pr.SetProbePaused(req.GetProbeName(), true); err != nil {
+		return &pb.PauseProbeResponse{}, status.Errorf(codes.NotFound, "%v", err)
+	}
+	return &pb.PauseProbeResponse{}, nil
+}
+
+// ResumeProbe gRPC method resumes the given probe.
+func (pr *Prober) ResumeProbe(ctx context.Context, req *pb.ResumeProbeRequest) (*pb.ResumeProbeResponse, error) {
+	if err := pr.checkAdminAuth(ctx); err != nil {
+		return &pb.ResumeProbeResponse{}, err
+	}
+	if req.GetProbeName() == "" {
+		return &pb.ResumeProbeResponse{}, status.Errorf(codes.InvalidArgument, "probe name cannot be empty")
+	}
+	if err := pr.SetProbePaused(req.GetProbeName(), false); err != nil {
+		return &pb.ResumeProbeResponse{}, status.Errorf(codes.NotFound, "%v", err)
+	}
+	return &pb.ResumeProbeResponse{}, nil
+}
+
+// SetLameDuck gRPC method puts the instance in the lameduck mode, or takes it
+// out of it.
+func (pr *Prober) SetLameDuck(ctx context.Context, req *pb.SetLameDuckRequest) (*pb.SetLameDuckResponse, error) {
+	if err := pr.checkAdminAuth(ctx); err != nil {
+		return &pb.SetLameDuckResponse{}, err
+	}
+	pr.SetLameDuckMode(req.GetLameDuck())
+	return &pb.SetLameDuckResponse{}, nil
+}
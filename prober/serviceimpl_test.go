@@ -25,6 +25,8 @@ import (
 	"time"
 
 	configpb "github.com/cloudprober/cloudprober/config/proto"
+	datadogpb "github.com/cloudprober/cloudprober/internal/surfacers/datadog/proto"
+	surfacerpb "github.com/cloudprober/cloudprober/internal/surfacers/proto"
 	"github.com/stretchr/testify/assert"
 
 	pb "github.com/cloudprober/cloudprober/prober/proto"
@@ -120,6 +122,43 @@ func TestAddProbe(t *testing.T) {
 	}
 }
 
+func TestAddProbeWithTTL(t *testing.T) {
+	pr, cancel := testProber(t, &configpb.ProberConfig{})
+	defer cancel()
+
+	_, err := pr.AddProbe(context.Background(), &pb.AddProbeRequest{ProbeConfig: testProbeDef("p"), TtlSec: proto.Int32(-1)})
+	assert.Error(t, err, "negative TTL should result in error")
+
+	_, err = pr.AddProbe(context.Background(), &pb.AddProbeRequest{ProbeConfig: testProbeDef("p"), TtlSec: proto.Int32(1)})
+	assert.NoError(t, err)
+	p := pr.Probes["p"].Probe.(*testProbe)
+	verifyProbeRunningStatus(t, p, true)
+
+	resp, err := pr.ListProbes(context.Background(), &pb.ListProbesRequest{})
+	assert.NoError(t, err)
+	assert.Len(t, resp.GetProbe(), 1)
+	assert.InDelta(t, time.Now().Add(time.Second).Unix(), resp.GetProbe()[0].GetExpirationTime(), 1)
+
+	// Probe should be stopped and removed after TTL.
+	verifyProbeRunningStatus(t, p, false)
+	pr.mu.RLock()
+	assert.Nil(t, pr.Probes["p"])
+	assert.Empty(t, pr.probeExpiration)
+	pr.mu.RUnlock()
+}
+
+func TestAddProbeNotOnThisHost(t *testing.T) {
+	pr, cancel := testProber(t, &configpb.ProberConfig{})
+	defer cancel()
+
+	probeDef := testProbeDef("p")
+	probeDef.RunOn = proto.String("^no-such-host$")
+
+	_, err := pr.AddProbe(context.Background(), &pb.AddProbeRequest{ProbeConfig: probeDef})
+	assert.NoError(t, err)
+	assert.Nil(t, pr.Probes["p"])
+}
+
 func TestListProbes(t *testing.T) {
 	pr, cancel := testProber(t, &configpb.ProberConfig{})
 	defer cancel()
@@ -251,3 +290,27 @@ func TestSaveProbesConfig(t *testing.T) {
 	pr.SaveProbesConfig(context.Background(), &pb.SaveProbesConfigRequest{FilePath: proto.String(f2.Name())})
 	compareConfig(f2.Name(), wantConfigProbe1)
 }
+
+func TestGetConfig(t *testing.T) {
+	pr := &Prober{
+		c: &configpb.ProberConfig{
+			Probe: []*probes_configpb.ProbeDef{testProbeDef("test-probe-1")},
+			Surfacer: []*surfacerpb.SurfacerDef{
+				{
+					Type: surfacerpb.Type_DATADOG.Enum(),
+					Surfacer: &surfacerpb.SurfacerDef_DatadogSurfacer{
+						DatadogSurfacer: &datadogpb.SurfacerConf{ApiKey: proto.String("dd-api-key-123")},
+					},
+				},
+			},
+		},
+	}
+
+	resp, err := pr.GetConfig(context.Background(), &pb.GetConfigRequest{})
+	assert.NoError(t, err)
+	// prototext randomizes the whitespace after the field separator.
+	gotConfig := strings.ReplaceAll(resp.GetConfig(), ":  ", ": ")
+	assert.Contains(t, gotConfig, `name: "test-probe-1"`)
+	assert.Contains(t, gotConfig, `api_key: "REDACTED"`)
+	assert.NotContains(t, gotConfig, "dd-api-key-123")
+}
@@ -112,6 +112,11 @@ func (p *Probe) newResult() sched.ProbeResult {
 	return result
 }
 
+// SuccessCount returns the number of successful probe runs so far.
+func (prr probeRunResult) SuccessCount() int64 {
+	return prr.success.Int64()
+}
+
 // Metrics converts probeRunResult into metrics.EventMetrics object
 func (prr probeRunResult) Metrics(ts time.Time, _ int64, opts *options.Options) []*metrics.EventMetrics {
 	em := metrics.NewEventMetrics(ts).
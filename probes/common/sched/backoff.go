@@ -0,0 +1,94 @@
+// Copyright 2025 The Cloudprober Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sched
+
+import (
+	"time"
+
+	"github.com/cloudprober/cloudprober/logger"
+	"github.com/cloudprober/cloudprober/metrics"
+	"github.com/cloudprober/cloudprober/probes/options"
+)
+
+// successCounter is implemented by the probe results that can report the
+// number of successful probe runs so far. It's used to track consecutive
+// failures for the failure backoff.
+type successCounter interface {
+	SuccessCount() int64
+}
+
+// backoffState keeps track of the failure backoff state for a target.
+type backoffState struct {
+	fb          *options.FailureBackoff
+	interval    time.Duration // Normal probe interval.
+	lastSuccess int64
+	failures    int
+	curInterval time.Duration // Current interval, 0 if not backing off.
+	nextRun     time.Time
+	l           *logger.Logger
+}
+
+// skip returns true if probe run at the given time should be skipped
+// because we are backing off.
+func (bs *backoffState) skip(ts time.Time) bool {
+	return ts.Before(bs.nextRun)
+}
+
+func (bs *backoffState) backingOff() bool {
+	return bs.curInterval != 0
+}
+
+// update updates the backoff state after a probe run, started at ts. It
+// returns true if we started or stopped backing off.
+func (bs *backoffState) update(ts time.Time, result ProbeResult, target string) bool {
+	sc, ok := result.(successCounter)
+	if !ok {
+		return false
+	}
+
+	if success := sc.SuccessCount(); success > bs.lastSuccess {
+		bs.lastSuccess = success
+		wasBackingOff := bs.backingOff()
+		if wasBackingOff {
+			bs.l.Infof("Target %s recovered, restoring probe interval to %v", target, bs.interval)
+		}
+		bs.failures, bs.curInterval, bs.nextRun = 0, 0, time.Time{}
+		return wasBackingOff
+	}
+
+	bs.failures++
+	interval := bs.fb.Interval(bs.interval, bs.failures)
+	if interval == bs.interval {
+		return false
+	}
+
+	started := !bs.backingOff()
+	if started {
+		bs.l.Warningf("Target %s failed %d times in a row, backing off the probe interval", target, bs.failures)
+	}
+	bs.curInterval = interval
+	bs.nextRun = ts.Add(interval)
+	return started
+}
+
+// metrics returns the backoff state as EventMetrics.
+func (bs *backoffState) metrics(ts time.Time) *metrics.EventMetrics {
+	em := metrics.NewEventMetrics(ts).
+		AddMetric("consecutive_failures", metrics.NewInt(int64(bs.failures))).
+		AddMetric("backoff_interval_sec", metrics.NewFloat(bs.curInterval.Seconds()))
+	em.Kind = metrics.GAUGE
+	em.SetNotForAlerting()
+	return em
+}
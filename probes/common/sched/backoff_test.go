@@ -0,0 +1,75 @@
+// Copyright 2025 The Cloudprober Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sched
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cloudprober/cloudprober/metrics"
+	"github.com/cloudprober/cloudprober/probes/options"
+	"github.com/stretchr/testify/assert"
+)
+
+type testSuccessResult struct {
+	success int64
+}
+
+func (r *testSuccessResult) Metrics(_ time.Time, _ int64, _ *options.Options) []*metrics.EventMetrics {
+	return nil
+}
+
+func (r *testSuccessResult) SuccessCount() int64 {
+	return r.success
+}
+
+func TestBackoffState(t *testing.T) {
+	interval := 10 * time.Second
+	bs := &backoffState{
+		fb:       &options.FailureBackoff{FailureThreshold: 2, Multiplier: 2, MaxInterval: time.Minute},
+		interval: interval,
+	}
+	result := &testSuccessResult{}
+	ts := time.Now()
+
+	// First failure, no backoff yet.
+	assert.False(t, bs.update(ts, result, "t1"))
+	assert.False(t, bs.backingOff())
+	assert.False(t, bs.skip(ts.Add(interval)))
+
+	// Second failure, start backing off. Backoff interval should be between
+	// 0.9*2*interval and 2*interval.
+	assert.True(t, bs.update(ts, result, "t1"))
+	assert.True(t, bs.backingOff())
+	assert.Equal(t, 2, bs.failures)
+	assert.True(t, bs.skip(ts.Add(interval)))
+	assert.False(t, bs.skip(ts.Add(2*interval)))
+
+	em := bs.metrics(ts)
+	assert.Equal(t, metrics.Kind(metrics.GAUGE), em.Kind)
+	assert.Equal(t, "2", em.Metric("consecutive_failures").String())
+	assert.Equal(t, bs.curInterval.Seconds(), em.Metric("backoff_interval_sec").(*metrics.Float).Float64())
+
+	// Third failure, still backing off, no state change.
+	assert.False(t, bs.update(ts, result, "t1"))
+	assert.True(t, bs.backingOff())
+
+	// Success, stop backing off.
+	result.success++
+	assert.True(t, bs.update(ts, result, "t1"))
+	assert.False(t, bs.backingOff())
+	assert.Equal(t, 0, bs.failures)
+	assert.False(t, bs.skip(ts))
+}
@@ -144,6 +144,22 @@ func (s *Scheduler) startForTarget(ctx context.Context, target endpoint.Endpoint
 		runReq.Result = s.NewResult(&target)
 	}
 
+	// Timeout may be scaled for the target. If retries are configured, timeout
+	// applies to each attempt.
+	tos := &timeoutState{
+		timeout: s.Opts.RetryPolicy.RunTimeout(s.Opts.TimeoutForTarget(target)),
+		l:       s.Opts.Logger,
+	}
+
+	var bs *backoffState
+	if s.Opts.FailureBackoff != nil {
+		bs = &backoffState{
+			fb:       s.Opts.FailureBackoff,
+			interval: s.Opts.Interval,
+			l:        s.Opts.Logger,
+		}
+	}
+
 	for ts := time.Now(); true; ts = <-ticker.C {
 		// Don't run another probe if context is canceled already.
 		if CtxDone(ctx) {
@@ -153,17 +169,48 @@ func (s *Scheduler) startForTarget(ctx context.Context, target endpoint.Endpoint
 			continue
 		}
 
+		// Skip this run if we are backing off after failures.
+		if bs != nil && bs.skip(ts) {
+			continue
+		}
+
+		// Wait for the outbound rate limits and a global run slot, if
+		// configured. An error here means that context was canceled.
+		release, err := s.Opts.WaitForRunSlot(ctx)
+		if err != nil {
+			return
+		}
+
+		// Track how late we are starting this run, compared to the ticker.
+		s.Opts.RecordSchedulingDrift(time.Since(ts))
+
 		runCnt++
-		timedCtx, cancelTimedCtx := context.WithTimeout(ctx, s.Opts.Timeout)
-		s.RunProbeForTarget(timedCtx, runReq)
-		cancelTimedCtx()
+		func() {
+			// Release the slot even if the probe run panics, panics are
+			// recovered and the probe is restarted as per its restart policy.
+			defer release()
+			tos.run(ctx, target.Name, func(timedCtx context.Context) {
+				s.RunProbeForTarget(timedCtx, runReq)
+			})
+		}()
+
+		exportNow := (runCnt % s.Opts.StatsExportFrequency()) == 0
+		if bs != nil {
+			changed := bs.update(ts, runReq.Result, target.Name)
+			// While backing off, runs are infrequent, export on every run.
+			exportNow = exportNow || changed || bs.backingOff()
+		}
 
 		// Export stats if it's the time to do so and context was not canceled
 		// while we were running the probe. Context is typically canceled when
 		// target is deleted after a target refresh. We don't want to export
 		// metrics in such cases.
-		if (runCnt%s.Opts.StatsExportFrequency()) == 0 && !CtxDone(ctx) {
-			for _, em := range runReq.Result.Metrics(ts, runCnt, s.Opts) {
+		if exportNow && !CtxDone(ctx) {
+			ems := runReq.Result.Metrics(ts, runCnt, s.Opts)
+			if bs != nil {
+				ems = append(ems, bs.metrics(ts))
+			}
+			for _, em := range ems {
 				// Returning nil is a way to skip this target. Used by grpc probe.
 				if em == nil {
 					continue
@@ -172,6 +219,15 @@ func (s *Scheduler) startForTarget(ctx context.Context, target endpoint.Endpoint
 					AddLabel("dst", target.Dst())
 				s.Opts.RecordMetrics(target, em, s.DataChan)
 			}
+
+			// Timeout counters are exported once there is a timeout. These
+			// are not probe results, so they go on their own path.
+			if tos.timeouts > 0 {
+				em := tos.metrics(ts).
+					AddLabel("probe", s.ProbeName).
+					AddLabel("dst", target.Dst())
+				s.Opts.RecordSchedulerMetrics(target, em, s.DataChan)
+			}
 		}
 	}
 }
@@ -246,7 +302,9 @@ func (s *Scheduler) refreshTargets(ctx context.Context) {
 				// Wait for wait time + some jitter before starting this probe loop.
 				time.Sleep(waitTime + time.Duration(rand.Int63n(jitterMaxUsec))*time.Microsecond)
 			}
-			s.startForTarget(probeCtx, target)
+			s.Opts.RunWithRestarts(probeCtx, target.Dst(), s.DataChan, func() {
+				s.startForTarget(probeCtx, target)
+			})
 		}(target, startWaitTime)
 
 		startWaitTime += gapBetweenTargets
@@ -153,6 +153,13 @@ func TestRunProbeForTargetTimeout(t *testing.T) {
 
 	assert.Equal(t, len(testTargets), len(s.cancelFuncs), "len(s.cancelFunc)=%d, want=%d", len(s.cancelFuncs), len(testTargets))
 
+	// Timeouts should be reported through the run_timeouts metric.
+	ems, _ := testutils.MetricsFromChannel(s.DataChan, 100, time.Second)
+	mmap := testutils.MetricsMapByTarget(ems).Filter("run_timeouts")
+	for _, tgt := range testTargets {
+		assert.NotEmpty(t, mmap[tgt], "run_timeouts metric for %s", tgt)
+	}
+
 	cancelF()
 	s.Wait()
 }
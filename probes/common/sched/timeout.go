@@ -0,0 +1,70 @@
+// Copyright 2025 The Cloudprober Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sched
+
+import (
+	"context"
+	"time"
+
+	"github.com/cloudprober/cloudprober/logger"
+	"github.com/cloudprober/cloudprober/metrics"
+)
+
+// Time after the run timeout, after which a probe run that is still running
+// is considered to be not honoring the timeout.
+var timeoutOverrunGrace = time.Second
+
+// timeoutState enforces the run timeout for a target's probe runs, and keeps
+// track of the runs that timed out.
+type timeoutState struct {
+	timeout  time.Duration
+	timeouts int64 // Runs that hit the timeout.
+	overruns int64 // Runs that didn't return within the grace period.
+	l        *logger.Logger
+}
+
+// run runs f with a context that expires after the timeout. Go doesn't allow
+// stopping a goroutine from outside, so run still waits for f to return, but
+// it logs a warning as soon as f overruns the timeout by more than the grace
+// period, and accounts for it.
+func (tos *timeoutState) run(ctx context.Context, target string, f func(context.Context)) {
+	timedCtx, cancel := context.WithTimeout(ctx, tos.timeout)
+	defer cancel()
+
+	overrunTimer := time.AfterFunc(tos.timeout+timeoutOverrunGrace, func() {
+		tos.l.Warningf("Probe run for target %s is still running %v after its timeout (%v), probe is not honoring the timeout", target, timeoutOverrunGrace, tos.timeout)
+	})
+
+	f(timedCtx)
+
+	if !overrunTimer.Stop() {
+		tos.overruns++
+	}
+	if timedCtx.Err() == context.DeadlineExceeded {
+		tos.timeouts++
+	}
+}
+
+// metrics returns the timeout counters as EventMetrics. Metric names are
+// different from the "timeouts" metric exported by some probe types, which
+// counts the timed out requests as seen by the probe itself.
+func (tos *timeoutState) metrics(ts time.Time) *metrics.EventMetrics {
+	em := metrics.NewEventMetrics(ts).
+		AddMetric("run_timeouts", metrics.NewInt(tos.timeouts)).
+		AddMetric("run_timeout_overruns", metrics.NewInt(tos.overruns))
+	em.Kind = metrics.CUMULATIVE
+	em.SetNotForAlerting()
+	return em
+}
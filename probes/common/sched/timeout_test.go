@@ -0,0 +1,57 @@
+// Copyright 2025 The Cloudprober Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sched
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/cloudprober/cloudprober/metrics"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTimeoutStateRun(t *testing.T) {
+	oldGrace := timeoutOverrunGrace
+	defer func() { timeoutOverrunGrace = oldGrace }()
+	timeoutOverrunGrace = 10 * time.Millisecond
+
+	tos := &timeoutState{timeout: 20 * time.Millisecond}
+
+	// Run that returns before the timeout.
+	tos.run(context.Background(), "t1", func(ctx context.Context) {})
+	assert.Equal(t, int64(0), tos.timeouts)
+
+	// Run that honors the timeout.
+	tos.run(context.Background(), "t1", func(ctx context.Context) { <-ctx.Done() })
+	assert.Equal(t, int64(1), tos.timeouts)
+	assert.Equal(t, int64(0), tos.overruns)
+
+	// Run that doesn't honor the timeout.
+	tos.run(context.Background(), "t1", func(ctx context.Context) { time.Sleep(100 * time.Millisecond) })
+	assert.Equal(t, int64(2), tos.timeouts)
+	assert.Equal(t, int64(1), tos.overruns)
+
+	// Parent context cancelation is not a timeout.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	tos.run(ctx, "t1", func(ctx context.Context) { <-ctx.Done() })
+	assert.Equal(t, int64(2), tos.timeouts)
+
+	em := tos.metrics(time.Now())
+	assert.Equal(t, metrics.Kind(metrics.CUMULATIVE), em.Kind)
+	assert.Equal(t, int64(2), em.Metric("run_timeouts").(*metrics.Int).Int64())
+	assert.Equal(t, int64(1), em.Metric("run_timeout_overruns").(*metrics.Int).Int64())
+}
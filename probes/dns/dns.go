@@ -25,15 +25,19 @@ package dns
 
 import (
 	"context"
+	"crypto/tls"
 	"errors"
 	"fmt"
 	"log/slog"
 	"net"
+	"regexp"
 	"strconv"
 	"strings"
 	"sync"
 	"time"
 
+	"github.com/cloudprober/cloudprober/common/strtemplate"
+	"github.com/cloudprober/cloudprober/common/tlsconfig"
 	"github.com/cloudprober/cloudprober/internal/validators"
 	"github.com/cloudprober/cloudprober/logger"
 	"github.com/cloudprober/cloudprober/metrics"
@@ -44,7 +48,11 @@ import (
 	"github.com/miekg/dns"
 )
 
-const defaultPort = 53
+const (
+	defaultPort    = 53
+	defaultDoTPort = 853
+	defaultDoHPort = 443
+)
 
 // Client provides a DNS client interface for required functionality.
 // This makes it possible to mock.
@@ -53,6 +61,7 @@ type Client interface {
 	setTimeout(time.Duration)
 	setSourceIP(net.IP)
 	setDNSProto(configpb.DNSProto)
+	setTLSConfig(*tls.Config)
 }
 
 // ClientImpl is a concrete DNS client that can be instantiated.
@@ -65,10 +74,15 @@ func (c *clientImpl) setTimeout(d time.Duration) {
 	c.Timeout = d
 }
 
-// setSourceIP allows write-access to the underlying ReadTimeout variable.
+// setSourceIP sets the local address to send queries from. It should be
+// called after setDNSProto, as local address type depends on the protocol.
 func (c *clientImpl) setSourceIP(ip net.IP) {
+	var localAddr net.Addr = &net.UDPAddr{IP: ip}
+	if strings.HasPrefix(c.Net, "tcp") {
+		localAddr = &net.TCPAddr{IP: ip}
+	}
 	c.Dialer = &net.Dialer{
-		LocalAddr: &net.UDPAddr{IP: ip},
+		LocalAddr: localAddr,
 	}
 }
 
@@ -84,6 +98,11 @@ func (c *clientImpl) setDNSProto(proto configpb.DNSProto) {
 	}
 }
 
+// setTLSConfig sets the TLS config for DNS over TLS.
+func (c *clientImpl) setTLSConfig(tlsConfig *tls.Config) {
+	c.TLSConfig = tlsConfig
+}
+
 // Probe holds aggregate information about all probe runs, per-target.
 type Probe struct {
 	name string
@@ -97,6 +116,22 @@ type Probe struct {
 	queryClass uint16
 	fqdn       string
 	client     Client
+
+	// Used to retry truncated UDP responses, if tcp_fallback is enabled.
+	tcpClient Client
+
+	// Clients for all DNS protocols, only used if dns_proto_label is set.
+	protoClients map[configpb.DNSProto]Client
+
+	// Only used if dnssec_validation is enabled.
+	trustAnchors []*dns.DS
+
+	ednsOptions []dns.EDNS0
+
+	// Only used if compare_soa_serials is enabled.
+	soaSerials *soaSerials
+
+	answerRegex *regexp.Regexp
 }
 
 // probeRunResult captures the results of a single probe run. The way we work with
@@ -109,6 +144,21 @@ type probeRunResult struct {
 	latency           metrics.LatencyValue
 	timeouts          metrics.Int
 	validationFailure *metrics.Map[int64]
+
+	// Only used if retry policy is configured.
+	successFirstAttempt metrics.Int
+	retries             metrics.Int
+
+	// Only used if tcp_fallback is enabled.
+	tcpRetries metrics.Int
+
+	// Only used if dnssec_validation is enabled.
+	dnssec *metrics.Map[int64]
+
+	// Only used if compare_soa_serials is enabled.
+	hasSOASerial bool
+	soaSerial    int64
+	soaSerialLag int64
 }
 
 func (p *Probe) newResult() sched.ProbeResult {
@@ -118,6 +168,13 @@ func (p *Probe) newResult() sched.ProbeResult {
 		result.validationFailure = validators.ValidationFailureMap(p.opts.Validators)
 	}
 
+	if p.c.GetDnssecValidation() {
+		result.dnssec = metrics.NewMap("status")
+		for _, status := range []string{dnssecSecure, dnssecInsecure, dnssecBogus} {
+			result.dnssec.IncKeyBy(status, 0)
+		}
+	}
+
 	if p.opts.LatencyDist != nil {
 		result.latency = p.opts.LatencyDist.CloneDist()
 	} else {
@@ -127,6 +184,11 @@ func (p *Probe) newResult() sched.ProbeResult {
 	return result
 }
 
+// SuccessCount returns the number of successful probe runs so far.
+func (prr probeRunResult) SuccessCount() int64 {
+	return prr.success.Int64()
+}
+
 // Metrics converts probeRunResult into metrics.EventMetrics object
 func (prr probeRunResult) Metrics(ts time.Time, _ int64, opts *options.Options) []*metrics.EventMetrics {
 	em := metrics.NewEventMetrics(ts).
@@ -140,7 +202,33 @@ func (prr probeRunResult) Metrics(ts time.Time, _ int64, opts *options.Options)
 		em.AddMetric("validation_failure", prr.validationFailure)
 	}
 
-	return []*metrics.EventMetrics{em}
+	if opts.RetryPolicy != nil {
+		em.AddMetric("success_first_attempt", &prr.successFirstAttempt)
+		em.AddMetric("retries", &prr.retries)
+	}
+
+	if c, ok := opts.ProbeConf.(*configpb.ProbeConf); ok && c.GetTcpFallback() {
+		em.AddMetric("tcp_retries", &prr.tcpRetries)
+	}
+
+	if prr.dnssec != nil {
+		em.AddMetric("dnssec", prr.dnssec)
+	}
+
+	ems := []*metrics.EventMetrics{em}
+
+	// SOA serial and lag are exported in an independent EM as they are GAUGE
+	// metrics.
+	if prr.hasSOASerial {
+		em := metrics.NewEventMetrics(ts).
+			AddMetric("soa_serial", metrics.NewInt(prr.soaSerial)).
+			AddMetric("soa_serial_lag", metrics.NewInt(prr.soaSerialLag))
+		em.Kind = metrics.GAUGE
+		em.AddLabel("ptype", "dns")
+		ems = append(ems, em)
+	}
+
+	return ems
 }
 
 // Init initializes the probe with the given params.
@@ -161,7 +249,7 @@ func (p *Probe) Init(name string, opts *options.Options) error {
 		p.l = &logger.Logger{}
 	}
 
-	totalDuration := time.Duration(p.c.GetRequestsIntervalMsec()*p.c.GetRequestsPerProbe())*time.Millisecond + p.opts.Timeout
+	totalDuration := time.Duration(p.c.GetRequestsIntervalMsec()*p.c.GetRequestsPerProbe())*time.Millisecond + p.opts.MaxTimeout()
 	if totalDuration > p.opts.Interval {
 		return fmt.Errorf("invalid config - executing all requests will take "+
 			"longer than the probe interval, i.e. "+
@@ -179,50 +267,223 @@ func (p *Probe) Init(name string, opts *options.Options) error {
 	p.queryClass = uint16(p.c.GetQueryClass())
 	p.fqdn = dns.Fqdn(p.c.GetResolvedDomain())
 
+	if p.c.GetExpectNoAnswer() {
+		if p.c.ExpectedRcode != nil || p.c.GetMinAnswers() > 0 || len(p.c.GetExpectedAnswer()) > 0 || p.c.GetAnswerRegex() != "" {
+			return fmt.Errorf("dns_probe(%v): expect_no_answer cannot be used along with expected_rcode, min_answers, expected_answer or answer_regex", name)
+		}
+	}
+
+	if p.c.GetCompareSoaSerials() {
+		if queryType != configpb.QueryType_SOA {
+			return fmt.Errorf("dns_probe(%v): compare_soa_serials requires query_type SOA, got %v", name, queryType)
+		}
+		// Ignore serials from targets that haven't been probed successfully
+		// for a couple of intervals.
+		p.soaSerials = newSOASerials(2*p.opts.Interval + p.opts.MaxTimeout())
+	}
+
+	if p.c.GetAnswerRegex() != "" {
+		re, err := regexp.Compile(p.c.GetAnswerRegex())
+		if err != nil {
+			return fmt.Errorf("dns_probe(%v): invalid answer_regex: %v", name, err)
+		}
+		p.answerRegex = re
+	}
+
+	// With dns_proto_label, targets may use any of the protocols.
+	protoLabel := p.c.GetDnsProtoLabel() != ""
+
+	var tlsConfig *tls.Config
+	if p.c.GetTlsConfig() != nil {
+		if !protoLabel && p.c.GetDnsProto() != configpb.DNSProto_TCP_TLS && p.c.GetDnsProto() != configpb.DNSProto_HTTPS {
+			return fmt.Errorf("dns_probe(%v): tls_config is valid only with TCP_TLS or HTTPS dns_proto, got %v", name, p.c.GetDnsProto())
+		}
+		tlsConfig = &tls.Config{}
+		if err := tlsconfig.UpdateTLSConfig(tlsConfig, p.c.GetTlsConfig()); err != nil {
+			return fmt.Errorf("dns_probe(%v): tls_config error: %v", name, err)
+		}
+	}
+
+	p.client = p.newClient(p.c.GetDnsProto(), tlsConfig)
+	if protoLabel {
+		p.protoClients = make(map[configpb.DNSProto]Client)
+		for v := range configpb.DNSProto_name {
+			dnsProto := configpb.DNSProto(v)
+			p.protoClients[dnsProto] = p.newClient(dnsProto, tlsConfig)
+		}
+	}
+
+	if p.c.GetTcpFallback() {
+		if !protoLabel && p.c.GetDnsProto() != configpb.DNSProto_UDP {
+			return fmt.Errorf("dns_probe(%v): tcp_fallback is valid only with UDP dns_proto, got %v", name, p.c.GetDnsProto())
+		}
+		p.tcpClient = p.newClient(configpb.DNSProto_TCP, nil)
+	}
+
+	if err := p.initEDNS0(); err != nil {
+		return fmt.Errorf("dns_probe(%v): %v", name, err)
+	}
+
+	if p.c.GetDnssecValidation() {
+		anchors, err := parseTrustAnchors(p.c.GetDnssecTrustAnchor())
+		if err != nil {
+			return fmt.Errorf("dns_probe(%v): %v", name, err)
+		}
+		p.trustAnchors = anchors
+	}
+
+	return nil
+}
+
+// fqdnForTarget returns the domain to query for the target, substituting the
+// target tokens in resolved_domain, e.g. @target.label.service@.
+func (p *Probe) fqdnForTarget(target endpoint.Endpoint, port int) string {
+	if !strings.Contains(p.fqdn, "@") {
+		return p.fqdn
+	}
+
+	labels := map[string]string{
+		"probe":       p.name,
+		"target":      target.Name,
+		"target.name": target.Name,
+		"target.port": strconv.Itoa(port),
+	}
+	if target.IP != nil {
+		labels["target.ip"] = target.IP.String()
+	}
+	for k, v := range target.Labels {
+		labels["target.label."+k] = v
+	}
+
+	fqdn, foundAll := strtemplate.SubstituteLabels(p.fqdn, labels)
+	if !foundAll {
+		p.l.Warningf("Target(%s): couldn't substitute all tokens in the resolved_domain: %s", target.Name, fqdn)
+	}
+	return dns.Fqdn(fqdn)
+}
+
+// newClient creates a new DNS client for the given protocol.
+func (p *Probe) newClient(dnsProto configpb.DNSProto, tlsConfig *tls.Config) Client {
 	// I believe the client is safe for concurrent use by multiple goroutines
 	// (although the documentation doesn't explicitly say so). It uses locks
 	// internally and the underlying net.Conn declares that multiple goroutines
 	// may invoke methods on a net.Conn simultaneously.
-	p.client = new(clientImpl)
+	var c Client
+	if dnsProto == configpb.DNSProto_HTTPS {
+		c = newDoHClient(p.c.GetDohPath())
+	} else {
+		c = new(clientImpl)
+	}
+	// Set DNS Protocol to use
+	c.setDNSProto(dnsProto)
 	if p.opts.SourceIP != nil {
-		p.client.setSourceIP(p.opts.SourceIP)
+		c.setSourceIP(p.opts.SourceIP)
 	}
 
 	// We need it even with context because DNS client uses the lower of this
 	// timeout which is 2s by default, and context timeout, so if context
 	// timeout is 5s, DNS query will timeout in 2s even though context timeout
 	// is 5s.
-	p.client.setTimeout(p.opts.Timeout)
-	// Set DNS Protocol to use
-	p.client.setDNSProto(p.c.GetDnsProto())
+	c.setTimeout(p.opts.MaxTimeout())
 
-	return nil
+	if tlsConfig != nil && (dnsProto == configpb.DNSProto_TCP_TLS || dnsProto == configpb.DNSProto_HTTPS) {
+		c.setTLSConfig(tlsConfig)
+	}
+	return c
 }
 
 // Return true if the underlying error indicates a dns.Client timeout.
 // In our case, we're using the ReadTimeout- time until response is read.
 func isClientTimeout(err error) bool {
-	e, ok := err.(*net.OpError)
-	return ok && e != nil && e.Timeout()
+	var e net.Error
+	return errors.As(err, &e) && e.Timeout()
+}
+
+// defaultPortForProto returns the default DNS server port for the protocol.
+func defaultPortForProto(dnsProto configpb.DNSProto) int {
+	switch dnsProto {
+	case configpb.DNSProto_TCP_TLS:
+		return defaultDoTPort
+	case configpb.DNSProto_HTTPS:
+		return defaultDoHPort
+	}
+	return defaultPort
+}
+
+// dnsProtoForTarget returns the DNS protocol to use for the target. Protocol
+// from the dns_proto_label target label takes precedence over dns_proto.
+func (p *Probe) dnsProtoForTarget(target endpoint.Endpoint) configpb.DNSProto {
+	if label := p.c.GetDnsProtoLabel(); label != "" {
+		if v, ok := configpb.DNSProto_value[strings.ToUpper(target.Labels[label])]; ok {
+			return configpb.DNSProto(v)
+		}
+	}
+	return p.c.GetDnsProto()
+}
+
+// clientForProto returns the client for the DNS protocol.
+func (p *Probe) clientForProto(dnsProto configpb.DNSProto) Client {
+	if c := p.protoClients[dnsProto]; c != nil {
+		return c
+	}
+	return p.client
+}
+
+// portForTarget returns the port to use for the target. Port from the
+// port_label target label takes precedence, followed by the configured port,
+// the target's own port, and then the protocol's default port.
+func (p *Probe) portForTarget(target endpoint.Endpoint, dnsProto configpb.DNSProto) int {
+	if label := p.c.GetPortLabel(); label != "" {
+		if port, err := strconv.Atoi(target.Labels[label]); err == nil && port > 0 && port < 65536 {
+			return port
+		}
+	}
+	if port := int(p.c.GetPort()); port != 0 {
+		return port
+	}
+	if target.Port != 0 {
+		return target.Port
+	}
+	return defaultPortForProto(dnsProto)
 }
 
 // validateResponse checks status code and answer section for correctness and
 // returns true if the response is valid. In case of validation failures, it
 // also updates the result structure.
 func (p *Probe) validateResponse(resp *dns.Msg, result *probeRunResult, l *logger.Logger) bool {
-	if resp == nil || resp.Rcode != dns.RcodeSuccess {
-		l.Error("error in response %v", resp.String())
+	if resp == nil {
+		l.Error("empty response")
 		return false
 	}
 
-	// Validate number of answers in response.
-	// TODO: Move this logic to validators.
-	minAnswers := p.c.GetMinAnswers()
-	if minAnswers > 0 && uint32(len(resp.Answer)) < minAnswers {
-		l.Errorf("too few answers - got %d want %d.\n\tAnswerBlock: %v", len(resp.Answer), minAnswers, resp.Answer)
+	if p.c.GetExpectAuthoritative() && !resp.Authoritative {
+		l.Errorf("response is not authoritative (AA bit not set).\n\tResponse: %v", resp.String())
 		return false
 	}
 
+	if p.c.GetExpectNoAnswer() {
+		if !checkNoAnswer(resp, l) {
+			return false
+		}
+	} else {
+		if resp.Rcode != int(p.c.GetExpectedRcode()) {
+			l.Errorf("unexpected response code - got %s want %s.\n\tResponse: %v", dns.RcodeToString[resp.Rcode], p.c.GetExpectedRcode(), resp.String())
+			return false
+		}
+
+		// Validate number of answers in response.
+		// TODO: Move this logic to validators.
+		minAnswers := p.c.GetMinAnswers()
+		if minAnswers > 0 && uint32(len(resp.Answer)) < minAnswers {
+			l.Errorf("too few answers - got %d want %d.\n\tAnswerBlock: %v", len(resp.Answer), minAnswers, resp.Answer)
+			return false
+		}
+
+		if !p.checkAnswerData(resp.Answer, l) {
+			return false
+		}
+	}
+
 	if p.opts.Validators != nil {
 		answers := []string{}
 		for _, rr := range resp.Answer {
@@ -242,21 +503,113 @@ func (p *Probe) validateResponse(resp *dns.Msg, result *probeRunResult, l *logge
 	return true
 }
 
-func (p *Probe) doDNSRequest(ctx context.Context, target string, result *probeRunResult, resultMu *sync.Mutex) {
-	l := p.l.WithAttributes(slog.String("target", target))
+// checkNoAnswer verifies that the response is negative, i.e. NXDOMAIN, or
+// NOERROR without any answers (NODATA).
+func checkNoAnswer(resp *dns.Msg, l *logger.Logger) bool {
+	if resp.Rcode != dns.RcodeNameError && resp.Rcode != dns.RcodeSuccess {
+		l.Errorf("unexpected response code - got %s want NXDOMAIN or NOERROR.\n\tResponse: %v", dns.RcodeToString[resp.Rcode], resp.String())
+		return false
+	}
+	if len(resp.Answer) > 0 {
+		l.Errorf("got answers for a negative expectation.\n\tAnswerBlock: %v", resp.Answer)
+		return false
+	}
+	return true
+}
+
+// rrData returns the data part of a resource record, i.e. the record without
+// the header (name, TTL, class and type).
+func rrData(rr dns.RR) string {
+	return strings.TrimPrefix(rr.String(), rr.Header().String())
+}
+
+// checkAnswerData verifies answer records' data against expected_answer and
+// answer_regex.
+func (p *Probe) checkAnswerData(answers []dns.RR, l *logger.Logger) bool {
+	if len(p.c.GetExpectedAnswer()) == 0 && p.answerRegex == nil {
+		return true
+	}
+
+	found := make(map[string]bool)
+	for _, rr := range answers {
+		if rr == nil {
+			continue
+		}
+		data := rrData(rr)
+		if p.answerRegex != nil && !p.answerRegex.MatchString(data) {
+			l.Errorf("answer data %q doesn't match answer_regex %q", data, p.answerRegex.String())
+			return false
+		}
+		found[data] = true
+	}
 
-	// Generate a new question for each probe so transaction IDs aren't repeated.
-	msg := new(dns.Msg)
-	msg.SetQuestion(p.fqdn, p.queryType)
-	msg.Question[0].Qclass = p.queryClass
+	for _, want := range p.c.GetExpectedAnswer() {
+		if !found[want] {
+			l.Errorf("expected answer %q not found.\n\tAnswerBlock: %v", want, answers)
+			return false
+		}
+	}
 
-	resp, latency, err := p.client.ExchangeContext(ctx, msg, target)
+	return true
+}
+
+// exchange sends the DNS query to the target. If tcp_fallback is enabled and
+// the response is truncated, it retries the query over TCP. Returned latency
+// includes both the exchanges in that case.
+func (p *Probe) exchange(ctx context.Context, client Client, msg *dns.Msg, target string) (*dns.Msg, time.Duration, bool, error) {
+	resp, latency, err := client.ExchangeContext(ctx, msg, target)
+	if err != nil || p.tcpClient == nil || resp == nil || !resp.Truncated {
+		return resp, latency, false, err
+	}
+
+	p.l.Debugf("Target(%s): UDP response truncated, retrying over TCP", target)
+	resp, tcpLatency, err := p.tcpClient.ExchangeContext(ctx, msg, target)
+	return resp, latency + tcpLatency, true, err
+}
+
+func (p *Probe) doDNSRequest(ctx context.Context, client Client, target, fqdn string, timeout time.Duration, result *probeRunResult, resultMu *sync.Mutex) {
+	l := p.l.WithAttributes(slog.String("target", target))
+
+	var resp *dns.Msg
+	var latency time.Duration
+	var tcpRetries int64
+
+	attempts, err := p.opts.RetryPolicy.Do(ctx, timeout, func(ctx context.Context) error {
+		// Generate a new question for each attempt so transaction IDs aren't
+		// repeated.
+		msg := new(dns.Msg)
+		msg.SetQuestion(fqdn, p.queryType)
+		msg.Question[0].Qclass = p.queryClass
+		msg.RecursionDesired = p.c.GetRecursionDesired()
+		p.setEDNS0(msg)
+
+		var err error
+		var tcpRetried bool
+		resp, latency, tcpRetried, err = p.exchange(ctx, client, msg, target)
+		if tcpRetried {
+			tcpRetries++
+		}
+		return err
+	})
+
+	// Validate DNSSEC before acquiring the result lock, as it may require
+	// several more queries.
+	var dnssecStatus string
+	var dnssecErr error
+	if err == nil && p.c.GetDnssecValidation() && resp != nil && resp.Rcode == dns.RcodeSuccess {
+		dnssecCtx, cancel := context.WithTimeout(ctx, timeout)
+		dnssecStatus, dnssecErr = p.dnssecStatus(dnssecCtx, client, target, resp)
+		cancel()
+	}
 
 	if resultMu != nil {
 		resultMu.Lock()
 		defer resultMu.Unlock()
 	}
 
+	result.retries.IncBy(int64(attempts - 1))
+	result.tcpRetries.IncBy(tcpRetries)
+
 	if err != nil {
 		if isClientTimeout(err) {
 			l.Error("client.Exchange: Timeout error: ", err.Error())
@@ -265,6 +618,25 @@ func (p *Probe) doDNSRequest(ctx context.Context, target string, result *probeRu
 			l.Error("client.Exchange: ", err.Error())
 		}
 	} else if p.validateResponse(resp, result, l) {
+		if dnssecStatus != "" {
+			result.dnssec.IncKey(dnssecStatus)
+			if dnssecErr != nil {
+				l.Warningf("DNSSEC %s: %v", dnssecStatus, dnssecErr)
+			}
+			if dnssecStatus == dnssecBogus {
+				return
+			}
+		}
+		if p.soaSerials != nil {
+			if serial, ok := soaSerial(resp); ok {
+				result.hasSOASerial = true
+				result.soaSerial = int64(serial)
+				result.soaSerialLag = p.soaSerials.update(target, serial, time.Now())
+			}
+		}
+		if attempts == 1 {
+			result.successFirstAttempt.Inc()
+		}
 		result.success.Inc()
 		result.latency.AddFloat64(latency.Seconds() / p.opts.LatencyUnit.Seconds())
 	}
@@ -276,14 +648,14 @@ func (p *Probe) runProbe(ctx context.Context, runReq *sched.RunProbeForTargetReq
 	}
 	target, result := runReq.Target, runReq.Result.(*probeRunResult)
 
-	port := defaultPort
-	if target.Port != 0 {
-		port = target.Port
-	}
+	dnsProto := p.dnsProtoForTarget(target)
+	client := p.clientForProto(dnsProto)
+	port := p.portForTarget(target, dnsProto)
 	result.total.IncBy(int64(p.c.GetRequestsPerProbe()))
 
 	ipLabel := ""
 	fullTarget := net.JoinHostPort(target.Name, strconv.Itoa(port))
+	timeout := p.opts.TimeoutForTarget(target)
 
 	resolveFirst := false
 	if p.c.ResolveFirst != nil {
@@ -305,8 +677,10 @@ func (p *Probe) runProbe(ctx context.Context, runReq *sched.RunProbeForTargetReq
 		al.UpdateForTarget(target, ipLabel, port)
 	}
 
+	fqdn := p.fqdnForTarget(target, port)
+
 	if p.c.GetRequestsPerProbe() == 1 {
-		p.doDNSRequest(ctx, fullTarget, result, nil)
+		p.doDNSRequest(ctx, client, fullTarget, fqdn, timeout, result, nil)
 		return
 	}
 
@@ -322,7 +696,7 @@ func (p *Probe) runProbe(ctx context.Context, runReq *sched.RunProbeForTargetReq
 			defer wg.Done()
 
 			time.Sleep(time.Duration(reqNum*int(p.c.GetRequestsIntervalMsec())) * time.Millisecond)
-			p.doDNSRequest(ctx, fullTarget, result, &resultMu)
+			p.doDNSRequest(ctx, client, fullTarget, fqdn, timeout, result, &resultMu)
 		}(i, result)
 	}
 	p.l.Debug("Waiting for DNS requests to finish")
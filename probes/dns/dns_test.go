@@ -16,11 +16,14 @@ package dns
 
 import (
 	"context"
+	"crypto/tls"
 	"fmt"
 	"net"
+	"sync/atomic"
 	"testing"
 	"time"
 
+	tlsconfigpb "github.com/cloudprober/cloudprober/common/tlsconfig/proto"
 	"github.com/cloudprober/cloudprober/internal/validators"
 	validatorpb "github.com/cloudprober/cloudprober/internal/validators/proto"
 	"github.com/cloudprober/cloudprober/logger"
@@ -38,6 +41,9 @@ import (
 const (
 	questionBadDomain    = "nosuchname"
 	questionBadType      = configpb.QueryType_CAA
+	questionTruncated    = "truncated.example.com"
+	questionNoData       = "nodata.example.com"
+	questionAuthority    = "authority.example.com"
 	answerContent        = " 3600 IN A 192.168.0.1"
 	answerMatchPattern   = "3600"
 	answerNoMatchPattern = "NAA"
@@ -47,19 +53,36 @@ var (
 	globalLog = logger.Logger{}
 )
 
-type mockClient struct{}
+type mockClient struct {
+	dnsProto configpb.DNSProto
+
+	// RD bit of the last query.
+	recursionDesired atomic.Bool
+}
 
 // Exchange implementation that returns an error status if the query is for
 // questionBad[Domain|Type]. This allows us to check if query parameters are
-// populated correctly.
-func (*mockClient) ExchangeContext(ctx context.Context, in *dns.Msg, fullTarget string) (*dns.Msg, time.Duration, error) {
+// populated correctly. Responses to questionTruncated are truncated, unless
+// the query is over TCP, responses to questionNoData have no answers, and
+// responses to questionAuthority are authoritative.
+func (m *mockClient) ExchangeContext(ctx context.Context, in *dns.Msg, fullTarget string) (*dns.Msg, time.Duration, error) {
 	if fullTarget != "8.8.8.8:53" {
 		return nil, 0, fmt.Errorf("unexpected target: %v", fullTarget)
 	}
+	m.recursionDesired.Store(in.RecursionDesired)
 	out := &dns.Msg{}
+	out.Authoritative = in.Question[0].Name == questionAuthority+"."
 	question := in.Question[0]
 	if question.Name == questionBadDomain+"." || int(question.Qtype) == int(questionBadType) {
 		out.Rcode = dns.RcodeNameError
+		return out, time.Millisecond, nil
+	}
+	if question.Name == questionNoData+"." {
+		return out, time.Millisecond, nil
+	}
+	if question.Name == questionTruncated+"." && m.dnsProto == configpb.DNSProto_UDP {
+		out.Truncated = true
+		return out, time.Millisecond, nil
 	}
 	answerStr := question.Name + answerContent
 	a, err := dns.NewRR(answerStr)
@@ -70,12 +93,18 @@ func (*mockClient) ExchangeContext(ctx context.Context, in *dns.Msg, fullTarget
 	}
 	return out, time.Millisecond, nil
 }
-func (*mockClient) setTimeout(time.Duration)      {}
-func (*mockClient) setSourceIP(net.IP)            {}
-func (*mockClient) setDNSProto(configpb.DNSProto) {}
+func (*mockClient) setTimeout(time.Duration) {}
+func (*mockClient) setSourceIP(net.IP)       {}
+func (m *mockClient) setDNSProto(proto configpb.DNSProto) {
+	m.dnsProto = proto
+}
+func (*mockClient) setTLSConfig(*tls.Config) {}
 
 func runProbeAndVerify(t *testing.T, testName string, p *Probe, total, success int64) {
 	p.client = new(mockClient)
+	if p.tcpClient != nil {
+		p.tcpClient = &mockClient{dnsProto: configpb.DNSProto_TCP}
+	}
 	p.targets = p.opts.Targets.ListEndpoints()
 
 	for _, target := range p.targets {
@@ -207,6 +236,46 @@ func TestProbeType(t *testing.T) {
 	runProbeAndVerify(t, "probetype", p, 1, 0)
 }
 
+func TestQueryType(t *testing.T) {
+	for _, test := range []struct {
+		queryType configpb.QueryType
+		want      uint16
+		wantErr   bool
+	}{
+		{queryType: configpb.QueryType_A, want: dns.TypeA},
+		{queryType: configpb.QueryType_AAAA, want: dns.TypeAAAA},
+		{queryType: configpb.QueryType_MX, want: dns.TypeMX},
+		{queryType: configpb.QueryType_TXT, want: dns.TypeTXT},
+		{queryType: configpb.QueryType_SRV, want: dns.TypeSRV},
+		{queryType: configpb.QueryType_NS, want: dns.TypeNS},
+		{queryType: configpb.QueryType_CNAME, want: dns.TypeCNAME},
+		{queryType: configpb.QueryType_SOA, want: dns.TypeSOA},
+		{queryType: configpb.QueryType_ANY, want: dns.TypeANY},
+		{queryType: configpb.QueryType_NONE, wantErr: true},
+	} {
+		t.Run(test.queryType.String(), func(t *testing.T) {
+			p := &Probe{}
+			opts := &options.Options{
+				Targets:   targets.StaticTargets("8.8.8.8"),
+				Interval:  2 * time.Second,
+				Timeout:   time.Second,
+				ProbeConf: &configpb.ProbeConf{QueryType: test.queryType.Enum()},
+			}
+			err := p.Init("dns_query_type_test", opts)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("got err: %v, want err: %v", err, test.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if p.queryType != test.want {
+				t.Errorf("query type: got %d, want %d", p.queryType, test.want)
+			}
+			runProbeAndVerify(t, "querytype_"+test.queryType.String(), p, 1, 1)
+		})
+	}
+}
+
 func TestProbeProto(t *testing.T) {
 	p := &Probe{}
 	opts := &options.Options{
@@ -328,3 +397,451 @@ func TestValidator(t *testing.T) {
 		runProbeAndVerify(t, tst.name, p, 1, tst.successCt)
 	}
 }
+
+func TestExpectedRcode(t *testing.T) {
+	for _, test := range []struct {
+		desc        string
+		domain      string
+		rcode       configpb.Rcode
+		wantSuccess int64
+	}{
+		{desc: "noerror", rcode: configpb.Rcode_NOERROR, wantSuccess: 1},
+		{desc: "noerror_bad_domain", domain: questionBadDomain, rcode: configpb.Rcode_NOERROR, wantSuccess: 0},
+		{desc: "nxdomain", domain: questionBadDomain, rcode: configpb.Rcode_NXDOMAIN, wantSuccess: 1},
+		{desc: "nxdomain_good_domain", rcode: configpb.Rcode_NXDOMAIN, wantSuccess: 0},
+	} {
+		t.Run(test.desc, func(t *testing.T) {
+			c := &configpb.ProbeConf{ExpectedRcode: test.rcode.Enum()}
+			if test.domain != "" {
+				c.ResolvedDomain = proto.String(test.domain)
+			}
+			p := &Probe{}
+			opts := &options.Options{
+				Targets:   targets.StaticTargets("8.8.8.8"),
+				Interval:  2 * time.Second,
+				Timeout:   time.Second,
+				ProbeConf: c,
+			}
+			if err := p.Init("dns_expected_rcode_test", opts); err != nil {
+				t.Fatalf("Error creating probe: %v", err)
+			}
+			runProbeAndVerify(t, test.desc, p, 1, test.wantSuccess)
+		})
+	}
+}
+
+func TestAnswerData(t *testing.T) {
+	for _, test := range []struct {
+		desc        string
+		expected    []string
+		regex       string
+		wantSuccess int64
+		wantErr     bool
+	}{
+		{desc: "expected_match", expected: []string{"192.168.0.1"}, wantSuccess: 1},
+		{desc: "expected_nomatch", expected: []string{"10.0.0.1"}, wantSuccess: 0},
+		{desc: "expected_partial", expected: []string{"192.168.0.1", "192.168.0.2"}, wantSuccess: 0},
+		{desc: "regex_match", regex: "^192\\.168\\.", wantSuccess: 1},
+		{desc: "regex_nomatch", regex: "^10\\.", wantSuccess: 0},
+		{desc: "regex_invalid", regex: "(", wantErr: true},
+	} {
+		t.Run(test.desc, func(t *testing.T) {
+			c := &configpb.ProbeConf{ExpectedAnswer: test.expected}
+			if test.regex != "" {
+				c.AnswerRegex = proto.String(test.regex)
+			}
+			p := &Probe{}
+			opts := &options.Options{
+				Targets:   targets.StaticTargets("8.8.8.8"),
+				Interval:  2 * time.Second,
+				Timeout:   time.Second,
+				ProbeConf: c,
+			}
+			err := p.Init("dns_answer_data_test", opts)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("got err: %v, want err: %v", err, test.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			runProbeAndVerify(t, test.desc, p, 1, test.wantSuccess)
+		})
+	}
+}
+
+func TestRRData(t *testing.T) {
+	for _, test := range []struct {
+		rr   string
+		want string
+	}{
+		{rr: "example.com. 3600 IN A 192.168.0.1", want: "192.168.0.1"},
+		{rr: "example.com. 3600 IN MX 10 mx.example.com.", want: "10 mx.example.com."},
+		{rr: "www.example.com. 300 IN CNAME example.com.", want: "example.com."},
+	} {
+		rr, err := dns.NewRR(test.rr)
+		if err != nil {
+			t.Fatalf("Error parsing RR %q: %v", test.rr, err)
+		}
+		if got := rrData(rr); got != test.want {
+			t.Errorf("rrData(%q) = %q, want %q", test.rr, got, test.want)
+		}
+	}
+}
+
+func TestTCPFallback(t *testing.T) {
+	for _, test := range []struct {
+		desc           string
+		c              *configpb.ProbeConf
+		wantSuccess    int64
+		wantTCPRetries int64
+		wantErr        bool
+	}{
+		{
+			desc:        "no_truncation",
+			c:           &configpb.ProbeConf{TcpFallback: proto.Bool(true)},
+			wantSuccess: 1,
+		},
+		{
+			desc: "truncation_with_fallback",
+			c: &configpb.ProbeConf{
+				ResolvedDomain: proto.String(questionTruncated),
+				MinAnswers:     proto.Uint32(1),
+				TcpFallback:    proto.Bool(true),
+			},
+			wantSuccess:    1,
+			wantTCPRetries: 1,
+		},
+		{
+			desc: "truncation_without_fallback",
+			c: &configpb.ProbeConf{
+				ResolvedDomain: proto.String(questionTruncated),
+				MinAnswers:     proto.Uint32(1),
+			},
+			wantSuccess: 0,
+		},
+		{
+			desc: "fallback_with_tcp",
+			c: &configpb.ProbeConf{
+				DnsProto:    configpb.DNSProto_TCP.Enum(),
+				TcpFallback: proto.Bool(true),
+			},
+			wantErr: true,
+		},
+	} {
+		t.Run(test.desc, func(t *testing.T) {
+			p := &Probe{}
+			opts := &options.Options{
+				Targets:   targets.StaticTargets("8.8.8.8"),
+				Interval:  2 * time.Second,
+				Timeout:   time.Second,
+				ProbeConf: test.c,
+			}
+			err := p.Init("dns_tcp_fallback_test", opts)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("got err: %v, want err: %v", err, test.wantErr)
+			}
+			if err != nil {
+				return
+			}
+
+			p.client = new(mockClient)
+			if p.tcpClient != nil {
+				p.tcpClient = &mockClient{dnsProto: configpb.DNSProto_TCP}
+			}
+			runReq := &sched.RunProbeForTargetRequest{Target: endpoint.Endpoint{Name: "8.8.8.8"}}
+			p.runProbe(context.Background(), runReq)
+
+			result := runReq.Result.(*probeRunResult)
+			if result.success.Int64() != test.wantSuccess {
+				t.Errorf("success: got %d, want %d", result.success.Int64(), test.wantSuccess)
+			}
+			if result.tcpRetries.Int64() != test.wantTCPRetries {
+				t.Errorf("tcp_retries: got %d, want %d", result.tcpRetries.Int64(), test.wantTCPRetries)
+			}
+		})
+	}
+}
+
+func TestDefaultPortForProto(t *testing.T) {
+	for dnsProto, want := range map[configpb.DNSProto]int{
+		configpb.DNSProto_UDP:     53,
+		configpb.DNSProto_TCP:     53,
+		configpb.DNSProto_TCP_TLS: 853,
+		configpb.DNSProto_HTTPS:   443,
+	} {
+		if got := defaultPortForProto(dnsProto); got != want {
+			t.Errorf("defaultPortForProto(%v) = %d, want %d", dnsProto, got, want)
+		}
+	}
+}
+
+func TestInitTLSConfig(t *testing.T) {
+	for _, test := range []struct {
+		dnsProto configpb.DNSProto
+		wantErr  bool
+	}{
+		{dnsProto: configpb.DNSProto_UDP, wantErr: true},
+		{dnsProto: configpb.DNSProto_TCP, wantErr: true},
+		{dnsProto: configpb.DNSProto_TCP_TLS},
+		{dnsProto: configpb.DNSProto_HTTPS},
+	} {
+		t.Run(test.dnsProto.String(), func(t *testing.T) {
+			p := &Probe{}
+			opts := &options.Options{
+				Targets:  targets.StaticTargets("8.8.8.8"),
+				Interval: 2 * time.Second,
+				Timeout:  time.Second,
+				ProbeConf: &configpb.ProbeConf{
+					DnsProto: test.dnsProto.Enum(),
+					TlsConfig: &tlsconfigpb.TLSConfig{
+						ServerName: proto.String("dns.example.com"),
+					},
+				},
+			}
+			err := p.Init("dns_tls_config_test", opts)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("got err: %v, want err: %v", err, test.wantErr)
+			}
+			if err != nil {
+				return
+			}
+
+			var tlsConfig *tls.Config
+			switch c := p.client.(type) {
+			case *clientImpl:
+				tlsConfig = c.TLSConfig
+			case *dohClient:
+				tlsConfig = c.transport.TLSClientConfig
+			}
+			if tlsConfig == nil || tlsConfig.ServerName != "dns.example.com" {
+				t.Errorf("TLS config not set correctly: %v", tlsConfig)
+			}
+		})
+	}
+}
+
+func TestPortAndProtoForTarget(t *testing.T) {
+	p := &Probe{}
+	opts := &options.Options{
+		Targets:  targets.StaticTargets("8.8.8.8"),
+		Interval: 2 * time.Second,
+		Timeout:  time.Second,
+		ProbeConf: &configpb.ProbeConf{
+			PortLabel:     proto.String("dns_port"),
+			DnsProtoLabel: proto.String("dns_proto"),
+		},
+	}
+	if err := p.Init("dns_port_proto_test", opts); err != nil {
+		t.Fatalf("Error creating probe: %v", err)
+	}
+
+	for _, test := range []struct {
+		desc       string
+		target     endpoint.Endpoint
+		configPort int32
+		wantProto  configpb.DNSProto
+		wantPort   int
+	}{
+		{
+			desc:      "default",
+			target:    endpoint.Endpoint{Name: "ns1"},
+			wantProto: configpb.DNSProto_UDP,
+			wantPort:  53,
+		},
+		{
+			desc:      "target_port",
+			target:    endpoint.Endpoint{Name: "ns1", Port: 5353},
+			wantProto: configpb.DNSProto_UDP,
+			wantPort:  5353,
+		},
+		{
+			desc:       "config_port",
+			target:     endpoint.Endpoint{Name: "ns1", Port: 5353},
+			configPort: 5300,
+			wantProto:  configpb.DNSProto_UDP,
+			wantPort:   5300,
+		},
+		{
+			desc:      "proto_label",
+			target:    endpoint.Endpoint{Name: "ns1", Labels: map[string]string{"dns_proto": "tcp_tls"}},
+			wantProto: configpb.DNSProto_TCP_TLS,
+			wantPort:  853,
+		},
+		{
+			desc:       "port_and_proto_labels",
+			target:     endpoint.Endpoint{Name: "ns1", Port: 5353, Labels: map[string]string{"dns_proto": "HTTPS", "dns_port": "8443"}},
+			configPort: 5300,
+			wantProto:  configpb.DNSProto_HTTPS,
+			wantPort:   8443,
+		},
+		{
+			desc:      "invalid_labels",
+			target:    endpoint.Endpoint{Name: "ns1", Labels: map[string]string{"dns_proto": "quic", "dns_port": "none"}},
+			wantProto: configpb.DNSProto_UDP,
+			wantPort:  53,
+		},
+	} {
+		t.Run(test.desc, func(t *testing.T) {
+			if test.configPort != 0 {
+				p.c.Port = proto.Int32(test.configPort)
+			} else {
+				p.c.Port = nil
+			}
+
+			dnsProto := p.dnsProtoForTarget(test.target)
+			if dnsProto != test.wantProto {
+				t.Errorf("dnsProtoForTarget: got %v, want %v", dnsProto, test.wantProto)
+			}
+			if port := p.portForTarget(test.target, dnsProto); port != test.wantPort {
+				t.Errorf("portForTarget: got %d, want %d", port, test.wantPort)
+			}
+
+			_, isDoH := p.clientForProto(dnsProto).(*dohClient)
+			if isDoH != (dnsProto == configpb.DNSProto_HTTPS) {
+				t.Errorf("clientForProto(%v): got DoH client: %v", dnsProto, isDoH)
+			}
+		})
+	}
+}
+
+func TestExpectNoAnswer(t *testing.T) {
+	for _, test := range []struct {
+		desc        string
+		c           *configpb.ProbeConf
+		wantSuccess int64
+		wantErr     bool
+	}{
+		{
+			desc:        "nxdomain",
+			c:           &configpb.ProbeConf{ResolvedDomain: proto.String(questionBadDomain)},
+			wantSuccess: 1,
+		},
+		{
+			desc:        "nodata",
+			c:           &configpb.ProbeConf{ResolvedDomain: proto.String(questionNoData)},
+			wantSuccess: 1,
+		},
+		{
+			desc:        "positive_answer",
+			c:           &configpb.ProbeConf{},
+			wantSuccess: 0,
+		},
+		{
+			desc:    "with_min_answers",
+			c:       &configpb.ProbeConf{MinAnswers: proto.Uint32(1)},
+			wantErr: true,
+		},
+		{
+			desc:    "with_expected_rcode",
+			c:       &configpb.ProbeConf{ExpectedRcode: configpb.Rcode_NXDOMAIN.Enum()},
+			wantErr: true,
+		},
+	} {
+		t.Run(test.desc, func(t *testing.T) {
+			test.c.ExpectNoAnswer = proto.Bool(true)
+			p := &Probe{}
+			opts := &options.Options{
+				Targets:   targets.StaticTargets("8.8.8.8"),
+				Interval:  2 * time.Second,
+				Timeout:   time.Second,
+				ProbeConf: test.c,
+			}
+			err := p.Init("dns_expect_no_answer_test", opts)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("got err: %v, want err: %v", err, test.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			runProbeAndVerify(t, test.desc, p, 1, test.wantSuccess)
+		})
+	}
+}
+
+func TestFqdnForTarget(t *testing.T) {
+	target := endpoint.Endpoint{
+		Name:   "ns1",
+		IP:     net.ParseIP("10.0.0.10"),
+		Labels: map[string]string{"service": "payments"},
+	}
+
+	for _, test := range []struct {
+		domain string
+		want   string
+	}{
+		{domain: "www.example.com", want: "www.example.com."},
+		{domain: "@target@.service.example.com.", want: "ns1.service.example.com."},
+		{domain: "@target.label.service@.example.com", want: "payments.example.com."},
+		{domain: "@probe@.@target.ip@.example.com", want: "dns_test.10.0.0.10.example.com."},
+		{domain: "@target.label.zone@.example.com", want: "@target.label.zone@.example.com."},
+	} {
+		t.Run(test.domain, func(t *testing.T) {
+			p := &Probe{
+				name: "dns_test",
+				fqdn: dns.Fqdn(test.domain),
+				l:    &logger.Logger{},
+			}
+			if got := p.fqdnForTarget(target, 53); got != test.want {
+				t.Errorf("fqdnForTarget() = %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestRecursionAndAuthoritative(t *testing.T) {
+	for _, test := range []struct {
+		desc        string
+		c           *configpb.ProbeConf
+		wantRD      bool
+		wantSuccess int64
+	}{
+		{
+			desc:        "default",
+			c:           &configpb.ProbeConf{},
+			wantRD:      true,
+			wantSuccess: 1,
+		},
+		{
+			desc: "authoritative",
+			c: &configpb.ProbeConf{
+				ResolvedDomain:      proto.String(questionAuthority),
+				RecursionDesired:    proto.Bool(false),
+				ExpectAuthoritative: proto.Bool(true),
+			},
+			wantSuccess: 1,
+		},
+		{
+			desc: "not_authoritative",
+			c: &configpb.ProbeConf{
+				RecursionDesired:    proto.Bool(false),
+				ExpectAuthoritative: proto.Bool(true),
+			},
+			wantSuccess: 0,
+		},
+	} {
+		t.Run(test.desc, func(t *testing.T) {
+			p := &Probe{}
+			opts := &options.Options{
+				Targets:   targets.StaticTargets("8.8.8.8"),
+				Interval:  2 * time.Second,
+				Timeout:   time.Second,
+				ProbeConf: test.c,
+			}
+			if err := p.Init("dns_authoritative_test", opts); err != nil {
+				t.Fatalf("Error creating probe: %v", err)
+			}
+
+			client := new(mockClient)
+			p.client = client
+			runReq := &sched.RunProbeForTargetRequest{Target: endpoint.Endpoint{Name: "8.8.8.8"}}
+			p.runProbe(context.Background(), runReq)
+
+			if client.recursionDesired.Load() != test.wantRD {
+				t.Errorf("RD bit: got %v, want %v", client.recursionDesired.Load(), test.wantRD)
+			}
+			if got := runReq.Result.(*probeRunResult).success.Int64(); got != test.wantSuccess {
+				t.Errorf("success: got %d, want %d", got, test.wantSuccess)
+			}
+		})
+	}
+}
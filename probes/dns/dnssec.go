@@ -0,0 +1,412 @@
+// Copyright 2025 The Cloudprober Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dns
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/miekg/dns"
+)
+
+// DNSSEC validation results.
+const (
+	dnssecSecure   = "secure"
+	dnssecInsecure = "insecure"
+	dnssecBogus    = "bogus"
+)
+
+// Root zone KSK-2017 (key tag 20326).
+const rootTrustAnchor = ". IN DS 20326 8 2 E06D44B80B8F1D39A95C0B0D7C65D08458E880409BBF683457104237C7F8EC8D"
+
+// Maximum number of zones to walk up while validating the chain of trust.
+const maxDNSSECChainLen = 16
+
+// parseTrustAnchors parses DS records in the zone file format.
+func parseTrustAnchors(anchors []string) ([]*dns.DS, error) {
+	if len(anchors) == 0 {
+		anchors = []string{rootTrustAnchor}
+	}
+
+	var dsRecords []*dns.DS
+	for _, s := range anchors {
+		rr, err := dns.NewRR(s)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trust anchor (%s): %v", s, err)
+		}
+		ds, ok := rr.(*dns.DS)
+		if !ok {
+			return nil, fmt.Errorf("trust anchor (%s) is not a DS record", s)
+		}
+		dsRecords = append(dsRecords, ds)
+	}
+	return dsRecords, nil
+}
+
+// rrsetKey identifies an RRset in a DNS message section.
+type rrsetKey struct {
+	name   string
+	rrtype uint16
+}
+
+// splitRRsets groups resource records into RRsets, and collects signatures
+// covering each RRset.
+func splitRRsets(rrs []dns.RR) (map[rrsetKey][]dns.RR, map[rrsetKey][]*dns.RRSIG) {
+	rrsets := make(map[rrsetKey][]dns.RR)
+	sigs := make(map[rrsetKey][]*dns.RRSIG)
+	for _, rr := range rrs {
+		name := strings.ToLower(rr.Header().Name)
+		if sig, ok := rr.(*dns.RRSIG); ok {
+			key := rrsetKey{name, sig.TypeCovered}
+			sigs[key] = append(sigs[key], sig)
+			continue
+		}
+		key := rrsetKey{name, rr.Header().Rrtype}
+		rrsets[key] = append(rrsets[key], rr)
+	}
+	return rrsets, sigs
+}
+
+// verifyRRset verifies that at least one of the signatures is valid for the
+// RRset, using one of the given keys.
+func verifyRRset(rrset []dns.RR, sigs []*dns.RRSIG, keys []*dns.DNSKEY, now time.Time) error {
+	if len(sigs) == 0 {
+		return errors.New("no signatures")
+	}
+
+	var errs []string
+	for _, sig := range sigs {
+		if !sig.ValidityPeriod(now) {
+			errs = append(errs, fmt.Sprintf("signature (key tag %d) expired or not yet valid (%s - %s)", sig.KeyTag, dns.TimeToString(sig.Inception), dns.TimeToString(sig.Expiration)))
+			continue
+		}
+		for _, key := range keys {
+			if key.KeyTag() != sig.KeyTag || key.Algorithm != sig.Algorithm {
+				continue
+			}
+			if err := sig.Verify(key, rrset); err != nil {
+				errs = append(errs, fmt.Sprintf("signature (key tag %d) verification error: %v", sig.KeyTag, err))
+				continue
+			}
+			return nil
+		}
+	}
+	if len(errs) == 0 {
+		return errors.New("no matching keys for signatures")
+	}
+	return errors.New(strings.Join(errs, "; "))
+}
+
+// keysMatchingDS returns the keys that match at least one of the DS records.
+func keysMatchingDS(keys []*dns.DNSKEY, dsRecords []*dns.DS) []*dns.DNSKEY {
+	var matched []*dns.DNSKEY
+	for _, key := range keys {
+		for _, ds := range dsRecords {
+			if key.KeyTag() != ds.KeyTag || key.Algorithm != ds.Algorithm {
+				continue
+			}
+			if kds := key.ToDS(ds.DigestType); kds != nil && strings.EqualFold(kds.Digest, ds.Digest) {
+				matched = append(matched, key)
+				break
+			}
+		}
+	}
+	return matched
+}
+
+// queryDNSSEC queries the target for the RRset of the given name and type,
+// along with its signatures. It also returns the response, e.g. to look for
+// the proof of non-existence in the authority section.
+func (p *Probe) queryDNSSEC(ctx context.Context, client Client, target, name string, qtype uint16) ([]dns.RR, []*dns.RRSIG, *dns.Msg, error) {
+	msg := new(dns.Msg)
+	msg.SetQuestion(name, qtype)
+	setDNSSECBits(msg)
+
+	resp, _, _, err := p.exchange(ctx, client, msg, target)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if resp.Rcode != dns.RcodeSuccess {
+		return nil, nil, nil, fmt.Errorf("%s query for %s failed: %s", dns.TypeToString[qtype], name, dns.RcodeToString[resp.Rcode])
+	}
+
+	rrsets, sigs := splitRRsets(resp.Answer)
+	key := rrsetKey{strings.ToLower(name), qtype}
+	return rrsets[key], sigs[key], resp, nil
+}
+
+func hasType(bitmap []uint16, t uint16) bool {
+	for _, bt := range bitmap {
+		if bt == t {
+			return true
+		}
+	}
+	return false
+}
+
+// dsDenialProof looks for the NSEC or NSEC3 records in the authority section
+// of a DS response that prove that the zone's DS records don't exist, i.e.
+// that it's an unsigned delegation. It returns the RRsets that make up the
+// proof; they still need to be validated.
+//
+// NSEC proof is an NSEC record for the zone name, without the DS bit. NSEC3
+// proof is either an NSEC3 record matching the zone name, without the DS
+// bit, or an opt-out NSEC3 record covering the next closer name, along with
+// an NSEC3 record matching the closest encloser (RFC 5155, section 8.6).
+func dsDenialProof(zone string, authority []dns.RR) []rrsetKey {
+	rrsets, _ := splitRRsets(authority)
+
+	var nsec3s []rrsetKey
+	for key, rrset := range rrsets {
+		switch key.rrtype {
+		case dns.TypeNSEC:
+			nsec := rrset[0].(*dns.NSEC)
+			if key.name == zone && hasType(nsec.TypeBitMap, dns.TypeNS) && !hasType(nsec.TypeBitMap, dns.TypeDS) {
+				return []rrsetKey{key}
+			}
+		case dns.TypeNSEC3:
+			nsec3s = append(nsec3s, key)
+		}
+	}
+
+	match := func(name string) (rrsetKey, *dns.NSEC3) {
+		for _, key := range nsec3s {
+			if nsec3 := rrsets[key][0].(*dns.NSEC3); nsec3.Match(name) {
+				return key, nsec3
+			}
+		}
+		return rrsetKey{}, nil
+	}
+
+	if key, nsec3 := match(zone); nsec3 != nil {
+		if hasType(nsec3.TypeBitMap, dns.TypeNS) && !hasType(nsec3.TypeBitMap, dns.TypeDS) {
+			return []rrsetKey{key}
+		}
+		return nil
+	}
+
+	// Opt-out: find the closest encloser, and the opt-out NSEC3 covering the
+	// next closer name.
+	labels := dns.SplitDomainName(zone)
+	for i := 1; i < len(labels); i++ {
+		ceKey, ce := match(dns.Fqdn(strings.Join(labels[i:], ".")))
+		if ce == nil {
+			continue
+		}
+		nextCloser := dns.Fqdn(strings.Join(labels[i-1:], "."))
+		for _, key := range nsec3s {
+			if nsec3 := rrsets[key][0].(*dns.NSEC3); nsec3.Flags&1 == 1 && nsec3.Cover(nextCloser) {
+				return []rrsetKey{ceKey, key}
+			}
+		}
+		return nil
+	}
+	return nil
+}
+
+// validateDSDenial validates that the zone's DS records don't exist, using the
+// NSEC/NSEC3 records in the DS response. Without a valid proof, a missing DS
+// could just be stripped by an attacker, so the result is bogus.
+func (p *Probe) validateDSDenial(ctx context.Context, client Client, target, zone string, resp *dns.Msg, depth int) (string, error) {
+	proof := dsDenialProof(zone, resp.Ns)
+	if len(proof) == 0 {
+		return dnssecBogus, fmt.Errorf("no DS records for %s in the parent zone, and no NSEC/NSEC3 proof of their absence", zone)
+	}
+
+	rrsets, sigs := splitRRsets(resp.Ns)
+	for _, key := range proof {
+		// Proof should come from the parent zone.
+		for _, sig := range sigs[key] {
+			if signer := dns.CanonicalName(sig.SignerName); signer == zone || !dns.IsSubDomain(signer, zone) {
+				return dnssecBogus, fmt.Errorf("%s/%s: DS denial proof for %s is not signed by the parent zone (%s)", key.name, dns.TypeToString[key.rrtype], zone, signer)
+			}
+		}
+		if status, err := p.validateChainDepth(ctx, client, target, rrsets[key], sigs[key], depth); status != dnssecSecure {
+			return dnssecBogus, fmt.Errorf("DS denial proof for %s is not valid: %v", zone, err)
+		}
+	}
+	return dnssecInsecure, fmt.Errorf("%s is an unsigned delegation (no DS records in the parent zone)", zone)
+}
+
+// setDNSSECBits sets the DO bit to get DNSSEC records in the response, and
+// the CD bit to get the records even if the resolver considers them bogus, so
+// that we can validate them ourselves.
+func setDNSSECBits(msg *dns.Msg) {
+	msg.SetEdns0(dns.DefaultMsgSize, true)
+	msg.CheckingDisabled = true
+}
+
+// validateChain validates the RRset's signatures, and then walks up the
+// chain of trust, validating DNSKEY and DS RRsets, until it finds a trust
+// anchor. If a zone has no DS records in its parent, their absence should be
+// proven by the parent zone, otherwise the result is bogus.
+func (p *Probe) validateChain(ctx context.Context, client Client, target string, rrset []dns.RR, sigs []*dns.RRSIG) (string, error) {
+	return p.validateChainDepth(ctx, client, target, rrset, sigs, 0)
+}
+
+// validateChainDepth implements validateChain. depth is the number of zones
+// already walked through, including the ones walked for the DS denial
+// proofs.
+func (p *Probe) validateChainDepth(ctx context.Context, client Client, target string, rrset []dns.RR, sigs []*dns.RRSIG, depth int) (string, error) {
+	now := time.Now()
+
+	for ; depth < maxDNSSECChainLen; depth++ {
+		name, rrtype := rrset[0].Header().Name, dns.TypeToString[rrset[0].Header().Rrtype]
+		if len(sigs) == 0 {
+			return dnssecBogus, fmt.Errorf("%s/%s: no signatures", name, rrtype)
+		}
+
+		zone := dns.CanonicalName(sigs[0].SignerName)
+		if !dns.IsSubDomain(zone, dns.CanonicalName(name)) {
+			return dnssecBogus, fmt.Errorf("%s/%s: signer %s is not an ancestor", name, rrtype, zone)
+		}
+
+		keyRRs, keySigs, _, err := p.queryDNSSEC(ctx, client, target, zone, dns.TypeDNSKEY)
+		if err != nil {
+			return dnssecBogus, err
+		}
+		var keys []*dns.DNSKEY
+		for _, rr := range keyRRs {
+			keys = append(keys, rr.(*dns.DNSKEY))
+		}
+		if len(keys) == 0 {
+			return dnssecBogus, fmt.Errorf("no DNSKEY records for %s", zone)
+		}
+
+		if err := verifyRRset(rrset, sigs, keys, now); err != nil {
+			return dnssecBogus, fmt.Errorf("%s/%s: %v", name, rrtype, err)
+		}
+
+		// DNSKEY RRset should be signed by a key that is either a trust
+		// anchor, or is referred to by a DS record in the parent zone.
+		var dsRecords []*dns.DS
+		for _, ds := range p.trustAnchors {
+			if dns.CanonicalName(ds.Hdr.Name) == zone {
+				dsRecords = append(dsRecords, ds)
+			}
+		}
+		anchored := len(dsRecords) > 0
+
+		var dsRRs []dns.RR
+		var dsSigs []*dns.RRSIG
+		if !anchored {
+			if zone == "." {
+				return dnssecBogus, errors.New("no trust anchor for the root zone")
+			}
+			var dsResp *dns.Msg
+			dsRRs, dsSigs, dsResp, err = p.queryDNSSEC(ctx, client, target, zone, dns.TypeDS)
+			if err != nil {
+				return dnssecBogus, err
+			}
+			if len(dsRRs) == 0 {
+				return p.validateDSDenial(ctx, client, target, zone, dsResp, depth+1)
+			}
+			for _, rr := range dsRRs {
+				dsRecords = append(dsRecords, rr.(*dns.DS))
+			}
+		}
+
+		trustedKeys := keysMatchingDS(keys, dsRecords)
+		if len(trustedKeys) == 0 {
+			return dnssecBogus, fmt.Errorf("no DNSKEY for %s matches its DS records", zone)
+		}
+		if err := verifyRRset(keyRRs, keySigs, trustedKeys, now); err != nil {
+			return dnssecBogus, fmt.Errorf("%s/DNSKEY: %v", zone, err)
+		}
+
+		if anchored {
+			return dnssecSecure, nil
+		}
+		rrset, sigs = dsRRs, dsSigs
+	}
+
+	return dnssecBogus, errors.New("chain of trust too long")
+}
+
+// unsignedZoneStatus checks that the name is in an unsigned zone, i.e. that
+// the name or one of its ancestors is an unsigned delegation, proven by the
+// NSEC/NSEC3 records in its parent zone. It's used for the answers without
+// signatures: without such a proof, signatures could just be stripped by an
+// attacker, so the result is bogus.
+func (p *Probe) unsignedZoneStatus(ctx context.Context, client Client, target, name string) (string, error) {
+	labels := dns.SplitDomainName(name)
+	for i := range labels {
+		zone := dns.Fqdn(strings.ToLower(strings.Join(labels[i:], ".")))
+
+		msg := new(dns.Msg)
+		msg.SetQuestion(zone, dns.TypeDS)
+		setDNSSECBits(msg)
+		resp, _, _, err := p.exchange(ctx, client, msg, target)
+		if err != nil {
+			return dnssecBogus, err
+		}
+		// Name may not exist, e.g. for an NXDOMAIN response, look further up.
+		if resp.Rcode != dns.RcodeSuccess && resp.Rcode != dns.RcodeNameError {
+			return dnssecBogus, fmt.Errorf("DS query for %s failed: %s", zone, dns.RcodeToString[resp.Rcode])
+		}
+
+		if rrsets, _ := splitRRsets(resp.Answer); len(rrsets[rrsetKey{zone, dns.TypeDS}]) > 0 {
+			return dnssecBogus, fmt.Errorf("%s is in a signed zone (%s has DS records), but the answer is not signed", name, zone)
+		}
+		// Not a zone cut, or no proof of the DS absence, look further up.
+		if len(dsDenialProof(zone, resp.Ns)) == 0 {
+			continue
+		}
+		return p.validateDSDenial(ctx, client, target, zone, resp, 0)
+	}
+	return dnssecBogus, fmt.Errorf("answer for %s is not signed, and there is no proof that it's in an unsigned zone", name)
+}
+
+// dnssecStatus validates DNSSEC signatures of the answer section of the
+// response, and returns one of secure, insecure or bogus status, along with
+// the reason if the status is not secure. Unsigned (or empty) answers are
+// insecure only if the name is proven to be in an unsigned zone.
+func (p *Probe) dnssecStatus(ctx context.Context, client Client, target string, resp *dns.Msg) (string, error) {
+	rrsets, sigs := splitRRsets(resp.Answer)
+	if len(rrsets) == 0 {
+		if len(resp.Question) == 0 {
+			return dnssecBogus, errors.New("no answers to validate")
+		}
+		status, err := p.unsignedZoneStatus(ctx, client, target, resp.Question[0].Name)
+		return status, fmt.Errorf("no answers to validate: %v", err)
+	}
+
+	var insecureErr error
+	for key, rrset := range rrsets {
+		if len(sigs[key]) == 0 {
+			status, err := p.unsignedZoneStatus(ctx, client, target, key.name)
+			if status == dnssecBogus {
+				return status, fmt.Errorf("%s/%s: %v", key.name, dns.TypeToString[key.rrtype], err)
+			}
+			insecureErr = fmt.Errorf("%s/%s: not signed: %v", key.name, dns.TypeToString[key.rrtype], err)
+			continue
+		}
+		status, err := p.validateChain(ctx, client, target, rrset, sigs[key])
+		switch status {
+		case dnssecBogus:
+			return status, err
+		case dnssecInsecure:
+			insecureErr = err
+		}
+	}
+
+	if insecureErr != nil {
+		return dnssecInsecure, insecureErr
+	}
+	return dnssecSecure, nil
+}
@@ -0,0 +1,246 @@
+// Copyright 2025 The Cloudprober Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dns
+
+import (
+	"context"
+	"crypto"
+	"crypto/tls"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	configpb "github.com/cloudprober/cloudprober/probes/dns/proto"
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/assert"
+)
+
+// dnssecTestClient answers queries from a fixed set of records. Authority
+// records are returned for the queries without answers.
+type dnssecTestClient struct {
+	records   map[rrsetKey][]dns.RR
+	authority map[rrsetKey][]dns.RR
+}
+
+func (c *dnssecTestClient) ExchangeContext(_ context.Context, in *dns.Msg, _ string) (*dns.Msg, time.Duration, error) {
+	out := new(dns.Msg)
+	out.SetReply(in)
+	q := in.Question[0]
+	key := rrsetKey{strings.ToLower(q.Name), q.Qtype}
+	out.Answer = c.records[key]
+	if len(out.Answer) == 0 {
+		out.Ns = c.authority[key]
+	}
+	return out, time.Millisecond, nil
+}
+func (*dnssecTestClient) setTimeout(time.Duration)      {}
+func (*dnssecTestClient) setSourceIP(net.IP)            {}
+func (*dnssecTestClient) setDNSProto(configpb.DNSProto) {}
+func (*dnssecTestClient) setTLSConfig(*tls.Config)      {}
+
+type testZoneKey struct {
+	key  *dns.DNSKEY
+	priv crypto.Signer
+}
+
+func newTestZoneKey(t *testing.T, zone string) *testZoneKey {
+	t.Helper()
+	key := &dns.DNSKEY{
+		Hdr:       dns.RR_Header{Name: zone, Rrtype: dns.TypeDNSKEY, Class: dns.ClassINET, Ttl: 3600},
+		Flags:     257,
+		Protocol:  3,
+		Algorithm: dns.ECDSAP256SHA256,
+	}
+	priv, err := key.Generate(256)
+	if err != nil {
+		t.Fatalf("Error generating key for %s: %v", zone, err)
+	}
+	return &testZoneKey{key: key, priv: priv.(crypto.Signer)}
+}
+
+func (zk *testZoneKey) sign(t *testing.T, rrset []dns.RR, validFor time.Duration) dns.RR {
+	t.Helper()
+	now := time.Now()
+	sig := &dns.RRSIG{
+		Hdr:        dns.RR_Header{Name: rrset[0].Header().Name, Rrtype: dns.TypeRRSIG, Class: dns.ClassINET, Ttl: 3600},
+		KeyTag:     zk.key.KeyTag(),
+		SignerName: zk.key.Hdr.Name,
+		Algorithm:  zk.key.Algorithm,
+		Inception:  uint32(now.Add(-2 * time.Hour).Unix()),
+		Expiration: uint32(now.Add(validFor).Unix()),
+	}
+	if err := sig.Sign(zk.priv, rrset); err != nil {
+		t.Fatalf("Error signing RRset: %v", err)
+	}
+	return sig
+}
+
+type testDNSSECZone struct {
+	noDS      bool
+	expiredA  bool
+	unsignedA bool
+	noA       bool
+
+	// Proof of the DS non-existence, if noDS is set: "nsec", "nsec3",
+	// "nsec3_opt_out", "nsec_with_ds" (NSEC that doesn't deny DS) or
+	// "child_nsec" (NSEC signed by the child zone instead of the parent).
+	dsDenial string
+}
+
+// setup creates a signed root zone, a signed com. zone delegated from it, and
+// a signed example.com. zone delegated from com., and returns the test client
+// along with the root trust anchor.
+func (tz testDNSSECZone) setup(t *testing.T) (*dnssecTestClient, *dns.DS) {
+	rootKey := newTestZoneKey(t, ".")
+	comKey := newTestZoneKey(t, "com.")
+	zoneKey := newTestZoneKey(t, "example.com.")
+	c := &dnssecTestClient{
+		records:   make(map[rrsetKey][]dns.RR),
+		authority: make(map[rrsetKey][]dns.RR),
+	}
+
+	add := func(rrset []dns.RR, signer *testZoneKey, validFor time.Duration) {
+		key := rrsetKey{rrset[0].Header().Name, rrset[0].Header().Rrtype}
+		c.records[key] = append([]dns.RR{}, rrset...)
+		if signer != nil {
+			c.records[key] = append(c.records[key], signer.sign(t, rrset, validFor))
+		}
+	}
+
+	add([]dns.RR{rootKey.key}, rootKey, time.Hour)
+	add([]dns.RR{comKey.key}, comKey, time.Hour)
+	add([]dns.RR{comKey.key.ToDS(dns.SHA256)}, rootKey, time.Hour)
+	add([]dns.RR{zoneKey.key}, zoneKey, time.Hour)
+	if !tz.noDS {
+		add([]dns.RR{zoneKey.key.ToDS(dns.SHA256)}, comKey, time.Hour)
+	}
+
+	// Proof of the DS non-existence, in the authority section of the DS
+	// response.
+	dsKey := rrsetKey{"example.com.", dns.TypeDS}
+	addDenial := func(rrs []dns.RR, signer *testZoneKey) {
+		c.authority[dsKey] = append(c.authority[dsKey], rrs...)
+		c.authority[dsKey] = append(c.authority[dsKey], signer.sign(t, rrs, time.Hour))
+	}
+	nsec3 := func(name string, flags uint8, bitmap []uint16) *dns.NSEC3 {
+		return &dns.NSEC3{
+			Hdr:        dns.RR_Header{Name: dns.HashName(name, dns.SHA1, 0, "") + ".com.", Rrtype: dns.TypeNSEC3, Class: dns.ClassINET, Ttl: 3600},
+			Hash:       dns.SHA1,
+			Flags:      flags,
+			NextDomain: strings.Repeat("V", 32), // Last possible hash.
+			TypeBitMap: bitmap,
+		}
+	}
+	switch tz.dsDenial {
+	case "nsec", "nsec_with_ds", "child_nsec":
+		bitmap := []uint16{dns.TypeNS, dns.TypeRRSIG, dns.TypeNSEC}
+		if tz.dsDenial == "nsec_with_ds" {
+			bitmap = append(bitmap, dns.TypeDS)
+		}
+		signer := comKey
+		if tz.dsDenial == "child_nsec" {
+			signer = zoneKey
+		}
+		addDenial([]dns.RR{&dns.NSEC{
+			Hdr:        dns.RR_Header{Name: "example.com.", Rrtype: dns.TypeNSEC, Class: dns.ClassINET, Ttl: 3600},
+			NextDomain: "example2.com.",
+			TypeBitMap: bitmap,
+		}}, signer)
+	case "nsec3":
+		addDenial([]dns.RR{nsec3("example.com.", 0, []uint16{dns.TypeNS})}, comKey)
+	case "nsec3_opt_out":
+		// Closest encloser is com., and the next closer name (example.com.)
+		// is covered by an opt-out NSEC3.
+		addDenial([]dns.RR{nsec3("com.", 0, []uint16{dns.TypeNS, dns.TypeSOA, dns.TypeDNSKEY})}, comKey)
+		optOut := nsec3("example.com.", 1, []uint16{dns.TypeNS})
+		optOut.Hdr.Name = strings.Repeat("0", 32) + ".com."
+		addDenial([]dns.RR{optOut}, comKey)
+	}
+
+	a, _ := dns.NewRR("www.example.com. 300 IN A 192.168.0.1")
+	switch {
+	case tz.noA:
+		// Empty answer, e.g. NODATA.
+	case tz.unsignedA:
+		add([]dns.RR{a}, nil, 0)
+	case tz.expiredA:
+		add([]dns.RR{a}, zoneKey, -time.Hour)
+	default:
+		add([]dns.RR{a}, zoneKey, time.Hour)
+	}
+
+	return c, rootKey.key.ToDS(dns.SHA256)
+}
+
+func TestDNSSECStatus(t *testing.T) {
+	for _, test := range []struct {
+		desc       string
+		zone       testDNSSECZone
+		badAnchor  bool
+		wantStatus string
+	}{
+		{desc: "secure", wantStatus: dnssecSecure},
+		{desc: "expired_rrsig", zone: testDNSSECZone{expiredA: true}, wantStatus: dnssecBogus},
+		{desc: "unsigned_answer", zone: testDNSSECZone{unsignedA: true}, wantStatus: dnssecBogus},
+		{desc: "unsigned_answer_unsigned_zone", zone: testDNSSECZone{unsignedA: true, noDS: true, dsDenial: "nsec"}, wantStatus: dnssecInsecure},
+		{desc: "unsigned_answer_no_proof", zone: testDNSSECZone{unsignedA: true, noDS: true}, wantStatus: dnssecBogus},
+		{desc: "empty_answer", zone: testDNSSECZone{noA: true}, wantStatus: dnssecBogus},
+		{desc: "empty_answer_unsigned_zone", zone: testDNSSECZone{noA: true, noDS: true, dsDenial: "nsec3"}, wantStatus: dnssecInsecure},
+		{desc: "no_ds_no_proof", zone: testDNSSECZone{noDS: true}, wantStatus: dnssecBogus},
+		{desc: "no_ds_nsec", zone: testDNSSECZone{noDS: true, dsDenial: "nsec"}, wantStatus: dnssecInsecure},
+		{desc: "no_ds_nsec3", zone: testDNSSECZone{noDS: true, dsDenial: "nsec3"}, wantStatus: dnssecInsecure},
+		{desc: "no_ds_nsec3_opt_out", zone: testDNSSECZone{noDS: true, dsDenial: "nsec3_opt_out"}, wantStatus: dnssecInsecure},
+		{desc: "no_ds_nsec_with_ds", zone: testDNSSECZone{noDS: true, dsDenial: "nsec_with_ds"}, wantStatus: dnssecBogus},
+		{desc: "no_ds_child_nsec", zone: testDNSSECZone{noDS: true, dsDenial: "child_nsec"}, wantStatus: dnssecBogus},
+		{desc: "bad_trust_anchor", badAnchor: true, wantStatus: dnssecBogus},
+	} {
+		t.Run(test.desc, func(t *testing.T) {
+			client, anchor := test.zone.setup(t)
+			if test.badAnchor {
+				anchor = newTestZoneKey(t, ".").key.ToDS(dns.SHA256)
+			}
+			p := &Probe{
+				c:            &configpb.ProbeConf{},
+				trustAnchors: []*dns.DS{anchor},
+			}
+
+			msg := new(dns.Msg)
+			msg.SetQuestion("www.example.com.", dns.TypeA)
+			resp, _, _ := client.ExchangeContext(context.Background(), msg, "")
+
+			status, err := p.dnssecStatus(context.Background(), client, "", resp)
+			assert.Equal(t, test.wantStatus, status, "error: %v", err)
+			if test.wantStatus == dnssecSecure {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+			}
+		})
+	}
+}
+
+func TestParseTrustAnchors(t *testing.T) {
+	anchors, err := parseTrustAnchors(nil)
+	assert.NoError(t, err)
+	assert.Len(t, anchors, 1)
+	assert.Equal(t, uint16(20326), anchors[0].KeyTag)
+
+	_, err = parseTrustAnchors([]string{"example.com. IN A 192.168.0.1"})
+	assert.Error(t, err, "not a DS record")
+
+	_, err = parseTrustAnchors([]string{"invalid"})
+	assert.Error(t, err)
+}
@@ -0,0 +1,109 @@
+// Copyright 2025 The Cloudprober Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dns
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+
+	configpb "github.com/cloudprober/cloudprober/probes/dns/proto"
+	"github.com/miekg/dns"
+)
+
+const dohMediaType = "application/dns-message"
+
+// dohClient is a DNS over HTTPS (RFC 8484) client.
+type dohClient struct {
+	client    *http.Client
+	transport *http.Transport
+	path      string
+}
+
+func newDoHClient(path string) *dohClient {
+	// Similar to other DNS transports, we use a new connection for every
+	// query, so that each query exercises the full path.
+	transport := &http.Transport{
+		DialContext:       (&net.Dialer{}).DialContext,
+		DisableKeepAlives: true,
+		ForceAttemptHTTP2: true,
+		TLSClientConfig:   &tls.Config{},
+	}
+	return &dohClient{
+		client:    &http.Client{Transport: transport},
+		transport: transport,
+		path:      path,
+	}
+}
+
+// ExchangeContext sends the DNS message to the address (host:port) as an
+// HTTP POST request, and returns the DNS response.
+func (c *dohClient) ExchangeContext(ctx context.Context, m *dns.Msg, address string) (*dns.Msg, time.Duration, error) {
+	buf, err := m.Pack()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	u := url.URL{Scheme: "https", Host: address, Path: c.path}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u.String(), bytes.NewReader(buf))
+	if err != nil {
+		return nil, 0, err
+	}
+	req.Header.Set("Content-Type", dohMediaType)
+	req.Header.Set("Accept", dohMediaType)
+
+	start := time.Now()
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, dns.MaxMsgSize))
+	rtt := time.Since(start)
+	if err != nil {
+		return nil, rtt, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, rtt, fmt.Errorf("DoH request failed with status: %s", resp.Status)
+	}
+
+	r := new(dns.Msg)
+	if err := r.Unpack(body); err != nil {
+		return nil, rtt, fmt.Errorf("error parsing DoH response: %v", err)
+	}
+	return r, rtt, nil
+}
+
+func (c *dohClient) setTimeout(d time.Duration) {
+	c.client.Timeout = d
+}
+
+func (c *dohClient) setSourceIP(ip net.IP) {
+	c.transport.DialContext = (&net.Dialer{LocalAddr: &net.TCPAddr{IP: ip}}).DialContext
+}
+
+// setDNSProto is a no-op, DoH client always uses HTTPS.
+func (c *dohClient) setDNSProto(configpb.DNSProto) {}
+
+func (c *dohClient) setTLSConfig(tlsConfig *tls.Config) {
+	c.transport.TLSClientConfig = tlsConfig
+}
@@ -0,0 +1,87 @@
+// Copyright 2025 The Cloudprober Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dns
+
+import (
+	"context"
+	"crypto/tls"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/assert"
+)
+
+func testDoHHandler(t *testing.T) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/dns-query" {
+			http.NotFound(w, r)
+			return
+		}
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, dohMediaType, r.Header.Get("Content-Type"))
+
+		body, _ := io.ReadAll(r.Body)
+		req := new(dns.Msg)
+		if err := req.Unpack(body); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		resp := new(dns.Msg)
+		resp.SetReply(req)
+		rr, _ := dns.NewRR(req.Question[0].Name + " 300 IN A 192.168.0.1")
+		resp.Answer = []dns.RR{rr}
+		buf, _ := resp.Pack()
+
+		w.Header().Set("Content-Type", dohMediaType)
+		w.Write(buf)
+	}
+}
+
+func TestDoHClient(t *testing.T) {
+	ts := httptest.NewTLSServer(testDoHHandler(t))
+	defer ts.Close()
+
+	for _, test := range []struct {
+		path    string
+		wantErr bool
+	}{
+		{path: "/dns-query"},
+		{path: "/resolve", wantErr: true},
+	} {
+		t.Run(test.path, func(t *testing.T) {
+			c := newDoHClient(test.path)
+			c.setTimeout(time.Second)
+			c.setTLSConfig(&tls.Config{InsecureSkipVerify: true})
+
+			msg := new(dns.Msg)
+			msg.SetQuestion("www.example.com.", dns.TypeA)
+
+			resp, _, err := c.ExchangeContext(context.Background(), msg, ts.Listener.Addr().String())
+			if test.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, msg.Id, resp.Id)
+			assert.Len(t, resp.Answer, 1)
+			assert.Equal(t, "192.168.0.1", rrData(resp.Answer[0]))
+		})
+	}
+}
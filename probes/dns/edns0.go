@@ -0,0 +1,102 @@
+// Copyright 2025 The Cloudprober Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dns
+
+import (
+	"encoding/hex"
+	"fmt"
+	"math"
+	"net"
+
+	"github.com/miekg/dns"
+)
+
+// Minimum EDNS0 UDP buffer size, smaller values are treated as 512 by
+// servers (RFC 6891).
+const minEDNS0UDPSize = 512
+
+// parseClientSubnet parses a client subnet in the CIDR notation into an EDNS0
+// client subnet option.
+func parseClientSubnet(s string) (*dns.EDNS0_SUBNET, error) {
+	ip, ipNet, err := net.ParseCIDR(s)
+	if err != nil {
+		return nil, err
+	}
+	ones, _ := ipNet.Mask.Size()
+
+	subnet := &dns.EDNS0_SUBNET{
+		Code:          dns.EDNS0SUBNET,
+		SourceNetmask: uint8(ones),
+	}
+	if ip.To4() != nil {
+		subnet.Family = 1
+		subnet.Address = ipNet.IP.To4()
+	} else {
+		subnet.Family = 2
+		subnet.Address = ipNet.IP
+	}
+	return subnet, nil
+}
+
+// initEDNS0 parses EDNS0 options from the config.
+func (p *Probe) initEDNS0() error {
+	if p.c.Edns0UdpSize != nil {
+		size := p.c.GetEdns0UdpSize()
+		if size < minEDNS0UDPSize || size > dns.MaxMsgSize {
+			return fmt.Errorf("edns0_udp_size (%d) should be between %d and %d", size, minEDNS0UDPSize, dns.MaxMsgSize)
+		}
+	}
+
+	if p.c.GetEdns0ClientSubnet() != "" {
+		subnet, err := parseClientSubnet(p.c.GetEdns0ClientSubnet())
+		if err != nil {
+			return fmt.Errorf("invalid edns0_client_subnet: %v", err)
+		}
+		p.ednsOptions = append(p.ednsOptions, subnet)
+	}
+
+	for _, opt := range p.c.GetEdns0Option() {
+		if opt.GetCode() > math.MaxUint16 {
+			return fmt.Errorf("invalid EDNS0 option code (%d), should be <= %d", opt.GetCode(), math.MaxUint16)
+		}
+		data, err := hex.DecodeString(opt.GetDataHex())
+		if err != nil {
+			return fmt.Errorf("invalid data_hex for EDNS0 option %d: %v", opt.GetCode(), err)
+		}
+		p.ednsOptions = append(p.ednsOptions, &dns.EDNS0_LOCAL{Code: uint16(opt.GetCode()), Data: data})
+	}
+
+	return nil
+}
+
+// setEDNS0 adds an EDNS0 OPT record to the query if it's required by the
+// config.
+func (p *Probe) setEDNS0(msg *dns.Msg) {
+	if p.c.Edns0UdpSize == nil && len(p.ednsOptions) == 0 && !p.c.GetDnssecValidation() {
+		return
+	}
+
+	udpSize := uint16(dns.DefaultMsgSize)
+	if p.c.Edns0UdpSize != nil {
+		udpSize = uint16(p.c.GetEdns0UdpSize())
+	}
+	msg.SetEdns0(udpSize, p.c.GetDnssecValidation())
+	opt := msg.IsEdns0()
+	opt.Option = append(opt.Option, p.ednsOptions...)
+
+	if p.c.GetDnssecValidation() {
+		msg.CheckingDisabled = true
+	}
+}
@@ -0,0 +1,149 @@
+// Copyright 2025 The Cloudprober Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dns
+
+import (
+	"net"
+	"testing"
+
+	configpb "github.com/cloudprober/cloudprober/probes/dns/proto"
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestParseClientSubnet(t *testing.T) {
+	for _, test := range []struct {
+		subnet     string
+		wantFamily uint16
+		wantMask   uint8
+		wantAddr   string
+		wantErr    bool
+	}{
+		{subnet: "203.0.113.10/24", wantFamily: 1, wantMask: 24, wantAddr: "203.0.113.0"},
+		{subnet: "2001:db8::1/56", wantFamily: 2, wantMask: 56, wantAddr: "2001:db8::"},
+		{subnet: "203.0.113.10", wantErr: true},
+	} {
+		t.Run(test.subnet, func(t *testing.T) {
+			subnet, err := parseClientSubnet(test.subnet)
+			if test.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, test.wantFamily, subnet.Family)
+			assert.Equal(t, test.wantMask, subnet.SourceNetmask)
+			assert.True(t, net.ParseIP(test.wantAddr).Equal(subnet.Address), "address: %v", subnet.Address)
+		})
+	}
+}
+
+func TestSetEDNS0(t *testing.T) {
+	for _, test := range []struct {
+		desc        string
+		c           *configpb.ProbeConf
+		wantOPT     bool
+		wantUDPSize uint16
+		wantDO      bool
+		wantOptions []uint16
+		wantErr     bool
+	}{
+		{
+			desc: "no_edns0",
+			c:    &configpb.ProbeConf{},
+		},
+		{
+			desc:        "udp_size",
+			c:           &configpb.ProbeConf{Edns0UdpSize: proto.Uint32(1232)},
+			wantOPT:     true,
+			wantUDPSize: 1232,
+		},
+		{
+			desc: "client_subnet_and_option",
+			c: &configpb.ProbeConf{
+				Edns0ClientSubnet: proto.String("203.0.113.0/24"),
+				Edns0Option: []*configpb.EDNS0Option{
+					{Code: proto.Uint32(65001), DataHex: proto.String("cafe")},
+				},
+			},
+			wantOPT:     true,
+			wantUDPSize: dns.DefaultMsgSize,
+			wantOptions: []uint16{dns.EDNS0SUBNET, 65001},
+		},
+		{
+			desc:        "dnssec",
+			c:           &configpb.ProbeConf{DnssecValidation: proto.Bool(true), Edns0UdpSize: proto.Uint32(1400)},
+			wantOPT:     true,
+			wantUDPSize: 1400,
+			wantDO:      true,
+		},
+		{
+			desc:    "udp_size_too_small",
+			c:       &configpb.ProbeConf{Edns0UdpSize: proto.Uint32(100)},
+			wantErr: true,
+		},
+		{
+			desc:    "invalid_subnet",
+			c:       &configpb.ProbeConf{Edns0ClientSubnet: proto.String("203.0.113.0")},
+			wantErr: true,
+		},
+		{
+			desc: "invalid_option_data",
+			c: &configpb.ProbeConf{
+				Edns0Option: []*configpb.EDNS0Option{{Code: proto.Uint32(65001), DataHex: proto.String("xyz")}},
+			},
+			wantErr: true,
+		},
+		{
+			desc: "invalid_option_code",
+			c: &configpb.ProbeConf{
+				Edns0Option: []*configpb.EDNS0Option{{Code: proto.Uint32(65536), DataHex: proto.String("cafe")}},
+			},
+			wantErr: true,
+		},
+	} {
+		t.Run(test.desc, func(t *testing.T) {
+			p := &Probe{c: test.c}
+			err := p.initEDNS0()
+			if test.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+
+			msg := new(dns.Msg)
+			msg.SetQuestion("www.example.com.", dns.TypeA)
+			p.setEDNS0(msg)
+
+			opt := msg.IsEdns0()
+			if !test.wantOPT {
+				assert.Nil(t, opt)
+				return
+			}
+			if !assert.NotNil(t, opt) {
+				return
+			}
+			assert.Equal(t, test.wantUDPSize, opt.UDPSize())
+			assert.Equal(t, test.wantDO, opt.Do())
+			assert.Equal(t, test.wantDO, msg.CheckingDisabled)
+
+			var codes []uint16
+			for _, o := range opt.Option {
+				codes = append(codes, o.Option())
+			}
+			assert.Equal(t, test.wantOptions, codes)
+		})
+	}
+}
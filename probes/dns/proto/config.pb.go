@@ -7,6 +7,7 @@
 package proto
 
 import (
+	proto "github.com/cloudprober/cloudprober/common/tlsconfig/proto"
 	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
 	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
 	reflect "reflect"
@@ -61,6 +62,7 @@ const (
 	QueryType_OPENPGPKEY QueryType = 61
 	QueryType_TKEY       QueryType = 249
 	QueryType_TSIG       QueryType = 250
+	QueryType_ANY        QueryType = 255
 	QueryType_URI        QueryType = 256
 	QueryType_CAA        QueryType = 257
 	QueryType_TA         QueryType = 32768
@@ -106,6 +108,7 @@ var (
 		61:    "OPENPGPKEY",
 		249:   "TKEY",
 		250:   "TSIG",
+		255:   "ANY",
 		256:   "URI",
 		257:   "CAA",
 		32768: "TA",
@@ -148,6 +151,7 @@ var (
 		"OPENPGPKEY": 61,
 		"TKEY":       249,
 		"TSIG":       250,
+		"ANY":        255,
 		"URI":        256,
 		"CAA":        257,
 		"TA":         32768,
@@ -249,12 +253,84 @@ func (QueryClass) EnumDescriptor() ([]byte, []int) {
 	return file_github_com_cloudprober_cloudprober_probes_dns_proto_config_proto_rawDescGZIP(), []int{1}
 }
 
+// DNS response codes https://datatracker.ietf.org/doc/html/rfc1035#section-4.1.1
+type Rcode int32
+
+const (
+	Rcode_NOERROR  Rcode = 0
+	Rcode_FORMERR  Rcode = 1
+	Rcode_SERVFAIL Rcode = 2
+	Rcode_NXDOMAIN Rcode = 3
+	Rcode_NOTIMP   Rcode = 4
+	Rcode_REFUSED  Rcode = 5
+)
+
+// Enum value maps for Rcode.
+var (
+	Rcode_name = map[int32]string{
+		0: "NOERROR",
+		1: "FORMERR",
+		2: "SERVFAIL",
+		3: "NXDOMAIN",
+		4: "NOTIMP",
+		5: "REFUSED",
+	}
+	Rcode_value = map[string]int32{
+		"NOERROR":  0,
+		"FORMERR":  1,
+		"SERVFAIL": 2,
+		"NXDOMAIN": 3,
+		"NOTIMP":   4,
+		"REFUSED":  5,
+	}
+)
+
+func (x Rcode) Enum() *Rcode {
+	p := new(Rcode)
+	*p = x
+	return p
+}
+
+func (x Rcode) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (Rcode) Descriptor() protoreflect.EnumDescriptor {
+	return file_github_com_cloudprober_cloudprober_probes_dns_proto_config_proto_enumTypes[2].Descriptor()
+}
+
+func (Rcode) Type() protoreflect.EnumType {
+	return &file_github_com_cloudprober_cloudprober_probes_dns_proto_config_proto_enumTypes[2]
+}
+
+func (x Rcode) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Do not use.
+func (x *Rcode) UnmarshalJSON(b []byte) error {
+	num, err := protoimpl.X.UnmarshalJSONEnum(x.Descriptor(), b)
+	if err != nil {
+		return err
+	}
+	*x = Rcode(num)
+	return nil
+}
+
+// Deprecated: Use Rcode.Descriptor instead.
+func (Rcode) EnumDescriptor() ([]byte, []int) {
+	return file_github_com_cloudprober_cloudprober_probes_dns_proto_config_proto_rawDescGZIP(), []int{2}
+}
+
 type DNSProto int32
 
 const (
-	DNSProto_UDP     DNSProto = 0
-	DNSProto_TCP     DNSProto = 1
+	DNSProto_UDP DNSProto = 0
+	DNSProto_TCP DNSProto = 1
+	// DNS over TLS (RFC 7858). Default port: 853.
 	DNSProto_TCP_TLS DNSProto = 2
+	// DNS over HTTPS (RFC 8484). Default port: 443.
+	DNSProto_HTTPS DNSProto = 3
 )
 
 // Enum value maps for DNSProto.
@@ -263,11 +339,13 @@ var (
 		0: "UDP",
 		1: "TCP",
 		2: "TCP_TLS",
+		3: "HTTPS",
 	}
 	DNSProto_value = map[string]int32{
 		"UDP":     0,
 		"TCP":     1,
 		"TCP_TLS": 2,
+		"HTTPS":   3,
 	}
 )
 
@@ -282,11 +360,11 @@ func (x DNSProto) String() string {
 }
 
 func (DNSProto) Descriptor() protoreflect.EnumDescriptor {
-	return file_github_com_cloudprober_cloudprober_probes_dns_proto_config_proto_enumTypes[2].Descriptor()
+	return file_github_com_cloudprober_cloudprober_probes_dns_proto_config_proto_enumTypes[3].Descriptor()
 }
 
 func (DNSProto) Type() protoreflect.EnumType {
-	return &file_github_com_cloudprober_cloudprober_probes_dns_proto_config_proto_enumTypes[2]
+	return &file_github_com_cloudprober_cloudprober_probes_dns_proto_config_proto_enumTypes[3]
 }
 
 func (x DNSProto) Number() protoreflect.EnumNumber {
@@ -305,14 +383,74 @@ func (x *DNSProto) UnmarshalJSON(b []byte) error {
 
 // Deprecated: Use DNSProto.Descriptor instead.
 func (DNSProto) EnumDescriptor() ([]byte, []int) {
-	return file_github_com_cloudprober_cloudprober_probes_dns_proto_config_proto_rawDescGZIP(), []int{2}
+	return file_github_com_cloudprober_cloudprober_probes_dns_proto_config_proto_rawDescGZIP(), []int{3}
+}
+
+// Generic EDNS0 option (RFC 6891).
+type EDNS0Option struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Option code (0-65535), e.g. 10 for DNS cookie.
+	Code *uint32 `protobuf:"varint,1,req,name=code" json:"code,omitempty"`
+	// Option data as a hex string.
+	DataHex       *string `protobuf:"bytes,2,opt,name=data_hex,json=dataHex" json:"data_hex,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *EDNS0Option) Reset() {
+	*x = EDNS0Option{}
+	mi := &file_github_com_cloudprober_cloudprober_probes_dns_proto_config_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *EDNS0Option) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EDNS0Option) ProtoMessage() {}
+
+func (x *EDNS0Option) ProtoReflect() protoreflect.Message {
+	mi := &file_github_com_cloudprober_cloudprober_probes_dns_proto_config_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EDNS0Option.ProtoReflect.Descriptor instead.
+func (*EDNS0Option) Descriptor() ([]byte, []int) {
+	return file_github_com_cloudprober_cloudprober_probes_dns_proto_config_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *EDNS0Option) GetCode() uint32 {
+	if x != nil && x.Code != nil {
+		return *x.Code
+	}
+	return 0
+}
+
+func (x *EDNS0Option) GetDataHex() string {
+	if x != nil && x.DataHex != nil {
+		return *x.DataHex
+	}
+	return ""
 }
 
 type ProbeConf struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
-	// Domain to use when making DNS queries
+	// Domain to use when making DNS queries. It can be templated using the
+	// target's name and labels, e.g. "@target.label.service@.example.com.",
+	// so that a single probe can cover a dynamic list of targets. Supported
+	// tokens: @probe@, @target@ (same as @target.name@), @target.port@,
+	// @target.ip@ (if available), and @target.label.<label_name>@.
 	ResolvedDomain *string `protobuf:"bytes,1,opt,name=resolved_domain,json=resolvedDomain,def=www.google.com." json:"resolved_domain,omitempty"`
-	// DNS Query Type
+	// DNS query type, e.g. A, AAAA, MX, TXT, SRV, NS, CNAME, SOA or ANY. Note
+	// that many DNS servers refuse or minimize ANY queries (RFC 8482).
 	QueryType *QueryType `protobuf:"varint,3,opt,name=query_type,json=queryType,enum=cloudprober.probes.dns.QueryType,def=15" json:"query_type,omitempty"`
 	// Minimum number of answers expected. Default behavior is to return success
 	// if DNS response status is NOERROR.
@@ -323,6 +461,96 @@ type ProbeConf struct {
 	// default we resolve first if it's a discovered resource, e.g., a k8s
 	// endpoint.
 	ResolveFirst *bool `protobuf:"varint,5,opt,name=resolve_first,json=resolveFirst" json:"resolve_first,omitempty"`
+	// Expected response code. Probe fails if the response code doesn't match.
+	// For example, set it to NXDOMAIN to verify that a name doesn't resolve.
+	ExpectedRcode *Rcode `protobuf:"varint,6,opt,name=expected_rcode,json=expectedRcode,enum=cloudprober.probes.dns.Rcode,def=0" json:"expected_rcode,omitempty"`
+	// Data that must be present in the answer section, matched exactly
+	// against the answer records' data, e.g. "192.168.0.1" for an A record, or
+	// "10 mx.example.com." for an MX record. If multiple values are specified,
+	// all of them must be present.
+	ExpectedAnswer []string `protobuf:"bytes,7,rep,name=expected_answer,json=expectedAnswer" json:"expected_answer,omitempty"`
+	// Regex that the data of all the records in the answer section must match,
+	// e.g. "^10\\.1\\." to make sure that an internal name resolves only to
+	// internal IPs. Useful to detect hijacked or stale answers. To also require
+	// a non-empty answer section, use it along with min_answers.
+	AnswerRegex *string `protobuf:"bytes,8,opt,name=answer_regex,json=answerRegex" json:"answer_regex,omitempty"`
+	// If a UDP response is truncated (TC bit set), retry the query over TCP,
+	// the way stub resolvers do. Latency includes both the UDP and the TCP
+	// exchanges, and the number of such retries is exported as "tcp_retries".
+	// Only valid with the UDP dns_proto. To monitor the TCP path explicitly,
+	// set dns_proto to TCP instead.
+	TcpFallback *bool `protobuf:"varint,9,opt,name=tcp_fallback,json=tcpFallback" json:"tcp_fallback,omitempty"`
+	// TLS configuration for DNS over TLS and DNS over HTTPS. By default, the
+	// target name is used to verify the server certificate; use
+	// tls_config.server_name to override it, e.g. along with resolve_first.
+	TlsConfig *proto.TLSConfig `protobuf:"bytes,10,opt,name=tls_config,json=tlsConfig" json:"tls_config,omitempty"`
+	// URL path for DNS over HTTPS requests. Queries are sent as POST requests
+	// to https://<target>:<port><doh_path>.
+	DohPath *string `protobuf:"bytes,11,opt,name=doh_path,json=dohPath,def=/dns-query" json:"doh_path,omitempty"`
+	// Validate DNSSEC signatures. If enabled, queries are sent with the DO
+	// (DNSSEC OK) and CD (checking disabled) bits set, and the signatures in
+	// the answer are validated up to a trust anchor, by querying the target for
+	// the DNSKEY and DS records of all the zones in the chain. Results are
+	// exported as "dnssec" counters, with a "status" label:
+	//
+	//	secure: chain of trust validated.
+	//	insecure: zone is an unsigned delegation, i.e. the parent zone
+	//	          proves (NSEC/NSEC3) that the zone has no DS records. This
+	//	          applies to the unsigned and empty answers as well.
+	//	bogus: validation failed, e.g. because of an expired RRSIG, a DS
+	//	       record that doesn't match any DNSKEY, or missing DS records
+	//	       (or signatures) without a valid proof of the unsigned zone.
+	//
+	// Bogus responses are counted as failures.
+	DnssecValidation *bool `protobuf:"varint,12,opt,name=dnssec_validation,json=dnssecValidation" json:"dnssec_validation,omitempty"`
+	// Trust anchors for DNSSEC validation, as DS records in the zone file
+	// format. Default is the root zone KSK-2017:
+	//
+	//	". IN DS 20326 8 2 E06D44B80B8F1D39A95C0B0D7C65D08458E880409BBF683457104237C7F8EC8D"
+	DnssecTrustAnchor []string `protobuf:"bytes,13,rep,name=dnssec_trust_anchor,json=dnssecTrustAnchor" json:"dnssec_trust_anchor,omitempty"`
+	// EDNS0 UDP buffer size to advertise. If set, queries include an EDNS0 OPT
+	// record. Larger sizes let servers send bigger responses over UDP, instead
+	// of truncating them. If not set, 4096 is used whenever EDNS0 is needed for
+	// other options (e.g. dnssec_validation or edns0_client_subnet).
+	Edns0UdpSize *uint32 `protobuf:"varint,14,opt,name=edns0_udp_size,json=edns0UdpSize" json:"edns0_udp_size,omitempty"`
+	// EDNS0 client subnet (RFC 7871) to attach to the queries, e.g.
+	// "203.0.113.0/24" or "2001:db8::/56". Useful to test GeoDNS behavior for
+	// clients in different locations.
+	Edns0ClientSubnet *string `protobuf:"bytes,15,opt,name=edns0_client_subnet,json=edns0ClientSubnet" json:"edns0_client_subnet,omitempty"`
+	// Other EDNS0 options to attach to the queries.
+	Edns0Option []*EDNS0Option `protobuf:"bytes,16,rep,name=edns0_option,json=edns0Option" json:"edns0_option,omitempty"`
+	// DNS server port. If not specified, target's port is used if available
+	// (e.g. "host:port" static targets, or kubernetes endpoints), and the
+	// protocol's default port otherwise: 53 for UDP and TCP, 853 for TCP_TLS,
+	// and 443 for HTTPS.
+	Port *int32 `protobuf:"varint,17,opt,name=port" json:"port,omitempty"`
+	// Target label to read the port from, e.g. "dns_port". If set, and a target
+	// has this label with a valid port number, that port is used for the
+	// target, overriding both the port field above and the target's own port.
+	PortLabel *string `protobuf:"bytes,18,opt,name=port_label,json=portLabel" json:"port_label,omitempty"`
+	// Target label to read the DNS protocol from, e.g. "dns_proto". If set, and
+	// a target has this label set to one of the DNSProto values (UDP, TCP,
+	// TCP_TLS or HTTPS, case-insensitive), that protocol is used for the
+	// target. Other targets use dns_proto.
+	DnsProtoLabel *string `protobuf:"bytes,19,opt,name=dns_proto_label,json=dnsProtoLabel" json:"dns_proto_label,omitempty"`
+	// Expect a negative response: NXDOMAIN, or NOERROR with an empty answer
+	// section (NODATA). Any positive answer is counted as a failure. Useful to
+	// detect wildcard hijacking, or leakage of internal names to public DNS
+	// servers. Cannot be used along with expected_rcode, min_answers,
+	// expected_answer or answer_regex.
+	ExpectNoAnswer *bool `protobuf:"varint,20,opt,name=expect_no_answer,json=expectNoAnswer" json:"expect_no_answer,omitempty"`
+	// Whether to set the RD (recursion desired) bit in the queries. Set it to
+	// false when probing authoritative servers.
+	RecursionDesired *bool `protobuf:"varint,21,opt,name=recursion_desired,json=recursionDesired,def=1" json:"recursion_desired,omitempty"`
+	// Expect authoritative answers, i.e. count responses without the AA
+	// (authoritative answer) bit as failures.
+	ExpectAuthoritative *bool `protobuf:"varint,22,opt,name=expect_authoritative,json=expectAuthoritative" json:"expect_authoritative,omitempty"`
+	// Compare SOA serials across targets, to monitor zone propagation to all
+	// the authoritative servers of a zone. Requires query_type SOA. SOA serial
+	// returned by each target is exported as "soa_serial", and how far behind
+	// it's from the highest serial across all targets as "soa_serial_lag" (0
+	// for the up-to-date targets).
+	CompareSoaSerials *bool `protobuf:"varint,23,opt,name=compare_soa_serials,json=compareSoaSerials" json:"compare_soa_serials,omitempty"`
 	// DNS Query QueryClass
 	QueryClass *QueryClass `protobuf:"varint,96,opt,name=query_class,json=queryClass,enum=cloudprober.probes.dns.QueryClass,def=1" json:"query_class,omitempty"`
 	// Which DNS protocol is used for resolution.
@@ -350,6 +578,9 @@ const (
 	Default_ProbeConf_ResolvedDomain       = string("www.google.com.")
 	Default_ProbeConf_QueryType            = QueryType_MX
 	Default_ProbeConf_MinAnswers           = uint32(0)
+	Default_ProbeConf_ExpectedRcode        = Rcode_NOERROR
+	Default_ProbeConf_DohPath              = string("/dns-query")
+	Default_ProbeConf_RecursionDesired     = bool(true)
 	Default_ProbeConf_QueryClass           = QueryClass_IN
 	Default_ProbeConf_DnsProto             = DNSProto_UDP
 	Default_ProbeConf_RequestsPerProbe     = int32(1)
@@ -358,7 +589,7 @@ const (
 
 func (x *ProbeConf) Reset() {
 	*x = ProbeConf{}
-	mi := &file_github_com_cloudprober_cloudprober_probes_dns_proto_config_proto_msgTypes[0]
+	mi := &file_github_com_cloudprober_cloudprober_probes_dns_proto_config_proto_msgTypes[1]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -370,7 +601,7 @@ func (x *ProbeConf) String() string {
 func (*ProbeConf) ProtoMessage() {}
 
 func (x *ProbeConf) ProtoReflect() protoreflect.Message {
-	mi := &file_github_com_cloudprober_cloudprober_probes_dns_proto_config_proto_msgTypes[0]
+	mi := &file_github_com_cloudprober_cloudprober_probes_dns_proto_config_proto_msgTypes[1]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -383,7 +614,7 @@ func (x *ProbeConf) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ProbeConf.ProtoReflect.Descriptor instead.
 func (*ProbeConf) Descriptor() ([]byte, []int) {
-	return file_github_com_cloudprober_cloudprober_probes_dns_proto_config_proto_rawDescGZIP(), []int{0}
+	return file_github_com_cloudprober_cloudprober_probes_dns_proto_config_proto_rawDescGZIP(), []int{1}
 }
 
 func (x *ProbeConf) GetResolvedDomain() string {
@@ -414,6 +645,132 @@ func (x *ProbeConf) GetResolveFirst() bool {
 	return false
 }
 
+func (x *ProbeConf) GetExpectedRcode() Rcode {
+	if x != nil && x.ExpectedRcode != nil {
+		return *x.ExpectedRcode
+	}
+	return Default_ProbeConf_ExpectedRcode
+}
+
+func (x *ProbeConf) GetExpectedAnswer() []string {
+	if x != nil {
+		return x.ExpectedAnswer
+	}
+	return nil
+}
+
+func (x *ProbeConf) GetAnswerRegex() string {
+	if x != nil && x.AnswerRegex != nil {
+		return *x.AnswerRegex
+	}
+	return ""
+}
+
+func (x *ProbeConf) GetTcpFallback() bool {
+	if x != nil && x.TcpFallback != nil {
+		return *x.TcpFallback
+	}
+	return false
+}
+
+func (x *ProbeConf) GetTlsConfig() *proto.TLSConfig {
+	if x != nil {
+		return x.TlsConfig
+	}
+	return nil
+}
+
+func (x *ProbeConf) GetDohPath() string {
+	if x != nil && x.DohPath != nil {
+		return *x.DohPath
+	}
+	return Default_ProbeConf_DohPath
+}
+
+func (x *ProbeConf) GetDnssecValidation() bool {
+	if x != nil && x.DnssecValidation != nil {
+		return *x.DnssecValidation
+	}
+	return false
+}
+
+func (x *ProbeConf) GetDnssecTrustAnchor() []string {
+	if x != nil {
+		return x.DnssecTrustAnchor
+	}
+	return nil
+}
+
+func (x *ProbeConf) GetEdns0UdpSize() uint32 {
+	if x != nil && x.Edns0UdpSize != nil {
+		return *x.Edns0UdpSize
+	}
+	return 0
+}
+
+func (x *ProbeConf) GetEdns0ClientSubnet() string {
+	if x != nil && x.Edns0ClientSubnet != nil {
+		return *x.Edns0ClientSubnet
+	}
+	return ""
+}
+
+func (x *ProbeConf) GetEdns0Option() []*EDNS0Option {
+	if x != nil {
+		return x.Edns0Option
+	}
+	return nil
+}
+
+func (x *ProbeConf) GetPort() int32 {
+	if x != nil && x.Port != nil {
+		return *x.Port
+	}
+	return 0
+}
+
+func (x *ProbeConf) GetPortLabel() string {
+	if x != nil && x.PortLabel != nil {
+		return *x.PortLabel
+	}
+	return ""
+}
+
+func (x *ProbeConf) GetDnsProtoLabel() string {
+	if x != nil && x.DnsProtoLabel != nil {
+		return *x.DnsProtoLabel
+	}
+	return ""
+}
+
+func (x *ProbeConf) GetExpectNoAnswer() bool {
+	if x != nil && x.ExpectNoAnswer != nil {
+		return *x.ExpectNoAnswer
+	}
+	return false
+}
+
+func (x *ProbeConf) GetRecursionDesired() bool {
+	if x != nil && x.RecursionDesired != nil {
+		return *x.RecursionDesired
+	}
+	return Default_ProbeConf_RecursionDesired
+}
+
+func (x *ProbeConf) GetExpectAuthoritative() bool {
+	if x != nil && x.ExpectAuthoritative != nil {
+		return *x.ExpectAuthoritative
+	}
+	return false
+}
+
+func (x *ProbeConf) GetCompareSoaSerials() bool {
+	if x != nil && x.CompareSoaSerials != nil {
+		return *x.CompareSoaSerials
+	}
+	return false
+}
+
 func (x *ProbeConf) GetQueryClass() QueryClass {
 	if x != nil && x.QueryClass != nil {
 		return *x.QueryClass
@@ -446,19 +803,45 @@ var File_github_com_cloudprober_cloudprober_probes_dns_proto_config_proto protor
 
 const file_github_com_cloudprober_cloudprober_probes_dns_proto_config_proto_rawDesc = "" +
 	"\n" +
-	"@github.com/cloudprober/cloudprober/probes/dns/proto/config.proto\x12\x16cloudprober.probes.dns\"\xcb\x03\n" +
+	"@github.com/cloudprober/cloudprober/probes/dns/proto/config.proto\x12\x16cloudprober.probes.dns\x1aFgithub.com/cloudprober/cloudprober/common/tlsconfig/proto/config.proto\"<\n" +
+	"\vEDNS0Option\x12\x12\n" +
+	"\x04code\x18\x01 \x02(\rR\x04code\x12\x19\n" +
+	"\bdata_hex\x18\x02 \x01(\tR\adataHex\"\x87\n" +
+	"\n" +
 	"\tProbeConf\x128\n" +
 	"\x0fresolved_domain\x18\x01 \x01(\t:\x0fwww.google.com.R\x0eresolvedDomain\x12D\n" +
 	"\n" +
 	"query_type\x18\x03 \x01(\x0e2!.cloudprober.probes.dns.QueryType:\x02MXR\tqueryType\x12\"\n" +
 	"\vmin_answers\x18\x04 \x01(\r:\x010R\n" +
 	"minAnswers\x12#\n" +
-	"\rresolve_first\x18\x05 \x01(\bR\fresolveFirst\x12G\n" +
+	"\rresolve_first\x18\x05 \x01(\bR\fresolveFirst\x12M\n" +
+	"\x0eexpected_rcode\x18\x06 \x01(\x0e2\x1d.cloudprober.probes.dns.Rcode:\aNOERRORR\rexpectedRcode\x12'\n" +
+	"\x0fexpected_answer\x18\a \x03(\tR\x0eexpectedAnswer\x12!\n" +
+	"\fanswer_regex\x18\b \x01(\tR\vanswerRegex\x12!\n" +
+	"\ftcp_fallback\x18\t \x01(\bR\vtcpFallback\x12?\n" +
+	"\n" +
+	"tls_config\x18\n" +
+	" \x01(\v2 .cloudprober.tlsconfig.TLSConfigR\ttlsConfig\x12%\n" +
+	"\bdoh_path\x18\v \x01(\t:\n" +
+	"/dns-queryR\adohPath\x12+\n" +
+	"\x11dnssec_validation\x18\f \x01(\bR\x10dnssecValidation\x12.\n" +
+	"\x13dnssec_trust_anchor\x18\r \x03(\tR\x11dnssecTrustAnchor\x12$\n" +
+	"\x0eedns0_udp_size\x18\x0e \x01(\rR\fedns0UdpSize\x12.\n" +
+	"\x13edns0_client_subnet\x18\x0f \x01(\tR\x11edns0ClientSubnet\x12F\n" +
+	"\fedns0_option\x18\x10 \x03(\v2#.cloudprober.probes.dns.EDNS0OptionR\vedns0Option\x12\x12\n" +
+	"\x04port\x18\x11 \x01(\x05R\x04port\x12\x1d\n" +
+	"\n" +
+	"port_label\x18\x12 \x01(\tR\tportLabel\x12&\n" +
+	"\x0fdns_proto_label\x18\x13 \x01(\tR\rdnsProtoLabel\x12(\n" +
+	"\x10expect_no_answer\x18\x14 \x01(\bR\x0eexpectNoAnswer\x121\n" +
+	"\x11recursion_desired\x18\x15 \x01(\b:\x04trueR\x10recursionDesired\x121\n" +
+	"\x14expect_authoritative\x18\x16 \x01(\bR\x13expectAuthoritative\x12.\n" +
+	"\x13compare_soa_serials\x18\x17 \x01(\bR\x11compareSoaSerials\x12G\n" +
 	"\vquery_class\x18` \x01(\x0e2\".cloudprober.probes.dns.QueryClass:\x02INR\n" +
 	"queryClass\x12B\n" +
 	"\tdns_proto\x18a \x01(\x0e2 .cloudprober.probes.dns.DNSProto:\x03UDPR\bdnsProto\x12/\n" +
 	"\x12requests_per_probe\x18b \x01(\x05:\x011R\x10requestsPerProbe\x127\n" +
-	"\x16requests_interval_msec\x18c \x01(\x05:\x010R\x14requestsIntervalMsec*\xa4\x03\n" +
+	"\x16requests_interval_msec\x18c \x01(\x05:\x010R\x14requestsIntervalMsec*\xae\x03\n" +
 	"\tQueryType\x12\b\n" +
 	"\x04NONE\x10\x00\x12\x05\n" +
 	"\x01A\x10\x01\x12\x06\n" +
@@ -499,6 +882,7 @@ const file_github_com_cloudprober_cloudprober_probes_dns_proto_config_proto_rawD
 	"OPENPGPKEY\x10=\x12\t\n" +
 	"\x04TKEY\x10\xf9\x01\x12\t\n" +
 	"\x04TSIG\x10\xfa\x01\x12\b\n" +
+	"\x03ANY\x10\xff\x01\x12\b\n" +
 	"\x03URI\x10\x80\x02\x12\b\n" +
 	"\x03CAA\x10\x81\x02\x12\b\n" +
 	"\x02TA\x10\x80\x80\x02\x12\t\n" +
@@ -506,11 +890,20 @@ const file_github_com_cloudprober_cloudprober_probes_dns_proto_config_proto_rawD
 	"\n" +
 	"QueryClass\x12\x06\n" +
 	"\x02IN\x10\x01\x12\x06\n" +
-	"\x02CH\x10\x03*)\n" +
+	"\x02CH\x10\x03*V\n" +
+	"\x05Rcode\x12\v\n" +
+	"\aNOERROR\x10\x00\x12\v\n" +
+	"\aFORMERR\x10\x01\x12\f\n" +
+	"\bSERVFAIL\x10\x02\x12\f\n" +
+	"\bNXDOMAIN\x10\x03\x12\n" +
+	"\n" +
+	"\x06NOTIMP\x10\x04\x12\v\n" +
+	"\aREFUSED\x10\x05*4\n" +
 	"\bDNSProto\x12\a\n" +
 	"\x03UDP\x10\x00\x12\a\n" +
 	"\x03TCP\x10\x01\x12\v\n" +
-	"\aTCP_TLS\x10\x02B5Z3github.com/cloudprober/cloudprober/probes/dns/proto"
+	"\aTCP_TLS\x10\x02\x12\t\n" +
+	"\x05HTTPS\x10\x03B5Z3github.com/cloudprober/cloudprober/probes/dns/proto"
 
 var (
 	file_github_com_cloudprober_cloudprober_probes_dns_proto_config_proto_rawDescOnce sync.Once
@@ -524,23 +917,29 @@ func file_github_com_cloudprober_cloudprober_probes_dns_proto_config_proto_rawDe
 	return file_github_com_cloudprober_cloudprober_probes_dns_proto_config_proto_rawDescData
 }
 
-var file_github_com_cloudprober_cloudprober_probes_dns_proto_config_proto_enumTypes = make([]protoimpl.EnumInfo, 3)
-var file_github_com_cloudprober_cloudprober_probes_dns_proto_config_proto_msgTypes = make([]protoimpl.MessageInfo, 1)
+var file_github_com_cloudprober_cloudprober_probes_dns_proto_config_proto_enumTypes = make([]protoimpl.EnumInfo, 4)
+var file_github_com_cloudprober_cloudprober_probes_dns_proto_config_proto_msgTypes = make([]protoimpl.MessageInfo, 2)
 var file_github_com_cloudprober_cloudprober_probes_dns_proto_config_proto_goTypes = []any{
-	(QueryType)(0),    // 0: cloudprober.probes.dns.QueryType
-	(QueryClass)(0),   // 1: cloudprober.probes.dns.QueryClass
-	(DNSProto)(0),     // 2: cloudprober.probes.dns.DNSProto
-	(*ProbeConf)(nil), // 3: cloudprober.probes.dns.ProbeConf
+	(QueryType)(0),          // 0: cloudprober.probes.dns.QueryType
+	(QueryClass)(0),         // 1: cloudprober.probes.dns.QueryClass
+	(Rcode)(0),              // 2: cloudprober.probes.dns.Rcode
+	(DNSProto)(0),           // 3: cloudprober.probes.dns.DNSProto
+	(*EDNS0Option)(nil),     // 4: cloudprober.probes.dns.EDNS0Option
+	(*ProbeConf)(nil),       // 5: cloudprober.probes.dns.ProbeConf
+	(*proto.TLSConfig)(nil), // 6: cloudprober.tlsconfig.TLSConfig
 }
 var file_github_com_cloudprober_cloudprober_probes_dns_proto_config_proto_depIdxs = []int32{
 	0, // 0: cloudprober.probes.dns.ProbeConf.query_type:type_name -> cloudprober.probes.dns.QueryType
-	1, // 1: cloudprober.probes.dns.ProbeConf.query_class:type_name -> cloudprober.probes.dns.QueryClass
-	2, // 2: cloudprober.probes.dns.ProbeConf.dns_proto:type_name -> cloudprober.probes.dns.DNSProto
-	3, // [3:3] is the sub-list for method output_type
-	3, // [3:3] is the sub-list for method input_type
-	3, // [3:3] is the sub-list for extension type_name
-	3, // [3:3] is the sub-list for extension extendee
-	0, // [0:3] is the sub-list for field type_name
+	2, // 1: cloudprober.probes.dns.ProbeConf.expected_rcode:type_name -> cloudprober.probes.dns.Rcode
+	6, // 2: cloudprober.probes.dns.ProbeConf.tls_config:type_name -> cloudprober.tlsconfig.TLSConfig
+	4, // 3: cloudprober.probes.dns.ProbeConf.edns0_option:type_name -> cloudprober.probes.dns.EDNS0Option
+	1, // 4: cloudprober.probes.dns.ProbeConf.query_class:type_name -> cloudprober.probes.dns.QueryClass
+	3, // 5: cloudprober.probes.dns.ProbeConf.dns_proto:type_name -> cloudprober.probes.dns.DNSProto
+	6, // [6:6] is the sub-list for method output_type
+	6, // [6:6] is the sub-list for method input_type
+	6, // [6:6] is the sub-list for extension type_name
+	6, // [6:6] is the sub-list for extension extendee
+	0, // [0:6] is the sub-list for field type_name
 }
 
 func init() { file_github_com_cloudprober_cloudprober_probes_dns_proto_config_proto_init() }
@@ -553,8 +952,8 @@ func file_github_com_cloudprober_cloudprober_probes_dns_proto_config_proto_init(
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_github_com_cloudprober_cloudprober_probes_dns_proto_config_proto_rawDesc), len(file_github_com_cloudprober_cloudprober_probes_dns_proto_config_proto_rawDesc)),
-			NumEnums:      3,
-			NumMessages:   1,
+			NumEnums:      4,
+			NumMessages:   2,
 			NumExtensions: 0,
 			NumServices:   0,
 		},
@@ -0,0 +1,81 @@
+// Copyright 2025 The Cloudprober Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dns
+
+import (
+	"sync"
+	"time"
+
+	"github.com/miekg/dns"
+)
+
+type soaSerialEntry struct {
+	serial  uint32
+	updated time.Time
+}
+
+// soaSerials keeps track of the latest SOA serials returned by the targets,
+// so that we can find out which targets are behind.
+type soaSerials struct {
+	mu      sync.Mutex
+	serials map[string]soaSerialEntry
+	// Serials not updated in this duration, e.g. for the targets that went
+	// away, are ignored.
+	maxAge time.Duration
+}
+
+func newSOASerials(maxAge time.Duration) *soaSerials {
+	return &soaSerials{
+		serials: make(map[string]soaSerialEntry),
+		maxAge:  maxAge,
+	}
+}
+
+// serialLess compares serial numbers using serial number arithmetic (RFC
+// 1982), to handle the serial numbers wrapping around.
+func serialLess(a, b uint32) bool {
+	return int32(b-a) > 0
+}
+
+// update records the target's serial, and returns how far behind it's from
+// the highest serial across all targets.
+func (s *soaSerials) update(target string, serial uint32, now time.Time) int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.serials[target] = soaSerialEntry{serial: serial, updated: now}
+
+	maxSerial := serial
+	for t, e := range s.serials {
+		if now.Sub(e.updated) > s.maxAge {
+			delete(s.serials, t)
+			continue
+		}
+		if serialLess(maxSerial, e.serial) {
+			maxSerial = e.serial
+		}
+	}
+	return int64(maxSerial - serial)
+}
+
+// soaSerial returns the serial from the first SOA record in the answer.
+func soaSerial(resp *dns.Msg) (uint32, bool) {
+	for _, rr := range resp.Answer {
+		if soa, ok := rr.(*dns.SOA); ok {
+			return soa.Serial, true
+		}
+	}
+	return 0, false
+}
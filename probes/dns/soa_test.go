@@ -0,0 +1,62 @@
+// Copyright 2025 The Cloudprober Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dns
+
+import (
+	"testing"
+	"time"
+
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSerialLess(t *testing.T) {
+	assert.True(t, serialLess(1, 2))
+	assert.False(t, serialLess(2, 1))
+	assert.False(t, serialLess(2, 2))
+	// Wrap around.
+	assert.True(t, serialLess(0xfffffff0, 5))
+	assert.False(t, serialLess(5, 0xfffffff0))
+}
+
+func TestSOASerialsUpdate(t *testing.T) {
+	s := newSOASerials(time.Minute)
+	now := time.Now()
+
+	assert.Equal(t, int64(0), s.update("ns1", 2025010101, now))
+	assert.Equal(t, int64(0), s.update("ns2", 2025010102, now))
+	assert.Equal(t, int64(1), s.update("ns1", 2025010101, now), "ns1 behind ns2")
+	assert.Equal(t, int64(0), s.update("ns1", 2025010102, now), "ns1 caught up")
+
+	// Wrap around.
+	s = newSOASerials(time.Minute)
+	s.update("ns1", 3, now)
+	assert.Equal(t, int64(13), s.update("ns2", 0xfffffff6, now))
+
+	// Serials older than maxAge are ignored.
+	s = newSOASerials(time.Minute)
+	s.update("ns1", 10, now)
+	assert.Equal(t, int64(0), s.update("ns2", 5, now.Add(2*time.Minute)))
+}
+
+func TestSOASerial(t *testing.T) {
+	soa, _ := dns.NewRR("example.com. 3600 IN SOA ns1.example.com. admin.example.com. 2025010101 7200 3600 1209600 3600")
+	serial, ok := soaSerial(&dns.Msg{Answer: []dns.RR{soa}})
+	assert.True(t, ok)
+	assert.Equal(t, uint32(2025010101), serial)
+
+	_, ok = soaSerial(&dns.Msg{})
+	assert.False(t, ok)
+}
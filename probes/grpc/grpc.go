@@ -480,10 +480,12 @@ func (p *Probe) runProbeForTargetAndConnIndex(ctx context.Context, runReq *sched
 	case configpb.ProbeConf_GENERIC:
 		r, err = p.genericRequest(reqCtx, conn, p.c.GetRequest())
 	default:
-		p.l.Criticalf("Method %v not implemented", p.c.GetMethod())
+		err = fmt.Errorf("method %v not implemented", p.c.GetMethod())
 	}
 
-	l.Debug("Response: " + r.String())
+	if r != nil {
+		l.Debug("Response: " + r.String())
+	}
 
 	if err != nil {
 		peerAddr := "unknown"
@@ -377,9 +377,19 @@ func TestProbeTimeouts(t *testing.T) {
 		p.Start(ctx, dataChan)
 	}()
 
-	ems, err := testutils.MetricsFromChannel(dataChan, 2, 3*statsExportInterval)
-	if err != nil || len(ems) != 2 {
-		t.Errorf("Err: %v", err)
+	// Skip the scheduler's run timeout counters, we are interested only in
+	// the probe results here.
+	var ems []*metrics.EventMetrics
+	timeoutCh := time.After(3 * statsExportInterval)
+	for len(ems) < 2 {
+		select {
+		case em := <-dataChan:
+			if em.Metric("run_timeouts") == nil {
+				ems = append(ems, em)
+			}
+		case <-timeoutCh:
+			t.Fatalf("Timed out waiting for probe results, got %d EventMetrics", len(ems))
+		}
 	}
 
 	for i, em := range ems {
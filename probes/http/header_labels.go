@@ -0,0 +1,123 @@
+// Copyright 2025 The Cloudprober Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package http
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/cloudprober/cloudprober/metrics"
+	"github.com/cloudprober/cloudprober/probes/options"
+)
+
+// headerLabelResult keeps the results for a unique combination of the
+// response_header_label header values.
+type headerLabelResult struct {
+	labels         [][2]string
+	total, success int64
+	latency        metrics.LatencyValue
+}
+
+// otherHeaderLabelValue is used for all header labels, once the number of
+// unique combinations of header values reaches the limit.
+const otherHeaderLabelValue = "other"
+
+// Key for the "other" bucket in probeResult.headerLabelResults. It can't
+// collide with the keys made from the header values, and it sorts last.
+const otherHeaderLabelKey = "\xffother"
+
+// headerLabelKey returns the metrics label key for a response header, e.g.
+// x_cache for X-Cache.
+func headerLabelKey(header string) string {
+	return strings.ToLower(strings.ReplaceAll(header, "-", "_"))
+}
+
+// headerLabelResult returns the result object corresponding to the response
+// header values, creating it if required. Once max_response_header_label_values
+// combinations are being tracked, new combinations share the result object
+// with all labels set to "other". It returns nil if response_header_label is
+// not configured.
+func (p *Probe) headerLabelResult(result *probeResult, header http.Header) *headerLabelResult {
+	if len(p.c.GetResponseHeaderLabel()) == 0 {
+		return nil
+	}
+
+	labels := make([][2]string, len(p.c.GetResponseHeaderLabel()))
+	values := make([]string, len(labels))
+	for i, h := range p.c.GetResponseHeaderLabel() {
+		values[i] = header.Get(h)
+		labels[i] = [2]string{headerLabelKey(h), values[i]}
+	}
+	key := strings.Join(values, "\x00")
+
+	if result.headerLabelResults == nil {
+		result.headerLabelResults = make(map[string]*headerLabelResult)
+	}
+	hlr := result.headerLabelResults[key]
+	if hlr == nil && len(result.headerLabelResults) >= p.maxHeaderLabelValues(result) {
+		key = otherHeaderLabelKey
+		for i := range labels {
+			labels[i][1] = otherHeaderLabelValue
+		}
+		hlr = result.headerLabelResults[key]
+	}
+	if hlr == nil {
+		hlr = &headerLabelResult{labels: labels}
+		if p.opts.LatencyDist != nil {
+			hlr.latency = p.opts.LatencyDist.CloneDist()
+		} else {
+			hlr.latency = metrics.NewFloat(0)
+		}
+		result.headerLabelResults[key] = hlr
+	}
+	return hlr
+}
+
+// maxHeaderLabelValues returns the number of header value combinations
+// that can be tracked for a target, not counting the "other" bucket.
+func (p *Probe) maxHeaderLabelValues(result *probeResult) int {
+	n := int(p.c.GetMaxResponseHeaderLabelValues())
+	if result.headerLabelResults[otherHeaderLabelKey] != nil {
+		n++
+	}
+	return n
+}
+
+// headerLabelMetrics returns EventMetrics for the header label results, one
+// for each unique combination of header values.
+func (result *probeResult) headerLabelMetrics(ts time.Time, opts *options.Options) []*metrics.EventMetrics {
+	keys := make([]string, 0, len(result.headerLabelResults))
+	for k := range result.headerLabelResults {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var ems []*metrics.EventMetrics
+	for _, k := range keys {
+		hlr := result.headerLabelResults[k]
+		em := metrics.NewEventMetrics(ts).
+			AddMetric("total", metrics.NewInt(hlr.total)).
+			AddMetric("success", metrics.NewInt(hlr.success)).
+			AddMetric(opts.LatencyMetricName, hlr.latency.Clone())
+		em.AddLabel("ptype", "http") // Other labels are added by scheduler.
+		for _, l := range hlr.labels {
+			em.AddLabel(l[0], l[1])
+		}
+		ems = append(ems, em)
+	}
+	return ems
+}
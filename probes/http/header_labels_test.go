@@ -0,0 +1,137 @@
+// Copyright 2025 The Cloudprober Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/cloudprober/cloudprober/metrics"
+	"github.com/cloudprober/cloudprober/probes/common/sched"
+	configpb "github.com/cloudprober/cloudprober/probes/http/proto"
+	"github.com/cloudprober/cloudprober/probes/options"
+	"github.com/cloudprober/cloudprober/targets"
+	"github.com/cloudprober/cloudprober/targets/endpoint"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestHeaderLabelKey(t *testing.T) {
+	assert.Equal(t, "x_cache", headerLabelKey("X-Cache"))
+	assert.Equal(t, "via", headerLabelKey("Via"))
+}
+
+func TestResponseHeaderLabels(t *testing.T) {
+	var reqCount atomic.Int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Requests alternate between be-1 and be-2, and only be-1 sets
+		// X-Cache header.
+		if reqCount.Add(1)%2 == 1 {
+			w.Header().Set("X-Backend", "be-1")
+			w.Header().Set("X-Cache", "HIT")
+			return
+		}
+		w.Header().Set("X-Backend", "be-2")
+	}))
+	defer ts.Close()
+
+	tsURL, _ := url.Parse(ts.URL)
+	port, _ := strconv.Atoi(tsURL.Port())
+
+	p := &Probe{}
+	opts := &options.Options{
+		Targets:           targets.StaticTargets(tsURL.Hostname()),
+		Interval:          2 * time.Second,
+		Timeout:           time.Second,
+		LatencyMetricName: "latency",
+		ProbeConf: &configpb.ProbeConf{
+			Port:                proto.Int32(int32(port)),
+			ResponseHeaderLabel: []string{"X-Backend", "X-Cache"},
+		},
+	}
+	assert.NoError(t, p.Init("http_test", opts))
+
+	runReq := &sched.RunProbeForTargetRequest{Target: endpoint.Endpoint{Name: tsURL.Hostname()}}
+	for i := 0; i < 3; i++ {
+		p.runProbe(context.Background(), runReq)
+	}
+
+	ems := runReq.Result.Metrics(time.Now(), 0, opts)
+	assert.Len(t, ems, 3)
+
+	// Default metrics are not affected.
+	assert.Equal(t, int64(3), ems[0].Metric("total").(metrics.NumValue).Int64())
+	assert.Empty(t, ems[0].Label("x_backend"))
+
+	for i, want := range []struct {
+		backend, cache string
+		total          int64
+	}{
+		{backend: "be-1", cache: "HIT", total: 2},
+		{backend: "be-2", cache: "", total: 1},
+	} {
+		em := ems[i+1]
+		assert.Equal(t, want.backend, em.Label("x_backend"))
+		assert.Equal(t, want.cache, em.Label("x_cache"))
+		assert.Equal(t, "http", em.Label("ptype"))
+		assert.Equal(t, want.total, em.Metric("total").(metrics.NumValue).Int64())
+		assert.Equal(t, want.total, em.Metric("success").(metrics.NumValue).Int64())
+		assert.NotNil(t, em.Metric("latency"))
+	}
+}
+
+func TestResponseHeaderLabelsLimit(t *testing.T) {
+	p := &Probe{}
+	opts := &options.Options{
+		Targets:           targets.StaticTargets("test.com"),
+		Interval:          2 * time.Second,
+		Timeout:           time.Second,
+		LatencyMetricName: "latency",
+		ProbeConf: &configpb.ProbeConf{
+			ResponseHeaderLabel:          []string{"X-Request-Id"},
+			MaxResponseHeaderLabelValues: proto.Int32(2),
+		},
+	}
+	assert.NoError(t, p.Init("http_test", opts))
+
+	result := p.newResult()
+	for _, id := range []string{"r1", "r2", "r3", "r4", "r1"} {
+		hlr := p.headerLabelResult(result, http.Header{"X-Request-Id": []string{id}})
+		hlr.total++
+	}
+
+	ems := result.headerLabelMetrics(time.Now(), opts)
+	assert.Len(t, ems, 3)
+	for i, want := range []struct {
+		id    string
+		total int64
+	}{
+		{id: "r1", total: 2},
+		{id: "r2", total: 1},
+		{id: "other", total: 2},
+	} {
+		assert.Equal(t, want.id, ems[i].Label("x_request_id"))
+		assert.Equal(t, want.total, ems[i].Metric("total").(metrics.NumValue).Int64())
+	}
+
+	opts.ProbeConf = &configpb.ProbeConf{MaxResponseHeaderLabelValues: proto.Int32(0)}
+	assert.Error(t, (&Probe{}).Init("http_test", opts))
+}
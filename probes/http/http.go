@@ -18,6 +18,7 @@ package http
 import (
 	"context"
 	"crypto/tls"
+	"crypto/x509"
 	"errors"
 	"fmt"
 	"io"
@@ -33,7 +34,9 @@ import (
 	"sync"
 	"time"
 
+	"github.com/cloudprober/cloudprober/common/iputils"
 	"github.com/cloudprober/cloudprober/common/oauth"
+	"github.com/cloudprober/cloudprober/common/strtemplate"
 	"github.com/cloudprober/cloudprober/common/tlsconfig"
 	"github.com/cloudprober/cloudprober/internal/httpreq"
 	"github.com/cloudprober/cloudprober/internal/validators"
@@ -45,6 +48,7 @@ import (
 	configpb "github.com/cloudprober/cloudprober/probes/http/proto"
 	"github.com/cloudprober/cloudprober/probes/options"
 	"github.com/cloudprober/cloudprober/targets/endpoint"
+	"github.com/quic-go/quic-go/http3"
 	"golang.org/x/oauth2"
 )
 
@@ -62,40 +66,78 @@ type Probe struct {
 	baseTransport http.RoundTripper
 	redirectFunc  func(req *http.Request, via []*http.Request) error
 
+	// HTTP/3 transports for the targets that need their own TLS server name,
+	// keyed by the server name. See http3TransportForTarget.
+	http3TransportsMu sync.Mutex
+	http3Transports   map[string]*http3.Transport
+
 	// book-keeping params
 	targets []endpoint.Endpoint
 	method  string
 	url     string
 	oauthTS oauth2.TokenSource
 
+	basicAuthUser, basicAuthPassword string
+
 	responseParser *payload.Parser
 
 	requestBody *httpreq.RequestBody
 }
 
 type latencyDetails struct {
-	dnsLatency, connectLatency, tlsLatency, reqWriteLatency, firstByteLatency metrics.LatencyValue
+	dnsLatency, connectLatency, tlsLatency, reqWriteLatency, firstByteLatency, bodyReadLatency metrics.LatencyValue
+
+	// Used only for HTTP/3, in place of connectLatency and tlsLatency.
+	quicHandshakeLatency metrics.LatencyValue
 }
 
 type probeResult struct {
 	total, success, timeouts     int64
+	successFirstAttempt, retries int64
 	connEvent                    *metrics.AtomicInt
+	connReused                   *metrics.AtomicInt
 	latency                      metrics.LatencyValue
 	respCodes                    *metrics.Map[int64]
+	respProtos                   *metrics.Map[int64]
+	h3Advertised                 int64
+	respBytes                    *metrics.Int
 	respBodies                   *metrics.Map[int64]
 	validationFailure            *metrics.Map[int64]
 	latencyBreakdown             *latencyDetails
 	sslEarliestExpirationSeconds int64
+	sslLeafCert                  *x509.Certificate
+	headerLabelResults           map[string]*headerLabelResult
 	payloadMetrics               []*metrics.EventMetrics
 }
 
+// tlsConfig returns the TLS config for the probe, or nil if TLS is not
+// configured explicitly.
+func (p *Probe) tlsConfig() (*tls.Config, error) {
+	if !p.c.GetDisableCertValidation() && p.c.GetTlsConfig() == nil {
+		return nil, nil
+	}
+
+	tlsConfig := &tls.Config{}
+	if p.c.GetDisableCertValidation() {
+		p.l.Warning("disable_cert_validation is deprecated as of v0.10.6. Instead of this, please use \"tls_config {disable_cert_validation: true}\"")
+		tlsConfig.InsecureSkipVerify = true
+	}
+
+	if p.c.GetTlsConfig() != nil {
+		if err := tlsconfig.UpdateTLSConfig(tlsConfig, p.c.GetTlsConfig()); err != nil {
+			return nil, err
+		}
+	}
+	return tlsConfig, nil
+}
+
 func (p *Probe) getTransport() (*http.Transport, error) {
 	transport := &http.Transport{
 		Proxy:             http.ProxyFromEnvironment,
 		ForceAttemptHTTP2: true,
 	}
 	dialer := &net.Dialer{
-		Timeout:   p.opts.Timeout,
+		Timeout:   p.opts.MaxTimeout(),
 		KeepAlive: 30 * time.Second, // TCP keep-alive
 	}
 	if p.opts.SourceIP != nil {
@@ -103,15 +145,29 @@ func (p *Probe) getTransport() (*http.Transport, error) {
 			IP: p.opts.SourceIP,
 		}
 	}
+	if p.opts.BindInterface != "" {
+		dialer.Control = iputils.BindToDeviceControl(p.opts.BindInterface)
+	}
 	transport.DialContext = dialer.DialContext
 	transport.MaxIdleConns = int(p.c.GetMaxIdleConns())
-	transport.TLSHandshakeTimeout = p.opts.Timeout
+	transport.TLSHandshakeTimeout = p.opts.MaxTimeout()
 
 	if p.c.GetProxyUrl() != "" {
 		url, err := url.Parse(p.c.GetProxyUrl())
 		if err != nil {
 			return nil, fmt.Errorf("error parsing proxy URL (%s): %v", p.c.GetProxyUrl(), err)
 		}
+		// Go's transport supports HTTP(S) proxies, using CONNECT for HTTPS
+		// URLs, and SOCKS5 proxies.
+		switch url.Scheme {
+		case "http", "https":
+		case "socks5", "socks5h":
+			if len(p.c.GetProxyConnectHeader()) > 0 {
+				return nil, fmt.Errorf("proxy_connect_header is not supported for SOCKS5 proxy (%s)", p.c.GetProxyUrl())
+			}
+		default:
+			return nil, fmt.Errorf("unsupported proxy URL scheme (%s), should be one of http, https, socks5 or socks5h", p.c.GetProxyUrl())
+		}
 		transport.Proxy = http.ProxyURL(url)
 
 		if len(p.c.GetProxyConnectHeader()) > 0 && transport.ProxyConnectHeader == nil {
@@ -122,22 +178,11 @@ func (p *Probe) getTransport() (*http.Transport, error) {
 		}
 	}
 
-	if p.c.GetDisableCertValidation() || p.c.GetTlsConfig() != nil {
-		if transport.TLSClientConfig == nil {
-			transport.TLSClientConfig = &tls.Config{}
-		}
-
-		if p.c.GetDisableCertValidation() {
-			p.l.Warning("disable_cert_validation is deprecated as of v0.10.6. Instead of this, please use \"tls_config {disable_cert_validation: true}\"")
-			transport.TLSClientConfig.InsecureSkipVerify = true
-		}
-
-		if p.c.GetTlsConfig() != nil {
-			if err := tlsconfig.UpdateTLSConfig(transport.TLSClientConfig, p.c.GetTlsConfig()); err != nil {
-				return nil, err
-			}
-		}
+	tlsConfig, err := p.tlsConfig()
+	if err != nil {
+		return nil, err
 	}
+	transport.TLSClientConfig = tlsConfig
 
 	// If HTTP keep-alives are not enabled (default), disable HTTP keep-alive in
 	// transport.
@@ -146,6 +191,9 @@ func (p *Probe) getTransport() (*http.Transport, error) {
 	} else {
 		// If it's been more than 2 probe intervals since connection was used, close it.
 		transport.IdleConnTimeout = 2 * p.opts.Interval
+		if p.c.IdleConnTimeoutMsec != nil {
+			transport.IdleConnTimeout = time.Duration(p.c.GetIdleConnTimeoutMsec()) * time.Millisecond
+		}
 		if p.c.GetRequestsPerProbe() > 1 {
 			transport.MaxIdleConnsPerHost = int(p.c.GetRequestsPerProbe())
 		}
@@ -160,9 +208,43 @@ func (p *Probe) getTransport() (*http.Transport, error) {
 		transport.ForceAttemptHTTP2 = false
 	}
 
+	if p.c.GetForceHttp2() {
+		// Use only HTTP/2: negotiated through ALPN for HTTPS, and with prior
+		// knowledge (h2c) for plain HTTP. Requests to servers that don't
+		// support HTTP/2 fail.
+		protocols := &http.Protocols{}
+		protocols.SetHTTP2(true)
+		protocols.SetUnencryptedHTTP2(true)
+		transport.Protocols = protocols
+	}
+
 	return transport, nil
 }
 
+func (p *Probe) initBasicAuth() error {
+	ba := p.c.GetBasicAuth()
+	p.basicAuthUser = ba.GetUsername()
+
+	switch ba.PasswordSource.(type) {
+	case *configpb.ProbeConf_BasicAuth_Password:
+		p.basicAuthPassword = ba.GetPassword()
+	case *configpb.ProbeConf_BasicAuth_PasswordFile:
+		b, err := os.ReadFile(ba.GetPasswordFile())
+		if err != nil {
+			return fmt.Errorf("error reading basic auth password file: %v", err)
+		}
+		p.basicAuthPassword = strings.TrimRight(string(b), "\r\n")
+	case *configpb.ProbeConf_BasicAuth_PasswordEnvVar:
+		v, ok := os.LookupEnv(ba.GetPasswordEnvVar())
+		if !ok {
+			return fmt.Errorf("basic auth password environment variable (%s) is not set", ba.GetPasswordEnvVar())
+		}
+		p.basicAuthPassword = v
+	}
+
+	return nil
+}
+
 // Init initializes the probe with the given params.
 func (p *Probe) Init(name string, opts *options.Options) error {
 	c, ok := opts.ProbeConf.(*configpb.ProbeConf)
@@ -179,7 +261,7 @@ func (p *Probe) Init(name string, opts *options.Options) error {
 		p.c = &configpb.ProbeConf{}
 	}
 
-	totalDuration := time.Duration(p.c.GetRequestsIntervalMsec()*p.c.GetRequestsPerProbe())*time.Millisecond + p.opts.Timeout
+	totalDuration := time.Duration(p.c.GetRequestsIntervalMsec()*p.c.GetRequestsPerProbe())*time.Millisecond + p.opts.MaxTimeout()
 	if totalDuration > p.opts.Interval {
 		return fmt.Errorf("invalid config - executing all requests will take "+
 			"longer than the probe interval, i.e. "+
@@ -187,6 +269,22 @@ func (p *Probe) Init(name string, opts *options.Options) error {
 			totalDuration, p.opts.Interval)
 	}
 
+	if p.c.GetForceHttp2() && p.c.GetDisableHttp2() {
+		return fmt.Errorf("only one of force_http2 and disable_http2 can be set")
+	}
+
+	if err := p.validateHTTP3Config(); err != nil {
+		return err
+	}
+
+	if p.c.GetMaxConcurrentRequests() < 0 {
+		return fmt.Errorf("invalid max_concurrent_requests (%d), should be >= 0", p.c.GetMaxConcurrentRequests())
+	}
+
+	if p.c.GetMaxResponseHeaderLabelValues() < 1 {
+		return fmt.Errorf("invalid max_response_header_label_values (%d), should be > 0", p.c.GetMaxResponseHeaderLabelValues())
+	}
+
 	p.method = p.c.GetMethod().String()
 
 	p.url = p.c.GetRelativeUrl()
@@ -195,7 +293,10 @@ func (p *Probe) Init(name string, opts *options.Options) error {
 	}
 
 	body := p.c.GetBody()
-	if len(body) == 0 && p.c.GetBodyFile() != "" {
+	if len(body) != 0 && p.c.GetBodyFile() != "" {
+		return fmt.Errorf("only one of body and body_file can be set")
+	}
+	if p.c.GetBodyFile() != "" {
 		b, err := os.ReadFile(p.c.GetBodyFile())
 		if err != nil {
 			return fmt.Errorf("error reading body file: %v", err)
@@ -204,6 +305,15 @@ func (p *Probe) Init(name string, opts *options.Options) error {
 	}
 	p.requestBody = httpreq.NewRequestBody(body...)
 
+	if p.c.GetBasicAuth() != nil {
+		if p.c.GetOauthConfig() != nil {
+			return fmt.Errorf("only one of oauth_config and basic_auth can be set")
+		}
+		if err := p.initBasicAuth(); err != nil {
+			return err
+		}
+	}
+
 	if p.c.GetOauthConfig() != nil {
 		oauthTS, err := oauth.TokenSourceFromConfig(p.c.GetOauthConfig(), p.l)
 		if err != nil {
@@ -212,16 +322,21 @@ func (p *Probe) Init(name string, opts *options.Options) error {
 		p.oauthTS = oauthTS
 	}
 
-	transport, err := p.getTransport()
+	var err error
+	if p.c.GetUseHttp3() {
+		p.baseTransport, err = p.getHTTP3Transport()
+	} else {
+		p.baseTransport, err = p.getTransport()
+	}
 	if err != nil {
 		return err
 	}
 
-	p.baseTransport = transport
-
 	if p.c.MaxRedirects != nil {
 		p.redirectFunc = func(req *http.Request, via []*http.Request) error {
-			if len(via) >= int(p.c.GetMaxRedirects()) {
+			// via includes the original request, so len(via) is the
+			// number of the redirect we are about to follow.
+			if len(via) > int(p.c.GetMaxRedirects()) {
 				return http.ErrUseLastResponse
 			}
 			return nil
@@ -257,7 +372,7 @@ func (p *Probe) addLatency(latency metrics.LatencyValue, start time.Time) {
 }
 
 func (p *Probe) requestTrace(result *probeResult) *httptrace.ClientTrace {
-	if result.latencyBreakdown == nil && result.connEvent == nil {
+	if result.latencyBreakdown == nil && result.connEvent == nil && result.connReused == nil {
 		return nil
 	}
 
@@ -278,6 +393,12 @@ func (p *Probe) requestTrace(result *probeResult) *httptrace.ClientTrace {
 			trace.TLSHandshakeStart = func() { tlsStart = time.Now() }
 			trace.TLSHandshakeDone = func(_ tls.ConnectionState, _ error) { p.addLatency(lb.tlsLatency, tlsStart) }
 		}
+		// QUIC handshake is reported through the TLS handshake hooks, see
+		// dialQUIC.
+		if lb.quicHandshakeLatency != nil {
+			trace.TLSHandshakeStart = func() { tlsStart = time.Now() }
+			trace.TLSHandshakeDone = func(_ tls.ConnectionState, _ error) { p.addLatency(lb.quicHandshakeLatency, tlsStart) }
+		}
 		if lb.reqWriteLatency != nil {
 			trace.WroteHeaders = func() { writeStart = time.Now() }
 			trace.WroteRequest = func(_ httptrace.WroteRequestInfo) { p.addLatency(lb.reqWriteLatency, writeStart) }
@@ -288,6 +409,18 @@ func (p *Probe) requestTrace(result *probeResult) *httptrace.ClientTrace {
 		}
 	}
 
+	if result.connReused != nil {
+		oldGotConn := trace.GotConn
+		trace.GotConn = func(info httptrace.GotConnInfo) {
+			if info.Reused {
+				result.connReused.Inc()
+			}
+			if oldGotConn != nil {
+				oldGotConn(info)
+			}
+		}
+	}
+
 	if result.connEvent != nil {
 		oldConnectDone := trace.ConnectDone
 		trace.ConnectDone = func(network, addr string, err error) {
@@ -306,20 +439,75 @@ func (p *Probe) requestTrace(result *probeResult) *httptrace.ClientTrace {
 	return trace
 }
 
+// readResponseBody reads the response body, failing if it's larger than
+// max_response_bytes.
+func (p *Probe) readResponseBody(resp *http.Response) ([]byte, error) {
+	maxBytes := p.c.GetMaxResponseBytes()
+	if maxBytes <= 0 {
+		return io.ReadAll(resp.Body)
+	}
+
+	b, err := io.ReadAll(io.LimitReader(resp.Body, maxBytes+1))
+	if err != nil {
+		return b, err
+	}
+	if int64(len(b)) > maxBytes {
+		return b, fmt.Errorf("response body larger than max_response_bytes (%d)", maxBytes)
+	}
+	return b, nil
+}
+
+// advertisesHTTP3 returns true if the response advertises HTTP/3 support
+// through the Alt-Svc header, e.g. Alt-Svc: h3=":443"; ma=86400.
+func advertisesHTTP3(h http.Header) bool {
+	for _, v := range h.Values("Alt-Svc") {
+		for _, svc := range strings.Split(v, ",") {
+			if strings.HasPrefix(strings.TrimSpace(svc), "h3=") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 // doHTTPRequest executes an HTTP request and updates the provided result struct.
 func (p *Probe) doHTTPRequest(req *http.Request, client *http.Client, target endpoint.Endpoint, result *probeResult, resultMu *sync.Mutex) error {
 	l := p.l.WithAttributes(slog.String("target", target.Name), slog.String("url", req.URL.String()))
 
-	req = p.prepareRequest(req)
-
-	start := time.Now()
-
 	if trace := p.requestTrace(result); trace != nil {
 		req = req.WithContext(httptrace.WithClientTrace(req.Context(), trace))
 	}
 
-	resp, err := client.Do(req)
-	latency := time.Since(start)
+	var resp *http.Response
+	var respBody []byte
+	var latency, bodyReadTime time.Duration
+
+	attempts, err := p.opts.RetryPolicy.Do(req.Context(), p.opts.TimeoutForTarget(target), func(ctx context.Context) error {
+		// Prepare request for each attempt, as request body can be read only
+		// once.
+		start := time.Now()
+		var err error
+		resp, err = client.Do(p.prepareRequest(req.WithContext(ctx)))
+		latency = time.Since(start)
+		if err != nil {
+			return err
+		}
+
+		// Read the body within the attempt, as attempt's context is canceled
+		// once it's done. Calling Body.Close() allows the TCP connection to be
+		// reused.
+		defer resp.Body.Close()
+		bodyReadStart := time.Now()
+		respBody, err = p.readResponseBody(resp)
+		bodyReadTime = time.Since(bodyReadStart)
+		return err
+	})
+
+	// HTTP/3 transport doesn't have an option to disable keep-alives, close
+	// the connection once we are done with the request.
+	if p.c.GetUseHttp3() && !p.c.GetKeepAlive() {
+		client.CloseIdleConnections()
+	}
 
 	if resultMu != nil {
 		// Note that we take lock on result object outside of the actual request.
@@ -328,6 +516,7 @@ func (p *Probe) doHTTPRequest(req *http.Request, client *http.Client, target end
 	}
 
 	result.total++
+	result.retries += int64(attempts - 1)
 
 	if err != nil {
 		if isClientTimeout(err) {
@@ -337,18 +526,30 @@ func (p *Probe) doHTTPRequest(req *http.Request, client *http.Client, target end
 		return err
 	}
 
-	respBody, err := io.ReadAll(resp.Body)
-	if err != nil {
-		l.Warning(err.Error())
-		return err
+	l.Debug("Response: \n" + string(respBody))
+
+	if result.respBytes != nil {
+		result.respBytes.IncBy(int64(len(respBody)))
 	}
 
-	l.Debug("Response: \n" + string(respBody))
+	if lb := result.latencyBreakdown; lb != nil && lb.bodyReadLatency != nil {
+		lb.bodyReadLatency.AddFloat64(bodyReadTime.Seconds() / p.opts.LatencyUnit.Seconds())
+	}
 
-	// Calling Body.Close() allows the TCP connection to be reused.
-	resp.Body.Close()
 	result.respCodes.IncKey(strconv.FormatInt(int64(resp.StatusCode), 10))
 
+	if result.respProtos != nil {
+		result.respProtos.IncKey(resp.Proto)
+		if advertisesHTTP3(resp.Header) {
+			result.h3Advertised++
+		}
+	}
+
+	hlr := p.headerLabelResult(result, resp.Header)
+	if hlr != nil {
+		hlr.total++
+	}
+
 	if resp.TLS != nil && len(resp.TLS.PeerCertificates) > 0 {
 		now := time.Now()
 		minExpirySeconds := resp.TLS.PeerCertificates[0].NotAfter.Sub(now).Seconds()
@@ -360,6 +561,7 @@ func (p *Probe) doHTTPRequest(req *http.Request, client *http.Client, target end
 		}
 
 		result.sslEarliestExpirationSeconds = int64(minExpirySeconds)
+		result.sslLeafCert = resp.TLS.PeerCertificates[0]
 	}
 
 	if p.opts.Validators != nil {
@@ -374,8 +576,15 @@ func (p *Probe) doHTTPRequest(req *http.Request, client *http.Client, target end
 		}
 	}
 
+	if attempts == 1 {
+		result.successFirstAttempt++
+	}
 	result.success++
 	result.latency.AddFloat64(latency.Seconds() / p.opts.LatencyUnit.Seconds())
+	if hlr != nil {
+		hlr.success++
+		hlr.latency.AddFloat64(latency.Seconds() / p.opts.LatencyUnit.Seconds())
+	}
 	if result.respBodies != nil && len(respBody) <= maxResponseSizeForMetrics {
 		result.respBodies.IncKey(string(respBody))
 	}
@@ -399,23 +608,32 @@ func (p *Probe) parseLatencyBreakdown(baseLatencyValue metrics.LatencyValue) *la
 	}
 
 	all := lbMap[configpb.ProbeConf_ALL_STAGES]
+	useHTTP3 := p.c.GetUseHttp3()
 
 	ld := &latencyDetails{}
 	if all || lbMap[configpb.ProbeConf_DNS_LATENCY] {
 		ld.dnsLatency = baseLatencyValue.Clone().(metrics.LatencyValue)
 	}
-	if all || lbMap[configpb.ProbeConf_CONNECT_LATENCY] {
+	// For HTTP/3, connection setup and TLS handshake are a single stage, the
+	// QUIC handshake.
+	if (all && !useHTTP3) || lbMap[configpb.ProbeConf_CONNECT_LATENCY] {
 		ld.connectLatency = baseLatencyValue.Clone().(metrics.LatencyValue)
 	}
-	if all || lbMap[configpb.ProbeConf_TLS_HANDSHAKE_LATENCY] {
+	if (all && !useHTTP3) || lbMap[configpb.ProbeConf_TLS_HANDSHAKE_LATENCY] {
 		ld.tlsLatency = baseLatencyValue.Clone().(metrics.LatencyValue)
 	}
+	if (all && useHTTP3) || lbMap[configpb.ProbeConf_QUIC_HANDSHAKE_LATENCY] {
+		ld.quicHandshakeLatency = baseLatencyValue.Clone().(metrics.LatencyValue)
+	}
 	if all || lbMap[configpb.ProbeConf_REQ_WRITE_LATENCY] {
 		ld.reqWriteLatency = baseLatencyValue.Clone().(metrics.LatencyValue)
 	}
 	if all || lbMap[configpb.ProbeConf_FIRST_BYTE_LATENCY] {
 		ld.firstByteLatency = baseLatencyValue.Clone().(metrics.LatencyValue)
 	}
+	if all || lbMap[configpb.ProbeConf_BODY_READ_LATENCY] {
+		ld.bodyReadLatency = baseLatencyValue.Clone().(metrics.LatencyValue)
+	}
 	return ld
 }
 
@@ -427,6 +645,7 @@ func (p *Probe) newResult() *probeResult {
 
 	if p.c.GetKeepAlive() {
 		result.connEvent = metrics.NewAtomicInt(0)
+		result.connReused = metrics.NewAtomicInt(0)
 	}
 
 	if p.opts.Validators != nil {
@@ -445,9 +664,22 @@ func (p *Probe) newResult() *probeResult {
 		result.respBodies = metrics.NewMap("resp")
 	}
 
+	if p.c.GetExportResponseSize() {
+		result.respBytes = metrics.NewInt(0)
+	}
+
+	if p.c.GetExportProtocolMetrics() {
+		result.respProtos = metrics.NewMap("proto")
+	}
+
 	return result
 }
 
+// SuccessCount returns the number of successful probe runs so far.
+func (result *probeResult) SuccessCount() int64 {
+	return result.success
+}
+
 func (result *probeResult) Metrics(ts time.Time, runID int64, opts *options.Options) []*metrics.EventMetrics {
 	var ems []*metrics.EventMetrics
 
@@ -462,14 +694,32 @@ func (result *probeResult) Metrics(ts time.Time, runID int64, opts *options.Opti
 		em.AddMetric("resp-body", result.respBodies.Clone())
 	}
 
+	if result.respBytes != nil {
+		em.AddMetric("resp-bytes", result.respBytes.Clone())
+	}
+
+	if result.respProtos != nil {
+		em.AddMetric("resp_proto", result.respProtos.Clone())
+		em.AddMetric("h3_advertised", metrics.NewInt(result.h3Advertised))
+	}
+
 	if result.connEvent != nil {
 		em.AddMetric("connect_event", result.connEvent.Clone())
 	}
 
+	if result.connReused != nil {
+		em.AddMetric("conn_reused", result.connReused.Clone())
+	}
+
 	if result.validationFailure != nil {
 		em.AddMetric("validation_failure", result.validationFailure)
 	}
 
+	if opts.RetryPolicy != nil {
+		em.AddMetric("success_first_attempt", metrics.NewInt(result.successFirstAttempt))
+		em.AddMetric("retries", metrics.NewInt(result.retries))
+	}
+
 	if result.latencyBreakdown != nil {
 		if dl := result.latencyBreakdown.dnsLatency; dl != nil {
 			em.AddMetric("dns_"+opts.LatencyMetricName, dl.Clone())
@@ -486,6 +736,12 @@ func (result *probeResult) Metrics(ts time.Time, runID int64, opts *options.Opti
 		if fbl := result.latencyBreakdown.firstByteLatency; fbl != nil {
 			em.AddMetric("first_byte_"+opts.LatencyMetricName, fbl.Clone())
 		}
+		if brl := result.latencyBreakdown.bodyReadLatency; brl != nil {
+			em.AddMetric("body_read_"+opts.LatencyMetricName, brl.Clone())
+		}
+		if ql := result.latencyBreakdown.quicHandshakeLatency; ql != nil {
+			em.AddMetric("quic_handshake_"+opts.LatencyMetricName, ql.Clone())
+		}
 	}
 
 	em.AddLabel("ptype", "http") // Other labels are added by scheduler.
@@ -502,6 +758,21 @@ func (result *probeResult) Metrics(ts time.Time, runID int64, opts *options.Opti
 		ems = append(ems, em)
 	}
 
+	ems = append(ems, result.headerLabelMetrics(ts, opts)...)
+
+	// Leaf certificate expiry, along with the certificate's issuer and serial
+	// number, to make it easy to identify the certificate.
+	if cert := result.sslLeafCert; cert != nil {
+		em := metrics.NewEventMetrics(ts).
+			AddMetric("ssl_cert_expiry_sec", metrics.NewInt(int64(cert.NotAfter.Sub(ts).Seconds())))
+		em.Kind = metrics.GAUGE
+		em.SetNotForAlerting()
+		em.AddLabel("ptype", "http")
+		em.AddLabel("issuer", cert.Issuer.CommonName)
+		em.AddLabel("serial", cert.SerialNumber.Text(16))
+		ems = append(ems, em)
+	}
+
 	// Append any payload metrics and reset.
 	// If there is only one timestamp, use the same timestamp for all metrics.
 	timestamps := map[time.Time]bool{}
@@ -521,27 +792,52 @@ func (result *probeResult) Metrics(ts time.Time, runID int64, opts *options.Opti
 	return ems
 }
 
+// tlsConfigForTarget updates the TLS config for the target. Config should
+// already be a copy of the probe's TLS config, as it's modified in place.
+func (p *Probe) tlsConfigForTarget(tlsConfig *tls.Config, target endpoint.Endpoint) *tls.Config {
+	// If we're resolving target first, url.Host will be an IP address.
+	// In that case, we need to set ServerName in TLSClientConfig to
+	// the actual hostname.
+	if p.schemeForTarget(target) == "https" && p.resolveFirst(target) {
+		if tlsConfig == nil {
+			tlsConfig = &tls.Config{}
+		}
+		if tlsConfig.ServerName == "" {
+			tlsConfig.ServerName = hostForTarget(target)
+		}
+	}
+
+	// TLS server name can use target substitutions, e.g. to use a
+	// per-target SNI from the target labels.
+	if tlsConfig != nil && strings.Contains(tlsConfig.ServerName, "@") {
+		tlsConfig.ServerName, _ = strtemplate.SubstituteLabels(tlsConfig.ServerName, p.targetLabels(target, p.portForTarget(target)))
+	}
+
+	return tlsConfig
+}
+
 func (p *Probe) httpClient(target endpoint.Endpoint) *http.Client {
+	if ht, ok := p.baseTransport.(*http3.Transport); ok {
+		return &http.Client{Transport: p.http3TransportForTarget(ht, target), CheckRedirect: p.redirectFunc}
+	}
+
 	// We check for http.Transport because tests use a custom
 	// RoundTripper implementation.
 	if ht, ok := p.baseTransport.(*http.Transport); ok {
 		t := ht.Clone()
+		t.TLSClientConfig = p.tlsConfigForTarget(t.TLSClientConfig, target)
 
-		// If we're resolving target first, url.Host will be an IP address.
-		// In that case, we need to set ServerName in TLSClientConfig to
-		// the actual hostname.
-		if p.schemeForTarget(target) == "https" && p.resolveFirst(target) {
-			if t.TLSClientConfig == nil {
-				t.TLSClientConfig = &tls.Config{}
-			}
-			if t.TLSClientConfig.ServerName == "" {
-				t.TLSClientConfig.ServerName = hostForTarget(target)
+		if socket := p.unixSocketForTarget(target); socket != "" {
+			dialer := &net.Dialer{Timeout: p.opts.MaxTimeout()}
+			t.DialContext = func(ctx context.Context, _, _ string) (net.Conn, error) {
+				return dialer.DialContext(ctx, "unix", socket)
 			}
+			t.Proxy = nil
 		}
 
-		return &http.Client{Transport: t}
+		return &http.Client{Transport: t, CheckRedirect: p.redirectFunc}
 	}
-	return &http.Client{Transport: p.baseTransport}
+	return &http.Client{Transport: p.baseTransport, CheckRedirect: p.redirectFunc}
 }
 
 // Returns clients for a target. We use a different HTTP client (transport) for
@@ -607,6 +903,13 @@ func (p *Probe) runProbe(ctx context.Context, runReq *sched.RunProbeForTargetReq
 	// anywhere else -- export of metrics happens when probe is not running.
 	var resultMu sync.Mutex
 
+	// If max_concurrent_requests is set, limit the number of requests in
+	// flight using a semaphore.
+	var sem chan struct{}
+	if n := p.c.GetMaxConcurrentRequests(); n > 0 && n < p.c.GetRequestsPerProbe() {
+		sem = make(chan struct{}, n)
+	}
+
 	wg := sync.WaitGroup{}
 	for numReq := 0; numReq < int(p.c.GetRequestsPerProbe()); numReq++ {
 		wg.Add(1)
@@ -614,6 +917,20 @@ func (p *Probe) runProbe(ctx context.Context, runReq *sched.RunProbeForTargetReq
 			defer wg.Done()
 
 			time.Sleep(time.Duration(numReq*int(p.c.GetRequestsIntervalMsec())) * time.Millisecond)
+			if sem != nil {
+				select {
+				case sem <- struct{}{}:
+					defer func() { <-sem }()
+				case <-ctx.Done():
+					// Probe run timed out while waiting for a slot, count the
+					// request as timed out.
+					resultMu.Lock()
+					defer resultMu.Unlock()
+					result.total++
+					result.timeouts++
+					return
+				}
+			}
 			// Ignore the error returned by doHTTPRequest, as it's already logged.
 			_ = p.doHTTPRequest(req.WithContext(ctx), tgtState.clients[numReq], target, result, &resultMu)
 		}(tgtState.req, numReq, target, result)
@@ -0,0 +1,208 @@
+// Copyright 2025 The Cloudprober Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package http
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/http/httptrace"
+	"strconv"
+	"time"
+
+	"github.com/cloudprober/cloudprober/common/iputils"
+	configpb "github.com/cloudprober/cloudprober/probes/http/proto"
+	"github.com/cloudprober/cloudprober/targets/endpoint"
+	"github.com/quic-go/quic-go"
+	"github.com/quic-go/quic-go/http3"
+)
+
+// validateHTTP3Config verifies that the config is compatible with the
+// use_http3 setting.
+func (p *Probe) validateHTTP3Config() error {
+	for _, lb := range p.c.GetLatencyBreakdown() {
+		switch lb {
+		case configpb.ProbeConf_QUIC_HANDSHAKE_LATENCY:
+			if !p.c.GetUseHttp3() {
+				return errors.New("QUIC_HANDSHAKE_LATENCY latency breakdown is supported only with use_http3")
+			}
+		case configpb.ProbeConf_CONNECT_LATENCY, configpb.ProbeConf_TLS_HANDSHAKE_LATENCY:
+			if p.c.GetUseHttp3() {
+				return fmt.Errorf("%s latency breakdown is not supported with use_http3, use QUIC_HANDSHAKE_LATENCY instead", lb)
+			}
+		}
+	}
+
+	if !p.c.GetUseHttp3() {
+		return nil
+	}
+
+	if p.c.GetForceHttp2() || p.c.GetDisableHttp2() {
+		return errors.New("force_http2 and disable_http2 can't be used with use_http3")
+	}
+	if p.c.GetProxyUrl() != "" {
+		return errors.New("proxy_url is not supported with use_http3")
+	}
+	if p.c.GetUnixSocket() != "" {
+		return errors.New("unix_socket is not supported with use_http3")
+	}
+	if p.c.SchemeType != nil && p.schemeForTarget(endpoint.Endpoint{}) != "https" {
+		return errors.New("use_http3 is supported only for HTTPS scheme")
+	}
+	return nil
+}
+
+func (p *Probe) getHTTP3Transport() (*http3.Transport, error) {
+	tlsConfig, err := p.tlsConfig()
+	if err != nil {
+		return nil, err
+	}
+	return p.newHTTP3Transport(tlsConfig), nil
+}
+
+func (p *Probe) newHTTP3Transport(tlsConfig *tls.Config) *http3.Transport {
+	quicConfig := &quic.Config{
+		HandshakeIdleTimeout: p.opts.MaxTimeout(),
+	}
+
+	// Same as IdleConnTimeout for HTTP/1.1 and HTTP/2 connections. Note that
+	// without keep_alive, connections are closed after each request.
+	if p.c.GetKeepAlive() {
+		quicConfig.MaxIdleTimeout = 2 * p.opts.Interval
+		if p.c.IdleConnTimeoutMsec != nil {
+			quicConfig.MaxIdleTimeout = time.Duration(p.c.GetIdleConnTimeoutMsec()) * time.Millisecond
+		}
+	}
+
+	return &http3.Transport{
+		TLSClientConfig: tlsConfig,
+		QUICConfig:      quicConfig,
+		Dial:            p.dialQUIC,
+	}
+}
+
+// http3TransportForTarget returns the HTTP/3 transport for the target. HTTP/3
+// transport can't be cloned, so instead of creating a new transport for each
+// client, clients share the probe's base transport. Targets that need their
+// own TLS server name, e.g. with resolve_first or with target substitutions
+// in the server name, share a transport per server name. Note that unlike
+// HTTP/1.1 and HTTP/2, requests_per_probe requests to a target are multiplexed
+// over the same QUIC connection.
+func (p *Probe) http3TransportForTarget(base *http3.Transport, target endpoint.Endpoint) *http3.Transport {
+	tlsConfig := p.tlsConfigForTarget(base.TLSClientConfig.Clone(), target)
+	if tlsConfig == nil || (base.TLSClientConfig != nil && tlsConfig.ServerName == base.TLSClientConfig.ServerName) {
+		return base
+	}
+
+	p.http3TransportsMu.Lock()
+	defer p.http3TransportsMu.Unlock()
+
+	if t := p.http3Transports[tlsConfig.ServerName]; t != nil {
+		return t
+	}
+	if p.http3Transports == nil {
+		p.http3Transports = make(map[string]*http3.Transport)
+	}
+	t := p.newHTTP3Transport(tlsConfig)
+	p.http3Transports[tlsConfig.ServerName] = t
+	return t
+}
+
+// resolveUDPAddr resolves the UDP address for the QUIC connection, reporting
+// DNS resolution to the client trace.
+func (p *Probe) resolveUDPAddr(ctx context.Context, trace *httptrace.ClientTrace, addr string) (*net.UDPAddr, error) {
+	host, portStr, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, err
+	}
+	port, err := strconv.Atoi(portStr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid port in address %s: %v", addr, err)
+	}
+
+	if ip := net.ParseIP(host); ip != nil {
+		return &net.UDPAddr{IP: ip, Port: port}, nil
+	}
+
+	network := "ip"
+	if p.opts.IPVersion != 0 {
+		network = "ip" + strconv.Itoa(p.opts.IPVersion)
+	}
+
+	if trace != nil && trace.DNSStart != nil {
+		trace.DNSStart(httptrace.DNSStartInfo{Host: host})
+	}
+	ips, err := net.DefaultResolver.LookupIP(ctx, network, host)
+	if trace != nil && trace.DNSDone != nil {
+		trace.DNSDone(httptrace.DNSDoneInfo{Err: err})
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &net.UDPAddr{IP: ips[0], Port: port}, nil
+}
+
+// dialQUIC establishes a new QUIC connection. Unlike the HTTP/3 transport's
+// default dialer, we use a new UDP socket for each connection, so that we can
+// use the configured source IP and interface, and the socket goes away along
+// with the connection. QUIC handshake is reported to the client trace through
+// the TLS handshake hooks, as TLS handshake is part of the QUIC handshake.
+func (p *Probe) dialQUIC(ctx context.Context, addr string, tlsConfig *tls.Config, quicConfig *quic.Config) (*quic.Conn, error) {
+	trace := httptrace.ContextClientTrace(ctx)
+
+	raddr, err := p.resolveUDPAddr(ctx, trace, addr)
+	if err != nil {
+		return nil, err
+	}
+
+	lc := &net.ListenConfig{}
+	if p.opts.BindInterface != "" {
+		lc.Control = iputils.BindToDeviceControl(p.opts.BindInterface)
+	}
+	pconn, err := lc.ListenPacket(ctx, "udp", (&net.UDPAddr{IP: p.opts.SourceIP}).String())
+	if err != nil {
+		return nil, err
+	}
+
+	if trace != nil && trace.ConnectStart != nil {
+		trace.ConnectStart("udp", raddr.String())
+	}
+	if trace != nil && trace.TLSHandshakeStart != nil {
+		trace.TLSHandshakeStart()
+	}
+
+	conn, err := quic.Dial(ctx, pconn, raddr, tlsConfig, quicConfig)
+
+	if trace != nil && trace.TLSHandshakeDone != nil {
+		var state tls.ConnectionState
+		if conn != nil {
+			state = conn.ConnectionState().TLS
+		}
+		trace.TLSHandshakeDone(state, err)
+	}
+	if trace != nil && trace.ConnectDone != nil {
+		trace.ConnectDone("udp", raddr.String(), err)
+	}
+
+	if err != nil {
+		pconn.Close()
+		return nil, err
+	}
+
+	context.AfterFunc(conn.Context(), func() { pconn.Close() })
+	return conn, nil
+}
@@ -22,6 +22,7 @@ import (
 	"strings"
 
 	"github.com/cloudprober/cloudprober/common/iputils"
+	"github.com/cloudprober/cloudprober/common/strtemplate"
 	"github.com/cloudprober/cloudprober/internal/httpreq"
 	"github.com/cloudprober/cloudprober/logger"
 	configpb "github.com/cloudprober/cloudprober/probes/http/proto"
@@ -64,9 +65,30 @@ func (p *Probe) schemeForTarget(target endpoint.Endpoint) string {
 		}
 	}
 
+	// HTTP/3 is always over TLS.
+	if p.c.GetUseHttp3() {
+		return "https"
+	}
 	return "http"
 }
 
+const unixSocketPrefix = "unix://"
+
+// unixSocketForTarget returns the Unix domain socket to connect to for the
+// target, if any. Socket is either configured explicitly through the
+// unix_socket field, or is part of the target name, e.g.
+// unix:///var/run/docker.sock.
+func (p *Probe) unixSocketForTarget(target endpoint.Endpoint) string {
+	if p.c.GetUnixSocket() != "" {
+		socket, _ := strtemplate.SubstituteLabels(p.c.GetUnixSocket(), p.targetLabels(target, 0))
+		return socket
+	}
+	if socket, ok := strings.CutPrefix(target.Name, unixSocketPrefix); ok {
+		return socket
+	}
+	return ""
+}
+
 func hostForTarget(target endpoint.Endpoint) string {
 	for _, label := range []string{"fqdn", "__cp_host__"} {
 		if target.Labels[label] != "" {
@@ -74,6 +96,12 @@ func hostForTarget(target endpoint.Endpoint) string {
 		}
 	}
 
+	// For Unix socket targets, e.g. unix:///var/run/docker.sock, use
+	// localhost as the host.
+	if strings.HasPrefix(target.Name, unixSocketPrefix) {
+		return "localhost"
+	}
+
 	return handleIPv6(target.Name)
 }
 
@@ -94,7 +122,25 @@ func pathForTarget(target endpoint.Endpoint, probeURL string) string {
 	return ""
 }
 
+// portForTarget returns the port to use for the target. Port from the
+// port_label target label takes precedence, followed by the configured port,
+// and then the target's own port.
+func (p *Probe) portForTarget(target endpoint.Endpoint) int {
+	if label := p.c.GetPortLabel(); label != "" {
+		if port, err := strconv.Atoi(target.Labels[label]); err == nil && port > 0 && port < 65536 {
+			return port
+		}
+	}
+	if port := int(p.c.GetPort()); port != 0 {
+		return port
+	}
+	return target.Port
+}
+
 func (p *Probe) resolveFirst(target endpoint.Endpoint) bool {
+	if p.unixSocketForTarget(target) != "" {
+		return false
+	}
 	if p.c.ResolveFirst != nil {
 		return p.c.GetResolveFirst()
 	}
@@ -130,6 +176,47 @@ func (p *Probe) setHeaders(req *http.Request, host string, port int) {
 	req.Host = hostHeader
 }
 
+// targetLabels returns the values for the target substitution tokens that can
+// be used in the relative URL and the header values, e.g. @target.label.zone@.
+func (p *Probe) targetLabels(target endpoint.Endpoint, port int) map[string]string {
+	labels := map[string]string{
+		"probe":       p.name,
+		"target":      target.Name,
+		"target.name": target.Name,
+		"target.port": strconv.Itoa(port),
+	}
+	if target.IP != nil {
+		labels["target.ip"] = target.IP.String()
+	}
+	for k, v := range target.Labels {
+		labels["target.label."+k] = v
+	}
+	return labels
+}
+
+// substituteTargetLabels substitutes the target tokens (see targetLabels) in
+// the header values and the host header. Unknown tokens are left as is.
+func (p *Probe) substituteTargetLabels(req *http.Request, target endpoint.Endpoint, port int) {
+	var labels map[string]string
+	substitute := func(s string) string {
+		if !strings.Contains(s, "@") {
+			return s
+		}
+		if labels == nil {
+			labels = p.targetLabels(target, port)
+		}
+		s, _ = strtemplate.SubstituteLabels(s, labels)
+		return s
+	}
+
+	req.Host = substitute(req.Host)
+	for _, values := range req.Header {
+		for i, v := range values {
+			values[i] = substitute(v)
+		}
+	}
+}
+
 func (p *Probe) urlHostAndIPLabel(target endpoint.Endpoint, host string) (string, string, error) {
 	if !p.resolveFirst(target) {
 		return host, "", nil
@@ -147,11 +234,7 @@ func (p *Probe) urlHostAndIPLabel(target endpoint.Endpoint, host string) (string
 
 func (p *Probe) httpRequestForTarget(target endpoint.Endpoint) (*http.Request, error) {
 	// Prepare HTTP.Request for Client.Do
-	port := int(p.c.GetPort())
-	// If port is not configured explicitly, use target's port if available.
-	if port == 0 {
-		port = target.Port
-	}
+	port := p.portForTarget(target)
 
 	host := hostForTarget(target)
 
@@ -164,7 +247,12 @@ func (p *Probe) httpRequestForTarget(target endpoint.Endpoint) (*http.Request, e
 		return nil, err
 	}
 
-	url := fmt.Sprintf("%s://%s%s", p.schemeForTarget(target), hostWithPort(urlHost, port), pathForTarget(target, p.url))
+	path := pathForTarget(target, p.url)
+	if strings.Contains(path, "@") {
+		path, _ = strtemplate.SubstituteLabels(path, p.targetLabels(target, port))
+	}
+
+	url := fmt.Sprintf("%s://%s%s", p.schemeForTarget(target), hostWithPort(urlHost, port), path)
 
 	req, err := httpreq.NewRequest(p.method, url, p.requestBody)
 	if err != nil {
@@ -175,6 +263,11 @@ func (p *Probe) httpRequestForTarget(target endpoint.Endpoint) (*http.Request, e
 	if p.c.GetUserAgent() != "" {
 		req.Header.Set("User-Agent", p.c.GetUserAgent())
 	}
+	p.substituteTargetLabels(req, target, port)
+
+	if p.c.GetBasicAuth() != nil {
+		req.SetBasicAuth(p.basicAuthUser, p.basicAuthPassword)
+	}
 
 	return req, nil
 }
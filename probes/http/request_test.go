@@ -18,6 +18,8 @@ import (
 	"fmt"
 	"net"
 	"net/http"
+	"os"
+	"path/filepath"
 	"strconv"
 	"testing"
 	"time"
@@ -521,6 +523,153 @@ func TestRequestHasConfiguredHeaders(t *testing.T) {
 	assert.Contains(t, val, testHeadersValue)
 }
 
+func TestRequestHeaderTargetSubstitution(t *testing.T) {
+	p := &Probe{}
+	opts := &options.Options{
+		Targets:  targets.StaticTargets("test.com"),
+		Interval: 10 * time.Millisecond,
+		ProbeConf: &configpb.ProbeConf{
+			Port: proto.Int32(8080),
+			Header: map[string]string{
+				"Host":          "@target.label.vhost@",
+				"X-Target":      "@target@:@target.port@",
+				"X-Probe":       "@probe@",
+				"X-Unknown":     "@target.label.missing@",
+				"X-Email":       "probes@example.com",
+				"Authorization": "Bearer token",
+			},
+		},
+	}
+	assert.NoError(t, p.Init("http_test", opts))
+
+	req, err := p.httpRequestForTarget(endpoint.Endpoint{
+		Name:   "web-1",
+		Labels: map[string]string{"vhost": "www.example.com"},
+	})
+	assert.NoError(t, err)
+
+	assert.Equal(t, "www.example.com", req.Host)
+	assert.Equal(t, "web-1:8080", req.Header.Get("X-Target"))
+	assert.Equal(t, "http_test", req.Header.Get("X-Probe"))
+	assert.Equal(t, "@target.label.missing@", req.Header.Get("X-Unknown"))
+	assert.Equal(t, "probes@example.com", req.Header.Get("X-Email"))
+	assert.Equal(t, "Bearer token", req.Header.Get("Authorization"))
+	assert.Equal(t, "http://web-1:8080", req.URL.String())
+}
+
+func TestBasicAuth(t *testing.T) {
+	passwordFile := filepath.Join(t.TempDir(), "password")
+	assert.NoError(t, os.WriteFile(passwordFile, []byte("file-secret\n"), 0600))
+	t.Setenv("CLOUDPROBER_TEST_PASSWORD", "env-secret")
+
+	tests := []struct {
+		desc         string
+		basicAuth    *configpb.ProbeConf_BasicAuth
+		wantPassword string
+	}{
+		{
+			desc: "inline",
+			basicAuth: &configpb.ProbeConf_BasicAuth{
+				Username:       proto.String("user"),
+				PasswordSource: &configpb.ProbeConf_BasicAuth_Password{Password: "inline-secret"},
+			},
+			wantPassword: "inline-secret",
+		},
+		{
+			desc: "file",
+			basicAuth: &configpb.ProbeConf_BasicAuth{
+				Username:       proto.String("user"),
+				PasswordSource: &configpb.ProbeConf_BasicAuth_PasswordFile{PasswordFile: passwordFile},
+			},
+			wantPassword: "file-secret",
+		},
+		{
+			desc: "env_var",
+			basicAuth: &configpb.ProbeConf_BasicAuth{
+				Username:       proto.String("user"),
+				PasswordSource: &configpb.ProbeConf_BasicAuth_PasswordEnvVar{PasswordEnvVar: "CLOUDPROBER_TEST_PASSWORD"},
+			},
+			wantPassword: "env-secret",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			p := &Probe{}
+			opts := &options.Options{
+				Targets:   targets.StaticTargets("test.com"),
+				Interval:  10 * time.Millisecond,
+				ProbeConf: &configpb.ProbeConf{BasicAuth: test.basicAuth},
+			}
+			assert.NoError(t, p.Init("http_test", opts))
+
+			req, err := p.httpRequestForTarget(endpoint.Endpoint{Name: "test.com"})
+			assert.NoError(t, err)
+
+			user, password, ok := req.BasicAuth()
+			assert.True(t, ok)
+			assert.Equal(t, "user", user)
+			assert.Equal(t, test.wantPassword, password)
+		})
+	}
+}
+
+func TestRelativeURLTargetSubstitution(t *testing.T) {
+	p := &Probe{}
+	opts := &options.Options{
+		Targets:  targets.StaticTargets("test.com"),
+		Interval: 10 * time.Millisecond,
+		ProbeConf: &configpb.ProbeConf{
+			RelativeUrl: proto.String("/@target.label.service@/healthz?host=@target.name@&x=@unknown@"),
+		},
+	}
+	assert.NoError(t, p.Init("http_test", opts))
+
+	req, err := p.httpRequestForTarget(endpoint.Endpoint{
+		Name:   "web-1",
+		Labels: map[string]string{"service": "orders"},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "http://web-1/orders/healthz?host=web-1&x=@unknown@", req.URL.String())
+}
+
+func TestPortLabel(t *testing.T) {
+	p := &Probe{}
+	opts := &options.Options{
+		Targets:  targets.StaticTargets("test.com"),
+		Interval: 10 * time.Millisecond,
+		ProbeConf: &configpb.ProbeConf{
+			Port:      proto.Int32(9090),
+			PortLabel: proto.String("port"),
+		},
+	}
+	assert.NoError(t, p.Init("http_test", opts))
+
+	for _, test := range []struct {
+		target  endpoint.Endpoint
+		wantURL string
+	}{
+		{
+			target:  endpoint.Endpoint{Name: "web-1", Labels: map[string]string{"port": "8081"}},
+			wantURL: "http://web-1:8081",
+		},
+		{
+			target:  endpoint.Endpoint{Name: "web-2", Port: 8082},
+			wantURL: "http://web-2:9090",
+		},
+		{
+			target:  endpoint.Endpoint{Name: "web-3", Labels: map[string]string{"port": "not-a-port"}},
+			wantURL: "http://web-3:9090",
+		},
+	} {
+		t.Run(test.target.Name, func(t *testing.T) {
+			req, err := p.httpRequestForTarget(test.target)
+			assert.NoError(t, err)
+			assert.Equal(t, test.wantURL, req.URL.String())
+		})
+	}
+}
+
 func TestResolveFirst(t *testing.T) {
 	tests := []struct {
 		name   string
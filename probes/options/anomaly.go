@@ -0,0 +1,246 @@
+// Copyright 2025 The Cloudprober Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package options
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/cloudprober/cloudprober/internal/alerting"
+	alertpb "github.com/cloudprober/cloudprober/internal/alerting/proto"
+	"github.com/cloudprober/cloudprober/logger"
+	"github.com/cloudprober/cloudprober/metrics"
+	configpb "github.com/cloudprober/cloudprober/probes/proto"
+	"github.com/cloudprober/cloudprober/targets/endpoint"
+	"google.golang.org/protobuf/proto"
+)
+
+// Scale factor to make MAD a consistent estimator of the standard deviation
+// for normally distributed data.
+const madScale = 1.4826
+
+// anomalyTargetState keeps track of a target's latency baseline.
+type anomalyTargetState struct {
+	// Last seen cumulative values.
+	success    int64
+	latencySum float64
+
+	samples        int
+	mean, variance float64   // Used by EWMA.
+	window         []float64 // Used by MAD.
+
+	// Number of samples checked and found anomalous. These are used for
+	// alerting.
+	checks, anomalies int64
+
+	lastSeen time.Time
+}
+
+// anomalyDetector detects latency anomalies for each target.
+type anomalyDetector struct {
+	method        configpb.AnomalyDetection_Method
+	threshold     float64
+	ewmaAlpha     float64
+	windowSize    int
+	minSamples    int
+	interval      time.Duration
+	latencyMetric string
+	alertHandlers []*alerting.AlertHandler
+
+	mu        sync.Mutex
+	targets   map[string]*anomalyTargetState
+	lastPrune time.Time
+}
+
+func newAnomalyDetector(c *configpb.AnomalyDetection, opts *Options, l *logger.Logger) (*anomalyDetector, error) {
+	if c.GetThreshold() <= 0 {
+		return nil, fmt.Errorf("anomaly_detection: threshold (%f) should be positive", c.GetThreshold())
+	}
+	if c.GetEwmaAlpha() <= 0 || c.GetEwmaAlpha() > 1 {
+		return nil, fmt.Errorf("anomaly_detection: ewma_alpha (%f) should be in the range (0, 1]", c.GetEwmaAlpha())
+	}
+	if c.GetWindowSize() < 3 {
+		return nil, fmt.Errorf("anomaly_detection: window_size (%d) should be at least 3", c.GetWindowSize())
+	}
+	if c.GetMinSamples() < 1 {
+		return nil, fmt.Errorf("anomaly_detection: min_samples (%d) should be at least 1", c.GetMinSamples())
+	}
+
+	ad := &anomalyDetector{
+		method:        c.GetMethod(),
+		threshold:     float64(c.GetThreshold()),
+		ewmaAlpha:     float64(c.GetEwmaAlpha()),
+		windowSize:    int(c.GetWindowSize()),
+		minSamples:    int(c.GetMinSamples()),
+		interval:      opts.StatsExportInterval,
+		latencyMetric: opts.LatencyMetricName,
+		targets:       make(map[string]*anomalyTargetState),
+	}
+
+	for _, alertConf := range c.GetAlert() {
+		// Don't modify the caller's config.
+		alertConf = proto.Clone(alertConf).(*alertpb.AlertConf)
+		if alertConf.GetName() == "" {
+			alertConf.Name = opts.Name + "-latency-anomaly"
+		}
+		ah, err := alerting.NewAlertHandler(alertConf, opts.Name, l)
+		if err != nil {
+			return nil, fmt.Errorf("anomaly_detection: error creating alert handler: %v", err)
+		}
+		ad.alertHandlers = append(ad.alertHandlers, ah)
+	}
+
+	return ad, nil
+}
+
+func median(values []float64) float64 {
+	sorted := append([]float64{}, values...)
+	sort.Float64s(sorted)
+	n := len(sorted)
+	if n%2 == 1 {
+		return sorted[n/2]
+	}
+	return (sorted[n/2-1] + sorted[n/2]) / 2
+}
+
+// check returns baseline latency and the anomaly score for the given sample,
+// and updates the baseline with the sample.
+func (ad *anomalyDetector) check(st *anomalyTargetState, x float64) (baseline, score float64) {
+	defer func() { st.samples++ }()
+
+	if ad.method == configpb.AnomalyDetection_MAD {
+		if len(st.window) > 0 {
+			baseline = median(st.window)
+			deviations := make([]float64, len(st.window))
+			for i, v := range st.window {
+				deviations[i] = math.Abs(v - baseline)
+			}
+			if mad := median(deviations); mad > 0 {
+				score = (x - baseline) / (madScale * mad)
+			}
+		}
+		st.window = append(st.window, x)
+		if len(st.window) > ad.windowSize {
+			st.window = st.window[1:]
+		}
+		return baseline, score
+	}
+
+	if st.samples == 0 {
+		st.mean = x
+		return x, 0
+	}
+	baseline = st.mean
+	if std := math.Sqrt(st.variance); std > 0 {
+		score = (x - baseline) / std
+	}
+	alpha := ad.ewmaAlpha
+	diff := x - st.mean
+	st.mean += alpha * diff
+	st.variance = (1 - alpha) * (st.variance + alpha*diff*diff)
+	return baseline, score
+}
+
+// prune forgets targets that have not reported in a while, most likely they
+// have been removed. It's called with the lock held.
+func (ad *anomalyDetector) prune(now time.Time) {
+	if ad.interval <= 0 || now.Sub(ad.lastPrune) < ad.interval {
+		return
+	}
+	ad.lastPrune = now
+	for key, st := range ad.targets {
+		if now.Sub(st.lastSeen) > 2*ad.interval {
+			delete(ad.targets, key)
+		}
+	}
+}
+
+// record checks the target's latency over its last stats export interval for
+// anomalies. It returns the anomaly EventMetrics if a check was performed.
+func (ad *anomalyDetector) record(ep endpoint.Endpoint, em *metrics.EventMetrics) *metrics.EventMetrics {
+	if em.Kind != metrics.CUMULATIVE {
+		return nil
+	}
+	successV, ok := em.Metric("success").(metrics.NumValue)
+	if !ok {
+		return nil
+	}
+	latency, ok := latencySum(em.Metric(ad.latencyMetric))
+	if !ok {
+		return nil
+	}
+
+	ad.mu.Lock()
+
+	ad.prune(em.Timestamp)
+	st := ad.targets[ep.Key()]
+	if st == nil {
+		st = &anomalyTargetState{}
+		ad.targets[ep.Key()] = st
+	}
+	st.lastSeen = em.Timestamp
+
+	success := successV.Int64()
+	// If counters went down, target's results were reset.
+	if success < st.success {
+		st.success, st.latencySum = 0, 0
+	}
+	deltaSuccess, deltaLatency := success-st.success, latency-st.latencySum
+	st.success, st.latencySum = success, latency
+
+	// No successful probes, nothing to check.
+	if deltaSuccess <= 0 {
+		ad.mu.Unlock()
+		return nil
+	}
+
+	warmedUp := st.samples >= ad.minSamples
+	baseline, score := ad.check(st, deltaLatency/float64(deltaSuccess))
+
+	var anomaly int64
+	if warmedUp && score > ad.threshold {
+		anomaly = 1
+	}
+	st.checks++
+	st.anomalies += anomaly
+	checks, anomalies := st.checks, st.anomalies
+
+	ad.mu.Unlock()
+
+	// Alert handlers work with total and success counters. For them a check
+	// is a "probe", and an anomaly is a "failure".
+	if warmedUp && len(ad.alertHandlers) > 0 {
+		alertEM := metrics.NewEventMetrics(em.Timestamp).
+			AddMetric("total", metrics.NewInt(checks)).
+			AddMetric("success", metrics.NewInt(checks-anomalies))
+		for _, ah := range ad.alertHandlers {
+			ah.Record(ep, alertEM)
+		}
+	}
+
+	anomalyEM := metrics.NewEventMetrics(em.Timestamp).
+		AddMetric("anomaly", metrics.NewInt(anomaly)).
+		AddMetric(ad.latencyMetric+"_baseline", metrics.NewFloat(baseline)).
+		AddMetric("anomaly_score", metrics.NewFloat(score))
+	anomalyEM.Kind = metrics.GAUGE
+	anomalyEM.SetNotForAlerting()
+	for _, k := range em.LabelsKeys() {
+		anomalyEM.AddLabel(k, em.Label(k))
+	}
+	return anomalyEM
+}
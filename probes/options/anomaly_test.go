@@ -0,0 +1,151 @@
+// Copyright 2025 The Cloudprober Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package options
+
+import (
+	"testing"
+	"time"
+
+	alertpb "github.com/cloudprober/cloudprober/internal/alerting/proto"
+	"github.com/cloudprober/cloudprober/metrics"
+	configpb "github.com/cloudprober/cloudprober/probes/proto"
+	"github.com/cloudprober/cloudprober/targets/endpoint"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestNewAnomalyDetectorErrors(t *testing.T) {
+	for _, c := range []*configpb.AnomalyDetection{
+		{Threshold: proto.Float32(0)},
+		{EwmaAlpha: proto.Float32(1.5)},
+		{WindowSize: proto.Int32(2)},
+		{MinSamples: proto.Int32(0)},
+	} {
+		_, err := newAnomalyDetector(c, DefaultOptions(), nil)
+		assert.Error(t, err, "config: %v", c)
+	}
+}
+
+func TestAnomalyDetector(t *testing.T) {
+	for _, method := range []configpb.AnomalyDetection_Method{configpb.AnomalyDetection_EWMA, configpb.AnomalyDetection_MAD} {
+		t.Run(method.String(), func(t *testing.T) {
+			p := &configpb.ProbeDef{
+				Name:    proto.String("test-probe"),
+				Type:    configpb.ProbeDef_HTTP.Enum(),
+				Targets: testTargets,
+				AnomalyDetection: &configpb.AnomalyDetection{
+					Method:     method.Enum(),
+					MinSamples: proto.Int32(10),
+				},
+			}
+			opts, err := BuildProbeOptions(p, nil, nil, nil)
+			if err != nil {
+				t.Fatalf("BuildProbeOptions() error: %v", err)
+			}
+
+			ep := endpoint.Endpoint{Name: "t1"}
+			ts := time.Now()
+			var success int64
+			var latencySum float64
+
+			// Record avgLatency for 10 successful probes and return the anomaly
+			// EventMetrics.
+			recordWindow := func(avgLatency float64) *metrics.EventMetrics {
+				success += 10
+				latencySum += 10 * avgLatency
+				ts = ts.Add(10 * time.Second)
+				em := metrics.NewEventMetrics(ts).
+					AddMetric("total", metrics.NewInt(success)).
+					AddMetric("success", metrics.NewInt(success)).
+					AddMetric("latency", metrics.NewFloat(latencySum)).
+					AddLabel("dst", "t1")
+
+				dataChan := make(chan *metrics.EventMetrics, 10)
+				opts.RecordMetrics(ep, em, dataChan)
+				assert.Len(t, dataChan, 2)
+				<-dataChan
+				return <-dataChan
+			}
+
+			// Build baseline, with some noise.
+			for i := 0; i < 20; i++ {
+				em := recordWindow(100 + float64(i%3)*5)
+				assert.Equal(t, int64(0), em.Metric("anomaly").(*metrics.Int).Int64(), "sample %d", i)
+				assert.Equal(t, "t1", em.Label("dst"))
+				assert.Equal(t, metrics.Kind(metrics.GAUGE), em.Kind)
+			}
+
+			em := recordWindow(200)
+			assert.Equal(t, int64(1), em.Metric("anomaly").(*metrics.Int).Int64())
+			baseline := em.Metric("latency_baseline").(*metrics.Float).Float64()
+			assert.InDelta(t, 105, baseline, 5)
+
+			// Lower latency is not an anomaly.
+			em = recordWindow(50)
+			assert.Equal(t, int64(0), em.Metric("anomaly").(*metrics.Int).Int64())
+		})
+	}
+}
+
+func TestAnomalyDetectorWarmup(t *testing.T) {
+	ad, err := newAnomalyDetector(&configpb.AnomalyDetection{MinSamples: proto.Int32(3)}, DefaultOptions(), nil)
+	if err != nil {
+		t.Fatalf("newAnomalyDetector() error: %v", err)
+	}
+
+	ep := endpoint.Endpoint{Name: "t1"}
+	for i, latency := range []float64{10, 10, 1000} {
+		em := metrics.NewEventMetrics(time.Now()).
+			AddMetric("success", metrics.NewInt(int64(i+1))).
+			AddMetric("latency", metrics.NewFloat(latency))
+		// Before warm-up, no anomalies are reported.
+		anomalyEM := ad.record(ep, em)
+		assert.Equal(t, int64(0), anomalyEM.Metric("anomaly").(*metrics.Int).Int64())
+	}
+}
+
+func TestAnomalyDetectorConfigAndPruning(t *testing.T) {
+	c := &configpb.AnomalyDetection{
+		Alert: []*alertpb.AlertConf{{}},
+	}
+	opts := DefaultOptions()
+	opts.Name = "test-probe"
+	opts.StatsExportInterval = 10 * time.Second
+
+	ad, err := newAnomalyDetector(c, opts, nil)
+	if err != nil {
+		t.Fatalf("newAnomalyDetector() error: %v", err)
+	}
+	assert.True(t, proto.Equal(&alertpb.AlertConf{}, c.GetAlert()[0]), "config should not be modified: %v", c.GetAlert()[0])
+
+	ts := time.Now()
+	record := func(target string, ts time.Time) {
+		em := metrics.NewEventMetrics(ts).
+			AddMetric("success", metrics.NewInt(1)).
+			AddMetric("latency", metrics.NewFloat(10))
+		ad.record(endpoint.Endpoint{Name: target}, em)
+	}
+	record("t1", ts)
+	record("t2", ts)
+	assert.Len(t, ad.targets, 2)
+
+	// t1 goes away, t2 keeps reporting.
+	for i := 1; i <= 3; i++ {
+		record("t2", ts.Add(time.Duration(i)*10*time.Second))
+	}
+	assert.Len(t, ad.targets, 1)
+	t2 := endpoint.Endpoint{Name: "t2"}
+	assert.NotNil(t, ad.targets[t2.Key()])
+}
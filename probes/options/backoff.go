@@ -0,0 +1,82 @@
+// Copyright 2025 The Cloudprober Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package options
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"time"
+
+	configpb "github.com/cloudprober/cloudprober/probes/proto"
+)
+
+// Max jitter, as a fraction of the backoff interval.
+const backoffJitterFraction = 0.1
+
+// FailureBackoff implements exponential backoff of the probe interval on
+// sustained failures.
+type FailureBackoff struct {
+	FailureThreshold int
+	Multiplier       float64
+	MaxInterval      time.Duration
+}
+
+// NewFailureBackoff creates FailureBackoff from the given config. interval is
+// the normal probe interval.
+func NewFailureBackoff(c *configpb.FailureBackoff, interval time.Duration) (*FailureBackoff, error) {
+	fb := &FailureBackoff{
+		FailureThreshold: int(c.GetFailureThreshold()),
+		Multiplier:       float64(c.GetMultiplier()),
+	}
+
+	if fb.FailureThreshold < 1 {
+		return nil, fmt.Errorf("failure_threshold (%d) should be at least 1", fb.FailureThreshold)
+	}
+	if fb.Multiplier < 1 {
+		return nil, fmt.Errorf("multiplier (%f) should be at least 1", fb.Multiplier)
+	}
+
+	var err error
+	if fb.MaxInterval, err = time.ParseDuration(c.GetMaxInterval()); err != nil {
+		return nil, fmt.Errorf("failed to parse max_interval (%s): %v", c.GetMaxInterval(), err)
+	}
+	if fb.MaxInterval < interval {
+		return nil, fmt.Errorf("max_interval (%v) cannot be smaller than the probe interval (%v)", fb.MaxInterval, interval)
+	}
+
+	return fb, nil
+}
+
+// Interval returns the probe interval to use after the given number of
+// consecutive failures. It returns the normal probe interval if
+// consecutive failures are below the failure threshold.
+func (fb *FailureBackoff) Interval(interval time.Duration, failures int) time.Duration {
+	if fb == nil || failures < fb.FailureThreshold {
+		return interval
+	}
+
+	d := float64(interval) * math.Pow(fb.Multiplier, float64(failures-fb.FailureThreshold+1))
+	d = math.Min(d, float64(fb.MaxInterval))
+
+	// Subtract jitter, so that targets that started failing at the same time
+	// don't stay in lockstep, and we never go over the max interval.
+	d -= d * backoffJitterFraction * rand.Float64()
+
+	if time.Duration(d) < interval {
+		return interval
+	}
+	return time.Duration(d)
+}
@@ -0,0 +1,99 @@
+// Copyright 2025 The Cloudprober Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package options
+
+import (
+	"testing"
+	"time"
+
+	configpb "github.com/cloudprober/cloudprober/probes/proto"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestNewFailureBackoff(t *testing.T) {
+	tests := []struct {
+		name    string
+		c       *configpb.FailureBackoff
+		want    *FailureBackoff
+		wantErr bool
+	}{
+		{
+			name: "default",
+			c:    &configpb.FailureBackoff{},
+			want: &FailureBackoff{FailureThreshold: 3, Multiplier: 2, MaxInterval: 5 * time.Minute},
+		},
+		{
+			name:    "bad_threshold",
+			c:       &configpb.FailureBackoff{FailureThreshold: proto.Int32(0)},
+			wantErr: true,
+		},
+		{
+			name:    "bad_multiplier",
+			c:       &configpb.FailureBackoff{Multiplier: proto.Float32(0.5)},
+			wantErr: true,
+		},
+		{
+			name:    "bad_max_interval",
+			c:       &configpb.FailureBackoff{MaxInterval: proto.String("5")},
+			wantErr: true,
+		},
+		{
+			name:    "max_interval_too_small",
+			c:       &configpb.FailureBackoff{MaxInterval: proto.String("5s")},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewFailureBackoff(tt.c, 10*time.Second)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewFailureBackoff() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestFailureBackoffInterval(t *testing.T) {
+	fb := &FailureBackoff{FailureThreshold: 3, Multiplier: 2, MaxInterval: time.Minute}
+	interval := 10 * time.Second
+
+	tests := []struct {
+		failures int
+		wantMax  time.Duration
+	}{
+		{failures: 0, wantMax: interval},
+		{failures: 2, wantMax: interval},
+		{failures: 3, wantMax: 20 * time.Second},
+		{failures: 4, wantMax: 40 * time.Second},
+		{failures: 5, wantMax: time.Minute},
+		{failures: 50, wantMax: time.Minute},
+	}
+
+	for _, tt := range tests {
+		got := fb.Interval(interval, tt.failures)
+		wantMin := tt.wantMax - time.Duration(float64(tt.wantMax)*backoffJitterFraction)
+		if wantMin < interval {
+			wantMin = interval
+		}
+		assert.GreaterOrEqual(t, got, wantMin, "failures: %d", tt.failures)
+		assert.LessOrEqual(t, got, tt.wantMax, "failures: %d", tt.failures)
+	}
+
+	var nilFB *FailureBackoff
+	assert.Equal(t, interval, nilFB.Interval(interval, 10))
+}
@@ -0,0 +1,165 @@
+// Copyright 2025 The Cloudprober Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package options
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// runLimiter limits the number of probe runs in progress at the same time.
+// Its limit can be changed in place (e.g. on config reload), so that all
+// probes keep sharing the same limiter. Limit 0 means no limit.
+type runLimiter struct {
+	mu      sync.Mutex
+	limit   int
+	inUse   int
+	changed chan struct{} // Closed when a slot is released or limit changes.
+}
+
+func (rl *runLimiter) notifyLocked() {
+	close(rl.changed)
+	rl.changed = make(chan struct{})
+}
+
+// setLimit updates the limit. Runs in progress are not affected, if the
+// limit is lowered, new runs wait until enough runs finish.
+func (rl *runLimiter) setLimit(n int) {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+	rl.limit = n
+	rl.notifyLocked()
+}
+
+// tryAcquire acquires a slot if one is available. Otherwise it returns a
+// channel that is closed when it's worth trying again.
+func (rl *runLimiter) tryAcquire() (bool, <-chan struct{}) {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+	if rl.limit > 0 && rl.inUse >= rl.limit {
+		return false, rl.changed
+	}
+	rl.inUse++
+	return true, nil
+}
+
+func (rl *runLimiter) release() {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+	rl.inUse--
+	rl.notifyLocked()
+}
+
+func (rl *runLimiter) stats() (inUse, total int) {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+	return rl.inUse, rl.limit
+}
+
+// Global run slots are shared by all probes. A probe run occupies a slot
+// while it's in progress.
+var (
+	globalRunSlotsMu sync.Mutex
+	globalRunSlots   *runLimiter
+)
+
+// Probe runs that had to wait for the rate limits or the run slots, and the
+// total time they spent waiting, across all probes.
+var (
+	queuedRuns atomic.Int64
+	queueDelay atomic.Int64 // Nanoseconds
+)
+
+// runSlots returns the global run slots, creating them (without a limit) if
+// required. All probes share these slots, irrespective of whether the limit is
+// set when they are built, so that a limit set later, e.g. on config reload,
+// applies to all of them.
+func runSlots() *runLimiter {
+	globalRunSlotsMu.Lock()
+	defer globalRunSlotsMu.Unlock()
+
+	if globalRunSlots == nil {
+		globalRunSlots = &runLimiter{changed: make(chan struct{})}
+	}
+	return globalRunSlots
+}
+
+// SetMaxConcurrentProbeRuns sets the global run slots limit, on start-up and
+// on config reload. Limit is updated in place, so that it applies to the
+// probes that are already running as well. n <= 0 removes the limit.
+func SetMaxConcurrentProbeRuns(n int) {
+	runSlots().setLimit(max(n, 0))
+}
+
+// WaitForRunSlot blocks until a probe run is allowed as per the rate limits
+// and the global concurrency limit, or until the context is canceled. If it
+// succeeds, the caller should call the returned release function once the
+// probe run is done.
+func (opts *Options) WaitForRunSlot(ctx context.Context) (release func(), err error) {
+	delay, err := opts.waitForRateLimit(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	release = func() {}
+	if slots := opts.runSlots; slots != nil {
+		var start time.Time
+		for {
+			ok, changed := slots.tryAcquire()
+			if ok {
+				break
+			}
+			if start.IsZero() {
+				start = time.Now()
+			}
+			select {
+			case <-changed:
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			}
+		}
+		if !start.IsZero() {
+			delay += time.Since(start)
+		}
+		release = slots.release
+	}
+
+	if delay > 0 {
+		queuedRuns.Add(1)
+		queueDelay.Add(int64(delay))
+	}
+	return release, nil
+}
+
+// QueueStats returns the number of probe runs that had to wait for the rate
+// limits or the run slots so far, and the total time they spent waiting.
+func QueueStats() (queued int64, delay time.Duration) {
+	return queuedRuns.Load(), time.Duration(queueDelay.Load())
+}
+
+// RunSlotsInUse returns the number of global run slots in use, and the total
+// number of slots. Total is 0 if there is no concurrency limit.
+func RunSlotsInUse() (inUse, total int) {
+	globalRunSlotsMu.Lock()
+	slots := globalRunSlots
+	globalRunSlotsMu.Unlock()
+
+	if slots == nil {
+		return 0, 0
+	}
+	return slots.stats()
+}
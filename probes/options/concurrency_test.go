@@ -0,0 +1,115 @@
+// Copyright 2025 The Cloudprober Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package options
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	proberconfigpb "github.com/cloudprober/cloudprober/config/proto"
+	configpb "github.com/cloudprober/cloudprober/probes/proto"
+	targetspb "github.com/cloudprober/cloudprober/targets/proto"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestWaitForRunSlot(t *testing.T) {
+	probeDef := func(name string) *configpb.ProbeDef {
+		return &configpb.ProbeDef{
+			Name: proto.String(name),
+			Type: configpb.ProbeDef_HTTP.Enum(),
+			Targets: &targetspb.TargetsDef{
+				Type: &targetspb.TargetsDef_DummyTargets{},
+			},
+		}
+	}
+	// Probes built before the limit is set, share the run slots as well.
+	SetMaxConcurrentProbeRuns(0)
+	opts1, err := BuildProbeOptions(probeDef("p1"), nil, nil, nil)
+	assert.NoError(t, err)
+	SetMaxConcurrentProbeRuns(2)
+	opts2, err := BuildProbeOptions(probeDef("p2"), nil, &proberconfigpb.ProberConfig{}, nil)
+	assert.NoError(t, err)
+	assert.Same(t, opts1.runSlots, opts2.runSlots, "run slots should be shared")
+
+	queuedBefore, _ := QueueStats()
+
+	ctx := context.Background()
+	release1, err := opts1.WaitForRunSlot(ctx)
+	assert.NoError(t, err)
+	release2, err := opts2.WaitForRunSlot(ctx)
+	assert.NoError(t, err)
+
+	inUse, total := RunSlotsInUse()
+	assert.Equal(t, 2, inUse)
+	assert.Equal(t, 2, total)
+
+	// No slots left, next run should wait until a slot is released.
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		release1()
+	}()
+	release3, err := opts1.WaitForRunSlot(ctx)
+	assert.NoError(t, err)
+
+	queued, delay := QueueStats()
+	assert.Equal(t, queuedBefore+1, queued)
+	assert.GreaterOrEqual(t, delay, 40*time.Millisecond)
+
+	// Return error if context is canceled while waiting.
+	cctx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	_, err = opts2.WaitForRunSlot(cctx)
+	assert.Error(t, err)
+
+	release2()
+	release3()
+	inUse, _ = RunSlotsInUse()
+	assert.Equal(t, 0, inUse)
+
+	// Limit change (e.g. config reload) applies in place to the existing
+	// probes as well.
+	SetMaxConcurrentProbeRuns(1)
+	opts4, err := BuildProbeOptions(probeDef("p4"), nil, nil, nil)
+	assert.NoError(t, err)
+	assert.Same(t, opts1.runSlots, opts4.runSlots)
+	_, total = RunSlotsInUse()
+	assert.Equal(t, 1, total)
+
+	release4, err := opts4.WaitForRunSlot(ctx)
+	assert.NoError(t, err)
+	cctx2, cancel2 := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel2()
+	_, err = opts1.WaitForRunSlot(cctx2)
+	assert.Error(t, err, "limit should apply to the existing probes")
+
+	// Removing the limit unblocks the waiting runs.
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		SetMaxConcurrentProbeRuns(0)
+	}()
+	release5, err := opts1.WaitForRunSlot(ctx)
+	assert.NoError(t, err)
+	release4()
+	release5()
+
+	// No concurrency limit.
+	opts3, err := BuildProbeOptions(probeDef("p3"), nil, nil, nil)
+	assert.NoError(t, err)
+	release, err := opts3.WaitForRunSlot(ctx)
+	assert.NoError(t, err)
+	release()
+}
@@ -0,0 +1,173 @@
+// Copyright 2025 The Cloudprober Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package options
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// cronField describes one field of a cron expression.
+type cronField struct {
+	name     string
+	min, max int
+	names    map[string]int
+}
+
+var cronFields = []cronField{
+	{name: "minute", min: 0, max: 59},
+	{name: "hour", min: 0, max: 23},
+	{name: "day of month", min: 1, max: 31},
+	{name: "month", min: 1, max: 12, names: map[string]int{
+		"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+		"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+	}},
+	// 7 is also accepted for Sunday, see parseCronExpr.
+	{name: "day of week", min: 0, max: 7, names: map[string]int{
+		"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+	}},
+}
+
+// cronExpr is a parsed standard 5-field cron expression. A cron expression
+// is used as a time window matcher: a time is in the window if its minute
+// matches the expression, e.g. "* 1-4 * * mon-fri" matches every minute
+// between 01:00 and 04:59 on weekdays.
+type cronExpr struct {
+	expr string
+	loc  *time.Location
+
+	// Bitsets of the allowed values, one for each field.
+	minute, hour, dom, month, dow uint64
+
+	// Whether day of month and day of week fields were restricted. As per the
+	// standard cron behavior, if both are restricted, a time matches if
+	// either of them matches.
+	domRestricted, dowRestricted bool
+}
+
+func parseCronValue(s string, f cronField) (int, error) {
+	if v, ok := f.names[strings.ToLower(s)]; ok {
+		return v, nil
+	}
+	v, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s value: %s", f.name, s)
+	}
+	if v < f.min || v > f.max {
+		return 0, fmt.Errorf("%s value %d out of range [%d, %d]", f.name, v, f.min, f.max)
+	}
+	return v, nil
+}
+
+// parseCronField parses a cron field, e.g. "*", "*/15", "1-5", "1,3,5" or
+// "mon-fri", into a bitset.
+func parseCronField(s string, f cronField) (uint64, error) {
+	var bits uint64
+
+	for _, part := range strings.Split(s, ",") {
+		rangePart, stepPart, hasStep := strings.Cut(part, "/")
+
+		step := 1
+		if hasStep {
+			var err error
+			if step, err = strconv.Atoi(stepPart); err != nil || step <= 0 {
+				return 0, fmt.Errorf("invalid step in %s field: %s", f.name, part)
+			}
+		}
+
+		var start, end int
+		switch {
+		case rangePart == "*":
+			start, end = f.min, f.max
+		case strings.Contains(rangePart, "-"):
+			startStr, endStr, _ := strings.Cut(rangePart, "-")
+			var err error
+			if start, err = parseCronValue(startStr, f); err != nil {
+				return 0, err
+			}
+			if end, err = parseCronValue(endStr, f); err != nil {
+				return 0, err
+			}
+			if end < start {
+				return 0, fmt.Errorf("invalid range in %s field: %s", f.name, rangePart)
+			}
+		default:
+			v, err := parseCronValue(rangePart, f)
+			if err != nil {
+				return 0, err
+			}
+			start, end = v, v
+			// "5/10" means every 10 starting at 5.
+			if hasStep {
+				end = f.max
+			}
+		}
+
+		for v := start; v <= end; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+
+	return bits, nil
+}
+
+// parseCronExpr parses a standard 5-field cron expression: minute, hour, day
+// of month, month and day of week.
+func parseCronExpr(expr string, loc *time.Location) (*cronExpr, error) {
+	fields := strings.Fields(expr)
+	if len(fields) != len(cronFields) {
+		return nil, fmt.Errorf("invalid cron expression (%s): expected %d fields, got %d", expr, len(cronFields), len(fields))
+	}
+
+	c := &cronExpr{expr: expr, loc: loc}
+	for i, dst := range []*uint64{&c.minute, &c.hour, &c.dom, &c.month, &c.dow} {
+		bits, err := parseCronField(fields[i], cronFields[i])
+		if err != nil {
+			return nil, fmt.Errorf("invalid cron expression (%s): %v", expr, err)
+		}
+		*dst = bits
+	}
+
+	// Sunday can be specified as 0 or 7.
+	if c.dow&(1<<7) != 0 {
+		c.dow |= 1
+	}
+
+	c.domRestricted = fields[2] != "*"
+	c.dowRestricted = fields[4] != "*"
+
+	return c, nil
+}
+
+func (c *cronExpr) isInPeriod(t time.Time) bool {
+	t = t.In(c.loc)
+
+	if c.minute&(1<<uint(t.Minute())) == 0 || c.hour&(1<<uint(t.Hour())) == 0 || c.month&(1<<uint(t.Month())) == 0 {
+		return false
+	}
+
+	domMatch := c.dom&(1<<uint(t.Day())) != 0
+	dowMatch := c.dow&(1<<uint(t.Weekday())) != 0
+	if c.domRestricted && c.dowRestricted {
+		return domMatch || dowMatch
+	}
+	return domMatch && dowMatch
+}
+
+func (c *cronExpr) String() string {
+	return fmt.Sprintf("Cron %q %s", c.expr, c.loc)
+}
@@ -0,0 +1,89 @@
+// Copyright 2025 The Cloudprober Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package options
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseCronExpr(t *testing.T) {
+	for _, tt := range []struct {
+		expr    string
+		wantErr bool
+	}{
+		{expr: "* * * * *"},
+		{expr: "*/15 1-4,22 1 jan-mar mon-fri"},
+		{expr: "5/10 * * * 7"},
+		{expr: "* * * *", wantErr: true},
+		{expr: "60 * * * *", wantErr: true},
+		{expr: "* 5-1 * * *", wantErr: true},
+		{expr: "*/0 * * * *", wantErr: true},
+		{expr: "* * * * funday", wantErr: true},
+	} {
+		t.Run(tt.expr, func(t *testing.T) {
+			_, err := parseCronExpr(tt.expr, time.UTC)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestCronIsInPeriod(t *testing.T) {
+	nyc, _ := time.LoadLocation("America/New_York")
+
+	// Jan 2, 2023 was a Monday.
+	monday := func(h, m int) time.Time {
+		return time.Date(2023, time.January, 2, h, m, 0, 0, time.UTC)
+	}
+
+	for _, tt := range []struct {
+		expr string
+		loc  *time.Location
+		t    time.Time
+		want bool
+	}{
+		{expr: "* 1-4 * * mon-fri", t: monday(1, 0), want: true},
+		{expr: "* 1-4 * * mon-fri", t: monday(4, 59), want: true},
+		{expr: "* 1-4 * * mon-fri", t: monday(5, 0), want: false},
+		{expr: "* 1-4 * * sat,sun", t: monday(2, 0), want: false},
+		{expr: "* 1-4 * * 7", t: monday(2, 0).AddDate(0, 0, -1), want: true},
+		{expr: "*/15 * * * *", t: monday(2, 30), want: true},
+		{expr: "*/15 * * * *", t: monday(2, 31), want: false},
+		{expr: "* * 15 * *", t: monday(2, 0), want: false},
+		// If both day of month and day of week are restricted, either of
+		// them should match.
+		{expr: "* * 15 * mon", t: monday(2, 0), want: true},
+		{expr: "* * 2 feb *", t: monday(2, 0), want: false},
+		// 03:00 UTC is 22:00 in New York on the previous day (Sunday).
+		{expr: "* 22 * * sun", loc: nyc, t: monday(3, 0), want: true},
+		{expr: "* 22 * * sun", t: monday(3, 0), want: false},
+	} {
+		t.Run(tt.expr+"@"+tt.t.String(), func(t *testing.T) {
+			loc := tt.loc
+			if loc == nil {
+				loc = time.UTC
+			}
+			c, err := parseCronExpr(tt.expr, loc)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, c.isInPeriod(tt.t))
+		})
+	}
+}
@@ -0,0 +1,47 @@
+// Copyright 2025 The Cloudprober Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package options
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// schedulingDrift keeps track of how late probe runs start, compared to
+// their schedule, across all targets of a probe.
+type schedulingDrift struct {
+	runs  atomic.Int64
+	total atomic.Int64 // Nanoseconds
+}
+
+// RecordSchedulingDrift records how late a probe run started, compared to
+// when it was scheduled to run, e.g. because of the rate limits or because
+// the previous run took longer than the interval.
+func (opts *Options) RecordSchedulingDrift(d time.Duration) {
+	if opts.drift == nil {
+		return
+	}
+	opts.drift.runs.Add(1)
+	opts.drift.total.Add(int64(d))
+}
+
+// SchedulingDrift returns the number of probe runs recorded so far, and
+// their total scheduling drift.
+func (opts *Options) SchedulingDrift() (runs int64, total time.Duration) {
+	if opts.drift == nil {
+		return 0, 0
+	}
+	return opts.drift.runs.Load(), time.Duration(opts.drift.total.Load())
+}
@@ -0,0 +1,38 @@
+// Copyright 2025 The Cloudprober Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package options
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSchedulingDrift(t *testing.T) {
+	// Options not created through BuildProbeOptions don't track drift.
+	opts := &Options{}
+	opts.RecordSchedulingDrift(time.Second)
+	runs, total := opts.SchedulingDrift()
+	assert.Equal(t, int64(0), runs)
+	assert.Equal(t, time.Duration(0), total)
+
+	opts = &Options{drift: &schedulingDrift{}}
+	opts.RecordSchedulingDrift(time.Second)
+	opts.RecordSchedulingDrift(2 * time.Millisecond)
+	runs, total = opts.SchedulingDrift()
+	assert.Equal(t, int64(2), runs)
+	assert.Equal(t, time.Second+2*time.Millisecond, total)
+}
@@ -0,0 +1,176 @@
+// Copyright 2025 The Cloudprober Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package options
+
+import (
+	"sync"
+	"time"
+
+	"github.com/cloudprober/cloudprober/metrics"
+	"github.com/cloudprober/cloudprober/targets/endpoint"
+)
+
+// fleetTargetState keeps track of a target's cumulative results, and their
+// change over the target's last stats export interval.
+type fleetTargetState struct {
+	lastSeen       time.Time
+	total, success int64
+	latencySum     float64
+
+	deltaTotal, deltaSuccess int64
+	avgLatency               float64 // -1 if there were no successes.
+}
+
+// fleetAggregator aggregates probe results across all targets and exports
+// fleet-level metrics once every stats export interval.
+type fleetAggregator struct {
+	probeName     string
+	interval      time.Duration
+	latencyMetric string
+	latencyDist   *metrics.Distribution
+
+	mu         sync.Mutex
+	ptype      string
+	targets    map[string]*fleetTargetState
+	lastExport time.Time
+}
+
+func newFleetAggregator(opts *Options) *fleetAggregator {
+	return &fleetAggregator{
+		probeName:     opts.Name,
+		interval:      opts.StatsExportInterval,
+		latencyMetric: opts.LatencyMetricName,
+		latencyDist:   opts.LatencyDist,
+		targets:       make(map[string]*fleetTargetState),
+	}
+}
+
+func latencySum(v metrics.Value) (float64, bool) {
+	switch lv := v.(type) {
+	case *metrics.Float:
+		return lv.Float64(), true
+	case *metrics.Distribution:
+		return lv.Data().Sum, true
+	}
+	return 0, false
+}
+
+// record records the target's EventMetrics, and returns fleet EventMetrics if
+// it's time to export them.
+func (fa *fleetAggregator) record(ep endpoint.Endpoint, em *metrics.EventMetrics) *metrics.EventMetrics {
+	if em.Kind != metrics.CUMULATIVE {
+		return nil
+	}
+	totalV, ok1 := em.Metric("total").(metrics.NumValue)
+	successV, ok2 := em.Metric("success").(metrics.NumValue)
+	if !ok1 || !ok2 {
+		return nil
+	}
+
+	fa.mu.Lock()
+	defer fa.mu.Unlock()
+
+	if fa.ptype == "" {
+		fa.ptype = em.Label("ptype")
+	}
+
+	ts := fa.targets[ep.Key()]
+	if ts == nil {
+		ts = &fleetTargetState{}
+		fa.targets[ep.Key()] = ts
+	}
+
+	total, success := totalV.Int64(), successV.Int64()
+	latency, hasLatency := latencySum(em.Metric(fa.latencyMetric))
+
+	// If counters went down, target's results were reset, e.g. because target
+	// was re-added.
+	if total < ts.total {
+		*ts = fleetTargetState{}
+	}
+
+	ts.deltaTotal, ts.deltaSuccess = total-ts.total, success-ts.success
+	ts.avgLatency = -1
+	if hasLatency && ts.deltaSuccess > 0 {
+		ts.avgLatency = (latency - ts.latencySum) / float64(ts.deltaSuccess)
+	}
+	ts.total, ts.success, ts.latencySum, ts.lastSeen = total, success, latency, em.Timestamp
+
+	if fa.lastExport.IsZero() {
+		fa.lastExport = em.Timestamp
+		return nil
+	}
+	if em.Timestamp.Sub(fa.lastExport) < fa.interval {
+		return nil
+	}
+	fa.lastExport = em.Timestamp
+	return fa.metrics(em.Timestamp)
+}
+
+// metrics builds fleet EventMetrics. It's called with the lock held.
+func (fa *fleetAggregator) metrics(now time.Time) *metrics.EventMetrics {
+	var numTargets, failing, numLatencies int64
+	var latencyTotal float64
+
+	var latencyDist *metrics.Distribution
+	if fa.latencyDist != nil {
+		latencyDist = fa.latencyDist.CloneDist()
+	}
+
+	for key, ts := range fa.targets {
+		// Forget targets that have not reported in a while, most likely they
+		// have been removed.
+		if now.Sub(ts.lastSeen) > 2*fa.interval {
+			delete(fa.targets, key)
+			continue
+		}
+
+		numTargets++
+		if ts.deltaSuccess < ts.deltaTotal {
+			failing++
+		}
+		if ts.avgLatency >= 0 {
+			numLatencies++
+			latencyTotal += ts.avgLatency
+			if latencyDist != nil {
+				latencyDist.AddFloat64(ts.avgLatency)
+			}
+		}
+	}
+
+	availableFraction := 0.0
+	if numTargets > 0 {
+		availableFraction = float64(numTargets-failing) / float64(numTargets)
+	}
+
+	em := metrics.NewEventMetrics(now).
+		AddMetric("fleet_targets", metrics.NewInt(numTargets)).
+		AddMetric("fleet_failing_targets", metrics.NewInt(failing)).
+		AddMetric("fleet_available_fraction", metrics.NewFloat(availableFraction))
+
+	if latencyDist != nil {
+		em.AddMetric("fleet_"+fa.latencyMetric, latencyDist)
+	} else if numLatencies > 0 {
+		em.AddMetric("fleet_"+fa.latencyMetric, metrics.NewFloat(latencyTotal/float64(numLatencies)))
+	}
+
+	em.Kind = metrics.GAUGE
+	em.SetNotForAlerting()
+	if fa.ptype != "" {
+		em.AddLabel("ptype", fa.ptype)
+	}
+	em.AddLabel("probe", fa.probeName)
+	return em
+}
@@ -0,0 +1,100 @@
+// Copyright 2025 The Cloudprober Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package options
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cloudprober/cloudprober/metrics"
+	configpb "github.com/cloudprober/cloudprober/probes/proto"
+	"github.com/cloudprober/cloudprober/targets/endpoint"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/proto"
+)
+
+func testTargetEM(ts time.Time, total, success int64, latency float64) *metrics.EventMetrics {
+	return metrics.NewEventMetrics(ts).
+		AddMetric("total", metrics.NewInt(total)).
+		AddMetric("success", metrics.NewInt(success)).
+		AddMetric("latency", metrics.NewFloat(latency)).
+		AddLabel("ptype", "http")
+}
+
+func TestFleetMetrics(t *testing.T) {
+	p := &configpb.ProbeDef{
+		Name:                    proto.String("test-probe"),
+		Type:                    configpb.ProbeDef_HTTP.Enum(),
+		Targets:                 testTargets,
+		StatsExportIntervalMsec: proto.Int32(10000),
+		ExportFleetMetrics:      proto.Bool(true),
+	}
+	opts, err := BuildProbeOptions(p, nil, nil, nil)
+	if err != nil {
+		t.Fatalf("BuildProbeOptions() error: %v", err)
+	}
+
+	dataChan := make(chan *metrics.EventMetrics, 10)
+	t1, t2, t3 := endpoint.Endpoint{Name: "t1"}, endpoint.Endpoint{Name: "t2"}, endpoint.Endpoint{Name: "t3"}
+	start := time.Now()
+
+	// First window, no fleet metrics yet.
+	opts.RecordMetrics(t1, testTargetEM(start, 5, 5, 50), dataChan)
+	opts.RecordMetrics(t2, testTargetEM(start, 5, 5, 100), dataChan)
+	opts.RecordMetrics(t3, testTargetEM(start, 5, 0, 0), dataChan)
+	assert.Len(t, dataChan, 3)
+
+	// Second window: t1 is healthy, t2 had a failure and t3 is down. Fleet
+	// metrics are exported along with the first target's metrics in this
+	// window, and hence don't include the other targets' updates yet.
+	ts := start.Add(10 * time.Second)
+	opts.RecordMetrics(t1, testTargetEM(ts, 10, 10, 150), dataChan)
+	opts.RecordMetrics(t2, testTargetEM(ts, 10, 9, 180), dataChan)
+	opts.RecordMetrics(t3, testTargetEM(ts, 10, 0, 0), dataChan)
+	assert.Len(t, dataChan, 7)
+
+	// Third window.
+	ts = start.Add(20 * time.Second)
+	opts.RecordMetrics(t1, testTargetEM(ts, 15, 15, 200), dataChan)
+
+	var fleetEMs []*metrics.EventMetrics
+	for len(dataChan) > 0 {
+		em := <-dataChan
+		if em.Metric("fleet_targets") != nil {
+			fleetEMs = append(fleetEMs, em)
+		}
+	}
+	if len(fleetEMs) != 2 {
+		t.Fatalf("Got %d fleet EventMetrics, want 2", len(fleetEMs))
+	}
+
+	// Fleet metrics in the second window: only t3 is failing. Average latency
+	// is 20 for both t1 and t2.
+	em := fleetEMs[0]
+	assert.Equal(t, metrics.Kind(metrics.GAUGE), em.Kind)
+	assert.Equal(t, "test-probe", em.Label("probe"))
+	assert.Equal(t, "http", em.Label("ptype"))
+	assert.Equal(t, int64(3), em.Metric("fleet_targets").(*metrics.Int).Int64())
+	assert.Equal(t, int64(1), em.Metric("fleet_failing_targets").(*metrics.Int).Int64())
+	assert.Equal(t, 20.0, em.Metric("fleet_latency").(*metrics.Float).Float64())
+
+	// Fleet metrics in the third window: t2 and t3 are failing. Average
+	// latency, t1: 50/5 = 10, t2: 80/4 = 20.
+	em = fleetEMs[1]
+	assert.Equal(t, int64(3), em.Metric("fleet_targets").(*metrics.Int).Int64())
+	assert.Equal(t, int64(2), em.Metric("fleet_failing_targets").(*metrics.Int).Int64())
+	assert.InDelta(t, 1.0/3, em.Metric("fleet_available_fraction").(*metrics.Float).Float64(), 0.0001)
+	assert.Equal(t, 15.0, em.Metric("fleet_latency").(*metrics.Float).Float64())
+}
@@ -170,12 +170,20 @@ func ParseAdditionalLabel(alpb *configpb.AdditionalLabel) *AdditionalLabel {
 	return al
 }
 
-func parseAdditionalLabels(p *configpb.ProbeDef) []*AdditionalLabel {
+func parseAdditionalLabels(p *configpb.ProbeDef, sysvarsAsLabels []string, sysVars map[string]string) []*AdditionalLabel {
 	var aLabels []*AdditionalLabel
 
 	for _, pb := range p.GetAdditionalLabel() {
 		aLabels = append(aLabels, ParseAdditionalLabel(pb))
 	}
 
+	// Sysvars based labels come after the probe's additional labels, so that
+	// the latter take precedence.
+	for _, name := range sysvarsAsLabels {
+		if v := sysVars[name]; v != "" {
+			aLabels = append(aLabels, &AdditionalLabel{Key: name, staticValue: v})
+		}
+	}
+
 	return aLabels
 }
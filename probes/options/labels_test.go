@@ -18,9 +18,12 @@ import (
 	"net"
 	"reflect"
 	"testing"
+	"time"
 
+	"github.com/cloudprober/cloudprober/metrics"
 	configpb "github.com/cloudprober/cloudprober/probes/proto"
 	"github.com/cloudprober/cloudprober/targets/endpoint"
+	"github.com/stretchr/testify/assert"
 	"google.golang.org/protobuf/proto"
 )
 
@@ -115,7 +118,7 @@ func TestParseAdditionalLabel(t *testing.T) {
 }
 
 func TestUpdateAdditionalLabel(t *testing.T) {
-	aLabels := parseAdditionalLabels(configWithAdditionalLabels)
+	aLabels := parseAdditionalLabels(configWithAdditionalLabels, nil, nil)
 
 	endpoints := map[string]endpoint.Endpoint{
 		"target1": {Name: "target1", Labels: map[string]string{}, IP: net.ParseIP("1.2.3.4"), Port: 80},
@@ -177,3 +180,33 @@ func TestUpdateAdditionalLabel(t *testing.T) {
 		}
 	}
 }
+
+func TestSysvarsAsLabels(t *testing.T) {
+	p := &configpb.ProbeDef{
+		AdditionalLabel: []*configpb.AdditionalLabel{
+			{Key: proto.String("zone"), Value: proto.String("probe-zone")},
+		},
+	}
+	sysVars := map[string]string{
+		"zone":       "zoneA",
+		"label_tier": "edge",
+	}
+
+	aLabels := parseAdditionalLabels(p, []string{"label_tier", "zone", "metadata_pop"}, sysVars)
+
+	var gotLabels [][2]string
+	for _, al := range aLabels {
+		k, v := al.KeyValueForTarget(endpoint.Endpoint{Name: "target1"})
+		gotLabels = append(gotLabels, [2]string{k, v})
+	}
+	// Probe's own "zone" label comes first, and hence takes precedence while
+	// adding labels to EventMetrics. Unset sysvars are skipped.
+	assert.Equal(t, [][2]string{{"zone", "probe-zone"}, {"label_tier", "edge"}, {"zone", "zoneA"}}, gotLabels)
+
+	em := metrics.NewEventMetrics(time.Now())
+	for _, al := range aLabels {
+		em.AddLabel(al.KeyValueForTarget(endpoint.Endpoint{Name: "target1"}))
+	}
+	assert.Equal(t, "probe-zone", em.Label("zone"))
+	assert.Equal(t, "edge", em.Label("label_tier"))
+}
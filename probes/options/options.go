@@ -21,12 +21,15 @@ import (
 	"fmt"
 	"log/slog"
 	"net"
+	"runtime"
 	"slices"
+	"sync/atomic"
 	"time"
 
 	"github.com/cloudprober/cloudprober/common/iputils"
 	proberconfigpb "github.com/cloudprober/cloudprober/config/proto"
 	"github.com/cloudprober/cloudprober/internal/alerting"
+	"github.com/cloudprober/cloudprober/internal/sysvars"
 	"github.com/cloudprober/cloudprober/internal/validators"
 	"github.com/cloudprober/cloudprober/logger"
 	"github.com/cloudprober/cloudprober/metrics"
@@ -34,6 +37,7 @@ import (
 	"github.com/cloudprober/cloudprober/targets"
 	"github.com/cloudprober/cloudprober/targets/endpoint"
 	targetspb "github.com/cloudprober/cloudprober/targets/proto"
+	"golang.org/x/time/rate"
 )
 
 // Options encapsulates common probe options.
@@ -48,17 +52,29 @@ type Options struct {
 	LatencyMetricName   string
 	Validators          []*validators.Validator
 	SourceIP            net.IP
+	BindInterface       string
 	IPVersion           int
 	StatsExportInterval time.Duration
 	AdditionalLabels    []*AdditionalLabel
 	Schedule            *Schedule
 	NegativeTest        bool
 	AlertHandlers       []*alerting.AlertHandler
+	RateLimiters        []*rate.Limiter
+	FailureBackoff      *FailureBackoff
+	RetryPolicy         *RetryPolicy
+	RestartPolicy       *RestartPolicy
+	StartJitter         *time.Duration // Not set if nil.
+	TimeoutScales       []*TimeoutScale
 	// Prober config at the prober initialization time. This config is not
 	// reliable for things that may change after initialization, e.g. probes
 	// that can be added or removed through gRPC.
 	ProberConfig       *proberconfigpb.ProberConfig
 	logMetricsOverride func(*metrics.EventMetrics)
+	fleet              *fleetAggregator
+	anomaly            *anomalyDetector
+	drift              *schedulingDrift
+	runSlots           *runLimiter
+	paused             atomic.Bool
 }
 
 // StatsExportFrequency returns how often to export metrics (in probe counts),
@@ -86,6 +102,13 @@ var negativeTestSupported = map[configpb.ProbeDef_Type]bool{
 	configpb.ProbeDef_PING: true,
 }
 
+var bindToInterfaceSupported = map[configpb.ProbeDef_Type]bool{
+	configpb.ProbeDef_PING: true,
+	configpb.ProbeDef_UDP:  true,
+	configpb.ProbeDef_TCP:  true,
+	configpb.ProbeDef_HTTP: true,
+}
+
 func defaultStatsExportInterval(p *configpb.ProbeDef, opts *Options) time.Duration {
 	minIntv := opts.Interval
 	if opts.Timeout > opts.Interval {
@@ -174,8 +197,17 @@ func BuildProbeOptions(p *configpb.ProbeDef, ldLister endpoint.Lister, proberCon
 		}
 	}
 
+	if intervalDuration <= 0 {
+		return nil, fmt.Errorf("interval (%v) must be positive", intervalDuration)
+	}
+	if timeoutDuration <= 0 {
+		return nil, fmt.Errorf("timeout (%v) must be positive", timeoutDuration)
+	}
+
+	// A probe run should finish before the next one starts. UDP probe is an
+	// exception as it sends and receives packets independently.
 	if intervalDuration < timeoutDuration && p.GetType() != configpb.ProbeDef_UDP {
-		return nil, fmt.Errorf("interval (%v) cannot be smaller than timeout (%v)", intervalDuration, timeoutDuration)
+		return nil, fmt.Errorf("timeout (%v) cannot be larger than interval (%v), probe run must finish before the next one starts", timeoutDuration, intervalDuration)
 	}
 
 	if p.GetNegativeTest() && !negativeTestSupported[p.GetType()] {
@@ -191,6 +223,11 @@ func BuildProbeOptions(p *configpb.ProbeDef, ldLister endpoint.Lister, proberCon
 		ProberConfig:      proberConfig,
 		NegativeTest:      p.GetNegativeTest(),
 		Logger:            logger.NewWithAttrs(slog.String("probe", p.GetName())),
+		drift:             &schedulingDrift{},
+	}
+
+	if opts.TimeoutScales, err = parseTimeoutScales(p, timeoutDuration, intervalDuration); err != nil {
+		return nil, err
 	}
 
 	if p.GetTargets() == nil {
@@ -200,6 +237,8 @@ func BuildProbeOptions(p *configpb.ProbeDef, ldLister endpoint.Lister, proberCon
 			configpb.ProbeDef_EXTENSION,
 			configpb.ProbeDef_BROWSER,
 			configpb.ProbeDef_SYSTEM,
+			configpb.ProbeDef_SCRIPT,
+			configpb.ProbeDef_TRANSACTION,
 		}
 		if !slices.Contains(targetsNotRequired, p.GetType()) {
 			return nil, fmt.Errorf("targets requied for probe type: %s", p.GetType().String())
@@ -245,6 +284,19 @@ func BuildProbeOptions(p *configpb.ProbeDef, ldLister endpoint.Lister, proberCon
 		}
 	}
 
+	if p.GetBindToInterface() {
+		if p.GetSourceInterface() == "" {
+			return nil, fmt.Errorf("bind_to_interface requires source_interface")
+		}
+		if !bindToInterfaceSupported[p.GetType()] {
+			return nil, fmt.Errorf("bind_to_interface is not supported by %s probes", p.GetType().String())
+		}
+		if runtime.GOOS != "linux" {
+			return nil, fmt.Errorf("bind_to_interface is supported only on Linux")
+		}
+		opts.BindInterface = p.GetSourceInterface()
+	}
+
 	if p.StatsExportIntervalMsec == nil {
 		opts.StatsExportInterval = defaultStatsExportInterval(p, opts)
 	} else {
@@ -254,7 +306,11 @@ func BuildProbeOptions(p *configpb.ProbeDef, ldLister endpoint.Lister, proberCon
 		}
 	}
 
-	opts.AdditionalLabels = parseAdditionalLabels(p)
+	opts.AdditionalLabels = parseAdditionalLabels(p, proberConfig.GetSysvarsAsLabels(), sysvars.Vars())
+
+	if p.GetExportFleetMetrics() {
+		opts.fleet = newFleetAggregator(opts)
+	}
 
 	for _, alertConf := range p.GetAlert() {
 		ah, err := alerting.NewAlertHandler(alertConf, p.GetName(), opts.Logger)
@@ -264,6 +320,13 @@ func BuildProbeOptions(p *configpb.ProbeDef, ldLister endpoint.Lister, proberCon
 		opts.AlertHandlers = append(opts.AlertHandlers, ah)
 	}
 
+	if p.GetAnomalyDetection() != nil {
+		opts.anomaly, err = newAnomalyDetector(p.GetAnomalyDetection(), opts, opts.Logger)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	if p.GetSchedule() != nil {
 		opts.Schedule, err = NewSchedule(p.GetSchedule(), opts.Logger)
 		if err != nil {
@@ -271,6 +334,55 @@ func BuildProbeOptions(p *configpb.ProbeDef, ldLister endpoint.Lister, proberCon
 		}
 	}
 
+	if p.GetFailureBackoff() != nil {
+		opts.FailureBackoff, err = NewFailureBackoff(p.GetFailureBackoff(), opts.Interval)
+		if err != nil {
+			return nil, fmt.Errorf("error creating failure backoff for the probe (%s): %v", p.GetName(), err)
+		}
+	}
+
+	if p.GetRetryPolicy() != nil {
+		if !retrySupported[p.GetType()] {
+			return nil, fmt.Errorf("retry_policy is not supported by %s probes", p.GetType().String())
+		}
+		if p.GetNegativeTest() {
+			return nil, fmt.Errorf("retry_policy cannot be used with negative_test")
+		}
+		opts.RetryPolicy, err = NewRetryPolicy(p.GetRetryPolicy())
+		if err != nil {
+			return nil, fmt.Errorf("error creating retry policy for the probe (%s): %v", p.GetName(), err)
+		}
+		if runTimeout := opts.RetryPolicy.RunTimeout(opts.MaxTimeout()); runTimeout > opts.Interval {
+			return nil, fmt.Errorf("interval (%v) cannot be smaller than the timeout including retries (%v)", opts.Interval, runTimeout)
+		}
+	}
+
+	opts.RestartPolicy, err = NewRestartPolicy(p.GetRestartPolicy())
+	if err != nil {
+		return nil, fmt.Errorf("error creating restart policy for the probe (%s): %v", p.GetName(), err)
+	}
+
+	if p.StartJitter != nil {
+		d, err := time.ParseDuration(p.GetStartJitter())
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse start_jitter (%s): %v", p.GetStartJitter(), err)
+		}
+		if d < 0 {
+			return nil, fmt.Errorf("start_jitter (%v) cannot be negative", d)
+		}
+		opts.StartJitter = &d
+	}
+
+	if r := proberConfig.GetMaxOutboundOpsPerSec(); r > 0 {
+		opts.RateLimiters = append(opts.RateLimiters, globalLimiter(r))
+	}
+	if r := p.GetMaxOpsPerSec(); r > 0 {
+		opts.RateLimiters = append(opts.RateLimiters, newRateLimiter(r))
+	}
+	// Limit on the run slots is set by the prober, see
+	// SetMaxConcurrentProbeRuns.
+	opts.runSlots = runSlots()
+
 	if p.GetDebugOptions().GetLogMetrics() {
 		opts.logMetricsOverride = func(em *metrics.EventMetrics) {
 			opts.Logger.Info(em.String())
@@ -299,7 +411,12 @@ func DefaultOptions() *Options {
 	return opts
 }
 
+// IsScheduled returns true if the probe should run now, i.e. it's not paused,
+// instance is not in the lameduck mode, and probe's schedule allows it.
 func (opts *Options) IsScheduled() bool {
+	if opts.IsPaused() || IsLameDuck() {
+		return false
+	}
 	return opts.Schedule.isIn(time.Now())
 }
 
@@ -314,8 +431,30 @@ func (opts *Options) RecordMetrics(ep endpoint.Endpoint, em *metrics.EventMetric
 		em.AddLabel(al.KeyValueForTarget(ep))
 	}
 
+	// Fleet aggregator and anomaly detector need to read em before we hand it
+	// over to the data channel.
+	var derivedEMs []*metrics.EventMetrics
+	if opts.fleet != nil {
+		if fleetEM := opts.fleet.record(ep, em); fleetEM != nil {
+			derivedEMs = append(derivedEMs, fleetEM)
+		}
+	}
+	if opts.anomaly != nil {
+		if anomalyEM := opts.anomaly.record(ep, em); anomalyEM != nil {
+			derivedEMs = append(derivedEMs, anomalyEM)
+		}
+	}
+
+	dropOnFull := opts.ProberConfig.GetDropMetricsOnFullBuffer()
+
 	opts.LogMetrics(em)
-	dataChan <- em
+	metrics.Send(dataChan, em, dropOnFull)
+
+	for _, dem := range derivedEMs {
+		dem.LatencyUnit = opts.LatencyUnit
+		opts.LogMetrics(dem)
+		metrics.Send(dataChan, dem, dropOnFull)
+	}
 
 	if em.IsForAlerting() {
 		for _, ah := range opts.AlertHandlers {
@@ -323,3 +462,17 @@ func (opts *Options) RecordMetrics(ep endpoint.Endpoint, em *metrics.EventMetric
 		}
 	}
 }
+
+// RecordSchedulerMetrics is like RecordMetrics, but for the metrics that
+// describe the probe's scheduling rather than its results, e.g. run timeout
+// counters. These metrics skip the fleet aggregator, anomaly detector and
+// alert handlers, as these work only with the probe results.
+func (opts *Options) RecordSchedulerMetrics(ep endpoint.Endpoint, em *metrics.EventMetrics, dataChan chan<- *metrics.EventMetrics) {
+	em.LatencyUnit = opts.LatencyUnit
+	for _, al := range opts.AdditionalLabels {
+		em.AddLabel(al.KeyValueForTarget(ep))
+	}
+
+	opts.LogMetrics(em)
+	metrics.Send(dataChan, em, opts.ProberConfig.GetDropMetricsOnFullBuffer())
+}
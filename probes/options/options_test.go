@@ -18,6 +18,7 @@ import (
 	"bytes"
 	"errors"
 	"net"
+	"runtime"
 	"testing"
 	"time"
 
@@ -365,6 +366,97 @@ func TestNegativeTestSupport(t *testing.T) {
 	}
 }
 
+func TestBindToInterface(t *testing.T) {
+	mockInterfaceByName("eth1", []string{"1.1.1.1"})
+
+	tests := []struct {
+		desc       string
+		ptype      configpb.ProbeDef_Type
+		sourceIP   string
+		sourceIntf string
+		wantErr    bool
+	}{
+		{
+			desc:       "ping",
+			ptype:      configpb.ProbeDef_PING,
+			sourceIntf: "eth1",
+		},
+		{
+			desc:       "http",
+			ptype:      configpb.ProbeDef_HTTP,
+			sourceIntf: "eth1",
+		},
+		{
+			desc:       "unsupported-probe-type",
+			ptype:      configpb.ProbeDef_DNS,
+			sourceIntf: "eth1",
+			wantErr:    true,
+		},
+		{
+			desc:     "source-ip",
+			ptype:    configpb.ProbeDef_TCP,
+			sourceIP: "1.1.1.1",
+			wantErr:  true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			p := &configpb.ProbeDef{
+				Type:            test.ptype.Enum(),
+				Targets:         testTargets,
+				BindToInterface: proto.Bool(true),
+			}
+			if test.sourceIP != "" {
+				p.SourceIpConfig = &configpb.ProbeDef_SourceIp{SourceIp: test.sourceIP}
+			} else {
+				p.SourceIpConfig = &configpb.ProbeDef_SourceInterface{SourceInterface: test.sourceIntf}
+			}
+
+			opts, err := BuildProbeOptions(p, nil, nil, nil)
+			if test.wantErr || runtime.GOOS != "linux" {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, test.sourceIntf, opts.BindInterface)
+			assert.Equal(t, "1.1.1.1", opts.SourceIP.String())
+		})
+	}
+}
+
+func TestStartJitter(t *testing.T) {
+	for _, tt := range []struct {
+		startJitter string
+		want        time.Duration
+		wantErr     bool
+	}{
+		{startJitter: "0s", want: 0},
+		{startJitter: "30s", want: 30 * time.Second},
+		{startJitter: "30", wantErr: true},
+		{startJitter: "-1s", wantErr: true},
+	} {
+		t.Run(tt.startJitter, func(t *testing.T) {
+			p := &configpb.ProbeDef{
+				Type:        configpb.ProbeDef_PING.Enum(),
+				Targets:     testTargets,
+				StartJitter: proto.String(tt.startJitter),
+			}
+			opts, err := BuildProbeOptions(p, nil, nil, nil)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, *opts.StartJitter)
+		})
+	}
+
+	opts, err := BuildProbeOptions(&configpb.ProbeDef{Type: configpb.ProbeDef_PING.Enum(), Targets: testTargets}, nil, nil, nil)
+	assert.NoError(t, err)
+	assert.Nil(t, opts.StartJitter, "start_jitter not set")
+}
+
 func TestRecordMetrics(t *testing.T) {
 	ep := endpoint.Endpoint{Name: "test_target"}
 	opts := DefaultOptions()
@@ -0,0 +1,48 @@
+// Copyright 2025 The Cloudprober Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package options
+
+import "sync/atomic"
+
+// Whether the whole instance is in the lameduck mode. No probe runs in the
+// lameduck mode.
+var lameDuck atomic.Bool
+
+// SetLameDuck puts the instance in the lameduck mode, or takes it out of it.
+// Lameduck mode doesn't change the paused state of the individual probes.
+func SetLameDuck(b bool) {
+	lameDuck.Store(b)
+}
+
+// IsLameDuck returns true if the instance is in the lameduck mode.
+func IsLameDuck() bool {
+	return lameDuck.Load()
+}
+
+// Pause pauses the probe. A paused probe skips its runs, the same way as
+// when it's outside its schedule, until it's resumed.
+func (opts *Options) Pause() {
+	opts.paused.Store(true)
+}
+
+// Resume resumes a paused probe.
+func (opts *Options) Resume() {
+	opts.paused.Store(false)
+}
+
+// IsPaused returns true if the probe has been paused.
+func (opts *Options) IsPaused() bool {
+	return opts.paused.Load()
+}
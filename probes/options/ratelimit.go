@@ -0,0 +1,104 @@
+// Copyright 2025 The Cloudprober Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package options
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// Global rate limiter is shared by all probes.
+var (
+	globalRateLimiterMu sync.Mutex
+	globalRateLimiter   *rate.Limiter
+)
+
+func newRateLimiter(opsPerSec float32) *rate.Limiter {
+	// Allow bursts of up to a second worth of operations.
+	return rate.NewLimiter(rate.Limit(opsPerSec), int(math.Ceil(float64(opsPerSec))))
+}
+
+// globalLimiter returns the global rate limiter, creating it if required.
+func globalLimiter(opsPerSec float32) *rate.Limiter {
+	globalRateLimiterMu.Lock()
+	defer globalRateLimiterMu.Unlock()
+
+	if globalRateLimiter == nil || globalRateLimiter.Limit() != rate.Limit(opsPerSec) {
+		globalRateLimiter = newRateLimiter(opsPerSec)
+	}
+	return globalRateLimiter
+}
+
+// WaitForRateLimit blocks until a probe operation is allowed as per the
+// global and the per-probe rate limits, or until the context is canceled.
+func (opts *Options) WaitForRateLimit(ctx context.Context) error {
+	_, err := opts.waitForRateLimit(ctx)
+	return err
+}
+
+// WaitForRateLimitN is like WaitForRateLimit, but it waits for n operations.
+// It's used by the probes that probe all their targets in one go, e.g. PING
+// and UDP, with each target counting as one operation. Operations that had to
+// wait are counted in the queue stats, see QueueStats.
+func (opts *Options) WaitForRateLimitN(ctx context.Context, n int) error {
+	for i := 0; i < n; i++ {
+		delay, err := opts.waitForRateLimit(ctx)
+		if err != nil {
+			return err
+		}
+		if delay > 0 {
+			queuedRuns.Add(1)
+			queueDelay.Add(int64(delay))
+		}
+	}
+	return nil
+}
+
+// waitForRateLimit is like WaitForRateLimit, but it also returns how long it
+// had to wait.
+func (opts *Options) waitForRateLimit(ctx context.Context) (time.Duration, error) {
+	var total time.Duration
+
+	for _, rl := range opts.RateLimiters {
+		if err := ctx.Err(); err != nil {
+			return 0, err
+		}
+
+		r := rl.Reserve()
+		if !r.OK() {
+			return 0, fmt.Errorf("rate limiter with limit %v and burst %d can never allow an operation", rl.Limit(), rl.Burst())
+		}
+		d := r.Delay()
+		if d == 0 {
+			continue
+		}
+
+		t := time.NewTimer(d)
+		select {
+		case <-t.C:
+			total += d
+		case <-ctx.Done():
+			t.Stop()
+			r.Cancel()
+			return 0, ctx.Err()
+		}
+	}
+	return total, nil
+}
@@ -0,0 +1,97 @@
+// Copyright 2025 The Cloudprober Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package options
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	proberconfigpb "github.com/cloudprober/cloudprober/config/proto"
+	configpb "github.com/cloudprober/cloudprober/probes/proto"
+	targetspb "github.com/cloudprober/cloudprober/targets/proto"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/time/rate"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestRateLimiters(t *testing.T) {
+	probeDef := func(name string, opsPerSec float32) *configpb.ProbeDef {
+		p := &configpb.ProbeDef{
+			Name: proto.String(name),
+			Type: configpb.ProbeDef_HTTP.Enum(),
+			Targets: &targetspb.TargetsDef{
+				Type: &targetspb.TargetsDef_DummyTargets{},
+			},
+		}
+		if opsPerSec != 0 {
+			p.MaxOpsPerSec = proto.Float32(opsPerSec)
+		}
+		return p
+	}
+
+	proberConfig := &proberconfigpb.ProberConfig{
+		MaxOutboundOpsPerSec: proto.Float32(100),
+	}
+
+	opts1, err := BuildProbeOptions(probeDef("p1", 5), nil, proberConfig, nil)
+	assert.NoError(t, err)
+	opts2, err := BuildProbeOptions(probeDef("p2", 0), nil, proberConfig, nil)
+	assert.NoError(t, err)
+	opts3, err := BuildProbeOptions(probeDef("p3", 0), nil, nil, nil)
+	assert.NoError(t, err)
+
+	assert.Len(t, opts1.RateLimiters, 2)
+	assert.Len(t, opts2.RateLimiters, 1)
+	assert.Len(t, opts3.RateLimiters, 0)
+
+	// Global rate limiter should be shared.
+	assert.Same(t, opts1.RateLimiters[0], opts2.RateLimiters[0])
+	assert.Equal(t, 5, opts1.RateLimiters[1].Burst())
+
+	// Burst should be allowed without waiting.
+	start := time.Now()
+	for i := 0; i < 5; i++ {
+		assert.NoError(t, opts1.WaitForRateLimit(context.Background()))
+	}
+	assert.Less(t, time.Since(start), 100*time.Millisecond)
+
+	// Next operation needs to wait, return error if context gets canceled.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.Error(t, opts1.WaitForRateLimit(ctx))
+
+	// No rate limit.
+	assert.NoError(t, opts3.WaitForRateLimit(ctx))
+}
+
+func TestWaitForRateLimitN(t *testing.T) {
+	opts := &Options{
+		RateLimiters: []*rate.Limiter{newRateLimiter(5)},
+	}
+
+	// Whole burst should be allowed without waiting.
+	queued, _ := QueueStats()
+	start := time.Now()
+	assert.NoError(t, opts.WaitForRateLimitN(context.Background(), 5))
+	assert.Less(t, time.Since(start), 100*time.Millisecond)
+	queuedAfter, _ := QueueStats()
+	assert.Equal(t, queued, queuedAfter)
+
+	// Next operations need to wait, return error if context gets canceled.
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	assert.Error(t, opts.WaitForRateLimitN(ctx, 2))
+}
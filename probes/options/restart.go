@@ -0,0 +1,136 @@
+// Copyright 2025 The Cloudprober Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package options
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+	"time"
+
+	"github.com/cloudprober/cloudprober/metrics"
+	configpb "github.com/cloudprober/cloudprober/probes/proto"
+)
+
+// RestartPolicy implements restarts of the probe goroutines after panics.
+type RestartPolicy struct {
+	MaxRestarts    int // Negative value means no limit.
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+}
+
+// NewRestartPolicy creates RestartPolicy from the given config. A nil config
+// results in the default policy.
+func NewRestartPolicy(c *configpb.RestartPolicy) (*RestartPolicy, error) {
+	rp := &RestartPolicy{
+		MaxRestarts: int(c.GetMaxRestarts()),
+	}
+
+	var err error
+	if rp.InitialBackoff, err = time.ParseDuration(c.GetInitialBackoff()); err != nil {
+		return nil, fmt.Errorf("failed to parse initial_backoff (%s): %v", c.GetInitialBackoff(), err)
+	}
+	if rp.MaxBackoff, err = time.ParseDuration(c.GetMaxBackoff()); err != nil {
+		return nil, fmt.Errorf("failed to parse max_backoff (%s): %v", c.GetMaxBackoff(), err)
+	}
+	if rp.InitialBackoff < 0 {
+		return nil, fmt.Errorf("initial_backoff (%v) cannot be negative", rp.InitialBackoff)
+	}
+	if rp.MaxBackoff < rp.InitialBackoff {
+		return nil, fmt.Errorf("max_backoff (%v) cannot be smaller than initial_backoff (%v)", rp.MaxBackoff, rp.InitialBackoff)
+	}
+
+	return rp, nil
+}
+
+// Backoff returns the time to wait before the given restart (starting at 1).
+func (rp *RestartPolicy) Backoff(restart int) time.Duration {
+	d := rp.InitialBackoff
+	for i := 1; i < restart && d < rp.MaxBackoff; i++ {
+		d *= 2
+	}
+	return min(d, rp.MaxBackoff)
+}
+
+// runRecovered runs f and recovers from a panic, if any. It returns the
+// recovered value and the stack trace at the time of the panic.
+func runRecovered(f func()) (r any, stack []byte) {
+	defer func() {
+		if r = recover(); r != nil {
+			stack = debug.Stack()
+		}
+	}()
+	f()
+	return nil, nil
+}
+
+// RunWithRestarts runs f, recovering from the panics in it. After a panic,
+// the "panics" metric is pushed to the data channel, and f is restarted as
+// per the restart policy, with exponential backoff. RunWithRestarts returns
+// when f returns normally, context is canceled, or max restarts are
+// exhausted. target, if not empty, is used as the "dst" label of the
+// "panics" metric.
+func (opts *Options) RunWithRestarts(ctx context.Context, target string, dataChan chan<- *metrics.EventMetrics, f func()) {
+	rp := opts.RestartPolicy
+	if rp == nil {
+		rp, _ = NewRestartPolicy(nil)
+	}
+
+	desc := "probe " + opts.Name
+	if target != "" {
+		desc += ", target " + target
+	}
+
+	var panics int64
+	for {
+		r, stack := runRecovered(f)
+		if r == nil {
+			return
+		}
+		panics++
+		opts.Logger.Errorf("Panic in %s: %v\n%s", desc, r, stack)
+
+		if dataChan != nil {
+			em := metrics.NewEventMetrics(time.Now()).AddMetric("panics", metrics.NewInt(panics))
+			em.Kind = metrics.CUMULATIVE
+			em.SetNotForAlerting()
+			em.AddLabel("probe", opts.Name)
+			if target != "" {
+				em.AddLabel("dst", target)
+			}
+			opts.LogMetrics(em)
+			select {
+			case dataChan <- em:
+			case <-ctx.Done():
+			}
+		}
+
+		if ctx.Err() != nil {
+			return
+		}
+		if rp.MaxRestarts >= 0 && panics > int64(rp.MaxRestarts) {
+			opts.Logger.Errorf("Not restarting %s, max restarts (%d) exhausted", desc, rp.MaxRestarts)
+			return
+		}
+
+		backoff := rp.Backoff(int(panics))
+		opts.Logger.Warningf("Restarting %s in %v (restart %d)", desc, backoff, panics)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+	}
+}
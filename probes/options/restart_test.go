@@ -0,0 +1,112 @@
+// Copyright 2025 The Cloudprober Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package options
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/cloudprober/cloudprober/metrics"
+	configpb "github.com/cloudprober/cloudprober/probes/proto"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestNewRestartPolicy(t *testing.T) {
+	rp, err := NewRestartPolicy(nil)
+	assert.NoError(t, err)
+	assert.Equal(t, &RestartPolicy{MaxRestarts: 10, InitialBackoff: time.Second, MaxBackoff: 5 * time.Minute}, rp)
+
+	_, err = NewRestartPolicy(&configpb.RestartPolicy{InitialBackoff: proto.String("1")})
+	assert.Error(t, err)
+	_, err = NewRestartPolicy(&configpb.RestartPolicy{InitialBackoff: proto.String("10m")})
+	assert.Error(t, err, "max_backoff smaller than initial_backoff")
+}
+
+func TestRestartPolicyBackoff(t *testing.T) {
+	rp := &RestartPolicy{InitialBackoff: time.Second, MaxBackoff: 5 * time.Second}
+	for restart, want := range map[int]time.Duration{
+		1:  time.Second,
+		2:  2 * time.Second,
+		3:  4 * time.Second,
+		4:  5 * time.Second,
+		50: 5 * time.Second,
+	} {
+		assert.Equal(t, want, rp.Backoff(restart), "restart: %d", restart)
+	}
+}
+
+func TestRunWithRestarts(t *testing.T) {
+	tests := []struct {
+		name        string
+		maxRestarts int
+		panicFor    int // Number of runs that panic before f returns normally.
+		wantRuns    int
+		wantPanics  int64
+	}{
+		{name: "no_panic", maxRestarts: 2, panicFor: 0, wantRuns: 1},
+		{name: "recovers", maxRestarts: 2, panicFor: 2, wantRuns: 3, wantPanics: 2},
+		{name: "max_restarts", maxRestarts: 2, panicFor: 10, wantRuns: 3, wantPanics: 3},
+		{name: "no_restarts", maxRestarts: 0, panicFor: 10, wantRuns: 1, wantPanics: 1},
+		{name: "unlimited", maxRestarts: -1, panicFor: 5, wantRuns: 6, wantPanics: 5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := &Options{
+				Name: "test-probe",
+				RestartPolicy: &RestartPolicy{
+					MaxRestarts:    tt.maxRestarts,
+					InitialBackoff: time.Millisecond,
+					MaxBackoff:     time.Millisecond,
+				},
+			}
+			dataChan := make(chan *metrics.EventMetrics, 20)
+
+			runs := 0
+			opts.RunWithRestarts(context.Background(), "t1", dataChan, func() {
+				runs++
+				if runs <= tt.panicFor {
+					panic("boom")
+				}
+			})
+			assert.Equal(t, tt.wantRuns, runs)
+
+			assert.Len(t, dataChan, int(tt.wantPanics))
+			var lastEM *metrics.EventMetrics
+			for len(dataChan) > 0 {
+				lastEM = <-dataChan
+			}
+			if tt.wantPanics == 0 {
+				return
+			}
+			assert.Equal(t, tt.wantPanics, lastEM.Metric("panics").(*metrics.Int).Int64())
+			assert.Equal(t, "test-probe", lastEM.Label("probe"))
+			assert.Equal(t, "t1", lastEM.Label("dst"))
+		})
+	}
+
+	// Context cancelation should stop restarts.
+	ctx, cancel := context.WithCancel(context.Background())
+	opts := &Options{Name: "test-probe"}
+	runs := 0
+	opts.RunWithRestarts(ctx, "", nil, func() {
+		runs++
+		cancel()
+		panic("boom")
+	})
+	assert.Equal(t, 1, runs)
+}
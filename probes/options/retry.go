@@ -0,0 +1,133 @@
+// Copyright 2025 The Cloudprober Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package options
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"syscall"
+	"time"
+
+	configpb "github.com/cloudprober/cloudprober/probes/proto"
+)
+
+var retrySupported = map[configpb.ProbeDef_Type]bool{
+	configpb.ProbeDef_HTTP: true,
+	configpb.ProbeDef_TCP:  true,
+	configpb.ProbeDef_DNS:  true,
+}
+
+// RetryPolicy implements in-run retries of the probe requests.
+type RetryPolicy struct {
+	MaxRetries int
+	Backoff    time.Duration
+	retryOn    map[configpb.RetryPolicy_RetryOn]bool
+}
+
+// NewRetryPolicy creates RetryPolicy from the given config.
+func NewRetryPolicy(c *configpb.RetryPolicy) (*RetryPolicy, error) {
+	rp := &RetryPolicy{
+		MaxRetries: int(c.GetMaxRetries()),
+		retryOn:    make(map[configpb.RetryPolicy_RetryOn]bool),
+	}
+
+	if rp.MaxRetries < 0 {
+		return nil, fmt.Errorf("max_retries (%d) cannot be negative", rp.MaxRetries)
+	}
+
+	var err error
+	if rp.Backoff, err = time.ParseDuration(c.GetBackoff()); err != nil {
+		return nil, fmt.Errorf("failed to parse backoff (%s): %v", c.GetBackoff(), err)
+	}
+
+	for _, ro := range c.GetRetryOn() {
+		rp.retryOn[ro] = true
+	}
+	if len(rp.retryOn) == 0 {
+		rp.retryOn[configpb.RetryPolicy_ANY_ERROR] = true
+	}
+
+	return rp, nil
+}
+
+// RunTimeout returns the maximum time a probe run can take with retries,
+// given the per-attempt timeout.
+func (rp *RetryPolicy) RunTimeout(timeout time.Duration) time.Duration {
+	if rp == nil {
+		return timeout
+	}
+	return time.Duration(rp.MaxRetries+1)*timeout + time.Duration(rp.MaxRetries)*rp.Backoff
+}
+
+func isTimeout(err error) bool {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	var nerr net.Error
+	return errors.As(err, &nerr) && nerr.Timeout()
+}
+
+func isConnectionError(err error) bool {
+	var opErr *net.OpError
+	if errors.As(err, &opErr) && opErr.Op == "dial" && !opErr.Timeout() {
+		return true
+	}
+	return errors.Is(err, syscall.ECONNREFUSED) || errors.Is(err, syscall.ECONNRESET)
+}
+
+// ShouldRetry returns true if an attempt that failed with the given error
+// should be retried. If no retry conditions are set, we retry on any error.
+func (rp *RetryPolicy) ShouldRetry(err error) bool {
+	if rp == nil || err == nil {
+		return false
+	}
+	if len(rp.retryOn) == 0 || rp.retryOn[configpb.RetryPolicy_ANY_ERROR] {
+		return true
+	}
+	if rp.retryOn[configpb.RetryPolicy_TIMEOUT] && isTimeout(err) {
+		return true
+	}
+	return rp.retryOn[configpb.RetryPolicy_CONNECTION_ERROR] && isConnectionError(err)
+}
+
+// Do calls fn, retrying it as per the retry policy, until it succeeds, it
+// fails with a non-retriable error, retries are exhausted, or the context is
+// canceled. Timeout is applied to each attempt. Do returns the number of
+// attempts made and the error returned by the last attempt.
+//
+// If retry policy is nil, fn is called just once, with the given context.
+func (rp *RetryPolicy) Do(ctx context.Context, timeout time.Duration, fn func(context.Context) error) (int, error) {
+	if rp == nil {
+		return 1, fn(ctx)
+	}
+
+	for attempt := 1; ; attempt++ {
+		attemptCtx, cancel := context.WithTimeout(ctx, timeout)
+		err := fn(attemptCtx)
+		cancel()
+
+		if attempt > rp.MaxRetries || !rp.ShouldRetry(err) || ctx.Err() != nil {
+			return attempt, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return attempt, err
+		case <-time.After(rp.Backoff):
+		}
+	}
+}
@@ -0,0 +1,124 @@
+// Copyright 2025 The Cloudprober Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package options
+
+import (
+	"context"
+	"errors"
+	"net"
+	"syscall"
+	"testing"
+	"time"
+
+	configpb "github.com/cloudprober/cloudprober/probes/proto"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestNewRetryPolicy(t *testing.T) {
+	rp, err := NewRetryPolicy(&configpb.RetryPolicy{})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, rp.MaxRetries)
+	assert.Equal(t, 100*time.Millisecond, rp.Backoff)
+	assert.Equal(t, 2*time.Second+100*time.Millisecond, rp.RunTimeout(time.Second))
+
+	_, err = NewRetryPolicy(&configpb.RetryPolicy{MaxRetries: proto.Int32(-1)})
+	assert.Error(t, err)
+	_, err = NewRetryPolicy(&configpb.RetryPolicy{Backoff: proto.String("100")})
+	assert.Error(t, err)
+
+	var nilRP *RetryPolicy
+	assert.Equal(t, time.Second, nilRP.RunTimeout(time.Second))
+}
+
+func TestRetryPolicyShouldRetry(t *testing.T) {
+	timeoutErr := &net.OpError{Op: "read", Err: context.DeadlineExceeded}
+	connErr := &net.OpError{Op: "dial", Err: syscall.ECONNREFUSED}
+	otherErr := errors.New("bad response")
+
+	tests := []struct {
+		name    string
+		retryOn []configpb.RetryPolicy_RetryOn
+		err     error
+		want    bool
+	}{
+		{name: "no_error", err: nil, want: false},
+		{name: "any_error", err: otherErr, want: true},
+		{name: "timeout", retryOn: []configpb.RetryPolicy_RetryOn{configpb.RetryPolicy_TIMEOUT}, err: timeoutErr, want: true},
+		{name: "timeout_conn_err", retryOn: []configpb.RetryPolicy_RetryOn{configpb.RetryPolicy_TIMEOUT}, err: connErr, want: false},
+		{name: "conn_err", retryOn: []configpb.RetryPolicy_RetryOn{configpb.RetryPolicy_CONNECTION_ERROR}, err: connErr, want: true},
+		{name: "conn_err_other", retryOn: []configpb.RetryPolicy_RetryOn{configpb.RetryPolicy_CONNECTION_ERROR}, err: otherErr, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rp, err := NewRetryPolicy(&configpb.RetryPolicy{RetryOn: tt.retryOn})
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, rp.ShouldRetry(tt.err))
+		})
+	}
+}
+
+func TestRetryPolicyDo(t *testing.T) {
+	rp := &RetryPolicy{
+		MaxRetries: 2,
+		Backoff:    time.Millisecond,
+		retryOn:    map[configpb.RetryPolicy_RetryOn]bool{configpb.RetryPolicy_ANY_ERROR: true},
+	}
+
+	failFirstN := func(n int) func(context.Context) error {
+		var calls int
+		return func(ctx context.Context) error {
+			if _, ok := ctx.Deadline(); !ok {
+				return errors.New("no deadline for the attempt")
+			}
+			calls++
+			if calls <= n {
+				return errors.New("failed")
+			}
+			return nil
+		}
+	}
+
+	attempts, err := rp.Do(context.Background(), time.Second, failFirstN(0))
+	assert.NoError(t, err)
+	assert.Equal(t, 1, attempts)
+
+	attempts, err = rp.Do(context.Background(), time.Second, failFirstN(2))
+	assert.NoError(t, err)
+	assert.Equal(t, 3, attempts)
+
+	attempts, err = rp.Do(context.Background(), time.Second, failFirstN(3))
+	assert.Error(t, err)
+	assert.Equal(t, 3, attempts)
+
+	// Stop retrying once context is canceled.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	attempts, err = rp.Do(ctx, time.Second, failFirstN(3))
+	assert.Error(t, err)
+	assert.Equal(t, 1, attempts)
+
+	// Retry policy without retry conditions, retries on any error.
+	attempts, err = (&RetryPolicy{MaxRetries: 1}).Do(context.Background(), time.Second, failFirstN(1))
+	assert.NoError(t, err)
+	assert.Equal(t, 2, attempts)
+
+	// Nil retry policy, single attempt.
+	var nilRP *RetryPolicy
+	attempts, err = nilRP.Do(context.Background(), time.Second, func(context.Context) error { return errors.New("failed") })
+	assert.Error(t, err)
+	assert.Equal(t, 1, attempts)
+}
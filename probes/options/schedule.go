@@ -71,6 +71,36 @@ func (p *period) isInPeriod(t time.Time) bool {
 	return nt.After(p.startTime) && nt.Before(p.endTime)
 }
 
+// schedulePeriod is a period of time, defined either using weekdays and
+// times, or using a cron expression.
+type schedulePeriod interface {
+	isInPeriod(t time.Time) bool
+	String() string
+}
+
+func parseSchedulePeriod(sched *configpb.Schedule, l *logger.Logger) (schedulePeriod, error) {
+	if sched.Cron == nil {
+		return parsePeriod(sched, l)
+	}
+
+	if sched.StartWeekday != nil || sched.StartTime != nil || sched.EndWeekday != nil || sched.EndTime != nil {
+		return nil, fmt.Errorf("invalid schedule: cron cannot be combined with start_weekday, start_time, end_weekday or end_time")
+	}
+
+	loc, err := time.LoadLocation(sched.GetTimezone())
+	if err != nil {
+		return nil, fmt.Errorf("error loading timezone (%s): %v", sched.GetTimezone(), err)
+	}
+
+	c, err := parseCronExpr(sched.GetCron(), loc)
+	if err != nil {
+		return nil, err
+	}
+	l.Infof("Schedule: %s", c.String())
+
+	return c, nil
+}
+
 func parsePeriod(sched *configpb.Schedule, l *logger.Logger) (*period, error) {
 	p := &period{l: l}
 
@@ -121,8 +151,8 @@ func (p *period) String() string {
 }
 
 type Schedule struct {
-	enablePeriods  []*period
-	disablePeriods []*period
+	enablePeriods  []schedulePeriod
+	disablePeriods []schedulePeriod
 	l              *logger.Logger
 }
 
@@ -181,7 +211,7 @@ func parseTime(t string) (int, int, error) {
 func NewSchedule(scheds []*configpb.Schedule, l *logger.Logger) (*Schedule, error) {
 	s := &Schedule{l: l}
 	for _, sched := range scheds {
-		p, err := parsePeriod(sched, l)
+		p, err := parseSchedulePeriod(sched, l)
 		if err != nil {
 			return nil, err
 		}
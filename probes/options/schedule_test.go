@@ -375,3 +375,38 @@ func TestIsInPeriod(t *testing.T) {
 		})
 	}
 }
+
+func TestCronSchedules(t *testing.T) {
+	s, err := NewSchedule([]*configpb.Schedule{
+		{
+			Type:     configpb.Schedule_ENABLE.Enum(),
+			Cron:     proto.String("* 1-4 * * *"),
+			Timezone: proto.String("America/New_York"),
+		},
+		{
+			Type: configpb.Schedule_DISABLE.Enum(),
+			Cron: proto.String("* * * * sat,sun"),
+		},
+	}, nil)
+	assert.NoError(t, err)
+
+	for timeStr, want := range map[string]bool{
+		"2023-12-14 01:00:00 -0500": true,  // Thu
+		"2023-12-14 04:59:00 -0500": true,  // Thu
+		"2023-12-14 05:00:00 -0500": false, // Thu
+		"2023-12-14 03:00:00 +0000": false, // Wed 22:00 in New York
+		"2023-12-16 02:00:00 -0500": false, // Sat
+	} {
+		ttime, _ := time.Parse("2006-01-02 15:04:05 -0700", timeStr)
+		assert.Equal(t, want, s.isIn(ttime), timeStr)
+	}
+
+	_, err = NewSchedule([]*configpb.Schedule{
+		{
+			Type:      configpb.Schedule_ENABLE.Enum(),
+			Cron:      proto.String("* 1-4 * * *"),
+			StartTime: proto.String("01:00"),
+		},
+	}, nil)
+	assert.Error(t, err, "cron with start_time")
+}
@@ -0,0 +1,97 @@
+// Copyright 2025 The Cloudprober Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package options
+
+import (
+	"fmt"
+	"time"
+
+	configpb "github.com/cloudprober/cloudprober/probes/proto"
+	"github.com/cloudprober/cloudprober/targets/endpoint"
+)
+
+var timeoutScaleSupported = map[configpb.ProbeDef_Type]bool{
+	configpb.ProbeDef_HTTP:        true,
+	configpb.ProbeDef_TCP:         true,
+	configpb.ProbeDef_DNS:         true,
+	configpb.ProbeDef_SCRIPT:      true,
+	configpb.ProbeDef_TRANSACTION: true,
+}
+
+// TimeoutScale scales the probe timeout for the targets with the given
+// labels.
+type TimeoutScale struct {
+	TargetLabels map[string]string
+	Timeout      time.Duration // Scaled timeout.
+}
+
+func (ts *TimeoutScale) matches(target endpoint.Endpoint) bool {
+	for k, v := range ts.TargetLabels {
+		if target.Labels[k] != v {
+			return false
+		}
+	}
+	return true
+}
+
+func parseTimeoutScales(p *configpb.ProbeDef, timeout, interval time.Duration) ([]*TimeoutScale, error) {
+	if len(p.GetTargetTimeoutScale()) == 0 {
+		return nil, nil
+	}
+
+	if !timeoutScaleSupported[p.GetType()] {
+		return nil, fmt.Errorf("target_timeout_scale is not supported by %s probes", p.GetType().String())
+	}
+
+	var scales []*TimeoutScale
+	for _, c := range p.GetTargetTimeoutScale() {
+		if c.GetMultiplier() <= 0 {
+			return nil, fmt.Errorf("target_timeout_scale: multiplier (%f) should be positive", c.GetMultiplier())
+		}
+		ts := &TimeoutScale{
+			TargetLabels: c.GetTargetLabels(),
+			Timeout:      time.Duration(float64(timeout) * float64(c.GetMultiplier())),
+		}
+		if ts.Timeout > interval {
+			return nil, fmt.Errorf("target_timeout_scale: scaled timeout (%v) for target labels %v cannot be larger than interval (%v)", ts.Timeout, ts.TargetLabels, interval)
+		}
+		scales = append(scales, ts)
+	}
+	return scales, nil
+}
+
+// TimeoutForTarget returns the probe timeout for the given target, taking
+// target timeout scaling into account.
+func (opts *Options) TimeoutForTarget(target endpoint.Endpoint) time.Duration {
+	for _, ts := range opts.TimeoutScales {
+		if ts.matches(target) {
+			return ts.Timeout
+		}
+	}
+	return opts.Timeout
+}
+
+// MaxTimeout returns the maximum probe timeout across all targets. It's useful
+// for configuring the timeouts that are not set per target, e.g. dialer
+// timeouts.
+func (opts *Options) MaxTimeout() time.Duration {
+	max := opts.Timeout
+	for _, ts := range opts.TimeoutScales {
+		if ts.Timeout > max {
+			max = ts.Timeout
+		}
+	}
+	return max
+}
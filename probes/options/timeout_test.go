@@ -0,0 +1,148 @@
+// Copyright 2025 The Cloudprober Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package options
+
+import (
+	"testing"
+	"time"
+
+	configpb "github.com/cloudprober/cloudprober/probes/proto"
+	"github.com/cloudprober/cloudprober/targets/endpoint"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestTimeoutValidation(t *testing.T) {
+	tests := []struct {
+		name              string
+		pType             *configpb.ProbeDef_Type // Default: HTTP
+		interval, timeout string
+		scales            []*configpb.TargetTimeoutScale
+		wantErr           bool
+	}{
+		{
+			name:     "valid",
+			interval: "10s",
+			timeout:  "2s",
+		},
+		{
+			name:     "timeout_larger_than_interval",
+			interval: "1s",
+			timeout:  "2s",
+			wantErr:  true,
+		},
+		{
+			name:     "zero_interval",
+			interval: "0s",
+			timeout:  "2s",
+			wantErr:  true,
+		},
+		{
+			name:     "negative_timeout",
+			interval: "10s",
+			timeout:  "-2s",
+			wantErr:  true,
+		},
+		{
+			name:     "scaled_timeout_larger_than_interval",
+			interval: "10s",
+			timeout:  "4s",
+			scales: []*configpb.TargetTimeoutScale{
+				{Multiplier: proto.Float32(3)},
+			},
+			wantErr: true,
+		},
+		{
+			name:     "bad_multiplier",
+			interval: "10s",
+			timeout:  "4s",
+			scales: []*configpb.TargetTimeoutScale{
+				{Multiplier: proto.Float32(0)},
+			},
+			wantErr: true,
+		},
+		{
+			name:     "scaling_not_supported",
+			pType:    configpb.ProbeDef_PING.Enum(),
+			interval: "10s",
+			timeout:  "2s",
+			scales: []*configpb.TargetTimeoutScale{
+				{Multiplier: proto.Float32(3)},
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pType := tt.pType
+			if pType == nil {
+				pType = configpb.ProbeDef_HTTP.Enum()
+			}
+			p := &configpb.ProbeDef{
+				Name:               proto.String("test-probe"),
+				Type:               pType,
+				Targets:            testTargets,
+				Interval:           proto.String(tt.interval),
+				Timeout:            proto.String(tt.timeout),
+				TargetTimeoutScale: tt.scales,
+			}
+			_, err := BuildProbeOptions(p, nil, nil, nil)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("BuildProbeOptions() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestTimeoutForTarget(t *testing.T) {
+	p := &configpb.ProbeDef{
+		Name:     proto.String("test-probe"),
+		Type:     configpb.ProbeDef_HTTP.Enum(),
+		Targets:  testTargets,
+		Interval: proto.String("10s"),
+		Timeout:  proto.String("2s"),
+		TargetTimeoutScale: []*configpb.TargetTimeoutScale{
+			{
+				TargetLabels: map[string]string{"region": "apac"},
+				Multiplier:   proto.Float32(3),
+			},
+			{
+				TargetLabels: map[string]string{"region": "eu", "tier": "edge"},
+				Multiplier:   proto.Float32(1.5),
+			},
+		},
+	}
+
+	opts, err := BuildProbeOptions(p, nil, nil, nil)
+	if err != nil {
+		t.Fatalf("BuildProbeOptions() error: %v", err)
+	}
+
+	tests := []struct {
+		labels map[string]string
+		want   time.Duration
+	}{
+		{labels: nil, want: 2 * time.Second},
+		{labels: map[string]string{"region": "apac"}, want: 6 * time.Second},
+		{labels: map[string]string{"region": "eu"}, want: 2 * time.Second},
+		{labels: map[string]string{"region": "eu", "tier": "edge"}, want: 3 * time.Second},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, opts.TimeoutForTarget(endpoint.Endpoint{Name: "t1", Labels: tt.labels}), "labels: %v", tt.labels)
+	}
+
+	assert.Equal(t, 6*time.Second, opts.MaxTimeout())
+}
@@ -18,6 +18,7 @@
 package ping
 
 import (
+	"fmt"
 	"net"
 	"strconv"
 	"time"
@@ -43,6 +44,19 @@ func (p *Probe) newICMPConn(sourceIP net.IP) (icmpConn, error) {
 	if err != nil {
 		return nil, err
 	}
+
+	if tos := int(p.c.GetTos()); tos != 0 {
+		if p.ipVer == 6 {
+			err = c.IPv6PacketConn().SetTrafficClass(tos)
+		} else {
+			err = c.IPv4PacketConn().SetTOS(tos)
+		}
+		if err != nil {
+			c.Close()
+			return nil, fmt.Errorf("error setting TOS to %d: %v", tos, err)
+		}
+	}
+
 	return &icmpPacketConn{c}, nil
 }
 
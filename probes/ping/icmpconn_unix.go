@@ -19,6 +19,7 @@ package ping
 
 import (
 	"encoding/binary"
+	"errors"
 	"fmt"
 	"net"
 	"os"
@@ -26,6 +27,10 @@ import (
 	"syscall"
 	"time"
 	"unsafe"
+
+	"github.com/cloudprober/cloudprober/common/iputils"
+	"golang.org/x/net/ipv4"
+	"golang.org/x/net/ipv6"
 )
 
 // NativeEndian is the machine native endian implementation of ByteOrder.
@@ -56,6 +61,29 @@ func sockaddr(sourceIP net.IP, ipVer int) (syscall.Sockaddr, error) {
 	}
 }
 
+// socketError adds a hint on how to fix the socket creation permission
+// errors, which are common when running as a non-root user or inside a
+// container.
+func socketError(err error, datagram bool) error {
+	serr := os.NewSyscallError("socket", err)
+	if !errors.Is(err, syscall.EACCES) && !errors.Is(err, syscall.EPERM) {
+		return serr
+	}
+	if datagram {
+		return fmt.Errorf("%w: unprivileged ICMP sockets are not allowed for the process group (gid %d); allow it through the net.ipv4.ping_group_range sysctl, e.g. sysctl -w net.ipv4.ping_group_range=\"0 2147483647\"", serr, os.Getegid())
+	}
+	return fmt.Errorf("%w: raw ICMP sockets require root or the CAP_NET_RAW capability; set use_datagram_socket to true to use unprivileged ICMP sockets instead", serr)
+}
+
+// setTOS sets the TOS (IPv4) or traffic class (IPv6) byte for the outgoing
+// packets.
+func setTOS(c net.PacketConn, ipVer, tos int) error {
+	if ipVer == 6 {
+		return ipv6.NewPacketConn(c).SetTrafficClass(tos)
+	}
+	return ipv4.NewPacketConn(c).SetTOS(tos)
+}
+
 // listenPacket listens for incoming ICMP packets addressed to sourceIP.
 // We need to write our own listenPacket instead of using "net.ListenPacket"
 // for the following reasons:
@@ -82,7 +110,14 @@ func (p *Probe) listenPacket(sourceIP net.IP) (*icmpPacketConn, error) {
 
 	s, err := syscall.Socket(family, sockType, proto)
 	if err != nil {
-		return nil, os.NewSyscallError("socket", err)
+		return nil, socketError(err, p.useDatagramSocket)
+	}
+
+	if p.opts.BindInterface != "" {
+		if err := iputils.BindToDevice(s, p.opts.BindInterface); err != nil {
+			syscall.Close(s)
+			return nil, fmt.Errorf("error binding ICMP socket to interface %s: %v", p.opts.BindInterface, err)
+		}
 	}
 
 	// Set socket option to receive kernel's timestamp from each packet.
@@ -127,6 +162,13 @@ func (p *Probe) listenPacket(sourceIP net.IP) (*icmpPacketConn, error) {
 		return nil, cerr
 	}
 
+	if tos := int(p.c.GetTos()); tos != 0 {
+		if err := setTOS(c, p.ipVer, tos); err != nil {
+			c.Close()
+			return nil, fmt.Errorf("error setting TOS to %d: %v", tos, err)
+		}
+	}
+
 	ipc := &icmpPacketConn{c: c}
 	ipc.ipConn, _ = c.(*net.IPConn)
 	ipc.udpConn, _ = c.(*net.UDPConn)
@@ -19,7 +19,9 @@ package ping
 
 import (
 	"bytes"
+	"errors"
 	"net"
+	"strings"
 	"syscall"
 	"testing"
 )
@@ -96,3 +98,41 @@ func TestSockaddr(t *testing.T) {
 		})
 	}
 }
+
+func TestSocketError(t *testing.T) {
+	tests := []struct {
+		desc     string
+		err      error
+		datagram bool
+		wantHint string
+	}{
+		{
+			desc:     "datagram-permission-denied",
+			err:      syscall.EACCES,
+			datagram: true,
+			wantHint: "net.ipv4.ping_group_range",
+		},
+		{
+			desc:     "raw-not-permitted",
+			err:      syscall.EPERM,
+			wantHint: "CAP_NET_RAW",
+		},
+		{
+			desc:     "other-error",
+			err:      syscall.EMFILE,
+			datagram: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.desc, func(t *testing.T) {
+			err := socketError(tc.err, tc.datagram)
+			if !errors.Is(err, tc.err) {
+				t.Errorf("socketError(%v)=%v, expected it to wrap the original error", tc.err, err)
+			}
+			if tc.wantHint != "" && !strings.Contains(err.Error(), tc.wantHint) {
+				t.Errorf("socketError(%v)=%v, expected hint: %s", tc.err, err, tc.wantHint)
+			}
+		})
+	}
+}
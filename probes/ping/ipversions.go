@@ -0,0 +1,87 @@
+// Copyright 2025 The Cloudprober Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ping
+
+import (
+	"errors"
+	"net"
+	"strings"
+
+	"github.com/cloudprober/cloudprober/targets/endpoint"
+)
+
+// multiIPVersion returns whether targets may be pinged over more than one IP
+// version, through dual_stack or ip_version_label.
+func (p *Probe) multiIPVersion() bool {
+	return p.c.GetDualStack() || p.c.GetIpVersionLabel() != ""
+}
+
+// ipVersionsForTarget returns the IP versions to ping the target over.
+func (p *Probe) ipVersionsForTarget(target endpoint.Endpoint) []int {
+	if label := p.c.GetIpVersionLabel(); label != "" {
+		switch strings.ToLower(target.Labels[label]) {
+		case "4":
+			return []int{4}
+		case "6":
+			return []int{6}
+		case "dual":
+			return []int{4, 6}
+		}
+	}
+	if p.c.GetDualStack() {
+		return []int{4, 6}
+	}
+	return []int{p.ipVer}
+}
+
+// initIPVersionProbes sets up one internal probe per IP version. Each of
+// these probes uses its own ICMP connection and pings only the targets
+// selected for its IP version.
+func (p *Probe) initIPVersionProbes() error {
+	if p.opts.SourceIP != nil {
+		return errors.New("source_ip cannot be used along with dual_stack or ip_version_label")
+	}
+	if p.c.GetDualStack() && p.opts.IPVersion != 0 {
+		return errors.New("ip_version cannot be used along with dual_stack")
+	}
+
+	for _, ipVer := range []int{4, 6} {
+		vp := &Probe{
+			name:              p.name,
+			opts:              p.opts,
+			c:                 p.c,
+			l:                 p.l,
+			ipVer:             ipVer,
+			results:           make(map[string]*result),
+			ip2target:         make(map[[16]byte]string),
+			target2addr:       make(map[string]net.Addr),
+			useDatagramSocket: p.useDatagramSocket,
+			statsExportFreq:   p.statsExportFreq,
+		}
+		vp.disableFragmentation = p.disableFragmentation && ipVer == 4
+		vp.targetFilter = func(target endpoint.Endpoint) bool {
+			for _, v := range p.ipVersionsForTarget(target) {
+				if v == ipVer {
+					return true
+				}
+			}
+			return false
+		}
+		vp.updateTargets()
+		p.ipVersionProbes = append(p.ipVersionProbes, vp)
+	}
+
+	return nil
+}
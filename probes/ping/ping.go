@@ -67,12 +67,18 @@ const (
 	icmpHeaderSize   = 8
 	minPacketSize    = icmpHeaderSize + timeBytesSize // 16
 	maxPacketSize    = 9001                           // MTU
+	// Replies on raw IPv4 sockets include the IP header (up to 60 bytes).
+	maxIPv4HeaderSize = 60
 )
 
 type result struct {
 	sent, rcvd        int64
 	latency           metrics.LatencyValue
 	validationFailure *metrics.Map[int64]
+	pathMTU           int
+
+	// Bounds of the path MTU search in progress, if any, see discoverPathMTU.
+	pmtuLo, pmtuHi int
 }
 
 // icmpConn is an interface wrapper for *icmp.PacketConn to allow testing.
@@ -102,6 +108,12 @@ type Probe struct {
 	useDatagramSocket    bool
 	disableFragmentation bool
 	statsExportFreq      int // Export frequency
+
+	// If targets are pinged over more than one IP version (dual_stack or
+	// ip_version_label), ping is delegated to these per IP version probes,
+	// and targetFilter selects the targets for each of them.
+	ipVersionProbes []*Probe
+	targetFilter    func(endpoint.Endpoint) bool
 }
 
 // Init initliazes the probe with the given params.
@@ -111,6 +123,14 @@ func (p *Probe) Init(name string, opts *options.Options) error {
 	if err := p.initInternal(); err != nil {
 		return err
 	}
+	for _, vp := range p.ipVersionProbes {
+		if err := vp.listen(); err != nil {
+			return err
+		}
+	}
+	if len(p.ipVersionProbes) != 0 {
+		return nil
+	}
 	return p.listen()
 }
 
@@ -140,6 +160,12 @@ func (p *Probe) initInternal() error {
 	if p.c.GetPayloadSize() > maxPacketSize-icmpHeaderSize {
 		return fmt.Errorf("payload_size (%d) cannot be bigger than %d", p.c.GetPayloadSize(), maxPacketSize-icmpHeaderSize)
 	}
+	if p.c.GetPacketsIntervalMsec() < 0 || p.c.GetIntervalBetweenTargetsUsec() < 0 {
+		return fmt.Errorf("packets_interval_msec (%d) and interval_between_targets_usec (%d) cannot be negative", p.c.GetPacketsIntervalMsec(), p.c.GetIntervalBetweenTargetsUsec())
+	}
+	if p.c.GetTos() < 0 || p.c.GetTos() > 255 {
+		return fmt.Errorf("tos (%d) should be between 0 and 255", p.c.GetTos())
+	}
 	if runtime.GOOS == "windows" {
 		if p.c.UseDatagramSocket != nil {
 			p.l.Warning("use_datagram_socket option is not supported on windows, disabling it.")
@@ -174,6 +200,20 @@ func (p *Probe) initInternal() error {
 		p.disableFragmentation = false
 	}
 
+	if p.c.GetPathMtuDiscovery() {
+		if runtime.GOOS != "linux" || p.ipVer != 4 || p.multiIPVersion() {
+			return errors.New("path_mtu_discovery is supported only for IPv4 on Linux")
+		}
+		if p.c.GetPathMtuMax() < minPathMTU || p.c.GetPathMtuMax() > maxPacketSize {
+			return fmt.Errorf("path_mtu_max (%d) should be between %d and %d", p.c.GetPathMtuMax(), minPathMTU, maxPacketSize)
+		}
+		p.disableFragmentation = true
+	}
+
+	if p.multiIPVersion() {
+		return p.initIPVersionProbes()
+	}
+
 	// Update targets run peiodically as well.
 	p.updateTargets()
 
@@ -224,6 +264,15 @@ func (p *Probe) listen() error {
 
 func (p *Probe) updateTargets() {
 	p.targets = p.opts.Targets.ListEndpoints()
+	if p.targetFilter != nil {
+		var targets []endpoint.Endpoint
+		for _, target := range p.targets {
+			if p.targetFilter(target) {
+				targets = append(targets, target)
+			}
+		}
+		p.targets = targets
+	}
 
 	for _, target := range p.targets {
 
@@ -304,8 +353,8 @@ func (p *Probe) sendPackets(runID uint16, tracker chan bool) {
 
 			tracker <- true
 			// Sleep between pushing packets to avoid network buffer overflow
-			// in case of larger number of targets.
-			time.Sleep(1 * time.Millisecond)
+			// (and bursts) in case of larger number of targets.
+			time.Sleep(time.Duration(p.c.GetIntervalBetweenTargetsUsec()) * time.Microsecond)
 		}
 
 		packetsSent++
@@ -331,12 +380,63 @@ type packetKey struct {
 	seqNo  uint16
 }
 
+// parseReply parses an ICMP echo reply packet received from the peer. It
+// returns nil if the packet is not a valid echo reply from one of the targets.
+func (p *Probe) parseReply(pktbuf []byte, peer net.Addr, recvTime time.Time) *rcvdPkt {
+	pktLen := len(pktbuf)
+	if pktLen < minPacketSize {
+		p.l.Warning("packet too small: size (", strconv.FormatInt(int64(pktLen), 10), ") < minPacketSize (16), from peer: ", peer.String())
+		return nil
+	}
+
+	var ip net.IP
+	if p.useDatagramSocket {
+		ip = peer.(*net.UDPAddr).IP
+	} else {
+		ip = peer.(*net.IPAddr).IP
+	}
+	target := p.ip2target[ipToKey(ip)]
+	if target == "" {
+		p.l.Debug("Got a packet from a peer that's not one of my targets: ", peer.String())
+		return nil
+	}
+
+	// recvmsg for RAW sockets (and even DGRAM sockets on MacOS) doesn't
+	// strip the IP header for IPv4 packets. See following issues:
+	// https://github.com/cloudprober/cloudprober/issues/80
+	// https://github.com/cloudprober/cloudprober/issues/122
+	offset := 0
+	if p.ipVer == 4 && int(pktbuf[0])>>4 == 4 {
+		offset = int(pktbuf[0]&0x0f) << 2
+
+		// If packet includes IP header it needs to be bigger.
+		if pktLen < offset+minPacketSize {
+			p.l.Warning("packet too small: size (", strconv.Itoa(pktLen), ") < minPacketSize+ipHdrLen (", strconv.Itoa(minPacketSize+offset), "), from peer: ", peer.String())
+			return nil
+		}
+	}
+
+	if !validEchoReply(p.ipVer, pktbuf[offset+0]) {
+		p.l.Warning("Not a valid ICMP echo reply packet from: ", target)
+		return nil
+	}
+
+	return &rcvdPkt{
+		tsUnix: recvTime.UnixNano(),
+		target: target,
+		// ICMP packet body starts from the 5th byte
+		id:   binary.BigEndian.Uint16(pktbuf[offset+4 : offset+6]),
+		seq:  binary.BigEndian.Uint16(pktbuf[offset+6 : offset+8]),
+		data: pktbuf[offset+8 : pktLen],
+	}
+}
+
 func (p *Probe) recvPackets(runID uint16, tracker chan bool) {
 	// Number of expected packets: p.c.GetPacketsPerProbe() * len(p.targets)
 	received := make(map[packetKey]bool, int(p.c.GetPacketsPerProbe())*len(p.targets))
 	outstandingPkts := 0
 	p.conn.setReadDeadline(time.Now().Add(p.opts.Timeout))
-	pktbuf := make([]byte, maxPacketSize)
+	pktbuf := make([]byte, maxPacketSize+maxIPv4HeaderSize)
 	for {
 		// To make sure that we have picked up all the packets sent by the sender, we
 		// use a tracker channel. Whenever sender successfully sends a packet, it notifies
@@ -373,11 +473,6 @@ func (p *Probe) recvPackets(runID uint16, tracker chan bool) {
 			p.l.Warning("Negative test, but got a reply from ", peer.String())
 		}
 
-		if pktLen < minPacketSize {
-			p.l.Warning("packet too small: size (", strconv.FormatInt(int64(pktLen), 10), ") < minPacketSize (16), from peer: ", peer.String())
-			continue
-		}
-
 		// recvTime should never be zero:
 		// -- On Unix systems, recvTime comes from the sockets.
 		// -- On Non-Unix systems, read() call returns recvTime based on when
@@ -387,47 +482,11 @@ func (p *Probe) recvPackets(runID uint16, tracker chan bool) {
 			recvTime = time.Now()
 		}
 
-		var ip net.IP
-		if p.useDatagramSocket {
-			ip = peer.(*net.UDPAddr).IP
-		} else {
-			ip = peer.(*net.IPAddr).IP
-		}
-		target := p.ip2target[ipToKey(ip)]
-		if target == "" {
-			p.l.Debug("Got a packet from a peer that's not one of my targets: ", peer.String())
+		pkt := p.parseReply(pktbuf[:pktLen], peer, recvTime)
+		if pkt == nil {
 			continue
 		}
 
-		// recvmsg for RAW sockets (and even DGRAM sockets on MacOS) doesn't
-		// strip the IP header for IPv4 packets. See following issues:
-		// https://github.com/cloudprober/cloudprober/issues/80
-		// https://github.com/cloudprober/cloudprober/issues/122
-		offset := 0
-		if p.ipVer == 4 && int(pktbuf[0])>>4 == 4 {
-			offset = int(pktbuf[0]&0x0f) << 2
-
-			// If packet includes IP header it needs to be bigger.
-			if pktLen < offset+minPacketSize {
-				p.l.Warning("packet too small: size (", strconv.Itoa(pktLen), ") < minPacketSize+ipHdrLen (", strconv.Itoa(minPacketSize+offset), "), from peer: ", peer.String())
-				continue
-			}
-		}
-
-		if !validEchoReply(p.ipVer, pktbuf[offset+0]) {
-			p.l.Warning("Not a valid ICMP echo reply packet from: ", target)
-			continue
-		}
-
-		var pkt = rcvdPkt{
-			tsUnix: recvTime.UnixNano(),
-			target: target,
-			// ICMP packet body starts from the 5th byte
-			id:   binary.BigEndian.Uint16(pktbuf[offset+4 : offset+6]),
-			seq:  binary.BigEndian.Uint16(pktbuf[offset+6 : offset+8]),
-			data: pktbuf[offset+8 : pktLen],
-		}
-
 		rtt := time.Duration(pkt.tsUnix-bytesToTime(pkt.data)) * time.Nanosecond
 
 		// check if this packet belongs to this run
@@ -492,6 +551,20 @@ func (p *Probe) newRunID() uint16 {
 //   - Starts a goroutine to receive packets.
 //   - Send packets.
 func (p *Probe) runProbe() {
+	if len(p.ipVersionProbes) != 0 {
+		p.runCnt++
+		var wg sync.WaitGroup
+		for _, vp := range p.ipVersionProbes {
+			wg.Add(1)
+			go func(vp *Probe) {
+				defer wg.Done()
+				vp.runProbe()
+			}(vp)
+		}
+		wg.Wait()
+		return
+	}
+
 	// Resolve targets if target resolve interval has elapsed.
 	if (p.runCnt % uint64(p.c.GetResolveTargetsInterval())) == 0 {
 		p.updateTargets()
@@ -507,15 +580,27 @@ func (p *Probe) runProbe() {
 	}()
 	p.sendPackets(runID, tracker)
 	wg.Wait()
+
+	// Discover path MTU only when metrics are going to be exported.
+	if p.c.GetPathMtuDiscovery() && p.runCnt%uint64(p.statsExportFreq) == 0 {
+		p.discoverPathMTU(runID)
+	}
 }
 
 // Start starts the probe and writes back the data on the provided channel.
 // Probe should have been initialized with Init() before calling Start on it.
 func (p *Probe) Start(ctx context.Context, dataChan chan *metrics.EventMetrics) {
-	if p.conn == nil {
-		p.l.Critical("Probe has not been properly initialized yet.")
+	probes := p.ipVersionProbes
+	if len(probes) == 0 {
+		probes = []*Probe{p}
+	}
+	for _, vp := range probes {
+		if vp.conn == nil {
+			p.l.Error("Probe has not been properly initialized yet.")
+			return
+		}
+		defer vp.conn.close()
 	}
-	defer p.conn.close()
 
 	ticker := time.NewTicker(p.opts.Interval)
 	defer ticker.Stop()
@@ -532,33 +617,60 @@ func (p *Probe) Start(ctx context.Context, dataChan chan *metrics.EventMetrics)
 			continue
 		}
 
+		// Wait for the outbound rate limits, if configured. Each target counts
+		// as one operation. An error here means that context was canceled.
+		numTargets := 0
+		for _, vp := range probes {
+			numTargets += len(vp.targets)
+		}
+		if err := p.opts.WaitForRateLimitN(ctx, numTargets); err != nil {
+			return
+		}
+
 		p.l.Debugf("Probe started, runcount %d", p.runCnt)
 		p.runProbe()
 		p.l.Debugf("Probe finished, runcount %d", p.runCnt)
 		if (p.runCnt % uint64(p.statsExportFreq)) != 0 {
 			continue
 		}
-		for _, target := range p.targets {
-			result := p.results[target.Name]
-			success := result.rcvd
-			if p.opts.NegativeTest {
-				success = result.sent - result.rcvd
+		for _, vp := range probes {
+			for _, target := range vp.targets {
+				result := vp.results[target.Name]
+				success := result.rcvd
+				if p.opts.NegativeTest {
+					success = result.sent - result.rcvd
+				}
+				em := metrics.NewEventMetrics(ts).
+					AddMetric("total", metrics.NewInt(result.sent)).
+					AddMetric("success", metrics.NewInt(success)).
+					AddMetric(p.opts.LatencyMetricName, result.latency.Clone()).
+					AddLabel("ptype", "ping").
+					AddLabel("probe", p.name).
+					AddLabel("dst", target.Name)
+
+				if p.multiIPVersion() {
+					em.AddLabel("ip_version", strconv.Itoa(vp.ipVer))
+				}
+
+				em.LatencyUnit = p.opts.LatencyUnit
+
+				if p.opts.Validators != nil {
+					em.AddMetric("validation_failure", result.validationFailure)
+				}
+
+				p.opts.RecordMetrics(target, em, dataChan)
+
+				if p.c.GetPathMtuDiscovery() {
+					em := metrics.NewEventMetrics(ts).
+						AddMetric("path_mtu", metrics.NewInt(int64(result.pathMTU))).
+						AddLabel("ptype", "ping").
+						AddLabel("probe", p.name).
+						AddLabel("dst", target.Name)
+					em.Kind = metrics.GAUGE
+					em.SetNotForAlerting()
+					p.opts.RecordMetrics(target, em, dataChan)
+				}
 			}
-			em := metrics.NewEventMetrics(ts).
-				AddMetric("total", metrics.NewInt(result.sent)).
-				AddMetric("success", metrics.NewInt(success)).
-				AddMetric(p.opts.LatencyMetricName, result.latency.Clone()).
-				AddLabel("ptype", "ping").
-				AddLabel("probe", p.name).
-				AddLabel("dst", target.Name)
-
-			em.LatencyUnit = p.opts.LatencyUnit
-
-			if p.opts.Validators != nil {
-				em.AddMetric("validation_failure", result.validationFailure)
-			}
-
-			p.opts.RecordMetrics(target, em, dataChan)
 		}
 	}
 }
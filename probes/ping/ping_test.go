@@ -26,6 +26,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/cloudprober/cloudprober/metrics"
 	"github.com/cloudprober/cloudprober/probes/options"
 	configpb "github.com/cloudprober/cloudprober/probes/ping/proto"
 	"github.com/cloudprober/cloudprober/targets"
@@ -318,6 +319,26 @@ func testRunProbe(t *testing.T, ipVersion int, useDatagramSocket bool, payloadSi
 	}
 }
 
+func TestInitPayloadSize(t *testing.T) {
+	for _, test := range []struct {
+		size    int32
+		wantErr bool
+	}{
+		{size: timeBytesSize},
+		{size: 1472},
+		{size: maxPacketSize - icmpHeaderSize},
+		{size: timeBytesSize - 1, wantErr: true},
+		{size: maxPacketSize - icmpHeaderSize + 1, wantErr: true},
+	} {
+		t.Run(strconv.Itoa(int(test.size)), func(t *testing.T) {
+			_, err := newProbe(&configpb.ProbeConf{PayloadSize: proto.Int32(test.size)}, 0, []string{"2.2.2.2"})
+			if (err != nil) != test.wantErr {
+				t.Errorf("newProbe() with payload_size %d, got err=%v, wantErr=%v", test.size, err, test.wantErr)
+			}
+		})
+	}
+}
+
 // Test runProbe
 func TestRunProbe(t *testing.T) {
 	for _, dgram := range []bool{false, true} {
@@ -441,3 +462,222 @@ func TestRunProbeRealICMP(t *testing.T) {
 		}
 	}
 }
+
+// Verify that each packet's RTT is recorded in the latency distribution.
+func TestRunProbeLatencyDistribution(t *testing.T) {
+	p := &Probe{
+		name: "ping_test",
+		opts: &options.Options{
+			ProbeConf:   &configpb.ProbeConf{PacketsPerProbe: proto.Int32(5)},
+			Targets:     targets.StaticTargets("2.2.2.2,3.3.3.3"),
+			Interval:    2 * time.Second,
+			Timeout:     time.Second,
+			LatencyUnit: time.Millisecond,
+			LatencyDist: metrics.NewDistribution([]float64{0, 1, 10, 100}),
+		},
+	}
+	if err := p.initInternal(); err != nil {
+		t.Fatalf("Got error from initInternal: %v", err)
+	}
+
+	p.conn = newTestICMPConn(p.opts, p.targets)
+	for i := 0; i < 2; i++ {
+		p.runProbe()
+	}
+
+	for _, ep := range p.targets {
+		d, ok := p.results[ep.Name].latency.(*metrics.Distribution)
+		if !ok {
+			t.Fatalf("target %s: latency is %T, want *metrics.Distribution", ep.Name, p.results[ep.Name].latency)
+		}
+		if got := d.Data().Count; got != 10 {
+			t.Errorf("target %s: latency distribution count=%d, want=10", ep.Name, got)
+		}
+	}
+}
+
+func TestIPVersionsForTarget(t *testing.T) {
+	labeled := func(v string) endpoint.Endpoint {
+		return endpoint.Endpoint{Name: "t1", Labels: map[string]string{"ipv": v}}
+	}
+
+	tests := []struct {
+		desc   string
+		c      *configpb.ProbeConf
+		ipVer  int
+		target endpoint.Endpoint
+		want   []int
+	}{
+		{
+			desc:   "default",
+			c:      &configpb.ProbeConf{},
+			ipVer:  4,
+			target: endpoint.Endpoint{Name: "t1"},
+			want:   []int{4},
+		},
+		{
+			desc:   "dual_stack",
+			c:      &configpb.ProbeConf{DualStack: proto.Bool(true)},
+			ipVer:  4,
+			target: endpoint.Endpoint{Name: "t1"},
+			want:   []int{4, 6},
+		},
+		{
+			desc:   "label_v6",
+			c:      &configpb.ProbeConf{IpVersionLabel: proto.String("ipv")},
+			ipVer:  4,
+			target: labeled("6"),
+			want:   []int{6},
+		},
+		{
+			desc:   "label_dual",
+			c:      &configpb.ProbeConf{IpVersionLabel: proto.String("ipv")},
+			ipVer:  4,
+			target: labeled("dual"),
+			want:   []int{4, 6},
+		},
+		{
+			desc:   "label_overrides_dual_stack",
+			c:      &configpb.ProbeConf{DualStack: proto.Bool(true), IpVersionLabel: proto.String("ipv")},
+			ipVer:  4,
+			target: labeled("4"),
+			want:   []int{4},
+		},
+		{
+			desc:   "label_missing",
+			c:      &configpb.ProbeConf{IpVersionLabel: proto.String("ipv")},
+			ipVer:  6,
+			target: endpoint.Endpoint{Name: "t1"},
+			want:   []int{6},
+		},
+		{
+			desc:   "label_invalid",
+			c:      &configpb.ProbeConf{IpVersionLabel: proto.String("ipv")},
+			ipVer:  4,
+			target: labeled("5"),
+			want:   []int{4},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			p := &Probe{c: test.c, ipVer: test.ipVer}
+			if got := p.ipVersionsForTarget(test.target); !reflect.DeepEqual(got, test.want) {
+				t.Errorf("ipVersionsForTarget()=%v, want=%v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestRunProbeDualStack(t *testing.T) {
+	c := &configpb.ProbeConf{DualStack: proto.Bool(true)}
+	p, err := newProbe(c, 0, []string{"2.2.2.2", "::2"})
+	if err != nil {
+		t.Fatalf("Got error from newProbe: %v", err)
+	}
+	if len(p.ipVersionProbes) != 2 {
+		t.Fatalf("Got %d IP version probes, want 2", len(p.ipVersionProbes))
+	}
+
+	for _, vp := range p.ipVersionProbes {
+		tic := newTestICMPConn(p.opts, vp.targets)
+		tic.ipVersion = vp.ipVer
+		vp.conn = tic
+	}
+	p.runProbe()
+
+	// Each target should get replies only over its own IP version, and 100%
+	// loss over the other one.
+	wantReplies := map[int]string{4: "2.2.2.2", 6: "::2"}
+	for _, vp := range p.ipVersionProbes {
+		if len(vp.targets) != 2 {
+			t.Errorf("IPv%d probe: got %d targets, want 2", vp.ipVer, len(vp.targets))
+		}
+		for _, ep := range vp.targets {
+			res := vp.results[ep.Name]
+			if res.sent == 0 {
+				t.Errorf("IPv%d probe, target %s: no packets sent", vp.ipVer, ep.Name)
+			}
+			wantRcvd := int64(0)
+			if wantReplies[vp.ipVer] == ep.Name {
+				wantRcvd = res.sent
+			}
+			if res.rcvd != wantRcvd {
+				t.Errorf("IPv%d probe, target %s: received=%d, want=%d", vp.ipVer, ep.Name, res.rcvd, wantRcvd)
+			}
+		}
+	}
+}
+
+func TestInitDualStackErrors(t *testing.T) {
+	c := &configpb.ProbeConf{DualStack: proto.Bool(true)}
+	if _, err := newProbe(c, 6, []string{"::2"}); err == nil {
+		t.Errorf("Expected error for dual_stack with ip_version, got nil")
+	}
+}
+
+func TestInitTOS(t *testing.T) {
+	for _, tos := range []int32{-1, 256} {
+		if _, err := newProbe(&configpb.ProbeConf{Tos: proto.Int32(tos)}, 0, []string{"2.2.2.2"}); err == nil {
+			t.Errorf("Expected error for tos %d, got nil", tos)
+		}
+	}
+	if _, err := newProbe(&configpb.ProbeConf{Tos: proto.Int32(184)}, 0, []string{"2.2.2.2"}); err != nil {
+		t.Errorf("Unexpected error for tos 184: %v", err)
+	}
+}
+
+func TestStatsExportFreq(t *testing.T) {
+	for _, test := range []struct {
+		interval, statsExportInterval time.Duration
+		want                          int
+	}{
+		{interval: time.Second, statsExportInterval: time.Minute, want: 60},
+		{interval: 10 * time.Second, statsExportInterval: 10 * time.Second, want: 1},
+		{interval: time.Minute, statsExportInterval: 10 * time.Second, want: 1},
+	} {
+		t.Run(fmt.Sprintf("%s,%s", test.interval, test.statsExportInterval), func(t *testing.T) {
+			p := &Probe{
+				name: "ping_test",
+				opts: &options.Options{
+					ProbeConf:           &configpb.ProbeConf{PacketsPerProbe: proto.Int32(1)},
+					Targets:             targets.StaticTargets("2.2.2.2"),
+					Interval:            test.interval,
+					Timeout:             time.Second,
+					StatsExportInterval: test.statsExportInterval,
+				},
+			}
+			if err := p.initInternal(); err != nil {
+				t.Fatalf("Got error from initInternal: %v", err)
+			}
+			if p.statsExportFreq != test.want {
+				t.Errorf("statsExportFreq=%d, want=%d", p.statsExportFreq, test.want)
+			}
+		})
+	}
+}
+
+func TestSendPacketsPacing(t *testing.T) {
+	c := &configpb.ProbeConf{
+		PacketsPerProbe:            proto.Int32(2),
+		PacketsIntervalMsec:        proto.Int32(0),
+		IntervalBetweenTargetsUsec: proto.Int32(20000),
+	}
+	p, err := newProbe(c, 0, []string{"2.2.2.2", "3.3.3.3", "4.4.4.4"})
+	if err != nil {
+		t.Fatalf("Got error from newProbe: %v", err)
+	}
+	p.conn = newTestICMPConn(p.opts, p.targets)
+
+	start := time.Now()
+	p.sendPackets(p.newRunID(), make(chan bool, 6))
+
+	// 2 rounds of 3 packets each, 20ms apart.
+	if elapsed := time.Since(start); elapsed < 120*time.Millisecond {
+		t.Errorf("sendPackets took %s, expected at least 120ms with pacing", elapsed)
+	}
+
+	if _, err := newProbe(&configpb.ProbeConf{IntervalBetweenTargetsUsec: proto.Int32(-1)}, 0, []string{"2.2.2.2"}); err == nil {
+		t.Errorf("Expected error for negative interval_between_targets_usec, got nil")
+	}
+}
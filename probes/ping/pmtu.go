@@ -0,0 +1,155 @@
+// Copyright 2025 The Cloudprober Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ping
+
+import (
+	"net"
+	"time"
+)
+
+const (
+	ipv4HeaderSize = 20
+	// Smallest packet that can carry our ICMP payload (timestamp).
+	minPathMTU = ipv4HeaderSize + minPacketSize
+
+	// Fraction of the probe interval that path MTU discovery can take.
+	pathMTUTimeBudget = 0.5
+)
+
+// pmtuRound sends one packet of the given size (IP packet size) to each of
+// the targets, and returns the targets that replied. Packets that cannot be
+// sent, e.g. because they are bigger than the local interface MTU or the
+// path MTU already known to the kernel, count as failures. Replies are
+// waited for up to the probe timeout, but not beyond the deadline.
+func (p *Probe) pmtuRound(runID, seq uint16, sizes map[string]int, deadline time.Time) map[string]bool {
+	replied := make(map[string]bool)
+
+	outstanding := 0
+	for target, size := range sizes {
+		pktbuf := make([]byte, size-ipv4HeaderSize)
+		p.prepareRequestPacket(pktbuf, runID, seq, time.Now().UnixNano())
+		if _, err := p.conn.write(pktbuf, p.target2addr[target]); err != nil {
+			p.l.Debugf("Path MTU discovery: error sending %d bytes packet to %s: %v", size, target, err)
+			continue
+		}
+		outstanding++
+	}
+
+	readDeadline := time.Now().Add(p.opts.Timeout)
+	if readDeadline.After(deadline) {
+		readDeadline = deadline
+	}
+	p.conn.setReadDeadline(readDeadline)
+	pktbuf := make([]byte, maxPacketSize+maxIPv4HeaderSize)
+	for outstanding > 0 {
+		pktLen, peer, recvTime, err := p.conn.read(pktbuf)
+		if err != nil {
+			if neterr, ok := err.(*net.OpError); ok && neterr.Timeout() {
+				break
+			}
+			p.l.Warning(err.Error())
+			continue
+		}
+
+		pkt := p.parseReply(pktbuf[:pktLen], peer, recvTime)
+		if pkt == nil || replied[pkt.target] {
+			continue
+		}
+		// Sequence number and size together make sure that the reply is for
+		// this round's packet.
+		if !matchPacket(runID, pkt.id, pkt.seq, p.useDatagramSocket) || pkt.seq != seq || len(pkt.data)+icmpHeaderSize+ipv4HeaderSize != sizes[pkt.target] {
+			continue
+		}
+
+		replied[pkt.target] = true
+		outstanding--
+	}
+
+	return replied
+}
+
+// discoverPathMTU finds the path MTU for all targets, by binary searching the
+// packet sizes that reach them with the don't-fragment bit set. For each
+// target, lo is the largest packet size known to work, and hi is the
+// smallest packet size known to fail.
+//
+// Discovery runs in the probe loop, as it shares the connection with the
+// regular pings, so it's limited to pathMTUTimeBudget of the probe interval
+// to not delay the next probe run (e.g. on black-holed paths, where every
+// step times out). Targets for which the search doesn't finish in time keep
+// their last path MTU, and their search resumes from where it left off in
+// the next discovery run.
+func (p *Probe) discoverPathMTU(runID uint16) {
+	deadline := time.Now().Add(time.Duration(float64(p.opts.Interval) * pathMTUTimeBudget))
+
+	lo, hi := make(map[string]int), make(map[string]int)
+	resumed := make(map[string]bool)
+	for _, target := range p.targets {
+		res := p.results[target.Name]
+		if p.target2addr[target.Name] == nil {
+			res.pathMTU, res.pmtuLo, res.pmtuHi = 0, 0, 0
+			continue
+		}
+		if res.pmtuHi-res.pmtuLo > 1 {
+			lo[target.Name], hi[target.Name] = res.pmtuLo, res.pmtuHi
+			resumed[target.Name] = true
+			continue
+		}
+		lo[target.Name], hi[target.Name] = minPathMTU-1, int(p.c.GetPathMtuMax())+1
+	}
+
+	for round := 0; time.Now().Before(deadline); round++ {
+		sizes := make(map[string]int)
+		for target := range lo {
+			if hi[target]-lo[target] <= 1 {
+				continue
+			}
+			// For new searches, try the max size first, as that's the most
+			// common case.
+			if round == 0 && !resumed[target] {
+				sizes[target] = hi[target] - 1
+			} else {
+				sizes[target] = (lo[target] + hi[target]) / 2
+			}
+		}
+		if len(sizes) == 0 {
+			break
+		}
+
+		replied := p.pmtuRound(runID, runID&0xff00+uint16(round), sizes, deadline)
+		for target, size := range sizes {
+			if replied[target] {
+				lo[target] = size
+			} else {
+				hi[target] = size
+			}
+		}
+	}
+
+	for target, mtu := range lo {
+		res := p.results[target]
+		if hi[target]-mtu > 1 {
+			p.l.Warningf("Path MTU discovery: target: %s, search didn't finish in time (range: %d-%d), keeping the last path MTU: %d", target, mtu, hi[target], res.pathMTU)
+			res.pmtuLo, res.pmtuHi = mtu, hi[target]
+			continue
+		}
+		res.pmtuLo, res.pmtuHi = 0, 0
+		res.pathMTU = 0
+		if mtu >= minPathMTU {
+			res.pathMTU = mtu
+		}
+		p.l.Debugf("Path MTU discovery: target: %s, path MTU: %d", target, res.pathMTU)
+	}
+}
@@ -0,0 +1,173 @@
+// Copyright 2025 The Cloudprober Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ping
+
+import (
+	"net"
+	"os"
+	"runtime"
+	"testing"
+	"time"
+
+	configpb "github.com/cloudprober/cloudprober/probes/ping/proto"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/proto"
+)
+
+type pmtuTestReply struct {
+	pkt  []byte
+	peer net.Addr
+}
+
+// pmtuTestConn echoes the packets that fit in the target's path MTU, and
+// silently drops the bigger ones, like an MTU black hole.
+type pmtuTestConn struct {
+	pathMTU  map[string]int
+	replies  chan pmtuTestReply
+	deadline time.Time
+}
+
+func (c *pmtuTestConn) write(buf []byte, peer net.Addr) (int, error) {
+	if len(buf)+ipv4HeaderSize <= c.pathMTU[peerToIP(peer)] {
+		c.replies <- pmtuTestReply{pkt: replyPkt(buf, 4), peer: peer}
+	}
+	return len(buf), nil
+}
+
+func (c *pmtuTestConn) read(buf []byte) (int, net.Addr, time.Time, error) {
+	select {
+	case r := <-c.replies:
+		return copy(buf, r.pkt), r.peer, time.Now(), nil
+	case <-time.After(time.Until(c.deadline)):
+		return 0, nil, time.Time{}, &net.OpError{Op: "read", Err: os.ErrDeadlineExceeded}
+	}
+}
+
+func (c *pmtuTestConn) setReadDeadline(deadline time.Time) {
+	c.deadline = deadline
+}
+
+func (c *pmtuTestConn) close() {}
+
+func TestDiscoverPathMTU(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("path MTU discovery is supported only on Linux")
+	}
+
+	c := &configpb.ProbeConf{
+		UseDatagramSocket: proto.Bool(false),
+		PathMtuDiscovery:  proto.Bool(true),
+	}
+	p, err := newProbe(c, 0, []string{"2.2.2.2", "3.3.3.3", "4.4.4.4"})
+	if err != nil {
+		t.Fatalf("Got error from newProbe: %v", err)
+	}
+	assert.True(t, p.disableFragmentation, "disable_fragmentation should be enabled")
+
+	p.opts.Timeout = 20 * time.Millisecond
+	p.conn = &pmtuTestConn{
+		pathMTU: map[string]int{"2.2.2.2": 1500, "3.3.3.3": 1400},
+		replies: make(chan pmtuTestReply, 10),
+	}
+
+	p.discoverPathMTU(p.newRunID())
+
+	assert.Equal(t, 1500, p.results["2.2.2.2"].pathMTU)
+	assert.Equal(t, 1400, p.results["3.3.3.3"].pathMTU)
+	assert.Equal(t, 0, p.results["4.4.4.4"].pathMTU, "target dropping all packets")
+}
+
+func TestDiscoverPathMTUTimeBudget(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("path MTU discovery is supported only on Linux")
+	}
+
+	c := &configpb.ProbeConf{
+		UseDatagramSocket: proto.Bool(false),
+		PathMtuDiscovery:  proto.Bool(true),
+	}
+	p, err := newProbe(c, 0, []string{"2.2.2.2", "3.3.3.3", "4.4.4.4"})
+	if err != nil {
+		t.Fatalf("Got error from newProbe: %v", err)
+	}
+
+	// Budget allows for only a couple of search steps.
+	p.opts.Interval = 100 * time.Millisecond
+	p.opts.Timeout = 20 * time.Millisecond
+	p.conn = &pmtuTestConn{
+		pathMTU: map[string]int{"2.2.2.2": 1500, "3.3.3.3": 1400},
+		replies: make(chan pmtuTestReply, 10),
+	}
+	p.results["4.4.4.4"].pathMTU = 1400
+
+	start := time.Now()
+	p.discoverPathMTU(p.newRunID())
+	assert.Less(t, time.Since(start), p.opts.Interval)
+
+	assert.Equal(t, 1500, p.results["2.2.2.2"].pathMTU)
+	assert.Equal(t, 0, p.results["3.3.3.3"].pathMTU, "unfinished search should keep the last path MTU")
+	assert.Equal(t, 1400, p.results["4.4.4.4"].pathMTU, "unfinished search should keep the last path MTU")
+
+	// Search resumes where it left off, so it converges over the next runs.
+	for i := 0; i < 10 && p.results["4.4.4.4"].pmtuHi != 0; i++ {
+		p.discoverPathMTU(p.newRunID())
+	}
+	assert.Equal(t, 1500, p.results["2.2.2.2"].pathMTU)
+	assert.Equal(t, 1400, p.results["3.3.3.3"].pathMTU)
+	assert.Equal(t, 0, p.results["4.4.4.4"].pathMTU, "target dropping all packets")
+}
+
+func TestInitPathMTUDiscovery(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("path MTU discovery is supported only on Linux")
+	}
+
+	for _, test := range []struct {
+		desc      string
+		c         *configpb.ProbeConf
+		ipVersion int
+		wantErr   bool
+	}{
+		{
+			desc: "default",
+			c:    &configpb.ProbeConf{PathMtuDiscovery: proto.Bool(true)},
+		},
+		{
+			desc:      "ipv6",
+			c:         &configpb.ProbeConf{PathMtuDiscovery: proto.Bool(true)},
+			ipVersion: 6,
+			wantErr:   true,
+		},
+		{
+			desc:    "dual_stack",
+			c:       &configpb.ProbeConf{PathMtuDiscovery: proto.Bool(true), DualStack: proto.Bool(true)},
+			wantErr: true,
+		},
+		{
+			desc:    "max_too_small",
+			c:       &configpb.ProbeConf{PathMtuDiscovery: proto.Bool(true), PathMtuMax: proto.Int32(20)},
+			wantErr: true,
+		},
+	} {
+		t.Run(test.desc, func(t *testing.T) {
+			_, err := newProbe(test.c, test.ipVersion, []string{"2.2.2.2"})
+			if test.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
@@ -21,17 +21,28 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
-// Next tag: 1
+// Next tag: 21
 type ProbeConf struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
 	// Packets per probe
 	PacketsPerProbe *int32 `protobuf:"varint,6,opt,name=packets_per_probe,json=packetsPerProbe,def=2" json:"packets_per_probe,omitempty"`
-	// How long to wait between two packets to the same target
+	// How long to wait between two packets to the same target. Packets to all
+	// the targets are sent in rounds: one packet to each target (spaced by
+	// interval_between_targets_usec), then wait for packets_interval_msec, and
+	// repeat for packets_per_probe rounds.
 	PacketsIntervalMsec *int32 `protobuf:"varint,7,opt,name=packets_interval_msec,json=packetsIntervalMsec,def=25" json:"packets_interval_msec,omitempty"`
+	// How long to wait between packets to two different targets within a
+	// round, in microseconds. Increase it to pace out the packets, e.g. if
+	// bursts of pings to many targets trip rate policers along the path
+	// and show up as packet loss. Set it to 0 to send packets back to back.
+	IntervalBetweenTargetsUsec *int32 `protobuf:"varint,20,opt,name=interval_between_targets_usec,json=intervalBetweenTargetsUsec,def=1000" json:"interval_between_targets_usec,omitempty"`
 	// Resolve targets after these many probes
 	ResolveTargetsInterval *int32 `protobuf:"varint,9,opt,name=resolve_targets_interval,json=resolveTargetsInterval,def=5" json:"resolve_targets_interval,omitempty"` // =10s
 	// Ping payload size in bytes. It cannot be smaller than 8, number of bytes
-	// required for the nanoseconds timestamp.
+	// required for the nanoseconds timestamp, or bigger than 8993, the jumbo
+	// frame MTU (9001) minus the ICMP header size. Larger payloads, e.g. 1472
+	// (1500 bytes IPv4 packets), can be used to catch fragmentation related
+	// packet loss, especially along with disable_fragmentation.
 	PayloadSize *int32 `protobuf:"varint,10,opt,name=payload_size,json=payloadSize,def=56" json:"payload_size,omitempty"`
 	// Use datagram socket for ICMP.
 	// This option enables unprivileged pings (that is, you don't require root
@@ -54,19 +65,68 @@ type ProbeConf struct {
 	DisableIntegrityCheck *bool `protobuf:"varint,13,opt,name=disable_integrity_check,json=disableIntegrityCheck,def=0" json:"disable_integrity_check,omitempty"`
 	// Do not allow OS-level fragmentation, only works on Linux systems.
 	DisableFragmentation *bool `protobuf:"varint,14,opt,name=disable_fragmentation,json=disableFragmentation,def=0" json:"disable_fragmentation,omitempty"`
-	unknownFields        protoimpl.UnknownFields
-	sizeCache            protoimpl.SizeCache
+	// Ping targets over both IPv4 and IPv6. Metrics for the two IP versions are
+	// exported separately, with the "ip_version" label set to "4" or "6". A
+	// target that doesn't have an address for an IP version shows 100% loss for
+	// that IP version. This option cannot be used along with the probe's
+	// ip_version or source_ip.
+	DualStack *bool `protobuf:"varint,15,opt,name=dual_stack,json=dualStack" json:"dual_stack,omitempty"`
+	// Target label to select the IP version(s) for each target. Label value
+	// can be "4", "6" or "dual", to ping the target over IPv4, IPv6, or both.
+	// Targets without this label (or with an unrecognized value) are pinged
+	// over the probe's IP version (ip_version, or both if dual_stack is set).
+	// If this option is set, "ip_version" label is added to the metrics, as
+	// with dual_stack.
+	//
+	// Example:
+	//
+	//	targets {
+	//	  endpoint {
+	//	    name: "v6-only-host"
+	//	    labels { key: "ip_version" value: "6" }
+	//	  }
+	//	  endpoint { name: "v4-host" }
+	//	}
+	//	ping_probe {
+	//	  ip_version_label: "ip_version"
+	//	}
+	IpVersionLabel *string `protobuf:"bytes,16,opt,name=ip_version_label,json=ipVersionLabel" json:"ip_version_label,omitempty"`
+	// Discover the path MTU to each target. If enabled, every time metrics are
+	// exported, probe sends pings with the don't-fragment bit set, binary
+	// searching the packet sizes, to find the largest packet that reaches the
+	// target. The result is exported as the "path_mtu" gauge, in bytes
+	// including the IP header, or 0 if no packet size worked. This catches MTU
+	// black holes, e.g. across VPN or overlay links.
+	//
+	// Each search step waits for the replies for up to the probe timeout, and
+	// it can take up to about 12 steps (for the default path_mtu_max). Search
+	// is limited to half of the probe interval, so that it doesn't delay the
+	// next probe run; targets for which it doesn't finish in time keep their
+	// last path MTU, and their search resumes from where it left off the next
+	// time. This option is supported only for IPv4 on Linux, and it enables
+	// disable_fragmentation.
+	PathMtuDiscovery *bool `protobuf:"varint,17,opt,name=path_mtu_discovery,json=pathMtuDiscovery" json:"path_mtu_discovery,omitempty"`
+	// Largest MTU to try for path MTU discovery.
+	PathMtuMax *int32 `protobuf:"varint,18,opt,name=path_mtu_max,json=pathMtuMax,def=1500" json:"path_mtu_max,omitempty"`
+	// TOS byte (IPv4) or traffic class (IPv6) for the outgoing packets, e.g. to
+	// validate QoS classes end-to-end. DSCP value goes in the upper 6 bits of
+	// this byte, i.e. tos = dscp << 2. For example, use 184 for DSCP EF (46).
+	Tos           *int32 `protobuf:"varint,19,opt,name=tos" json:"tos,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
 // Default values for ProbeConf fields.
 const (
-	Default_ProbeConf_PacketsPerProbe        = int32(2)
-	Default_ProbeConf_PacketsIntervalMsec    = int32(25)
-	Default_ProbeConf_ResolveTargetsInterval = int32(5)
-	Default_ProbeConf_PayloadSize            = int32(56)
-	Default_ProbeConf_UseDatagramSocket      = bool(true)
-	Default_ProbeConf_DisableIntegrityCheck  = bool(false)
-	Default_ProbeConf_DisableFragmentation   = bool(false)
+	Default_ProbeConf_PacketsPerProbe            = int32(2)
+	Default_ProbeConf_PacketsIntervalMsec        = int32(25)
+	Default_ProbeConf_IntervalBetweenTargetsUsec = int32(1000)
+	Default_ProbeConf_ResolveTargetsInterval     = int32(5)
+	Default_ProbeConf_PayloadSize                = int32(56)
+	Default_ProbeConf_UseDatagramSocket          = bool(true)
+	Default_ProbeConf_DisableIntegrityCheck      = bool(false)
+	Default_ProbeConf_DisableFragmentation       = bool(false)
+	Default_ProbeConf_PathMtuMax                 = int32(1500)
 )
 
 func (x *ProbeConf) Reset() {
@@ -113,6 +173,13 @@ func (x *ProbeConf) GetPacketsIntervalMsec() int32 {
 	return Default_ProbeConf_PacketsIntervalMsec
 }
 
+func (x *ProbeConf) GetIntervalBetweenTargetsUsec() int32 {
+	if x != nil && x.IntervalBetweenTargetsUsec != nil {
+		return *x.IntervalBetweenTargetsUsec
+	}
+	return Default_ProbeConf_IntervalBetweenTargetsUsec
+}
+
 func (x *ProbeConf) GetResolveTargetsInterval() int32 {
 	if x != nil && x.ResolveTargetsInterval != nil {
 		return *x.ResolveTargetsInterval
@@ -148,20 +215,63 @@ func (x *ProbeConf) GetDisableFragmentation() bool {
 	return Default_ProbeConf_DisableFragmentation
 }
 
+func (x *ProbeConf) GetDualStack() bool {
+	if x != nil && x.DualStack != nil {
+		return *x.DualStack
+	}
+	return false
+}
+
+func (x *ProbeConf) GetIpVersionLabel() string {
+	if x != nil && x.IpVersionLabel != nil {
+		return *x.IpVersionLabel
+	}
+	return ""
+}
+
+func (x *ProbeConf) GetPathMtuDiscovery() bool {
+	if x != nil && x.PathMtuDiscovery != nil {
+		return *x.PathMtuDiscovery
+	}
+	return false
+}
+
+func (x *ProbeConf) GetPathMtuMax() int32 {
+	if x != nil && x.PathMtuMax != nil {
+		return *x.PathMtuMax
+	}
+	return Default_ProbeConf_PathMtuMax
+}
+
+func (x *ProbeConf) GetTos() int32 {
+	if x != nil && x.Tos != nil {
+		return *x.Tos
+	}
+	return 0
+}
+
 var File_github_com_cloudprober_cloudprober_probes_ping_proto_config_proto protoreflect.FileDescriptor
 
 const file_github_com_cloudprober_cloudprober_probes_ping_proto_config_proto_rawDesc = "" +
 	"\n" +
-	"Agithub.com/cloudprober/cloudprober/probes/ping/proto/config.proto\x12\x17cloudprober.probes.ping\"\x87\x03\n" +
+	"Agithub.com/cloudprober/cloudprober/probes/ping/proto/config.proto\x12\x17cloudprober.probes.ping\"\x81\x05\n" +
 	"\tProbeConf\x12-\n" +
 	"\x11packets_per_probe\x18\x06 \x01(\x05:\x012R\x0fpacketsPerProbe\x126\n" +
-	"\x15packets_interval_msec\x18\a \x01(\x05:\x0225R\x13packetsIntervalMsec\x12;\n" +
+	"\x15packets_interval_msec\x18\a \x01(\x05:\x0225R\x13packetsIntervalMsec\x12G\n" +
+	"\x1dinterval_between_targets_usec\x18\x14 \x01(\x05:\x041000R\x1aintervalBetweenTargetsUsec\x12;\n" +
 	"\x18resolve_targets_interval\x18\t \x01(\x05:\x015R\x16resolveTargetsInterval\x12%\n" +
 	"\fpayload_size\x18\n" +
 	" \x01(\x05:\x0256R\vpayloadSize\x124\n" +
 	"\x13use_datagram_socket\x18\f \x01(\b:\x04trueR\x11useDatagramSocket\x12=\n" +
 	"\x17disable_integrity_check\x18\r \x01(\b:\x05falseR\x15disableIntegrityCheck\x12:\n" +
-	"\x15disable_fragmentation\x18\x0e \x01(\b:\x05falseR\x14disableFragmentationB6Z4github.com/cloudprober/cloudprober/probes/ping/proto"
+	"\x15disable_fragmentation\x18\x0e \x01(\b:\x05falseR\x14disableFragmentation\x12\x1d\n" +
+	"\n" +
+	"dual_stack\x18\x0f \x01(\bR\tdualStack\x12(\n" +
+	"\x10ip_version_label\x18\x10 \x01(\tR\x0eipVersionLabel\x12,\n" +
+	"\x12path_mtu_discovery\x18\x11 \x01(\bR\x10pathMtuDiscovery\x12&\n" +
+	"\fpath_mtu_max\x18\x12 \x01(\x05:\x041500R\n" +
+	"pathMtuMax\x12\x10\n" +
+	"\x03tos\x18\x13 \x01(\x05R\x03tosB6Z4github.com/cloudprober/cloudprober/probes/ping/proto"
 
 var (
 	file_github_com_cloudprober_cloudprober_probes_ping_proto_config_proto_rawDescOnce sync.Once
@@ -32,8 +32,10 @@ import (
 	"github.com/cloudprober/cloudprober/probes/options"
 	"github.com/cloudprober/cloudprober/probes/ping"
 	configpb "github.com/cloudprober/cloudprober/probes/proto"
+	"github.com/cloudprober/cloudprober/probes/script"
 	"github.com/cloudprober/cloudprober/probes/system"
 	"github.com/cloudprober/cloudprober/probes/tcp"
+	"github.com/cloudprober/cloudprober/probes/transaction"
 	"github.com/cloudprober/cloudprober/probes/udp"
 	"github.com/cloudprober/cloudprober/probes/udplistener"
 	"github.com/cloudprober/cloudprober/web/formatutils"
@@ -42,10 +44,11 @@ import (
 )
 
 var (
-	userDefinedProbes   = make(map[string]Probe)
-	userDefinedProbesMu sync.RWMutex
-	extensionMap        = make(map[int]func() Probe)
-	extensionMapMu      sync.RWMutex
+	userDefinedProbes    = make(map[string]Probe)
+	userDefinedFactories = make(map[string]func() Probe)
+	userDefinedProbesMu  sync.RWMutex
+	extensionMap         = make(map[int]func() Probe)
+	extensionMapMu       sync.RWMutex
 )
 
 // Probe interface represents a probe.
@@ -170,17 +173,20 @@ func initProbe(p *configpb.ProbeDef, opts *options.Options) (probe Probe, probeC
 	case configpb.ProbeDef_SYSTEM:
 		probe = &system.Probe{}
 		probeConf = p.GetSystemProbe()
+	case configpb.ProbeDef_SCRIPT:
+		probe = &script.Probe{}
+		probeConf = p.GetScriptProbe()
+	case configpb.ProbeDef_TRANSACTION:
+		probe = &transaction.Probe{}
+		probeConf = p.GetTransactionProbe()
 	case configpb.ProbeDef_EXTENSION:
 		probe, probeConf, err = getExtensionProbe(p)
 		if err != nil {
 			return
 		}
 	case configpb.ProbeDef_USER_DEFINED:
-		userDefinedProbesMu.RLock()
-		defer userDefinedProbesMu.RUnlock()
-		probe = userDefinedProbes[p.GetName()]
-		if probe == nil {
-			err = fmt.Errorf("unregistered user defined probe: %s", p.GetName())
+		probe, err = getUserDefinedProbe(p.GetName())
+		if err != nil {
 			return
 		}
 		probeConf = p.GetUserDefinedProbe()
@@ -194,6 +200,21 @@ func initProbe(p *configpb.ProbeDef, opts *options.Options) (probe Probe, probeC
 	return
 }
 
+// getUserDefinedProbe returns the user defined probe registered for the
+// given name. Factories take precedence over the registered probe instances.
+func getUserDefinedProbe(name string) (Probe, error) {
+	userDefinedProbesMu.RLock()
+	defer userDefinedProbesMu.RUnlock()
+
+	if f := userDefinedFactories[name]; f != nil {
+		return f(), nil
+	}
+	if probe := userDefinedProbes[name]; probe != nil {
+		return probe, nil
+	}
+	return nil, fmt.Errorf("unregistered user defined probe: %s", name)
+}
+
 // RegisterUserDefined allows you to register a user defined probe with
 // cloudprober.
 // Example usage:
@@ -215,10 +236,63 @@ func RegisterUserDefined(name string, probe Probe) {
 	userDefinedProbes[name] = probe
 }
 
+// RegisterUserDefinedFactory registers a factory for a user defined probe.
+// Unlike RegisterUserDefined, a new probe instance is created every time the
+// probe is created from the config, e.g. when it's re-added on config reload,
+// so probe implementations don't need to support re-initialization.
+//
+// Example usage:
+//
+//	probes.RegisterUserDefinedFactory("fancy_probe", func() probes.Probe {
+//		return &FancyProbe{}
+//	})
+//
+// Corresponding probe config (probe gets "user_defined_probe" field's value
+// as opts.ProbeConf):
+//
+//	probe {
+//	  name: "fancy_probe"
+//	  type: USER_DEFINED
+//	  user_defined_probe: "{\"endpoint\": \"svc.internal:8080\"}"
+//	  targets { ... }
+//	}
+func RegisterUserDefinedFactory(name string, f func() Probe) {
+	userDefinedProbesMu.Lock()
+	defer userDefinedProbesMu.Unlock()
+	userDefinedFactories[name] = f
+}
+
 // RegisterProbeType registers a new probe-type. New probe types are integrated
-// with the config subsystem using the protobuf extensions.
+// with the config subsystem using the protobuf extensions: probe's config is
+// defined as an extension of the ProbeDef message, and the extension field
+// number is used to find the probe type. Extension's value is passed to the
+// probe as opts.ProbeConf.
+//
+// Example usage (see examples/extensions/myprober for a complete example):
+//
+//	// myprobe.proto
+//	import "github.com/cloudprober/cloudprober/probes/proto/config.proto";
 //
-// TODO(manugarg): Add a full example of using extensions.
+//	message ProbeConf {
+//	  optional string redis_server = 1;
+//	}
+//
+//	extend cloudprober.probes.ProbeDef {
+//	  optional ProbeConf redis_probe = 200;
+//	}
+//
+//	// myprobe.go
+//	probes.RegisterProbeType(200, func() probes.Probe { return &RedisProbe{} })
+//
+//	// Config
+//	probe {
+//	  name: "redis_set"
+//	  type: EXTENSION
+//	  targets { ... }
+//	  [myprober.redis_probe] {
+//	    redis_server: "localhost:6379"
+//	  }
+//	}
 func RegisterProbeType(extensionFieldNo int, newProbeFunc func() Probe) {
 	extensionMapMu.Lock()
 	defer extensionMapMu.Unlock()
@@ -86,3 +86,70 @@ func TestGetExtensionProbe(t *testing.T) {
 		t.Errorf("Extensions probe's Init() called %d times, should be called exactly once.", testProbeIntialized)
 	}
 }
+
+// udTestProbe is used for the user defined probes tests. It's not zero-sized,
+// so that distinct instances have distinct addresses, and it keeps its own
+// Init counter so that it doesn't interfere with the other tests.
+type udTestProbe struct {
+	initialized int
+}
+
+func (p *udTestProbe) Init(name string, opts *options.Options) error {
+	p.initialized++
+	return nil
+}
+
+func (p *udTestProbe) Start(ctx context.Context, dataChan chan *metrics.EventMetrics) {}
+
+func TestUserDefinedProbe(t *testing.T) {
+	probeDef := func(name string) *configpb.ProbeDef {
+		return &configpb.ProbeDef{
+			Name:  proto.String(name),
+			Type:  configpb.ProbeDef_USER_DEFINED.Enum(),
+			Probe: &configpb.ProbeDef_UserDefinedProbe{UserDefinedProbe: "conf-" + name},
+			Targets: &targetspb.TargetsDef{
+				Type: &targetspb.TargetsDef_DummyTargets{},
+			},
+		}
+	}
+
+	if _, err := probes.CreateProbe(probeDef("ud-unregistered"), &options.Options{}); err == nil {
+		t.Errorf("Expected error for the unregistered user defined probe")
+	}
+
+	// Registered instance is returned as is.
+	p := &udTestProbe{}
+	probes.RegisterUserDefined("ud-instance", p)
+	for i := 0; i < 2; i++ {
+		probeInfo, err := probes.CreateProbe(probeDef("ud-instance"), &options.Options{})
+		if err != nil {
+			t.Fatalf("Error creating user defined probe: %v", err)
+		}
+		if probeInfo.Probe != p {
+			t.Errorf("Got probe: %v, want registered instance: %v", probeInfo.Probe, p)
+		}
+	}
+
+	// Factory creates a new probe every time.
+	probes.RegisterUserDefinedFactory("ud-factory", func() probes.Probe { return &udTestProbe{} })
+	opts := &options.Options{}
+	probeInfo1, err := probes.CreateProbe(probeDef("ud-factory"), opts)
+	if err != nil {
+		t.Fatalf("Error creating user defined probe: %v", err)
+	}
+	probeInfo2, err := probes.CreateProbe(probeDef("ud-factory"), &options.Options{})
+	if err != nil {
+		t.Fatalf("Error creating user defined probe: %v", err)
+	}
+	if probeInfo1.Probe == probeInfo2.Probe {
+		t.Errorf("Factory returned the same probe instance twice")
+	}
+	for _, pi := range []*probes.ProbeInfo{probeInfo1, probeInfo2} {
+		if n := pi.Probe.(*udTestProbe).initialized; n != 1 {
+			t.Errorf("Factory probe's Init() called %d times, should be called exactly once.", n)
+		}
+	}
+	if opts.ProbeConf != "conf-ud-factory" {
+		t.Errorf("opts.ProbeConf=%v, want: conf-ud-factory", opts.ProbeConf)
+	}
+}
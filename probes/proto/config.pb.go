@@ -16,8 +16,10 @@ import (
 	proto10 "github.com/cloudprober/cloudprober/probes/grpc/proto"
 	proto5 "github.com/cloudprober/cloudprober/probes/http/proto"
 	proto4 "github.com/cloudprober/cloudprober/probes/ping/proto"
+	proto14 "github.com/cloudprober/cloudprober/probes/script/proto"
 	proto13 "github.com/cloudprober/cloudprober/probes/system/proto"
 	proto11 "github.com/cloudprober/cloudprober/probes/tcp/proto"
+	proto15 "github.com/cloudprober/cloudprober/probes/transaction/proto"
 	proto8 "github.com/cloudprober/cloudprober/probes/udp/proto"
 	proto9 "github.com/cloudprober/cloudprober/probes/udplistener/proto"
 	proto "github.com/cloudprober/cloudprober/targets/proto"
@@ -48,6 +50,8 @@ const (
 	ProbeDef_TCP          ProbeDef_Type = 7
 	ProbeDef_BROWSER      ProbeDef_Type = 8
 	ProbeDef_SYSTEM       ProbeDef_Type = 9
+	ProbeDef_SCRIPT       ProbeDef_Type = 10
+	ProbeDef_TRANSACTION  ProbeDef_Type = 11
 	// One of the extension probe types. See "extensions" below for more
 	// details.
 	ProbeDef_EXTENSION ProbeDef_Type = 98
@@ -71,6 +75,8 @@ var (
 		7:  "TCP",
 		8:  "BROWSER",
 		9:  "SYSTEM",
+		10: "SCRIPT",
+		11: "TRANSACTION",
 		98: "EXTENSION",
 		99: "USER_DEFINED",
 	}
@@ -85,6 +91,8 @@ var (
 		"TCP":          7,
 		"BROWSER":      8,
 		"SYSTEM":       9,
+		"SCRIPT":       10,
+		"TRANSACTION":  11,
 		"EXTENSION":    98,
 		"USER_DEFINED": 99,
 	}
@@ -331,7 +339,127 @@ func (Schedule_ScheduleType) EnumDescriptor() ([]byte, []int) {
 	return file_github_com_cloudprober_cloudprober_probes_proto_config_proto_rawDescGZIP(), []int{2, 1}
 }
 
-// Next tag: 101
+type RetryPolicy_RetryOn int32
+
+const (
+	// Retry on any error.
+	RetryPolicy_ANY_ERROR RetryPolicy_RetryOn = 0
+	// Retry on timeouts.
+	RetryPolicy_TIMEOUT RetryPolicy_RetryOn = 1
+	// Retry on connection errors, e.g. connection refused or reset.
+	RetryPolicy_CONNECTION_ERROR RetryPolicy_RetryOn = 2
+)
+
+// Enum value maps for RetryPolicy_RetryOn.
+var (
+	RetryPolicy_RetryOn_name = map[int32]string{
+		0: "ANY_ERROR",
+		1: "TIMEOUT",
+		2: "CONNECTION_ERROR",
+	}
+	RetryPolicy_RetryOn_value = map[string]int32{
+		"ANY_ERROR":        0,
+		"TIMEOUT":          1,
+		"CONNECTION_ERROR": 2,
+	}
+)
+
+func (x RetryPolicy_RetryOn) Enum() *RetryPolicy_RetryOn {
+	p := new(RetryPolicy_RetryOn)
+	*p = x
+	return p
+}
+
+func (x RetryPolicy_RetryOn) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (RetryPolicy_RetryOn) Descriptor() protoreflect.EnumDescriptor {
+	return file_github_com_cloudprober_cloudprober_probes_proto_config_proto_enumTypes[4].Descriptor()
+}
+
+func (RetryPolicy_RetryOn) Type() protoreflect.EnumType {
+	return &file_github_com_cloudprober_cloudprober_probes_proto_config_proto_enumTypes[4]
+}
+
+func (x RetryPolicy_RetryOn) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Do not use.
+func (x *RetryPolicy_RetryOn) UnmarshalJSON(b []byte) error {
+	num, err := protoimpl.X.UnmarshalJSONEnum(x.Descriptor(), b)
+	if err != nil {
+		return err
+	}
+	*x = RetryPolicy_RetryOn(num)
+	return nil
+}
+
+// Deprecated: Use RetryPolicy_RetryOn.Descriptor instead.
+func (RetryPolicy_RetryOn) EnumDescriptor() ([]byte, []int) {
+	return file_github_com_cloudprober_cloudprober_probes_proto_config_proto_rawDescGZIP(), []int{5, 0}
+}
+
+type AnomalyDetection_Method int32
+
+const (
+	// Exponentially weighted moving average and variance.
+	AnomalyDetection_EWMA AnomalyDetection_Method = 0
+	// Median and median absolute deviation (MAD) over a sliding window.
+	AnomalyDetection_MAD AnomalyDetection_Method = 1
+)
+
+// Enum value maps for AnomalyDetection_Method.
+var (
+	AnomalyDetection_Method_name = map[int32]string{
+		0: "EWMA",
+		1: "MAD",
+	}
+	AnomalyDetection_Method_value = map[string]int32{
+		"EWMA": 0,
+		"MAD":  1,
+	}
+)
+
+func (x AnomalyDetection_Method) Enum() *AnomalyDetection_Method {
+	p := new(AnomalyDetection_Method)
+	*p = x
+	return p
+}
+
+func (x AnomalyDetection_Method) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (AnomalyDetection_Method) Descriptor() protoreflect.EnumDescriptor {
+	return file_github_com_cloudprober_cloudprober_probes_proto_config_proto_enumTypes[5].Descriptor()
+}
+
+func (AnomalyDetection_Method) Type() protoreflect.EnumType {
+	return &file_github_com_cloudprober_cloudprober_probes_proto_config_proto_enumTypes[5]
+}
+
+func (x AnomalyDetection_Method) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Do not use.
+func (x *AnomalyDetection_Method) UnmarshalJSON(b []byte) error {
+	num, err := protoimpl.X.UnmarshalJSONEnum(x.Descriptor(), b)
+	if err != nil {
+		return err
+	}
+	*x = AnomalyDetection_Method(num)
+	return nil
+}
+
+// Deprecated: Use AnomalyDetection_Method.Descriptor instead.
+func (AnomalyDetection_Method) EnumDescriptor() ([]byte, []int) {
+	return file_github_com_cloudprober_cloudprober_probes_proto_config_proto_rawDescGZIP(), []int{8, 0}
+}
+
+// Next tag: 109
 type ProbeDef struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
 	// Probe name. It should be unique across all probes.
@@ -354,7 +482,8 @@ type ProbeDef struct {
 	// Default timeout is 1s.
 	Timeout *string `protobuf:"bytes,17,opt,name=timeout" json:"timeout,omitempty"`
 	// Targets for the probe. Targets are required for all probes except
-	// for external, user_defined, and extension probe types.
+	// for external, user_defined, extension, script and transaction probe
+	// types.
 	Targets *proto.TargetsDef `protobuf:"bytes,6,opt,name=targets" json:"targets,omitempty"`
 	// Latency distribution. If specified, latency is stored as a distribution.
 	LatencyDistribution *proto1.Dist `protobuf:"bytes,7,opt,name=latency_distribution,json=latencyDistribution" json:"latency_distribution,omitempty"`
@@ -388,7 +517,15 @@ type ProbeDef struct {
 	//	*ProbeDef_SourceIp
 	//	*ProbeDef_SourceInterface
 	SourceIpConfig isProbeDef_SourceIpConfig `protobuf_oneof:"source_ip_config"`
-	IpVersion      *ProbeDef_IPVersion       `protobuf:"varint,12,opt,name=ip_version,json=ipVersion,enum=cloudprober.probes.ProbeDef_IPVersion" json:"ip_version,omitempty"`
+	// Bind the probe sockets to the source_interface (SO_BINDTODEVICE), so that
+	// the probe traffic goes out through that interface regardless of the
+	// routing table, e.g. on multi-homed probers where the default route isn't
+	// the path under test. Without this option, source_interface only sets the
+	// source IP of the packets. Supported by the PING, UDP, TCP and HTTP probes,
+	// only on Linux. It may require the CAP_NET_RAW capability on older kernels
+	// (before 5.7).
+	BindToInterface *bool               `protobuf:"varint,111,opt,name=bind_to_interface,json=bindToInterface" json:"bind_to_interface,omitempty"`
+	IpVersion       *ProbeDef_IPVersion `protobuf:"varint,12,opt,name=ip_version,json=ipVersion,enum=cloudprober.probes.ProbeDef_IPVersion" json:"ip_version,omitempty"`
 	// How often to export stats. Probes usually run at a higher frequency (e.g.
 	// every second); stats from individual probes are aggregated within
 	// cloudprober until exported. In most cases, users don't need to change the
@@ -450,6 +587,8 @@ type ProbeDef struct {
 	//	*ProbeDef_TcpProbe
 	//	*ProbeDef_BrowserProbe
 	//	*ProbeDef_SystemProbe
+	//	*ProbeDef_ScriptProbe
+	//	*ProbeDef_TransactionProbe
 	//	*ProbeDef_UserDefinedProbe
 	Probe isProbeDef_Probe `protobuf_oneof:"probe"`
 	// Which machines this probe should run on. If defined, cloudprober will run
@@ -486,7 +625,78 @@ type ProbeDef struct {
 	//	}
 	Schedule []*Schedule `protobuf:"bytes,101,rep,name=schedule" json:"schedule,omitempty"`
 	// Debug options. Currently only used to enable logging metrics.
-	DebugOptions    *DebugOptions `protobuf:"bytes,100,opt,name=debug_options,json=debugOptions" json:"debug_options,omitempty"`
+	DebugOptions *DebugOptions `protobuf:"bytes,100,opt,name=debug_options,json=debugOptions" json:"debug_options,omitempty"`
+	// Namespace that this probe belongs to. Namespace must be defined at the
+	// top level of the config. See ProberConfig.namespace for more details.
+	Namespace *string `protobuf:"bytes,102,opt,name=namespace" json:"namespace,omitempty"`
+	// Maximum number of probe operations (probe runs for a target), per second,
+	// for this probe. See ProberConfig.max_outbound_ops_per_sec for the global
+	// limit. 0 means no limit.
+	MaxOpsPerSec *float32 `protobuf:"fixed32,103,opt,name=max_ops_per_sec,json=maxOpsPerSec" json:"max_ops_per_sec,omitempty"`
+	// Failure backoff. If configured, probe interval for a target is backed off
+	// exponentially (with jitter) once the target has been failing for a while,
+	// and is restored to the normal interval as soon as the target recovers.
+	// This cuts pointless load during long outages. Backoff state is exported
+	// through the "consecutive_failures" and "backoff_interval_sec" metrics.
+	// Note: This is currently supported only for the HTTP, TCP, DNS, BROWSER,
+	// SCRIPT and TRANSACTION probe types.
+	FailureBackoff *FailureBackoff `protobuf:"bytes,104,opt,name=failure_backoff,json=failureBackoff" json:"failure_backoff,omitempty"`
+	// Retry policy for the probe requests. If configured, a failed request is
+	// retried within the same probe run, so that a single transient failure
+	// (e.g. a lost packet) doesn't mark the run as failed. "success" metric
+	// reflects success after retries, while "success_first_attempt" reflects
+	// success on the first attempt, keeping flakiness visible. Note that
+	// "timeout" applies to each attempt, and only request errors (e.g.
+	// connection errors and timeouts) are retried, not validation failures.
+	// Note: This is currently supported only for the HTTP, TCP and DNS probe
+	// types.
+	RetryPolicy *RetryPolicy `protobuf:"bytes,105,opt,name=retry_policy,json=retryPolicy" json:"retry_policy,omitempty"`
+	// Scale probe timeout for the targets with the given labels, for example,
+	// to give more time to far away targets:
+	//
+	//	target_timeout_scale {
+	//	  target_labels { key: "region" value: "apac" }
+	//	  multiplier: 3
+	//	}
+	//
+	// First matching rule wins. Scaled timeouts must not be larger than the
+	// probe interval.
+	// Note: This is currently supported only for the HTTP, TCP, DNS, SCRIPT and
+	// TRANSACTION probe types.
+	TargetTimeoutScale []*TargetTimeoutScale `protobuf:"bytes,106,rep,name=target_timeout_scale,json=targetTimeoutScale" json:"target_timeout_scale,omitempty"`
+	// Export fleet-level metrics, aggregated across all targets, once every
+	// stats export interval. Fleet metrics are exported with only the "probe"
+	// and "ptype" labels, so that dashboards don't have to aggregate a large
+	// number of per-target series:
+	//
+	//	fleet_targets: number of active targets.
+	//	fleet_failing_targets: number of targets that had failures in their
+	//	                       last stats export interval.
+	//	fleet_available_fraction: fraction of targets that had no failures.
+	//	fleet_<latency_metric_name>: distribution of per-target average
+	//	                             latencies, if latency_distribution is
+	//	                             configured, mean of per-target average
+	//	                             latencies otherwise.
+	ExportFleetMetrics *bool `protobuf:"varint,107,opt,name=export_fleet_metrics,json=exportFleetMetrics" json:"export_fleet_metrics,omitempty"`
+	// Latency anomaly detection. If configured, average latency for each
+	// target, over its stats export interval, is compared against the target's
+	// baseline, and "anomaly" metric is set to 1 if latency is significantly
+	// higher than the baseline. This helps catch degradations that never cross
+	// a static threshold.
+	AnomalyDetection *AnomalyDetection `protobuf:"bytes,108,opt,name=anomaly_detection,json=anomalyDetection" json:"anomaly_detection,omitempty"`
+	// Restart policy for the probe goroutines. If a probe panics, the panic is
+	// recovered, "panics" metric is incremented, and the probe is restarted
+	// with exponential backoff. For probe types that run independently for each
+	// target (e.g. HTTP, TCP, DNS), only the affected target's loop is
+	// restarted. Once max_restarts is exhausted, the probe (or the target's
+	// loop) is stopped, while the rest of cloudprober keeps running.
+	RestartPolicy *RestartPolicy `protobuf:"bytes,109,opt,name=restart_policy,json=restartPolicy" json:"restart_policy,omitempty"`
+	// Maximum random delay before the probe starts, e.g. "30s". If set, the
+	// probe is started after a random delay between 0 and start_jitter,
+	// instead of being staggered along with the other probes (see
+	// disable_jitter and probe_start_window in the prober config). Use "0s" to
+	// start the probe right away.
+	StartJitter     *string `protobuf:"bytes,110,opt,name=start_jitter,json=startJitter" json:"start_jitter,omitempty"`
 	extensionFields protoimpl.ExtensionFields
 	unknownFields   protoimpl.UnknownFields
 	sizeCache       protoimpl.SizeCache
@@ -630,6 +840,13 @@ func (x *ProbeDef) GetSourceInterface() string {
 	return ""
 }
 
+func (x *ProbeDef) GetBindToInterface() bool {
+	if x != nil && x.BindToInterface != nil {
+		return *x.BindToInterface
+	}
+	return false
+}
+
 func (x *ProbeDef) GetIpVersion() ProbeDef_IPVersion {
 	if x != nil && x.IpVersion != nil {
 		return *x.IpVersion
@@ -762,6 +979,24 @@ func (x *ProbeDef) GetSystemProbe() *proto13.ProbeConf {
 	return nil
 }
 
+func (x *ProbeDef) GetScriptProbe() *proto14.ProbeConf {
+	if x != nil {
+		if x, ok := x.Probe.(*ProbeDef_ScriptProbe); ok {
+			return x.ScriptProbe
+		}
+	}
+	return nil
+}
+
+func (x *ProbeDef) GetTransactionProbe() *proto15.ProbeConf {
+	if x != nil {
+		if x, ok := x.Probe.(*ProbeDef_TransactionProbe); ok {
+			return x.TransactionProbe
+		}
+	}
+	return nil
+}
+
 func (x *ProbeDef) GetUserDefinedProbe() string {
 	if x != nil {
 		if x, ok := x.Probe.(*ProbeDef_UserDefinedProbe); ok {
@@ -792,6 +1027,69 @@ func (x *ProbeDef) GetDebugOptions() *DebugOptions {
 	return nil
 }
 
+func (x *ProbeDef) GetNamespace() string {
+	if x != nil && x.Namespace != nil {
+		return *x.Namespace
+	}
+	return ""
+}
+
+func (x *ProbeDef) GetMaxOpsPerSec() float32 {
+	if x != nil && x.MaxOpsPerSec != nil {
+		return *x.MaxOpsPerSec
+	}
+	return 0
+}
+
+func (x *ProbeDef) GetFailureBackoff() *FailureBackoff {
+	if x != nil {
+		return x.FailureBackoff
+	}
+	return nil
+}
+
+func (x *ProbeDef) GetRetryPolicy() *RetryPolicy {
+	if x != nil {
+		return x.RetryPolicy
+	}
+	return nil
+}
+
+func (x *ProbeDef) GetTargetTimeoutScale() []*TargetTimeoutScale {
+	if x != nil {
+		return x.TargetTimeoutScale
+	}
+	return nil
+}
+
+func (x *ProbeDef) GetExportFleetMetrics() bool {
+	if x != nil && x.ExportFleetMetrics != nil {
+		return *x.ExportFleetMetrics
+	}
+	return false
+}
+
+func (x *ProbeDef) GetAnomalyDetection() *AnomalyDetection {
+	if x != nil {
+		return x.AnomalyDetection
+	}
+	return nil
+}
+
+func (x *ProbeDef) GetRestartPolicy() *RestartPolicy {
+	if x != nil {
+		return x.RestartPolicy
+	}
+	return nil
+}
+
+func (x *ProbeDef) GetStartJitter() string {
+	if x != nil && x.StartJitter != nil {
+		return *x.StartJitter
+	}
+	return ""
+}
+
 type isProbeDef_SourceIpConfig interface {
 	isProbeDef_SourceIpConfig()
 }
@@ -852,9 +1150,18 @@ type ProbeDef_SystemProbe struct {
 	SystemProbe *proto13.ProbeConf `protobuf:"bytes,29,opt,name=system_probe,json=systemProbe,oneof"`
 }
 
+type ProbeDef_ScriptProbe struct {
+	ScriptProbe *proto14.ProbeConf `protobuf:"bytes,30,opt,name=script_probe,json=scriptProbe,oneof"`
+}
+
+type ProbeDef_TransactionProbe struct {
+	TransactionProbe *proto15.ProbeConf `protobuf:"bytes,31,opt,name=transaction_probe,json=transactionProbe,oneof"`
+}
+
 type ProbeDef_UserDefinedProbe struct {
 	// This field's contents are passed on to the user defined probe,
-	// registered for this probe's name through probes.RegisterUserDefined().
+	// registered for this probe's name through probes.RegisterUserDefined()
+	// or probes.RegisterUserDefinedFactory().
 	UserDefinedProbe string `protobuf:"bytes,99,opt,name=user_defined_probe,json=userDefinedProbe,oneof"`
 }
 
@@ -878,6 +1185,10 @@ func (*ProbeDef_BrowserProbe) isProbeDef_Probe() {}
 
 func (*ProbeDef_SystemProbe) isProbeDef_Probe() {}
 
+func (*ProbeDef_ScriptProbe) isProbeDef_Probe() {}
+
+func (*ProbeDef_TransactionProbe) isProbeDef_Probe() {}
+
 func (*ProbeDef_UserDefinedProbe) isProbeDef_Probe() {}
 
 type AdditionalLabel struct {
@@ -948,7 +1259,22 @@ type Schedule struct {
 	EndTime *string `protobuf:"bytes,5,opt,name=end_time,json=endTime,def=23:59" json:"end_time,omitempty"`
 	// Timezone in which the probe should run. If not specified, it defaults to
 	// UTC. Example: "America/New_York"
-	Timezone      *string `protobuf:"bytes,6,opt,name=timezone,def=UTC" json:"timezone,omitempty"`
+	Timezone *string `protobuf:"bytes,6,opt,name=timezone,def=UTC" json:"timezone,omitempty"`
+	// Cron expression to define the schedule period, as an alternative to the
+	// weekday and time fields above. Standard 5-field cron format (minute,
+	// hour, day of month, month, day of week) is supported, and the period
+	// covers all the minutes that match the expression. For example, to enable
+	// a probe only between 01:00 and 04:59 on weekdays:
+	//
+	//	schedule {
+	//	  type: ENABLE
+	//	  cron: "* 1-4 * * mon-fri"
+	//	  timezone: "America/New_York"
+	//	}
+	//
+	// Cron expression is interpreted in the timezone specified above. It cannot
+	// be combined with the weekday and time fields.
+	Cron          *string `protobuf:"bytes,7,opt,name=cron" json:"cron,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -1034,6 +1360,13 @@ func (x *Schedule) GetTimezone() string {
 	return Default_Schedule_Timezone
 }
 
+func (x *Schedule) GetCron() string {
+	if x != nil && x.Cron != nil {
+		return *x.Cron
+	}
+	return ""
+}
+
 type DebugOptions struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
 	// Whether to log metrics or not.
@@ -1079,11 +1412,386 @@ func (x *DebugOptions) GetLogMetrics() bool {
 	return false
 }
 
+type FailureBackoff struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Number of consecutive failures after which backoff kicks in.
+	FailureThreshold *int32 `protobuf:"varint,1,opt,name=failure_threshold,json=failureThreshold,def=3" json:"failure_threshold,omitempty"`
+	// Factor by which the probe interval is multiplied for every subsequent
+	// failure.
+	Multiplier *float32 `protobuf:"fixed32,2,opt,name=multiplier,def=2" json:"multiplier,omitempty"`
+	// Maximum probe interval while backing off.
+	MaxInterval   *string `protobuf:"bytes,3,opt,name=max_interval,json=maxInterval,def=5m" json:"max_interval,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+// Default values for FailureBackoff fields.
+const (
+	Default_FailureBackoff_FailureThreshold = int32(3)
+	Default_FailureBackoff_Multiplier       = float32(2)
+	Default_FailureBackoff_MaxInterval      = string("5m")
+)
+
+func (x *FailureBackoff) Reset() {
+	*x = FailureBackoff{}
+	mi := &file_github_com_cloudprober_cloudprober_probes_proto_config_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FailureBackoff) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FailureBackoff) ProtoMessage() {}
+
+func (x *FailureBackoff) ProtoReflect() protoreflect.Message {
+	mi := &file_github_com_cloudprober_cloudprober_probes_proto_config_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FailureBackoff.ProtoReflect.Descriptor instead.
+func (*FailureBackoff) Descriptor() ([]byte, []int) {
+	return file_github_com_cloudprober_cloudprober_probes_proto_config_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *FailureBackoff) GetFailureThreshold() int32 {
+	if x != nil && x.FailureThreshold != nil {
+		return *x.FailureThreshold
+	}
+	return Default_FailureBackoff_FailureThreshold
+}
+
+func (x *FailureBackoff) GetMultiplier() float32 {
+	if x != nil && x.Multiplier != nil {
+		return *x.Multiplier
+	}
+	return Default_FailureBackoff_Multiplier
+}
+
+func (x *FailureBackoff) GetMaxInterval() string {
+	if x != nil && x.MaxInterval != nil {
+		return *x.MaxInterval
+	}
+	return Default_FailureBackoff_MaxInterval
+}
+
+type RetryPolicy struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Maximum number of retries after the first attempt.
+	MaxRetries *int32 `protobuf:"varint,1,opt,name=max_retries,json=maxRetries,def=1" json:"max_retries,omitempty"`
+	// Time to wait between attempts.
+	Backoff *string `protobuf:"bytes,2,opt,name=backoff,def=100ms" json:"backoff,omitempty"`
+	// Conditions to retry on. If not specified, we retry on any error.
+	RetryOn       []RetryPolicy_RetryOn `protobuf:"varint,3,rep,name=retry_on,json=retryOn,enum=cloudprober.probes.RetryPolicy_RetryOn" json:"retry_on,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+// Default values for RetryPolicy fields.
+const (
+	Default_RetryPolicy_MaxRetries = int32(1)
+	Default_RetryPolicy_Backoff    = string("100ms")
+)
+
+func (x *RetryPolicy) Reset() {
+	*x = RetryPolicy{}
+	mi := &file_github_com_cloudprober_cloudprober_probes_proto_config_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RetryPolicy) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RetryPolicy) ProtoMessage() {}
+
+func (x *RetryPolicy) ProtoReflect() protoreflect.Message {
+	mi := &file_github_com_cloudprober_cloudprober_probes_proto_config_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RetryPolicy.ProtoReflect.Descriptor instead.
+func (*RetryPolicy) Descriptor() ([]byte, []int) {
+	return file_github_com_cloudprober_cloudprober_probes_proto_config_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *RetryPolicy) GetMaxRetries() int32 {
+	if x != nil && x.MaxRetries != nil {
+		return *x.MaxRetries
+	}
+	return Default_RetryPolicy_MaxRetries
+}
+
+func (x *RetryPolicy) GetBackoff() string {
+	if x != nil && x.Backoff != nil {
+		return *x.Backoff
+	}
+	return Default_RetryPolicy_Backoff
+}
+
+func (x *RetryPolicy) GetRetryOn() []RetryPolicy_RetryOn {
+	if x != nil {
+		return x.RetryOn
+	}
+	return nil
+}
+
+type RestartPolicy struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Maximum number of restarts after panics. Set it to a negative value to
+	// restart indefinitely, or to 0 to not restart at all.
+	MaxRestarts *int32 `protobuf:"varint,1,opt,name=max_restarts,json=maxRestarts,def=10" json:"max_restarts,omitempty"`
+	// Time to wait before the first restart. It is doubled for every subsequent
+	// restart, up to max_backoff.
+	InitialBackoff *string `protobuf:"bytes,2,opt,name=initial_backoff,json=initialBackoff,def=1s" json:"initial_backoff,omitempty"`
+	// Maximum time to wait before a restart.
+	MaxBackoff    *string `protobuf:"bytes,3,opt,name=max_backoff,json=maxBackoff,def=5m" json:"max_backoff,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+// Default values for RestartPolicy fields.
+const (
+	Default_RestartPolicy_MaxRestarts    = int32(10)
+	Default_RestartPolicy_InitialBackoff = string("1s")
+	Default_RestartPolicy_MaxBackoff     = string("5m")
+)
+
+func (x *RestartPolicy) Reset() {
+	*x = RestartPolicy{}
+	mi := &file_github_com_cloudprober_cloudprober_probes_proto_config_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RestartPolicy) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RestartPolicy) ProtoMessage() {}
+
+func (x *RestartPolicy) ProtoReflect() protoreflect.Message {
+	mi := &file_github_com_cloudprober_cloudprober_probes_proto_config_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RestartPolicy.ProtoReflect.Descriptor instead.
+func (*RestartPolicy) Descriptor() ([]byte, []int) {
+	return file_github_com_cloudprober_cloudprober_probes_proto_config_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *RestartPolicy) GetMaxRestarts() int32 {
+	if x != nil && x.MaxRestarts != nil {
+		return *x.MaxRestarts
+	}
+	return Default_RestartPolicy_MaxRestarts
+}
+
+func (x *RestartPolicy) GetInitialBackoff() string {
+	if x != nil && x.InitialBackoff != nil {
+		return *x.InitialBackoff
+	}
+	return Default_RestartPolicy_InitialBackoff
+}
+
+func (x *RestartPolicy) GetMaxBackoff() string {
+	if x != nil && x.MaxBackoff != nil {
+		return *x.MaxBackoff
+	}
+	return Default_RestartPolicy_MaxBackoff
+}
+
+type TargetTimeoutScale struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Labels that a target must have for this rule to apply.
+	TargetLabels map[string]string `protobuf:"bytes,1,rep,name=target_labels,json=targetLabels" json:"target_labels,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	// Factor by which the probe timeout is multiplied for the matching targets.
+	Multiplier    *float32 `protobuf:"fixed32,2,req,name=multiplier" json:"multiplier,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TargetTimeoutScale) Reset() {
+	*x = TargetTimeoutScale{}
+	mi := &file_github_com_cloudprober_cloudprober_probes_proto_config_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TargetTimeoutScale) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TargetTimeoutScale) ProtoMessage() {}
+
+func (x *TargetTimeoutScale) ProtoReflect() protoreflect.Message {
+	mi := &file_github_com_cloudprober_cloudprober_probes_proto_config_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TargetTimeoutScale.ProtoReflect.Descriptor instead.
+func (*TargetTimeoutScale) Descriptor() ([]byte, []int) {
+	return file_github_com_cloudprober_cloudprober_probes_proto_config_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *TargetTimeoutScale) GetTargetLabels() map[string]string {
+	if x != nil {
+		return x.TargetLabels
+	}
+	return nil
+}
+
+func (x *TargetTimeoutScale) GetMultiplier() float32 {
+	if x != nil && x.Multiplier != nil {
+		return *x.Multiplier
+	}
+	return 0
+}
+
+type AnomalyDetection struct {
+	state  protoimpl.MessageState   `protogen:"open.v1"`
+	Method *AnomalyDetection_Method `protobuf:"varint,1,opt,name=method,enum=cloudprober.probes.AnomalyDetection_Method,def=0" json:"method,omitempty"`
+	// Number of standard deviations (EWMA) or scaled MADs (MAD) above the
+	// baseline, for a latency sample to be considered anomalous.
+	Threshold *float32 `protobuf:"fixed32,2,opt,name=threshold,def=3" json:"threshold,omitempty"`
+	// Smoothing factor for EWMA. Higher values give more weight to the recent
+	// samples.
+	EwmaAlpha *float32 `protobuf:"fixed32,3,opt,name=ewma_alpha,json=ewmaAlpha,def=0.1" json:"ewma_alpha,omitempty"`
+	// Sliding window size for MAD.
+	WindowSize *int32 `protobuf:"varint,4,opt,name=window_size,json=windowSize,def=30" json:"window_size,omitempty"`
+	// Number of samples to collect before we start detecting anomalies.
+	MinSamples *int32 `protobuf:"varint,5,opt,name=min_samples,json=minSamples,def=10" json:"min_samples,omitempty"`
+	// Alerts on latency anomalies. Alert condition applies to the anomaly
+	// detection results, e.g. following alerts if 3 of the last 5 latency
+	// samples were anomalous:
+	//
+	//	alert {
+	//	  condition { failures: 3 total: 5 }
+	//	  notify { ... }
+	//	}
+	//
+	// Default alert name is "<probe>-latency-anomaly".
+	Alert         []*proto3.AlertConf `protobuf:"bytes,6,rep,name=alert" json:"alert,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+// Default values for AnomalyDetection fields.
+const (
+	Default_AnomalyDetection_Method     = AnomalyDetection_EWMA
+	Default_AnomalyDetection_Threshold  = float32(3)
+	Default_AnomalyDetection_EwmaAlpha  = float32(0.10000000149011612)
+	Default_AnomalyDetection_WindowSize = int32(30)
+	Default_AnomalyDetection_MinSamples = int32(10)
+)
+
+func (x *AnomalyDetection) Reset() {
+	*x = AnomalyDetection{}
+	mi := &file_github_com_cloudprober_cloudprober_probes_proto_config_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AnomalyDetection) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AnomalyDetection) ProtoMessage() {}
+
+func (x *AnomalyDetection) ProtoReflect() protoreflect.Message {
+	mi := &file_github_com_cloudprober_cloudprober_probes_proto_config_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AnomalyDetection.ProtoReflect.Descriptor instead.
+func (*AnomalyDetection) Descriptor() ([]byte, []int) {
+	return file_github_com_cloudprober_cloudprober_probes_proto_config_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *AnomalyDetection) GetMethod() AnomalyDetection_Method {
+	if x != nil && x.Method != nil {
+		return *x.Method
+	}
+	return Default_AnomalyDetection_Method
+}
+
+func (x *AnomalyDetection) GetThreshold() float32 {
+	if x != nil && x.Threshold != nil {
+		return *x.Threshold
+	}
+	return Default_AnomalyDetection_Threshold
+}
+
+func (x *AnomalyDetection) GetEwmaAlpha() float32 {
+	if x != nil && x.EwmaAlpha != nil {
+		return *x.EwmaAlpha
+	}
+	return Default_AnomalyDetection_EwmaAlpha
+}
+
+func (x *AnomalyDetection) GetWindowSize() int32 {
+	if x != nil && x.WindowSize != nil {
+		return *x.WindowSize
+	}
+	return Default_AnomalyDetection_WindowSize
+}
+
+func (x *AnomalyDetection) GetMinSamples() int32 {
+	if x != nil && x.MinSamples != nil {
+		return *x.MinSamples
+	}
+	return Default_AnomalyDetection_MinSamples
+}
+
+func (x *AnomalyDetection) GetAlert() []*proto3.AlertConf {
+	if x != nil {
+		return x.Alert
+	}
+	return nil
+}
+
 var File_github_com_cloudprober_cloudprober_probes_proto_config_proto protoreflect.FileDescriptor
 
 const file_github_com_cloudprober_cloudprober_probes_proto_config_proto_rawDesc = "" +
 	"\n" +
-	"<github.com/cloudprober/cloudprober/probes/proto/config.proto\x12\x12cloudprober.probes\x1a;github.com/cloudprober/cloudprober/metrics/proto/dist.proto\x1aGgithub.com/cloudprober/cloudprober/internal/alerting/proto/config.proto\x1aDgithub.com/cloudprober/cloudprober/probes/browser/proto/config.proto\x1a@github.com/cloudprober/cloudprober/probes/dns/proto/config.proto\x1aEgithub.com/cloudprober/cloudprober/probes/external/proto/config.proto\x1aAgithub.com/cloudprober/cloudprober/probes/grpc/proto/config.proto\x1aAgithub.com/cloudprober/cloudprober/probes/http/proto/config.proto\x1aAgithub.com/cloudprober/cloudprober/probes/ping/proto/config.proto\x1a@github.com/cloudprober/cloudprober/probes/tcp/proto/config.proto\x1a@github.com/cloudprober/cloudprober/probes/udp/proto/config.proto\x1aHgithub.com/cloudprober/cloudprober/probes/udplistener/proto/config.proto\x1aCgithub.com/cloudprober/cloudprober/probes/system/proto/config.proto\x1a>github.com/cloudprober/cloudprober/targets/proto/targets.proto\x1aIgithub.com/cloudprober/cloudprober/internal/validators/proto/config.proto\"\x9c\x10\n" +
+	"<github.com/cloudprober/cloudprober/probes/proto/config.proto\x12\x12cloudprober.probes\x1a;github.com/cloudprober/cloudprober/metrics/proto/dist.proto\x1aGgithub.com/cloudprober/cloudprober/internal/alerting/proto/config.proto\x1aDgithub.com/cloudprober/cloudprober/probes/browser/proto/config.proto\x1a@github.com/cloudprober/cloudprober/probes/dns/proto/config.proto\x1aEgithub.com/cloudprober/cloudprober/probes/external/proto/config.proto\x1aAgithub.com/cloudprober/cloudprober/probes/grpc/proto/config.proto\x1aAgithub.com/cloudprober/cloudprober/probes/http/proto/config.proto\x1aAgithub.com/cloudprober/cloudprober/probes/ping/proto/config.proto\x1aCgithub.com/cloudprober/cloudprober/probes/script/proto/config.proto\x1a@github.com/cloudprober/cloudprober/probes/tcp/proto/config.proto\x1aHgithub.com/cloudprober/cloudprober/probes/transaction/proto/config.proto\x1a@github.com/cloudprober/cloudprober/probes/udp/proto/config.proto\x1aHgithub.com/cloudprober/cloudprober/probes/udplistener/proto/config.proto\x1aCgithub.com/cloudprober/cloudprober/probes/system/proto/config.proto\x1a>github.com/cloudprober/cloudprober/targets/proto/targets.proto\x1aIgithub.com/cloudprober/cloudprober/internal/validators/proto/config.proto\"\xac\x16\n" +
 	"\bProbeDef\x12\x12\n" +
 	"\x04name\x18\x01 \x02(\tR\x04name\x125\n" +
 	"\x04type\x18\x02 \x02(\x0e2!.cloudprober.probes.ProbeDef.TypeR\x04type\x12#\n" +
@@ -1098,7 +1806,8 @@ const file_github_com_cloudprober_cloudprober_probes_proto_config_proto_rawDesc
 	"\tvalidator\x18\t \x03(\v2!.cloudprober.validators.ValidatorR\tvalidator\x12\x1d\n" +
 	"\tsource_ip\x18\n" +
 	" \x01(\tH\x00R\bsourceIp\x12+\n" +
-	"\x10source_interface\x18\v \x01(\tH\x00R\x0fsourceInterface\x12E\n" +
+	"\x10source_interface\x18\v \x01(\tH\x00R\x0fsourceInterface\x12*\n" +
+	"\x11bind_to_interface\x18o \x01(\bR\x0fbindToInterface\x12E\n" +
 	"\n" +
 	"ip_version\x18\f \x01(\x0e2&.cloudprober.probes.ProbeDef.IPVersionR\tipVersion\x12;\n" +
 	"\x1astats_export_interval_msec\x18\r \x01(\x05R\x17statsExportIntervalMsec\x12N\n" +
@@ -1117,11 +1826,22 @@ const file_github_com_cloudprober_cloudprober_probes_proto_config_proto_rawDesc
 	"grpc_probe\x18\x1a \x01(\v2\".cloudprober.probes.grpc.ProbeConfH\x01R\tgrpcProbe\x12@\n" +
 	"\ttcp_probe\x18\x1b \x01(\v2!.cloudprober.probes.tcp.ProbeConfH\x01R\btcpProbe\x12L\n" +
 	"\rbrowser_probe\x18\x1c \x01(\v2%.cloudprober.probes.browser.ProbeConfH\x01R\fbrowserProbe\x12I\n" +
-	"\fsystem_probe\x18\x1d \x01(\v2$.cloudprober.probes.system.ProbeConfH\x01R\vsystemProbe\x12.\n" +
+	"\fsystem_probe\x18\x1d \x01(\v2$.cloudprober.probes.system.ProbeConfH\x01R\vsystemProbe\x12I\n" +
+	"\fscript_probe\x18\x1e \x01(\v2$.cloudprober.probes.script.ProbeConfH\x01R\vscriptProbe\x12X\n" +
+	"\x11transaction_probe\x18\x1f \x01(\v2).cloudprober.probes.transaction.ProbeConfH\x01R\x10transactionProbe\x12.\n" +
 	"\x12user_defined_probe\x18c \x01(\tH\x01R\x10userDefinedProbe\x12\x15\n" +
 	"\x06run_on\x18\x03 \x01(\tR\x05runOn\x128\n" +
 	"\bschedule\x18e \x03(\v2\x1c.cloudprober.probes.ScheduleR\bschedule\x12E\n" +
-	"\rdebug_options\x18d \x01(\v2 .cloudprober.probes.DebugOptionsR\fdebugOptions\"\x99\x01\n" +
+	"\rdebug_options\x18d \x01(\v2 .cloudprober.probes.DebugOptionsR\fdebugOptions\x12\x1c\n" +
+	"\tnamespace\x18f \x01(\tR\tnamespace\x12%\n" +
+	"\x0fmax_ops_per_sec\x18g \x01(\x02R\fmaxOpsPerSec\x12K\n" +
+	"\x0ffailure_backoff\x18h \x01(\v2\".cloudprober.probes.FailureBackoffR\x0efailureBackoff\x12B\n" +
+	"\fretry_policy\x18i \x01(\v2\x1f.cloudprober.probes.RetryPolicyR\vretryPolicy\x12X\n" +
+	"\x14target_timeout_scale\x18j \x03(\v2&.cloudprober.probes.TargetTimeoutScaleR\x12targetTimeoutScale\x120\n" +
+	"\x14export_fleet_metrics\x18k \x01(\bR\x12exportFleetMetrics\x12Q\n" +
+	"\x11anomaly_detection\x18l \x01(\v2$.cloudprober.probes.AnomalyDetectionR\x10anomalyDetection\x12H\n" +
+	"\x0erestart_policy\x18m \x01(\v2!.cloudprober.probes.RestartPolicyR\rrestartPolicy\x12!\n" +
+	"\fstart_jitter\x18n \x01(\tR\vstartJitter\"\xb6\x01\n" +
 	"\x04Type\x12\b\n" +
 	"\x04PING\x10\x00\x12\b\n" +
 	"\x04HTTP\x10\x01\x12\a\n" +
@@ -1133,7 +1853,11 @@ const file_github_com_cloudprober_cloudprober_probes_proto_config_proto_rawDesc
 	"\x03TCP\x10\a\x12\v\n" +
 	"\aBROWSER\x10\b\x12\n" +
 	"\n" +
-	"\x06SYSTEM\x10\t\x12\r\n" +
+	"\x06SYSTEM\x10\t\x12\n" +
+	"\n" +
+	"\x06SCRIPT\x10\n" +
+	"\x12\x0f\n" +
+	"\vTRANSACTION\x10\v\x12\r\n" +
 	"\tEXTENSION\x10b\x12\x10\n" +
 	"\fUSER_DEFINED\x10c\";\n" +
 	"\tIPVersion\x12\x1a\n" +
@@ -1144,7 +1868,7 @@ const file_github_com_cloudprober_cloudprober_probes_proto_config_proto_rawDesc
 	"\x05probe\"9\n" +
 	"\x0fAdditionalLabel\x12\x10\n" +
 	"\x03key\x18\x01 \x02(\tR\x03key\x12\x14\n" +
-	"\x05value\x18\x02 \x02(\tR\x05value\"\x94\x04\n" +
+	"\x05value\x18\x02 \x02(\tR\x05value\"\xa8\x04\n" +
 	"\bSchedule\x12=\n" +
 	"\x04type\x18\x01 \x02(\x0e2).cloudprober.probes.Schedule.ScheduleTypeR\x04type\x12S\n" +
 	"\rstart_weekday\x18\x02 \x01(\x0e2$.cloudprober.probes.Schedule.Weekday:\bEVERYDAYR\fstartWeekday\x12$\n" +
@@ -1153,7 +1877,8 @@ const file_github_com_cloudprober_cloudprober_probes_proto_config_proto_rawDesc
 	"\vend_weekday\x18\x04 \x01(\x0e2$.cloudprober.probes.Schedule.Weekday:\bEVERYDAYR\n" +
 	"endWeekday\x12 \n" +
 	"\bend_time\x18\x05 \x01(\t:\x0523:59R\aendTime\x12\x1f\n" +
-	"\btimezone\x18\x06 \x01(\t:\x03UTCR\btimezone\"s\n" +
+	"\btimezone\x18\x06 \x01(\t:\x03UTCR\btimezone\x12\x12\n" +
+	"\x04cron\x18\a \x01(\tR\x04cron\"s\n" +
 	"\aWeekday\x12\f\n" +
 	"\bEVERYDAY\x10\x00\x12\n" +
 	"\n" +
@@ -1173,7 +1898,48 @@ const file_github_com_cloudprober_cloudprober_probes_proto_config_proto_rawDesc
 	"\aDISABLE\x10\x02\"/\n" +
 	"\fDebugOptions\x12\x1f\n" +
 	"\vlog_metrics\x18\x01 \x01(\bR\n" +
-	"logMetricsB1Z/github.com/cloudprober/cloudprober/probes/proto"
+	"logMetrics\"\x8a\x01\n" +
+	"\x0eFailureBackoff\x12.\n" +
+	"\x11failure_threshold\x18\x01 \x01(\x05:\x013R\x10failureThreshold\x12!\n" +
+	"\n" +
+	"multiplier\x18\x02 \x01(\x02:\x012R\n" +
+	"multiplier\x12%\n" +
+	"\fmax_interval\x18\x03 \x01(\t:\x025mR\vmaxInterval\"\xd3\x01\n" +
+	"\vRetryPolicy\x12\"\n" +
+	"\vmax_retries\x18\x01 \x01(\x05:\x011R\n" +
+	"maxRetries\x12\x1f\n" +
+	"\abackoff\x18\x02 \x01(\t:\x05100msR\abackoff\x12B\n" +
+	"\bretry_on\x18\x03 \x03(\x0e2'.cloudprober.probes.RetryPolicy.RetryOnR\aretryOn\";\n" +
+	"\aRetryOn\x12\r\n" +
+	"\tANY_ERROR\x10\x00\x12\v\n" +
+	"\aTIMEOUT\x10\x01\x12\x14\n" +
+	"\x10CONNECTION_ERROR\x10\x02\"\x88\x01\n" +
+	"\rRestartPolicy\x12%\n" +
+	"\fmax_restarts\x18\x01 \x01(\x05:\x0210R\vmaxRestarts\x12+\n" +
+	"\x0finitial_backoff\x18\x02 \x01(\t:\x021sR\x0einitialBackoff\x12#\n" +
+	"\vmax_backoff\x18\x03 \x01(\t:\x025mR\n" +
+	"maxBackoff\"\xd4\x01\n" +
+	"\x12TargetTimeoutScale\x12]\n" +
+	"\rtarget_labels\x18\x01 \x03(\v28.cloudprober.probes.TargetTimeoutScale.TargetLabelsEntryR\ftargetLabels\x12\x1e\n" +
+	"\n" +
+	"multiplier\x18\x02 \x02(\x02R\n" +
+	"multiplier\x1a?\n" +
+	"\x11TargetLabelsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\xc0\x02\n" +
+	"\x10AnomalyDetection\x12I\n" +
+	"\x06method\x18\x01 \x01(\x0e2+.cloudprober.probes.AnomalyDetection.Method:\x04EWMAR\x06method\x12\x1f\n" +
+	"\tthreshold\x18\x02 \x01(\x02:\x013R\tthreshold\x12\"\n" +
+	"\n" +
+	"ewma_alpha\x18\x03 \x01(\x02:\x030.1R\tewmaAlpha\x12#\n" +
+	"\vwindow_size\x18\x04 \x01(\x05:\x0230R\n" +
+	"windowSize\x12#\n" +
+	"\vmin_samples\x18\x05 \x01(\x05:\x0210R\n" +
+	"minSamples\x125\n" +
+	"\x05alert\x18\x06 \x03(\v2\x1f.cloudprober.alerting.AlertConfR\x05alert\"\x1b\n" +
+	"\x06Method\x12\b\n" +
+	"\x04EWMA\x10\x00\x12\a\n" +
+	"\x03MAD\x10\x01B1Z/github.com/cloudprober/cloudprober/probes/proto"
 
 var (
 	file_github_com_cloudprober_cloudprober_probes_proto_config_proto_rawDescOnce sync.Once
@@ -1187,60 +1953,81 @@ func file_github_com_cloudprober_cloudprober_probes_proto_config_proto_rawDescGZ
 	return file_github_com_cloudprober_cloudprober_probes_proto_config_proto_rawDescData
 }
 
-var file_github_com_cloudprober_cloudprober_probes_proto_config_proto_enumTypes = make([]protoimpl.EnumInfo, 4)
-var file_github_com_cloudprober_cloudprober_probes_proto_config_proto_msgTypes = make([]protoimpl.MessageInfo, 4)
+var file_github_com_cloudprober_cloudprober_probes_proto_config_proto_enumTypes = make([]protoimpl.EnumInfo, 6)
+var file_github_com_cloudprober_cloudprober_probes_proto_config_proto_msgTypes = make([]protoimpl.MessageInfo, 10)
 var file_github_com_cloudprober_cloudprober_probes_proto_config_proto_goTypes = []any{
-	(ProbeDef_Type)(0),         // 0: cloudprober.probes.ProbeDef.Type
-	(ProbeDef_IPVersion)(0),    // 1: cloudprober.probes.ProbeDef.IPVersion
-	(Schedule_Weekday)(0),      // 2: cloudprober.probes.Schedule.Weekday
-	(Schedule_ScheduleType)(0), // 3: cloudprober.probes.Schedule.ScheduleType
-	(*ProbeDef)(nil),           // 4: cloudprober.probes.ProbeDef
-	(*AdditionalLabel)(nil),    // 5: cloudprober.probes.AdditionalLabel
-	(*Schedule)(nil),           // 6: cloudprober.probes.Schedule
-	(*DebugOptions)(nil),       // 7: cloudprober.probes.DebugOptions
-	(*proto.TargetsDef)(nil),   // 8: cloudprober.targets.TargetsDef
-	(*proto1.Dist)(nil),        // 9: cloudprober.metrics.Dist
-	(*proto2.Validator)(nil),   // 10: cloudprober.validators.Validator
-	(*proto3.AlertConf)(nil),   // 11: cloudprober.alerting.AlertConf
-	(*proto4.ProbeConf)(nil),   // 12: cloudprober.probes.ping.ProbeConf
-	(*proto5.ProbeConf)(nil),   // 13: cloudprober.probes.http.ProbeConf
-	(*proto6.ProbeConf)(nil),   // 14: cloudprober.probes.dns.ProbeConf
-	(*proto7.ProbeConf)(nil),   // 15: cloudprober.probes.external.ProbeConf
-	(*proto8.ProbeConf)(nil),   // 16: cloudprober.probes.udp.ProbeConf
-	(*proto9.ProbeConf)(nil),   // 17: cloudprober.probes.udplistener.ProbeConf
-	(*proto10.ProbeConf)(nil),  // 18: cloudprober.probes.grpc.ProbeConf
-	(*proto11.ProbeConf)(nil),  // 19: cloudprober.probes.tcp.ProbeConf
-	(*proto12.ProbeConf)(nil),  // 20: cloudprober.probes.browser.ProbeConf
-	(*proto13.ProbeConf)(nil),  // 21: cloudprober.probes.system.ProbeConf
+	(ProbeDef_Type)(0),           // 0: cloudprober.probes.ProbeDef.Type
+	(ProbeDef_IPVersion)(0),      // 1: cloudprober.probes.ProbeDef.IPVersion
+	(Schedule_Weekday)(0),        // 2: cloudprober.probes.Schedule.Weekday
+	(Schedule_ScheduleType)(0),   // 3: cloudprober.probes.Schedule.ScheduleType
+	(RetryPolicy_RetryOn)(0),     // 4: cloudprober.probes.RetryPolicy.RetryOn
+	(AnomalyDetection_Method)(0), // 5: cloudprober.probes.AnomalyDetection.Method
+	(*ProbeDef)(nil),             // 6: cloudprober.probes.ProbeDef
+	(*AdditionalLabel)(nil),      // 7: cloudprober.probes.AdditionalLabel
+	(*Schedule)(nil),             // 8: cloudprober.probes.Schedule
+	(*DebugOptions)(nil),         // 9: cloudprober.probes.DebugOptions
+	(*FailureBackoff)(nil),       // 10: cloudprober.probes.FailureBackoff
+	(*RetryPolicy)(nil),          // 11: cloudprober.probes.RetryPolicy
+	(*RestartPolicy)(nil),        // 12: cloudprober.probes.RestartPolicy
+	(*TargetTimeoutScale)(nil),   // 13: cloudprober.probes.TargetTimeoutScale
+	(*AnomalyDetection)(nil),     // 14: cloudprober.probes.AnomalyDetection
+	nil,                          // 15: cloudprober.probes.TargetTimeoutScale.TargetLabelsEntry
+	(*proto.TargetsDef)(nil),     // 16: cloudprober.targets.TargetsDef
+	(*proto1.Dist)(nil),          // 17: cloudprober.metrics.Dist
+	(*proto2.Validator)(nil),     // 18: cloudprober.validators.Validator
+	(*proto3.AlertConf)(nil),     // 19: cloudprober.alerting.AlertConf
+	(*proto4.ProbeConf)(nil),     // 20: cloudprober.probes.ping.ProbeConf
+	(*proto5.ProbeConf)(nil),     // 21: cloudprober.probes.http.ProbeConf
+	(*proto6.ProbeConf)(nil),     // 22: cloudprober.probes.dns.ProbeConf
+	(*proto7.ProbeConf)(nil),     // 23: cloudprober.probes.external.ProbeConf
+	(*proto8.ProbeConf)(nil),     // 24: cloudprober.probes.udp.ProbeConf
+	(*proto9.ProbeConf)(nil),     // 25: cloudprober.probes.udplistener.ProbeConf
+	(*proto10.ProbeConf)(nil),    // 26: cloudprober.probes.grpc.ProbeConf
+	(*proto11.ProbeConf)(nil),    // 27: cloudprober.probes.tcp.ProbeConf
+	(*proto12.ProbeConf)(nil),    // 28: cloudprober.probes.browser.ProbeConf
+	(*proto13.ProbeConf)(nil),    // 29: cloudprober.probes.system.ProbeConf
+	(*proto14.ProbeConf)(nil),    // 30: cloudprober.probes.script.ProbeConf
+	(*proto15.ProbeConf)(nil),    // 31: cloudprober.probes.transaction.ProbeConf
 }
 var file_github_com_cloudprober_cloudprober_probes_proto_config_proto_depIdxs = []int32{
 	0,  // 0: cloudprober.probes.ProbeDef.type:type_name -> cloudprober.probes.ProbeDef.Type
-	8,  // 1: cloudprober.probes.ProbeDef.targets:type_name -> cloudprober.targets.TargetsDef
-	9,  // 2: cloudprober.probes.ProbeDef.latency_distribution:type_name -> cloudprober.metrics.Dist
-	10, // 3: cloudprober.probes.ProbeDef.validator:type_name -> cloudprober.validators.Validator
+	16, // 1: cloudprober.probes.ProbeDef.targets:type_name -> cloudprober.targets.TargetsDef
+	17, // 2: cloudprober.probes.ProbeDef.latency_distribution:type_name -> cloudprober.metrics.Dist
+	18, // 3: cloudprober.probes.ProbeDef.validator:type_name -> cloudprober.validators.Validator
 	1,  // 4: cloudprober.probes.ProbeDef.ip_version:type_name -> cloudprober.probes.ProbeDef.IPVersion
-	5,  // 5: cloudprober.probes.ProbeDef.additional_label:type_name -> cloudprober.probes.AdditionalLabel
-	11, // 6: cloudprober.probes.ProbeDef.alert:type_name -> cloudprober.alerting.AlertConf
-	12, // 7: cloudprober.probes.ProbeDef.ping_probe:type_name -> cloudprober.probes.ping.ProbeConf
-	13, // 8: cloudprober.probes.ProbeDef.http_probe:type_name -> cloudprober.probes.http.ProbeConf
-	14, // 9: cloudprober.probes.ProbeDef.dns_probe:type_name -> cloudprober.probes.dns.ProbeConf
-	15, // 10: cloudprober.probes.ProbeDef.external_probe:type_name -> cloudprober.probes.external.ProbeConf
-	16, // 11: cloudprober.probes.ProbeDef.udp_probe:type_name -> cloudprober.probes.udp.ProbeConf
-	17, // 12: cloudprober.probes.ProbeDef.udp_listener_probe:type_name -> cloudprober.probes.udplistener.ProbeConf
-	18, // 13: cloudprober.probes.ProbeDef.grpc_probe:type_name -> cloudprober.probes.grpc.ProbeConf
-	19, // 14: cloudprober.probes.ProbeDef.tcp_probe:type_name -> cloudprober.probes.tcp.ProbeConf
-	20, // 15: cloudprober.probes.ProbeDef.browser_probe:type_name -> cloudprober.probes.browser.ProbeConf
-	21, // 16: cloudprober.probes.ProbeDef.system_probe:type_name -> cloudprober.probes.system.ProbeConf
-	6,  // 17: cloudprober.probes.ProbeDef.schedule:type_name -> cloudprober.probes.Schedule
-	7,  // 18: cloudprober.probes.ProbeDef.debug_options:type_name -> cloudprober.probes.DebugOptions
-	3,  // 19: cloudprober.probes.Schedule.type:type_name -> cloudprober.probes.Schedule.ScheduleType
-	2,  // 20: cloudprober.probes.Schedule.start_weekday:type_name -> cloudprober.probes.Schedule.Weekday
-	2,  // 21: cloudprober.probes.Schedule.end_weekday:type_name -> cloudprober.probes.Schedule.Weekday
-	22, // [22:22] is the sub-list for method output_type
-	22, // [22:22] is the sub-list for method input_type
-	22, // [22:22] is the sub-list for extension type_name
-	22, // [22:22] is the sub-list for extension extendee
-	0,  // [0:22] is the sub-list for field type_name
+	7,  // 5: cloudprober.probes.ProbeDef.additional_label:type_name -> cloudprober.probes.AdditionalLabel
+	19, // 6: cloudprober.probes.ProbeDef.alert:type_name -> cloudprober.alerting.AlertConf
+	20, // 7: cloudprober.probes.ProbeDef.ping_probe:type_name -> cloudprober.probes.ping.ProbeConf
+	21, // 8: cloudprober.probes.ProbeDef.http_probe:type_name -> cloudprober.probes.http.ProbeConf
+	22, // 9: cloudprober.probes.ProbeDef.dns_probe:type_name -> cloudprober.probes.dns.ProbeConf
+	23, // 10: cloudprober.probes.ProbeDef.external_probe:type_name -> cloudprober.probes.external.ProbeConf
+	24, // 11: cloudprober.probes.ProbeDef.udp_probe:type_name -> cloudprober.probes.udp.ProbeConf
+	25, // 12: cloudprober.probes.ProbeDef.udp_listener_probe:type_name -> cloudprober.probes.udplistener.ProbeConf
+	26, // 13: cloudprober.probes.ProbeDef.grpc_probe:type_name -> cloudprober.probes.grpc.ProbeConf
+	27, // 14: cloudprober.probes.ProbeDef.tcp_probe:type_name -> cloudprober.probes.tcp.ProbeConf
+	28, // 15: cloudprober.probes.ProbeDef.browser_probe:type_name -> cloudprober.probes.browser.ProbeConf
+	29, // 16: cloudprober.probes.ProbeDef.system_probe:type_name -> cloudprober.probes.system.ProbeConf
+	30, // 17: cloudprober.probes.ProbeDef.script_probe:type_name -> cloudprober.probes.script.ProbeConf
+	31, // 18: cloudprober.probes.ProbeDef.transaction_probe:type_name -> cloudprober.probes.transaction.ProbeConf
+	8,  // 19: cloudprober.probes.ProbeDef.schedule:type_name -> cloudprober.probes.Schedule
+	9,  // 20: cloudprober.probes.ProbeDef.debug_options:type_name -> cloudprober.probes.DebugOptions
+	10, // 21: cloudprober.probes.ProbeDef.failure_backoff:type_name -> cloudprober.probes.FailureBackoff
+	11, // 22: cloudprober.probes.ProbeDef.retry_policy:type_name -> cloudprober.probes.RetryPolicy
+	13, // 23: cloudprober.probes.ProbeDef.target_timeout_scale:type_name -> cloudprober.probes.TargetTimeoutScale
+	14, // 24: cloudprober.probes.ProbeDef.anomaly_detection:type_name -> cloudprober.probes.AnomalyDetection
+	12, // 25: cloudprober.probes.ProbeDef.restart_policy:type_name -> cloudprober.probes.RestartPolicy
+	3,  // 26: cloudprober.probes.Schedule.type:type_name -> cloudprober.probes.Schedule.ScheduleType
+	2,  // 27: cloudprober.probes.Schedule.start_weekday:type_name -> cloudprober.probes.Schedule.Weekday
+	2,  // 28: cloudprober.probes.Schedule.end_weekday:type_name -> cloudprober.probes.Schedule.Weekday
+	4,  // 29: cloudprober.probes.RetryPolicy.retry_on:type_name -> cloudprober.probes.RetryPolicy.RetryOn
+	15, // 30: cloudprober.probes.TargetTimeoutScale.target_labels:type_name -> cloudprober.probes.TargetTimeoutScale.TargetLabelsEntry
+	5,  // 31: cloudprober.probes.AnomalyDetection.method:type_name -> cloudprober.probes.AnomalyDetection.Method
+	19, // 32: cloudprober.probes.AnomalyDetection.alert:type_name -> cloudprober.alerting.AlertConf
+	33, // [33:33] is the sub-list for method output_type
+	33, // [33:33] is the sub-list for method input_type
+	33, // [33:33] is the sub-list for extension type_name
+	33, // [33:33] is the sub-list for extension extendee
+	0,  // [0:33] is the sub-list for field type_name
 }
 
 func init() { file_github_com_cloudprober_cloudprober_probes_proto_config_proto_init() }
@@ -1261,6 +2048,8 @@ func file_github_com_cloudprober_cloudprober_probes_proto_config_proto_init() {
 		(*ProbeDef_TcpProbe)(nil),
 		(*ProbeDef_BrowserProbe)(nil),
 		(*ProbeDef_SystemProbe)(nil),
+		(*ProbeDef_ScriptProbe)(nil),
+		(*ProbeDef_TransactionProbe)(nil),
 		(*ProbeDef_UserDefinedProbe)(nil),
 	}
 	type x struct{}
@@ -1268,8 +2057,8 @@ func file_github_com_cloudprober_cloudprober_probes_proto_config_proto_init() {
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_github_com_cloudprober_cloudprober_probes_proto_config_proto_rawDesc), len(file_github_com_cloudprober_cloudprober_probes_proto_config_proto_rawDesc)),
-			NumEnums:      4,
-			NumMessages:   4,
+			NumEnums:      6,
+			NumMessages:   10,
 			NumExtensions: 0,
 			NumServices:   0,
 		},
@@ -0,0 +1,173 @@
+// Copyright 2025 The Cloudprober Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package script
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+
+	starlarkjson "go.starlark.net/lib/json"
+	"go.starlark.net/starlark"
+	"go.starlark.net/starlarkstruct"
+)
+
+// Keys for the thread-local values.
+const (
+	contextKey = "context"
+	metricsKey = "metrics"
+)
+
+// builtins returns the helpers that are available to the scripts.
+func (p *Probe) builtins() starlark.StringDict {
+	return starlark.StringDict{
+		"http_request": starlark.NewBuiltin("http_request", p.httpRequest),
+		"tcp_connect":  starlark.NewBuiltin("tcp_connect", p.tcpConnect),
+		"dns_lookup":   starlark.NewBuiltin("dns_lookup", dnsLookup),
+		"metric":       starlark.NewBuiltin("metric", metric),
+		"json":         starlarkjson.Module,
+	}
+}
+
+func threadContext(thread *starlark.Thread) context.Context {
+	if ctx, ok := thread.Local(contextKey).(context.Context); ok {
+		return ctx
+	}
+	return context.Background()
+}
+
+// httpRequest implements:
+//
+//	http_request(url, method="GET", body="", headers={})
+func (p *Probe) httpRequest(thread *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var url, body string
+	var headers *starlark.Dict
+	method := http.MethodGet
+	if err := starlark.UnpackArgs(b.Name(), args, kwargs, "url", &url, "method?", &method, "body?", &body, "headers?", &headers); err != nil {
+		return nil, err
+	}
+
+	var reqBody io.Reader
+	if body != "" {
+		reqBody = strings.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(threadContext(thread), method, url, reqBody)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", b.Name(), err)
+	}
+
+	if headers != nil {
+		for _, item := range headers.Items() {
+			k, ok1 := starlark.AsString(item[0])
+			v, ok2 := starlark.AsString(item[1])
+			if !ok1 || !ok2 {
+				return nil, fmt.Errorf("%s: headers should be a dict of strings, got: %s", b.Name(), headers.String())
+			}
+			if strings.EqualFold(k, "Host") {
+				req.Host = v
+				continue
+			}
+			req.Header.Set(k, v)
+		}
+	}
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", b.Name(), err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("%s: error reading response body: %v", b.Name(), err)
+	}
+
+	respHeaders := starlark.NewDict(len(resp.Header))
+	for k, v := range resp.Header {
+		respHeaders.SetKey(starlark.String(k), starlark.String(strings.Join(v, ",")))
+	}
+
+	return starlarkstruct.FromStringDict(starlarkstruct.Default, starlark.StringDict{
+		"status_code": starlark.MakeInt(resp.StatusCode),
+		"body":        starlark.String(respBody),
+		"headers":     respHeaders,
+	}), nil
+}
+
+// tcpConnect implements:
+//
+//	tcp_connect(addr)
+func (p *Probe) tcpConnect(thread *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var addr string
+	if err := starlark.UnpackArgs(b.Name(), args, kwargs, "addr", &addr); err != nil {
+		return nil, err
+	}
+
+	conn, err := p.dialer.DialContext(threadContext(thread), p.network, addr)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", b.Name(), err)
+	}
+	conn.Close()
+
+	return starlark.None, nil
+}
+
+// dnsLookup implements:
+//
+//	dns_lookup(host)
+func dnsLookup(thread *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var host string
+	if err := starlark.UnpackArgs(b.Name(), args, kwargs, "host", &host); err != nil {
+		return nil, err
+	}
+
+	addrs, err := net.DefaultResolver.LookupHost(threadContext(thread), host)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", b.Name(), err)
+	}
+
+	var ips []starlark.Value
+	for _, addr := range addrs {
+		ips = append(ips, starlark.String(addr))
+	}
+	return starlark.NewList(ips), nil
+}
+
+// metric implements:
+//
+//	metric(name, value)
+func metric(thread *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var name string
+	var v starlark.Value
+	if err := starlark.UnpackArgs(b.Name(), args, kwargs, "name", &name, "value", &v); err != nil {
+		return nil, err
+	}
+	if name == "" {
+		return nil, fmt.Errorf("%s: metric name cannot be empty", b.Name())
+	}
+	// Unpacking into a float64 doesn't accept ints.
+	value, ok := starlark.AsFloat(v)
+	if !ok {
+		return nil, fmt.Errorf("%s: value for metric %s is not a number: %s", b.Name(), name, v.Type())
+	}
+
+	if m, ok := thread.Local(metricsKey).(map[string]float64); ok {
+		m[name] = value
+	}
+	return starlark.None, nil
+}
@@ -0,0 +1,215 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.11
+// 	protoc        v5.27.5
+// source: github.com/cloudprober/cloudprober/probes/script/proto/config.proto
+
+package proto
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// Script probe runs a Starlark (https://github.com/bazelbuild/starlark)
+// script for each target. Script must define a function (by default "probe")
+// that takes a target as the only argument. Target has the following fields:
+// name, ip, port and labels.
+//
+// Probe is considered successful if the function returns None or True, and
+// failed if it returns False or fails with an error (e.g. through fail()).
+//
+// Following helpers are available to the script, in addition to the
+// built-in json module:
+//
+//	http_request(url, method="GET", body="", headers={}): returns a struct
+//	    with status_code, body and headers (dict) fields.
+//	tcp_connect(addr): connects to "host:port" and closes the connection.
+//	dns_lookup(host): returns the list of IP addresses for the host.
+//	metric(name, value): exports a custom metric with the given value.
+//
+// Example:
+//
+//	def probe(target):
+//	    resp = http_request("http://%s:%d/token" % (target.name, target.port))
+//	    if resp.status_code != 200:
+//	        fail("bad status code: %d" % resp.status_code)
+//	    token = json.decode(resp.body)["token"]
+//	    resp = http_request("http://%s:%d/data" % (target.name, target.port),
+//	                        headers={"Authorization": "Bearer " + token})
+//	    metric("data_size", len(resp.body))
+//	    return resp.status_code == 200
+//
+// Next tag: 4
+type ProbeConf struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Types that are valid to be assigned to Source:
+	//
+	//	*ProbeConf_Script
+	//	*ProbeConf_ScriptFile
+	Source isProbeConf_Source `protobuf_oneof:"source"`
+	// Name of the function to call for each probe run.
+	EntryPoint    *string `protobuf:"bytes,3,opt,name=entry_point,json=entryPoint,def=probe" json:"entry_point,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+// Default values for ProbeConf fields.
+const (
+	Default_ProbeConf_EntryPoint = string("probe")
+)
+
+func (x *ProbeConf) Reset() {
+	*x = ProbeConf{}
+	mi := &file_github_com_cloudprober_cloudprober_probes_script_proto_config_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ProbeConf) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ProbeConf) ProtoMessage() {}
+
+func (x *ProbeConf) ProtoReflect() protoreflect.Message {
+	mi := &file_github_com_cloudprober_cloudprober_probes_script_proto_config_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ProbeConf.ProtoReflect.Descriptor instead.
+func (*ProbeConf) Descriptor() ([]byte, []int) {
+	return file_github_com_cloudprober_cloudprober_probes_script_proto_config_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *ProbeConf) GetSource() isProbeConf_Source {
+	if x != nil {
+		return x.Source
+	}
+	return nil
+}
+
+func (x *ProbeConf) GetScript() string {
+	if x != nil {
+		if x, ok := x.Source.(*ProbeConf_Script); ok {
+			return x.Script
+		}
+	}
+	return ""
+}
+
+func (x *ProbeConf) GetScriptFile() string {
+	if x != nil {
+		if x, ok := x.Source.(*ProbeConf_ScriptFile); ok {
+			return x.ScriptFile
+		}
+	}
+	return ""
+}
+
+func (x *ProbeConf) GetEntryPoint() string {
+	if x != nil && x.EntryPoint != nil {
+		return *x.EntryPoint
+	}
+	return Default_ProbeConf_EntryPoint
+}
+
+type isProbeConf_Source interface {
+	isProbeConf_Source()
+}
+
+type ProbeConf_Script struct {
+	// Inline script.
+	Script string `protobuf:"bytes,1,opt,name=script,oneof"`
+}
+
+type ProbeConf_ScriptFile struct {
+	// File to read the script from.
+	ScriptFile string `protobuf:"bytes,2,opt,name=script_file,json=scriptFile,oneof"`
+}
+
+func (*ProbeConf_Script) isProbeConf_Source() {}
+
+func (*ProbeConf_ScriptFile) isProbeConf_Source() {}
+
+var File_github_com_cloudprober_cloudprober_probes_script_proto_config_proto protoreflect.FileDescriptor
+
+const file_github_com_cloudprober_cloudprober_probes_script_proto_config_proto_rawDesc = "" +
+	"\n" +
+	"Cgithub.com/cloudprober/cloudprober/probes/script/proto/config.proto\x12\x19cloudprober.probes.script\"z\n" +
+	"\tProbeConf\x12\x18\n" +
+	"\x06script\x18\x01 \x01(\tH\x00R\x06script\x12!\n" +
+	"\vscript_file\x18\x02 \x01(\tH\x00R\n" +
+	"scriptFile\x12&\n" +
+	"\ventry_point\x18\x03 \x01(\t:\x05probeR\n" +
+	"entryPointB\b\n" +
+	"\x06sourceB8Z6github.com/cloudprober/cloudprober/probes/script/proto"
+
+var (
+	file_github_com_cloudprober_cloudprober_probes_script_proto_config_proto_rawDescOnce sync.Once
+	file_github_com_cloudprober_cloudprober_probes_script_proto_config_proto_rawDescData []byte
+)
+
+func file_github_com_cloudprober_cloudprober_probes_script_proto_config_proto_rawDescGZIP() []byte {
+	file_github_com_cloudprober_cloudprober_probes_script_proto_config_proto_rawDescOnce.Do(func() {
+		file_github_com_cloudprober_cloudprober_probes_script_proto_config_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_github_com_cloudprober_cloudprober_probes_script_proto_config_proto_rawDesc), len(file_github_com_cloudprober_cloudprober_probes_script_proto_config_proto_rawDesc)))
+	})
+	return file_github_com_cloudprober_cloudprober_probes_script_proto_config_proto_rawDescData
+}
+
+var file_github_com_cloudprober_cloudprober_probes_script_proto_config_proto_msgTypes = make([]protoimpl.MessageInfo, 1)
+var file_github_com_cloudprober_cloudprober_probes_script_proto_config_proto_goTypes = []any{
+	(*ProbeConf)(nil), // 0: cloudprober.probes.script.ProbeConf
+}
+var file_github_com_cloudprober_cloudprober_probes_script_proto_config_proto_depIdxs = []int32{
+	0, // [0:0] is the sub-list for method output_type
+	0, // [0:0] is the sub-list for method input_type
+	0, // [0:0] is the sub-list for extension type_name
+	0, // [0:0] is the sub-list for extension extendee
+	0, // [0:0] is the sub-list for field type_name
+}
+
+func init() { file_github_com_cloudprober_cloudprober_probes_script_proto_config_proto_init() }
+func file_github_com_cloudprober_cloudprober_probes_script_proto_config_proto_init() {
+	if File_github_com_cloudprober_cloudprober_probes_script_proto_config_proto != nil {
+		return
+	}
+	file_github_com_cloudprober_cloudprober_probes_script_proto_config_proto_msgTypes[0].OneofWrappers = []any{
+		(*ProbeConf_Script)(nil),
+		(*ProbeConf_ScriptFile)(nil),
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_github_com_cloudprober_cloudprober_probes_script_proto_config_proto_rawDesc), len(file_github_com_cloudprober_cloudprober_probes_script_proto_config_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   1,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_github_com_cloudprober_cloudprober_probes_script_proto_config_proto_goTypes,
+		DependencyIndexes: file_github_com_cloudprober_cloudprober_probes_script_proto_config_proto_depIdxs,
+		MessageInfos:      file_github_com_cloudprober_cloudprober_probes_script_proto_config_proto_msgTypes,
+	}.Build()
+	File_github_com_cloudprober_cloudprober_probes_script_proto_config_proto = out.File
+	file_github_com_cloudprober_cloudprober_probes_script_proto_config_proto_goTypes = nil
+	file_github_com_cloudprober_cloudprober_probes_script_proto_config_proto_depIdxs = nil
+}
@@ -0,0 +1,283 @@
+// Copyright 2025 The Cloudprober Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+/*
+Package script implements a probe type that runs a Starlark script for each
+target. It's meant for simple multi-step checks that can be expressed in the
+config itself, without having to build and ship an external probe binary.
+*/
+package script
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/cloudprober/cloudprober/internal/file"
+	"github.com/cloudprober/cloudprober/logger"
+	"github.com/cloudprober/cloudprober/metrics"
+	"github.com/cloudprober/cloudprober/probes/common/sched"
+	"github.com/cloudprober/cloudprober/probes/options"
+	configpb "github.com/cloudprober/cloudprober/probes/script/proto"
+	"github.com/cloudprober/cloudprober/targets/endpoint"
+	"go.starlark.net/starlark"
+	"go.starlark.net/starlarkstruct"
+	"go.starlark.net/syntax"
+)
+
+// Probe holds aggregate information about all probe runs, per-target.
+type Probe struct {
+	name string
+	opts *options.Options
+	c    *configpb.ProbeConf
+	l    *logger.Logger
+
+	prog        *starlark.Program
+	predeclared starlark.StringDict
+	network     string
+	dialer      *net.Dialer
+	httpClient  *http.Client
+}
+
+type probeResult struct {
+	total, success int64
+	latency        metrics.LatencyValue
+
+	// Latest values of the metrics emitted by the script through metric().
+	customMetrics map[string]float64
+}
+
+func (p *Probe) newResult() sched.ProbeResult {
+	result := &probeResult{
+		customMetrics: make(map[string]float64),
+	}
+
+	if p.opts.LatencyDist != nil {
+		result.latency = p.opts.LatencyDist.CloneDist()
+	} else {
+		result.latency = metrics.NewFloat(0)
+	}
+
+	return result
+}
+
+// SuccessCount returns the number of successful probe runs so far.
+func (result *probeResult) SuccessCount() int64 {
+	return result.success
+}
+
+func (result *probeResult) Metrics(ts time.Time, _ int64, opts *options.Options) []*metrics.EventMetrics {
+	em := metrics.NewEventMetrics(ts).
+		AddMetric("total", metrics.NewInt(result.total)).
+		AddMetric("success", metrics.NewInt(result.success)).
+		AddMetric(opts.LatencyMetricName, result.latency.Clone()).
+		AddLabel("ptype", "script")
+
+	ems := []*metrics.EventMetrics{em}
+
+	// Metrics emitted by the script represent the value at the time of the
+	// last run, hence we export them as GAUGE, in an independent EM.
+	if len(result.customMetrics) != 0 {
+		names := make([]string, 0, len(result.customMetrics))
+		for name := range result.customMetrics {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		em := metrics.NewEventMetrics(ts)
+		for _, name := range names {
+			em.AddMetric(name, metrics.NewFloat(result.customMetrics[name]))
+		}
+		em.Kind = metrics.GAUGE
+		em.AddLabel("ptype", "script")
+		ems = append(ems, em)
+	}
+
+	return ems
+}
+
+func loadScript(c *configpb.ProbeConf) (string, string, error) {
+	if c.GetScriptFile() != "" {
+		b, err := file.ReadFile(context.Background(), c.GetScriptFile())
+		if err != nil {
+			return "", "", fmt.Errorf("error reading script file (%s): %v", c.GetScriptFile(), err)
+		}
+		return c.GetScriptFile(), string(b), nil
+	}
+	if c.GetScript() == "" {
+		return "", "", errors.New("one of script or script_file is required")
+	}
+	return "script", c.GetScript(), nil
+}
+
+// Init initializes the probe with the given params.
+func (p *Probe) Init(name string, opts *options.Options) error {
+	c, ok := opts.ProbeConf.(*configpb.ProbeConf)
+	if !ok {
+		return fmt.Errorf("not script probe config")
+	}
+	p.name = name
+	p.opts = opts
+	if p.l = opts.Logger; p.l == nil {
+		p.l = &logger.Logger{}
+	}
+	p.c = c
+
+	p.network = "tcp"
+	if p.opts.IPVersion != 0 {
+		p.network += strconv.Itoa(p.opts.IPVersion)
+	}
+	p.dialer = &net.Dialer{}
+	if p.opts.SourceIP != nil {
+		p.dialer.LocalAddr = &net.TCPAddr{IP: p.opts.SourceIP}
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DialContext = p.dialer.DialContext
+	p.httpClient = &http.Client{Transport: transport}
+
+	p.predeclared = p.builtins()
+
+	filename, src, err := loadScript(p.c)
+	if err != nil {
+		return err
+	}
+	f, prog, err := starlark.SourceProgram(filename, src, p.predeclared.Has)
+	if err != nil {
+		return fmt.Errorf("error compiling script: %v", err)
+	}
+
+	// Make sure that the entry point is defined at the top level.
+	var found bool
+	for _, stmt := range f.Stmts {
+		if def, ok := stmt.(*syntax.DefStmt); ok && def.Name.Name == p.c.GetEntryPoint() {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return fmt.Errorf("function %s() not defined in the script", p.c.GetEntryPoint())
+	}
+	p.prog = prog
+
+	return nil
+}
+
+func targetValue(target endpoint.Endpoint) starlark.Value {
+	labels := starlark.NewDict(len(target.Labels))
+	for k, v := range target.Labels {
+		labels.SetKey(starlark.String(k), starlark.String(v))
+	}
+	ip := ""
+	if target.IP != nil {
+		ip = target.IP.String()
+	}
+	return starlarkstruct.FromStringDict(starlark.String("target"), starlark.StringDict{
+		"name":   starlark.String(target.Name),
+		"ip":     starlark.String(ip),
+		"port":   starlark.MakeInt(target.Port),
+		"labels": labels,
+	})
+}
+
+// runScript runs the script's entry point for the given target, and returns
+// whether probe was successful.
+func (p *Probe) runScript(ctx context.Context, target endpoint.Endpoint, customMetrics map[string]float64, l *logger.Logger) (bool, error) {
+	thread := &starlark.Thread{
+		Name:  p.name + "/" + target.Name,
+		Print: func(_ *starlark.Thread, msg string) { l.Info(msg) },
+	}
+	thread.SetLocal(contextKey, ctx)
+	thread.SetLocal(metricsKey, customMetrics)
+
+	// Stop script execution if context is canceled, e.g. on timeout.
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			thread.Cancel(ctx.Err().Error())
+		case <-done:
+		}
+	}()
+
+	// We initialize the program for every run, so that runs don't share state
+	// through global variables.
+	globals, err := p.prog.Init(thread, p.predeclared)
+	if err != nil {
+		return false, scriptError(err)
+	}
+
+	fn, ok := globals[p.c.GetEntryPoint()].(starlark.Callable)
+	if !ok {
+		return false, fmt.Errorf("%s is not a function", p.c.GetEntryPoint())
+	}
+
+	v, err := starlark.Call(thread, fn, starlark.Tuple{targetValue(target)}, nil)
+	if err != nil {
+		return false, scriptError(err)
+	}
+	return v == starlark.None || bool(v.Truth()), nil
+}
+
+func scriptError(err error) error {
+	var evalErr *starlark.EvalError
+	if errors.As(err, &evalErr) {
+		return errors.New(evalErr.Backtrace())
+	}
+	return err
+}
+
+func (p *Probe) runProbe(ctx context.Context, runReq *sched.RunProbeForTargetRequest) {
+	if runReq.Result == nil {
+		runReq.Result = p.newResult()
+	}
+
+	target, result := runReq.Target, runReq.Result.(*probeResult)
+	l := p.l.WithAttributes(slog.String("target", target.Name))
+
+	result.total++
+
+	start := time.Now()
+	success, err := p.runScript(ctx, target, result.customMetrics, l)
+	latency := time.Since(start)
+
+	if err != nil {
+		l.Error(err.Error())
+		return
+	}
+	if !success {
+		l.Error(p.c.GetEntryPoint(), "() returned false")
+		return
+	}
+
+	result.success++
+	result.latency.AddFloat64(latency.Seconds() / p.opts.LatencyUnit.Seconds())
+}
+
+// Start starts and runs the probe indefinitely.
+func (p *Probe) Start(ctx context.Context, dataChan chan *metrics.EventMetrics) {
+	s := &sched.Scheduler{
+		ProbeName:         p.name,
+		DataChan:          dataChan,
+		Opts:              p.opts,
+		RunProbeForTarget: p.runProbe,
+	}
+	s.UpdateTargetsAndStartProbes(ctx)
+}
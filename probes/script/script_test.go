@@ -0,0 +1,259 @@
+// Copyright 2025 The Cloudprober Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package script
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/cloudprober/cloudprober/metrics"
+	"github.com/cloudprober/cloudprober/probes/common/sched"
+	"github.com/cloudprober/cloudprober/probes/options"
+	configpb "github.com/cloudprober/cloudprober/probes/script/proto"
+	"github.com/cloudprober/cloudprober/targets/endpoint"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/proto"
+)
+
+func testProbe(t *testing.T, c *configpb.ProbeConf) (*Probe, error) {
+	t.Helper()
+
+	opts := options.DefaultOptions()
+	opts.ProbeConf = c
+	p := &Probe{}
+	return p, p.Init("test-probe", opts)
+}
+
+func TestInit(t *testing.T) {
+	scriptFile := filepath.Join(t.TempDir(), "probe.star")
+	if err := os.WriteFile(scriptFile, []byte("def probe(target):\n    pass\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		conf    *configpb.ProbeConf
+		wantErr bool
+	}{
+		{
+			name: "inline",
+			conf: &configpb.ProbeConf{
+				Source: &configpb.ProbeConf_Script{Script: "def probe(target):\n    pass\n"},
+			},
+		},
+		{
+			name: "file",
+			conf: &configpb.ProbeConf{
+				Source: &configpb.ProbeConf_ScriptFile{ScriptFile: scriptFile},
+			},
+		},
+		{
+			name: "custom_entry_point",
+			conf: &configpb.ProbeConf{
+				Source:     &configpb.ProbeConf_Script{Script: "def check(target):\n    pass\n"},
+				EntryPoint: proto.String("check"),
+			},
+		},
+		{
+			name:    "no_script",
+			conf:    &configpb.ProbeConf{},
+			wantErr: true,
+		},
+		{
+			name: "missing_file",
+			conf: &configpb.ProbeConf{
+				Source: &configpb.ProbeConf_ScriptFile{ScriptFile: filepath.Join(t.TempDir(), "missing.star")},
+			},
+			wantErr: true,
+		},
+		{
+			name: "no_entry_point",
+			conf: &configpb.ProbeConf{
+				Source: &configpb.ProbeConf_Script{Script: "def check(target):\n    pass\n"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "syntax_error",
+			conf: &configpb.ProbeConf{
+				Source: &configpb.ProbeConf_Script{Script: "def probe(target)\n    pass\n"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "undefined_name",
+			conf: &configpb.ProbeConf{
+				Source: &configpb.ProbeConf_Script{Script: "def probe(target):\n    undefined_func()\n"},
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := testProbe(t, tt.conf)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Init() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRunProbe(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/token":
+			w.Header().Set("X-Test", "test-value")
+			fmt.Fprint(w, `{"token": "secret"}`)
+		case "/data":
+			if r.Header.Get("Authorization") != "Bearer secret" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			fmt.Fprint(w, "hello")
+		}
+	}))
+	defer ts.Close()
+
+	host, portStr, _ := net.SplitHostPort(ts.Listener.Addr().String())
+	port, _ := strconv.Atoi(portStr)
+
+	tests := []struct {
+		name        string
+		script      string
+		timeout     time.Duration
+		wantSuccess int64
+		wantMetrics map[string]float64
+	}{
+		{
+			name: "multi_step_http",
+			script: `
+def probe(target):
+    base = "http://%s:%d" % (target.name, target.port)
+    resp = http_request(base + "/token")
+    if resp.headers["X-Test"] != "test-value":
+        fail("unexpected header: %s" % resp.headers)
+    token = json.decode(resp.body)["token"]
+    resp = http_request(base + "/data", headers={"Authorization": "Bearer " + token})
+    metric("data_size", len(resp.body))
+    metric("env_prod", 1 if target.labels["env"] == "prod" else 0)
+    return resp.status_code == 200
+`,
+			wantSuccess: 1,
+			wantMetrics: map[string]float64{"data_size": 5, "env_prod": 1},
+		},
+		{
+			name: "tcp_connect",
+			script: `
+def probe(target):
+    tcp_connect("%s:%d" % (target.name, target.port))
+`,
+			wantSuccess: 1,
+		},
+		{
+			name: "returns_false",
+			script: `
+def probe(target):
+    resp = http_request("http://%s:%d/data" % (target.name, target.port))
+    return resp.status_code == 200
+`,
+		},
+		{
+			name: "fail",
+			script: `
+def probe(target):
+    metric("before_fail", 1)
+    fail("failed")
+`,
+			wantMetrics: map[string]float64{"before_fail": 1},
+		},
+		{
+			name: "timeout",
+			script: `
+def probe(target):
+    for i in range(1000000000):
+        pass
+`,
+			timeout: 100 * time.Millisecond,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := testProbe(t, &configpb.ProbeConf{
+				Source: &configpb.ProbeConf_Script{Script: tt.script},
+			})
+			if err != nil {
+				t.Fatalf("Error initializing probe: %v", err)
+			}
+
+			ctx := context.Background()
+			if tt.timeout != 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, tt.timeout)
+				defer cancel()
+			}
+
+			runReq := &sched.RunProbeForTargetRequest{
+				Target: endpoint.Endpoint{
+					Name:   host,
+					Port:   port,
+					Labels: map[string]string{"env": "prod"},
+				},
+			}
+			p.runProbe(ctx, runReq)
+
+			result := runReq.Result.(*probeResult)
+			assert.Equal(t, int64(1), result.total, "total")
+			assert.Equal(t, tt.wantSuccess, result.success, "success")
+			if tt.wantMetrics == nil {
+				tt.wantMetrics = map[string]float64{}
+			}
+			assert.Equal(t, tt.wantMetrics, result.customMetrics, "custom metrics")
+		})
+	}
+}
+
+func TestMetrics(t *testing.T) {
+	p, err := testProbe(t, &configpb.ProbeConf{
+		Source: &configpb.ProbeConf_Script{Script: "def probe(target):\n    metric(\"b\", 2)\n    metric(\"a\", 1.5)\n"},
+	})
+	if err != nil {
+		t.Fatalf("Error initializing probe: %v", err)
+	}
+
+	runReq := &sched.RunProbeForTargetRequest{Target: endpoint.Endpoint{Name: "test-target"}}
+	p.runProbe(context.Background(), runReq)
+
+	ems := runReq.Result.Metrics(time.Now(), 0, p.opts)
+	assert.Len(t, ems, 2)
+
+	assert.Equal(t, metrics.Kind(metrics.CUMULATIVE), ems[0].Kind)
+	assert.Equal(t, "1", ems[0].Metric("total").String())
+	assert.Equal(t, "1", ems[0].Metric("success").String())
+
+	assert.Equal(t, metrics.Kind(metrics.GAUGE), ems[1].Kind)
+	assert.Equal(t, []string{"a", "b"}, ems[1].MetricsKeys())
+	assert.Equal(t, "1.500", ems[1].Metric("a").String())
+	assert.Equal(t, "script", ems[1].Label("ptype"))
+}
@@ -0,0 +1,107 @@
+// Copyright 2025 The Cloudprober Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tcp
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+
+	"github.com/cloudprober/cloudprober/internal/validators"
+)
+
+// expectResponse returns true if we need to read the response from the
+// target.
+func (p *Probe) expectResponse() bool {
+	return p.c.GetExpectPrefix() != "" || p.expectRegex != nil
+}
+
+// responseMatches returns whether the response matches the expectations, and
+// whether it's final, i.e. reading more data won't change the result.
+func (p *Probe) responseMatches(resp []byte) (matched, final bool) {
+	if prefix := []byte(p.c.GetExpectPrefix()); len(prefix) > 0 {
+		if len(resp) < len(prefix) {
+			return false, !bytes.HasPrefix(prefix, resp)
+		}
+		if !bytes.HasPrefix(resp, prefix) {
+			return false, true
+		}
+	}
+	if p.expectRegex != nil && !p.expectRegex.Match(resp) {
+		return false, false
+	}
+	return true, true
+}
+
+// readResponse reads the response until it matches the expectations, or
+// there is nothing more to read.
+func (p *Probe) readResponse(conn net.Conn) ([]byte, bool, error) {
+	maxLen := int(p.c.GetMaxResponseBytes())
+	resp := make([]byte, 0, min(maxLen, 512))
+	buf := make([]byte, 512)
+
+	for len(resp) < maxLen {
+		n, err := conn.Read(buf[:min(len(buf), maxLen-len(resp))])
+		resp = append(resp, buf[:n]...)
+		if matched, final := p.responseMatches(resp); final {
+			return resp, matched, nil
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return resp, false, err
+		}
+	}
+	matched, _ := p.responseMatches(resp)
+	return resp, matched, nil
+}
+
+// exchange sends the configured data to the target, and verifies the
+// response.
+func (p *Probe) exchange(ctx context.Context, conn net.Conn, result *probeResult) error {
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	if send := p.c.GetSend(); send != "" {
+		if _, err := io.WriteString(conn, send); err != nil {
+			return fmt.Errorf("error sending data: %v", err)
+		}
+	}
+
+	if !p.expectResponse() {
+		return nil
+	}
+
+	resp, matched, err := p.readResponse(conn)
+	if err != nil {
+		return fmt.Errorf("error reading response (read so far: %q): %v", resp, err)
+	}
+	if !matched {
+		return fmt.Errorf("response (%q) doesn't match the expectations", resp)
+	}
+
+	if p.opts.Validators != nil {
+		failedValidations := validators.RunValidators(p.opts.Validators, &validators.Input{ResponseBody: resp}, result.validationFailure, p.l)
+		if len(failedValidations) > 0 {
+			return fmt.Errorf("failed validations: %s", strings.Join(failedValidations, ","))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,149 @@
+// Copyright 2025 The Cloudprober Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tcp
+
+import (
+	"context"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/cloudprober/cloudprober/probes/options"
+	configpb "github.com/cloudprober/cloudprober/probes/tcp/proto"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestExchange(t *testing.T) {
+	tests := []struct {
+		desc     string
+		c        *configpb.ProbeConf
+		resp     string
+		keepOpen bool
+		wantErr  bool
+	}{
+		{
+			desc: "redis_ping",
+			c: &configpb.ProbeConf{
+				Send:         proto.String("PING\r\n"),
+				ExpectPrefix: proto.String("+PONG"),
+			},
+			resp:     "+PONG\r\n",
+			keepOpen: true,
+		},
+		{
+			desc: "smtp_banner",
+			c: &configpb.ProbeConf{
+				ExpectRegex: proto.String(`^220 \S+ ESMTP`),
+			},
+			resp:     "220 mail.example.com ESMTP ready\r\n",
+			keepOpen: true,
+		},
+		{
+			desc: "prefix_mismatch",
+			c: &configpb.ProbeConf{
+				Send:         proto.String("PING\r\n"),
+				ExpectPrefix: proto.String("+PONG"),
+			},
+			resp:     "-ERR unknown command\r\n",
+			keepOpen: true,
+			wantErr:  true,
+		},
+		{
+			desc: "regex_mismatch_conn_closed",
+			c: &configpb.ProbeConf{
+				ExpectRegex: proto.String(`ESMTP`),
+			},
+			resp:    "554 no service\r\n",
+			wantErr: true,
+		},
+		{
+			desc: "regex_mismatch_timeout",
+			c: &configpb.ProbeConf{
+				ExpectRegex: proto.String(`ESMTP`),
+			},
+			resp:     "220 ",
+			keepOpen: true,
+			wantErr:  true,
+		},
+		{
+			desc: "max_response_bytes",
+			c: &configpb.ProbeConf{
+				ExpectRegex:      proto.String(`ESMTP`),
+				MaxResponseBytes: proto.Int32(8),
+			},
+			resp:     "220 mail.example.com ESMTP\r\n",
+			keepOpen: true,
+			wantErr:  true,
+		},
+		{
+			desc: "send_only",
+			c: &configpb.ProbeConf{
+				Send: proto.String("QUIT\r\n"),
+			},
+			keepOpen: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			opts := options.DefaultOptions()
+			opts.ProbeConf = test.c
+			p := &Probe{}
+			assert.NoError(t, p.Init("test-probe", opts))
+
+			client, server := net.Pipe()
+			defer client.Close()
+
+			go func() {
+				defer server.Close()
+				if send := test.c.GetSend(); send != "" {
+					b := make([]byte, len(send))
+					if _, err := io.ReadFull(server, b); err != nil {
+						return
+					}
+					assert.Equal(t, send, string(b))
+				}
+				if test.resp != "" {
+					server.Write([]byte(test.resp))
+				}
+				if test.keepOpen {
+					// Wait for the client to close the connection.
+					io.Copy(io.Discard, server)
+				}
+			}()
+
+			ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+			defer cancel()
+
+			err := p.exchange(ctx, client, p.newResult().(*probeResult))
+			if test.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestInitExpectRegex(t *testing.T) {
+	opts := options.DefaultOptions()
+	opts.ProbeConf = &configpb.ProbeConf{ExpectRegex: proto.String("(")}
+	assert.Error(t, (&Probe{}).Init("test-probe", opts))
+
+	opts.ProbeConf = &configpb.ProbeConf{MaxResponseBytes: proto.Int32(0)}
+	assert.Error(t, (&Probe{}).Init("test-probe", opts))
+}
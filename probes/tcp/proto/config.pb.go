@@ -22,15 +22,24 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
-// Next tag: 4
+// Next tag: 11
 type ProbeConf struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
 	// Port for TCP requests. If not specfied, and port is provided by the
 	// targets (e.g. kubernetes endpoint or service), that port is used.
 	Port *int32 `protobuf:"varint,1,opt,name=port" json:"port,omitempty"`
+	// Target label to read the port from, e.g. "port". If set, and a target has
+	// this label with a valid port number, that port is used for the target,
+	// overriding both the port field above and the target's own port. Targets
+	// without this label use the port as determined above.
+	PortLabel *string `protobuf:"bytes,6,opt,name=port_label,json=portLabel" json:"port_label,omitempty"`
 	// Whether to perform a TLS handshake after TCP connection is established.
-	// When TLS handshake is enabled, we export two additional metrics:
-	// - connect_latency and tls_handshake_latency.
+	// When TLS handshake is enabled, we export these additional metrics:
+	//   - connect_latency and tls_handshake_latency.
+	//   - tls_version and tls_cipher: negotiated TLS versions and cipher suites,
+	//     as maps keyed by "version" and "cipher" respectively.
+	//   - ssl_earliest_cert_expiry_sec and ssl_cert_expiry_sec: time remaining
+	//     before the certificates' expiry (same as the HTTP probe).
 	TlsHandshake *bool `protobuf:"varint,2,opt,name=tls_handshake,json=tlsHandshake,def=0" json:"tls_handshake,omitempty"`
 	// TLS configuration for TLS handshake.
 	TlsConfig *proto.TLSConfig `protobuf:"bytes,3,opt,name=tls_config,json=tlsConfig" json:"tls_config,omitempty"`
@@ -42,14 +51,28 @@ type ProbeConf struct {
 	ResolveFirst *bool `protobuf:"varint,4,opt,name=resolve_first,json=resolveFirst" json:"resolve_first,omitempty"`
 	// Interval between targets.
 	IntervalBetweenTargetsMsec *int32 `protobuf:"varint,5,opt,name=interval_between_targets_msec,json=intervalBetweenTargetsMsec,def=10" json:"interval_between_targets_msec,omitempty"`
-	unknownFields              protoimpl.UnknownFields
-	sizeCache                  protoimpl.SizeCache
+	// Data to send to the target after the connection is established (and
+	// after the TLS handshake, if enabled), e.g. "PING\r\n" for Redis.
+	Send *string `protobuf:"bytes,7,opt,name=send" json:"send,omitempty"`
+	// Expected prefix of the response, e.g. "+PONG" for Redis or "220 " for an
+	// SMTP server's banner.
+	ExpectPrefix *string `protobuf:"bytes,8,opt,name=expect_prefix,json=expectPrefix" json:"expect_prefix,omitempty"`
+	// Regex that the response should match.
+	ExpectRegex *string `protobuf:"bytes,9,opt,name=expect_regex,json=expectRegex" json:"expect_regex,omitempty"`
+	// If expect_prefix or expect_regex is set, probe reads the response until
+	// it matches, the connection is closed, max_response_bytes are read, or the
+	// probe times out, and fails if the response doesn't match. Configured
+	// validators are also run on the response.
+	MaxResponseBytes *int32 `protobuf:"varint,10,opt,name=max_response_bytes,json=maxResponseBytes,def=4096" json:"max_response_bytes,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
 }
 
 // Default values for ProbeConf fields.
 const (
 	Default_ProbeConf_TlsHandshake               = bool(false)
 	Default_ProbeConf_IntervalBetweenTargetsMsec = int32(10)
+	Default_ProbeConf_MaxResponseBytes           = int32(4096)
 )
 
 func (x *ProbeConf) Reset() {
@@ -89,6 +112,13 @@ func (x *ProbeConf) GetPort() int32 {
 	return 0
 }
 
+func (x *ProbeConf) GetPortLabel() string {
+	if x != nil && x.PortLabel != nil {
+		return *x.PortLabel
+	}
+	return ""
+}
+
 func (x *ProbeConf) GetTlsHandshake() bool {
 	if x != nil && x.TlsHandshake != nil {
 		return *x.TlsHandshake
@@ -117,18 +147,53 @@ func (x *ProbeConf) GetIntervalBetweenTargetsMsec() int32 {
 	return Default_ProbeConf_IntervalBetweenTargetsMsec
 }
 
+func (x *ProbeConf) GetSend() string {
+	if x != nil && x.Send != nil {
+		return *x.Send
+	}
+	return ""
+}
+
+func (x *ProbeConf) GetExpectPrefix() string {
+	if x != nil && x.ExpectPrefix != nil {
+		return *x.ExpectPrefix
+	}
+	return ""
+}
+
+func (x *ProbeConf) GetExpectRegex() string {
+	if x != nil && x.ExpectRegex != nil {
+		return *x.ExpectRegex
+	}
+	return ""
+}
+
+func (x *ProbeConf) GetMaxResponseBytes() int32 {
+	if x != nil && x.MaxResponseBytes != nil {
+		return *x.MaxResponseBytes
+	}
+	return Default_ProbeConf_MaxResponseBytes
+}
+
 var File_github_com_cloudprober_cloudprober_probes_tcp_proto_config_proto protoreflect.FileDescriptor
 
 const file_github_com_cloudprober_cloudprober_probes_tcp_proto_config_proto_rawDesc = "" +
 	"\n" +
-	"@github.com/cloudprober/cloudprober/probes/tcp/proto/config.proto\x12\x16cloudprober.probes.tcp\x1aFgithub.com/cloudprober/cloudprober/common/tlsconfig/proto/config.proto\"\xf8\x01\n" +
+	"@github.com/cloudprober/cloudprober/probes/tcp/proto/config.proto\x12\x16cloudprober.probes.tcp\x1aFgithub.com/cloudprober/cloudprober/common/tlsconfig/proto/config.proto\"\xa7\x03\n" +
 	"\tProbeConf\x12\x12\n" +
-	"\x04port\x18\x01 \x01(\x05R\x04port\x12*\n" +
+	"\x04port\x18\x01 \x01(\x05R\x04port\x12\x1d\n" +
+	"\n" +
+	"port_label\x18\x06 \x01(\tR\tportLabel\x12*\n" +
 	"\rtls_handshake\x18\x02 \x01(\b:\x05falseR\ftlsHandshake\x12?\n" +
 	"\n" +
 	"tls_config\x18\x03 \x01(\v2 .cloudprober.tlsconfig.TLSConfigR\ttlsConfig\x12#\n" +
 	"\rresolve_first\x18\x04 \x01(\bR\fresolveFirst\x12E\n" +
-	"\x1dinterval_between_targets_msec\x18\x05 \x01(\x05:\x0210R\x1aintervalBetweenTargetsMsecB5Z3github.com/cloudprober/cloudprober/probes/tcp/proto"
+	"\x1dinterval_between_targets_msec\x18\x05 \x01(\x05:\x0210R\x1aintervalBetweenTargetsMsec\x12\x12\n" +
+	"\x04send\x18\a \x01(\tR\x04send\x12#\n" +
+	"\rexpect_prefix\x18\b \x01(\tR\fexpectPrefix\x12!\n" +
+	"\fexpect_regex\x18\t \x01(\tR\vexpectRegex\x122\n" +
+	"\x12max_response_bytes\x18\n" +
+	" \x01(\x05:\x044096R\x10maxResponseBytesB5Z3github.com/cloudprober/cloudprober/probes/tcp/proto"
 
 var (
 	file_github_com_cloudprober_cloudprober_probes_tcp_proto_config_proto_rawDescOnce sync.Once
@@ -18,12 +18,15 @@ package tcp
 import (
 	"context"
 	"crypto/tls"
+	"crypto/x509"
 	"fmt"
 	"log/slog"
 	"net"
+	"regexp"
 	"strconv"
 	"time"
 
+	"github.com/cloudprober/cloudprober/common/iputils"
 	"github.com/cloudprober/cloudprober/common/tlsconfig"
 	"github.com/cloudprober/cloudprober/internal/validators"
 	"github.com/cloudprober/cloudprober/logger"
@@ -31,6 +34,7 @@ import (
 	"github.com/cloudprober/cloudprober/probes/common/sched"
 	"github.com/cloudprober/cloudprober/probes/options"
 	configpb "github.com/cloudprober/cloudprober/probes/tcp/proto"
+	"github.com/cloudprober/cloudprober/targets/endpoint"
 	"google.golang.org/protobuf/proto"
 )
 
@@ -45,19 +49,31 @@ type Probe struct {
 	network          string
 	tlsConfig        *tls.Config
 	dialContext      func(context.Context, string, string) (net.Conn, error) // Keeps some dialing related config
-	handshakeContext func(context.Context, net.Conn, *tls.Config) error
+	handshakeContext func(context.Context, net.Conn, *tls.Config) (net.Conn, tls.ConnectionState, error)
+	expectRegex      *regexp.Regexp
 }
 
 type probeResult struct {
 	total, success      int64
+	successFirstAttempt int64
+	retries             int64
 	latency             metrics.LatencyValue
 	connLatency         metrics.LatencyValue
 	tlsHandshakeLatency metrics.LatencyValue
 	validationFailure   *metrics.Map[int64]
+
+	// Negotiated TLS versions and cipher suites.
+	tlsVersion, tlsCipher *metrics.Map[int64]
+
+	// Certificate expiry from the last successful TLS handshake.
+	sslEarliestExpirationSeconds int64
+	sslLeafCert                  *x509.Certificate
 }
 
 func (p *Probe) newResult() sched.ProbeResult {
-	result := &probeResult{}
+	result := &probeResult{
+		sslEarliestExpirationSeconds: -1,
+	}
 
 	if p.opts.Validators != nil {
 		result.validationFailure = validators.ValidationFailureMap(p.opts.Validators)
@@ -77,11 +93,41 @@ func (p *Probe) newResult() sched.ProbeResult {
 			result.connLatency = metrics.NewFloat(0)
 			result.tlsHandshakeLatency = metrics.NewFloat(0)
 		}
+		result.tlsVersion = metrics.NewMap("version")
+		result.tlsCipher = metrics.NewMap("cipher")
 	}
 
 	return result
 }
 
+// recordTLSState records the negotiated TLS parameters, and the expiry of the
+// certificates presented by the server.
+func (result *probeResult) recordTLSState(state tls.ConnectionState, now time.Time) {
+	if result.tlsVersion != nil {
+		result.tlsVersion.IncKey(tls.VersionName(state.Version))
+	}
+	if result.tlsCipher != nil {
+		result.tlsCipher.IncKey(tls.CipherSuiteName(state.CipherSuite))
+	}
+
+	if len(state.PeerCertificates) == 0 {
+		return
+	}
+	minExpiry := state.PeerCertificates[0].NotAfter
+	for _, cert := range state.PeerCertificates[1:] {
+		if cert.NotAfter.Before(minExpiry) {
+			minExpiry = cert.NotAfter
+		}
+	}
+	result.sslEarliestExpirationSeconds = int64(minExpiry.Sub(now).Seconds())
+	result.sslLeafCert = state.PeerCertificates[0]
+}
+
+// SuccessCount returns the number of successful probe runs so far.
+func (result *probeResult) SuccessCount() int64 {
+	return result.success
+}
+
 func (result *probeResult) Metrics(ts time.Time, _ int64, opts *options.Options) []*metrics.EventMetrics {
 	em := metrics.NewEventMetrics(ts).
 		AddMetric("total", metrics.NewInt(result.total)).
@@ -95,11 +141,45 @@ func (result *probeResult) Metrics(ts time.Time, _ int64, opts *options.Options)
 		em.AddMetric("tls_handshake_latency", result.tlsHandshakeLatency.Clone())
 	}
 
+	if result.tlsVersion != nil {
+		em.AddMetric("tls_version", result.tlsVersion.Clone())
+		em.AddMetric("tls_cipher", result.tlsCipher.Clone())
+	}
+
 	if result.validationFailure != nil {
 		em.AddMetric("validation_failure", result.validationFailure)
 	}
 
-	return []*metrics.EventMetrics{em}
+	if opts.RetryPolicy != nil {
+		em.AddMetric("success_first_attempt", metrics.NewInt(result.successFirstAttempt))
+		em.AddMetric("retries", metrics.NewInt(result.retries))
+	}
+
+	ems := []*metrics.EventMetrics{em}
+
+	// SSL certificate expiry metrics are exported in independent EMs as they
+	// are GAUGE metrics. These are the same as the HTTP probe's.
+	if result.sslEarliestExpirationSeconds >= 0 {
+		em := metrics.NewEventMetrics(ts).
+			AddMetric("ssl_earliest_cert_expiry_sec", metrics.NewInt(result.sslEarliestExpirationSeconds))
+		em.Kind = metrics.GAUGE
+		em.SetNotForAlerting()
+		em.AddLabel("ptype", "tcp")
+		ems = append(ems, em)
+	}
+
+	if cert := result.sslLeafCert; cert != nil {
+		em := metrics.NewEventMetrics(ts).
+			AddMetric("ssl_cert_expiry_sec", metrics.NewInt(int64(cert.NotAfter.Sub(ts).Seconds())))
+		em.Kind = metrics.GAUGE
+		em.SetNotForAlerting()
+		em.AddLabel("ptype", "tcp")
+		em.AddLabel("issuer", cert.Issuer.CommonName)
+		em.AddLabel("serial", cert.SerialNumber.Text(16))
+		ems = append(ems, em)
+	}
+
+	return ems
 }
 
 // Init initializes the probe with the given params.
@@ -130,7 +210,7 @@ func (p *Probe) Init(name string, opts *options.Options) error {
 
 	// Create a dialer for our use.
 	dialer := &net.Dialer{
-		Timeout:   p.opts.Timeout,
+		Timeout:   p.opts.MaxTimeout(),
 		KeepAlive: 30 * time.Second, // TCP keep-alive
 	}
 	if p.opts.SourceIP != nil {
@@ -138,6 +218,9 @@ func (p *Probe) Init(name string, opts *options.Options) error {
 			IP: p.opts.SourceIP,
 		}
 	}
+	if p.opts.BindInterface != "" {
+		dialer.Control = iputils.BindToDeviceControl(p.opts.BindInterface)
+	}
 	p.dialContext = dialer.DialContext
 
 	if p.c.GetTlsConfig() != nil {
@@ -156,6 +239,17 @@ func (p *Probe) Init(name string, opts *options.Options) error {
 		}
 	}
 
+	if p.c.GetExpectRegex() != "" {
+		re, err := regexp.Compile(p.c.GetExpectRegex())
+		if err != nil {
+			return fmt.Errorf("invalid expect_regex (%s): %v", p.c.GetExpectRegex(), err)
+		}
+		p.expectRegex = re
+	}
+	if p.c.GetMaxResponseBytes() <= 0 {
+		return fmt.Errorf("max_response_bytes (%d) should be positive", p.c.GetMaxResponseBytes())
+	}
+
 	return nil
 }
 
@@ -165,6 +259,11 @@ func (p *Probe) connectAndHandshake(ctx context.Context, addr, targetName string
 	if err != nil {
 		return err
 	}
+	defer func() {
+		if conn != nil {
+			conn.Close()
+		}
+	}()
 
 	if p.c.GetTlsHandshake() {
 		result.connLatency.AddFloat64(time.Since(start).Seconds() / p.opts.LatencyUnit.Seconds())
@@ -179,22 +278,43 @@ func (p *Probe) connectAndHandshake(ctx context.Context, addr, targetName string
 		}
 
 		if p.handshakeContext == nil {
-			p.handshakeContext = func(ctx context.Context, nc net.Conn, tlsConfig *tls.Config) error {
-				return tls.Client(nc, tlsConfig).HandshakeContext(ctx)
+			p.handshakeContext = func(ctx context.Context, nc net.Conn, tlsConfig *tls.Config) (net.Conn, tls.ConnectionState, error) {
+				tlsConn := tls.Client(nc, tlsConfig)
+				err := tlsConn.HandshakeContext(ctx)
+				return tlsConn, tlsConn.ConnectionState(), err
 			}
 		}
-		if err := p.handshakeContext(ctx, conn, p.tlsConfig); err != nil {
+		tlsConn, state, err := p.handshakeContext(ctx, conn, p.tlsConfig)
+		if err != nil {
 			return err
 		}
+		// Use the TLS connection for the rest of the exchange.
+		conn = tlsConn
 		result.tlsHandshakeLatency.AddFloat64(time.Since(start).Seconds() / p.opts.LatencyUnit.Seconds())
+		result.recordTLSState(state, time.Now())
 	}
 
-	if conn != nil {
-		conn.Close()
+	if p.c.GetSend() != "" || p.expectResponse() {
+		return p.exchange(ctx, conn, result)
 	}
 	return nil
 }
 
+// portForTarget returns the port to use for the target. Port from the
+// port_label target label takes precedence, followed by the configured port,
+// and then the target's own port.
+func (p *Probe) portForTarget(target endpoint.Endpoint) int {
+	if label := p.c.GetPortLabel(); label != "" {
+		if port, err := strconv.Atoi(target.Labels[label]); err == nil && port > 0 && port < 65536 {
+			return port
+		}
+	}
+	if port := int(p.c.GetPort()); port != 0 {
+		return port
+	}
+	return target.Port
+}
+
 func (p *Probe) runProbe(ctx context.Context, runReq *sched.RunProbeForTargetRequest) {
 	if runReq.Result == nil {
 		runReq.Result = p.newResult()
@@ -224,19 +344,22 @@ func (p *Probe) runProbe(ctx context.Context, runReq *sched.RunProbeForTargetReq
 		ipLabel = host
 	}
 
+	port := p.portForTarget(target)
+
 	for _, al := range p.opts.AdditionalLabels {
-		al.UpdateForTarget(target, ipLabel, int(p.c.GetPort()))
+		al.UpdateForTarget(target, ipLabel, port)
 	}
 
-	port := int(p.c.GetPort())
-	if port == 0 {
-		port = target.Port
-	}
 	addr := net.JoinHostPort(host, strconv.Itoa(port))
 
-	start := time.Now()
-	err := p.connectAndHandshake(ctx, addr, target.Name, result)
-	latency := time.Since(start)
+	var latency time.Duration
+	attempts, err := p.opts.RetryPolicy.Do(ctx, p.opts.TimeoutForTarget(target), func(ctx context.Context) error {
+		start := time.Now()
+		err := p.connectAndHandshake(ctx, addr, target.Name, result)
+		latency = time.Since(start)
+		return err
+	})
+	result.retries += int64(attempts - 1)
 
 	if p.opts.NegativeTest {
 		if err == nil {
@@ -251,6 +374,9 @@ func (p *Probe) runProbe(ctx context.Context, runReq *sched.RunProbeForTargetReq
 		l.Error(err.Error())
 		return
 	}
+	if attempts == 1 {
+		result.successFirstAttempt++
+	}
 	result.success++
 	result.latency.AddFloat64(latency.Seconds() / p.opts.LatencyUnit.Seconds())
 }
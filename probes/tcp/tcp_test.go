@@ -17,7 +17,10 @@ package tcp
 import (
 	"context"
 	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
 	"fmt"
+	"math/big"
 	"net"
 	"strconv"
 	"strings"
@@ -27,6 +30,7 @@ import (
 	"github.com/cloudprober/cloudprober/metrics"
 	"github.com/cloudprober/cloudprober/probes/common/sched"
 	"github.com/cloudprober/cloudprober/probes/options"
+	probeconfigpb "github.com/cloudprober/cloudprober/probes/proto"
 	configpb "github.com/cloudprober/cloudprober/probes/tcp/proto"
 	"github.com/cloudprober/cloudprober/targets/endpoint"
 	"github.com/stretchr/testify/assert"
@@ -122,6 +126,64 @@ func TestRunProbe(t *testing.T) {
 
 }
 
+func TestRunProbeWithRetries(t *testing.T) {
+	tests := []struct {
+		desc             string
+		dialFailures     int
+		wantSuccess      int64
+		wantFirstAttempt int64
+		wantRetries      int64
+	}{
+		{
+			desc:             "success-first-attempt",
+			wantSuccess:      1,
+			wantFirstAttempt: 1,
+		},
+		{
+			desc:         "success-after-retry",
+			dialFailures: 1,
+			wantSuccess:  1,
+			wantRetries:  1,
+		},
+		{
+			desc:         "fail-after-retries",
+			dialFailures: 3,
+			wantRetries:  2,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			p := &Probe{}
+			opts := options.DefaultOptions()
+			rp, err := options.NewRetryPolicy(&probeconfigpb.RetryPolicy{MaxRetries: proto.Int32(2), Backoff: proto.String("1ms")})
+			assert.NoError(t, err)
+			opts.RetryPolicy = rp
+			assert.NoError(t, p.Init("test-probe", opts))
+
+			var dialCnt int
+			p.dialContext = func(ctx context.Context, network, addr string) (net.Conn, error) {
+				dialCnt++
+				if dialCnt <= test.dialFailures {
+					return nil, fmt.Errorf("connection refused")
+				}
+				return nil, nil
+			}
+
+			runReq := &sched.RunProbeForTargetRequest{Target: endpoint.Endpoint{Name: "test.com", Port: 80}}
+			p.runProbe(context.Background(), runReq)
+
+			result := runReq.Result.(*probeResult)
+			assert.Equal(t, int64(1), result.total, "total")
+			assert.Equal(t, test.wantSuccess, result.success, "success")
+			assert.Equal(t, test.wantFirstAttempt, result.successFirstAttempt, "success_first_attempt")
+			assert.Equal(t, test.wantRetries, result.retries, "retries")
+
+			em := result.Metrics(time.Now(), 0, opts)[0]
+			assert.Equal(t, test.wantFirstAttempt, em.Metric("success_first_attempt").(*metrics.Int).Int64())
+		})
+	}
+}
+
 func TestConnectAndHandshake(t *testing.T) {
 	tests := []struct {
 		desc                   string
@@ -188,13 +250,13 @@ func TestConnectAndHandshake(t *testing.T) {
 				return nil, test.dialError
 			}
 
-			p.handshakeContext = func(ctx context.Context, _ net.Conn, tlsConfig *tls.Config) error {
+			p.handshakeContext = func(ctx context.Context, nc net.Conn, tlsConfig *tls.Config) (net.Conn, tls.ConnectionState, error) {
 				if tlsConfig.ServerName == "error.com" {
-					return fmt.Errorf("handshake error")
+					return nil, tls.ConnectionState{}, fmt.Errorf("handshake error")
 				}
 				assert.Equal(t, host, tlsConfig.ServerName)
 				time.Sleep(1 * time.Millisecond)
-				return nil
+				return nc, tls.ConnectionState{}, nil
 			}
 
 			result := &probeResult{
@@ -314,3 +376,93 @@ func TestNewResultAndMetrics(t *testing.T) {
 		})
 	}
 }
+
+func TestTLSStateMetrics(t *testing.T) {
+	p := &Probe{
+		opts: options.DefaultOptions(),
+		c:    &configpb.ProbeConf{TlsHandshake: proto.Bool(true)},
+	}
+	result := p.newResult().(*probeResult)
+
+	ts := time.Now()
+	leaf := &x509.Certificate{
+		NotAfter:     ts.Add(48 * time.Hour),
+		Issuer:       pkix.Name{CommonName: "Test CA"},
+		SerialNumber: big.NewInt(0xbeef),
+	}
+	intermediate := &x509.Certificate{NotAfter: ts.Add(24 * time.Hour)}
+	result.recordTLSState(tls.ConnectionState{
+		Version:          tls.VersionTLS13,
+		CipherSuite:      tls.TLS_AES_128_GCM_SHA256,
+		PeerCertificates: []*x509.Certificate{leaf, intermediate},
+	}, ts)
+	result.recordTLSState(tls.ConnectionState{
+		Version:     tls.VersionTLS12,
+		CipherSuite: tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256,
+	}, ts)
+
+	ems := result.Metrics(ts, 0, p.opts)
+	if !assert.Len(t, ems, 3) {
+		return
+	}
+
+	versions := ems[0].Metric("tls_version").(*metrics.Map[int64])
+	assert.Equal(t, int64(1), versions.GetKey("TLS 1.3"))
+	assert.Equal(t, int64(1), versions.GetKey("TLS 1.2"))
+	ciphers := ems[0].Metric("tls_cipher").(*metrics.Map[int64])
+	assert.Equal(t, int64(1), ciphers.GetKey("TLS_AES_128_GCM_SHA256"))
+	assert.Equal(t, "cipher", ciphers.MapName)
+
+	assert.Equal(t, int64(86400), ems[1].Metric("ssl_earliest_cert_expiry_sec").(*metrics.Int).Int64())
+	assert.Equal(t, metrics.Kind(metrics.GAUGE), ems[1].Kind)
+
+	assert.Equal(t, int64(172800), ems[2].Metric("ssl_cert_expiry_sec").(*metrics.Int).Int64())
+	assert.Equal(t, "Test CA", ems[2].Label("issuer"))
+	assert.Equal(t, "beef", ems[2].Label("serial"))
+}
+
+func TestPortForTarget(t *testing.T) {
+	tests := []struct {
+		desc     string
+		conf     *configpb.ProbeConf
+		target   endpoint.Endpoint
+		wantPort int
+	}{
+		{
+			desc:     "target-port",
+			conf:     &configpb.ProbeConf{},
+			target:   endpoint.Endpoint{Name: "t1", Port: 8080},
+			wantPort: 8080,
+		},
+		{
+			desc:     "configured-port",
+			conf:     &configpb.ProbeConf{Port: proto.Int32(9090)},
+			target:   endpoint.Endpoint{Name: "t1", Port: 8080},
+			wantPort: 9090,
+		},
+		{
+			desc:     "port-label",
+			conf:     &configpb.ProbeConf{Port: proto.Int32(9090), PortLabel: proto.String("port")},
+			target:   endpoint.Endpoint{Name: "t1", Port: 8080, Labels: map[string]string{"port": "7070"}},
+			wantPort: 7070,
+		},
+		{
+			desc:     "port-label-missing",
+			conf:     &configpb.ProbeConf{Port: proto.Int32(9090), PortLabel: proto.String("port")},
+			target:   endpoint.Endpoint{Name: "t1", Port: 8080},
+			wantPort: 9090,
+		},
+		{
+			desc:     "port-label-invalid",
+			conf:     &configpb.ProbeConf{PortLabel: proto.String("port")},
+			target:   endpoint.Endpoint{Name: "t1", Port: 8080, Labels: map[string]string{"port": "http"}},
+			wantPort: 8080,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			p := &Probe{c: test.conf}
+			assert.Equal(t, test.wantPort, p.portForTarget(test.target))
+		})
+	}
+}
@@ -0,0 +1,550 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.11
+// 	protoc        v5.27.5
+// source: github.com/cloudprober/cloudprober/probes/transaction/proto/config.proto
+
+package proto
+
+import (
+	proto "github.com/cloudprober/cloudprober/common/tlsconfig/proto"
+	proto1 "github.com/cloudprober/cloudprober/internal/validators/proto"
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type Step_Method int32
+
+const (
+	Step_GET     Step_Method = 0
+	Step_POST    Step_Method = 1
+	Step_PUT     Step_Method = 2
+	Step_HEAD    Step_Method = 3
+	Step_DELETE  Step_Method = 4
+	Step_PATCH   Step_Method = 5
+	Step_OPTIONS Step_Method = 6
+)
+
+// Enum value maps for Step_Method.
+var (
+	Step_Method_name = map[int32]string{
+		0: "GET",
+		1: "POST",
+		2: "PUT",
+		3: "HEAD",
+		4: "DELETE",
+		5: "PATCH",
+		6: "OPTIONS",
+	}
+	Step_Method_value = map[string]int32{
+		"GET":     0,
+		"POST":    1,
+		"PUT":     2,
+		"HEAD":    3,
+		"DELETE":  4,
+		"PATCH":   5,
+		"OPTIONS": 6,
+	}
+)
+
+func (x Step_Method) Enum() *Step_Method {
+	p := new(Step_Method)
+	*p = x
+	return p
+}
+
+func (x Step_Method) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (Step_Method) Descriptor() protoreflect.EnumDescriptor {
+	return file_github_com_cloudprober_cloudprober_probes_transaction_proto_config_proto_enumTypes[0].Descriptor()
+}
+
+func (Step_Method) Type() protoreflect.EnumType {
+	return &file_github_com_cloudprober_cloudprober_probes_transaction_proto_config_proto_enumTypes[0]
+}
+
+func (x Step_Method) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Do not use.
+func (x *Step_Method) UnmarshalJSON(b []byte) error {
+	num, err := protoimpl.X.UnmarshalJSONEnum(x.Descriptor(), b)
+	if err != nil {
+		return err
+	}
+	*x = Step_Method(num)
+	return nil
+}
+
+// Deprecated: Use Step_Method.Descriptor instead.
+func (Step_Method) EnumDescriptor() ([]byte, []int) {
+	return file_github_com_cloudprober_cloudprober_probes_transaction_proto_config_proto_rawDescGZIP(), []int{1, 0}
+}
+
+// Transaction probe executes an ordered sequence of HTTP requests (steps) for
+// each target, e.g. login -> get token -> call API -> logout. Probe run fails
+// as soon as any step fails.
+//
+// Step's url, header values and body can use the following variables:
+//
+//	@target@: target name.
+//	@port@: target port (if any).
+//	@target.label.<key>@: target's label value.
+//	@<name>@: value extracted by one of the previous steps (see Extract).
+//
+// Example:
+//
+//	step {
+//	  name: "login"
+//	  url: "https://@target@/login"
+//	  method: POST
+//	  body: "{\"user\": \"prober\"}"
+//	  extract {
+//	    name: "token"
+//	    jq_filter: ".token"
+//	  }
+//	}
+//	step {
+//	  name: "get_profile"
+//	  url: "https://@target@/api/profile"
+//	  header {
+//	    key: "Authorization"
+//	    value: "Bearer @token@"
+//	  }
+//	}
+type ProbeConf struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Step  []*Step                `protobuf:"bytes,1,rep,name=step" json:"step,omitempty"`
+	// Whether to keep cookies across the steps of a probe run. Each probe run
+	// starts with an empty cookie jar.
+	KeepCookies *bool `protobuf:"varint,2,opt,name=keep_cookies,json=keepCookies,def=1" json:"keep_cookies,omitempty"`
+	// TLS config for the HTTPS requests.
+	TlsConfig *proto.TLSConfig `protobuf:"bytes,3,opt,name=tls_config,json=tlsConfig" json:"tls_config,omitempty"`
+	// Latency budget for the whole transaction, e.g. "2s". A probe run that
+	// succeeds but goes over the budget (or over any of the step budgets) is
+	// counted as degraded, through the "degraded" metric. It doesn't affect
+	// the "success" metric.
+	LatencyBudget *string `protobuf:"bytes,4,opt,name=latency_budget,json=latencyBudget" json:"latency_budget,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+// Default values for ProbeConf fields.
+const (
+	Default_ProbeConf_KeepCookies = bool(true)
+)
+
+func (x *ProbeConf) Reset() {
+	*x = ProbeConf{}
+	mi := &file_github_com_cloudprober_cloudprober_probes_transaction_proto_config_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ProbeConf) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ProbeConf) ProtoMessage() {}
+
+func (x *ProbeConf) ProtoReflect() protoreflect.Message {
+	mi := &file_github_com_cloudprober_cloudprober_probes_transaction_proto_config_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ProbeConf.ProtoReflect.Descriptor instead.
+func (*ProbeConf) Descriptor() ([]byte, []int) {
+	return file_github_com_cloudprober_cloudprober_probes_transaction_proto_config_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *ProbeConf) GetStep() []*Step {
+	if x != nil {
+		return x.Step
+	}
+	return nil
+}
+
+func (x *ProbeConf) GetKeepCookies() bool {
+	if x != nil && x.KeepCookies != nil {
+		return *x.KeepCookies
+	}
+	return Default_ProbeConf_KeepCookies
+}
+
+func (x *ProbeConf) GetTlsConfig() *proto.TLSConfig {
+	if x != nil {
+		return x.TlsConfig
+	}
+	return nil
+}
+
+func (x *ProbeConf) GetLatencyBudget() string {
+	if x != nil && x.LatencyBudget != nil {
+		return *x.LatencyBudget
+	}
+	return ""
+}
+
+type Step struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Step name. It's used as the "step" label for per-step metrics.
+	Name *string `protobuf:"bytes,1,req,name=name" json:"name,omitempty"`
+	// Request URL.
+	Url    *string      `protobuf:"bytes,2,req,name=url" json:"url,omitempty"`
+	Method *Step_Method `protobuf:"varint,3,opt,name=method,enum=cloudprober.probes.transaction.Step_Method,def=0" json:"method,omitempty"`
+	// Request headers.
+	Header map[string]string `protobuf:"bytes,4,rep,name=header" json:"header,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	// Request body.
+	Body *string `protobuf:"bytes,5,opt,name=body" json:"body,omitempty"`
+	// Expected response status codes. If not specified, any 2xx status code is
+	// considered a success.
+	ExpectedStatusCode []int32 `protobuf:"varint,6,rep,name=expected_status_code,json=expectedStatusCode" json:"expected_status_code,omitempty"`
+	// Validators for the step's response.
+	Validator []*proto1.Validator `protobuf:"bytes,7,rep,name=validator" json:"validator,omitempty"`
+	// Values to extract from the response, for use in the subsequent steps.
+	// Step fails if a value cannot be extracted.
+	Extract []*Extract `protobuf:"bytes,8,rep,name=extract" json:"extract,omitempty"`
+	// Latency budget for the step, e.g. "500ms". Successful step runs that go
+	// over the budget are counted through the "step_over_budget" metric, and
+	// the probe run is counted as degraded. See ProbeConf.latency_budget.
+	LatencyBudget *string `protobuf:"bytes,9,opt,name=latency_budget,json=latencyBudget" json:"latency_budget,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+// Default values for Step fields.
+const (
+	Default_Step_Method = Step_GET
+)
+
+func (x *Step) Reset() {
+	*x = Step{}
+	mi := &file_github_com_cloudprober_cloudprober_probes_transaction_proto_config_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Step) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Step) ProtoMessage() {}
+
+func (x *Step) ProtoReflect() protoreflect.Message {
+	mi := &file_github_com_cloudprober_cloudprober_probes_transaction_proto_config_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Step.ProtoReflect.Descriptor instead.
+func (*Step) Descriptor() ([]byte, []int) {
+	return file_github_com_cloudprober_cloudprober_probes_transaction_proto_config_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Step) GetName() string {
+	if x != nil && x.Name != nil {
+		return *x.Name
+	}
+	return ""
+}
+
+func (x *Step) GetUrl() string {
+	if x != nil && x.Url != nil {
+		return *x.Url
+	}
+	return ""
+}
+
+func (x *Step) GetMethod() Step_Method {
+	if x != nil && x.Method != nil {
+		return *x.Method
+	}
+	return Default_Step_Method
+}
+
+func (x *Step) GetHeader() map[string]string {
+	if x != nil {
+		return x.Header
+	}
+	return nil
+}
+
+func (x *Step) GetBody() string {
+	if x != nil && x.Body != nil {
+		return *x.Body
+	}
+	return ""
+}
+
+func (x *Step) GetExpectedStatusCode() []int32 {
+	if x != nil {
+		return x.ExpectedStatusCode
+	}
+	return nil
+}
+
+func (x *Step) GetValidator() []*proto1.Validator {
+	if x != nil {
+		return x.Validator
+	}
+	return nil
+}
+
+func (x *Step) GetExtract() []*Extract {
+	if x != nil {
+		return x.Extract
+	}
+	return nil
+}
+
+func (x *Step) GetLatencyBudget() string {
+	if x != nil && x.LatencyBudget != nil {
+		return *x.LatencyBudget
+	}
+	return ""
+}
+
+type Extract struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Variable name. Extracted value can be referred to as @<name>@ in the
+	// subsequent steps.
+	Name *string `protobuf:"bytes,1,req,name=name" json:"name,omitempty"`
+	// Types that are valid to be assigned to Source:
+	//
+	//	*Extract_JqFilter
+	//	*Extract_Regex
+	//	*Extract_Header
+	Source        isExtract_Source `protobuf_oneof:"source"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Extract) Reset() {
+	*x = Extract{}
+	mi := &file_github_com_cloudprober_cloudprober_probes_transaction_proto_config_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Extract) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Extract) ProtoMessage() {}
+
+func (x *Extract) ProtoReflect() protoreflect.Message {
+	mi := &file_github_com_cloudprober_cloudprober_probes_transaction_proto_config_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Extract.ProtoReflect.Descriptor instead.
+func (*Extract) Descriptor() ([]byte, []int) {
+	return file_github_com_cloudprober_cloudprober_probes_transaction_proto_config_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *Extract) GetName() string {
+	if x != nil && x.Name != nil {
+		return *x.Name
+	}
+	return ""
+}
+
+func (x *Extract) GetSource() isExtract_Source {
+	if x != nil {
+		return x.Source
+	}
+	return nil
+}
+
+func (x *Extract) GetJqFilter() string {
+	if x != nil {
+		if x, ok := x.Source.(*Extract_JqFilter); ok {
+			return x.JqFilter
+		}
+	}
+	return ""
+}
+
+func (x *Extract) GetRegex() string {
+	if x != nil {
+		if x, ok := x.Source.(*Extract_Regex); ok {
+			return x.Regex
+		}
+	}
+	return ""
+}
+
+func (x *Extract) GetHeader() string {
+	if x != nil {
+		if x, ok := x.Source.(*Extract_Header); ok {
+			return x.Header
+		}
+	}
+	return ""
+}
+
+type isExtract_Source interface {
+	isExtract_Source()
+}
+
+type Extract_JqFilter struct {
+	// jq filter to run on the JSON response body, e.g. ".auth.token".
+	JqFilter string `protobuf:"bytes,2,opt,name=jq_filter,json=jqFilter,oneof"`
+}
+
+type Extract_Regex struct {
+	// Regex to match on the response body. If regex has a capturing group,
+	// first group's value is extracted, otherwise the whole match.
+	Regex string `protobuf:"bytes,3,opt,name=regex,oneof"`
+}
+
+type Extract_Header struct {
+	// Response header.
+	Header string `protobuf:"bytes,4,opt,name=header,oneof"`
+}
+
+func (*Extract_JqFilter) isExtract_Source() {}
+
+func (*Extract_Regex) isExtract_Source() {}
+
+func (*Extract_Header) isExtract_Source() {}
+
+var File_github_com_cloudprober_cloudprober_probes_transaction_proto_config_proto protoreflect.FileDescriptor
+
+const file_github_com_cloudprober_cloudprober_probes_transaction_proto_config_proto_rawDesc = "" +
+	"\n" +
+	"Hgithub.com/cloudprober/cloudprober/probes/transaction/proto/config.proto\x12\x1ecloudprober.probes.transaction\x1aFgithub.com/cloudprober/cloudprober/common/tlsconfig/proto/config.proto\x1aIgithub.com/cloudprober/cloudprober/internal/validators/proto/config.proto\"\xd6\x01\n" +
+	"\tProbeConf\x128\n" +
+	"\x04step\x18\x01 \x03(\v2$.cloudprober.probes.transaction.StepR\x04step\x12'\n" +
+	"\fkeep_cookies\x18\x02 \x01(\b:\x04trueR\vkeepCookies\x12?\n" +
+	"\n" +
+	"tls_config\x18\x03 \x01(\v2 .cloudprober.tlsconfig.TLSConfigR\ttlsConfig\x12%\n" +
+	"\x0elatency_budget\x18\x04 \x01(\tR\rlatencyBudget\"\xc0\x04\n" +
+	"\x04Step\x12\x12\n" +
+	"\x04name\x18\x01 \x02(\tR\x04name\x12\x10\n" +
+	"\x03url\x18\x02 \x02(\tR\x03url\x12H\n" +
+	"\x06method\x18\x03 \x01(\x0e2+.cloudprober.probes.transaction.Step.Method:\x03GETR\x06method\x12H\n" +
+	"\x06header\x18\x04 \x03(\v20.cloudprober.probes.transaction.Step.HeaderEntryR\x06header\x12\x12\n" +
+	"\x04body\x18\x05 \x01(\tR\x04body\x120\n" +
+	"\x14expected_status_code\x18\x06 \x03(\x05R\x12expectedStatusCode\x12?\n" +
+	"\tvalidator\x18\a \x03(\v2!.cloudprober.validators.ValidatorR\tvalidator\x12A\n" +
+	"\aextract\x18\b \x03(\v2'.cloudprober.probes.transaction.ExtractR\aextract\x12%\n" +
+	"\x0elatency_budget\x18\t \x01(\tR\rlatencyBudget\x1a9\n" +
+	"\vHeaderEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"R\n" +
+	"\x06Method\x12\a\n" +
+	"\x03GET\x10\x00\x12\b\n" +
+	"\x04POST\x10\x01\x12\a\n" +
+	"\x03PUT\x10\x02\x12\b\n" +
+	"\x04HEAD\x10\x03\x12\n" +
+	"\n" +
+	"\x06DELETE\x10\x04\x12\t\n" +
+	"\x05PATCH\x10\x05\x12\v\n" +
+	"\aOPTIONS\x10\x06\"x\n" +
+	"\aExtract\x12\x12\n" +
+	"\x04name\x18\x01 \x02(\tR\x04name\x12\x1d\n" +
+	"\tjq_filter\x18\x02 \x01(\tH\x00R\bjqFilter\x12\x16\n" +
+	"\x05regex\x18\x03 \x01(\tH\x00R\x05regex\x12\x18\n" +
+	"\x06header\x18\x04 \x01(\tH\x00R\x06headerB\b\n" +
+	"\x06sourceB=Z;github.com/cloudprober/cloudprober/probes/transaction/proto"
+
+var (
+	file_github_com_cloudprober_cloudprober_probes_transaction_proto_config_proto_rawDescOnce sync.Once
+	file_github_com_cloudprober_cloudprober_probes_transaction_proto_config_proto_rawDescData []byte
+)
+
+func file_github_com_cloudprober_cloudprober_probes_transaction_proto_config_proto_rawDescGZIP() []byte {
+	file_github_com_cloudprober_cloudprober_probes_transaction_proto_config_proto_rawDescOnce.Do(func() {
+		file_github_com_cloudprober_cloudprober_probes_transaction_proto_config_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_github_com_cloudprober_cloudprober_probes_transaction_proto_config_proto_rawDesc), len(file_github_com_cloudprober_cloudprober_probes_transaction_proto_config_proto_rawDesc)))
+	})
+	return file_github_com_cloudprober_cloudprober_probes_transaction_proto_config_proto_rawDescData
+}
+
+var file_github_com_cloudprober_cloudprober_probes_transaction_proto_config_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_github_com_cloudprober_cloudprober_probes_transaction_proto_config_proto_msgTypes = make([]protoimpl.MessageInfo, 4)
+var file_github_com_cloudprober_cloudprober_probes_transaction_proto_config_proto_goTypes = []any{
+	(Step_Method)(0),         // 0: cloudprober.probes.transaction.Step.Method
+	(*ProbeConf)(nil),        // 1: cloudprober.probes.transaction.ProbeConf
+	(*Step)(nil),             // 2: cloudprober.probes.transaction.Step
+	(*Extract)(nil),          // 3: cloudprober.probes.transaction.Extract
+	nil,                      // 4: cloudprober.probes.transaction.Step.HeaderEntry
+	(*proto.TLSConfig)(nil),  // 5: cloudprober.tlsconfig.TLSConfig
+	(*proto1.Validator)(nil), // 6: cloudprober.validators.Validator
+}
+var file_github_com_cloudprober_cloudprober_probes_transaction_proto_config_proto_depIdxs = []int32{
+	2, // 0: cloudprober.probes.transaction.ProbeConf.step:type_name -> cloudprober.probes.transaction.Step
+	5, // 1: cloudprober.probes.transaction.ProbeConf.tls_config:type_name -> cloudprober.tlsconfig.TLSConfig
+	0, // 2: cloudprober.probes.transaction.Step.method:type_name -> cloudprober.probes.transaction.Step.Method
+	4, // 3: cloudprober.probes.transaction.Step.header:type_name -> cloudprober.probes.transaction.Step.HeaderEntry
+	6, // 4: cloudprober.probes.transaction.Step.validator:type_name -> cloudprober.validators.Validator
+	3, // 5: cloudprober.probes.transaction.Step.extract:type_name -> cloudprober.probes.transaction.Extract
+	6, // [6:6] is the sub-list for method output_type
+	6, // [6:6] is the sub-list for method input_type
+	6, // [6:6] is the sub-list for extension type_name
+	6, // [6:6] is the sub-list for extension extendee
+	0, // [0:6] is the sub-list for field type_name
+}
+
+func init() { file_github_com_cloudprober_cloudprober_probes_transaction_proto_config_proto_init() }
+func file_github_com_cloudprober_cloudprober_probes_transaction_proto_config_proto_init() {
+	if File_github_com_cloudprober_cloudprober_probes_transaction_proto_config_proto != nil {
+		return
+	}
+	file_github_com_cloudprober_cloudprober_probes_transaction_proto_config_proto_msgTypes[2].OneofWrappers = []any{
+		(*Extract_JqFilter)(nil),
+		(*Extract_Regex)(nil),
+		(*Extract_Header)(nil),
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_github_com_cloudprober_cloudprober_probes_transaction_proto_config_proto_rawDesc), len(file_github_com_cloudprober_cloudprober_probes_transaction_proto_config_proto_rawDesc)),
+			NumEnums:      1,
+			NumMessages:   4,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_github_com_cloudprober_cloudprober_probes_transaction_proto_config_proto_goTypes,
+		DependencyIndexes: file_github_com_cloudprober_cloudprober_probes_transaction_proto_config_proto_depIdxs,
+		EnumInfos:         file_github_com_cloudprober_cloudprober_probes_transaction_proto_config_proto_enumTypes,
+		MessageInfos:      file_github_com_cloudprober_cloudprober_probes_transaction_proto_config_proto_msgTypes,
+	}.Build()
+	File_github_com_cloudprober_cloudprober_probes_transaction_proto_config_proto = out.File
+	file_github_com_cloudprober_cloudprober_probes_transaction_proto_config_proto_goTypes = nil
+	file_github_com_cloudprober_cloudprober_probes_transaction_proto_config_proto_depIdxs = nil
+}
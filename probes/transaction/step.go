@@ -0,0 +1,246 @@
+// Copyright 2025 The Cloudprober Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package transaction
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/cloudprober/cloudprober/common/strtemplate"
+	"github.com/cloudprober/cloudprober/internal/validators"
+	"github.com/cloudprober/cloudprober/logger"
+	configpb "github.com/cloudprober/cloudprober/probes/transaction/proto"
+	"github.com/itchyny/gojq"
+)
+
+// Max response body size that we read for a step.
+const maxBodySize = 10 * 1024 * 1024
+
+type extractor struct {
+	name   string
+	jq     *gojq.Query
+	re     *regexp.Regexp
+	header string
+}
+
+// step is the compiled form of a step config.
+type step struct {
+	c          *configpb.Step
+	name       string
+	method     string
+	validators []*validators.Validator
+	extractors []*extractor
+	budget     time.Duration // Latency budget, 0 if not configured.
+}
+
+func newStep(c *configpb.Step) (*step, error) {
+	s := &step{
+		c:      c,
+		name:   c.GetName(),
+		method: c.GetMethod().String(),
+	}
+
+	if s.name == "" {
+		return nil, errors.New("step name is required")
+	}
+	if c.GetUrl() == "" {
+		return nil, fmt.Errorf("step %s: url is required", s.name)
+	}
+
+	var err error
+	if c.GetLatencyBudget() != "" {
+		if s.budget, err = parseBudget(c.GetLatencyBudget()); err != nil {
+			return nil, fmt.Errorf("step %s: %v", s.name, err)
+		}
+	}
+
+	if len(c.GetValidator()) > 0 {
+		if s.validators, err = validators.Init(c.GetValidator()); err != nil {
+			return nil, fmt.Errorf("step %s: failed to initialize validators: %v", s.name, err)
+		}
+	}
+
+	for _, ec := range c.GetExtract() {
+		e := &extractor{name: ec.GetName()}
+		switch ec.Source.(type) {
+		case *configpb.Extract_JqFilter:
+			if e.jq, err = gojq.Parse(ec.GetJqFilter()); err != nil {
+				return nil, fmt.Errorf("step %s: error parsing jq_filter (%s): %v", s.name, ec.GetJqFilter(), err)
+			}
+		case *configpb.Extract_Regex:
+			if e.re, err = regexp.Compile(ec.GetRegex()); err != nil {
+				return nil, fmt.Errorf("step %s: error compiling regex (%s): %v", s.name, ec.GetRegex(), err)
+			}
+		case *configpb.Extract_Header:
+			e.header = ec.GetHeader()
+		default:
+			return nil, fmt.Errorf("step %s: no source specified for extract %s", s.name, e.name)
+		}
+		s.extractors = append(s.extractors, e)
+	}
+
+	return s, nil
+}
+
+func parseBudget(s string) (time.Duration, error) {
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, fmt.Errorf("error parsing latency_budget (%s): %v", s, err)
+	}
+	if d <= 0 {
+		return 0, fmt.Errorf("latency_budget (%s) should be positive", s)
+	}
+	return d, nil
+}
+
+func substitute(in string, vars map[string]string) (string, error) {
+	out, foundAll := strtemplate.SubstituteLabels(in, vars)
+	if !foundAll {
+		return "", fmt.Errorf("unknown variable in: %s", in)
+	}
+	return out, nil
+}
+
+func (s *step) request(ctx context.Context, vars map[string]string) (*http.Request, error) {
+	url, err := substitute(s.c.GetUrl(), vars)
+	if err != nil {
+		return nil, err
+	}
+
+	var body io.Reader
+	if s.c.GetBody() != "" {
+		b, err := substitute(s.c.GetBody(), vars)
+		if err != nil {
+			return nil, err
+		}
+		body = strings.NewReader(b)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, s.method, url, body)
+	if err != nil {
+		return nil, err
+	}
+
+	for k, v := range s.c.GetHeader() {
+		if v, err = substitute(v, vars); err != nil {
+			return nil, err
+		}
+		if strings.EqualFold(k, "Host") {
+			req.Host = v
+			continue
+		}
+		req.Header.Set(k, v)
+	}
+
+	return req, nil
+}
+
+func (s *step) statusOK(code int) bool {
+	if len(s.c.GetExpectedStatusCode()) == 0 {
+		return code >= 200 && code < 300
+	}
+	return slices.Contains(s.c.GetExpectedStatusCode(), int32(code))
+}
+
+func (e *extractor) extract(resp *http.Response, body []byte) (string, error) {
+	switch {
+	case e.header != "":
+		v := resp.Header.Get(e.header)
+		if v == "" {
+			return "", fmt.Errorf("header %s not found in the response", e.header)
+		}
+		return v, nil
+
+	case e.re != nil:
+		m := e.re.FindSubmatch(body)
+		if m == nil {
+			return "", fmt.Errorf("regex %s didn't match the response", e.re.String())
+		}
+		if len(m) > 1 {
+			return string(m[1]), nil
+		}
+		return string(m[0]), nil
+
+	default:
+		var input any
+		if err := json.Unmarshal(body, &input); err != nil {
+			return "", fmt.Errorf("response is not a valid JSON: %v", err)
+		}
+		v, ok := e.jq.Run(input).Next()
+		if !ok || v == nil {
+			return "", fmt.Errorf("jq filter %s didn't return a value", e.jq.String())
+		}
+		if err, ok := v.(error); ok {
+			return "", err
+		}
+		if str, ok := v.(string); ok {
+			return str, nil
+		}
+		b, err := json.Marshal(v)
+		if err != nil {
+			return "", err
+		}
+		return string(b), nil
+	}
+}
+
+// run runs the step and updates vars with the extracted values.
+func (s *step) run(ctx context.Context, client *http.Client, vars map[string]string, l *logger.Logger) error {
+	req, err := s.request(ctx, vars)
+	if err != nil {
+		return err
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxBodySize))
+	if err != nil {
+		return err
+	}
+	l.Debug("Response: \n" + string(body))
+
+	if !s.statusOK(resp.StatusCode) {
+		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	if s.validators != nil {
+		failedValidations := validators.RunValidators(s.validators, &validators.Input{Response: resp, ResponseBody: body}, nil, l)
+		if len(failedValidations) > 0 {
+			return fmt.Errorf("failed validations: %s", strings.Join(failedValidations, ","))
+		}
+	}
+
+	for _, e := range s.extractors {
+		v, err := e.extract(resp, body)
+		if err != nil {
+			return fmt.Errorf("error extracting %s: %v", e.name, err)
+		}
+		vars[e.name] = v
+	}
+
+	return nil
+}
@@ -0,0 +1,281 @@
+// Copyright 2025 The Cloudprober Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+/*
+Package transaction implements a probe type that executes an ordered sequence
+of HTTP requests (steps) for each target, e.g. to monitor a user journey like
+login -> call API -> logout. Values extracted from a step's response can be
+used in the subsequent steps.
+*/
+package transaction
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/http/cookiejar"
+	"strconv"
+	"time"
+
+	"github.com/cloudprober/cloudprober/common/tlsconfig"
+	"github.com/cloudprober/cloudprober/logger"
+	"github.com/cloudprober/cloudprober/metrics"
+	"github.com/cloudprober/cloudprober/probes/common/sched"
+	"github.com/cloudprober/cloudprober/probes/options"
+	configpb "github.com/cloudprober/cloudprober/probes/transaction/proto"
+	"github.com/cloudprober/cloudprober/targets/endpoint"
+)
+
+// Probe holds aggregate information about all probe runs, per-target.
+type Probe struct {
+	name string
+	opts *options.Options
+	c    *configpb.ProbeConf
+	l    *logger.Logger
+
+	steps     []*step
+	transport http.RoundTripper
+
+	// Latency budget for the whole transaction, 0 if not configured.
+	budget time.Duration
+	// Whether a latency budget is configured for the transaction or any of the
+	// steps.
+	hasBudgets bool
+}
+
+type stepResult struct {
+	name           string
+	total, success int64
+	latency        metrics.LatencyValue
+
+	hasBudget  bool
+	overBudget int64
+}
+
+type probeResult struct {
+	total, success int64
+	latency        metrics.LatencyValue
+	steps          []*stepResult
+
+	// Successful runs that went over a latency budget.
+	hasBudgets bool
+	degraded   int64
+}
+
+func (p *Probe) newLatencyValue() metrics.LatencyValue {
+	if p.opts.LatencyDist != nil {
+		return p.opts.LatencyDist.CloneDist()
+	}
+	return metrics.NewFloat(0)
+}
+
+func (p *Probe) newResult(_ *endpoint.Endpoint) sched.ProbeResult {
+	result := &probeResult{
+		latency:    p.newLatencyValue(),
+		steps:      make([]*stepResult, len(p.steps)),
+		hasBudgets: p.hasBudgets,
+	}
+	for i, s := range p.steps {
+		result.steps[i] = &stepResult{
+			name:      s.name,
+			latency:   p.newLatencyValue(),
+			hasBudget: s.budget != 0,
+		}
+	}
+	return result
+}
+
+// SuccessCount returns the number of successful probe runs so far.
+func (result *probeResult) SuccessCount() int64 {
+	return result.success
+}
+
+func (result *probeResult) Metrics(ts time.Time, _ int64, opts *options.Options) []*metrics.EventMetrics {
+	em := metrics.NewEventMetrics(ts).
+		AddMetric("total", metrics.NewInt(result.total)).
+		AddMetric("success", metrics.NewInt(result.success)).
+		AddMetric(opts.LatencyMetricName, result.latency.Clone()).
+		AddLabel("ptype", "transaction")
+	if result.hasBudgets {
+		em.AddMetric("degraded", metrics.NewInt(result.degraded))
+	}
+
+	ems := []*metrics.EventMetrics{em}
+
+	// Per-step metrics, to make it easy to find out which step is failing or
+	// slow.
+	for _, sr := range result.steps {
+		em := metrics.NewEventMetrics(ts).
+			AddMetric("step_total", metrics.NewInt(sr.total)).
+			AddMetric("step_success", metrics.NewInt(sr.success)).
+			AddMetric("step_"+opts.LatencyMetricName, sr.latency.Clone()).
+			AddLabel("ptype", "transaction").
+			AddLabel("step", sr.name)
+		if sr.hasBudget {
+			em.AddMetric("step_over_budget", metrics.NewInt(sr.overBudget))
+		}
+		ems = append(ems, em)
+	}
+
+	return ems
+}
+
+// Init initializes the probe with the given params.
+func (p *Probe) Init(name string, opts *options.Options) error {
+	c, ok := opts.ProbeConf.(*configpb.ProbeConf)
+	if !ok {
+		return fmt.Errorf("not transaction probe config")
+	}
+	p.name = name
+	p.opts = opts
+	if p.l = opts.Logger; p.l == nil {
+		p.l = &logger.Logger{}
+	}
+	p.c = c
+
+	if len(p.c.GetStep()) == 0 {
+		return errors.New("at least one step is required")
+	}
+
+	stepNames := make(map[string]bool)
+	for _, sc := range p.c.GetStep() {
+		s, err := newStep(sc)
+		if err != nil {
+			return err
+		}
+		if stepNames[s.name] {
+			return fmt.Errorf("duplicate step name: %s", s.name)
+		}
+		stepNames[s.name] = true
+		p.steps = append(p.steps, s)
+		p.hasBudgets = p.hasBudgets || s.budget != 0
+	}
+
+	if p.c.GetLatencyBudget() != "" {
+		var err error
+		if p.budget, err = parseBudget(p.c.GetLatencyBudget()); err != nil {
+			return err
+		}
+		p.hasBudgets = true
+	}
+
+	dialer := &net.Dialer{
+		Timeout:   p.opts.MaxTimeout(),
+		KeepAlive: 30 * time.Second,
+	}
+	if p.opts.SourceIP != nil {
+		dialer.LocalAddr = &net.TCPAddr{IP: p.opts.SourceIP}
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DialContext = dialer.DialContext
+	if p.c.GetTlsConfig() != nil {
+		transport.TLSClientConfig = &tls.Config{}
+		if err := tlsconfig.UpdateTLSConfig(transport.TLSClientConfig, p.c.GetTlsConfig()); err != nil {
+			return fmt.Errorf("tls_config error: %v", err)
+		}
+	}
+	p.transport = transport
+
+	return nil
+}
+
+// targetVars returns the variables that are available to the steps, for the
+// given target.
+func targetVars(target endpoint.Endpoint) map[string]string {
+	vars := map[string]string{
+		"target": target.Name,
+	}
+	if target.Port != 0 {
+		vars["port"] = strconv.Itoa(target.Port)
+	}
+	for k, v := range target.Labels {
+		vars["target.label."+k] = v
+	}
+	return vars
+}
+
+func (p *Probe) client() *http.Client {
+	client := &http.Client{Transport: p.transport}
+	if p.c.GetKeepCookies() {
+		// cookiejar.New never returns an error.
+		client.Jar, _ = cookiejar.New(nil)
+	}
+	return client
+}
+
+func (p *Probe) runProbe(ctx context.Context, runReq *sched.RunProbeForTargetRequest) {
+	if runReq.Result == nil {
+		runReq.Result = p.newResult(&runReq.Target)
+	}
+
+	target, result := runReq.Target, runReq.Result.(*probeResult)
+	l := p.l.WithAttributes(slog.String("target", target.Name))
+
+	result.total++
+
+	vars := targetVars(target)
+	client := p.client()
+
+	var degraded bool
+
+	start := time.Now()
+	for i, s := range p.steps {
+		sr := result.steps[i]
+		sr.total++
+
+		stepStart := time.Now()
+		if err := s.run(ctx, client, vars, l); err != nil {
+			l.Error("step ", s.name, " failed: ", err.Error())
+			return
+		}
+		latency := time.Since(stepStart)
+
+		sr.success++
+		sr.latency.AddFloat64(latency.Seconds() / p.opts.LatencyUnit.Seconds())
+
+		if s.budget != 0 && latency > s.budget {
+			l.Warningf("step %s took %v, over the latency budget of %v", s.name, latency, s.budget)
+			sr.overBudget++
+			degraded = true
+		}
+	}
+	latency := time.Since(start)
+
+	result.success++
+	result.latency.AddFloat64(latency.Seconds() / p.opts.LatencyUnit.Seconds())
+
+	if p.budget != 0 && latency > p.budget {
+		l.Warningf("transaction took %v, over the latency budget of %v", latency, p.budget)
+		degraded = true
+	}
+	if degraded {
+		result.degraded++
+	}
+}
+
+// Start starts and runs the probe indefinitely.
+func (p *Probe) Start(ctx context.Context, dataChan chan *metrics.EventMetrics) {
+	s := &sched.Scheduler{
+		ProbeName:         p.name,
+		DataChan:          dataChan,
+		Opts:              p.opts,
+		NewResult:         p.newResult,
+		RunProbeForTarget: p.runProbe,
+	}
+	s.UpdateTargetsAndStartProbes(ctx)
+}
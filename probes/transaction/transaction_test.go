@@ -0,0 +1,381 @@
+// Copyright 2025 The Cloudprober Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package transaction
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/cloudprober/cloudprober/metrics"
+	"github.com/cloudprober/cloudprober/probes/common/sched"
+	"github.com/cloudprober/cloudprober/probes/options"
+	configpb "github.com/cloudprober/cloudprober/probes/transaction/proto"
+	"github.com/cloudprober/cloudprober/targets/endpoint"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/proto"
+)
+
+func testProbe(t *testing.T, c *configpb.ProbeConf) (*Probe, error) {
+	t.Helper()
+
+	opts := options.DefaultOptions()
+	opts.ProbeConf = c
+	p := &Probe{}
+	return p, p.Init("test-probe", opts)
+}
+
+func TestInit(t *testing.T) {
+	tests := []struct {
+		name    string
+		steps   []*configpb.Step
+		wantErr bool
+	}{
+		{
+			name: "ok",
+			steps: []*configpb.Step{
+				{Name: proto.String("login"), Url: proto.String("http://@target@/login")},
+				{Name: proto.String("data"), Url: proto.String("http://@target@/data")},
+			},
+		},
+		{
+			name:    "no_steps",
+			wantErr: true,
+		},
+		{
+			name: "duplicate_step_name",
+			steps: []*configpb.Step{
+				{Name: proto.String("login"), Url: proto.String("http://@target@/login")},
+				{Name: proto.String("login"), Url: proto.String("http://@target@/data")},
+			},
+			wantErr: true,
+		},
+		{
+			name: "no_url",
+			steps: []*configpb.Step{
+				{Name: proto.String("login")},
+			},
+			wantErr: true,
+		},
+		{
+			name: "bad_regex",
+			steps: []*configpb.Step{
+				{
+					Name: proto.String("login"),
+					Url:  proto.String("http://@target@/login"),
+					Extract: []*configpb.Extract{
+						{Name: proto.String("token"), Source: &configpb.Extract_Regex{Regex: "("}},
+					},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "bad_latency_budget",
+			steps: []*configpb.Step{
+				{
+					Name:          proto.String("login"),
+					Url:           proto.String("http://@target@/login"),
+					LatencyBudget: proto.String("-1s"),
+				},
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := testProbe(t, &configpb.ProbeConf{Step: tt.steps})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Init() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRunProbe(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/login":
+			if r.Method != http.MethodPost {
+				w.WriteHeader(http.StatusMethodNotAllowed)
+				return
+			}
+			http.SetCookie(w, &http.Cookie{Name: "session", Value: "s1"})
+			w.Header().Set("X-Request-Id", "req-1")
+			fmt.Fprint(w, `{"token": "secret", "user": {"id": 42}}`)
+		case "/data":
+			if r.Header.Get("Authorization") != "Bearer secret" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			if c, err := r.Cookie("session"); err != nil || c.Value != "s1" {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+			fmt.Fprintf(w, "user=%s env=%s", r.URL.Query().Get("user"), r.URL.Query().Get("env"))
+		case "/logout":
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	defer ts.Close()
+
+	host, portStr, _ := net.SplitHostPort(ts.Listener.Addr().String())
+	port, _ := strconv.Atoi(portStr)
+
+	loginStep := &configpb.Step{
+		Name:   proto.String("login"),
+		Url:    proto.String("http://@target@:@port@/login"),
+		Method: configpb.Step_POST.Enum(),
+		Body:   proto.String(`{"user": "test"}`),
+		Extract: []*configpb.Extract{
+			{Name: proto.String("token"), Source: &configpb.Extract_JqFilter{JqFilter: ".token"}},
+			{Name: proto.String("user_id"), Source: &configpb.Extract_JqFilter{JqFilter: ".user.id"}},
+		},
+	}
+	dataStep := &configpb.Step{
+		Name:   proto.String("data"),
+		Url:    proto.String("http://@target@:@port@/data?user=@user_id@&env=@target.label.env@"),
+		Header: map[string]string{"Authorization": "Bearer @token@"},
+		Extract: []*configpb.Extract{
+			{Name: proto.String("user"), Source: &configpb.Extract_Regex{Regex: "user=([0-9]+)"}},
+		},
+	}
+	logoutStep := &configpb.Step{
+		Name:               proto.String("logout"),
+		Url:                proto.String("http://@target@:@port@/logout?user=@user@"),
+		ExpectedStatusCode: []int32{204},
+	}
+
+	tests := []struct {
+		name        string
+		steps       []*configpb.Step
+		keepCookies bool
+		wantSuccess int64
+		// Expected step success counts.
+		wantStepSuccess []int64
+	}{
+		{
+			name:            "success",
+			steps:           []*configpb.Step{loginStep, dataStep, logoutStep},
+			keepCookies:     true,
+			wantSuccess:     1,
+			wantStepSuccess: []int64{1, 1, 1},
+		},
+		{
+			name:            "no_cookies",
+			steps:           []*configpb.Step{loginStep, dataStep, logoutStep},
+			wantStepSuccess: []int64{1, 0, 0},
+		},
+		{
+			name: "missing_variable",
+			steps: []*configpb.Step{
+				{
+					Name:   proto.String("data"),
+					Url:    proto.String("http://@target@:@port@/data"),
+					Header: map[string]string{"Authorization": "Bearer @token@"},
+				},
+			},
+			keepCookies:     true,
+			wantStepSuccess: []int64{0},
+		},
+		{
+			name: "unexpected_status_code",
+			steps: []*configpb.Step{
+				loginStep,
+				{
+					Name:               proto.String("logout"),
+					Url:                proto.String("http://@target@:@port@/logout"),
+					ExpectedStatusCode: []int32{200},
+				},
+			},
+			keepCookies:     true,
+			wantStepSuccess: []int64{1, 0},
+		},
+		{
+			name: "extraction_failure",
+			steps: []*configpb.Step{
+				{
+					Name: proto.String("login"),
+					Url:  proto.String("http://@target@:@port@/login"),
+					// Login requires POST, and the response is empty.
+					ExpectedStatusCode: []int32{405},
+					Extract: []*configpb.Extract{
+						{Name: proto.String("req_id"), Source: &configpb.Extract_Header{Header: "X-Request-Id"}},
+					},
+				},
+			},
+			keepCookies:     true,
+			wantStepSuccess: []int64{0},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := testProbe(t, &configpb.ProbeConf{
+				Step:        tt.steps,
+				KeepCookies: proto.Bool(tt.keepCookies),
+			})
+			if err != nil {
+				t.Fatalf("Error initializing probe: %v", err)
+			}
+
+			runReq := &sched.RunProbeForTargetRequest{
+				Target: endpoint.Endpoint{
+					Name:   host,
+					Port:   port,
+					Labels: map[string]string{"env": "prod"},
+				},
+			}
+			p.runProbe(context.Background(), runReq)
+
+			result := runReq.Result.(*probeResult)
+			assert.Equal(t, int64(1), result.total, "total")
+			assert.Equal(t, tt.wantSuccess, result.success, "success")
+
+			var gotStepSuccess []int64
+			for _, sr := range result.steps {
+				gotStepSuccess = append(gotStepSuccess, sr.success)
+			}
+			assert.Equal(t, tt.wantStepSuccess, gotStepSuccess, "step success")
+		})
+	}
+}
+
+func TestMetrics(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer ts.Close()
+
+	p, err := testProbe(t, &configpb.ProbeConf{
+		Step: []*configpb.Step{
+			{Name: proto.String("step1"), Url: proto.String(ts.URL)},
+			{Name: proto.String("step2"), Url: proto.String(ts.URL + "/@missing@")},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Error initializing probe: %v", err)
+	}
+
+	runReq := &sched.RunProbeForTargetRequest{Target: endpoint.Endpoint{Name: "test-target"}}
+	p.runProbe(context.Background(), runReq)
+
+	ems := runReq.Result.Metrics(time.Now(), 0, p.opts)
+	assert.Len(t, ems, 3)
+
+	assert.Equal(t, "1", ems[0].Metric("total").String())
+	assert.Equal(t, "0", ems[0].Metric("success").String())
+	assert.Equal(t, "transaction", ems[0].Label("ptype"))
+
+	for i, want := range []struct {
+		step    string
+		success string
+	}{{"step1", "1"}, {"step2", "0"}} {
+		em := ems[i+1]
+		assert.Equal(t, metrics.Kind(metrics.CUMULATIVE), em.Kind)
+		assert.Equal(t, want.step, em.Label("step"))
+		assert.Equal(t, "1", em.Metric("step_total").String())
+		assert.Equal(t, want.success, em.Metric("step_success").String())
+		assert.NotNil(t, em.Metric("step_latency"))
+	}
+}
+
+func TestLatencyBudget(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/slow" {
+			time.Sleep(50 * time.Millisecond)
+		}
+	}))
+	defer ts.Close()
+
+	tests := []struct {
+		name           string
+		budget         string
+		stepBudgets    []string
+		wantDegraded   int64
+		wantOverBudget []int64 // -1 if step_over_budget is not exported.
+	}{
+		{
+			name:           "no_budgets",
+			stepBudgets:    []string{"", ""},
+			wantDegraded:   -1,
+			wantOverBudget: []int64{-1, -1},
+		},
+		{
+			name:           "within_budgets",
+			budget:         "10s",
+			stepBudgets:    []string{"5s", "5s"},
+			wantOverBudget: []int64{0, 0},
+		},
+		{
+			name:           "step_over_budget",
+			stepBudgets:    []string{"5s", "10ms"},
+			wantDegraded:   1,
+			wantOverBudget: []int64{0, 1},
+		},
+		{
+			name:           "transaction_over_budget",
+			budget:         "10ms",
+			stepBudgets:    []string{"", ""},
+			wantDegraded:   1,
+			wantOverBudget: []int64{-1, -1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &configpb.ProbeConf{
+				Step: []*configpb.Step{
+					{Name: proto.String("fast"), Url: proto.String(ts.URL + "/fast")},
+					{Name: proto.String("slow"), Url: proto.String(ts.URL + "/slow")},
+				},
+			}
+			if tt.budget != "" {
+				c.LatencyBudget = proto.String(tt.budget)
+			}
+			for i, b := range tt.stepBudgets {
+				if b != "" {
+					c.Step[i].LatencyBudget = proto.String(b)
+				}
+			}
+
+			p, err := testProbe(t, c)
+			if err != nil {
+				t.Fatalf("Error initializing probe: %v", err)
+			}
+
+			runReq := &sched.RunProbeForTargetRequest{Target: endpoint.Endpoint{Name: "test-target"}}
+			p.runProbe(context.Background(), runReq)
+
+			ems := runReq.Result.Metrics(time.Now(), 0, p.opts)
+			assert.Equal(t, "1", ems[0].Metric("success").String(), "success")
+
+			metricValue := func(em *metrics.EventMetrics, name string) int64 {
+				if em.Metric(name) == nil {
+					return -1
+				}
+				return em.Metric(name).(*metrics.Int).Int64()
+			}
+			assert.Equal(t, tt.wantDegraded, metricValue(ems[0], "degraded"), "degraded")
+			for i, want := range tt.wantOverBudget {
+				assert.Equal(t, want, metricValue(ems[i+1], "step_over_budget"), "step_over_budget for step %d", i)
+			}
+		})
+	}
+}
@@ -0,0 +1,133 @@
+// Copyright 2025 The Cloudprober Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package udp
+
+import (
+	"net"
+	"sync"
+	"time"
+
+	"github.com/cloudprober/cloudprober/targets/endpoint"
+)
+
+// pendingPackets keeps track of the packets sent without the cloudprober
+// message header, i.e. when match_strategy is not MESSAGE. As these packets
+// don't carry a sequence number, responses are matched to the oldest pending
+// packet of the flow.
+type pendingPackets struct {
+	mu      sync.Mutex
+	timeout time.Duration
+	nextSeq map[flow]uint64
+	packets map[flow][]packetID
+	targets map[string]string // Target names by their "ip:port".
+}
+
+func newPendingPackets(timeout time.Duration) *pendingPackets {
+	return &pendingPackets{
+		timeout: timeout,
+		nextSeq: make(map[flow]uint64),
+		packets: make(map[flow][]packetID),
+		targets: make(map[string]string),
+	}
+}
+
+// setTarget records the target's address, so that we can find the target for
+// the responses.
+func (pp *pendingPackets) setTarget(addr *net.UDPAddr, target string) {
+	pp.mu.Lock()
+	defer pp.mu.Unlock()
+	pp.targets[addr.String()] = target
+}
+
+// retainTargets removes the state for the targets that are not in the given
+// list anymore.
+func (pp *pendingPackets) retainTargets(targets []endpoint.Endpoint) {
+	pp.mu.Lock()
+	defer pp.mu.Unlock()
+
+	current := make(map[string]bool)
+	for _, target := range targets {
+		current[target.Name] = true
+	}
+	for addr, target := range pp.targets {
+		if !current[target] {
+			delete(pp.targets, addr)
+		}
+	}
+	for f := range pp.packets {
+		if !current[f.target] {
+			delete(pp.packets, f)
+			delete(pp.nextSeq, f)
+		}
+	}
+}
+
+// expire drops the packets that have been pending for longer than the
+// timeout. Caller should hold the lock.
+func (pp *pendingPackets) expire(f flow, now time.Time) []packetID {
+	pkts := pp.packets[f]
+	for len(pkts) > 0 && now.Sub(pkts[0].txTS) > pp.timeout {
+		pkts = pkts[1:]
+	}
+	pp.packets[f] = pkts
+	return pkts
+}
+
+// add records a packet sent at txTS, and returns its sequence number.
+func (pp *pendingPackets) add(f flow, txTS time.Time) uint64 {
+	pp.mu.Lock()
+	defer pp.mu.Unlock()
+
+	pp.nextSeq[f]++
+	seq := pp.nextSeq[f]
+	pp.packets[f] = append(pp.expire(f, txTS), packetID{f: f, seq: seq, txTS: txTS})
+	return seq
+}
+
+// withdraw removes a packet that couldn't be sent.
+func (pp *pendingPackets) withdraw(f flow, seq uint64) {
+	pp.mu.Lock()
+	defer pp.mu.Unlock()
+
+	pkts := pp.packets[f]
+	for i := range pkts {
+		if pkts[i].seq == seq {
+			pp.packets[f] = append(pkts[:i], pkts[i+1:]...)
+			return
+		}
+	}
+}
+
+// match matches a response received from raddr, on the given source port, to
+// the oldest pending packet of the flow.
+func (pp *pendingPackets) match(srcPort string, raddr *net.UDPAddr, rxTS time.Time) (packetID, bool) {
+	pp.mu.Lock()
+	defer pp.mu.Unlock()
+
+	target, ok := pp.targets[raddr.String()]
+	if !ok {
+		return packetID{}, false
+	}
+	f := flow{srcPort, target}
+
+	pkts := pp.expire(f, rxTS)
+	if len(pkts) == 0 {
+		return packetID{}, false
+	}
+	pkt := pkts[0]
+	pp.packets[f] = pkts[1:]
+	pkt.rxTS = rxTS
+	return pkt, true
+}
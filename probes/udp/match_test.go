@@ -0,0 +1,90 @@
+// Copyright 2025 The Cloudprober Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package udp
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	configpb "github.com/cloudprober/cloudprober/probes/udp/proto"
+	"github.com/cloudprober/cloudprober/targets/endpoint"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestPendingPackets(t *testing.T) {
+	pp := newPendingPackets(time.Second)
+	addr := &net.UDPAddr{IP: net.ParseIP("10.0.0.1"), Port: 7}
+	pp.setTarget(addr, "t1")
+
+	f := flow{"1001", "t1"}
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		assert.Equal(t, uint64(i+1), pp.add(f, start.Add(time.Duration(i)*100*time.Millisecond)))
+	}
+	pp.withdraw(f, 2)
+
+	// Response from an unknown address.
+	_, ok := pp.match("1001", &net.UDPAddr{IP: net.ParseIP("10.0.0.2"), Port: 7}, start)
+	assert.False(t, ok, "unknown address")
+
+	// Response on a different source port.
+	_, ok = pp.match("1002", addr, start)
+	assert.False(t, ok, "different source port")
+
+	pkt, ok := pp.match("1001", addr, start.Add(50*time.Millisecond))
+	assert.True(t, ok)
+	assert.Equal(t, uint64(1), pkt.seq)
+	assert.Equal(t, 50*time.Millisecond, pkt.rxTS.Sub(pkt.txTS))
+
+	// Packet 3 is expired by now.
+	_, ok = pp.match("1001", addr, start.Add(1500*time.Millisecond))
+	assert.False(t, ok, "expired packet")
+
+	pp.add(f, start.Add(2*time.Second))
+	pp.retainTargets([]endpoint.Endpoint{{Name: "t2"}})
+	_, ok = pp.match("1001", addr, start.Add(2*time.Second))
+	assert.False(t, ok, "removed target")
+}
+
+func TestMatchResponse(t *testing.T) {
+	addr := &net.UDPAddr{IP: net.ParseIP("10.0.0.1"), Port: 7}
+	f := flow{"1001", "t1"}
+
+	for _, strategy := range []configpb.ProbeConf_MatchStrategy{configpb.ProbeConf_PAYLOAD, configpb.ProbeConf_ANY_RESPONSE} {
+		t.Run(strategy.String(), func(t *testing.T) {
+			p := &Probe{
+				c:       &configpb.ProbeConf{MatchStrategy: strategy.Enum(), Payload: proto.String("ping")},
+				payload: []byte("ping"),
+				pending: newPendingPackets(time.Second),
+			}
+			p.pending.setTarget(addr, "t1")
+			now := time.Now()
+			p.pending.add(f, now)
+			p.pending.add(f, now)
+
+			_, err := p.matchResponse("1001", addr, []byte("pong"), now)
+			if strategy == configpb.ProbeConf_PAYLOAD {
+				assert.Error(t, err, "mismatched payload")
+			} else {
+				assert.NoError(t, err)
+			}
+
+			_, err = p.matchResponse("1001", addr, []byte("ping"), now)
+			assert.NoError(t, err)
+		})
+	}
+}
@@ -21,6 +21,77 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
+// How to match the responses to the sent packets.
+type ProbeConf_MatchStrategy int32
+
+const (
+	// Packets carry a cloudprober message header (source, destination,
+	// sequence number and timestamp), and responses are matched using it.
+	// This works with the cloudprober UDP server, and with any other server
+	// that echoes the packets back as it is.
+	ProbeConf_MESSAGE ProbeConf_MatchStrategy = 0
+	// Packets carry only the payload (see payload below), and a response
+	// matches if it's identical to the payload. Use this for echo services
+	// that may not be able to handle the cloudprober message, e.g. because
+	// they limit the message size or expect a particular payload.
+	ProbeConf_PAYLOAD ProbeConf_MatchStrategy = 1
+	// Packets carry only the payload, and any response from the target
+	// counts. Use this for request-response services that don't echo, e.g.
+	// game servers or proprietary daemons.
+	ProbeConf_ANY_RESPONSE ProbeConf_MatchStrategy = 2
+)
+
+// Enum value maps for ProbeConf_MatchStrategy.
+var (
+	ProbeConf_MatchStrategy_name = map[int32]string{
+		0: "MESSAGE",
+		1: "PAYLOAD",
+		2: "ANY_RESPONSE",
+	}
+	ProbeConf_MatchStrategy_value = map[string]int32{
+		"MESSAGE":      0,
+		"PAYLOAD":      1,
+		"ANY_RESPONSE": 2,
+	}
+)
+
+func (x ProbeConf_MatchStrategy) Enum() *ProbeConf_MatchStrategy {
+	p := new(ProbeConf_MatchStrategy)
+	*p = x
+	return p
+}
+
+func (x ProbeConf_MatchStrategy) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (ProbeConf_MatchStrategy) Descriptor() protoreflect.EnumDescriptor {
+	return file_github_com_cloudprober_cloudprober_probes_udp_proto_config_proto_enumTypes[0].Descriptor()
+}
+
+func (ProbeConf_MatchStrategy) Type() protoreflect.EnumType {
+	return &file_github_com_cloudprober_cloudprober_probes_udp_proto_config_proto_enumTypes[0]
+}
+
+func (x ProbeConf_MatchStrategy) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Do not use.
+func (x *ProbeConf_MatchStrategy) UnmarshalJSON(b []byte) error {
+	num, err := protoimpl.X.UnmarshalJSONEnum(x.Descriptor(), b)
+	if err != nil {
+		return err
+	}
+	*x = ProbeConf_MatchStrategy(num)
+	return nil
+}
+
+// Deprecated: Use ProbeConf_MatchStrategy.Descriptor instead.
+func (ProbeConf_MatchStrategy) EnumDescriptor() ([]byte, []int) {
+	return file_github_com_cloudprober_cloudprober_probes_udp_proto_config_proto_rawDescGZIP(), []int{0, 0}
+}
+
 type ProbeConf struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
 	// Port to send UDP Ping to (UDP Echo).  If running with the UDP server that
@@ -31,7 +102,9 @@ type ProbeConf struct {
 	NumTxPorts *int32 `protobuf:"varint,4,opt,name=num_tx_ports,json=numTxPorts,def=16" json:"num_tx_ports,omitempty"`
 	// message max to account for MTU.
 	MaxLength *int32 `protobuf:"varint,5,opt,name=max_length,json=maxLength,def=1300" json:"max_length,omitempty"`
-	// Payload size
+	// Payload size in bytes. Messages also include a header (source, destination,
+	// sequence number and timestamp), so max_length should be bigger than the
+	// payload size.
 	PayloadSize *int32 `protobuf:"varint,6,opt,name=payload_size,json=payloadSize" json:"payload_size,omitempty"`
 	// Changes the exported monitoring streams to be per port:
 	// 1. Changes the streams names to total-per-port, success-per-port etc.
@@ -51,7 +124,30 @@ type ProbeConf struct {
 	// If there are more targets, they are pruned from the list to bring targets
 	// list under maxTargets.  A large number of targets has impact on resource
 	// consumption.
-	MaxTargets    *int32 `protobuf:"varint,9,opt,name=max_targets,json=maxTargets,def=500" json:"max_targets,omitempty"`
+	MaxTargets *int32 `protobuf:"varint,9,opt,name=max_targets,json=maxTargets,def=500" json:"max_targets,omitempty"`
+	// TOS byte (IPv4) or traffic class (IPv6) for the outgoing packets, e.g. to
+	// validate QoS classes end-to-end. DSCP value goes in the upper 6 bits of
+	// this byte, i.e. tos = dscp << 2. For example, use 184 for DSCP EF (46).
+	// Note that the echo replies from the UDP server are not marked.
+	Tos *int32 `protobuf:"varint,10,opt,name=tos" json:"tos,omitempty"`
+	// Number of messages to send to each target in each probe run. Along with
+	// payload_size and latency_distribution, this lets UDP probe double as a
+	// lightweight loss (total - success) and jitter measurement between two
+	// probers. Messages are round-robined through the transmit ports; if
+	// use_all_tx_ports_per_probe is set, this many messages are sent from each
+	// transmit port.
+	PacketsPerProbe *int32 `protobuf:"varint,11,opt,name=packets_per_probe,json=packetsPerProbe,def=1" json:"packets_per_probe,omitempty"`
+	// Interval between the messages to a target in a probe run. All messages
+	// should be sent within the first half of the probe interval, i.e.
+	// (packets_per_probe - 1) * packets_interval_msec < interval / 2.
+	PacketsIntervalMsec *int32 `protobuf:"varint,12,opt,name=packets_interval_msec,json=packetsIntervalMsec,def=0" json:"packets_interval_msec,omitempty"`
+	// For PAYLOAD and ANY_RESPONSE, as packets don't carry sequence numbers,
+	// responses are matched to the oldest pending packet from the same source
+	// port to the same target, in the order they arrive.
+	MatchStrategy *ProbeConf_MatchStrategy `protobuf:"varint,13,opt,name=match_strategy,json=matchStrategy,enum=cloudprober.probes.udp.ProbeConf_MatchStrategy,def=0" json:"match_strategy,omitempty"`
+	// Payload to send to the targets for PAYLOAD and ANY_RESPONSE match
+	// strategies. If not set, a payload of payload_size bytes is generated.
+	Payload       *string `protobuf:"bytes,14,opt,name=payload" json:"payload,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -64,6 +160,9 @@ const (
 	Default_ProbeConf_ExportMetricsByPort   = bool(false)
 	Default_ProbeConf_UseAllTxPortsPerProbe = bool(false)
 	Default_ProbeConf_MaxTargets            = int32(500)
+	Default_ProbeConf_PacketsPerProbe       = int32(1)
+	Default_ProbeConf_PacketsIntervalMsec   = int32(0)
+	Default_ProbeConf_MatchStrategy         = ProbeConf_MESSAGE
 )
 
 func (x *ProbeConf) Reset() {
@@ -145,11 +244,46 @@ func (x *ProbeConf) GetMaxTargets() int32 {
 	return Default_ProbeConf_MaxTargets
 }
 
+func (x *ProbeConf) GetTos() int32 {
+	if x != nil && x.Tos != nil {
+		return *x.Tos
+	}
+	return 0
+}
+
+func (x *ProbeConf) GetPacketsPerProbe() int32 {
+	if x != nil && x.PacketsPerProbe != nil {
+		return *x.PacketsPerProbe
+	}
+	return Default_ProbeConf_PacketsPerProbe
+}
+
+func (x *ProbeConf) GetPacketsIntervalMsec() int32 {
+	if x != nil && x.PacketsIntervalMsec != nil {
+		return *x.PacketsIntervalMsec
+	}
+	return Default_ProbeConf_PacketsIntervalMsec
+}
+
+func (x *ProbeConf) GetMatchStrategy() ProbeConf_MatchStrategy {
+	if x != nil && x.MatchStrategy != nil {
+		return *x.MatchStrategy
+	}
+	return Default_ProbeConf_MatchStrategy
+}
+
+func (x *ProbeConf) GetPayload() string {
+	if x != nil && x.Payload != nil {
+		return *x.Payload
+	}
+	return ""
+}
+
 var File_github_com_cloudprober_cloudprober_probes_udp_proto_config_proto protoreflect.FileDescriptor
 
 const file_github_com_cloudprober_cloudprober_probes_udp_proto_config_proto_rawDesc = "" +
 	"\n" +
-	"@github.com/cloudprober/cloudprober/probes/udp/proto/config.proto\x12\x16cloudprober.probes.udp\"\xb8\x02\n" +
+	"@github.com/cloudprober/cloudprober/probes/udp/proto/config.proto\x12\x16cloudprober.probes.udp\"\xe8\x04\n" +
 	"\tProbeConf\x12\x19\n" +
 	"\x04port\x18\x03 \x01(\x05:\x0531122R\x04port\x12$\n" +
 	"\fnum_tx_ports\x18\x04 \x01(\x05:\x0216R\n" +
@@ -160,7 +294,17 @@ const file_github_com_cloudprober_cloudprober_probes_udp_proto_config_proto_rawD
 	"\x16export_metrics_by_port\x18\a \x01(\b:\x05falseR\x13exportMetricsByPort\x12@\n" +
 	"\x1ause_all_tx_ports_per_probe\x18\b \x01(\b:\x05falseR\x15useAllTxPortsPerProbe\x12$\n" +
 	"\vmax_targets\x18\t \x01(\x05:\x03500R\n" +
-	"maxTargetsB5Z3github.com/cloudprober/cloudprober/probes/udp/proto"
+	"maxTargets\x12\x10\n" +
+	"\x03tos\x18\n" +
+	" \x01(\x05R\x03tos\x12-\n" +
+	"\x11packets_per_probe\x18\v \x01(\x05:\x011R\x0fpacketsPerProbe\x125\n" +
+	"\x15packets_interval_msec\x18\f \x01(\x05:\x010R\x13packetsIntervalMsec\x12_\n" +
+	"\x0ematch_strategy\x18\r \x01(\x0e2/.cloudprober.probes.udp.ProbeConf.MatchStrategy:\aMESSAGER\rmatchStrategy\x12\x18\n" +
+	"\apayload\x18\x0e \x01(\tR\apayload\";\n" +
+	"\rMatchStrategy\x12\v\n" +
+	"\aMESSAGE\x10\x00\x12\v\n" +
+	"\aPAYLOAD\x10\x01\x12\x10\n" +
+	"\fANY_RESPONSE\x10\x02B5Z3github.com/cloudprober/cloudprober/probes/udp/proto"
 
 var (
 	file_github_com_cloudprober_cloudprober_probes_udp_proto_config_proto_rawDescOnce sync.Once
@@ -174,16 +318,19 @@ func file_github_com_cloudprober_cloudprober_probes_udp_proto_config_proto_rawDe
 	return file_github_com_cloudprober_cloudprober_probes_udp_proto_config_proto_rawDescData
 }
 
+var file_github_com_cloudprober_cloudprober_probes_udp_proto_config_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
 var file_github_com_cloudprober_cloudprober_probes_udp_proto_config_proto_msgTypes = make([]protoimpl.MessageInfo, 1)
 var file_github_com_cloudprober_cloudprober_probes_udp_proto_config_proto_goTypes = []any{
-	(*ProbeConf)(nil), // 0: cloudprober.probes.udp.ProbeConf
+	(ProbeConf_MatchStrategy)(0), // 0: cloudprober.probes.udp.ProbeConf.MatchStrategy
+	(*ProbeConf)(nil),            // 1: cloudprober.probes.udp.ProbeConf
 }
 var file_github_com_cloudprober_cloudprober_probes_udp_proto_config_proto_depIdxs = []int32{
-	0, // [0:0] is the sub-list for method output_type
-	0, // [0:0] is the sub-list for method input_type
-	0, // [0:0] is the sub-list for extension type_name
-	0, // [0:0] is the sub-list for extension extendee
-	0, // [0:0] is the sub-list for field type_name
+	0, // 0: cloudprober.probes.udp.ProbeConf.match_strategy:type_name -> cloudprober.probes.udp.ProbeConf.MatchStrategy
+	1, // [1:1] is the sub-list for method output_type
+	1, // [1:1] is the sub-list for method input_type
+	1, // [1:1] is the sub-list for extension type_name
+	1, // [1:1] is the sub-list for extension extendee
+	0, // [0:1] is the sub-list for field type_name
 }
 
 func init() { file_github_com_cloudprober_cloudprober_probes_udp_proto_config_proto_init() }
@@ -196,13 +343,14 @@ func file_github_com_cloudprober_cloudprober_probes_udp_proto_config_proto_init(
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_github_com_cloudprober_cloudprober_probes_udp_proto_config_proto_rawDesc), len(file_github_com_cloudprober_cloudprober_probes_udp_proto_config_proto_rawDesc)),
-			NumEnums:      0,
+			NumEnums:      1,
 			NumMessages:   1,
 			NumExtensions: 0,
 			NumServices:   0,
 		},
 		GoTypes:           file_github_com_cloudprober_cloudprober_probes_udp_proto_config_proto_goTypes,
 		DependencyIndexes: file_github_com_cloudprober_cloudprober_probes_udp_proto_config_proto_depIdxs,
+		EnumInfos:         file_github_com_cloudprober_cloudprober_probes_udp_proto_config_proto_enumTypes,
 		MessageInfos:      file_github_com_cloudprober_cloudprober_probes_udp_proto_config_proto_msgTypes,
 	}.Build()
 	File_github_com_cloudprober_cloudprober_probes_udp_proto_config_proto = out.File
@@ -22,6 +22,7 @@ Queries to each target are sent in parallel.
 package udp
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
@@ -30,6 +31,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/cloudprober/cloudprober/common/iputils"
 	udpsrv "github.com/cloudprober/cloudprober/internal/servers/udp"
 	"github.com/cloudprober/cloudprober/internal/sysvars"
 	"github.com/cloudprober/cloudprober/internal/udpmessage"
@@ -39,6 +41,8 @@ import (
 	"github.com/cloudprober/cloudprober/probes/probeutils"
 	configpb "github.com/cloudprober/cloudprober/probes/udp/proto"
 	"github.com/cloudprober/cloudprober/targets/endpoint"
+	"golang.org/x/net/ipv4"
+	"golang.org/x/net/ipv6"
 )
 
 const (
@@ -74,6 +78,9 @@ type Probe struct {
 	fsm     *udpmessage.FlowStateMap // Map flow parameters to flow state.
 	payload []byte
 
+	// Packets pending a response, if match_strategy is not MESSAGE.
+	pending *pendingPackets
+
 	// Intermediate buffers of sent and received packets
 	sentPackets, rcvdPackets chan packetID
 	sPackets, rPackets       []packetID
@@ -126,6 +133,30 @@ func (p *Probe) newProbeResult(target endpoint.Endpoint) *probeResult {
 	}
 }
 
+// setTOS sets the TOS (IPv4) and traffic class (IPv6) byte for the outgoing
+// packets. Since the socket may be a dual-stack socket, we set both, and fail
+// only if neither of them can be set.
+func setTOS(conn *net.UDPConn, tos int) error {
+	err4 := ipv4.NewConn(conn).SetTOS(tos)
+	err6 := ipv6.NewConn(conn).SetTrafficClass(tos)
+	if err4 != nil && err6 != nil {
+		return fmt.Errorf("error setting TOS to %d: %v, %v", tos, err4, err6)
+	}
+	return nil
+}
+
+// bindToInterface binds the socket to the given network interface.
+func bindToInterface(conn *net.UDPConn, intf string) error {
+	rc, err := conn.SyscallConn()
+	if err != nil {
+		return err
+	}
+	if err := iputils.BindToDeviceControl(intf)("", "", rc); err != nil {
+		return fmt.Errorf("error binding UDP socket to interface %s: %v", intf, err)
+	}
+	return nil
+}
+
 // Init initializes the probe with the given params.
 func (p *Probe) Init(name string, opts *options.Options) error {
 	c, ok := opts.ProbeConf.(*configpb.ProbeConf)
@@ -145,11 +176,47 @@ func (p *Probe) Init(name string, opts *options.Options) error {
 	p.fsm = udpmessage.NewFlowStateMap()
 	p.res = make(map[flow]*probeResult)
 
+	if p.c.GetTos() < 0 || p.c.GetTos() > 255 {
+		return fmt.Errorf("tos (%d) should be between 0 and 255", p.c.GetTos())
+	}
+
+	if p.c.GetMaxLength() > maxMsgSize {
+		return fmt.Errorf("max_length (%d) should not be more than %d", p.c.GetMaxLength(), maxMsgSize)
+	}
+	if p.c.GetPayloadSize() < 0 || p.c.GetPayloadSize() >= p.c.GetMaxLength() {
+		return fmt.Errorf("payload_size (%d) should be between 0 and max_length (%d)", p.c.GetPayloadSize(), p.c.GetMaxLength())
+	}
+
+	if p.c.GetPacketsPerProbe() < 1 {
+		return fmt.Errorf("packets_per_probe (%d) should be at least 1", p.c.GetPacketsPerProbe())
+	}
+	if p.c.GetPacketsIntervalMsec() < 0 {
+		return fmt.Errorf("packets_interval_msec (%d) should not be negative", p.c.GetPacketsIntervalMsec())
+	}
+	if sendDuration := time.Duration(p.c.GetPacketsPerProbe()-1) * p.packetsInterval(); sendDuration > 0 && sendDuration >= p.opts.Interval/2 {
+		return fmt.Errorf("sending all the packets (%s) will take longer than half of the probe interval (%s), reduce packets_per_probe or packets_interval_msec", sendDuration, p.opts.Interval)
+	}
+
 	if p.c.GetPayloadSize() != 0 {
 		p.payload = make([]byte, p.c.GetPayloadSize())
 		probeutils.PatternPayload(p.payload, []byte(payloadPattern))
 	}
 
+	if p.c.GetMatchStrategy() != configpb.ProbeConf_MESSAGE {
+		if p.c.Payload != nil {
+			p.payload = []byte(p.c.GetPayload())
+		}
+		if len(p.payload) > int(p.c.GetMaxLength()) {
+			return fmt.Errorf("payload length (%d) should not be more than max_length (%d)", len(p.payload), p.c.GetMaxLength())
+		}
+		if p.c.GetMatchStrategy() == configpb.ProbeConf_PAYLOAD && len(p.payload) == 0 {
+			return errors.New("payload or payload_size is required for the PAYLOAD match strategy")
+		}
+		p.pending = newPendingPackets(p.opts.Timeout)
+	} else if p.c.Payload != nil {
+		return errors.New("payload is supported only for PAYLOAD and ANY_RESPONSE match strategies")
+	}
+
 	// Initialize intermediate buffers of sent and received packets
 	p.flushIntv = 2 * p.opts.Interval
 	if p.opts.Timeout > p.opts.Interval {
@@ -161,7 +228,7 @@ func (p *Probe) Init(name string, opts *options.Options) error {
 	}
 
 	// #send/recv-channel-buffer = #targets * #sources * #probing-intervals-between-flushes
-	minChanLen := int(p.c.GetMaxTargets()) * int(p.c.GetNumTxPorts()) * int(math.Ceil(float64(p.flushIntv)/float64(p.opts.Interval)))
+	minChanLen := int(p.c.GetMaxTargets()) * int(p.c.GetNumTxPorts()) * int(p.c.GetPacketsPerProbe()) * int(math.Ceil(float64(p.flushIntv)/float64(p.opts.Interval)))
 	p.l.Infof("Creating sent, rcvd channels of length: %d", 2*minChanLen)
 	p.sentPackets = make(chan packetID, 2*minChanLen)
 	p.rcvdPackets = make(chan packetID, 2*minChanLen)
@@ -187,6 +254,24 @@ func (p *Probe) Init(name string, opts *options.Options) error {
 			p.l.Warningf("Opening UDP socket failed: %v", err)
 			continue
 		}
+		if p.opts.BindInterface != "" {
+			if err := bindToInterface(udpConn, p.opts.BindInterface); err != nil {
+				udpConn.Close()
+				for _, c := range p.connList[:p.numConn] {
+					c.Close()
+				}
+				return err
+			}
+		}
+		if tos := int(p.c.GetTos()); tos != 0 {
+			if err := setTOS(udpConn, tos); err != nil {
+				udpConn.Close()
+				for _, c := range p.connList[:p.numConn] {
+					c.Close()
+				}
+				return err
+			}
+		}
 		p.l.Infof("UDP socket id %d, addr %v", p.numConn, udpConn.LocalAddr())
 		p.connList[p.numConn] = udpConn
 		_, p.srcPortList[p.numConn], err = net.SplitHostPort(udpConn.LocalAddr().String())
@@ -349,10 +434,24 @@ func isClientTimeout(err error) bool {
 	return ok && e != nil && e.Timeout()
 }
 
+// matchResponse matches a response without the cloudprober message header to
+// a pending packet.
+func (p *Probe) matchResponse(srcPort string, raddr *net.UDPAddr, b []byte, rxTS time.Time) (packetID, error) {
+	if p.c.GetMatchStrategy() == configpb.ProbeConf_PAYLOAD && !bytes.Equal(b, p.payload) {
+		return packetID{}, fmt.Errorf("response doesn't match the payload (got %d bytes, want %d)", len(b), len(p.payload))
+	}
+	pkt, ok := p.pending.match(srcPort, raddr, rxTS)
+	if !ok {
+		return packetID{}, errors.New("no pending packet for the response")
+	}
+	return pkt, nil
+}
+
 // recvLoop receives all packets over a UDP socket and updates
 // flowStates accordingly.
 func (p *Probe) recvLoop(ctx context.Context, conn *net.UDPConn) {
 	b := make([]byte, maxMsgSize)
+	_, srcPort, _ := net.SplitHostPort(conn.LocalAddr().String())
 	for {
 		select {
 		case <-ctx.Done():
@@ -369,6 +468,20 @@ func (p *Probe) recvLoop(ctx context.Context, conn *net.UDPConn) {
 		}
 
 		rxTS := time.Now()
+		if p.pending != nil {
+			pkt, err := p.matchResponse(srcPort, raddr, b[:msgLen], rxTS)
+			if err != nil {
+				p.l.Warningf("Incoming message from %s: %v", raddr, err)
+				continue
+			}
+			select {
+			case p.rcvdPackets <- pkt:
+			default:
+				p.l.Errorf("rcvdPackets channel full")
+			}
+			continue
+		}
+
 		msg, err := udpmessage.NewMessage(b[:msgLen])
 		if err != nil {
 			p.l.Errorf("Incoming message error from %s: %v", raddr, err)
@@ -383,16 +496,28 @@ func (p *Probe) recvLoop(ctx context.Context, conn *net.UDPConn) {
 }
 
 func (p *Probe) runSingleProbe(f flow, conn *net.UDPConn, maxLen int, raddr *net.UDPAddr) error {
-	flowState := p.fsm.FlowState(p.src, f.srcPort, f.target)
 	now := time.Now()
-	msg, seq, err := flowState.CreateMessage(now, p.payload, maxLen)
-	if err != nil {
-		return fmt.Errorf("error creating new message to probe target(%s): %v", f.target, err)
-	}
+	var seq uint64
 
-	if _, err := conn.WriteToUDP(msg, raddr); err != nil {
-		flowState.WithdrawMessage(seq)
-		return fmt.Errorf("unable to send to %s(%v): %v", f.target, raddr, err)
+	if p.pending != nil {
+		seq = p.pending.add(f, now)
+		if _, err := conn.WriteToUDP(p.payload, raddr); err != nil {
+			p.pending.withdraw(f, seq)
+			return fmt.Errorf("unable to send to %s(%v): %v", f.target, raddr, err)
+		}
+	} else {
+		flowState := p.fsm.FlowState(p.src, f.srcPort, f.target)
+		var msg []byte
+		var err error
+		msg, seq, err = flowState.CreateMessage(now, p.payload, maxLen)
+		if err != nil {
+			return fmt.Errorf("error creating new message to probe target(%s): %v", f.target, err)
+		}
+
+		if _, err := conn.WriteToUDP(msg, raddr); err != nil {
+			flowState.WithdrawMessage(seq)
+			return fmt.Errorf("unable to send to %s(%v): %v", f.target, raddr, err)
+		}
 	}
 	// Send packet over sentPackets channel
 	// May need to make a longer buffer for the channel.
@@ -404,6 +529,10 @@ func (p *Probe) runSingleProbe(f flow, conn *net.UDPConn, maxLen int, raddr *net
 	}
 }
 
+func (p *Probe) packetsInterval() time.Duration {
+	return time.Duration(p.c.GetPacketsIntervalMsec()) * time.Millisecond
+}
+
 // runProbe performs a single probe run. The main thread launches one goroutine
 // per target to probe. It manages a sync.WaitGroup and Wait's until all probes
 // have finished, then exits the runProbe method.
@@ -418,14 +547,18 @@ func (p *Probe) runProbe() {
 	}
 	maxLen := int(p.c.GetMaxLength())
 
-	var packetsPerTarget, initialConn int
+	// Packets are sent in rounds, packets_interval_msec apart. In each round,
+	// we send one packet from each transmit port if use_all_tx_ports_per_probe
+	// is set, and one packet otherwise.
+	var packetsPerRound, initialConn int
 	if p.c.GetUseAllTxPortsPerProbe() {
-		packetsPerTarget = len(p.connList)
+		packetsPerRound = len(p.connList)
 		initialConn = 0
 	} else {
-		packetsPerTarget = 1
+		packetsPerRound = 1
 		initialConn = int(p.runID % uint64(len(p.connList)))
 	}
+	packetsPerTarget := packetsPerRound * int(p.c.GetPacketsPerProbe())
 
 	var wg sync.WaitGroup
 	for _, conn := range p.connList {
@@ -447,16 +580,21 @@ func (p *Probe) runProbe() {
 			al.UpdateForTarget(target, ip.String(), dstPort)
 		}
 
+		if p.pending != nil {
+			p.pending.setTarget(&net.UDPAddr{IP: ip, Port: dstPort}, target.Name)
+		}
+
 		for i := 0; i < packetsPerTarget; i++ {
 			connID := (initialConn + i) % len(p.connList)
 			conn := p.connList[connID]
 			wg.Add(1)
-			go func(conn *net.UDPConn, f flow) {
+			go func(conn *net.UDPConn, f flow, delay time.Duration) {
 				defer wg.Done()
+				time.Sleep(delay)
 				if err := p.runSingleProbe(f, conn, maxLen, &net.UDPAddr{IP: ip, Port: dstPort}); err != nil {
 					p.l.Errorf("Probing %+v failed: %v", f, err)
 				}
-			}(conn, flow{p.srcPortList[connID], target.Name})
+			}(conn, flow{p.srcPortList[connID], target.Name}, time.Duration(i/packetsPerRound)*p.packetsInterval())
 		}
 	}
 	wg.Wait()
@@ -470,6 +608,9 @@ func (p *Probe) updateTargets() {
 		p.targets = p.targets[:p.c.GetMaxTargets()]
 	}
 	p.initProbeRunResults()
+	if p.pending != nil {
+		p.pending.retainTargets(p.targets)
+	}
 }
 
 // Start starts and runs the probe indefinitely.
@@ -503,6 +644,12 @@ func (p *Probe) Start(ctx context.Context, dataChan chan *metrics.EventMetrics)
 			}
 			return
 		case <-probeTicker.C:
+			// Wait for the outbound rate limits, if configured. Each target
+			// counts as one operation. An error here means that context was
+			// canceled, we'll return on the next iteration.
+			if err := p.opts.WaitForRateLimitN(ctx, len(p.targets)); err != nil {
+				continue
+			}
 			p.runProbe()
 		case <-flushTicker.C:
 			p.processPackets()
@@ -35,6 +35,8 @@ import (
 	configpb "github.com/cloudprober/cloudprober/probes/udp/proto"
 	"github.com/cloudprober/cloudprober/targets"
 	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/ipv4"
+	"golang.org/x/net/ipv6"
 	"google.golang.org/protobuf/proto"
 )
 
@@ -256,6 +258,112 @@ func TestSuccessMultipleCasesResultPerPort(t *testing.T) {
 	}
 }
 
+func TestPacketsPerProbe(t *testing.T) {
+	ctx, cancelServerCtx := context.WithCancel(context.Background())
+	port, scs := startUDPServer(ctx, t, false, 5*time.Millisecond)
+
+	conf := &configpb.ProbeConf{
+		Port:                proto.Int32(int32(port)),
+		PacketsPerProbe:     proto.Int32(4),
+		PacketsIntervalMsec: proto.Int32(10),
+		PayloadSize:         proto.Int32(512),
+	}
+
+	// 10 probes, sending 4 packets to the target in each probe.
+	probeCount, pktCount := 10, int64(40)
+	p := runProbe(t, 200*time.Millisecond, 100*time.Millisecond, probeCount, scs, conf)
+	cancelServerCtx()
+
+	res := p.res[flow{"", "localhost"}]
+	assert.GreaterOrEqual(t, res.total, pktCount/2, "total")
+	assert.Greater(t, res.total, int64(probeCount), "total should be more than one packet per probe")
+	assert.GreaterOrEqual(t, res.success, pktCount/2, "success")
+}
+
+func TestMatchStrategy(t *testing.T) {
+	for _, strategy := range []configpb.ProbeConf_MatchStrategy{configpb.ProbeConf_PAYLOAD, configpb.ProbeConf_ANY_RESPONSE} {
+		t.Run(strategy.String(), func(t *testing.T) {
+			ctx, cancelServerCtx := context.WithCancel(context.Background())
+			port, scs := startUDPServer(ctx, t, false, 5*time.Millisecond)
+
+			conf := &configpb.ProbeConf{
+				Port:          proto.Int32(int32(port)),
+				MatchStrategy: strategy.Enum(),
+				Payload:       proto.String("ping"),
+			}
+
+			probeCount := 10
+			p := runProbe(t, 200*time.Millisecond, 100*time.Millisecond, probeCount, scs, conf)
+			cancelServerCtx()
+
+			res := p.res[flow{"", "localhost"}]
+			assert.GreaterOrEqual(t, res.total, int64(probeCount/2), "total")
+			assert.GreaterOrEqual(t, res.success, int64(probeCount/2), "success")
+		})
+	}
+}
+
+func TestInitPacketsValidation(t *testing.T) {
+	for _, test := range []struct {
+		desc    string
+		c       *configpb.ProbeConf
+		wantErr bool
+	}{
+		{
+			desc:    "payload_too_big",
+			c:       &configpb.ProbeConf{PayloadSize: proto.Int32(1300)},
+			wantErr: true,
+		},
+		{
+			desc:    "zero_packets",
+			c:       &configpb.ProbeConf{PacketsPerProbe: proto.Int32(0)},
+			wantErr: true,
+		},
+		{
+			desc:    "packets_take_too_long",
+			c:       &configpb.ProbeConf{PacketsPerProbe: proto.Int32(11), PacketsIntervalMsec: proto.Int32(50)},
+			wantErr: true,
+		},
+		{
+			desc:    "payload_with_message_strategy",
+			c:       &configpb.ProbeConf{Payload: proto.String("ping")},
+			wantErr: true,
+		},
+		{
+			desc:    "no_payload_for_payload_strategy",
+			c:       &configpb.ProbeConf{MatchStrategy: configpb.ProbeConf_PAYLOAD.Enum()},
+			wantErr: true,
+		},
+		{
+			desc: "any_response_without_payload",
+			c:    &configpb.ProbeConf{MatchStrategy: configpb.ProbeConf_ANY_RESPONSE.Enum(), NumTxPorts: proto.Int32(1)},
+		},
+		{
+			desc: "valid",
+			c:    &configpb.ProbeConf{PacketsPerProbe: proto.Int32(10), PacketsIntervalMsec: proto.Int32(50), PayloadSize: proto.Int32(1000), NumTxPorts: proto.Int32(1)},
+		},
+	} {
+		t.Run(test.desc, func(t *testing.T) {
+			p := &Probe{}
+			opts := &options.Options{
+				ProbeConf:           test.c,
+				Interval:            time.Second,
+				Timeout:             time.Second,
+				StatsExportInterval: 10 * time.Second,
+			}
+			err := p.Init("udp_test", opts)
+			if test.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			for _, conn := range p.connList {
+				conn.Close()
+			}
+		})
+	}
+}
+
 func extractMetric(em *metrics.EventMetrics, key string) int64 {
 	return em.Metric(key).(*metrics.Int).Int64()
 }
@@ -350,3 +458,31 @@ func TestLossAndDelayed(t *testing.T) {
 		})
 	}
 }
+
+func TestSetTOS(t *testing.T) {
+	for _, network := range []string{"udp", "udp4"} {
+		t.Run(network, func(t *testing.T) {
+			conn, err := net.ListenUDP(network, nil)
+			if err != nil {
+				t.Skipf("Listening on %s not supported: %v", network, err)
+			}
+			defer conn.Close()
+
+			assert.NoError(t, setTOS(conn, 184))
+
+			tos4, err4 := ipv4.NewConn(conn).TOS()
+			tc6, err6 := ipv6.NewConn(conn).TrafficClass()
+			assert.True(t, (err4 == nil && tos4 == 184) || (err6 == nil && tc6 == 184), "TOS: %d (%v), traffic class: %d (%v)", tos4, err4, tc6, err6)
+		})
+	}
+}
+
+func TestInitTOSValidation(t *testing.T) {
+	p := &Probe{}
+	opts := &options.Options{
+		ProbeConf: &configpb.ProbeConf{Tos: proto.Int32(256)},
+		Interval:  time.Second,
+		Timeout:   time.Second,
+	}
+	assert.Error(t, p.Init("udp_test", opts))
+}
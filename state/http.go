@@ -15,6 +15,7 @@
 package state
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"net/http"
@@ -29,6 +30,7 @@ func SetDefaultHTTPServeMux(mux *http.ServeMux) {
 	defer st.Unlock()
 	st.httpServeMux = mux
 	st.webURLs = make([]string, 0)
+	st.ctxHandlers = make(map[string]*ctxWebHandler)
 }
 
 // DefaultHTTPServeMux returns the default HTTP ServeMux.
@@ -46,6 +48,11 @@ func IsHandled(url string) bool {
 		return false
 	}
 
+	// Handlers tied to a canceled context are not serving anymore.
+	if h := st.ctxHandlers[url]; h != nil && h.ctx.Err() != nil {
+		return false
+	}
+
 	_, matchedPattern := st.httpServeMux.Handler(httptest.NewRequest("", url, nil))
 	return matchedPattern == url
 }
@@ -74,6 +81,63 @@ func AddWebHandler(path string, f func(w http.ResponseWriter, r *http.Request))
 	return nil
 }
 
+// ctxWebHandler is a web handler that is active only until its context is
+// canceled.
+type ctxWebHandler struct {
+	ctx context.Context
+	f   func(w http.ResponseWriter, r *http.Request)
+}
+
+// AddWebHandlerWithContext is like AddWebHandler, but the handler is tied to
+// the given context: once the context is canceled, handler stops serving
+// requests and the path can be registered again. This is used by the
+// components that can be re-created at runtime, e.g. surfacers on config
+// reload.
+func AddWebHandlerWithContext(ctx context.Context, path string, f func(w http.ResponseWriter, r *http.Request)) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+
+	st.Lock()
+	defer st.Unlock()
+
+	if st.httpServeMux == nil {
+		return errors.New("default http server not initialized")
+	}
+
+	// Path was registered before, take it over if previous handler is gone.
+	if h := st.ctxHandlers[path]; h != nil {
+		if h.ctx.Err() == nil {
+			return fmt.Errorf("path %s already registered", path)
+		}
+		h.ctx, h.f = ctx, f
+		return nil
+	}
+
+	if slices.Contains(st.webURLs, path) {
+		return fmt.Errorf("path %s already registered", path)
+	}
+
+	h := &ctxWebHandler{ctx: ctx, f: f}
+	st.httpServeMux.HandleFunc(path, func(w http.ResponseWriter, r *http.Request) {
+		st.RLock()
+		ctx, f := h.ctx, h.f
+		st.RUnlock()
+
+		if ctx.Err() != nil {
+			http.NotFound(w, r)
+			return
+		}
+		f(w, r)
+	})
+	st.ctxHandlers[path] = h
+	st.webURLs = append(st.webURLs, path)
+
+	return nil
+}
+
 func AllLinks() []string {
 	st.RLock()
 	defer st.RUnlock()
@@ -15,7 +15,9 @@
 package state
 
 import (
+	"context"
 	"net/http"
+	"net/http/httptest"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -70,3 +72,40 @@ func TestAllLinks(t *testing.T) {
 	AddWebHandler("/another-test", func(w http.ResponseWriter, r *http.Request) {})
 	assert.Equal(t, []string{"/test", "/another-test"}, AllLinks())
 }
+
+func TestAddWebHandlerWithContext(t *testing.T) {
+	SetDefaultHTTPServeMux(http.NewServeMux())
+	defer SetDefaultHTTPServeMux(nil)
+
+	serve := func(path string) (int, string) {
+		w := httptest.NewRecorder()
+		DefaultHTTPServeMux().ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+		return w.Code, w.Body.String()
+	}
+
+	ctx1, cancel1 := context.WithCancel(context.Background())
+	assert.NoError(t, AddWebHandlerWithContext(ctx1, "/test", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("handler1"))
+	}))
+	code, body := serve("/test")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "handler1", body)
+
+	// Path is in use, should fail.
+	assert.Error(t, AddWebHandlerWithContext(context.Background(), "/test", func(w http.ResponseWriter, r *http.Request) {}))
+	assert.Error(t, AddWebHandler("/test", func(w http.ResponseWriter, r *http.Request) {}))
+
+	// Once context is canceled, handler stops serving and path can be
+	// taken over.
+	cancel1()
+	code, _ = serve("/test")
+	assert.Equal(t, http.StatusNotFound, code)
+
+	assert.NoError(t, AddWebHandlerWithContext(context.Background(), "/test", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("handler2"))
+	}))
+	code, body = serve("/test")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "handler2", body)
+	assert.Equal(t, []string{"/test"}, AllLinks())
+}
@@ -25,11 +25,14 @@ package surfacers
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"html/template"
 	"log/slog"
 	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/cloudprober/cloudprober/internal/surfacers/bigquery"
 	"github.com/cloudprober/cloudprober/internal/surfacers/cloudwatch"
@@ -41,6 +44,7 @@ import (
 	"github.com/cloudprober/cloudprober/internal/surfacers/probestatus"
 	"github.com/cloudprober/cloudprober/internal/surfacers/prometheus"
 	"github.com/cloudprober/cloudprober/internal/surfacers/pubsub"
+	"github.com/cloudprober/cloudprober/internal/surfacers/snmp"
 	"github.com/cloudprober/cloudprober/internal/surfacers/stackdriver"
 	"github.com/cloudprober/cloudprober/logger"
 	"github.com/cloudprober/cloudprober/metrics"
@@ -54,14 +58,23 @@ import (
 
 type surfacerFunc func(any) (Surfacer, error)
 
+// Factory creates a user defined surfacer. It's called with the surfacer's
+// user_defined_config (opaque config blob) and the common surfacer options.
+type Factory func(ctx context.Context, config string, opts *options.Options) (Surfacer, error)
+
 var (
 	userDefinedSurfacers   = make(map[string]Surfacer)
+	userDefinedFactories   = make(map[string]Factory)
 	userDefinedSurfacersMu sync.RWMutex
 
 	extensionMap   = make(map[int]surfacerFunc)
 	extensionMapMu sync.RWMutex
 )
 
+// Maximum time given to the removed surfacers to process the data they have
+// already received, before they are closed on config reload.
+var surfacerDrainTime = 2 * time.Second
+
 // StatusTmpl variable stores the HTML template suitable to generate the
 // surfacers' status for cloudprober's /status page. It expects an array of
 // SurfacerInfo objects as input.
@@ -71,6 +84,8 @@ var StatusTmpl = template.Must(template.New("statusTmpl").Parse(`
     <th>Type</th>
     <th>Name</th>
     <th>Conf</th>
+    <th>Queue Depth</th>
+    <th>Dropped</th>
   </tr>
   {{ range . }}
   <tr>
@@ -83,6 +98,8 @@ var StatusTmpl = template.Must(template.New("statusTmpl").Parse(`
       default
     {{end}}
     </td>
+    <td>{{.QueueDepth}}</td>
+    <td>{{.Dropped}}</td>
   </tr>
   {{ end }}
 </table>
@@ -123,6 +140,14 @@ type Surfacer interface {
 	Write(ctx context.Context, em *metrics.EventMetrics)
 }
 
+// Flusher is implemented by the surfacers that buffer data internally, e.g.
+// to write it in batches. Flush writes out the buffered data, and returns once
+// it's done or the context is canceled. It's called before a surfacer is
+// closed on config reload.
+type Flusher interface {
+	Flush(ctx context.Context)
+}
+
 type surfacerWrapper struct {
 	Surfacer
 	opts    *options.Options
@@ -157,6 +182,13 @@ func (sw *surfacerWrapper) Write(ctx context.Context, em *metrics.EventMetrics)
 	sw.Surfacer.Write(ctx, em)
 }
 
+// Flush flushes the underlying surfacer, if it buffers data.
+func (sw *surfacerWrapper) Flush(ctx context.Context) {
+	if f, ok := sw.Surfacer.(Flusher); ok {
+		f.Flush(ctx)
+	}
+}
+
 // SurfacerInfo encapsulates a Surfacer and related info.
 type SurfacerInfo struct {
 	Surfacer
@@ -164,6 +196,109 @@ type SurfacerInfo struct {
 	Name        string
 	SurfacerDef *surfacerpb.SurfacerDef
 	Conf        string
+
+	def    *surfacerpb.SurfacerDef // Definition, including for required surfacers.
+	cancel context.CancelFunc
+
+	// Write queue, drained by the surfacer's own writer goroutine, the
+	// number of EventMetrics queued but not written yet, and the number of
+	// EventMetrics dropped because the queue was full.
+	queue   chan *metrics.EventMetrics
+	pending atomic.Int64
+	dropped atomic.Int64
+
+	// Number of writes by the writer goroutine, and total time spent in them.
+	writes       atomic.Int64
+	writeLatency atomic.Int64
+}
+
+// Close stops the surfacer by canceling its context.
+func (si *SurfacerInfo) Close() {
+	if si.cancel != nil {
+		si.cancel()
+	}
+}
+
+// startWriter creates the surfacer's write queue and starts the goroutine
+// that writes the queued EventMetrics to the surfacer.
+func (si *SurfacerInfo) startWriter(ctx context.Context, size int) {
+	si.queue = make(chan *metrics.EventMetrics, size)
+
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case em := <-si.queue:
+				start := time.Now()
+				si.Surfacer.Write(ctx, em)
+				si.writeLatency.Add(int64(time.Since(start)))
+				si.writes.Add(1)
+				si.pending.Add(-1)
+			}
+		}
+	}()
+}
+
+// drain waits until the EventMetrics already queued for the surfacer have
+// been written, and the surfacer has flushed its own buffer (if it has one),
+// or the timeout expires.
+func (si *SurfacerInfo) drain(timeout time.Duration) {
+	deadline := time.Now().Add(timeout)
+	for si.pending.Load() > 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if f, ok := si.Surfacer.(Flusher); ok {
+		ctx, cancel := context.WithDeadline(context.Background(), deadline)
+		defer cancel()
+		f.Flush(ctx)
+	}
+}
+
+// Write queues the EventMetrics for the surfacer's writer goroutine, so that
+// a slow surfacer doesn't hold up the other surfacers. If the queue is full,
+// EventMetrics is dropped. Surfacers write to the EventMetrics they receive
+// (e.g. add labels), so each surfacer gets its own copy.
+//
+// SurfacerInfo not created through Init (e.g. in tests) writes to the
+// surfacer synchronously.
+func (si *SurfacerInfo) Write(ctx context.Context, em *metrics.EventMetrics) {
+	if si.queue == nil {
+		si.Surfacer.Write(ctx, em)
+		return
+	}
+
+	si.pending.Add(1)
+	select {
+	case si.queue <- em.Clone():
+	default:
+		si.pending.Add(-1)
+		si.dropped.Add(1)
+	}
+}
+
+// QueueDepth returns the number of EventMetrics waiting in the surfacer's
+// write queue.
+func (si *SurfacerInfo) QueueDepth() int {
+	return len(si.queue)
+}
+
+// QueueCapacity returns the capacity of the surfacer's write queue.
+func (si *SurfacerInfo) QueueCapacity() int {
+	return cap(si.queue)
+}
+
+// WriteLatency returns the number of writes to the surfacer so far, and the
+// total time spent in them.
+func (si *SurfacerInfo) WriteLatency() (writes int64, total time.Duration) {
+	return si.writes.Load(), time.Duration(si.writeLatency.Load())
+}
+
+// Dropped returns the number of EventMetrics dropped so far because the
+// surfacer's write queue was full.
+func (si *SurfacerInfo) Dropped() int64 {
+	return si.dropped.Load()
 }
 
 func inferType(s *surfacerpb.SurfacerDef) surfacerpb.Type {
@@ -188,6 +323,10 @@ func inferType(s *surfacerpb.SurfacerDef) surfacerpb.Type {
 		return surfacerpb.Type_BIGQUERY
 	case *surfacerpb.SurfacerDef_OtelSurfacer:
 		return surfacerpb.Type_OTEL
+	case *surfacerpb.SurfacerDef_SnmpSurfacer:
+		return surfacerpb.Type_SNMP
+	case *surfacerpb.SurfacerDef_UserDefinedConfig:
+		return surfacerpb.Type_USER_DEFINED
 	}
 
 	return surfacerpb.Type_NONE
@@ -231,13 +370,10 @@ func initSurfacer(ctx context.Context, s *surfacerpb.SurfacerDef, sType surfacer
 		surfacer, err = bigquery.New(ctx, s.GetBigquerySurfacer(), opts, l)
 	case surfacerpb.Type_OTEL:
 		surfacer, err = otel.New(ctx, s.GetOtelSurfacer(), opts, l)
+	case surfacerpb.Type_SNMP:
+		surfacer, err = snmp.New(ctx, s.GetSnmpSurfacer(), opts, l)
 	case surfacerpb.Type_USER_DEFINED:
-		userDefinedSurfacersMu.Lock()
-		defer userDefinedSurfacersMu.Unlock()
-		surfacer = userDefinedSurfacers[s.GetName()]
-		if surfacer == nil {
-			return nil, fmt.Errorf("unregistered user defined surfacer: %s", s.GetName())
-		}
+		surfacer, err = userDefinedSurfacer(ctx, s, opts)
 	case surfacerpb.Type_EXTENSION:
 		surfacer, _, err = getExtensionSurfacer(s)
 	default:
@@ -251,6 +387,24 @@ func initSurfacer(ctx context.Context, s *surfacerpb.SurfacerDef, sType surfacer
 	}, err
 }
 
+// userDefinedSurfacer returns the user defined surfacer for the given config.
+// Factories take precedence over the surfacer instances registered through
+// Register().
+func userDefinedSurfacer(ctx context.Context, s *surfacerpb.SurfacerDef, opts *options.Options) (Surfacer, error) {
+	userDefinedSurfacersMu.RLock()
+	factory := userDefinedFactories[s.GetName()]
+	surfacer := userDefinedSurfacers[s.GetName()]
+	userDefinedSurfacersMu.RUnlock()
+
+	if factory != nil {
+		return factory(ctx, s.GetUserDefinedConfig(), opts)
+	}
+	if surfacer == nil {
+		return nil, fmt.Errorf("unregistered user defined surfacer: %s", s.GetName())
+	}
+	return surfacer, nil
+}
+
 func getExtensionSurfacer(p *surfacerpb.SurfacerDef) (Surfacer, any, error) {
 	extensionMapMu.RLock()
 	defer extensionMapMu.RUnlock()
@@ -278,9 +432,19 @@ func getExtensionSurfacer(p *surfacerpb.SurfacerDef) (Surfacer, any, error) {
 	return surfacer, value, nil
 }
 
-// Init initializes the surfacers from the config protobufs and returns them as
-// a list.
-func Init(ctx context.Context, sDefs []*surfacerpb.SurfacerDef) ([]*SurfacerInfo, error) {
+// surfacerDef is a surfacer definition, resolved to its type.
+type surfacerDef struct {
+	def   *surfacerpb.SurfacerDef
+	sType surfacerpb.Type
+
+	// Required surfacers are added automatically, and are not reported
+	// with their definitions.
+	required bool
+}
+
+// resolveDefs returns the surfacers that should run for the given surfacer
+// definitions, after applying the default and the required surfacers.
+func resolveDefs(sDefs []*surfacerpb.SurfacerDef) ([]*surfacerDef, error) {
 	// If no surfacers are defined, return default surfacers. This behavior
 	// can be disabled by explicitly specifying "surfacer {}" in the config.
 	if len(sDefs) == 0 {
@@ -289,7 +453,7 @@ func Init(ctx context.Context, sDefs []*surfacerpb.SurfacerDef) ([]*SurfacerInfo
 
 	foundSurfacers := make(map[surfacerpb.Type]bool)
 
-	var result []*SurfacerInfo
+	var result []*surfacerDef
 	for _, sDef := range sDefs {
 		sType := sDef.GetType()
 
@@ -307,36 +471,167 @@ func Init(ctx context.Context, sDefs []*surfacerpb.SurfacerDef) ([]*SurfacerInfo
 		if sType == surfacerpb.Type_PROBESTATUS && foundSurfacers[sType] {
 			return nil, fmt.Errorf("probestatus surfacer cannot be defined more than once")
 		}
+		foundSurfacers[sType] = true
+
+		result = append(result, &surfacerDef{def: sDef, sType: sType})
+	}
+
+	for _, s := range requiredSurfacers {
+		if !foundSurfacers[s.GetType()] {
+			result = append(result, &surfacerDef{def: s, sType: s.GetType(), required: true})
+		}
+	}
+	return result, nil
+}
 
-		s, err := initSurfacer(ctx, sDef, sType)
+// newSurfacerInfo initializes the surfacer for the given definition. Each
+// surfacer gets its own context, so that it can be stopped independently.
+func newSurfacerInfo(ctx context.Context, sd *surfacerDef) (*SurfacerInfo, error) {
+	sCtx, cancel := context.WithCancel(ctx)
+
+	s, err := initSurfacer(sCtx, sd.def, sd.sType)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	si := &SurfacerInfo{
+		Surfacer: s,
+		Type:     sd.sType.String(),
+		def:      sd.def,
+		cancel:   cancel,
+	}
+	si.startWriter(sCtx, int(sd.def.GetMetricsBufferSize()))
+	if !sd.required {
+		si.Name = sd.def.GetName()
+		si.SurfacerDef = sd.def
+		si.Conf = formatutils.ConfToString(sd.def)
+	}
+	return si, nil
+}
+
+// Init initializes the surfacers from the config protobufs and returns them as
+// a list.
+func Init(ctx context.Context, sDefs []*surfacerpb.SurfacerDef) ([]*SurfacerInfo, error) {
+	defs, err := resolveDefs(sDefs)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []*SurfacerInfo
+	for _, sd := range defs {
+		si, err := newSurfacerInfo(ctx, sd)
 		if err != nil {
 			return nil, err
 		}
+		result = append(result, si)
+	}
+	return result, nil
+}
 
-		foundSurfacers[sType] = true
+// Validate initializes the surfacers for the given definitions, one by one,
+// and closes them right away. It's used to validate the surfacers config,
+// e.g. credentials, without running the surfacers. Returned error includes
+// errors for all surfacers that failed to initialize.
+func Validate(ctx context.Context, sDefs []*surfacerpb.SurfacerDef) error {
+	defs, err := resolveDefs(sDefs)
+	if err != nil {
+		return err
+	}
 
-		result = append(result, &SurfacerInfo{
-			Surfacer:    s,
-			Type:        sType.String(),
-			Name:        sDef.GetName(),
-			SurfacerDef: sDef,
-			Conf:        formatutils.ConfToString(sDef),
-		})
+	var errs []error
+	for _, sd := range defs {
+		name := sd.def.GetName()
+		if name == "" {
+			name = strings.ToLower(sd.sType.String())
+		}
+
+		sCtx, cancel := context.WithCancel(ctx)
+		if _, err := initSurfacer(sCtx, sd.def, sd.sType); err != nil {
+			errs = append(errs, fmt.Errorf("surfacer %s: %v", name, err))
+		}
+		cancel()
 	}
+	return errors.Join(errs...)
+}
 
-	for _, s := range requiredSurfacers {
-		if !foundSurfacers[s.GetType()] {
-			surfacer, err := initSurfacer(ctx, s, s.GetType())
-			if err != nil {
-				return nil, err
+// Reload updates the running surfacers to match the new surfacer
+// definitions, and returns the updated surfacers list. Surfacers with
+// unchanged definitions are carried over as is, with their state intact.
+// Surfacers that are not needed anymore (including the changed ones) are
+// detached, drained and closed, before the new surfacers are initialized, so
+// that the new surfacers can take over their resources, e.g. web handlers.
+//
+// setSurfacers is used to update the fan-out: it's called with the surfacers
+// that are being kept before closing the removed surfacers, and with the final
+// list at the end. If some of the new surfacers fail to initialize, Reload
+// returns an error, along with the surfacers that are running.
+func Reload(ctx context.Context, current []*SurfacerInfo, sDefs []*surfacerpb.SurfacerDef, setSurfacers func([]*SurfacerInfo), l *logger.Logger) ([]*SurfacerInfo, error) {
+	defs, err := resolveDefs(sDefs)
+	if err != nil {
+		return current, err
+	}
+
+	// Match new definitions with the running surfacers.
+	matched := make([]*SurfacerInfo, len(defs))
+	kept := make(map[*SurfacerInfo]bool)
+	for i, sd := range defs {
+		for _, si := range current {
+			if !kept[si] && si.def != nil && proto.Equal(si.def, sd.def) {
+				matched[i], kept[si] = si, true
+				break
 			}
-			result = append(result, &SurfacerInfo{
-				Surfacer: surfacer,
-				Type:     s.GetType().String(),
-			})
 		}
 	}
-	return result, nil
+
+	var keptList, removed []*SurfacerInfo
+	for _, si := range current {
+		if kept[si] {
+			keptList = append(keptList, si)
+		} else {
+			removed = append(removed, si)
+		}
+	}
+
+	if len(removed) != 0 {
+		setSurfacers(keptList)
+
+		// Give removed surfacers a chance to process the data they have
+		// already received. Surfacers are drained in parallel, and we move on
+		// as soon as they are done, instead of always waiting for the full
+		// drain time.
+		var wg sync.WaitGroup
+		for _, si := range removed {
+			wg.Add(1)
+			go func(si *SurfacerInfo) {
+				defer wg.Done()
+				si.drain(surfacerDrainTime)
+				l.Infof("Config reload: closing surfacer: %s (%s)", si.Name, si.Type)
+				si.Close()
+			}(si)
+		}
+		wg.Wait()
+	}
+
+	var errs error
+	var result []*SurfacerInfo
+	for i, sd := range defs {
+		if matched[i] != nil {
+			result = append(result, matched[i])
+			continue
+		}
+
+		l.Infof("Config reload: initializing surfacer: %s (%s)", sd.def.GetName(), sd.sType)
+		si, err := newSurfacerInfo(ctx, sd)
+		if err != nil {
+			errs = errors.Join(errs, fmt.Errorf("error initializing surfacer %s (%s): %v", sd.def.GetName(), sd.sType, err))
+			continue
+		}
+		result = append(result, si)
+	}
+
+	setSurfacers(result)
+	return result, errs
 }
 
 // Register allows you to register a user defined surfacer with cloudprober.
@@ -359,6 +654,29 @@ func Register(name string, s Surfacer) {
 	userDefinedSurfacers[name] = s
 }
 
+// RegisterFactory registers a factory for a user defined surfacer. Unlike
+// Register, surfacer is created by cloudprober at the initialization time,
+// using the surfacer's config. This makes it possible to configure the same
+// surfacer implementation differently from the config file.
+//
+// Example usage:
+//
+//	surfacers.RegisterFactory("fancy_surfacer", func(ctx context.Context, conf string, opts *options.Options) (surfacers.Surfacer, error) {
+//		return NewFancySurfacer(ctx, conf, opts.Logger)
+//	})
+//
+// Corresponding surfacer config:
+//
+//	surfacer {
+//	  name: "fancy_surfacer"
+//	  user_defined_config: "{\"endpoint\": \"metrics.internal:8080\"}"
+//	}
+func RegisterFactory(name string, f Factory) {
+	userDefinedSurfacersMu.Lock()
+	defer userDefinedSurfacersMu.Unlock()
+	userDefinedFactories[name] = f
+}
+
 // RegisterSurfacerType registers a new surfacer-type. New surfacer types are
 // integrated with the config subsystem using the protobuf extensions.
 //
@@ -15,6 +15,7 @@
 package surfacers
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"net/http"
@@ -25,6 +26,7 @@ import (
 	surfacerpb "github.com/cloudprober/cloudprober/internal/surfacers/proto"
 	"github.com/cloudprober/cloudprober/metrics"
 	"github.com/cloudprober/cloudprober/state"
+	"github.com/cloudprober/cloudprober/surfacers/options"
 	testdatapb "github.com/cloudprober/cloudprober/surfacers/testdata"
 	"github.com/stretchr/testify/assert"
 	"google.golang.org/protobuf/proto"
@@ -65,20 +67,22 @@ func TestEmptyConfig(t *testing.T) {
 
 func TestInferType(t *testing.T) {
 	typeToConf := map[string]*surfacerpb.SurfacerDef{
-		"CLOUDWATCH":  {Surfacer: &surfacerpb.SurfacerDef_CloudwatchSurfacer{}},
-		"DATADOG":     {Surfacer: &surfacerpb.SurfacerDef_DatadogSurfacer{}},
-		"FILE":        {Surfacer: &surfacerpb.SurfacerDef_FileSurfacer{}},
-		"POSTGRES":    {Surfacer: &surfacerpb.SurfacerDef_PostgresSurfacer{}},
-		"PROBESTATUS": {Surfacer: &surfacerpb.SurfacerDef_ProbestatusSurfacer{}},
-		"PROMETHEUS":  {Surfacer: &surfacerpb.SurfacerDef_PrometheusSurfacer{}},
-		"PUBSUB":      {Surfacer: &surfacerpb.SurfacerDef_PubsubSurfacer{}},
-		"STACKDRIVER": {Surfacer: &surfacerpb.SurfacerDef_StackdriverSurfacer{}},
-		"BIGQUERY":    {Surfacer: &surfacerpb.SurfacerDef_BigquerySurfacer{}},
-		"OTEL":        {Surfacer: &surfacerpb.SurfacerDef_OtelSurfacer{}},
+		"CLOUDWATCH":   {Surfacer: &surfacerpb.SurfacerDef_CloudwatchSurfacer{}},
+		"DATADOG":      {Surfacer: &surfacerpb.SurfacerDef_DatadogSurfacer{}},
+		"FILE":         {Surfacer: &surfacerpb.SurfacerDef_FileSurfacer{}},
+		"POSTGRES":     {Surfacer: &surfacerpb.SurfacerDef_PostgresSurfacer{}},
+		"PROBESTATUS":  {Surfacer: &surfacerpb.SurfacerDef_ProbestatusSurfacer{}},
+		"PROMETHEUS":   {Surfacer: &surfacerpb.SurfacerDef_PrometheusSurfacer{}},
+		"PUBSUB":       {Surfacer: &surfacerpb.SurfacerDef_PubsubSurfacer{}},
+		"STACKDRIVER":  {Surfacer: &surfacerpb.SurfacerDef_StackdriverSurfacer{}},
+		"BIGQUERY":     {Surfacer: &surfacerpb.SurfacerDef_BigquerySurfacer{}},
+		"OTEL":         {Surfacer: &surfacerpb.SurfacerDef_OtelSurfacer{}},
+		"SNMP":         {Surfacer: &surfacerpb.SurfacerDef_SnmpSurfacer{}},
+		"USER_DEFINED": {Surfacer: &surfacerpb.SurfacerDef_UserDefinedConfig{}},
 	}
 
 	for k := range surfacerpb.Type_value {
-		if k == "NONE" || k == "USER_DEFINED" || k == "EXTENSION" {
+		if k == "NONE" || k == "EXTENSION" {
 			continue
 		}
 		if typeToConf[k] == nil {
@@ -176,6 +180,75 @@ func TestUserDefinedAndFiltering(t *testing.T) {
 	}
 }
 
+func TestUserDefinedFactory(t *testing.T) {
+	defer state.SetDefaultHTTPServeMux(nil)
+
+	gotConf := make(map[string]string)
+	RegisterFactory("fs", func(ctx context.Context, conf string, opts *options.Options) (Surfacer, error) {
+		if conf == "bad" {
+			return nil, fmt.Errorf("bad config")
+		}
+		gotConf[opts.Config.GetName()] = conf
+		return &testSurfacer{}, nil
+	})
+
+	tests := []struct {
+		name    string
+		sdef    *surfacerpb.SurfacerDef
+		wantErr bool
+	}{
+		{
+			name: "explicit_type",
+			sdef: &surfacerpb.SurfacerDef{
+				Name:     proto.String("fs"),
+				Type:     surfacerpb.Type_USER_DEFINED.Enum(),
+				Surfacer: &surfacerpb.SurfacerDef_UserDefinedConfig{UserDefinedConfig: "endpoint=x"},
+			},
+		},
+		{
+			name: "inferred_type",
+			sdef: &surfacerpb.SurfacerDef{
+				Name:     proto.String("fs"),
+				Surfacer: &surfacerpb.SurfacerDef_UserDefinedConfig{UserDefinedConfig: "endpoint=y"},
+			},
+		},
+		{
+			name: "factory_error",
+			sdef: &surfacerpb.SurfacerDef{
+				Name:     proto.String("fs"),
+				Surfacer: &surfacerpb.SurfacerDef_UserDefinedConfig{UserDefinedConfig: "bad"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "unregistered",
+			sdef: &surfacerpb.SurfacerDef{
+				Name:     proto.String("fs-unknown"),
+				Surfacer: &surfacerpb.SurfacerDef_UserDefinedConfig{UserDefinedConfig: "endpoint=z"},
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Default surfacers register HTTP handlers, a fresh mux avoids
+			// duplicate registrations across subtests.
+			state.SetDefaultHTTPServeMux(http.NewServeMux())
+
+			si, err := Init(context.Background(), []*surfacerpb.SurfacerDef{tt.sdef})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Init() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			assert.Equal(t, "USER_DEFINED", si[0].Type)
+			assert.Equal(t, tt.sdef.GetUserDefinedConfig(), gotConf["fs"])
+		})
+	}
+}
+
 func TestFailureMetric(t *testing.T) {
 	state.SetDefaultHTTPServeMux(http.NewServeMux())
 
@@ -362,3 +435,249 @@ func TestExtensionSurfacer(t *testing.T) {
 		assert.Equal(t, em.String(), ts.received[i].String())
 	}
 }
+
+type ctxSurfacer struct {
+	testSurfacer
+	ctx  context.Context
+	conf string
+}
+
+func TestReload(t *testing.T) {
+	state.SetDefaultHTTPServeMux(http.NewServeMux())
+
+	oldDrainTime := surfacerDrainTime
+	surfacerDrainTime = 0
+	defer func() { surfacerDrainTime = oldDrainTime }()
+
+	RegisterFactory("reload_fs", func(ctx context.Context, conf string, opts *options.Options) (Surfacer, error) {
+		if conf == "bad" {
+			return nil, fmt.Errorf("bad config")
+		}
+		return &ctxSurfacer{ctx: ctx, conf: conf}, nil
+	})
+	sDef := func(name, conf string) *surfacerpb.SurfacerDef {
+		return &surfacerpb.SurfacerDef{
+			Name:     proto.String(name),
+			Type:     surfacerpb.Type_USER_DEFINED.Enum(),
+			Surfacer: &surfacerpb.SurfacerDef_UserDefinedConfig{UserDefinedConfig: conf},
+		}
+	}
+	// Surfacer name is used to look up the factory, so we differentiate
+	// surfacers using the config.
+	cs := func(si *SurfacerInfo) *ctxSurfacer {
+		return si.Surfacer.(*surfacerWrapper).Surfacer.(*ctxSurfacer)
+	}
+	confs := func(sis []*SurfacerInfo) []string {
+		var out []string
+		for _, si := range sis {
+			if si.Type == "USER_DEFINED" {
+				out = append(out, cs(si).conf)
+			} else {
+				out = append(out, si.Type)
+			}
+		}
+		return out
+	}
+
+	current, err := Init(context.Background(), []*surfacerpb.SurfacerDef{
+		sDef("reload_fs", "keep"),
+		sDef("reload_fs", "change"),
+		sDef("reload_fs", "remove"),
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"keep", "change", "remove", "PROBESTATUS"}, confs(current))
+
+	var fanOut [][]string
+	setSurfacers := func(sis []*SurfacerInfo) { fanOut = append(fanOut, confs(sis)) }
+
+	got, err := Reload(context.Background(), current, []*surfacerpb.SurfacerDef{
+		sDef("reload_fs", "keep"),
+		sDef("reload_fs", "change2"),
+		sDef("reload_fs", "new"),
+		sDef("reload_fs", "bad"),
+	}, setSurfacers, nil)
+	assert.Error(t, err, "expected error for the bad surfacer")
+	assert.Equal(t, []string{"keep", "change2", "new", "PROBESTATUS"}, confs(got))
+
+	// Removed surfacers should be detached first, before new surfacers are
+	// added.
+	assert.Equal(t, [][]string{{"keep", "PROBESTATUS"}, {"keep", "change2", "new", "PROBESTATUS"}}, fanOut)
+
+	// Unchanged surfacers are carried over as is.
+	assert.Same(t, current[0], got[0])
+	assert.Same(t, current[3], got[3])
+	assert.NoError(t, cs(got[0]).ctx.Err())
+
+	// Removed and changed surfacers are closed.
+	assert.Error(t, cs(current[1]).ctx.Err())
+	assert.Error(t, cs(current[2]).ctx.Err())
+	assert.NoError(t, cs(got[1]).ctx.Err())
+
+	// Invalid config, nothing should change.
+	fanOut = nil
+	got2, err := Reload(context.Background(), got, []*surfacerpb.SurfacerDef{
+		{Type: surfacerpb.Type_PROBESTATUS.Enum()},
+		{Type: surfacerpb.Type_PROBESTATUS.Enum()},
+	}, setSurfacers, nil)
+	assert.Error(t, err)
+	assert.Equal(t, got, got2)
+	assert.Nil(t, fanOut)
+}
+
+// blockingSurfacer blocks in Write until unblocked.
+type blockingSurfacer struct {
+	received chan *metrics.EventMetrics
+	unblock  chan struct{}
+}
+
+func (bs *blockingSurfacer) Write(ctx context.Context, em *metrics.EventMetrics) {
+	bs.received <- em
+	<-bs.unblock
+}
+
+func TestSurfacerQueue(t *testing.T) {
+	state.SetDefaultHTTPServeMux(http.NewServeMux())
+
+	bs := &blockingSurfacer{
+		received: make(chan *metrics.EventMetrics, 10),
+		unblock:  make(chan struct{}),
+	}
+	fast := &blockingSurfacer{
+		received: make(chan *metrics.EventMetrics, 10),
+		unblock:  make(chan struct{}),
+	}
+	close(fast.unblock)
+
+	RegisterFactory("queue_fs", func(ctx context.Context, conf string, opts *options.Options) (Surfacer, error) {
+		if conf == "slow" {
+			return bs, nil
+		}
+		return fast, nil
+	})
+	sDef := func(conf string, bufferSize int64) *surfacerpb.SurfacerDef {
+		return &surfacerpb.SurfacerDef{
+			Name:              proto.String("queue_fs"),
+			MetricsBufferSize: proto.Int64(bufferSize),
+			AddFailureMetric:  proto.Bool(false),
+			Surfacer:          &surfacerpb.SurfacerDef_UserDefinedConfig{UserDefinedConfig: conf},
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	// Fast surfacer's queue should hold all the writes below, as they come in
+	// a burst, faster than the surfacer's writer goroutine may pick them up.
+	sis, err := Init(ctx, []*surfacerpb.SurfacerDef{sDef("slow", 2), sDef("fast", 5)})
+	assert.NoError(t, err)
+	slowSI, fastSI := sis[0], sis[1]
+
+	write := func() {
+		em := metrics.NewEventMetrics(time.Now()).AddMetric("total", metrics.NewInt(1))
+		slowSI.Write(ctx, em)
+		fastSI.Write(ctx, em)
+	}
+
+	// First EventMetrics gets the slow surfacer stuck.
+	write()
+	<-bs.received
+
+	// Next two fill up the queue, rest are dropped.
+	for i := 0; i < 4; i++ {
+		write()
+	}
+	assert.Equal(t, 2, slowSI.QueueDepth())
+	assert.Equal(t, int64(2), slowSI.Dropped())
+
+	// Queue stats should show up on the status page.
+	var buf bytes.Buffer
+	assert.NoError(t, StatusTmpl.Execute(&buf, []*SurfacerInfo{slowSI}))
+	assert.Contains(t, buf.String(), "<td>2</td>")
+
+	// Fast surfacer should not be held up by the slow one.
+	for i := 0; i < 5; i++ {
+		select {
+		case <-fast.received:
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timed out waiting for EventMetrics %d at the fast surfacer", i)
+		}
+	}
+	assert.Equal(t, int64(0), fastSI.Dropped())
+	assert.Eventually(t, func() bool {
+		writes, _ := fastSI.WriteLatency()
+		return writes == 5
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// Queued EventMetrics are delivered once the slow surfacer recovers.
+	close(bs.unblock)
+	for i := 0; i < 2; i++ {
+		select {
+		case <-bs.received:
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timed out waiting for queued EventMetrics %d", i)
+		}
+	}
+}
+
+func TestSurfacerDrain(t *testing.T) {
+	bs := &blockingSurfacer{
+		received: make(chan *metrics.EventMetrics, 10),
+		unblock:  make(chan struct{}),
+	}
+	si := &SurfacerInfo{Surfacer: bs}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	si.startWriter(ctx, 10)
+
+	// Nothing to drain.
+	start := time.Now()
+	si.drain(5 * time.Second)
+	assert.Less(t, time.Since(start), time.Second)
+
+	for i := 0; i < 3; i++ {
+		si.Write(ctx, metrics.NewEventMetrics(time.Now()))
+	}
+	<-bs.received
+
+	// Surfacer is stuck, drain should give up after the timeout.
+	si.drain(50 * time.Millisecond)
+	assert.Equal(t, int64(3), si.pending.Load())
+
+	// Drain should return as soon as the queued EventMetrics are written.
+	close(bs.unblock)
+	start = time.Now()
+	si.drain(5 * time.Second)
+	assert.Less(t, time.Since(start), time.Second)
+	assert.Equal(t, int64(0), si.pending.Load())
+}
+
+// flushingSurfacer buffers EventMetrics until it's flushed.
+type flushingSurfacer struct {
+	buffered, flushed []*metrics.EventMetrics
+}
+
+func (fs *flushingSurfacer) Write(ctx context.Context, em *metrics.EventMetrics) {
+	fs.buffered = append(fs.buffered, em)
+}
+
+func (fs *flushingSurfacer) Flush(ctx context.Context) {
+	fs.flushed = append(fs.flushed, fs.buffered...)
+	fs.buffered = nil
+}
+
+func TestSurfacerDrainFlush(t *testing.T) {
+	fs := &flushingSurfacer{}
+	// Flush should go through the surfacer wrapper.
+	si := &SurfacerInfo{Surfacer: &surfacerWrapper{Surfacer: fs, opts: &options.Options{}}}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	si.startWriter(ctx, 10)
+
+	for i := 0; i < 3; i++ {
+		si.Write(ctx, metrics.NewEventMetrics(time.Now()))
+	}
+	si.drain(5 * time.Second)
+	assert.Len(t, fs.flushed, 3)
+	assert.Empty(t, fs.buffered)
+}
@@ -394,6 +394,7 @@ type TargetsDef struct {
 	//	*TargetsDef_RdsTargets
 	//	*TargetsDef_FileTargets
 	//	*TargetsDef_K8S
+	//	*TargetsDef_UserDefinedTargets
 	//	*TargetsDef_DummyTargets
 	Type isTargetsDef_Type `protobuf_oneof:"type"`
 	// Static endpoints. These endpoints are merged with the resources returned
@@ -528,6 +529,15 @@ func (x *TargetsDef) GetK8S() *K8STargets {
 	return nil
 }
 
+func (x *TargetsDef) GetUserDefinedTargets() *UserDefinedTargets {
+	if x != nil {
+		if x, ok := x.Type.(*TargetsDef_UserDefinedTargets); ok {
+			return x.UserDefinedTargets
+		}
+	}
+	return nil
+}
+
 func (x *TargetsDef) GetDummyTargets() *DummyTargets {
 	if x != nil {
 		if x, ok := x.Type.(*TargetsDef_DummyTargets); ok {
@@ -650,6 +660,19 @@ type TargetsDef_K8S struct {
 	K8S *K8STargets `protobuf:"bytes,6,opt,name=k8s,oneof"`
 }
 
+type TargetsDef_UserDefinedTargets struct {
+	// Targets from a user defined targets provider, registered through
+	// targets.RegisterProvider(). This is useful for adding an internal
+	// service registry or CMDB as a targets source.
+	// Example:
+	//
+	//	user_defined_targets {
+	//	  provider: "cmdb"
+	//	  config: "service=frontend,env=prod"
+	//	}
+	UserDefinedTargets *UserDefinedTargets `protobuf:"bytes,7,opt,name=user_defined_targets,json=userDefinedTargets,oneof"`
+}
+
 type TargetsDef_DummyTargets struct {
 	// Empty targets to meet the probe definition requirement where there are
 	// actually no targets, for example in case of some external probes.
@@ -668,8 +691,66 @@ func (*TargetsDef_FileTargets) isTargetsDef_Type() {}
 
 func (*TargetsDef_K8S) isTargetsDef_Type() {}
 
+func (*TargetsDef_UserDefinedTargets) isTargetsDef_Type() {}
+
 func (*TargetsDef_DummyTargets) isTargetsDef_Type() {}
 
+type UserDefinedTargets struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Name of the targets provider. It should match the name used while
+	// registering the provider through targets.RegisterProvider().
+	Provider *string `protobuf:"bytes,1,req,name=provider" json:"provider,omitempty"`
+	// Provider specific config. This config is passed as it is (as an opaque
+	// blob) to the provider's factory function.
+	Config        *string `protobuf:"bytes,2,opt,name=config" json:"config,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UserDefinedTargets) Reset() {
+	*x = UserDefinedTargets{}
+	mi := &file_github_com_cloudprober_cloudprober_targets_proto_targets_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UserDefinedTargets) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UserDefinedTargets) ProtoMessage() {}
+
+func (x *UserDefinedTargets) ProtoReflect() protoreflect.Message {
+	mi := &file_github_com_cloudprober_cloudprober_targets_proto_targets_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UserDefinedTargets.ProtoReflect.Descriptor instead.
+func (*UserDefinedTargets) Descriptor() ([]byte, []int) {
+	return file_github_com_cloudprober_cloudprober_targets_proto_targets_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *UserDefinedTargets) GetProvider() string {
+	if x != nil && x.Provider != nil {
+		return *x.Provider
+	}
+	return ""
+}
+
+func (x *UserDefinedTargets) GetConfig() string {
+	if x != nil && x.Config != nil {
+		return *x.Config
+	}
+	return ""
+}
+
 // DummyTargets represent empty targets, which are useful for external
 // probes that do not have any "proper" targets.  Such as ilbprober.
 type DummyTargets struct {
@@ -680,7 +761,7 @@ type DummyTargets struct {
 
 func (x *DummyTargets) Reset() {
 	*x = DummyTargets{}
-	mi := &file_github_com_cloudprober_cloudprober_targets_proto_targets_proto_msgTypes[4]
+	mi := &file_github_com_cloudprober_cloudprober_targets_proto_targets_proto_msgTypes[5]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -692,7 +773,7 @@ func (x *DummyTargets) String() string {
 func (*DummyTargets) ProtoMessage() {}
 
 func (x *DummyTargets) ProtoReflect() protoreflect.Message {
-	mi := &file_github_com_cloudprober_cloudprober_targets_proto_targets_proto_msgTypes[4]
+	mi := &file_github_com_cloudprober_cloudprober_targets_proto_targets_proto_msgTypes[5]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -705,7 +786,7 @@ func (x *DummyTargets) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use DummyTargets.ProtoReflect.Descriptor instead.
 func (*DummyTargets) Descriptor() ([]byte, []int) {
-	return file_github_com_cloudprober_cloudprober_targets_proto_targets_proto_rawDescGZIP(), []int{4}
+	return file_github_com_cloudprober_cloudprober_targets_proto_targets_proto_rawDescGZIP(), []int{5}
 }
 
 // Global targets options. These options are independent of the per-probe
@@ -742,7 +823,7 @@ type GlobalTargetsOptions struct {
 
 func (x *GlobalTargetsOptions) Reset() {
 	*x = GlobalTargetsOptions{}
-	mi := &file_github_com_cloudprober_cloudprober_targets_proto_targets_proto_msgTypes[5]
+	mi := &file_github_com_cloudprober_cloudprober_targets_proto_targets_proto_msgTypes[6]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -754,7 +835,7 @@ func (x *GlobalTargetsOptions) String() string {
 func (*GlobalTargetsOptions) ProtoMessage() {}
 
 func (x *GlobalTargetsOptions) ProtoReflect() protoreflect.Message {
-	mi := &file_github_com_cloudprober_cloudprober_targets_proto_targets_proto_msgTypes[5]
+	mi := &file_github_com_cloudprober_cloudprober_targets_proto_targets_proto_msgTypes[6]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -767,7 +848,7 @@ func (x *GlobalTargetsOptions) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use GlobalTargetsOptions.ProtoReflect.Descriptor instead.
 func (*GlobalTargetsOptions) Descriptor() ([]byte, []int) {
-	return file_github_com_cloudprober_cloudprober_targets_proto_targets_proto_rawDescGZIP(), []int{5}
+	return file_github_com_cloudprober_cloudprober_targets_proto_targets_proto_rawDescGZIP(), []int{6}
 }
 
 // Deprecated: Marked as deprecated in github.com/cloudprober/cloudprober/targets/proto/targets.proto.
@@ -830,7 +911,7 @@ const file_github_com_cloudprober_cloudprober_targets_proto_targets_proto_rawDes
 	"\x06server\x18\x01 \x01(\tR\x06server\x12\x1c\n" +
 	"\attl_sec\x18\x02 \x01(\x05:\x03300R\x06ttlSec\x12)\n" +
 	"\x11max_cache_age_sec\x18\x03 \x01(\x05R\x0emaxCacheAgeSec\x126\n" +
-	"\x14backend_timeout_msec\x18\x04 \x01(\x05:\x045000R\x12backendTimeoutMsec\"\xa6\x05\n" +
+	"\x14backend_timeout_msec\x18\x04 \x01(\x05:\x045000R\x12backendTimeoutMsec\"\x83\x06\n" +
 	"\n" +
 	"TargetsDef\x12\x1f\n" +
 	"\n" +
@@ -841,7 +922,8 @@ const file_github_com_cloudprober_cloudprober_targets_proto_targets_proto_rawDes
 	"\vrds_targets\x18\x03 \x01(\v2\x1f.cloudprober.targets.RDSTargetsH\x00R\n" +
 	"rdsTargets\x12J\n" +
 	"\ffile_targets\x18\x04 \x01(\v2%.cloudprober.targets.file.TargetsConfH\x00R\vfileTargets\x123\n" +
-	"\x03k8s\x18\x06 \x01(\v2\x1f.cloudprober.targets.K8sTargetsH\x00R\x03k8s\x12H\n" +
+	"\x03k8s\x18\x06 \x01(\v2\x1f.cloudprober.targets.K8sTargetsH\x00R\x03k8s\x12[\n" +
+	"\x14user_defined_targets\x18\a \x01(\v2'.cloudprober.targets.UserDefinedTargetsH\x00R\x12userDefinedTargets\x12H\n" +
 	"\rdummy_targets\x18\x14 \x01(\v2!.cloudprober.targets.DummyTargetsH\x00R\fdummyTargets\x129\n" +
 	"\bendpoint\x18\x17 \x03(\v2\x1d.cloudprober.targets.EndpointR\bendpoint\x12\x14\n" +
 	"\x05regex\x18\x15 \x01(\tR\x05regex\x121\n" +
@@ -850,7 +932,10 @@ const file_github_com_cloudprober_cloudprober_targets_proto_targets_proto_rawDes
 	"dnsOptions\x12\x1d\n" +
 	"\n" +
 	"dns_server\x18\x1f \x01(\tR\tdnsServer*\t\b\xc8\x01\x10\x80\x80\x80\x80\x02B\x06\n" +
-	"\x04type\"\x0e\n" +
+	"\x04type\"H\n" +
+	"\x12UserDefinedTargets\x12\x1a\n" +
+	"\bprovider\x18\x01 \x02(\tR\bprovider\x12\x16\n" +
+	"\x06config\x18\x02 \x01(\tR\x06config\"\x0e\n" +
 	"\fDummyTargets\"\xd9\x02\n" +
 	"\x14GlobalTargetsOptions\x120\n" +
 	"\x12rds_server_address\x18\x03 \x01(\tB\x02\x18\x01R\x10rdsServerAddress\x12W\n" +
@@ -870,43 +955,45 @@ func file_github_com_cloudprober_cloudprober_targets_proto_targets_proto_rawDesc
 	return file_github_com_cloudprober_cloudprober_targets_proto_targets_proto_rawDescData
 }
 
-var file_github_com_cloudprober_cloudprober_targets_proto_targets_proto_msgTypes = make([]protoimpl.MessageInfo, 6)
+var file_github_com_cloudprober_cloudprober_targets_proto_targets_proto_msgTypes = make([]protoimpl.MessageInfo, 7)
 var file_github_com_cloudprober_cloudprober_targets_proto_targets_proto_goTypes = []any{
 	(*RDSTargets)(nil),                     // 0: cloudprober.targets.RDSTargets
 	(*K8STargets)(nil),                     // 1: cloudprober.targets.K8sTargets
 	(*DNSOptions)(nil),                     // 2: cloudprober.targets.DNSOptions
 	(*TargetsDef)(nil),                     // 3: cloudprober.targets.TargetsDef
-	(*DummyTargets)(nil),                   // 4: cloudprober.targets.DummyTargets
-	(*GlobalTargetsOptions)(nil),           // 5: cloudprober.targets.GlobalTargetsOptions
-	(*proto.ClientConf_ServerOptions)(nil), // 6: cloudprober.rds.ClientConf.ServerOptions
-	(*proto1.Filter)(nil),                  // 7: cloudprober.rds.Filter
-	(*proto1.IPConfig)(nil),                // 8: cloudprober.rds.IPConfig
-	(*proto3.TargetsConf)(nil),             // 9: cloudprober.targets.gce.TargetsConf
-	(*proto4.TargetsConf)(nil),             // 10: cloudprober.targets.file.TargetsConf
-	(*proto2.Endpoint)(nil),                // 11: cloudprober.targets.Endpoint
-	(*proto3.GlobalOptions)(nil),           // 12: cloudprober.targets.gce.GlobalOptions
-	(*proto5.Options)(nil),                 // 13: cloudprober.targets.lameduck.Options
+	(*UserDefinedTargets)(nil),             // 4: cloudprober.targets.UserDefinedTargets
+	(*DummyTargets)(nil),                   // 5: cloudprober.targets.DummyTargets
+	(*GlobalTargetsOptions)(nil),           // 6: cloudprober.targets.GlobalTargetsOptions
+	(*proto.ClientConf_ServerOptions)(nil), // 7: cloudprober.rds.ClientConf.ServerOptions
+	(*proto1.Filter)(nil),                  // 8: cloudprober.rds.Filter
+	(*proto1.IPConfig)(nil),                // 9: cloudprober.rds.IPConfig
+	(*proto3.TargetsConf)(nil),             // 10: cloudprober.targets.gce.TargetsConf
+	(*proto4.TargetsConf)(nil),             // 11: cloudprober.targets.file.TargetsConf
+	(*proto2.Endpoint)(nil),                // 12: cloudprober.targets.Endpoint
+	(*proto3.GlobalOptions)(nil),           // 13: cloudprober.targets.gce.GlobalOptions
+	(*proto5.Options)(nil),                 // 14: cloudprober.targets.lameduck.Options
 }
 var file_github_com_cloudprober_cloudprober_targets_proto_targets_proto_depIdxs = []int32{
-	6,  // 0: cloudprober.targets.RDSTargets.rds_server_options:type_name -> cloudprober.rds.ClientConf.ServerOptions
-	7,  // 1: cloudprober.targets.RDSTargets.filter:type_name -> cloudprober.rds.Filter
-	8,  // 2: cloudprober.targets.RDSTargets.ip_config:type_name -> cloudprober.rds.IPConfig
-	6,  // 3: cloudprober.targets.K8sTargets.rds_server_options:type_name -> cloudprober.rds.ClientConf.ServerOptions
-	9,  // 4: cloudprober.targets.TargetsDef.gce_targets:type_name -> cloudprober.targets.gce.TargetsConf
+	7,  // 0: cloudprober.targets.RDSTargets.rds_server_options:type_name -> cloudprober.rds.ClientConf.ServerOptions
+	8,  // 1: cloudprober.targets.RDSTargets.filter:type_name -> cloudprober.rds.Filter
+	9,  // 2: cloudprober.targets.RDSTargets.ip_config:type_name -> cloudprober.rds.IPConfig
+	7,  // 3: cloudprober.targets.K8sTargets.rds_server_options:type_name -> cloudprober.rds.ClientConf.ServerOptions
+	10, // 4: cloudprober.targets.TargetsDef.gce_targets:type_name -> cloudprober.targets.gce.TargetsConf
 	0,  // 5: cloudprober.targets.TargetsDef.rds_targets:type_name -> cloudprober.targets.RDSTargets
-	10, // 6: cloudprober.targets.TargetsDef.file_targets:type_name -> cloudprober.targets.file.TargetsConf
+	11, // 6: cloudprober.targets.TargetsDef.file_targets:type_name -> cloudprober.targets.file.TargetsConf
 	1,  // 7: cloudprober.targets.TargetsDef.k8s:type_name -> cloudprober.targets.K8sTargets
-	4,  // 8: cloudprober.targets.TargetsDef.dummy_targets:type_name -> cloudprober.targets.DummyTargets
-	11, // 9: cloudprober.targets.TargetsDef.endpoint:type_name -> cloudprober.targets.Endpoint
-	2,  // 10: cloudprober.targets.TargetsDef.dns_options:type_name -> cloudprober.targets.DNSOptions
-	6,  // 11: cloudprober.targets.GlobalTargetsOptions.rds_server_options:type_name -> cloudprober.rds.ClientConf.ServerOptions
-	12, // 12: cloudprober.targets.GlobalTargetsOptions.global_gce_targets_options:type_name -> cloudprober.targets.gce.GlobalOptions
-	13, // 13: cloudprober.targets.GlobalTargetsOptions.lame_duck_options:type_name -> cloudprober.targets.lameduck.Options
-	14, // [14:14] is the sub-list for method output_type
-	14, // [14:14] is the sub-list for method input_type
-	14, // [14:14] is the sub-list for extension type_name
-	14, // [14:14] is the sub-list for extension extendee
-	0,  // [0:14] is the sub-list for field type_name
+	4,  // 8: cloudprober.targets.TargetsDef.user_defined_targets:type_name -> cloudprober.targets.UserDefinedTargets
+	5,  // 9: cloudprober.targets.TargetsDef.dummy_targets:type_name -> cloudprober.targets.DummyTargets
+	12, // 10: cloudprober.targets.TargetsDef.endpoint:type_name -> cloudprober.targets.Endpoint
+	2,  // 11: cloudprober.targets.TargetsDef.dns_options:type_name -> cloudprober.targets.DNSOptions
+	7,  // 12: cloudprober.targets.GlobalTargetsOptions.rds_server_options:type_name -> cloudprober.rds.ClientConf.ServerOptions
+	13, // 13: cloudprober.targets.GlobalTargetsOptions.global_gce_targets_options:type_name -> cloudprober.targets.gce.GlobalOptions
+	14, // 14: cloudprober.targets.GlobalTargetsOptions.lame_duck_options:type_name -> cloudprober.targets.lameduck.Options
+	15, // [15:15] is the sub-list for method output_type
+	15, // [15:15] is the sub-list for method input_type
+	15, // [15:15] is the sub-list for extension type_name
+	15, // [15:15] is the sub-list for extension extendee
+	0,  // [0:15] is the sub-list for field type_name
 }
 
 func init() { file_github_com_cloudprober_cloudprober_targets_proto_targets_proto_init() }
@@ -927,6 +1014,7 @@ func file_github_com_cloudprober_cloudprober_targets_proto_targets_proto_init()
 		(*TargetsDef_RdsTargets)(nil),
 		(*TargetsDef_FileTargets)(nil),
 		(*TargetsDef_K8S)(nil),
+		(*TargetsDef_UserDefinedTargets)(nil),
 		(*TargetsDef_DummyTargets)(nil),
 	}
 	type x struct{}
@@ -935,7 +1023,7 @@ func file_github_com_cloudprober_cloudprober_targets_proto_targets_proto_init()
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_github_com_cloudprober_cloudprober_targets_proto_targets_proto_rawDesc), len(file_github_com_cloudprober_cloudprober_targets_proto_targets_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   6,
+			NumMessages:   7,
 			NumExtensions: 0,
 			NumServices:   0,
 		},
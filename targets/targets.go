@@ -78,6 +78,17 @@ var (
 	extensionMapMu sync.Mutex
 )
 
+// ProviderFactory creates targets for a user defined targets provider. It's
+// called with the provider specific config (user_defined_targets.config).
+type ProviderFactory func(config string, l *logger.Logger) (Targets, error)
+
+// providers is a map of user defined targets providers, keyed by the provider
+// name.
+var (
+	providers   = make(map[string]ProviderFactory)
+	providersMu sync.RWMutex
+)
+
 var (
 	sharedTargets   = make(map[string]Targets)
 	sharedTargetsMu sync.RWMutex
@@ -389,6 +400,13 @@ func New(targetsDef *targetspb.TargetsDef, ldLister endpoint.Lister, globalOpts
 		}
 		t.lister, t.resolver = kt, kt
 
+	case *targetspb.TargetsDef_UserDefinedTargets:
+		udt, err := userDefinedTargets(targetsDef.GetUserDefinedTargets(), l)
+		if err != nil {
+			return nil, fmt.Errorf("targets.New(): %v", err)
+		}
+		t.lister, t.resolver = udt, udt
+
 	case *targetspb.TargetsDef_DummyTargets:
 		dummy := &dummy{}
 		t.lister, t.resolver = dummy, dummy
@@ -437,6 +455,45 @@ func RegisterTargetsType(extensionFieldNo int, newTargetsFunc func(interface{},
 	extensionMap[extensionFieldNo] = newTargetsFunc
 }
 
+func userDefinedTargets(pb *targetspb.UserDefinedTargets, l *logger.Logger) (Targets, error) {
+	providersMu.RLock()
+	newTargetsFunc := providers[pb.GetProvider()]
+	providersMu.RUnlock()
+
+	if newTargetsFunc == nil {
+		return nil, fmt.Errorf("unregistered targets provider: %s", pb.GetProvider())
+	}
+
+	tgts, err := newTargetsFunc(pb.GetConfig(), l)
+	if err != nil {
+		return nil, fmt.Errorf("error creating targets from provider %s: %v", pb.GetProvider(), err)
+	}
+	return tgts, nil
+}
+
+// RegisterProvider registers a user defined targets provider with the given
+// name. Registered providers can be used in the config through the
+// user_defined_targets targets type:
+//
+//	targets.RegisterProvider("cmdb", func(conf string, l *logger.Logger) (targets.Targets, error) {
+//		return cmdb.NewTargets(conf, l)
+//	})
+//
+//	probe {
+//	  ...
+//	  targets {
+//	    user_defined_targets {
+//	      provider: "cmdb"
+//	      config: "service=frontend"
+//	    }
+//	  }
+//	}
+func RegisterProvider(name string, f ProviderFactory) {
+	providersMu.Lock()
+	defer providersMu.Unlock()
+	providers[name] = f
+}
+
 // SetSharedTargets adds given targets to an internal map. These targets can
 // then be referred by multiple probes through "shared_targets" option.
 func SetSharedTargets(name string, tgts Targets) {
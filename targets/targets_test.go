@@ -183,6 +183,65 @@ func TestGetExtensionTargets(t *testing.T) {
 	}
 }
 
+func TestUserDefinedTargets(t *testing.T) {
+	var gotConf string
+	RegisterProvider("test-provider", func(conf string, l *logger.Logger) (Targets, error) {
+		if conf == "" {
+			return nil, errors.New("empty config")
+		}
+		gotConf = conf
+		return &testTargetsType{names: strings.Split(conf, ",")}, nil
+	})
+
+	tests := []struct {
+		name     string
+		provider string
+		conf     string
+		want     []string
+		wantErr  bool
+	}{
+		{
+			name:     "valid",
+			provider: "test-provider",
+			conf:     "a,b",
+			want:     []string{"a", "b"},
+		},
+		{
+			name:     "provider_error",
+			provider: "test-provider",
+			wantErr:  true,
+		},
+		{
+			name:     "unregistered",
+			provider: "unknown-provider",
+			conf:     "a,b",
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			targetsDef := &targetspb.TargetsDef{
+				Type: &targetspb.TargetsDef_UserDefinedTargets{
+					UserDefinedTargets: &targetspb.UserDefinedTargets{
+						Provider: proto.String(tt.provider),
+						Config:   proto.String(tt.conf),
+					},
+				},
+			}
+			tgts, err := New(targetsDef, nil, nil, nil, nil)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("New() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			assert.Equal(t, tt.conf, gotConf)
+			assert.Equal(t, tt.want, endpoint.NamesFromEndpoints(tgts.ListEndpoints()))
+		})
+	}
+}
+
 func TestSharedTargets(t *testing.T) {
 	testHosts := []string{"host1", "host2"}
 
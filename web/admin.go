@@ -0,0 +1,91 @@
+// Copyright 2025 The Cloudprober Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package web
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/cloudprober/cloudprober/state"
+)
+
+// adminHandler wraps the admin endpoints' handlers. Admin endpoints accept
+// only POST requests, authenticated with the configured admin token.
+func adminHandler(adminToken func() string, f func(w http.ResponseWriter, r *http.Request)) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			http.Error(w, "admin endpoints require a POST request", http.StatusMethodNotAllowed)
+			return
+		}
+
+		token := adminToken()
+		if token == "" {
+			http.Error(w, "admin endpoints are disabled, admin_token is not configured", http.StatusForbidden)
+			return
+		}
+
+		bearer, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(bearer), []byte(token)) != 1 {
+			http.Error(w, "missing or invalid admin token", http.StatusUnauthorized)
+			return
+		}
+
+		f(w, r)
+	}
+}
+
+func addAdminHandlers(fn DataFuncs) error {
+	for path, paused := range map[string]bool{"/admin/pause": true, "/admin/resume": false} {
+		if err := state.AddWebHandler(path, adminHandler(fn.AdminToken, func(w http.ResponseWriter, r *http.Request) {
+			name := r.URL.Query().Get("probe")
+			if name == "" {
+				http.Error(w, "probe name is required, e.g. ?probe=<name>", http.StatusBadRequest)
+				return
+			}
+			if err := fn.SetProbePaused(name, paused); err != nil {
+				http.Error(w, err.Error(), http.StatusNotFound)
+				return
+			}
+			if paused {
+				fmt.Fprintf(w, "Probe %s paused", name)
+			} else {
+				fmt.Fprintf(w, "Probe %s resumed", name)
+			}
+		})); err != nil {
+			return err
+		}
+	}
+
+	return state.AddWebHandler("/admin/lameduck", adminHandler(fn.AdminToken, func(w http.ResponseWriter, r *http.Request) {
+		lameDuck := true
+		if v := r.URL.Query().Get("enable"); v != "" {
+			var err error
+			if lameDuck, err = strconv.ParseBool(v); err != nil {
+				http.Error(w, fmt.Sprintf("invalid value for enable (%s): %v", v, err), http.StatusBadRequest)
+				return
+			}
+		}
+		fn.SetLameDuck(lameDuck)
+		if lameDuck {
+			fmt.Fprint(w, "Lameduck mode enabled")
+		} else {
+			fmt.Fprint(w, "Lameduck mode disabled")
+		}
+	}))
+}
@@ -0,0 +1,83 @@
+// Copyright 2025 The Cloudprober Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package web
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/cloudprober/cloudprober/state"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAdminHandlers(t *testing.T) {
+	oldSrvMux := state.DefaultHTTPServeMux()
+	defer state.SetDefaultHTTPServeMux(oldSrvMux)
+	srvMux := http.NewServeMux()
+	state.SetDefaultHTTPServeMux(srvMux)
+
+	token := ""
+	paused := map[string]bool{"probe1": false}
+	lameDuck := false
+
+	assert.NoError(t, InitWithDataFuncs(DataFuncs{
+		GetRawConfig:    func() string { return "" },
+		GetParsedConfig: func() string { return "" },
+		AdminToken:      func() string { return token },
+		SetProbePaused: func(name string, p bool) error {
+			if _, ok := paused[name]; !ok {
+				return fmt.Errorf("probe %s not found", name)
+			}
+			paused[name] = p
+			return nil
+		},
+		SetLameDuck: func(b bool) { lameDuck = b },
+	}))
+
+	call := func(method, url, authToken string) int {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(method, url, nil)
+		if authToken != "" {
+			req.Header.Set("Authorization", "Bearer "+authToken)
+		}
+		srvMux.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	// Admin token is not configured.
+	assert.Equal(t, http.StatusForbidden, call(http.MethodPost, "/admin/pause?probe=probe1", "t1"))
+	assert.False(t, paused["probe1"])
+
+	token = "t1"
+	assert.Equal(t, http.StatusMethodNotAllowed, call(http.MethodGet, "/admin/pause?probe=probe1", "t1"))
+	assert.Equal(t, http.StatusUnauthorized, call(http.MethodPost, "/admin/pause?probe=probe1", ""))
+	assert.Equal(t, http.StatusUnauthorized, call(http.MethodPost, "/admin/pause?probe=probe1", "t2"))
+	assert.False(t, paused["probe1"])
+
+	assert.Equal(t, http.StatusOK, call(http.MethodPost, "/admin/pause?probe=probe1", "t1"))
+	assert.True(t, paused["probe1"])
+	assert.Equal(t, http.StatusOK, call(http.MethodPost, "/admin/resume?probe=probe1", "t1"))
+	assert.False(t, paused["probe1"])
+	assert.Equal(t, http.StatusBadRequest, call(http.MethodPost, "/admin/pause", "t1"))
+	assert.Equal(t, http.StatusNotFound, call(http.MethodPost, "/admin/pause?probe=probe2", "t1"))
+
+	assert.Equal(t, http.StatusOK, call(http.MethodPost, "/admin/lameduck", "t1"))
+	assert.True(t, lameDuck)
+	assert.Equal(t, http.StatusOK, call(http.MethodPost, "/admin/lameduck?enable=false", "t1"))
+	assert.False(t, lameDuck)
+	assert.Equal(t, http.StatusBadRequest, call(http.MethodPost, "/admin/lameduck?enable=maybe", "t1"))
+}
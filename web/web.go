@@ -143,6 +143,20 @@ type DataFuncs struct {
 	GetRawConfig    func() string
 	GetParsedConfig func() string
 	GetInfo         func() (map[string]*probes.ProbeInfo, []*surfacers.SurfacerInfo, []*servers.ServerInfo)
+
+	// GetExpandedConfig, if set, is exposed through the /config-expanded
+	// endpoint. It should return the running config with secrets redacted.
+	GetExpandedConfig func() string
+
+	// ReloadConfig, if set, is exposed through the /config-reload endpoint.
+	ReloadConfig func() error
+
+	// SetProbePaused and SetLameDuck, if set, are exposed through the admin
+	// endpoints: /admin/pause, /admin/resume and /admin/lameduck. Admin
+	// endpoints are enabled only if AdminToken returns a non-empty token.
+	AdminToken     func() string
+	SetProbePaused func(name string, paused bool) error
+	SetLameDuck    func(lameDuck bool)
 }
 
 func Init() error {
@@ -182,6 +196,37 @@ func InitWithDataFuncs(fn DataFuncs) error {
 		return err
 	}
 
+	if fn.GetExpandedConfig != nil {
+		if err := state.AddWebHandler("/config-expanded", func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, fn.GetExpandedConfig())
+		}); err != nil {
+			return err
+		}
+	}
+
+	if fn.ReloadConfig != nil {
+		if err := state.AddWebHandler("/config-reload", func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodPost {
+				w.Header().Set("Allow", http.MethodPost)
+				http.Error(w, "config reload requires a POST request", http.StatusMethodNotAllowed)
+				return
+			}
+			if err := fn.ReloadConfig(); err != nil {
+				http.Error(w, fmt.Sprintf("error reloading config: %v", err), http.StatusInternalServerError)
+				return
+			}
+			fmt.Fprint(w, "Config reloaded")
+		}); err != nil {
+			return err
+		}
+	}
+
+	if fn.AdminToken != nil && fn.SetProbePaused != nil && fn.SetLameDuck != nil {
+		if err := addAdminHandlers(fn); err != nil {
+			return err
+		}
+	}
+
 	if err := state.AddWebHandler("/alerts", func(w http.ResponseWriter, r *http.Request) {
 		status, err := alerting.StatusHTML()
 		if err != nil {
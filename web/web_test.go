@@ -16,6 +16,7 @@ package web
 
 import (
 	"bytes"
+	"errors"
 	"html/template"
 	"io"
 	"net/http"
@@ -179,3 +180,37 @@ func TestArtifactsLinks(t *testing.T) {
 		})
 	}
 }
+
+func TestConfigReloadHandler(t *testing.T) {
+	oldSrvMux := state.DefaultHTTPServeMux()
+	defer state.SetDefaultHTTPServeMux(oldSrvMux)
+	srvMux := http.NewServeMux()
+	state.SetDefaultHTTPServeMux(srvMux)
+
+	var reloadErr error
+	reloads := 0
+	assert.NoError(t, InitWithDataFuncs(DataFuncs{
+		GetRawConfig:    func() string { return "" },
+		GetParsedConfig: func() string { return "" },
+		ReloadConfig: func() error {
+			reloads++
+			return reloadErr
+		},
+	}))
+
+	reload := func(method string) int {
+		w := httptest.NewRecorder()
+		srvMux.ServeHTTP(w, httptest.NewRequest(method, "/config-reload", nil))
+		return w.Code
+	}
+
+	assert.Equal(t, http.StatusMethodNotAllowed, reload(http.MethodGet))
+	assert.Equal(t, 0, reloads)
+
+	assert.Equal(t, http.StatusOK, reload(http.MethodPost))
+	assert.Equal(t, 1, reloads)
+
+	reloadErr = errors.New("bad config")
+	assert.Equal(t, http.StatusInternalServerError, reload(http.MethodPost))
+	assert.Equal(t, 2, reloads)
+}